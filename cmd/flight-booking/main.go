@@ -2,6 +2,8 @@ package main
 
 import (
     "log"
+    // Embed the IANA zone database so airport time zones resolve on hosts without one.
+    _ "time/tzdata"
 
     "github.com/ambiyansyah-risyal/flight-booking/internal/adapter/cli"
 )
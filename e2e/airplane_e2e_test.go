@@ -52,8 +52,41 @@ func TestAirplaneE2E(t *testing.T) {
     if _, err := runCLI("airplane", "update", "--code", "NONE", "--seats", "100"); err == nil {
         t.Fatalf("expected update non-existent airplane error")
     }
-    // Delete and non-existent delete
+    // Aircraft types and registrations
+    if _, err := runCLI("airplane", "type", "create", "--icao", "A320", "--iata", "320", "--manufacturer", "Airbus", "--model", "A320-200", "--range", "6100", "--rows", "30", "--layout", "3-3"); err != nil {
+        t.Fatalf("create type A320: %v", err)
+    }
+    if out, err := runCLI("airplane", "create", "--code", "PK-GQA", "--type", "A320"); err != nil || !strings.Contains(out, "(180 seats)") {
+        t.Fatalf("create PK-GQA: %v\n%s", err, out)
+    }
+    if out, err := runCLI("airplane", "list"); err != nil || !strings.Contains(out, "Airbus A320-200") {
+        t.Fatalf("list with types: %v\n%s", err, out)
+    }
+    if _, err := runCLI("airplane", "create", "--code", "PK-GQB", "--type", "B738"); err == nil {
+        t.Fatalf("expected unknown aircraft type error")
+    }
+    if _, err := runCLI("airplane", "type", "delete", "A320"); err == nil {
+        t.Fatalf("expected aircraft type in use error")
+    }
+    // An archived airplane still references its type; purging releases it.
+    if _, err := runCLI("airplane", "delete", "PK-GQA"); err != nil { t.Fatalf("archive PK-GQA: %v", err) }
+    if _, err := runCLI("airplane", "type", "delete", "A320"); err == nil {
+        t.Fatalf("expected aircraft type still in use by the archived airplane")
+    }
+    if _, err := runCLI("airplane", "delete", "PK-GQA", "--purge"); err != nil { t.Fatalf("purge PK-GQA: %v", err) }
+    if _, err := runCLI("airplane", "type", "delete", "A320"); err != nil { t.Fatalf("delete type A320: %v", err) }
+    // Archive, restore and non-existent delete
     if _, err := runCLI("airplane", "delete", "B737"); err != nil { t.Fatalf("delete B737: %v", err) }
+    if out, err := runCLI("airplane", "list"); err != nil || strings.Contains(out, "B737") {
+        t.Fatalf("archived B737 still listed: %v\n%s", err, out)
+    }
+    if out, err := runCLI("airplane", "list", "--include-archived"); err != nil || !strings.Contains(out, "B737") {
+        t.Fatalf("list with archived: %v\n%s", err, out)
+    }
+    if _, err := runCLI("airplane", "restore", "B737"); err != nil { t.Fatalf("restore B737: %v", err) }
+    if out, err := runCLI("airplane", "list"); err != nil || !strings.Contains(out, "B737") {
+        t.Fatalf("restored B737 not listed: %v\n%s", err, out)
+    }
     if _, err := runCLI("airplane", "delete", "A320"); err != nil { t.Fatalf("delete A320: %v", err) }
     if _, err := runCLI("airplane", "delete", "NONE"); err == nil { t.Fatalf("expected delete non-existent airplane error") }
 }
@@ -38,6 +38,17 @@ func TestBookingE2E(t *testing.T) {
 		t.Fatalf("search output missing airplane code: %s", searchOut)
 	}
 
+	// A published fare shows up in flexible search and the month calendar
+	mustRunCLI(t, "fare", "create", "--route", "BKR1", "--amount", "99.50", "--currency", "USD")
+	flexOut := mustRunCLI(t, "booking", "search", "--origin", "BKA", "--destination", "BKB", "--date", "2025-01-04", "--flex", "2")
+	if !strings.Contains(flexOut, "2025-01-02") || !strings.Contains(flexOut, "99.50 USD") {
+		t.Fatalf("flex search missing priced flight: %s", flexOut)
+	}
+	calOut := mustRunCLI(t, "booking", "calendar", "--origin", "BKA", "--destination", "BKB", "--month", "2025-01")
+	if !containsFields(calOut, "2025-01-02 1 99.50 USD 2") {
+		t.Fatalf("calendar missing day summary: %s", calOut)
+	}
+
 	// Book first passenger
 	bookOut1, ref1 := mustBook(t, scheduleID, "Alice")
 	if !strings.Contains(bookOut1, "seat 1") {
@@ -74,6 +85,192 @@ func TestBookingE2E(t *testing.T) {
 		t.Fatalf("expected no rows after full booking, got %q", searchOutAfter)
 	}
 
+	// Cancelling releases the seat through the inventory row, and the next booking reuses it
+	mustRunCLI(t, "booking", "cancel", ref2)
+	invOut := mustRunCLI(t, "schedule", "inventory", strconv.FormatInt(scheduleID, 10))
+	if !strings.Contains(invOut, "sold: 1") || !strings.Contains(invOut, "available: 1") {
+		t.Fatalf("unexpected inventory after cancel: %s", invOut)
+	}
+	bookOut3, _ := mustBook(t, scheduleID, "Charlie")
+	if !strings.Contains(bookOut3, "seat 2") {
+		t.Fatalf("expected the released seat 2, got %s", bookOut3)
+	}
+	reconcileOut := mustRunCLI(t, "schedule", "reconcile-inventory")
+	if !strings.Contains(reconcileOut, "consistent") {
+		t.Fatalf("expected consistent inventory, got %s", reconcileOut)
+	}
+}
+
+func TestBookingE2E_RoundTrip(t *testing.T) {
+	dsn, terminate := startPostgres(t)
+	defer terminate()
+	applyBootstrap(t, dsn)
+
+	setAppEnvFromDSN(t, dsn)
+
+	mustRunCLI(t, "airport", "create", "--code", "RTA", "--city", "Round Alpha")
+	mustRunCLI(t, "airport", "create", "--code", "RTB", "--city", "Round Beta")
+	mustRunCLI(t, "airplane", "create", "--code", "RTPL", "--seats", "1")
+	mustRunCLI(t, "route", "create", "--code", "RTOUT", "--origin", "RTA", "--destination", "RTB")
+	mustRunCLI(t, "route", "create", "--code", "RTBACK", "--origin", "RTB", "--destination", "RTA")
+	mustRunCLI(t, "fare", "create", "--route", "RTOUT", "--amount", "100", "--round-trip", "180", "--currency", "USD")
+	mustRunCLI(t, "schedule", "create", "--route", "RTOUT", "--airplane", "RTPL", "--date", "2025-05-01", "--time", "08:00", "--arrival", "10:00")
+	mustRunCLI(t, "schedule", "create", "--route", "RTBACK", "--airplane", "RTPL", "--date", "2025-05-03", "--time", "09:00")
+	outID := parseFirstScheduleID(t, mustRunCLI(t, "schedule", "list", "--route", "RTOUT"))
+	backID := parseFirstScheduleID(t, mustRunCLI(t, "schedule", "list", "--route", "RTBACK"))
+
+	searchOut := mustRunCLI(t, "booking", "search", "--origin", "RTA", "--destination", "RTB", "--date", "2025-05-01", "--return-date", "2025-05-03")
+	if !strings.Contains(searchOut, "180.00 USD") {
+		t.Fatalf("round-trip search missing pair: %s", searchOut)
+	}
+
+	bookOut := mustRunCLI(t, "booking", "book", "--schedule", strconv.FormatInt(outID, 10), "--return", strconv.FormatInt(backID, 10), "--name", "Alice")
+	if !strings.Contains(bookOut, "fare 180.00 USD") || strings.Count(bookOut, "booking confirmed") != 2 {
+		t.Fatalf("unexpected round-trip booking: %s", bookOut)
+	}
+
+	// Both flights are now full, so a second round trip books neither direction.
+	if _, err := runCLI("booking", "book", "--schedule", strconv.FormatInt(outID, 10), "--return", strconv.FormatInt(backID, 10), "--name", "Bob"); err == nil {
+		t.Fatalf("expected round trip on full flights to fail")
+	}
+	tripOut := mustRunCLI(t, "booking", "trip", "1")
+	if !strings.Contains(tripOut, "passenger: Alice") || strings.Count(tripOut, "CONFIRMED") != 2 {
+		t.Fatalf("unexpected trip: %s", tripOut)
+	}
+}
+
+func TestScheduleSeriesE2E(t *testing.T) {
+	dsn, terminate := startPostgres(t)
+	defer terminate()
+	applyBootstrap(t, dsn)
+
+	setAppEnvFromDSN(t, dsn)
+
+	mustRunCLI(t, "airport", "create", "--code", "SRA", "--city", "Series Alpha")
+	mustRunCLI(t, "airport", "create", "--code", "SRB", "--city", "Series Beta")
+	mustRunCLI(t, "airplane", "create", "--code", "SRPL", "--seats", "2")
+	mustRunCLI(t, "route", "create", "--code", "SRR1", "--origin", "SRA", "--destination", "SRB")
+	// An existing flight on a series date is left alone.
+	mustRunCLI(t, "schedule", "create", "--route", "SRR1", "--airplane", "SRPL", "--date", "2099-01-02", "--time", "07:00")
+
+	createOut := mustRunCLI(t, "schedule", "create-series", "--route", "SRR1", "--airplane", "SRPL", "--from", "2099-01-01", "--to", "2099-01-31", "--days", "Mon,Wed,Fri", "--time", "08:30", "--arrival", "10:00")
+	if !strings.Contains(createOut, "scheduled 12 flight(s), skipped 1 existing") {
+		t.Fatalf("unexpected create-series output: %s", createOut)
+	}
+	extendOut := mustRunCLI(t, "schedule", "series", "extend", "1", "--to", "2099-02-07")
+	if !strings.Contains(extendOut, "scheduled 3 flight(s)") {
+		t.Fatalf("unexpected extend output: %s", extendOut)
+	}
+	if out := mustRunCLI(t, "schedule", "series", "amend", "1", "--time", "09:00", "--arrival", "10:30"); !strings.Contains(out, "15 flight(s) moved") {
+		t.Fatalf("unexpected amend output: %s", out)
+	}
+
+	// Drop the series summary line so the flight table comes first.
+	showOut := mustRunCLI(t, "schedule", "series", "show", "1")
+	flightID := parseFirstScheduleID(t, showOut[strings.Index(showOut, "\n")+1:])
+	mustBook(t, flightID, "Alice")
+	cancelOut := mustRunCLI(t, "schedule", "series", "cancel", "1")
+	if !strings.Contains(cancelOut, "removed 14 flight(s), kept 1 with bookings") {
+		t.Fatalf("unexpected cancel output: %s", cancelOut)
+	}
+	if out := mustRunCLI(t, "schedule", "list", "--route", "SRR1"); strings.Count(out, "SRR1") != 2 {
+		t.Fatalf("expected the booked series flight and the one-off flight left, got: %s", out)
+	}
+}
+
+func TestScheduleSwapAircraftE2E(t *testing.T) {
+	dsn, terminate := startPostgres(t)
+	defer terminate()
+	applyBootstrap(t, dsn)
+
+	setAppEnvFromDSN(t, dsn)
+
+	mustRunCLI(t, "airport", "create", "--code", "SWA", "--city", "Swap Alpha")
+	mustRunCLI(t, "airport", "create", "--code", "SWB", "--city", "Swap Beta")
+	mustRunCLI(t, "airplane", "create", "--code", "SWBIG", "--seats", "3")
+	mustRunCLI(t, "airplane", "create", "--code", "SWONE", "--seats", "1")
+	mustRunCLI(t, "route", "create", "--code", "SWR1", "--origin", "SWA", "--destination", "SWB")
+	mustRunCLI(t, "schedule", "create", "--route", "SWR1", "--airplane", "SWBIG", "--date", "2099-03-01")
+	scheduleID := parseFirstScheduleID(t, mustRunCLI(t, "schedule", "list", "--route", "SWR1"))
+	id := strconv.FormatInt(scheduleID, 10)
+
+	_, alice := mustBook(t, scheduleID, "Alice")
+	_, bob := mustBook(t, scheduleID, "Bob")
+	if _, err := runCLI("schedule", "swap-aircraft", id, "--airplane", "SWONE"); err == nil {
+		t.Fatalf("expected two bookings refused on a one-seat airplane")
+	}
+
+	// With Alice gone, Bob moves from seat 2 to the only seat left.
+	mustRunCLI(t, "booking", "cancel", alice)
+	out := mustRunCLI(t, "schedule", "swap-aircraft", id, "--airplane", "SWONE")
+	if !strings.Contains(out, "swapped SWBIG -> SWONE, reseated 1 booking(s)") || !containsFields(out, bob+" 2 1") {
+		t.Fatalf("unexpected swap output: %s", out)
+	}
+	if out := mustRunCLI(t, "booking", "get", bob); !strings.Contains(out, "seat: 1") {
+		t.Fatalf("expected Bob reseated, got: %s", out)
+	}
+	if out := mustRunCLI(t, "schedule", "inventory", id); !strings.Contains(out, "capacity: 1\nsold: 1\n") {
+		t.Fatalf("expected the inventory resized to one sold seat, got: %s", out)
+	}
+}
+
+func TestAirplaneMaintenanceE2E(t *testing.T) {
+	dsn, terminate := startPostgres(t)
+	defer terminate()
+	applyBootstrap(t, dsn)
+
+	setAppEnvFromDSN(t, dsn)
+
+	mustRunCLI(t, "airport", "create", "--code", "MTA", "--city", "Maint Alpha")
+	mustRunCLI(t, "airport", "create", "--code", "MTB", "--city", "Maint Beta")
+	mustRunCLI(t, "airplane", "create", "--code", "MTONE", "--seats", "3")
+	mustRunCLI(t, "airplane", "create", "--code", "MTTWO", "--seats", "3")
+	mustRunCLI(t, "route", "create", "--code", "MTR1", "--origin", "MTA", "--destination", "MTB")
+	mustRunCLI(t, "schedule", "create", "--route", "MTR1", "--airplane", "MTONE", "--date", "2099-04-02", "--time", "08:00", "--arrival", "10:00")
+	scheduleID := parseFirstScheduleID(t, mustRunCLI(t, "schedule", "list", "--route", "MTR1"))
+	mustBook(t, scheduleID, "Alice")
+
+	out := mustRunCLI(t, "airplane", "maintenance", "add", "--code", "MTONE", "--from", "2099-04-01", "--to", "2099-04-03", "--reason", "C-check")
+	if !strings.Contains(out, "1 booked flight(s) fall in this window") || !containsFields(out, strconv.FormatInt(scheduleID, 10)+" MTR1") {
+		t.Fatalf("expected the booked flight reported, got: %s", out)
+	}
+	if _, err := runCLI("schedule", "create", "--route", "MTR1", "--airplane", "MTONE", "--date", "2099-04-02", "--time", "14:00"); err == nil {
+		t.Fatalf("expected schedule create refused during maintenance")
+	}
+	mustRunCLI(t, "schedule", "create", "--route", "MTR1", "--airplane", "MTONE", "--date", "2099-04-03", "--time", "08:00")
+
+	mustRunCLI(t, "airplane", "maintenance", "add", "--code", "MTTWO", "--from", "2099-04-02T09:00", "--to", "2099-04-02T12:00")
+	if _, err := runCLI("schedule", "swap-aircraft", strconv.FormatInt(scheduleID, 10), "--airplane", "MTTWO"); err == nil {
+		t.Fatalf("expected swap onto an airplane in maintenance refused")
+	}
+	if out := mustRunCLI(t, "airplane", "maintenance", "list", "--code", "MTONE"); !strings.Contains(out, "C-check") {
+		t.Fatalf("expected the window listed, got: %s", out)
+	}
+}
+
+func TestRouteDistanceAndBlockTimeE2E(t *testing.T) {
+	dsn, terminate := startPostgres(t)
+	defer terminate()
+	applyBootstrap(t, dsn)
+
+	setAppEnvFromDSN(t, dsn)
+
+	mustRunCLI(t, "airport", "create", "--code", "GCA", "--city", "Jakarta", "--tz", "Asia/Jakarta", "--lat", "-6.1256", "--lon", "106.6559")
+	mustRunCLI(t, "airport", "create", "--code", "GCB", "--city", "Denpasar", "--tz", "Asia/Makassar")
+	mustRunCLI(t, "airport", "update", "--code", "GCB", "--lat", "-8.7482", "--lon", "115.1672")
+	mustRunCLI(t, "airplane", "create", "--code", "GCPX", "--seats", "3")
+	mustRunCLI(t, "route", "create", "--code", "GCR1", "--origin", "GCA", "--destination", "GCB", "--block", "1h50m")
+	if out := mustRunCLI(t, "route", "list"); !containsFields(out, "GCR1 GCA GCB 983 km 1h50m") {
+		t.Fatalf("expected distance and block time listed, got: %s", out)
+	}
+
+	mustRunCLI(t, "schedule", "create", "--route", "GCR1", "--airplane", "GCPX", "--date", "2099-05-01", "--time", "22:30")
+	if out := mustRunCLI(t, "schedule", "list", "--route", "GCR1"); !strings.Contains(out, "2099-05-02 01:20 WITA") {
+		t.Fatalf("expected the arrival defaulted from the block time, got: %s", out)
+	}
+	if out := mustRunCLI(t, "fare", "create", "--route", "GCR1", "--per-km", "1500", "--currency", "IDR"); !strings.Contains(out, "1474500.00 IDR one-way") {
+		t.Fatalf("expected a distance-based fare, got: %s", out)
+	}
 }
 
 func TestBookingE2E_ErrorFlows(t *testing.T) {
@@ -120,6 +317,16 @@ func TestBookingE2E_ErrorFlows(t *testing.T) {
 	}
 }
 
+// containsFields reports whether any line of out has exactly the given whitespace-separated fields.
+func containsFields(out, fields string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Join(strings.Fields(line), " ") == fields {
+			return true
+		}
+	}
+	return false
+}
+
 func mustBook(t *testing.T, scheduleID int64, passenger string) (string, string) {
 	t.Helper()
 	out := mustRunCLI(t, "booking", "book", "--schedule", strconv.FormatInt(scheduleID, 10), "--name", passenger)
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/config"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+	"github.com/spf13/cobra"
+)
+
+func newAircraftTypeCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "type", Short: "Manage the aircraft type catalog"}
+	cmd.AddCommand(newAircraftTypeCreateCmd())
+	cmd.AddCommand(newAircraftTypeListCmd())
+	cmd.AddCommand(newAircraftTypeDeleteCmd())
+	return cmd
+}
+
+func withAircraftTypeUsecase(run func(*usecase.AircraftTypeUsecase) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := newAirplaneDB(cfg.Database.DSN())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return run(usecase.NewAircraftTypeUsecase(newAircraftTypeRepo(db)))
+}
+
+func newAircraftTypeCreateCmd() *cobra.Command {
+	var t domain.AircraftType
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Add an aircraft type to the catalog",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withAircraftTypeUsecase(func(u *usecase.AircraftTypeUsecase) error {
+				created, err := u.Create(context.Background(), t)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("created aircraft type %s (%s): %s, %d km, %s seat map (%d seats)\n", created.ICAOCode, created.IATACode, created.Name(), created.RangeKm, created.SeatMap, created.SeatMap.Seats())
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&t.ICAOCode, "icao", "", "ICAO type designator, e.g. A320")
+	cmd.Flags().StringVar(&t.IATACode, "iata", "", "IATA type code, e.g. 320")
+	cmd.Flags().StringVar(&t.Manufacturer, "manufacturer", "", "manufacturer, e.g. Airbus")
+	cmd.Flags().StringVar(&t.Model, "model", "", "model, e.g. A320-200")
+	cmd.Flags().IntVar(&t.RangeKm, "range", 0, "range in kilometres")
+	cmd.Flags().IntVar(&t.SeatMap.Rows, "rows", 0, "seat rows in the default seat map")
+	cmd.Flags().StringVar(&t.SeatMap.Layout, "layout", "", "seats per row between aisles, e.g. 3-3")
+	for _, name := range []string{"icao", "iata", "manufacturer", "model", "range", "rows", "layout"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+func newAircraftTypeListCmd() *cobra.Command {
+	var limit, offset int
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List aircraft types",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withAircraftTypeUsecase(func(u *usecase.AircraftTypeUsecase) error {
+				items, err := u.List(context.Background(), limit, offset)
+				if err != nil {
+					return err
+				}
+				tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+				_, _ = fmt.Fprintln(tw, "ICAO\tIATA\tNAME\tRANGE KM\tSEAT MAP\tSEATS")
+				for _, t := range items {
+					_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%d\n", t.ICAOCode, t.IATACode, t.Name(), t.RangeKm, t.SeatMap, t.SeatMap.Seats())
+				}
+				return tw.Flush()
+			})
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 50, "max items")
+	cmd.Flags().IntVar(&offset, "offset", 0, "offset")
+	return cmd
+}
+
+func newAircraftTypeDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <icao>",
+		Short: "Remove an aircraft type no airplane uses",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withAircraftTypeUsecase(func(u *usecase.AircraftTypeUsecase) error {
+				if err := u.Delete(context.Background(), args[0]); err != nil {
+					return err
+				}
+				fmt.Printf("deleted aircraft type %s\n", args[0])
+				return nil
+			})
+		},
+	}
+}
@@ -20,12 +20,19 @@ func newAirplaneCmd() *cobra.Command {
     cmd.AddCommand(newAirplaneListCmd())
     cmd.AddCommand(newAirplaneUpdateCmd())
     cmd.AddCommand(newAirplaneDeleteCmd())
+    cmd.AddCommand(newAirplaneRestoreCmd())
+    cmd.AddCommand(newAircraftTypeCmd())
+    cmd.AddCommand(newAirplaneMaintenanceCmd())
     return cmd
 }
 
 var (
     newAirplaneDB   = func(dsn string) (*sqlx.DB, error) { return sqlxrepo.New(dsn) }
     newAirplaneRepoF = func(db *sqlx.DB) domain.AirplaneRepository { return sqlxrepo.NewAirplaneRepository(db) }
+    newAirplaneScheduleRepo = func(db *sqlx.DB) domain.FlightScheduleRepository { return sqlxrepo.NewScheduleRepository(db) }
+    newAirplaneBookingRepo  = func(db *sqlx.DB) domain.BookingRepository { return sqlxrepo.NewBookingRepository(db) }
+    newAircraftTypeRepo     = func(db *sqlx.DB) domain.AircraftTypeRepository { return sqlxrepo.NewAircraftTypeRepository(db) }
+    newMaintenanceRepo      = func(db *sqlx.DB) domain.MaintenanceRepository { return sqlxrepo.NewMaintenanceRepository(db) }
 )
 
 func withAirplaneUsecase(run func(u *usecase.AirplaneUsecase) error) error {
@@ -35,7 +42,7 @@ func withAirplaneUsecase(run func(u *usecase.AirplaneUsecase) error) error {
     if err != nil { return err }
     defer func(){ _ = db.Close() }()
     repo := newAirplaneRepoF(db)
-    uc := NewAirplaneUsecase(repo)
+    uc := NewAirplaneUsecase(repo).WithTypes(newAircraftTypeRepo(db)).WithReaccommodation(newAirplaneScheduleRepo(db), newAirplaneBookingRepo(db))
     return run(uc)
 }
 
@@ -44,40 +51,50 @@ func NewAirplaneUsecase(r domain.AirplaneRepository) *usecase.AirplaneUsecase {
 }
 
 func newAirplaneCreateCmd() *cobra.Command {
-    var code string
+    var code, typeCode string
     var seats int
     cmd := &cobra.Command{
         Use: "create",
         Short: "Create an airplane",
         RunE: func(cmd *cobra.Command, args []string) error {
+            if typeCode == "" && seats == 0 { return fmt.Errorf("either --type or --seats is required") }
             return withAirplaneUsecase(func(u *usecase.AirplaneUsecase) error {
-                a, err := u.Create(context.Background(), code, seats)
+                a, err := u.Create(context.Background(), code, typeCode, seats)
                 if err != nil { return err }
+                if a.TypeName != "" {
+                    fmt.Printf("created airplane %s, %s (%d seats)\n", a.Code, a.TypeName, a.SeatCapacity)
+                    return nil
+                }
                 fmt.Printf("created airplane %s (%d seats)\n", a.Code, a.SeatCapacity)
                 return nil
             })
         },
     }
-    cmd.Flags().StringVar(&code, "code", "", "airplane code")
-    cmd.Flags().IntVar(&seats, "seats", 0, "seat capacity")
+    cmd.Flags().StringVar(&code, "code", "", "airplane registration, e.g. PK-GQA")
+    cmd.Flags().StringVar(&typeCode, "type", "", "ICAO aircraft type code from the catalog, e.g. A320")
+    cmd.Flags().IntVar(&seats, "seats", 0, "seat capacity (defaults to the type's seat map)")
     _ = cmd.MarkFlagRequired("code")
-    _ = cmd.MarkFlagRequired("seats")
     return cmd
 }
 
 func newAirplaneListCmd() *cobra.Command {
     var limit, offset int
+    var includeArchived bool
     cmd := &cobra.Command{
         Use: "list",
         Short: "List airplanes",
         RunE: func(cmd *cobra.Command, args []string) error {
             return withAirplaneUsecase(func(u *usecase.AirplaneUsecase) error {
-                items, err := u.List(context.Background(), limit, offset)
+                items, err := u.List(context.Background(), limit, offset, includeArchived)
                 if err != nil { return err }
                 tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
-                _, _ = fmt.Fprintln(tw, "CODE\tSEATS")
+                header := "CODE\tTYPE\tSEATS"
+                if includeArchived { header += "\tARCHIVED" }
+                _, _ = fmt.Fprintln(tw, header)
                 for _, a := range items {
-                    _, _ = fmt.Fprintf(tw, "%s\t%d\n", a.Code, a.SeatCapacity)
+                    row := fmt.Sprintf("%s\t%s\t%d", a.Code, dashIfEmpty(a.TypeName), a.SeatCapacity)
+                    if includeArchived { row += "\t" + archivedColumn(a.ArchivedAt) }
+                    _, _ = fmt.Fprintln(tw, row)
                 }
                 return tw.Flush()
             })
@@ -85,44 +102,74 @@ func newAirplaneListCmd() *cobra.Command {
     }
     cmd.Flags().IntVar(&limit, "limit", 50, "max items")
     cmd.Flags().IntVar(&offset, "offset", 0, "offset")
+    cmd.Flags().BoolVar(&includeArchived, "include-archived", false, includeArchivedUsage)
     return cmd
 }
 
 func newAirplaneUpdateCmd() *cobra.Command {
     var code string
     var seats int
+    var force bool
     cmd := &cobra.Command{
         Use: "update",
         Short: "Update airplane seat capacity",
         RunE: func(cmd *cobra.Command, args []string) error {
             return withAirplaneUsecase(func(u *usecase.AirplaneUsecase) error {
-                if err := u.UpdateSeats(context.Background(), code, seats); err != nil { return err }
+                change, err := u.UpdateSeats(context.Background(), code, seats, force)
+                if err != nil { return err }
                 fmt.Printf("updated airplane %s seats -> %d\n", code, seats)
-                return nil
+                return writeSeatChange(change)
             })
         },
     }
     cmd.Flags().StringVar(&code, "code", "", "airplane code")
     cmd.Flags().IntVar(&seats, "seats", 0, "seat capacity")
+    cmd.Flags().BoolVar(&force, "force", false, "apply even when future flights have more bookings than seats, and report them for reaccommodation")
     _ = cmd.MarkFlagRequired("code")
     _ = cmd.MarkFlagRequired("seats")
     return cmd
 }
 
 func newAirplaneDeleteCmd() *cobra.Command {
+    var purge, cascade, yes bool
     cmd := &cobra.Command{
         Use:   "delete <code>",
-        Short: "Delete an airplane by code",
+        Short: "Archive an airplane by code, or with --purge delete it; purging is refused while flights use it unless --cascade",
         Args:  cobra.ExactArgs(1),
         RunE: func(cmd *cobra.Command, args []string) error {
+            if err := requirePurge(purge, cascade); err != nil { return err }
             code := args[0]
             return withAirplaneUsecase(func(u *usecase.AirplaneUsecase) error {
-                if err := u.Delete(context.Background(), code); err != nil { return err }
-                fmt.Printf("deleted airplane %s\n", code)
-                return nil
+                if !purge {
+                    if err := u.Archive(context.Background(), code); err != nil { return err }
+                    printArchived(domain.EntityAirplane, code)
+                    return nil
+                }
+                return deleteWith(domain.EntityAirplane, code, cascade, yes, func(d *domain.Dependents) (domain.Dependents, error) {
+                    return u.Delete(context.Background(), code, d)
+                })
             })
         },
     }
+    cmd.Flags().BoolVar(&purge, "purge", false, purgeUsage)
+    cmd.Flags().BoolVar(&cascade, "cascade", false, purgeCascadeUsage)
+    cmd.Flags().BoolVar(&yes, "yes", false, yesUsage)
     return cmd
 }
 
+func newAirplaneRestoreCmd() *cobra.Command {
+    return &cobra.Command{
+        Use:   "restore <code>",
+        Short: "Restore an archived airplane",
+        Args:  cobra.ExactArgs(1),
+        RunE: func(cmd *cobra.Command, args []string) error {
+            code := args[0]
+            return withAirplaneUsecase(func(u *usecase.AirplaneUsecase) error {
+                if err := u.Restore(context.Background(), code); err != nil { return err }
+                fmt.Printf("restored airplane %s\n", code)
+                return nil
+            })
+        },
+    }
+}
+
@@ -2,8 +2,11 @@ package cli
 
 import (
     "context"
+    "errors"
     "os"
+    "strings"
     "testing"
+    "time"
 
     "github.com/ambiyansyah-risyal/flight-booking/internal/domain"
     "github.com/jmoiron/sqlx"
@@ -11,12 +14,23 @@ import (
     "fmt"
 )
 
-type fakePlaneRepo struct{ data map[string]int }
+type fakePlaneRepo struct{
+    data map[string]int
+    archived map[string]bool
+    // overbooked flights refuse a resize unless it is forced.
+    overbooked []domain.CapacityError
+}
 func (f *fakePlaneRepo) Create(ctx context.Context, a *domain.Airplane) error { f.data[a.Code]=a.SeatCapacity; return nil }
 func (f *fakePlaneRepo) GetByCode(ctx context.Context, code string) (*domain.Airplane, error) { if s,ok:=f.data[code]; ok { return &domain.Airplane{Code:code, SeatCapacity:s}, nil }; return nil, domain.ErrAirplaneNotFound }
-func (f *fakePlaneRepo) List(ctx context.Context, limit, offset int) ([]domain.Airplane, error) { out:=[]domain.Airplane{}; for k,v:=range f.data { out=append(out, domain.Airplane{Code:k, SeatCapacity:v})}; return out, nil }
-func (f *fakePlaneRepo) UpdateSeats(ctx context.Context, code string, seats int) error { if _,ok:=f.data[code]; !ok { return domain.ErrAirplaneNotFound }; f.data[code]=seats; return nil }
-func (f *fakePlaneRepo) Delete(ctx context.Context, code string) error { if _,ok:=f.data[code]; !ok { return domain.ErrAirplaneNotFound }; delete(f.data, code); return nil }
+func (f *fakePlaneRepo) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airplane, error) { out:=[]domain.Airplane{}; for k,v:=range f.data { if f.archived[k] && !includeArchived { continue }; out=append(out, domain.Airplane{Code:k, SeatCapacity:v})}; return out, nil }
+func (f *fakePlaneRepo) SetArchived(ctx context.Context, code string, archived bool) error { if _,ok:=f.data[code]; !ok { return domain.ErrAirplaneNotFound }; if f.archived == nil { f.archived = map[string]bool{} }; f.archived[code]=archived; return nil }
+func (f *fakePlaneRepo) UpdateSeats(ctx context.Context, code string, seats int, from time.Time, force bool) (*domain.CapacityChange, error) {
+    if _,ok:=f.data[code]; !ok { return nil, domain.ErrAirplaneNotFound }
+    if len(f.overbooked) > 0 && !force { return nil, &domain.FleetCapacityError{AirplaneCode: code, Capacity: seats, Flights: f.overbooked} }
+    f.data[code]=seats
+    return &domain.CapacityChange{Overbooked: f.overbooked}, nil
+}
+func (f *fakePlaneRepo) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) { if _,ok:=f.data[code]; !ok { return domain.Dependents{}, domain.ErrAirplaneNotFound }; delete(f.data, code); return domain.Dependents{}, nil }
 
 func TestAirplaneCLI_Flow(t *testing.T) {
     oldDB, oldRepo := newAirplaneDB, newAirplaneRepoF
@@ -41,6 +55,21 @@ func TestAirplaneCLI_Flow(t *testing.T) {
 
     os.Args = []string{"flight-booking", "airplane", "delete", "A320"}
     if err := Execute(); err != nil { t.Fatalf("delete: %v", err) }
+    if !r.archived["A320"] { t.Fatalf("delete should archive the airplane") }
+    os.Args = []string{"flight-booking", "airplane", "list"}
+    out := captureOutput(func(){ _ = Execute() })
+    if strings.Contains(out, "A320") { t.Fatalf("archived airplane listed: %q", out) }
+    os.Args = []string{"flight-booking", "airplane", "list", "--include-archived"}
+    out = captureOutput(func(){ _ = Execute() })
+    if !strings.Contains(out, "A320") { t.Fatalf("expected archived airplane listed: %q", out) }
+
+    os.Args = []string{"flight-booking", "airplane", "restore", "A320"}
+    if err := Execute(); err != nil { t.Fatalf("restore: %v", err) }
+    os.Args = []string{"flight-booking", "airplane", "restore", "NONE"}
+    if err := Execute(); err != domain.ErrAirplaneNotFound { t.Fatalf("want not found, got %v", err) }
+    os.Args = []string{"flight-booking", "airplane", "delete", "A320", "--purge"}
+    if err := Execute(); err != nil { t.Fatalf("purge: %v", err) }
+    if _, ok := r.data["A320"]; ok { t.Fatalf("airplane not purged") }
 }
 
 func TestAirplaneCLI_OpenError(t *testing.T) {
@@ -74,3 +103,90 @@ func TestAirplaneCLI_DeleteNotFound(t *testing.T) {
     os.Args = []string{"flight-booking", "airplane", "delete", "NONE"}
     if err := Execute(); err == nil { t.Fatalf("expected not found error") }
 }
+
+func TestAirplaneCLI_UpdateOverbooked(t *testing.T) {
+    oldDB, oldRepo, oldSchedRepo, oldBookingRepo := newAirplaneDB, newAirplaneRepoF, newAirplaneScheduleRepo, newAirplaneBookingRepo
+    t.Cleanup(func(){ newAirplaneDB=oldDB; newAirplaneRepoF=oldRepo; newAirplaneScheduleRepo=oldSchedRepo; newAirplaneBookingRepo=oldBookingRepo })
+    newAirplaneDB = func(dsn string) (*sqlx.DB, error) { db,_,_ := sqlmock.New(); return sqlx.NewDb(db, "pgx"), nil }
+    r := &fakePlaneRepo{data: map[string]int{"A320":180}, overbooked: []domain.CapacityError{{ScheduleID: 1, Capacity: 1, Booked: 2}}}
+    newAirplaneRepoF = func(db *sqlx.DB) domain.AirplaneRepository { return r }
+    schedules := &fakeScheduleRepoCLI{items: map[int64]domain.FlightSchedule{1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02"}}}
+    newAirplaneScheduleRepo = func(db *sqlx.DB) domain.FlightScheduleRepository { return schedules }
+    bookings := newFakeBookingRepoCLI()
+    bookings.items["K7QX2M"] = domain.Booking{ID: 1, Reference: "K7QX2M", ScheduleID: 1, PassengerName: "Alice", SeatNumber: 1, Status: domain.BookingStatusConfirmed}
+    bookings.items["P4RT8N"] = domain.Booking{ID: 2, Reference: "P4RT8N", ScheduleID: 1, PassengerName: "Bob", SeatNumber: 2, Status: domain.BookingStatusConfirmed}
+    newAirplaneBookingRepo = func(db *sqlx.DB) domain.BookingRepository { return bookings }
+    t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+    os.Args = []string{"flight-booking", "airplane", "update", "--code", "A320", "--seats", "1"}
+    if err := Execute(); !errors.Is(err, domain.ErrCapacityBelowBookings) || !strings.Contains(err.Error(), "schedule 1 (2 booked)") { t.Fatalf("want refusal listing the flight, got %v", err) }
+    if r.data["A320"] != 180 { t.Fatalf("a refused update must keep the seats") }
+
+    os.Args = []string{"flight-booking", "airplane", "update", "--code", "A320", "--seats", "1", "--force"}
+    var err error
+    out := captureOutput(func(){ err = Execute() })
+    if err != nil { t.Fatalf("forced update: %v", err) }
+    if !strings.Contains(out, "1 future flight(s) have more bookings than seats") || !strings.Contains(out, "P4RT8N") || strings.Contains(out, "K7QX2M") {
+        t.Fatalf("expected the newer booking offered reaccommodation, got %q", out)
+    }
+}
+
+type fakeAircraftTypeRepoCLI struct{ items map[string]domain.AircraftType }
+func (f *fakeAircraftTypeRepoCLI) Create(ctx context.Context, t *domain.AircraftType) error { if _,ok:=f.items[t.ICAOCode]; ok { return domain.ErrAircraftTypeExists }; f.items[t.ICAOCode]=*t; return nil }
+func (f *fakeAircraftTypeRepoCLI) GetByCode(ctx context.Context, code string) (*domain.AircraftType, error) { if t,ok:=f.items[code]; ok { return &t, nil }; return nil, domain.ErrAircraftTypeNotFound }
+func (f *fakeAircraftTypeRepoCLI) List(ctx context.Context, limit, offset int) ([]domain.AircraftType, error) { out:=[]domain.AircraftType{}; for _,t:=range f.items { out=append(out, t) }; return out, nil }
+func (f *fakeAircraftTypeRepoCLI) Delete(ctx context.Context, code string) error { if _,ok:=f.items[code]; !ok { return domain.ErrAircraftTypeNotFound }; delete(f.items, code); return nil }
+
+func TestAirplaneCLI_TypesAndRegistrations(t *testing.T) {
+    oldDB, oldRepo, oldTypeRepo := newAirplaneDB, newAirplaneRepoF, newAircraftTypeRepo
+    t.Cleanup(func(){ newAirplaneDB=oldDB; newAirplaneRepoF=oldRepo; newAircraftTypeRepo=oldTypeRepo })
+    newAirplaneDB = func(dsn string) (*sqlx.DB, error) { db,_,_ := sqlmock.New(); return sqlx.NewDb(db, "pgx"), nil }
+    r := &fakePlaneRepo{data: map[string]int{}}
+    newAirplaneRepoF = func(db *sqlx.DB) domain.AirplaneRepository { return r }
+    types := &fakeAircraftTypeRepoCLI{items: map[string]domain.AircraftType{}}
+    newAircraftTypeRepo = func(db *sqlx.DB) domain.AircraftTypeRepository { return types }
+    t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+    os.Args = []string{"flight-booking", "airplane", "type", "create", "--icao", "a320", "--iata", "320", "--manufacturer", "Airbus", "--model", "A320-200", "--range", "6100", "--rows", "30", "--layout", "3-3"}
+    out := captureOutput(func(){ if err := Execute(); err != nil { t.Fatalf("type create: %v", err) } })
+    if !strings.Contains(out, "created aircraft type A320 (320): Airbus A320-200, 6100 km, 30x3-3 seat map (180 seats)") { t.Fatalf("unexpected type create output %q", out) }
+
+    os.Args = []string{"flight-booking", "airplane", "type", "list"}
+    out = captureOutput(func(){ if err := Execute(); err != nil { t.Fatalf("type list: %v", err) } })
+    if !strings.Contains(out, "Airbus A320-200") { t.Fatalf("unexpected type list %q", out) }
+
+    os.Args = []string{"flight-booking", "airplane", "create", "--code", "pk-gqa", "--type", "A320"}
+    out = captureOutput(func(){ if err := Execute(); err != nil { t.Fatalf("create: %v", err) } })
+    if !strings.Contains(out, "created airplane PK-GQA, Airbus A320-200 (180 seats)") || r.data["PK-GQA"] != 180 { t.Fatalf("unexpected create output %q", out) }
+
+    os.Args = []string{"flight-booking", "airplane", "create", "--code", "PK-GQB"}
+    if err := Execute(); err == nil || !strings.Contains(err.Error(), "--type or --seats") { t.Fatalf("want missing type or seats, got %v", err) }
+
+    os.Args = []string{"flight-booking", "airplane", "type", "delete", "A320"}
+    if err := Execute(); err != nil { t.Fatalf("type delete: %v", err) }
+}
+
+func TestAirplaneCLI_Maintenance(t *testing.T) {
+    oldDB, oldRepo, oldMaintenanceRepo := newAirplaneDB, newAirplaneRepoF, newMaintenanceRepo
+    t.Cleanup(func(){ newAirplaneDB=oldDB; newAirplaneRepoF=oldRepo; newMaintenanceRepo=oldMaintenanceRepo })
+    newAirplaneDB = func(dsn string) (*sqlx.DB, error) { db,_,_ := sqlmock.New(); return sqlx.NewDb(db, "pgx"), nil }
+    newAirplaneRepoF = func(db *sqlx.DB) domain.AirplaneRepository { return &fakePlaneRepo{data: map[string]int{"A320":180}} }
+    booked := domain.FlightSchedule{ID: 7, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2099-01-02", DepartureAt: time.Date(2099, 1, 2, 8, 0, 0, 0, time.UTC), OriginTimeZone: "UTC", DestinationTimeZone: "UTC"}
+    m := &fakeMaintenanceRepoCLI{conflicts: []domain.MaintenanceConflict{{Schedule: booked, Booked: 3}}}
+    newMaintenanceRepo = func(db *sqlx.DB) domain.MaintenanceRepository { return m }
+    t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+    os.Args = []string{"flight-booking", "airplane", "maintenance", "add", "--code", "a320", "--from", "2099-01-02", "--to", "2099-01-01"}
+    if err := Execute(); !errors.Is(err, domain.ErrInvalidMaintenanceWindow) { t.Fatalf("want invalid window, got %v", err) }
+
+    os.Args = []string{"flight-booking", "airplane", "maintenance", "add", "--code", "a320", "--from", "2099-01-02", "--to", "2099-01-04T12:00", "--reason", "C-check"}
+    out := captureOutput(func(){ if err := Execute(); err != nil { t.Fatalf("add: %v", err) } })
+    if !strings.Contains(out, "added maintenance 1 for airplane A320 from 2099-01-02 00:00 UTC to 2099-01-04 12:00 UTC") || !strings.Contains(out, "1 booked flight(s) fall in this window") {
+        t.Fatalf("unexpected add output %q", out)
+    }
+    if !strings.Contains(out, "RT1") || !strings.Contains(out, "2099-01-02 08:00 UTC") { t.Fatalf("expected the booked flight listed, got %q", out) }
+
+    os.Args = []string{"flight-booking", "airplane", "maintenance", "list", "--code", "A320"}
+    out = captureOutput(func(){ if err := Execute(); err != nil { t.Fatalf("list: %v", err) } })
+    if !strings.Contains(out, "C-check") { t.Fatalf("expected the window listed, got %q", out) }
+}
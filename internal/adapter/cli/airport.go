@@ -5,6 +5,7 @@ import (
     "fmt"
     "os"
     "text/tabwriter"
+    "time"
 
     "github.com/ambiyansyah-risyal/flight-booking/internal/adapter/repository/sqlx"
     "github.com/ambiyansyah-risyal/flight-booking/internal/domain"
@@ -21,6 +22,8 @@ func newAirportCmd() *cobra.Command {
     cmd.AddCommand(newAirportListCmd())
     cmd.AddCommand(newAirportUpdateCmd())
     cmd.AddCommand(newAirportDeleteCmd())
+    cmd.AddCommand(newAirportRestoreCmd())
+    cmd.AddCommand(newAirportBoardCmd())
     return cmd
 }
 
@@ -42,21 +45,36 @@ func withAirportUsecase(run func(u *usecase.AirportUsecase) error) error {
 }
 
 func newAirportCreateCmd() *cobra.Command {
-    var code, city string
+    var code, city, timeZone string
+    var minConnection int
+    var lat, lon float64
     cmd := &cobra.Command{
         Use:   "create",
         Short: "Create an airport",
         RunE: func(cmd *cobra.Command, args []string) error {
+            if err := requirePosition(cmd); err != nil { return err }
             return withAirportUsecase(func(u *usecase.AirportUsecase) error {
-                a, err := u.Create(context.Background(), code, city)
+                a, err := u.Create(context.Background(), code, city, timeZone)
                 if err != nil { return err }
-                fmt.Printf("created airport %s (%s)\n", a.Code, a.City)
+                fmt.Printf("created airport %s (%s, %s)\n", a.Code, a.City, a.TimeZone)
+                if cmd.Flags().Changed("mct") {
+                    if err := u.SetMinConnection(context.Background(), a.Code, minConnection); err != nil { return err }
+                    fmt.Printf("minimum connection time at %s: %d min\n", a.Code, minConnection)
+                }
+                if cmd.Flags().Changed("lat") {
+                    if err := u.SetPosition(context.Background(), a.Code, lat, lon); err != nil { return err }
+                    fmt.Printf("position of %s: %.4f, %.4f\n", a.Code, lat, lon)
+                }
                 return nil
             })
         },
     }
     cmd.Flags().StringVar(&code, "code", "", "airport code (e.g., CGK)")
     cmd.Flags().StringVar(&city, "city", "", "city name")
+    cmd.Flags().StringVar(&timeZone, "tz", domain.DefaultTimeZone, "IANA time zone (e.g., Asia/Jakarta)")
+    cmd.Flags().IntVar(&minConnection, "mct", 0, "minimum connection time in minutes (0 uses the global default)")
+    cmd.Flags().Float64Var(&lat, "lat", 0, "latitude in decimal degrees (requires --lon)")
+    cmd.Flags().Float64Var(&lon, "lon", 0, "longitude in decimal degrees (requires --lat)")
     _ = cmd.MarkFlagRequired("code")
     _ = cmd.MarkFlagRequired("city")
     return cmd
@@ -64,17 +82,26 @@ func newAirportCreateCmd() *cobra.Command {
 
 func newAirportListCmd() *cobra.Command {
     var limit, offset int
+    var includeArchived bool
     cmd := &cobra.Command{
         Use:   "list",
         Short: "List airports",
         RunE: func(cmd *cobra.Command, args []string) error {
             return withAirportUsecase(func(u *usecase.AirportUsecase) error {
-                items, err := u.List(context.Background(), limit, offset)
+                items, err := u.List(context.Background(), limit, offset, includeArchived)
                 if err != nil { return err }
                 tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
-                _, _ = fmt.Fprintln(tw, "CODE\tCITY")
+                header := "CODE\tCITY\tTZ\tMCT\tPOSITION"
+                if includeArchived { header += "\tARCHIVED" }
+                _, _ = fmt.Fprintln(tw, header)
                 for _, a := range items {
-                    _, _ = fmt.Fprintf(tw, "%s\t%s\n", a.Code, a.City)
+                    mct := "default"
+                    if a.MinConnectionMinutes > 0 { mct = fmt.Sprintf("%dm", a.MinConnectionMinutes) }
+                    position := "-"
+                    if a.Position != nil { position = fmt.Sprintf("%.4f,%.4f", a.Position.Latitude, a.Position.Longitude) }
+                    row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", a.Code, a.City, a.TimeZone, mct, position)
+                    if includeArchived { row += "\t" + archivedColumn(a.ArchivedAt) }
+                    _, _ = fmt.Fprintln(tw, row)
                 }
                 return tw.Flush()
             })
@@ -82,42 +109,160 @@ func newAirportListCmd() *cobra.Command {
     }
     cmd.Flags().IntVar(&limit, "limit", 50, "max items to list")
     cmd.Flags().IntVar(&offset, "offset", 0, "items to skip")
+    cmd.Flags().BoolVar(&includeArchived, "include-archived", false, includeArchivedUsage)
     return cmd
 }
 
 func newAirportUpdateCmd() *cobra.Command {
-    var code, city string
+    var code, city, timeZone string
+    var minConnection int
+    var lat, lon float64
     cmd := &cobra.Command{
         Use:   "update",
-        Short: "Update an airport city by code",
+        Short: "Update an airport city, time zone, minimum connection time or position by code",
         RunE: func(cmd *cobra.Command, args []string) error {
+            if !cmd.Flags().Changed("city") && !cmd.Flags().Changed("tz") && !cmd.Flags().Changed("mct") && !cmd.Flags().Changed("lat") && !cmd.Flags().Changed("lon") {
+                return fmt.Errorf("at least one of --city, --tz, --mct or --lat/--lon is required")
+            }
+            if err := requirePosition(cmd); err != nil { return err }
             return withAirportUsecase(func(u *usecase.AirportUsecase) error {
-                if err := u.Update(context.Background(), code, city); err != nil { return err }
-                fmt.Printf("updated airport %s -> %s\n", code, city)
+                if cmd.Flags().Changed("city") {
+                    if err := u.Update(context.Background(), code, city); err != nil { return err }
+                    fmt.Printf("updated airport %s -> %s\n", code, city)
+                }
+                if cmd.Flags().Changed("tz") {
+                    if err := u.SetTimeZone(context.Background(), code, timeZone); err != nil { return err }
+                    fmt.Printf("updated airport %s time zone -> %s\n", code, timeZone)
+                }
+                if cmd.Flags().Changed("mct") {
+                    if err := u.SetMinConnection(context.Background(), code, minConnection); err != nil { return err }
+                    fmt.Printf("updated airport %s minimum connection time -> %d min\n", code, minConnection)
+                }
+                if cmd.Flags().Changed("lat") {
+                    if err := u.SetPosition(context.Background(), code, lat, lon); err != nil { return err }
+                    fmt.Printf("updated airport %s position -> %.4f, %.4f\n", code, lat, lon)
+                }
                 return nil
             })
         },
     }
     cmd.Flags().StringVar(&code, "code", "", "airport code")
     cmd.Flags().StringVar(&city, "city", "", "new city name")
+    cmd.Flags().StringVar(&timeZone, "tz", "", "new IANA time zone")
+    cmd.Flags().IntVar(&minConnection, "mct", 0, "minimum connection time in minutes (0 uses the global default)")
+    cmd.Flags().Float64Var(&lat, "lat", 0, "latitude in decimal degrees (requires --lon)")
+    cmd.Flags().Float64Var(&lon, "lon", 0, "longitude in decimal degrees (requires --lat)")
     _ = cmd.MarkFlagRequired("code")
-    _ = cmd.MarkFlagRequired("city")
     return cmd
 }
 
+// requirePosition rejects a latitude given without a longitude or the reverse.
+func requirePosition(cmd *cobra.Command) error {
+    if cmd.Flags().Changed("lat") != cmd.Flags().Changed("lon") {
+        return fmt.Errorf("--lat and --lon must be given together")
+    }
+    return nil
+}
+
 func newAirportDeleteCmd() *cobra.Command {
+    var purge, cascade, yes bool
     cmd := &cobra.Command{
         Use:   "delete <code>",
-        Short: "Delete an airport by code",
+        Short: "Archive an airport by code, or with --purge delete it; purging is refused while routes use it unless --cascade",
+        Args:  cobra.ExactArgs(1),
+        RunE: func(cmd *cobra.Command, args []string) error {
+            if err := requirePurge(purge, cascade); err != nil { return err }
+            code := args[0]
+            return withAirportUsecase(func(u *usecase.AirportUsecase) error {
+                if !purge {
+                    if err := u.Archive(context.Background(), code); err != nil { return err }
+                    printArchived(domain.EntityAirport, code)
+                    return nil
+                }
+                return deleteWith(domain.EntityAirport, code, cascade, yes, func(d *domain.Dependents) (domain.Dependents, error) {
+                    return u.Delete(context.Background(), code, d)
+                })
+            })
+        },
+    }
+    cmd.Flags().BoolVar(&purge, "purge", false, purgeUsage)
+    cmd.Flags().BoolVar(&cascade, "cascade", false, purgeCascadeUsage)
+    cmd.Flags().BoolVar(&yes, "yes", false, yesUsage)
+    return cmd
+}
+
+func newAirportRestoreCmd() *cobra.Command {
+    return &cobra.Command{
+        Use:   "restore <code>",
+        Short: "Restore an archived airport",
         Args:  cobra.ExactArgs(1),
         RunE: func(cmd *cobra.Command, args []string) error {
             code := args[0]
             return withAirportUsecase(func(u *usecase.AirportUsecase) error {
-                if err := u.Delete(context.Background(), code); err != nil { return err }
-                fmt.Printf("deleted airport %s\n", code)
+                if err := u.Restore(context.Background(), code); err != nil { return err }
+                fmt.Printf("restored airport %s\n", code)
                 return nil
             })
         },
     }
+}
+
+func newAirportBoardCmd() *cobra.Command {
+    var date string
+    var arrivals bool
+    cmd := &cobra.Command{
+        Use:   "board <code>",
+        Short: "Show an airport's departures, or arrivals, on a local date",
+        Args:  cobra.ExactArgs(1),
+        RunE: func(cmd *cobra.Command, args []string) error {
+            code := args[0]
+            return withScheduleUsecase(func(uc *usecase.ScheduleUsecase) error {
+                board, err := uc.Board(context.Background(), code, date, arrivals)
+                if err != nil { return err }
+                return writeAirportBoard(board)
+            })
+        },
+    }
+    cmd.Flags().StringVar(&date, "date", "", "local date at the airport (YYYY-MM-DD)")
+    cmd.Flags().BoolVar(&arrivals, "arrivals", false, "show arrivals instead of departures")
+    _ = cmd.MarkFlagRequired("date")
     return cmd
 }
+
+// writeAirportBoard prints a board in the airport's local time. Times falling
+// on another day than the board's are marked with the day offset.
+func writeAirportBoard(board *usecase.AirportBoard) error {
+    kind, other := "departures", "TO"
+    if board.Arrivals { kind, other = "arrivals", "FROM" }
+    fmt.Printf("%s %s on %s (%s)\n", board.Airport.Code, kind, board.Date, board.Airport.Location())
+    if len(board.Flights) == 0 {
+        fmt.Printf("no %s\n", kind)
+        return nil
+    }
+    loc := board.Airport.Location()
+    tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+    _, _ = fmt.Fprintf(tw, "TIME\tESTIMATED\tSCHEDULE\tROUTE\t%s\tAIRPLANE\tSTATUS\tDELAY\tGATE\tLOAD\n", other)
+    for _, f := range board.Flights {
+        s := f.Schedule
+        planned, estimated, airport, gate := s.DepartureAt, s.EstimatedDeparture(), f.DestinationCode, s.DepartureGate
+        if board.Arrivals {
+            planned, estimated, airport, gate = s.ArrivalAt, s.EstimatedArrival(), f.OriginCode, s.ArrivalGate
+        }
+        delay := "-"
+        if s.DelayMinutes > 0 { delay = fmt.Sprintf("%dm", s.DelayMinutes) }
+        _, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%.0f%%\n", boardClock(planned.In(loc), board.Date), boardClock(estimated.In(loc), board.Date), s.ID, s.RouteCode, airport, s.AirplaneCode, f.Remark(), delay, dashIfEmpty(gate), f.LoadFactor()*100)
+    }
+    return tw.Flush()
+}
+
+// boardClock renders a local time as HH:MM, followed by its offset in days
+// from the board's date when it falls on another day.
+func boardClock(t time.Time, date string) string {
+    day, err := time.Parse("2006-01-02", date)
+    if err != nil { return t.Format("15:04") }
+    local := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+    if days := int(local.Sub(day).Hours() / 24); days != 0 {
+        return fmt.Sprintf("%s %+dd", t.Format("15:04"), days)
+    }
+    return t.Format("15:04")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"text/tabwriter"
 
 	sqlxrepo "github.com/ambiyansyah-risyal/flight-booking/internal/adapter/repository/sqlx"
@@ -17,18 +18,28 @@ import (
 func newBookingCmd() *cobra.Command {
 	cmd := &cobra.Command{Use: "booking", Short: "Manage direct flight bookings"}
 	cmd.AddCommand(newBookingSearchCmd())
+	cmd.AddCommand(newBookingCalendarCmd())
 	cmd.AddCommand(newBookingCreateCmd())
 	cmd.AddCommand(newBookingGetCmd())
 	cmd.AddCommand(newBookingListCmd())
+	cmd.AddCommand(newBookingConfirmCmd())
+	cmd.AddCommand(newBookingCancelCmd())
+	cmd.AddCommand(newBookingTripCmd())
 	return cmd
 }
 
 var (
-	newBookingDB           = func(dsn string) (*sqlx.DB, error) { return sqlxrepo.New(dsn) }
-	newBookingRepo         = func(db *sqlx.DB) domain.BookingRepository { return sqlxrepo.NewBookingRepository(db) }
-	newBookingScheduleRepo = func(db *sqlx.DB) domain.FlightScheduleRepository { return sqlxrepo.NewScheduleRepository(db) }
-	newBookingRouteRepo    = func(db *sqlx.DB) domain.RouteRepository { return sqlxrepo.NewRouteRepository(db) }
-	newBookingAirplaneRepo = func(db *sqlx.DB) domain.AirplaneRepository { return sqlxrepo.NewAirplaneRepository(db) }
+	newBookingDB               = func(dsn string) (*sqlx.DB, error) { return sqlxrepo.New(dsn) }
+	newBookingRepo             = func(db *sqlx.DB) domain.BookingRepository { return sqlxrepo.NewBookingRepository(db) }
+	newBookingScheduleRepo     = func(db *sqlx.DB) domain.FlightScheduleRepository { return sqlxrepo.NewScheduleRepository(db) }
+	newBookingRouteRepo        = func(db *sqlx.DB) domain.RouteRepository { return sqlxrepo.NewRouteRepository(db) }
+	newBookingAirplaneRepo     = func(db *sqlx.DB) domain.AirplaneRepository { return sqlxrepo.NewAirplaneRepository(db) }
+	newBookingTicketRepo       = func(db *sqlx.DB) domain.TicketRepository { return sqlxrepo.NewTicketRepository(db) }
+	newBookingAirportRepo      = func(db *sqlx.DB) domain.AirportRepository { return sqlxrepo.NewAirportRepository(db) }
+	newBookingAvailabilityRepo = func(db *sqlx.DB) domain.AvailabilityRepository { return sqlxrepo.NewAvailabilityRepository(db) }
+	newBookingTripRepo         = func(db *sqlx.DB) domain.TripRepository { return sqlxrepo.NewTripRepository(db) }
+	newBookingFareRepo         = func(db *sqlx.DB) domain.FareRepository { return sqlxrepo.NewFareRepository(db) }
+	newBookingInventoryRepo    = func(db *sqlx.DB) domain.SeatInventoryRepository { return sqlxrepo.NewSeatInventoryRepository(db) }
 )
 
 func withBookingUsecase(run func(*usecase.BookingUsecase) error) error {
@@ -41,7 +52,27 @@ func withBookingUsecase(run func(*usecase.BookingUsecase) error) error {
 		return err
 	}
 	defer func() { _ = db.Close() }()
-	uc := usecase.NewBookingUsecase(newBookingRepo(db), newBookingScheduleRepo(db), newBookingRouteRepo(db), newBookingAirplaneRepo(db))
+	bookingRepo := newBookingRepo(db)
+	refs, err := usecase.NewReferenceGenerator(bookingRepo, domain.ReferenceFormat{
+		Prefix:   cfg.Booking.ReferencePrefix,
+		Length:   cfg.Booking.ReferenceLength,
+		Alphabet: cfg.Booking.ReferenceAlphabet,
+	})
+	if err != nil {
+		return err
+	}
+	uc := usecase.NewBookingUsecase(bookingRepo, newBookingScheduleRepo(db), newBookingRouteRepo(db), newBookingAirplaneRepo(db))
+	uc.WithReferenceGenerator(refs)
+	uc.WithTicketing(usecase.NewTicketUsecase(newBookingTicketRepo(db), bookingRepo, cfg.Ticketing.AirlinePrefix))
+	uc.WithAvailability(newBookingAvailabilityRepo(db))
+	uc.WithTrips(newBookingTripRepo(db))
+	uc.WithFares(newBookingFareRepo(db))
+	uc.WithInventory(newBookingInventoryRepo(db))
+	uc.WithConnectionPolicy(newBookingAirportRepo(db), domain.ConnectionPolicy{
+		MinConnection: cfg.Transit.MinConnection,
+		MaxLayover:    cfg.Transit.MaxLayover,
+		MaxCircuity:   cfg.Transit.MaxCircuity,
+	})
 	return run(uc)
 }
 
@@ -50,6 +81,9 @@ type OutputWriter interface {
 	WriteDirectFlightOptions(options []usecase.FlightOption) error
 	WriteTransitFlightOptions(options []usecase.TransitOption) error
 	WriteNoTransitMessage()
+	WriteItineraries(itineraries []usecase.Itinerary) error
+	WriteRoundTrips(options []usecase.RoundTripOption) error
+	WriteSearchTrace(trace *usecase.SearchTrace) error
 }
 
 // RealOutputWriter implements OutputWriter with actual output functionality
@@ -57,30 +91,45 @@ type RealOutputWriter struct{}
 
 func (r *RealOutputWriter) WriteDirectFlightOptions(options []usecase.FlightOption) error {
 	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
-	_, _ = fmt.Fprintln(tw, "SCHEDULE\tROUTE\tDATE\tAIRPLANE\tSEATS LEFT\tTOTAL SEATS")
+	_, _ = fmt.Fprintln(tw, "SCHEDULE\tROUTE\tDATE\tDEPARTS\tARRIVES\tAIRPLANE\tSEATS LEFT\tTOTAL SEATS\tFARE")
 	for _, opt := range options {
-		_, _ = fmt.Fprintf(tw, "%d\t%s->%s\t%s\t%s\t%d\t%d\n", opt.ScheduleID, opt.OriginCode, opt.DestinationCode, opt.DepartureDate, opt.AirplaneCode, opt.SeatsAvailable, opt.TotalSeats)
+		_, _ = fmt.Fprintf(tw, "%d\t%s->%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\n", opt.ScheduleID, opt.OriginCode, opt.DestinationCode, opt.DepartureDate, formatLocalTime(opt.DepartureTime), formatLocalTime(opt.ArrivalTime), formatAirplane(opt), opt.SeatsAvailable, opt.TotalSeats, formatFare(opt.Fare))
 	}
 	return tw.Flush()
 }
 
+// formatAirplane renders a flight's airplane with its aircraft type name when known.
+func formatAirplane(opt usecase.FlightOption) string {
+	if opt.AircraftType == "" {
+		return opt.AirplaneCode
+	}
+	return fmt.Sprintf("%s (%s)", opt.AirplaneCode, opt.AircraftType)
+}
+
 func (r *RealOutputWriter) WriteTransitFlightOptions(options []usecase.TransitOption) error {
 	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
-	_, _ = fmt.Fprintln(tw, "FIRST SCHEDULE\tFIRST ROUTE\tFIRST DATE\tFIRST AIRPLANE\tINTERMEDIATE\tSECOND SCHEDULE\tSECOND ROUTE\tSECOND DATE\tSECOND AIRPLANE\tSEATS LEFT")
+	_, _ = fmt.Fprintln(tw, "FIRST SCHEDULE\tFIRST ROUTE\tFIRST DATE\tFIRST DEPARTS\tFIRST ARRIVES\tFIRST AIRPLANE\tINTERMEDIATE\tSECOND SCHEDULE\tSECOND ROUTE\tSECOND DATE\tSECOND DEPARTS\tSECOND ARRIVES\tSECOND AIRPLANE\tLAYOVER\tJOURNEY\tSEATS LEFT\tFARE")
 	for _, opt := range options {
-		_, _ = fmt.Fprintf(tw, "%d\t%s->%s\t%s\t%s\t%s\t%d\t%s->%s\t%s\t%s\t%d\n", 
-			opt.FirstLeg.ScheduleID, 
-			opt.FirstLeg.OriginCode, 
-			opt.FirstLeg.DestinationCode, 
-			opt.FirstLeg.DepartureDate, 
-			opt.FirstLeg.AirplaneCode,
+		_, _ = fmt.Fprintf(tw, "%d\t%s->%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s->%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			opt.FirstLeg.ScheduleID,
+			opt.FirstLeg.OriginCode,
+			opt.FirstLeg.DestinationCode,
+			opt.FirstLeg.DepartureDate,
+			formatLocalTime(opt.FirstLeg.DepartureTime),
+			formatLocalTime(opt.FirstLeg.ArrivalTime),
+			formatAirplane(opt.FirstLeg),
 			opt.Intermediate,
 			opt.SecondLeg.ScheduleID,
 			opt.SecondLeg.OriginCode,
 			opt.SecondLeg.DestinationCode,
 			opt.SecondLeg.DepartureDate,
-			opt.SecondLeg.AirplaneCode,
-			opt.TotalAvailable)
+			formatLocalTime(opt.SecondLeg.DepartureTime),
+			formatLocalTime(opt.SecondLeg.ArrivalTime),
+			formatAirplane(opt.SecondLeg),
+			formatDuration(opt.Layover),
+			formatDuration(opt.TotalDuration),
+			opt.TotalAvailable,
+			opt.TotalFare)
 	}
 	return tw.Flush()
 }
@@ -89,68 +138,253 @@ func (r *RealOutputWriter) WriteNoTransitMessage() {
 	fmt.Println("No connecting flights found")
 }
 
+func (r *RealOutputWriter) WriteItineraries(itineraries []usecase.Itinerary) error {
+	if len(itineraries) == 0 {
+		fmt.Println("No itineraries found")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "OPTION\tSTOPS\tLEG\tSCHEDULE\tROUTE\tDEPARTS\tARRIVES\tAIRPLANE\tLAYOVER\tJOURNEY\tSEATS LEFT\tFARE")
+	for i, it := range itineraries {
+		for j, leg := range it.Legs {
+			// Layover is the wait before this leg; journey, seats and fare describe the whole itinerary.
+			layover, journey, seats, fare := "-", "", "", ""
+			if j > 0 {
+				layover = formatDuration(it.Layovers[j-1])
+			}
+			if j == 0 {
+				journey, seats, fare = formatDuration(it.TotalDuration), fmt.Sprint(it.TotalAvailable), it.TotalFare.String()
+			}
+			_, _ = fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%s->%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				i+1, it.Stops(), j+1, leg.ScheduleID, leg.OriginCode, leg.DestinationCode,
+				formatLocalTime(leg.DepartureTime), formatLocalTime(leg.ArrivalTime), formatAirplane(leg),
+				layover, journey, seats, fare)
+		}
+	}
+	return tw.Flush()
+}
+
+func (r *RealOutputWriter) WriteRoundTrips(options []usecase.RoundTripOption) error {
+	if len(options) == 0 {
+		fmt.Println("No round trips found")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "OUTBOUND\tOUT ROUTE\tOUT DEPARTS\tOUT ARRIVES\tRETURN\tRETURN ROUTE\tRETURN DEPARTS\tRETURN ARRIVES\tSEATS LEFT\tFARE")
+	for _, opt := range options {
+		seats := opt.Outbound.SeatsAvailable
+		if opt.Return.SeatsAvailable < seats {
+			seats = opt.Return.SeatsAvailable
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s->%s\t%s\t%s\t%d\t%s->%s\t%s\t%s\t%d\t%s\n",
+			opt.Outbound.ScheduleID, opt.Outbound.OriginCode, opt.Outbound.DestinationCode,
+			formatLocalTime(opt.Outbound.DepartureTime), formatLocalTime(opt.Outbound.ArrivalTime),
+			opt.Return.ScheduleID, opt.Return.OriginCode, opt.Return.DestinationCode,
+			formatLocalTime(opt.Return.DepartureTime), formatLocalTime(opt.Return.ArrivalTime),
+			seats, opt.Fare)
+	}
+	return tw.Flush()
+}
+
+func (r *RealOutputWriter) WriteSearchTrace(trace *usecase.SearchTrace) error {
+	fmt.Println("Search trace:")
+	if len(trace.Entries) == 0 {
+		fmt.Println("No candidate flights examined")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "LEG\tSCHEDULE\tROUTE\tDATE\tRESULT\tDETAIL")
+	for _, e := range trace.Entries {
+		schedule, result, detail := "-", "kept", dashIfEmpty(e.Detail)
+		if e.ScheduleID != 0 {
+			schedule = fmt.Sprint(e.ScheduleID)
+		}
+		if e.Reason != "" {
+			result = e.Reason
+		}
+		if e.After != 0 {
+			detail = fmt.Sprintf("after %d: %s", e.After, detail)
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s->%s\t%s\t%s\t%s\n", e.Leg, schedule, dashIfEmpty(e.OriginCode), dashIfEmpty(e.DestinationCode), dashIfEmpty(e.DepartureDate), result, detail)
+	}
+	return tw.Flush()
+}
+
 func newBookingSearchCmd() *cobra.Command {
 	return newBookingSearchCmdWithOutputWriter(&RealOutputWriter{})
 }
 
 func newBookingSearchCmdWithOutputWriter(writer OutputWriter) *cobra.Command {
-	var origin, destination, departure string
-	var transit bool
+	var q usecase.SearchQuery
+	var transit, explain bool
 	cmd := &cobra.Command{
 		Use:   "search",
-		Short: "Search flights with available seats (direct or transit)",
+		Short: "Search flights with available seats (direct, transit or multi-stop)",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if q.ReturnDate != "" && (transit || cmd.Flags().Changed("max-stops")) {
+				return fmt.Errorf("--return-date searches direct flights and cannot be combined with --transit or --max-stops")
+			}
+			if explain && (q.ReturnDate != "" || cmd.Flags().Changed("max-stops")) {
+				return fmt.Errorf("--explain covers direct and --transit searches only")
+			}
+			if explain {
+				q.Explain = &usecase.SearchTrace{}
+			}
+			// explained prints the trace, even when the search failed part way.
+			explained := func(err error) error {
+				if q.Explain == nil {
+					return err
+				}
+				if werr := writer.WriteSearchTrace(q.Explain); err == nil {
+					err = werr
+				}
+				return err
+			}
 			return withBookingUsecase(func(uc *usecase.BookingUsecase) error {
+				if q.ReturnDate != "" {
+					options, err := uc.FindRoundTrips(context.Background(), q)
+					if err != nil {
+						return err
+					}
+					return writer.WriteRoundTrips(options)
+				}
+				if cmd.Flags().Changed("max-stops") {
+					itineraries, err := uc.FindItineraries(context.Background(), q)
+					if err != nil {
+						return err
+					}
+					return writer.WriteItineraries(itineraries)
+				}
 				if transit {
 					// Search for transit flights
-					transitOptions, err := uc.SearchTransitFlights(context.Background(), origin, destination, departure)
+					transitOptions, err := uc.FindTransitFlights(context.Background(), q)
 					if err != nil {
-						return err
+						return explained(err)
 					}
 					if len(transitOptions) == 0 {
 						writer.WriteNoTransitMessage()
-						return nil
+						return explained(nil)
 					}
-					return writer.WriteTransitFlightOptions(transitOptions)
+					return explained(writer.WriteTransitFlightOptions(transitOptions))
 				} else {
 					// Search for direct flights
-					options, err := uc.SearchDirectFlights(context.Background(), origin, destination, departure)
+					options, err := uc.FindDirectFlights(context.Background(), q)
 					if err != nil {
-						return err
+						return explained(err)
 					}
-					return writer.WriteDirectFlightOptions(options)
+					return explained(writer.WriteDirectFlightOptions(options))
+				}
+			})
+		},
+	}
+	cmd.Flags().StringVar(&q.OriginCode, "origin", "", "origin airport code")
+	cmd.Flags().StringVar(&q.DestinationCode, "destination", "", "destination airport code")
+	cmd.Flags().StringVar(&q.DepartureDate, "date", "", "optional departure date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&q.ReturnDate, "return-date", "", "pair outbound flights on --date with return flights on this date (YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&transit, "transit", false, "search for connecting flights instead of direct")
+	cmd.Flags().IntVar(&q.MaxStops, "max-stops", 0, "search itineraries with up to N connections, shortest journey first")
+	cmd.Flags().IntVar(&q.FlexDays, "flex", 0, fmt.Sprintf("also search up to N days either side of --date (max %d)", usecase.MaxFlexDays))
+	cmd.Flags().IntVar(&q.Passengers, "passengers", 1, fmt.Sprintf("only show journeys with this many seats on every leg (max %d)", usecase.MaxPassengers))
+	cmd.Flags().StringVar(&q.AircraftType, "aircraft-type", "", "only show journeys flown entirely by this aircraft type (ICAO or IATA code, or name)")
+	cmd.Flags().StringVar(&q.DepartAfter, "depart-after", "", "earliest local departure time of the first leg (HH:MM)")
+	cmd.Flags().StringVar(&q.DepartBefore, "depart-before", "", "latest local departure time of the first leg (HH:MM)")
+	cmd.Flags().StringVar(&q.SortBy, "sort", "", "order results by departure, seats, fare, duration or stops")
+	cmd.Flags().BoolVar(&explain, "explain", false, "also list every candidate flight, up to a week either side of the date, and why it was left out; direct and --transit searches only")
+	_ = cmd.MarkFlagRequired("origin")
+	_ = cmd.MarkFlagRequired("destination")
+	return cmd
+}
+
+func newBookingCalendarCmd() *cobra.Command {
+	var origin, destination, month string
+	cmd := &cobra.Command{
+		Use:   "calendar",
+		Short: "Show direct flights, seats left and the cheapest fare for each day of a month",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withBookingUsecase(func(uc *usecase.BookingUsecase) error {
+				days, err := uc.Calendar(context.Background(), origin, destination, month)
+				if err != nil {
+					return err
 				}
+				tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+				_, _ = fmt.Fprintln(tw, "DATE\tFLIGHTS\tCHEAPEST\tSEATS LEFT")
+				for _, d := range days {
+					_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%d\n", d.Date, d.Flights, d.CheapestFare, d.SeatsLeft)
+				}
+				return tw.Flush()
 			})
 		},
 	}
 	cmd.Flags().StringVar(&origin, "origin", "", "origin airport code")
 	cmd.Flags().StringVar(&destination, "destination", "", "destination airport code")
-	cmd.Flags().StringVar(&departure, "date", "", "optional departure date (YYYY-MM-DD)")
-	cmd.Flags().BoolVar(&transit, "transit", false, "search for connecting flights instead of direct")
+	cmd.Flags().StringVar(&month, "month", "", "month to show (YYYY-MM)")
 	_ = cmd.MarkFlagRequired("origin")
 	_ = cmd.MarkFlagRequired("destination")
+	_ = cmd.MarkFlagRequired("month")
 	return cmd
 }
 
 func newBookingCreateCmd() *cobra.Command {
-	var scheduleID int64
+	var scheduleID, returnID int64
+	var connect []int64
 	var passenger string
+	var hold bool
 	cmd := &cobra.Command{
 		Use:   "book",
 		Short: "Create a new booking for a schedule",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if hold && cmd.Flags().Changed("return") {
+				return fmt.Errorf("--hold cannot be combined with --return")
+			}
+			if len(connect) > 0 && (hold || cmd.Flags().Changed("return")) {
+				return fmt.Errorf("--connect cannot be combined with --hold or --return")
+			}
 			return withBookingUsecase(func(uc *usecase.BookingUsecase) error {
+				if len(connect) > 0 {
+					trip, err := uc.BookItinerary(context.Background(), append([]int64{scheduleID}, connect...), passenger)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("connecting trip booked: trip %d fare %s\n", trip.ID, trip.Fare)
+					return printTripBookings(uc, trip)
+				}
+				if cmd.Flags().Changed("return") {
+					trip, err := uc.BookRoundTrip(context.Background(), scheduleID, returnID, passenger)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("round trip booked: trip %d fare %s\n", trip.ID, trip.Fare)
+					return printTripBookings(uc, trip)
+				}
+				if hold {
+					booking, err := uc.Hold(context.Background(), scheduleID, passenger)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("seat held: %s seat %d\n", booking.Reference, booking.SeatNumber)
+					return nil
+				}
 				booking, err := uc.Create(context.Background(), scheduleID, passenger)
 				if err != nil {
 					return err
 				}
 				fmt.Printf("booking confirmed: %s seat %d\n", booking.Reference, booking.SeatNumber)
+				tickets, err := uc.Tickets(context.Background(), booking.ID)
+				if err != nil {
+					return err
+				}
+				for _, t := range tickets {
+					fmt.Printf("e-ticket issued: %s\n", t.Number)
+				}
 				return nil
 			})
 		},
 	}
 	cmd.Flags().Int64Var(&scheduleID, "schedule", 0, "schedule identifier")
 	cmd.Flags().StringVar(&passenger, "name", "", "passenger full name")
+	cmd.Flags().BoolVar(&hold, "hold", false, "hold the seat without ticketing; confirm or cancel it later")
+	cmd.Flags().Int64Var(&returnID, "return", 0, "return schedule identifier; books both flights as one round trip")
+	cmd.Flags().Int64SliceVar(&connect, "connect", nil, "onward schedule identifiers in flying order; books every flight as one connecting trip")
 	_ = cmd.MarkFlagRequired("schedule")
 	_ = cmd.MarkFlagRequired("name")
 	return cmd
@@ -203,3 +437,88 @@ func newBookingListCmd() *cobra.Command {
 	_ = cmd.MarkFlagRequired("schedule")
 	return cmd
 }
+
+func newBookingConfirmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "confirm <reference>",
+		Short: "Confirm a held booking and issue its e-ticket",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withBookingUsecase(func(uc *usecase.BookingUsecase) error {
+				booking, err := uc.Confirm(context.Background(), args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Printf("booking confirmed: %s seat %d\n", booking.Reference, booking.SeatNumber)
+				tickets, err := uc.Tickets(context.Background(), booking.ID)
+				if err != nil {
+					return err
+				}
+				for _, t := range tickets {
+					fmt.Printf("e-ticket issued: %s\n", t.Number)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func newBookingCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <reference>",
+		Short: "Cancel a booking and release its seat",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withBookingUsecase(func(uc *usecase.BookingUsecase) error {
+				booking, err := uc.Cancel(context.Background(), args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Printf("booking cancelled: %s seat %d released\n", booking.Reference, booking.SeatNumber)
+				return nil
+			})
+		},
+	}
+}
+
+func newBookingTripCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "trip <id>",
+		Short: "Show a trip booked as one itinerary, such as a round trip or connecting flights",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			return withBookingUsecase(func(uc *usecase.BookingUsecase) error {
+				trip, err := uc.GetTrip(context.Background(), id)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("trip: %d\nkind: %s\npassenger: %s\nfare: %s\n", trip.ID, trip.Kind, trip.PassengerName, trip.Fare)
+				tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+				_, _ = fmt.Fprintln(tw, "REFERENCE\tSCHEDULE\tSEAT\tSTATUS")
+				for _, b := range trip.Bookings {
+					_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", b.Reference, b.ScheduleID, b.SeatNumber, b.Status)
+				}
+				return tw.Flush()
+			})
+		},
+	}
+}
+
+// printTripBookings prints each booking of a newly booked trip with its e-tickets.
+func printTripBookings(uc *usecase.BookingUsecase, trip *domain.Trip) error {
+	for _, b := range trip.Bookings {
+		fmt.Printf("booking confirmed: %s schedule %d seat %d\n", b.Reference, b.ScheduleID, b.SeatNumber)
+		tickets, err := uc.Tickets(context.Background(), b.ID)
+		if err != nil {
+			return err
+		}
+		for _, t := range tickets {
+			fmt.Printf("e-ticket issued: %s\n", t.Number)
+		}
+	}
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
@@ -16,6 +19,8 @@ type fakeBookingRepoCLI struct {
 	items  map[string]domain.Booking
 	counts map[int64]int
 	nextID int64
+	// affected flags bookings on cancelled flights by reference.
+	affected map[string]bool
 }
 
 func newFakeBookingRepoCLI() *fakeBookingRepoCLI {
@@ -33,6 +38,24 @@ func (f *fakeBookingRepoCLI) Create(ctx context.Context, b *domain.Booking) erro
 	return nil
 }
 
+func (f *fakeBookingRepoCLI) UpdateStatus(ctx context.Context, id int64, from, to string) error {
+	for ref, b := range f.items {
+		if b.ID != id {
+			continue
+		}
+		if b.Status != from {
+			return domain.ErrInvalidBookingTransition
+		}
+		if to == domain.BookingStatusCancelled {
+			f.counts[b.ScheduleID]--
+		}
+		b.Status = to
+		f.items[ref] = b
+		return nil
+	}
+	return domain.ErrBookingNotFound
+}
+
 func (f *fakeBookingRepoCLI) CountBySchedule(ctx context.Context, scheduleID int64) (int, error) {
 	return f.counts[scheduleID], nil
 }
@@ -55,6 +78,22 @@ func (f *fakeBookingRepoCLI) GetByReference(ctx context.Context, reference strin
 	return nil, domain.ErrBookingNotFound
 }
 
+func (f *fakeBookingRepoCLI) ListAffected(ctx context.Context, limit, offset int) ([]domain.Booking, error) {
+	var out []domain.Booking
+	for ref, b := range f.items {
+		if f.affected[ref] && b.Status != domain.BookingStatusCancelled {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ScheduleID != out[j].ScheduleID {
+			return out[i].ScheduleID < out[j].ScheduleID
+		}
+		return out[i].SeatNumber < out[j].SeatNumber
+	})
+	return out, nil
+}
+
 type fakeBookingScheduleRepoCLI struct {
 	items map[int64]domain.FlightSchedule
 }
@@ -81,7 +120,40 @@ func (f *fakeBookingScheduleRepoCLI) List(ctx context.Context, routeCode string,
 	return out, nil
 }
 
-func (f *fakeBookingScheduleRepoCLI) Delete(ctx context.Context, id int64) error { return nil }
+func (f *fakeBookingScheduleRepoCLI) ListByAirplane(ctx context.Context, airplaneCode string, limit, offset int) ([]domain.FlightSchedule, error) {
+	var out []domain.FlightSchedule
+	for _, s := range f.items {
+		if s.AirplaneCode == airplaneCode {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeBookingScheduleRepoCLI) ListByAirport(ctx context.Context, airportCode string, arrivals bool, from, to time.Time, limit, offset int) ([]domain.BoardFlight, error) {
+	return nil, nil
+}
+
+func (f *fakeBookingScheduleRepoCLI) Update(ctx context.Context, s *domain.FlightSchedule) ([]domain.SeatMove, error) {
+	f.items[s.ID] = *s
+	return nil, nil
+}
+
+func (f *fakeBookingScheduleRepoCLI) Cancel(ctx context.Context, id int64, reason string, at time.Time) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeBookingScheduleRepoCLI) Delay(ctx context.Context, delays []domain.ScheduleDelay, notices []domain.Notification) error {
+	return nil
+}
+
+func (f *fakeBookingScheduleRepoCLI) SetGates(ctx context.Context, id int64, departureGate, arrivalGate string) error {
+	return nil
+}
+
+func (f *fakeBookingScheduleRepoCLI) Delete(ctx context.Context, id int64, cascade *domain.Dependents) (domain.Dependents, error) {
+	return domain.Dependents{}, nil
+}
 
 type fakeRouteRepoBookingCLI struct {
 	items []domain.Route
@@ -99,11 +171,25 @@ func (f *fakeRouteRepoBookingCLI) GetByCode(ctx context.Context, code string) (*
 	return nil, domain.ErrRouteNotFound
 }
 
-func (f *fakeRouteRepoBookingCLI) List(ctx context.Context, limit, offset int) ([]domain.Route, error) {
+func (f *fakeRouteRepoBookingCLI) SetArchived(ctx context.Context, code string, archived bool) error {
+	return nil
+}
+
+func (f *fakeRouteRepoBookingCLI) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Route, error) {
 	return f.items, nil
 }
 
-func (f *fakeRouteRepoBookingCLI) Delete(ctx context.Context, code string) error { return nil }
+func (f *fakeRouteRepoBookingCLI) SetBlockTime(ctx context.Context, code string, minutes int) error {
+	return nil
+}
+
+func (f *fakeRouteRepoBookingCLI) Update(ctx context.Context, r *domain.Route, from time.Time, force bool) ([]domain.BookedFlight, error) {
+	return nil, nil
+}
+
+func (f *fakeRouteRepoBookingCLI) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	return domain.Dependents{}, nil
+}
 
 type fakeAirplaneRepoBookingCLI struct {
 	items map[string]domain.Airplane
@@ -125,7 +211,11 @@ func (f *fakeAirplaneRepoBookingCLI) GetByCode(ctx context.Context, code string)
 	return nil, domain.ErrAirplaneNotFound
 }
 
-func (f *fakeAirplaneRepoBookingCLI) List(ctx context.Context, limit, offset int) ([]domain.Airplane, error) {
+func (f *fakeAirplaneRepoBookingCLI) SetArchived(ctx context.Context, code string, archived bool) error {
+	return nil
+}
+
+func (f *fakeAirplaneRepoBookingCLI) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airplane, error) {
 	var out []domain.Airplane
 	for _, a := range f.items {
 		out = append(out, a)
@@ -133,20 +223,26 @@ func (f *fakeAirplaneRepoBookingCLI) List(ctx context.Context, limit, offset int
 	return out, nil
 }
 
-func (f *fakeAirplaneRepoBookingCLI) UpdateSeats(ctx context.Context, code string, seats int) error {
-	return nil
+func (f *fakeAirplaneRepoBookingCLI) UpdateSeats(ctx context.Context, code string, seats int, from time.Time, force bool) (*domain.CapacityChange, error) {
+	return &domain.CapacityChange{}, nil
 }
 
-func (f *fakeAirplaneRepoBookingCLI) Delete(ctx context.Context, code string) error { return nil }
+func (f *fakeAirplaneRepoBookingCLI) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	return domain.Dependents{}, nil
+}
 
 func TestBookingCLI_Flow(t *testing.T) {
-	oldDB, oldBookingRepo, oldScheduleRepo, oldRouteRepo, oldAirplaneRepo := newBookingDB, newBookingRepo, newBookingScheduleRepo, newBookingRouteRepo, newBookingAirplaneRepo
+	oldDB, oldBookingRepo, oldScheduleRepo, oldRouteRepo, oldAirplaneRepo, oldTicketRepo, oldAvailabilityRepo := newBookingDB, newBookingRepo, newBookingScheduleRepo, newBookingRouteRepo, newBookingAirplaneRepo, newBookingTicketRepo, newBookingAvailabilityRepo
+	oldInventoryRepo := newBookingInventoryRepo
 	t.Cleanup(func() {
+		newBookingInventoryRepo = oldInventoryRepo
 		newBookingDB = oldDB
 		newBookingRepo = oldBookingRepo
 		newBookingScheduleRepo = oldScheduleRepo
 		newBookingRouteRepo = oldRouteRepo
 		newBookingAirplaneRepo = oldAirplaneRepo
+		newBookingTicketRepo = oldTicketRepo
+		newBookingAvailabilityRepo = oldAvailabilityRepo
 	})
 
 	newBookingDB = func(string) (*sqlx.DB, error) {
@@ -162,11 +258,17 @@ func TestBookingCLI_Flow(t *testing.T) {
 	routes := &fakeRouteRepoBookingCLI{items: []domain.Route{{Code: "RT1", OriginCode: "CGK", DestinationCode: "SIN"}}}
 	airplanes := newFakeAirplaneRepoBookingCLI()
 	airplanes.items["A320"] = domain.Airplane{Code: "A320", SeatCapacity: 2}
+	tickets := newFakeTicketRepoCLI()
 
 	newBookingRepo = func(*sqlx.DB) domain.BookingRepository { return bookings }
 	newBookingScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
 	newBookingRouteRepo = func(*sqlx.DB) domain.RouteRepository { return routes }
 	newBookingAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository { return airplanes }
+	newBookingTicketRepo = func(*sqlx.DB) domain.TicketRepository { return tickets }
+	newBookingAvailabilityRepo = func(*sqlx.DB) domain.AvailabilityRepository {
+		return usecase.NewRepositoryAvailability(bookings, schedules, routes, airplanes, nil)
+	}
+	newBookingInventoryRepo = func(*sqlx.DB) domain.SeatInventoryRepository { return &fakeInventoryRepoCLI{} }
 
 	t.Setenv("FLIGHT_DB_HOST", "localhost")
 
@@ -176,8 +278,13 @@ func TestBookingCLI_Flow(t *testing.T) {
 	}
 
 	os.Args = []string{"flight-booking", "booking", "book", "--schedule", "1", "--name", "Alice"}
-	if err := Execute(); err != nil {
-		t.Fatalf("book: %v", err)
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("book: %v", err)
+		}
+	})
+	if !strings.Contains(out, "e-ticket issued: 126") {
+		t.Fatalf("expected ticket number in output, got %q", out)
 	}
 
 	os.Args = []string{"flight-booking", "booking", "list", "--schedule", "1"}
@@ -197,6 +304,42 @@ func TestBookingCLI_Flow(t *testing.T) {
 	if err := Execute(); err != nil {
 		t.Fatalf("get: %v", err)
 	}
+
+	os.Args = []string{"flight-booking", "booking", "book", "--schedule", "1", "--name", "Bob", "--hold"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("hold: %v", err)
+		}
+	})
+	if !strings.Contains(out, "seat held") || strings.Contains(out, "e-ticket") {
+		t.Fatalf("expected an unticketed hold, got %q", out)
+	}
+	var held string
+	for k, b := range bookings.items {
+		if b.Status == domain.BookingStatusHeld {
+			held = k
+		}
+	}
+
+	os.Args = []string{"flight-booking", "booking", "confirm", held}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("confirm: %v", err)
+		}
+	})
+	if !strings.Contains(out, "booking confirmed: "+held) || !strings.Contains(out, "e-ticket issued") {
+		t.Fatalf("expected confirmation with ticket, got %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "booking", "cancel", held}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("cancel: %v", err)
+		}
+	})
+	if !strings.Contains(out, "booking cancelled: "+held) || bookings.counts[1] != 1 {
+		t.Fatalf("expected the seat to be released, got %q counts=%v", out, bookings.counts)
+	}
 }
 
 func TestBookingCLI_MissingFlags(t *testing.T) {
@@ -272,3 +415,299 @@ func TestWriteNoTransitMessage(t *testing.T) {
 	w.Close()
 	_, _ = r.Read(make([]byte, 1024)) // Read the output to prevent blocking
 }
+
+func TestBookingCLI_SearchMaxStops(t *testing.T) {
+	oldDB, oldBookingRepo, oldScheduleRepo, oldRouteRepo, oldAirplaneRepo, oldAirportRepo, oldAvailabilityRepo := newBookingDB, newBookingRepo, newBookingScheduleRepo, newBookingRouteRepo, newBookingAirplaneRepo, newBookingAirportRepo, newBookingAvailabilityRepo
+	t.Cleanup(func() {
+		newBookingDB = oldDB
+		newBookingRepo = oldBookingRepo
+		newBookingScheduleRepo = oldScheduleRepo
+		newBookingRouteRepo = oldRouteRepo
+		newBookingAirplaneRepo = oldAirplaneRepo
+		newBookingAirportRepo = oldAirportRepo
+		newBookingAvailabilityRepo = oldAvailabilityRepo
+	})
+	newBookingDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+
+	base := time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC)
+	schedules := &fakeBookingScheduleRepoCLI{items: map[int64]domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-01", DepartureAt: base, ArrivalAt: base.Add(90 * time.Minute)},
+		2: {ID: 2, RouteCode: "RT2", AirplaneCode: "A320", DepartureDate: "2025-01-01", DepartureAt: base.Add(3 * time.Hour), ArrivalAt: base.Add(5 * time.Hour)},
+	}}
+	routes := &fakeRouteRepoBookingCLI{items: []domain.Route{
+		{Code: "RT1", OriginCode: "CGK", DestinationCode: "SUB"},
+		{Code: "RT2", OriginCode: "SUB", DestinationCode: "DPS"},
+	}}
+	airplanes := newFakeAirplaneRepoBookingCLI()
+	airplanes.items["A320"] = domain.Airplane{Code: "A320", SeatCapacity: 2}
+
+	bookings := newFakeBookingRepoCLI()
+	newBookingRepo = func(*sqlx.DB) domain.BookingRepository { return bookings }
+	newBookingScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
+	newBookingRouteRepo = func(*sqlx.DB) domain.RouteRepository { return routes }
+	newBookingAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository { return airplanes }
+	newBookingAirportRepo = func(*sqlx.DB) domain.AirportRepository { return &fakeAirportRepoCLI{} }
+	newBookingAvailabilityRepo = func(*sqlx.DB) domain.AvailabilityRepository {
+		return usecase.NewRepositoryAvailability(bookings, schedules, routes, airplanes, nil)
+	}
+
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+	os.Args = []string{"flight-booking", "booking", "search", "--origin", "CGK", "--destination", "DPS", "--max-stops", "1"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("search: %v", err)
+		}
+	})
+	if !strings.Contains(out, "CGK->SUB") || !strings.Contains(out, "SUB->DPS") || !strings.Contains(out, "5h00m") {
+		t.Fatalf("expected a one-stop itinerary in output, got %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "booking", "search", "--origin", "CGK", "--destination", "DPS", "--max-stops", "0"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("search direct: %v", err)
+		}
+	})
+	if !strings.Contains(out, "No itineraries found") {
+		t.Fatalf("expected no direct itineraries, got %q", out)
+	}
+}
+
+func TestBookingCLI_CalendarAndFlex(t *testing.T) {
+	oldDB, oldBookingRepo, oldScheduleRepo, oldRouteRepo, oldAirplaneRepo, oldAvailabilityRepo := newBookingDB, newBookingRepo, newBookingScheduleRepo, newBookingRouteRepo, newBookingAirplaneRepo, newBookingAvailabilityRepo
+	t.Cleanup(func() {
+		newBookingDB = oldDB
+		newBookingRepo = oldBookingRepo
+		newBookingScheduleRepo = oldScheduleRepo
+		newBookingRouteRepo = oldRouteRepo
+		newBookingAirplaneRepo = oldAirplaneRepo
+		newBookingAvailabilityRepo = oldAvailabilityRepo
+	})
+	newBookingDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+
+	schedules := &fakeBookingScheduleRepoCLI{items: map[int64]domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-03-13"},
+		2: {ID: 2, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-03-20"},
+	}}
+	routes := &fakeRouteRepoBookingCLI{items: []domain.Route{{Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}}}
+	airplanes := newFakeAirplaneRepoBookingCLI()
+	airplanes.items["A320"] = domain.Airplane{Code: "A320", TypeCode: "A320", TypeName: "Airbus A320-200", SeatCapacity: 3}
+	fares := &fakeFareRepoCLI{items: []domain.Fare{{ID: 1, RouteCode: "RT1", OneWay: domain.Money{Amount: 85000000, Currency: "IDR"}}}}
+	bookings := newFakeBookingRepoCLI()
+	newBookingRepo = func(*sqlx.DB) domain.BookingRepository { return bookings }
+	newBookingScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
+	newBookingRouteRepo = func(*sqlx.DB) domain.RouteRepository { return routes }
+	newBookingAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository { return airplanes }
+	newBookingAvailabilityRepo = func(*sqlx.DB) domain.AvailabilityRepository {
+		return usecase.NewRepositoryAvailability(bookings, schedules, routes, airplanes, fares)
+	}
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "booking", "search", "--origin", "CGK", "--destination", "DPS", "--date", "2025-03-15", "--flex", "3"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("flex search: %v", err)
+		}
+	})
+	if !strings.Contains(out, "2025-03-13") || strings.Contains(out, "2025-03-20") || !strings.Contains(out, "850000.00 IDR") || !strings.Contains(out, "A320 (Airbus A320-200)") {
+		t.Fatalf("expected only the flight within three days, priced and with its aircraft type, got %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "booking", "search", "--origin", "CGK", "--destination", "DPS", "--flex", "3"}
+	if err := Execute(); err != domain.ErrInvalidFlexDays {
+		t.Fatalf("want invalid flex days without a date, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "booking", "search", "--origin", "CGK", "--destination", "DPS", "--passengers", "4", "--sort", "fare"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("passenger search: %v", err)
+		}
+	})
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 1 {
+		t.Fatalf("expected no flight with four seats, got %q", out)
+	}
+	os.Args = []string{"flight-booking", "booking", "search", "--origin", "CGK", "--destination", "DPS", "--sort", "price"}
+	if err := Execute(); err != domain.ErrInvalidSortKey {
+		t.Fatalf("want invalid sort key, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "booking", "search", "--origin", "CGK", "--destination", "DPS", "--date", "2025-03-13", "--passengers", "4", "--explain"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("explain search: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Search trace:") || !strings.Contains(out, "full") || !strings.Contains(out, "3 of 3 seats left, 4 needed") || !strings.Contains(out, "wrong date") {
+		t.Fatalf("expected the full and wrong-date flights explained, got %q", out)
+	}
+	os.Args = []string{"flight-booking", "booking", "search", "--origin", "CGK", "--destination", "DPS", "--max-stops", "1", "--explain"}
+	if err := Execute(); err == nil || !strings.Contains(err.Error(), "--explain") {
+		t.Fatalf("want --explain rejected for itineraries, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "booking", "calendar", "--origin", "CGK", "--destination", "DPS", "--month", "2025-03"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("calendar: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 32 || !strings.Contains(lines[0], "CHEAPEST") {
+		t.Fatalf("expected a header and 31 days, got %q", out)
+	}
+	if f := strings.Fields(lines[13]); f[0] != "2025-03-13" || f[1] != "1" || f[2] != "850000.00" || f[4] != "3" {
+		t.Fatalf("unexpected calendar row %q", lines[13])
+	}
+}
+
+type fakeTripRepoCLI struct {
+	trips       map[int64]*domain.Trip
+	connections map[int64][]domain.Connection
+}
+
+func (f *fakeTripRepoCLI) Create(ctx context.Context, t *domain.Trip) error {
+	if f.trips == nil {
+		f.trips = make(map[int64]*domain.Trip)
+	}
+	t.ID = int64(len(f.trips) + 1)
+	for i := range t.Bookings {
+		t.Bookings[i].ID = t.ID*10 + int64(i)
+		t.Bookings[i].TripID = t.ID
+	}
+	f.trips[t.ID] = t
+	return nil
+}
+
+func (f *fakeTripRepoCLI) GetByID(ctx context.Context, id int64) (*domain.Trip, error) {
+	if t, ok := f.trips[id]; ok {
+		return t, nil
+	}
+	return nil, domain.ErrTripNotFound
+}
+
+func (f *fakeTripRepoCLI) Connections(ctx context.Context, scheduleID int64) ([]domain.Connection, error) {
+	return f.connections[scheduleID], nil
+}
+
+func TestBookingCLI_RoundTrip(t *testing.T) {
+	oldDB, oldBookingRepo, oldScheduleRepo, oldRouteRepo, oldAirplaneRepo, oldAvailabilityRepo, oldTripRepo, oldFareRepo := newBookingDB, newBookingRepo, newBookingScheduleRepo, newBookingRouteRepo, newBookingAirplaneRepo, newBookingAvailabilityRepo, newBookingTripRepo, newBookingFareRepo
+	oldTicketRepo, oldAirportRepo, oldInventoryRepo := newBookingTicketRepo, newBookingAirportRepo, newBookingInventoryRepo
+	t.Cleanup(func() {
+		newBookingInventoryRepo = oldInventoryRepo
+		newBookingTicketRepo = oldTicketRepo
+		newBookingAirportRepo = oldAirportRepo
+		newBookingDB = oldDB
+		newBookingRepo = oldBookingRepo
+		newBookingScheduleRepo = oldScheduleRepo
+		newBookingRouteRepo = oldRouteRepo
+		newBookingAirplaneRepo = oldAirplaneRepo
+		newBookingAvailabilityRepo = oldAvailabilityRepo
+		newBookingTripRepo = oldTripRepo
+		newBookingFareRepo = oldFareRepo
+	})
+	newBookingDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+
+	day := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	schedules := &fakeBookingScheduleRepoCLI{items: map[int64]domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "OUT", AirplaneCode: "A320", DepartureDate: "2025-03-15", DepartureAt: day.Add(8 * time.Hour), ArrivalAt: day.Add(10 * time.Hour)},
+		2: {ID: 2, RouteCode: "BACK", AirplaneCode: "A320", DepartureDate: "2025-03-18", DepartureAt: day.Add(3*24*time.Hour + 8*time.Hour)},
+		3: {ID: 3, RouteCode: "ONWARD", AirplaneCode: "A320", DepartureDate: "2025-03-15", DepartureAt: day.Add(11 * time.Hour), ArrivalAt: day.Add(12 * time.Hour)},
+	}}
+	routes := &fakeRouteRepoBookingCLI{items: []domain.Route{
+		{Code: "OUT", OriginCode: "CGK", DestinationCode: "DPS"},
+		{Code: "BACK", OriginCode: "DPS", DestinationCode: "CGK"},
+		{Code: "ONWARD", OriginCode: "DPS", DestinationCode: "LOP"},
+	}}
+	airplanes := newFakeAirplaneRepoBookingCLI()
+	airplanes.items["A320"] = domain.Airplane{Code: "A320", SeatCapacity: 3}
+	fares := &fakeFareRepoCLI{items: []domain.Fare{{ID: 1, RouteCode: "OUT", OneWay: domain.Money{Amount: 10000, Currency: "USD"}, RoundTrip: domain.Money{Amount: 17500, Currency: "USD"}}}}
+	bookings := newFakeBookingRepoCLI()
+	trips := &fakeTripRepoCLI{}
+	newBookingRepo = func(*sqlx.DB) domain.BookingRepository { return bookings }
+	newBookingScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
+	newBookingRouteRepo = func(*sqlx.DB) domain.RouteRepository { return routes }
+	newBookingAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository { return airplanes }
+	newBookingTripRepo = func(*sqlx.DB) domain.TripRepository { return trips }
+	newBookingTicketRepo = func(*sqlx.DB) domain.TicketRepository { return newFakeTicketRepoCLI() }
+	newBookingFareRepo = func(*sqlx.DB) domain.FareRepository { return fares }
+	newBookingAvailabilityRepo = func(*sqlx.DB) domain.AvailabilityRepository {
+		return usecase.NewRepositoryAvailability(bookings, schedules, routes, airplanes, fares)
+	}
+	newBookingAirportRepo = func(*sqlx.DB) domain.AirportRepository { return &fakeAirportRepoCLI{} }
+	newBookingInventoryRepo = func(*sqlx.DB) domain.SeatInventoryRepository { return &fakeInventoryRepoCLI{} }
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "booking", "search", "--origin", "CGK", "--destination", "DPS", "--date", "2025-03-15", "--return-date", "2025-03-18"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("round-trip search: %v", err)
+		}
+	})
+	if !strings.Contains(out, "CGK->DPS") || !strings.Contains(out, "DPS->CGK") || !strings.Contains(out, "175.00 USD") {
+		t.Fatalf("expected a priced pair, got %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "booking", "search", "--origin", "CGK", "--destination", "DPS", "--date", "2025-03-15", "--return-date", "2025-03-18", "--transit"}
+	if err := Execute(); err == nil {
+		t.Fatalf("expected --return-date with --transit to fail")
+	}
+
+	os.Args = []string{"flight-booking", "booking", "book", "--schedule", "1", "--return", "2", "--name", "Alice"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("book round trip: %v", err)
+		}
+	})
+	if !strings.Contains(out, "round trip booked: trip 1 fare 175.00 USD") || strings.Count(out, "booking confirmed") != 2 || strings.Count(out, "e-ticket issued") != 2 {
+		t.Fatalf("unexpected booking output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "booking", "trip", "1"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("trip: %v", err)
+		}
+	})
+	if !strings.Contains(out, "kind: ROUND_TRIP") || !strings.Contains(out, "passenger: Alice") || len(strings.Split(strings.TrimSpace(out), "\n")) != 7 {
+		t.Fatalf("unexpected trip output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "booking", "book", "--schedule", "1", "--connect", "3", "--name", "Carol"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("book connecting trip: %v", err)
+		}
+	})
+	if !strings.Contains(out, "connecting trip booked: trip 2") || strings.Count(out, "booking confirmed") != 2 || trips.trips[2].Kind != domain.TripConnecting {
+		t.Fatalf("unexpected connecting output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "booking", "book", "--schedule", "1", "--connect", "3", "--return", "2", "--name", "Dave"}
+	if err := Execute(); err == nil {
+		t.Fatalf("expected --connect with --return to fail")
+	}
+
+	os.Args = []string{"flight-booking", "booking", "book", "--schedule", "1", "--return", "2", "--name", "Bob", "--hold"}
+	if err := Execute(); err == nil {
+		t.Fatalf("expected --hold with --return to fail")
+	}
+}
@@ -133,12 +133,16 @@ func TestBookingListCommand(t *testing.T) {
 }
 
 // Fake repo for CLI tests
-type fakeRepo struct{ data map[string]string }
+type fakeRepo struct{ data map[string]string; archived map[string]bool }
 func (f *fakeRepo) Create(ctx context.Context, a *domain.Airport) error { f.data[a.Code]=a.City; return nil }
 func (f *fakeRepo) GetByCode(ctx context.Context, code string) (*domain.Airport, error) { if c,ok:=f.data[code]; ok { return &domain.Airport{Code:code, City:c}, nil }; return nil, domain.ErrAirportNotFound }
-func (f *fakeRepo) List(ctx context.Context, limit, offset int) ([]domain.Airport, error) { out:=[]domain.Airport{}; for k,v:= range f.data { out=append(out, domain.Airport{Code:k, City:v}) }; return out, nil }
+func (f *fakeRepo) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airport, error) { out:=[]domain.Airport{}; for k,v:= range f.data { if f.archived[k] && !includeArchived { continue }; a := domain.Airport{Code:k, City:v}; if f.archived[k] { a.ArchivedAt = "2025-01-01T00:00:00Z" }; out=append(out, a) }; return out, nil }
+func (f *fakeRepo) SetArchived(ctx context.Context, code string, archived bool) error { if _,ok:=f.data[code]; !ok { return domain.ErrAirportNotFound }; if f.archived == nil { f.archived = map[string]bool{} }; f.archived[code]=archived; return nil }
 func (f *fakeRepo) Update(ctx context.Context, code string, city string) error { if _,ok:=f.data[code]; !ok { return domain.ErrAirportNotFound }; f.data[code]=city; return nil }
-func (f *fakeRepo) Delete(ctx context.Context, code string) error { if _,ok:=f.data[code]; !ok { return domain.ErrAirportNotFound }; delete(f.data, code); return nil }
+func (f *fakeRepo) SetTimeZone(ctx context.Context, code string, timeZone string) error { if _,ok:=f.data[code]; !ok { return domain.ErrAirportNotFound }; return nil }
+func (f *fakeRepo) SetMinConnection(ctx context.Context, code string, minutes int) error { if _,ok:=f.data[code]; !ok { return domain.ErrAirportNotFound }; return nil }
+func (f *fakeRepo) SetPosition(ctx context.Context, code string, position *domain.Coordinates) error { if _,ok:=f.data[code]; !ok { return domain.ErrAirportNotFound }; return nil }
+func (f *fakeRepo) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) { if _,ok:=f.data[code]; !ok { return domain.Dependents{}, domain.ErrAirportNotFound }; delete(f.data, code); return domain.Dependents{}, nil }
 
 func TestAirportCLI_Subcommands(t *testing.T) {
     // Patch DB and repo factories
@@ -161,13 +165,44 @@ func TestAirportCLI_Subcommands(t *testing.T) {
     // Update
     os.Args = []string{"flight-booking", "airport", "update", "--code", "DPS", "--city", "Bali"}
     if err := Execute(); err != nil { t.Fatalf("update: %v", err) }
+    os.Args = []string{"flight-booking", "airport", "update", "--code", "DPS", "--tz", "Asia/Makassar"}
+    if err := Execute(); err != nil { t.Fatalf("update tz: %v", err) }
+    os.Args = []string{"flight-booking", "airport", "update", "--code", "DPS", "--tz", "Nowhere/Land"}
+    if err := Execute(); err != domain.ErrInvalidAirportTimeZone { t.Fatalf("want invalid tz, got %v", err) }
+    os.Args = []string{"flight-booking", "airport", "update", "--code", "DPS", "--mct", "60"}
+    if err := Execute(); err != nil { t.Fatalf("update mct: %v", err) }
+    os.Args = []string{"flight-booking", "airport", "update", "--code", "DPS", "--mct", "-1"}
+    if err := Execute(); err != domain.ErrInvalidMinConnection { t.Fatalf("want invalid mct, got %v", err) }
+    os.Args = []string{"flight-booking", "airport", "update", "--code", "DPS"}
+    if err := Execute(); err == nil { t.Fatalf("expected error when no field to update is given") }
+    os.Args = []string{"flight-booking", "airport", "update", "--code", "DPS", "--lat", "-8.7482", "--lon", "115.1672"}
+    if err := Execute(); err != nil { t.Fatalf("update position: %v", err) }
+    os.Args = []string{"flight-booking", "airport", "update", "--code", "DPS", "--lat", "-8.7482"}
+    if err := Execute(); err == nil { t.Fatalf("expected error for a latitude without a longitude") }
+    os.Args = []string{"flight-booking", "airport", "update", "--code", "DPS", "--lat", "95", "--lon", "115"}
+    if err := Execute(); err != domain.ErrInvalidCoordinates { t.Fatalf("want invalid coordinates, got %v", err) }
     // List
     os.Args = []string{"flight-booking", "airport", "list"}
     out := captureOutput(func(){ _ = Execute() })
     if out == "" { t.Fatalf("expected list output") }
-    // Delete
+    // Delete archives unless purged
     os.Args = []string{"flight-booking", "airport", "delete", "DPS"}
-    if err := Execute(); err != nil { t.Fatalf("delete: %v", err) }
+    out = captureOutput(func(){ if err := Execute(); err != nil { t.Fatalf("delete: %v", err) } })
+    if !strings.Contains(out, "archived airport DPS; restore it with: airport restore DPS") { t.Fatalf("unexpected archive output %q", out) }
+    os.Args = []string{"flight-booking", "airport", "list"}
+    out = captureOutput(func(){ _ = Execute() })
+    if strings.Contains(out, "DPS") { t.Fatalf("archived airport listed: %q", out) }
+    os.Args = []string{"flight-booking", "airport", "list", "--include-archived"}
+    out = captureOutput(func(){ _ = Execute() })
+    if !strings.Contains(out, "ARCHIVED") || !strings.Contains(out, "2025-01-01") { t.Fatalf("expected archived airport listed: %q", out) }
+    os.Args = []string{"flight-booking", "airport", "restore", "DPS"}
+    if err := Execute(); err != nil { t.Fatalf("restore: %v", err) }
+    if r.archived["DPS"] { t.Fatalf("airport not restored") }
+    os.Args = []string{"flight-booking", "airport", "delete", "DPS", "--cascade"}
+    if err := Execute(); err == nil { t.Fatalf("expected --cascade without --purge to fail") }
+    os.Args = []string{"flight-booking", "airport", "delete", "DPS", "--purge"}
+    if err := Execute(); err != nil { t.Fatalf("purge: %v", err) }
+    if _, ok := r.data["DPS"]; ok { t.Fatalf("airport not purged") }
 }
 
 func TestAirportCLI_DBInitError(t *testing.T) {
@@ -312,6 +347,9 @@ type MockOutputWriter struct {
     directFlightOptionsCalled    bool
     transitFlightOptionsCalled   bool
     noTransitMessageCalled       bool
+    itinerariesCalled            bool
+    roundTripsCalled             bool
+    trace                        *usecase.SearchTrace
     directFlightError            error
     transitFlightError           error
 }
@@ -330,6 +368,21 @@ func (m *MockOutputWriter) WriteNoTransitMessage() {
     m.noTransitMessageCalled = true
 }
 
+func (m *MockOutputWriter) WriteItineraries(itineraries []usecase.Itinerary) error {
+    m.itinerariesCalled = true
+    return nil
+}
+
+func (m *MockOutputWriter) WriteRoundTrips(options []usecase.RoundTripOption) error {
+    m.roundTripsCalled = true
+    return nil
+}
+
+func (m *MockOutputWriter) WriteSearchTrace(trace *usecase.SearchTrace) error {
+    m.trace = trace
+    return nil
+}
+
 // TestBookingSearchCmdDirectSuccess tests the direct flight search path
 func TestBookingSearchCmdDirectSuccess(t *testing.T) {
     mockWriter := &MockOutputWriter{}
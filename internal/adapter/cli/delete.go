@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// confirmInput answers the prompt before a cascading delete.
+var confirmInput io.Reader = os.Stdin
+
+// Usage of the delete and list flags.
+const (
+	cascadeUsage         = "also delete everything that depends on it, including bookings, after showing it and asking to confirm"
+	yesUsage             = "with --cascade, delete without asking to confirm"
+	purgeCascadeUsage    = "with --purge, " + cascadeUsage
+	purgeUsage           = "delete permanently instead of archiving; refused while anything depends on it unless --cascade"
+	includeArchivedUsage = "also list archived records"
+)
+
+// requirePurge rejects --cascade on a delete that only archives.
+func requirePurge(purge, cascade bool) error {
+	if cascade && !purge {
+		return fmt.Errorf("--cascade requires --purge")
+	}
+	return nil
+}
+
+// printArchived reports an archived entity and how to bring it back.
+func printArchived(entity, key string) {
+	fmt.Printf("archived %s %s; restore it with: %s restore %s\n", entity, key, entity, key)
+}
+
+// archivedColumn shows when a record was archived, or "-".
+func archivedColumn(archivedAt string) string {
+	if len(archivedAt) >= len("2006-01-02") {
+		return archivedAt[:len("2006-01-02")]
+	}
+	return dashIfEmpty(archivedAt)
+}
+
+// refusedDelete points a delete refused for dependents at --cascade; its
+// message already lists what would be removed.
+func refusedDelete(err error) error {
+	var inUse *domain.InUseError
+	if errors.As(err, &inUse) {
+		return fmt.Errorf("%w; rerun with --cascade to delete them too", err)
+	}
+	return err
+}
+
+// deleteWith deletes an entity through del. Without cascade a delete refused
+// for dependents points at --cascade. With it the dependents are shown first
+// and, once confirmed, del deletes exactly those; it fails with
+// domain.ErrDependentsChanged when they changed in between.
+func deleteWith(entity, key string, cascade, yes bool, del func(cascade *domain.Dependents) (domain.Dependents, error)) error {
+	removed, err := del(nil)
+	var inUse *domain.InUseError
+	if !cascade || !errors.As(err, &inUse) {
+		if err != nil {
+			return refusedDelete(err)
+		}
+		printDeleted(entity, key, removed)
+		return nil
+	}
+	fmt.Printf("deleting %s %s also removes %s\n", entity, key, inUse.Dependents)
+	if !yes {
+		fmt.Print("delete them too? [y/N] ")
+		answer, _ := bufio.NewReader(confirmInput).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return fmt.Errorf("delete not confirmed; nothing was deleted")
+		}
+	}
+	if removed, err = del(&inUse.Dependents); err != nil {
+		return err
+	}
+	printDeleted(entity, key, removed)
+	return nil
+}
+
+// printDeleted reports a delete with what was removed along with the entity.
+func printDeleted(entity, key string, removed domain.Dependents) {
+	if removed.Empty() {
+		fmt.Printf("deleted %s %s\n", entity, key)
+		return
+	}
+	fmt.Printf("deleted %s %s with %s\n", entity, key, removed)
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	sqlxrepo "github.com/ambiyansyah-risyal/flight-booking/internal/adapter/repository/sqlx"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/config"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
+)
+
+func newFareCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "fare", Short: "Manage route fares"}
+	cmd.AddCommand(newFareCreateCmd())
+	cmd.AddCommand(newFareListCmd())
+	cmd.AddCommand(newFareDeleteCmd())
+	return cmd
+}
+
+var (
+	newFareDB        = func(dsn string) (*sqlx.DB, error) { return sqlxrepo.New(dsn) }
+	newFareRepo      = func(db *sqlx.DB) domain.FareRepository { return sqlxrepo.NewFareRepository(db) }
+	newFareRouteRepo = func(db *sqlx.DB) domain.RouteRepository { return sqlxrepo.NewRouteRepository(db) }
+)
+
+func withFareUsecase(run func(*usecase.FareUsecase) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := newFareDB(cfg.Database.DSN())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return run(usecase.NewFareUsecase(newFareRepo(db), newFareRouteRepo(db)))
+}
+
+func newFareCreateCmd() *cobra.Command {
+	var routeCode, amount, perKm, roundTrip, currency, validFrom, validTo string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Publish a fare on a route",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (amount == "") == (perKm == "") {
+				return fmt.Errorf("exactly one of --amount or --per-km is required")
+			}
+			if perKm != "" && roundTrip != "" {
+				return fmt.Errorf("--round-trip cannot be combined with --per-km")
+			}
+			return withFareUsecase(func(uc *usecase.FareUsecase) error {
+				var (
+					f   *domain.Fare
+					err error
+				)
+				if perKm != "" {
+					f, err = uc.CreateByDistance(context.Background(), routeCode, perKm, currency, validFrom, validTo)
+				} else {
+					f, err = uc.Create(context.Background(), routeCode, amount, roundTrip, currency, validFrom, validTo)
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Printf("created fare %d on route %s: %s one-way, %s round trip\n", f.ID, f.RouteCode, f.OneWay, f.RoundTrip)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&routeCode, "route", "", "route code")
+	cmd.Flags().StringVar(&amount, "amount", "", "one-way amount (e.g. 129.90)")
+	cmd.Flags().StringVar(&perKm, "per-km", "", "one-way rate per kilometre of the route's great-circle distance, instead of --amount")
+	cmd.Flags().StringVar(&roundTrip, "round-trip", "", "optional round-trip amount")
+	cmd.Flags().StringVar(&currency, "currency", "", "ISO 4217 currency code (e.g. IDR)")
+	cmd.Flags().StringVar(&validFrom, "from", "", "optional first departure date the fare applies to (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&validTo, "to", "", "optional last departure date the fare applies to (YYYY-MM-DD)")
+	_ = cmd.MarkFlagRequired("route")
+	_ = cmd.MarkFlagRequired("currency")
+	return cmd
+}
+
+func newFareListCmd() *cobra.Command {
+	var routeCode string
+	var limit, offset int
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List fares",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withFareUsecase(func(uc *usecase.FareUsecase) error {
+				items, err := uc.List(context.Background(), routeCode, limit, offset)
+				if err != nil {
+					return err
+				}
+				tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+				_, _ = fmt.Fprintln(tw, "ID\tROUTE\tONE WAY\tROUND TRIP\tFROM\tTO")
+				for _, f := range items {
+					_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", f.ID, f.RouteCode, f.OneWay, f.RoundTrip, dashIfEmpty(f.ValidFrom), dashIfEmpty(f.ValidTo))
+				}
+				return tw.Flush()
+			})
+		},
+	}
+	cmd.Flags().StringVar(&routeCode, "route", "", "optional route filter")
+	cmd.Flags().IntVar(&limit, "limit", 50, "max items to list")
+	cmd.Flags().IntVar(&offset, "offset", 0, "items to skip")
+	return cmd
+}
+
+func newFareDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Withdraw a fare by id",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			return withFareUsecase(func(uc *usecase.FareUsecase) error {
+				if err := uc.Delete(context.Background(), id); err != nil {
+					return err
+				}
+				fmt.Printf("deleted fare %d\n", id)
+				return nil
+			})
+		},
+	}
+}
+
+// formatFare renders a flight's one-way fare, or "-" when none is published.
+func formatFare(f *domain.Fare) string {
+	if f == nil {
+		return "-"
+	}
+	return f.OneWay.String()
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
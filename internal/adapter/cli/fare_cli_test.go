@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type fakeFareRepoCLI struct{ items []domain.Fare }
+
+func (f *fakeFareRepoCLI) Create(ctx context.Context, fare *domain.Fare) error {
+	fare.ID = int64(len(f.items) + 1)
+	f.items = append(f.items, *fare)
+	return nil
+}
+
+func (f *fakeFareRepoCLI) List(ctx context.Context, routeCode string, limit, offset int) ([]domain.Fare, error) {
+	var out []domain.Fare
+	for _, item := range f.items {
+		if routeCode == "" || item.RouteCode == routeCode {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeFareRepoCLI) Delete(ctx context.Context, id int64) error {
+	for i, item := range f.items {
+		if item.ID == id {
+			f.items = append(f.items[:i], f.items[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrFareNotFound
+}
+
+func TestFareCLI_Flow(t *testing.T) {
+	oldDB, oldFareRepo, oldRouteRepo := newFareDB, newFareRepo, newFareRouteRepo
+	t.Cleanup(func() {
+		newFareDB = oldDB
+		newFareRepo = oldFareRepo
+		newFareRouteRepo = oldRouteRepo
+	})
+	newFareDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	fares := &fakeFareRepoCLI{}
+	newFareRepo = func(*sqlx.DB) domain.FareRepository { return fares }
+	newFareRouteRepo = func(*sqlx.DB) domain.RouteRepository {
+		return &fakeRouteRepoCLI{data: map[string]domain.Route{"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}}}
+	}
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "fare", "create", "--route", "RT1", "--amount", "850000", "--round-trip", "1500000", "--currency", "IDR", "--from", "2025-03-01"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	})
+	if !strings.Contains(out, "850000.00 IDR one-way, 1500000.00 IDR round trip") {
+		t.Fatalf("unexpected create output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "fare", "create", "--route", "RT1", "--currency", "IDR"}
+	if err := Execute(); err == nil || !strings.Contains(err.Error(), "exactly one of --amount or --per-km") {
+		t.Fatalf("expected --amount or --per-km error, got %v", err)
+	}
+	os.Args = []string{"flight-booking", "fare", "create", "--route", "RT1", "--per-km", "1500", "--currency", "IDR"}
+	if err := Execute(); err != domain.ErrRouteDistanceUnknown {
+		t.Fatalf("want distance unknown, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "fare", "create", "--route", "RT9", "--amount", "1", "--currency", "IDR"}
+	if err := Execute(); err != domain.ErrRouteNotFound {
+		t.Fatalf("want route not found, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "fare", "list", "--route", "rt1"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("list: %v", err)
+		}
+	})
+	if !strings.Contains(out, "2025-03-01") || !strings.Contains(out, "850000.00 IDR") {
+		t.Fatalf("unexpected list output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "fare", "delete", "x"}
+	if err := Execute(); err == nil {
+		t.Fatalf("expected parse error")
+	}
+	os.Args = []string{"flight-booking", "fare", "delete", "1"}
+	if err := Execute(); err != nil || len(fares.items) != 0 {
+		t.Fatalf("delete: err=%v items=%+v", err, fares.items)
+	}
+}
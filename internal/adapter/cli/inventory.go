@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	sqlxrepo "github.com/ambiyansyah-risyal/flight-booking/internal/adapter/repository/sqlx"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/config"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
+)
+
+var newInventoryRepo = func(db *sqlx.DB) domain.SeatInventoryRepository { return sqlxrepo.NewSeatInventoryRepository(db) }
+
+func withInventoryUsecase(run func(*usecase.InventoryUsecase) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := newScheduleDB(cfg.Database.DSN())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return run(usecase.NewInventoryUsecase(newInventoryRepo(db)))
+}
+
+func newScheduleInventoryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "inventory <id>",
+		Short: "Show the seat inventory of a schedule",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			return withInventoryUsecase(func(uc *usecase.InventoryUsecase) error {
+				inv, err := uc.Get(context.Background(), id)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("schedule: %d\ncapacity: %d\nsold: %d\nheld: %d\nblocked: %d\navailable: %d\nupdated: %s\n", inv.ScheduleID, inv.Capacity, inv.Sold, inv.Held, inv.Blocked, inv.Available(), inv.UpdatedAt)
+				return nil
+			})
+		},
+	}
+}
+
+func newScheduleReconcileInventoryCmd() *cobra.Command {
+	var repair bool
+	cmd := &cobra.Command{
+		Use:   "reconcile-inventory",
+		Short: "Detect (and optionally repair) seat inventory drift against bookings",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withInventoryUsecase(func(uc *usecase.InventoryUsecase) error {
+				drifts, err := uc.Reconcile(context.Background(), repair)
+				if err != nil {
+					return err
+				}
+				if len(drifts) == 0 {
+					fmt.Println("seat inventory is consistent")
+					return nil
+				}
+				tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+				_, _ = fmt.Fprintln(tw, "SCHEDULE\tCAPACITY\tSOLD\tHELD\tEXPECTED CAPACITY\tEXPECTED SOLD\tEXPECTED HELD")
+				for _, d := range drifts {
+					recorded := [3]string{strconv.Itoa(d.Recorded.Capacity), strconv.Itoa(d.Recorded.Sold), strconv.Itoa(d.Recorded.Held)}
+					if d.Missing {
+						recorded = [3]string{"-", "-", "-"}
+					}
+					_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%d\t%d\n", d.Expected.ScheduleID, recorded[0], recorded[1], recorded[2], d.Expected.Capacity, d.Expected.Sold, d.Expected.Held)
+				}
+				if err := tw.Flush(); err != nil {
+					return err
+				}
+				if repair {
+					fmt.Printf("repaired %d schedule(s)\n", len(drifts))
+				} else {
+					fmt.Printf("%d schedule(s) drifted; rerun with --repair to fix\n", len(drifts))
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&repair, "repair", false, "rewrite drifted inventory rows from bookings")
+	return cmd
+}
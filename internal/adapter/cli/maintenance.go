@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/config"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+	"github.com/spf13/cobra"
+)
+
+func newAirplaneMaintenanceCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "maintenance", Short: "Manage airplane maintenance windows"}
+	cmd.AddCommand(newAirplaneMaintenanceAddCmd())
+	cmd.AddCommand(newAirplaneMaintenanceListCmd())
+	return cmd
+}
+
+func withMaintenanceUsecase(run func(*usecase.MaintenanceUsecase) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := newAirplaneDB(cfg.Database.DSN())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return run(usecase.NewMaintenanceUsecase(newMaintenanceRepo(db), newAirplaneRepoF(db)))
+}
+
+func newAirplaneMaintenanceAddCmd() *cobra.Command {
+	var code, from, to, reason string
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Take an airplane out of service for a period",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withMaintenanceUsecase(func(u *usecase.MaintenanceUsecase) error {
+				res, err := u.Add(context.Background(), code, from, to, reason)
+				if err != nil {
+					return err
+				}
+				w := res.Window
+				fmt.Printf("added maintenance %d for airplane %s from %s to %s\n", w.ID, w.AirplaneCode, formatLocalTime(w.StartsAt), formatLocalTime(w.EndsAt))
+				return writeMaintenanceConflicts(res.Conflicts)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&code, "code", "", "airplane code")
+	cmd.Flags().StringVar(&from, "from", "", "start in UTC, YYYY-MM-DD or YYYY-MM-DDTHH:MM (inclusive)")
+	cmd.Flags().StringVar(&to, "to", "", "end in UTC, YYYY-MM-DD or YYYY-MM-DDTHH:MM (exclusive)")
+	cmd.Flags().StringVar(&reason, "reason", "", "what the airplane is out of service for")
+	for _, name := range []string{"code", "from", "to"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+// writeMaintenanceConflicts lists the booked flights a new maintenance window
+// collides with; they keep their airplane until swapped.
+func writeMaintenanceConflicts(conflicts []domain.MaintenanceConflict) error {
+	if len(conflicts) == 0 {
+		fmt.Println("no booked flights fall in this window")
+		return nil
+	}
+	fmt.Printf("%d booked flight(s) fall in this window and need another airplane:\n", len(conflicts))
+	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SCHEDULE\tROUTE\tDEPARTS\tARRIVES\tBOOKED")
+	for _, c := range conflicts {
+		s := c.Schedule
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\n", s.ID, s.RouteCode, formatLocalTime(s.LocalDeparture()), formatLocalTime(s.LocalArrival()), c.Booked)
+	}
+	return tw.Flush()
+}
+
+func newAirplaneMaintenanceListCmd() *cobra.Command {
+	var code string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List an airplane's current and upcoming maintenance",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withMaintenanceUsecase(func(u *usecase.MaintenanceUsecase) error {
+				items, err := u.List(context.Background(), code)
+				if err != nil {
+					return err
+				}
+				tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+				_, _ = fmt.Fprintln(tw, "ID\tFROM\tTO\tREASON")
+				for _, w := range items {
+					_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", w.ID, formatLocalTime(w.StartsAt), formatLocalTime(w.EndsAt), dashIfEmpty(w.Reason))
+				}
+				return tw.Flush()
+			})
+		},
+	}
+	cmd.Flags().StringVar(&code, "code", "", "airplane code")
+	_ = cmd.MarkFlagRequired("code")
+	return cmd
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+)
+
+// writeReaccommodation prints the bookings left without a seat on a flight and
+// the flights on the same route that could take them.
+func writeReaccommodation(plan *domain.Reaccommodation) error {
+	if err := writeDisplaced(plan); err != nil {
+		return err
+	}
+	s := plan.Schedule
+	if len(plan.Alternatives) == 0 {
+		fmt.Printf("no later flight on %s has seats left\n", s.RouteCode)
+		return nil
+	}
+	fmt.Printf("later flights on %s with seats:\n", s.RouteCode)
+	return writeSeatOffers(plan.Alternatives)
+}
+
+// writeDisplaced prints the bookings of a flight that need another flight.
+func writeDisplaced(plan *domain.Reaccommodation) error {
+	s := plan.Schedule
+	fmt.Printf("schedule %d (%s %s): %d booking(s) need another flight\n", s.ID, s.RouteCode, s.DepartureDate, len(plan.Displaced))
+	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "REFERENCE\tPASSENGER\tSEAT\tSTATUS")
+	for _, b := range plan.Displaced {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", b.Reference, b.PassengerName, b.SeatNumber, b.Status)
+	}
+	return tw.Flush()
+}
+
+// writeSeatOffers prints flights with seats left.
+func writeSeatOffers(offers []domain.SeatOffer) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SCHEDULE\tROUTE\tDEPARTS\tAIRPLANE\tSEATS LEFT")
+	for _, o := range offers {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\n", o.Schedule.ID, o.Schedule.RouteCode, formatLocalTime(o.Schedule.LocalDeparture()), o.Schedule.AirplaneCode, o.SeatsLeft)
+	}
+	return tw.Flush()
+}
+
+// writeRouteChange prints the bookings a forced route update left on flights
+// that no longer serve their airports, with later flights between the old
+// airports that could take them.
+func writeRouteChange(change *usecase.RouteChange) error {
+	old := change.Previous
+	for i := range change.Reaccommodations {
+		plan := &change.Reaccommodations[i]
+		if err := writeDisplaced(plan); err != nil {
+			return err
+		}
+		if len(plan.Alternatives) == 0 {
+			fmt.Printf("no later flight from %s to %s has seats left\n", old.OriginCode, old.DestinationCode)
+			continue
+		}
+		fmt.Printf("later flights from %s to %s with seats:\n", old.OriginCode, old.DestinationCode)
+		if err := writeSeatOffers(plan.Alternatives); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSeatChange prints the bookings an airplane resize reseated and the
+// flights it left overbooked.
+func writeSeatChange(change *usecase.SeatChange) error {
+	if len(change.Moves) > 0 {
+		fmt.Printf("reseated %d booking(s)\n", len(change.Moves))
+		tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "SCHEDULE\tREFERENCE\tOLD SEAT\tNEW SEAT")
+		for _, m := range change.Moves {
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%d\t%d\n", m.ScheduleID, m.Reference, m.From, m.To)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(change.Overbooked) == 0 {
+		return nil
+	}
+	fmt.Printf("%d future flight(s) have more bookings than seats; they keep their seats until the bookings are moved and the change is made again\n", len(change.Overbooked))
+	if len(change.Reaccommodations) == 0 {
+		for _, f := range change.Overbooked {
+			fmt.Printf("schedule %d: %d booked for %d seat(s)\n", f.ScheduleID, f.Booked, f.Capacity)
+		}
+		return nil
+	}
+	for i := range change.Reaccommodations {
+		if err := writeReaccommodation(&change.Reaccommodations[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
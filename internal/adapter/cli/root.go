@@ -38,6 +38,8 @@ func newRootCmd() *cobra.Command {
 	cmd.AddCommand(newRouteCmd())
 	cmd.AddCommand(newScheduleCmd())
 	cmd.AddCommand(newBookingCmd())
+	cmd.AddCommand(newTicketCmd())
+	cmd.AddCommand(newFareCmd())
 
 	return cmd
 }
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+	"github.com/spf13/cobra"
+)
+
+func newScheduleValidateRotationCmd() *cobra.Command {
+	var airplaneCode string
+	cmd := &cobra.Command{
+		Use:   "validate-rotation",
+		Short: "Check that an airplane's flights chain in place and time",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withScheduleUsecase(func(uc *usecase.ScheduleUsecase) error {
+				report, err := uc.ValidateRotation(context.Background(), airplaneCode)
+				if err != nil {
+					return err
+				}
+				return writeRotationReport(report)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&airplaneCode, "airplane", "", "airplane code")
+	_ = cmd.MarkFlagRequired("airplane")
+	return cmd
+}
+
+// writeRotationReport prints the rotation and its issues, failing when there are any.
+func writeRotationReport(report *usecase.RotationReport) error {
+	fmt.Printf("airplane %s: %d flight(s), minimum turnaround %s\n", report.AirplaneCode, len(report.Legs), formatDuration(report.MinTurnaround))
+	if len(report.Legs) > 0 {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "SCHEDULE\tROUTE\tFROM\tTO\tDEPARTS\tARRIVES")
+		for _, l := range report.Legs {
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", l.Schedule.ID, l.Schedule.RouteCode, l.OriginCode, l.DestinationCode, formatLocalTime(l.Schedule.LocalDeparture()), formatLocalTime(l.Schedule.LocalArrival()))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(report.Issues) == 0 {
+		fmt.Println("rotation is continuous")
+		return nil
+	}
+	fmt.Printf("%d rotation issue(s):\n", len(report.Issues))
+	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "KIND\tPREVIOUS\tNEXT\tDETAIL")
+	for _, issue := range report.Issues {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", issue.Kind, issue.Previous.Schedule.ID, issue.Next.Schedule.ID, issue)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%d rotation issue(s)", len(report.Issues))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"text/tabwriter"
+	"time"
 
 	sqlxrepo "github.com/ambiyansyah-risyal/flight-booking/internal/adapter/repository/sqlx"
 	"github.com/ambiyansyah-risyal/flight-booking/internal/config"
@@ -18,7 +19,9 @@ func newRouteCmd() *cobra.Command {
 	cmd := &cobra.Command{Use: "route", Short: "Manage flight routes"}
 	cmd.AddCommand(newRouteCreateCmd())
 	cmd.AddCommand(newRouteListCmd())
+	cmd.AddCommand(newRouteUpdateCmd())
 	cmd.AddCommand(newRouteDeleteCmd())
+	cmd.AddCommand(newRouteRestoreCmd())
 	return cmd
 }
 
@@ -26,6 +29,10 @@ var (
 	newRouteDB          = func(dsn string) (*sqlx.DB, error) { return sqlxrepo.New(dsn) }
 	newRouteRepo        = func(db *sqlx.DB) domain.RouteRepository { return sqlxrepo.NewRouteRepository(db) }
 	newRouteAirportRepo = func(db *sqlx.DB) domain.AirportRepository { return sqlxrepo.NewAirportRepository(db) }
+	// Reaccommodating bookings on a moved route reads its flights and bookings.
+	newRouteScheduleRepo = func(db *sqlx.DB) domain.FlightScheduleRepository { return sqlxrepo.NewScheduleRepository(db) }
+	newRouteBookingRepo  = func(db *sqlx.DB) domain.BookingRepository { return sqlxrepo.NewBookingRepository(db) }
+	newRouteAirplaneRepo = func(db *sqlx.DB) domain.AirplaneRepository { return sqlxrepo.NewAirplaneRepository(db) }
 )
 
 func withRouteUsecase(run func(*usecase.RouteUsecase) error) error {
@@ -38,12 +45,13 @@ func withRouteUsecase(run func(*usecase.RouteUsecase) error) error {
 		return err
 	}
 	defer func() { _ = db.Close() }()
-	uc := usecase.NewRouteUsecase(newRouteRepo(db), newRouteAirportRepo(db))
+	uc := usecase.NewRouteUsecase(newRouteRepo(db), newRouteAirportRepo(db)).WithReaccommodation(newRouteScheduleRepo(db), newRouteBookingRepo(db), newRouteAirplaneRepo(db))
 	return run(uc)
 }
 
 func newRouteCreateCmd() *cobra.Command {
 	var code, origin, destination string
+	var block time.Duration
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new route",
@@ -53,7 +61,13 @@ func newRouteCreateCmd() *cobra.Command {
 				if err != nil {
 					return err
 				}
-				fmt.Printf("created route %s (%s -> %s)\n", route.Code, route.OriginCode, route.DestinationCode)
+				fmt.Printf("created route %s (%s -> %s, %s)\n", route.Code, route.OriginCode, route.DestinationCode, formatDistance(route.DistanceKm))
+				if cmd.Flags().Changed("block") {
+					if err := uc.SetBlockTime(context.Background(), route.Code, block); err != nil {
+						return err
+					}
+					fmt.Printf("block time of %s: %s\n", route.Code, formatDuration(block))
+				}
 				return nil
 			})
 		},
@@ -61,6 +75,7 @@ func newRouteCreateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&code, "code", "", "route code identifier")
 	cmd.Flags().StringVar(&origin, "origin", "", "origin airport code")
 	cmd.Flags().StringVar(&destination, "destination", "", "destination airport code")
+	cmd.Flags().DurationVar(&block, "block", 0, "scheduled block time, the default flight duration (e.g. 1h50m)")
 	_ = cmd.MarkFlagRequired("code")
 	_ = cmd.MarkFlagRequired("origin")
 	_ = cmd.MarkFlagRequired("destination")
@@ -69,19 +84,28 @@ func newRouteCreateCmd() *cobra.Command {
 
 func newRouteListCmd() *cobra.Command {
 	var limit, offset int
+	var includeArchived bool
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List routes",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return withRouteUsecase(func(uc *usecase.RouteUsecase) error {
-				routes, err := uc.List(context.Background(), limit, offset)
+				routes, err := uc.List(context.Background(), limit, offset, includeArchived)
 				if err != nil {
 					return err
 				}
 				tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
-				_, _ = fmt.Fprintln(tw, "CODE\tORIGIN\tDESTINATION")
+				header := "CODE\tORIGIN\tDESTINATION\tDISTANCE\tBLOCK"
+				if includeArchived {
+					header += "\tARCHIVED"
+				}
+				_, _ = fmt.Fprintln(tw, header)
 				for _, r := range routes {
-					_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Code, r.OriginCode, r.DestinationCode)
+					row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", r.Code, r.OriginCode, r.DestinationCode, formatDistance(r.DistanceKm), formatDuration(r.BlockTime()))
+					if includeArchived {
+						row += "\t" + archivedColumn(r.ArchivedAt)
+					}
+					_, _ = fmt.Fprintln(tw, row)
 				}
 				return tw.Flush()
 			})
@@ -89,24 +113,106 @@ func newRouteListCmd() *cobra.Command {
 	}
 	cmd.Flags().IntVar(&limit, "limit", 50, "max items to list")
 	cmd.Flags().IntVar(&offset, "offset", 0, "items to skip")
+	cmd.Flags().BoolVar(&includeArchived, "include-archived", false, includeArchivedUsage)
+	return cmd
+}
+
+func newRouteUpdateCmd() *cobra.Command {
+	var origin, destination string
+	var block time.Duration
+	var reaccommodate bool
+	cmd := &cobra.Command{
+		Use:   "update <code>",
+		Short: "Move a route to other airports or change its block time",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			moving := cmd.Flags().Changed("origin") || cmd.Flags().Changed("destination")
+			if !moving && !cmd.Flags().Changed("block") {
+				return fmt.Errorf("at least one of --origin, --destination or --block is required")
+			}
+			code := args[0]
+			return withRouteUsecase(func(uc *usecase.RouteUsecase) error {
+				if moving {
+					change, err := uc.Update(context.Background(), code, origin, destination, reaccommodate)
+					if err != nil {
+						return err
+					}
+					r, old := change.Route, change.Previous
+					fmt.Printf("updated route %s: %s -> %s is now %s -> %s (%s)\n", r.Code, old.OriginCode, old.DestinationCode, r.OriginCode, r.DestinationCode, formatDistance(r.DistanceKm))
+					if err := writeRouteChange(change); err != nil {
+						return err
+					}
+				}
+				if cmd.Flags().Changed("block") {
+					if err := uc.SetBlockTime(context.Background(), code, block); err != nil {
+						return err
+					}
+					fmt.Printf("updated route %s block time -> %s\n", code, formatDuration(block))
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&origin, "origin", "", "new origin airport code")
+	cmd.Flags().StringVar(&destination, "destination", "", "new destination airport code")
+	cmd.Flags().DurationVar(&block, "block", 0, "scheduled block time (e.g. 1h50m); 0 clears it")
+	cmd.Flags().BoolVar(&reaccommodate, "reaccommodate", false, "move the route even when future flights have bookings, and list those bookings with other flights between the old airports")
 	return cmd
 }
 
+// formatDistance renders a route distance, or "-" when it is unknown.
+func formatDistance(km int) string {
+	if km <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d km", km)
+}
+
 func newRouteDeleteCmd() *cobra.Command {
+	var purge, cascade, yes bool
 	cmd := &cobra.Command{
 		Use:   "delete <code>",
-		Short: "Delete a route by code",
+		Short: "Archive a route by code, or with --purge delete it; purging is refused while flights or fares use it unless --cascade",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requirePurge(purge, cascade); err != nil {
+				return err
+			}
 			code := args[0]
 			return withRouteUsecase(func(uc *usecase.RouteUsecase) error {
-				if err := uc.Delete(context.Background(), code); err != nil {
+				if !purge {
+					if err := uc.Archive(context.Background(), code); err != nil {
+						return err
+					}
+					printArchived(domain.EntityRoute, code)
+					return nil
+				}
+				return deleteWith(domain.EntityRoute, code, cascade, yes, func(d *domain.Dependents) (domain.Dependents, error) {
+					return uc.Delete(context.Background(), code, d)
+				})
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&purge, "purge", false, purgeUsage)
+	cmd.Flags().BoolVar(&cascade, "cascade", false, purgeCascadeUsage)
+	cmd.Flags().BoolVar(&yes, "yes", false, yesUsage)
+	return cmd
+}
+
+func newRouteRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <code>",
+		Short: "Restore an archived route",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			code := args[0]
+			return withRouteUsecase(func(uc *usecase.RouteUsecase) error {
+				if err := uc.Restore(context.Background(), code); err != nil {
 					return err
 				}
-				fmt.Printf("deleted route %s\n", code)
+				fmt.Printf("restored route %s\n", code)
 				return nil
 			})
 		},
 	}
-	return cmd
 }
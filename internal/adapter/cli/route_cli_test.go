@@ -2,16 +2,25 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
 	"github.com/jmoiron/sqlx"
 )
 
-type fakeRouteRepoCLI struct{ data map[string]domain.Route }
+type fakeRouteRepoCLI struct {
+	data   map[string]domain.Route
+	booked []domain.BookedFlight
+	used   domain.Dependents
+	// booksMeanwhile adds bookings after each refused delete counted them.
+	booksMeanwhile int
+}
 
 func (f *fakeRouteRepoCLI) Create(ctx context.Context, r *domain.Route) error {
 	if f.data == nil {
@@ -31,39 +40,104 @@ func (f *fakeRouteRepoCLI) GetByCode(ctx context.Context, code string) (*domain.
 	return nil, domain.ErrRouteNotFound
 }
 
-func (f *fakeRouteRepoCLI) List(ctx context.Context, limit, offset int) ([]domain.Route, error) {
+func (f *fakeRouteRepoCLI) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Route, error) {
 	var routes []domain.Route
 	for _, r := range f.data {
-		routes = append(routes, r)
+		if includeArchived || !r.Archived() {
+			routes = append(routes, r)
+		}
 	}
 	return routes, nil
 }
 
-func (f *fakeRouteRepoCLI) Delete(ctx context.Context, code string) error {
+func (f *fakeRouteRepoCLI) SetArchived(ctx context.Context, code string, archived bool) error {
+	r, ok := f.data[code]
+	if !ok {
+		return domain.ErrRouteNotFound
+	}
+	r.ArchivedAt = ""
+	if archived {
+		r.ArchivedAt = "2025-01-01T00:00:00Z"
+	}
+	f.data[code] = r
+	return nil
+}
+
+func (f *fakeRouteRepoCLI) SetBlockTime(ctx context.Context, code string, minutes int) error {
+	r, ok := f.data[code]
+	if !ok {
+		return domain.ErrRouteNotFound
+	}
+	r.BlockMinutes = minutes
+	f.data[code] = r
+	return nil
+}
+
+func (f *fakeRouteRepoCLI) Update(ctx context.Context, r *domain.Route, from time.Time, force bool) ([]domain.BookedFlight, error) {
+	current, ok := f.data[r.Code]
+	if !ok {
+		return nil, domain.ErrRouteNotFound
+	}
+	if len(f.booked) > 0 && !force {
+		return nil, &domain.RouteBookingsError{RouteCode: r.Code, Flights: f.booked}
+	}
+	current.OriginCode, current.DestinationCode = r.OriginCode, r.DestinationCode
+	f.data[r.Code] = current
+	return f.booked, nil
+}
+
+func (f *fakeRouteRepoCLI) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
 	if f.data == nil {
 		f.data = make(map[string]domain.Route)
 	}
 	if _, ok := f.data[code]; !ok {
-		return domain.ErrRouteNotFound
+		return domain.Dependents{}, domain.ErrRouteNotFound
+	}
+	if !f.used.Empty() && cascade == nil {
+		used := f.used
+		f.used.Bookings += f.booksMeanwhile
+		return used, &domain.InUseError{Entity: domain.EntityRoute, Key: code, Dependents: used}
+	}
+	if cascade != nil && *cascade != f.used {
+		return f.used, domain.ErrDependentsChanged
 	}
 	delete(f.data, code)
-	return nil
+	return f.used, nil
 }
 
-type fakeAirportRepoCLI struct{ existing map[string]bool }
+type fakeAirportRepoCLI struct{ existing, archived map[string]bool }
 
 func (f *fakeAirportRepoCLI) Create(ctx context.Context, a *domain.Airport) error { return nil }
 func (f *fakeAirportRepoCLI) GetByCode(ctx context.Context, code string) (*domain.Airport, error) {
 	if f.existing != nil && f.existing[code] {
-		return &domain.Airport{Code: code}, nil
+		a := &domain.Airport{Code: code}
+		if f.archived[code] {
+			a.ArchivedAt = "2025-01-01T00:00:00Z"
+		}
+		return a, nil
 	}
 	return nil, domain.ErrAirportNotFound
 }
-func (f *fakeAirportRepoCLI) List(ctx context.Context, limit, offset int) ([]domain.Airport, error) {
+func (f *fakeAirportRepoCLI) SetArchived(ctx context.Context, code string, archived bool) error {
+	return nil
+}
+
+func (f *fakeAirportRepoCLI) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airport, error) {
 	return nil, nil
 }
 func (f *fakeAirportRepoCLI) Update(ctx context.Context, code string, city string) error { return nil }
-func (f *fakeAirportRepoCLI) Delete(ctx context.Context, code string) error              { return nil }
+func (f *fakeAirportRepoCLI) SetTimeZone(ctx context.Context, code string, timeZone string) error {
+	return nil
+}
+func (f *fakeAirportRepoCLI) SetMinConnection(ctx context.Context, code string, minutes int) error {
+	return nil
+}
+func (f *fakeAirportRepoCLI) SetPosition(ctx context.Context, code string, position *domain.Coordinates) error {
+	return nil
+}
+func (f *fakeAirportRepoCLI) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	return domain.Dependents{}, nil
+}
 
 func TestRouteCLI_Flow(t *testing.T) {
 	oldDB, oldRouteRepo, oldAirportRepo := newRouteDB, newRouteRepo, newRouteAirportRepo
@@ -97,8 +171,91 @@ func TestRouteCLI_Flow(t *testing.T) {
 	}
 
 	os.Args = []string{"flight-booking", "route", "delete", "RT1"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+	})
+	if !strings.Contains(out, "archived route RT1") || !routes.data["RT1"].Archived() {
+		t.Fatalf("delete should archive the route, got %q", out)
+	}
+	os.Args = []string{"flight-booking", "route", "list"}
+	out = captureOutput(func() { _ = Execute() })
+	if strings.Contains(out, "RT1") {
+		t.Fatalf("archived route listed: %q", out)
+	}
+	os.Args = []string{"flight-booking", "route", "list", "--include-archived"}
+	out = captureOutput(func() { _ = Execute() })
+	if !strings.Contains(out, "RT1") || !strings.Contains(out, "ARCHIVED") {
+		t.Fatalf("expected archived route listed: %q", out)
+	}
+
+	airports.archived = map[string]bool{"DPS": true}
+	os.Args = []string{"flight-booking", "route", "create", "--code", "RT2", "--origin", "CGK", "--destination", "DPS"}
+	if err := Execute(); !errors.Is(err, domain.ErrAirportArchived) {
+		t.Fatalf("want airport archived, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "route", "restore", "RT1"}
 	if err := Execute(); err != nil {
-		t.Fatalf("delete: %v", err)
+		t.Fatalf("restore: %v", err)
+	}
+	if routes.data["RT1"].Archived() {
+		t.Fatalf("route not restored")
+	}
+}
+
+func TestRouteCLI_DistanceAndBlockTime(t *testing.T) {
+	oldDB, oldRouteRepo, oldAirportRepo := newRouteDB, newRouteRepo, newRouteAirportRepo
+	t.Cleanup(func() {
+		newRouteDB = oldDB
+		newRouteRepo = oldRouteRepo
+		newRouteAirportRepo = oldAirportRepo
+	})
+	newRouteDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	routes := &fakeRouteRepoCLI{data: map[string]domain.Route{}}
+	newRouteRepo = func(*sqlx.DB) domain.RouteRepository { return routes }
+	newRouteAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true}}
+	}
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "route", "create", "--code", "RT1", "--origin", "CGK", "--destination", "DPS", "--block", "1h50m"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	})
+	if !strings.Contains(out, "block time of RT1: 1h50m") || routes.data["RT1"].BlockMinutes != 110 {
+		t.Fatalf("unexpected create output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "route", "update", "RT1", "--block", "2h"}
+	if err := Execute(); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	os.Args = []string{"flight-booking", "route", "update", "RT1"}
+	if err := Execute(); err == nil {
+		t.Fatalf("expected error without --block")
+	}
+
+	r := routes.data["RT1"]
+	r.DistanceKm = 983
+	routes.data["RT1"] = r
+	os.Args = []string{"flight-booking", "route", "list"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("list: %v", err)
+		}
+	})
+	if !strings.Contains(out, "DISTANCE") || !strings.Contains(out, "983 km") || !strings.Contains(out, "2h00m") {
+		t.Fatalf("unexpected list output %q", out)
 	}
 }
 
@@ -132,3 +289,128 @@ func TestRouteCLI_DeleteNotFound(t *testing.T) {
 		t.Fatalf("expected delete error")
 	}
 }
+
+func TestRouteCLI_UpdateAirports(t *testing.T) {
+	oldDB, oldRouteRepo, oldAirportRepo := newRouteDB, newRouteRepo, newRouteAirportRepo
+	oldScheduleRepo, oldBookingRepo, oldAirplaneRepo := newRouteScheduleRepo, newRouteBookingRepo, newRouteAirplaneRepo
+	t.Cleanup(func() {
+		newRouteDB = oldDB
+		newRouteRepo = oldRouteRepo
+		newRouteAirportRepo = oldAirportRepo
+		newRouteScheduleRepo = oldScheduleRepo
+		newRouteBookingRepo = oldBookingRepo
+		newRouteAirplaneRepo = oldAirplaneRepo
+	})
+	newRouteDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	t0 := time.Now().Add(24 * time.Hour).UTC()
+	routes := &fakeRouteRepoCLI{data: map[string]domain.Route{
+		"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"},
+		"RT2": {Code: "RT2", OriginCode: "CGK", DestinationCode: "DPS"},
+	}}
+	schedules := &fakeScheduleRepoCLI{items: map[int64]domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: t0.Format("2006-01-02"), DepartureAt: t0},
+		2: {ID: 2, RouteCode: "RT2", AirplaneCode: "A320", DepartureDate: t0.Format("2006-01-02"), DepartureAt: t0.Add(3 * time.Hour)},
+	}}
+	bookings := newFakeBookingRepoCLI()
+	_ = bookings.Create(context.Background(), &domain.Booking{Reference: "AAAAAA", ScheduleID: 1, SeatNumber: 1, Status: domain.BookingStatusConfirmed})
+	airplanes := newFakeAirplaneRepoBookingCLI()
+	airplanes.items["A320"] = domain.Airplane{Code: "A320", SeatCapacity: 180}
+	newRouteRepo = func(*sqlx.DB) domain.RouteRepository { return routes }
+	newRouteAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true, "SUB": true}}
+	}
+	newRouteScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
+	newRouteBookingRepo = func(*sqlx.DB) domain.BookingRepository { return bookings }
+	newRouteAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository { return airplanes }
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	routes.booked = []domain.BookedFlight{{Schedule: schedules.items[1], Booked: 1}}
+	os.Args = []string{"flight-booking", "route", "update", "RT1", "--destination", "SUB"}
+	if err := Execute(); err == nil || !strings.Contains(err.Error(), "schedule 1 (1 booked)") {
+		t.Fatalf("expected refusal listing the booked flight, got %v", err)
+	}
+	if routes.data["RT1"].DestinationCode != "DPS" {
+		t.Fatalf("refused update moved the route")
+	}
+
+	os.Args = []string{"flight-booking", "route", "update", "RT1", "--destination", "SUB", "--reaccommodate"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	})
+	if !strings.Contains(out, "CGK -> DPS is now CGK -> SUB") || !strings.Contains(out, "AAAAAA") || !strings.Contains(out, "later flights from CGK to DPS with seats") {
+		t.Fatalf("unexpected update output %q", out)
+	}
+	if routes.data["RT1"].DestinationCode != "SUB" {
+		t.Fatalf("route not moved: %+v", routes.data["RT1"])
+	}
+}
+
+func TestRouteCLI_DeleteCascade(t *testing.T) {
+	oldDB, oldRouteRepo, oldAirportRepo := newRouteDB, newRouteRepo, newRouteAirportRepo
+	t.Cleanup(func() {
+		newRouteDB = oldDB
+		newRouteRepo = oldRouteRepo
+		newRouteAirportRepo = oldAirportRepo
+	})
+	newRouteDB = func(string) (*sqlx.DB, error) {
+		db, _, _ := sqlmock.New()
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	routes := &fakeRouteRepoCLI{
+		data: map[string]domain.Route{"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}},
+		used: domain.Dependents{Schedules: 2, Bookings: 3, Fares: 1},
+	}
+	newRouteRepo = func(*sqlx.DB) domain.RouteRepository { return routes }
+	newRouteAirportRepo = func(*sqlx.DB) domain.AirportRepository { return &fakeAirportRepoCLI{} }
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "route", "delete", "RT1", "--purge"}
+	err := Execute()
+	if err == nil || !strings.Contains(err.Error(), "would remove 2 schedule(s), 3 booking(s), 1 fare(s)") || !strings.Contains(err.Error(), "--cascade") {
+		t.Fatalf("expected refusal listing dependents, got %v", err)
+	}
+	if _, ok := routes.data["RT1"]; !ok {
+		t.Fatalf("refused delete removed the route")
+	}
+
+	oldInput := confirmInput
+	t.Cleanup(func() { confirmInput = oldInput })
+	os.Args = []string{"flight-booking", "route", "delete", "RT1", "--purge", "--cascade"}
+	confirmInput = strings.NewReader("n\n")
+	if err := Execute(); err == nil || !strings.Contains(err.Error(), "not confirmed") {
+		t.Fatalf("expected an unconfirmed delete to fail, got %v", err)
+	}
+	if _, ok := routes.data["RT1"]; !ok {
+		t.Fatalf("unconfirmed delete removed the route")
+	}
+
+	// A booking made between the prompt and the delete stops it.
+	routes.booksMeanwhile = 1
+	os.Args = []string{"flight-booking", "route", "delete", "RT1", "--purge", "--cascade", "--yes"}
+	if err := Execute(); !errors.Is(err, domain.ErrDependentsChanged) {
+		t.Fatalf("want dependents changed, got %v", err)
+	}
+	if _, ok := routes.data["RT1"]; !ok {
+		t.Fatalf("changed delete removed the route")
+	}
+	routes.booksMeanwhile = 0
+
+	os.Args = []string{"flight-booking", "route", "delete", "RT1", "--purge", "--cascade"}
+	confirmInput = strings.NewReader("y\n")
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("cascade delete: %v", err)
+		}
+	})
+	if !strings.Contains(out, "deleting route RT1 also removes 2 schedule(s), 4 booking(s), 1 fare(s)") || !strings.Contains(out, "deleted route RT1 with 2 schedule(s), 4 booking(s), 1 fare(s)") {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
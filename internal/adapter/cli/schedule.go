@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"text/tabwriter"
+	"time"
 
 	sqlxrepo "github.com/ambiyansyah-risyal/flight-booking/internal/adapter/repository/sqlx"
 	"github.com/ambiyansyah-risyal/flight-booking/internal/config"
@@ -18,16 +19,32 @@ import (
 func newScheduleCmd() *cobra.Command {
 	cmd := &cobra.Command{Use: "schedule", Short: "Manage flight schedules"}
 	cmd.AddCommand(newScheduleCreateCmd())
+	cmd.AddCommand(newScheduleCreateSeriesCmd())
+	cmd.AddCommand(newScheduleSeriesCmd())
+	cmd.AddCommand(newScheduleImportCmd())
+	cmd.AddCommand(newScheduleExportCmd())
 	cmd.AddCommand(newScheduleListCmd())
 	cmd.AddCommand(newScheduleDeleteCmd())
+	cmd.AddCommand(newScheduleSwapAircraftCmd())
+	cmd.AddCommand(newScheduleCancelCmd())
+	cmd.AddCommand(newScheduleDisruptionsCmd())
+	cmd.AddCommand(newScheduleDelayCmd())
+	cmd.AddCommand(newScheduleGateCmd())
+	cmd.AddCommand(newScheduleValidateRotationCmd())
+	cmd.AddCommand(newScheduleInventoryCmd())
+	cmd.AddCommand(newScheduleReconcileInventoryCmd())
 	return cmd
 }
 
 var (
-	newScheduleDB           = func(dsn string) (*sqlx.DB, error) { return sqlxrepo.New(dsn) }
-	newScheduleRepo         = func(db *sqlx.DB) domain.FlightScheduleRepository { return sqlxrepo.NewScheduleRepository(db) }
-	newScheduleRouteRepo    = func(db *sqlx.DB) domain.RouteRepository { return sqlxrepo.NewRouteRepository(db) }
-	newScheduleAirplaneRepo = func(db *sqlx.DB) domain.AirplaneRepository { return sqlxrepo.NewAirplaneRepository(db) }
+	newScheduleDB              = func(dsn string) (*sqlx.DB, error) { return sqlxrepo.New(dsn) }
+	newScheduleRepo            = func(db *sqlx.DB) domain.FlightScheduleRepository { return sqlxrepo.NewScheduleRepository(db) }
+	newScheduleRouteRepo       = func(db *sqlx.DB) domain.RouteRepository { return sqlxrepo.NewRouteRepository(db) }
+	newScheduleAirplaneRepo    = func(db *sqlx.DB) domain.AirplaneRepository { return sqlxrepo.NewAirplaneRepository(db) }
+	newScheduleAirportRepo     = func(db *sqlx.DB) domain.AirportRepository { return sqlxrepo.NewAirportRepository(db) }
+	newScheduleBookingRepo     = func(db *sqlx.DB) domain.BookingRepository { return sqlxrepo.NewBookingRepository(db) }
+	newScheduleMaintenanceRepo = func(db *sqlx.DB) domain.MaintenanceRepository { return sqlxrepo.NewMaintenanceRepository(db) }
+	newScheduleTripRepo        = func(db *sqlx.DB) domain.TripRepository { return sqlxrepo.NewTripRepository(db) }
 )
 
 func withScheduleUsecase(run func(*usecase.ScheduleUsecase) error) error {
@@ -40,22 +57,32 @@ func withScheduleUsecase(run func(*usecase.ScheduleUsecase) error) error {
 		return err
 	}
 	defer func() { _ = db.Close() }()
-	uc := usecase.NewScheduleUsecase(newScheduleRepo(db), newScheduleRouteRepo(db), newScheduleAirplaneRepo(db))
+	uc := usecase.NewScheduleUsecase(newScheduleRepo(db), newScheduleRouteRepo(db), newScheduleAirplaneRepo(db), newScheduleAirportRepo(db)).
+		WithBookings(newScheduleBookingRepo(db)).
+		WithMaintenance(newScheduleMaintenanceRepo(db)).
+		WithMinTurnaround(cfg.Rotation.MinTurnaround).
+		WithStrictRotation(cfg.Rotation.Strict).
+		WithTrips(newScheduleTripRepo(db)).
+		WithMinConnection(cfg.Transit.MinConnection)
 	return run(uc)
 }
 
 func newScheduleCreateCmd() *cobra.Command {
-	var routeCode, airplaneCode, departureDate string
+	var routeCode, airplaneCode, departureDate, departureTime, arrivalTime string
+	var strictRotation bool
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new flight schedule",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return withScheduleUsecase(func(uc *usecase.ScheduleUsecase) error {
-				sched, err := uc.Create(context.Background(), routeCode, airplaneCode, departureDate)
+				if strictRotation {
+					uc.WithStrictRotation(true)
+				}
+				sched, err := uc.Create(context.Background(), routeCode, airplaneCode, departureDate, departureTime, arrivalTime)
 				if err != nil {
 					return err
 				}
-				fmt.Printf("scheduled route %s with %s on %s\n", sched.RouteCode, sched.AirplaneCode, sched.DepartureDate)
+				fmt.Printf("scheduled route %s with %s on %s departing %s arriving %s\n", sched.RouteCode, sched.AirplaneCode, sched.DepartureDate, formatLocalTime(sched.LocalDeparture()), formatLocalTime(sched.LocalArrival()))
 				return nil
 			})
 		},
@@ -63,6 +90,9 @@ func newScheduleCreateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&routeCode, "route", "", "route code")
 	cmd.Flags().StringVar(&airplaneCode, "airplane", "", "airplane code")
 	cmd.Flags().StringVar(&departureDate, "date", "", "departure date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&departureTime, "time", "", "local departure time at the origin (HH:MM, default 00:00)")
+	cmd.Flags().StringVar(&arrivalTime, "arrival", "", "local arrival time at the destination (HH:MM)")
+	cmd.Flags().BoolVar(&strictRotation, "strict-rotation", false, "reject the flight unless it chains with the airplane's other flights (default from FLIGHT_ROTATION_STRICT)")
 	_ = cmd.MarkFlagRequired("route")
 	_ = cmd.MarkFlagRequired("airplane")
 	_ = cmd.MarkFlagRequired("date")
@@ -82,9 +112,13 @@ func newScheduleListCmd() *cobra.Command {
 					return err
 				}
 				tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
-				_, _ = fmt.Fprintln(tw, "ID\tROUTE\tAIRPLANE\tDEPARTURE")
+				_, _ = fmt.Fprintln(tw, "ID\tROUTE\tAIRPLANE\tDEPARTURE\tDEPARTS\tARRIVES\tSERIES\tSTATUS")
 				for _, s := range items {
-					_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", s.ID, s.RouteCode, s.AirplaneCode, s.DepartureDate)
+					series := "-"
+					if s.SeriesID != 0 {
+						series = strconv.FormatInt(s.SeriesID, 10)
+					}
+					_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.RouteCode, s.AirplaneCode, s.DepartureDate, formatLocalTime(s.LocalDeparture()), formatLocalTime(s.LocalArrival()), series, dashIfEmpty(s.Status))
 				}
 				return tw.Flush()
 			})
@@ -97,23 +131,235 @@ func newScheduleListCmd() *cobra.Command {
 }
 
 func newScheduleDeleteCmd() *cobra.Command {
+	var cascade, yes bool
 	cmd := &cobra.Command{
 		Use:   "delete <id>",
-		Short: "Delete a scheduled flight by id",
+		Short: "Delete a scheduled flight by id; refused while it has bookings unless --cascade",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			return withScheduleUsecase(func(uc *usecase.ScheduleUsecase) error {
+				return deleteWith(domain.EntitySchedule, args[0], cascade, yes, func(d *domain.Dependents) (domain.Dependents, error) {
+					return uc.Delete(context.Background(), id, d)
+				})
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&cascade, "cascade", false, cascadeUsage)
+	cmd.Flags().BoolVar(&yes, "yes", false, yesUsage)
+	return cmd
+}
+
+func newScheduleSwapAircraftCmd() *cobra.Command {
+	var airplaneCode string
+	cmd := &cobra.Command{
+		Use:   "swap-aircraft <id>",
+		Short: "Fly a schedule with another airplane, keeping its bookings",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			return withScheduleUsecase(func(uc *usecase.ScheduleUsecase) error {
+				res, err := uc.SwapAirplane(context.Background(), id, airplaneCode)
+				if err != nil {
+					if res != nil && res.Reaccommodation != nil {
+						if werr := writeReaccommodation(res.Reaccommodation); werr != nil {
+							return werr
+						}
+					}
+					return err
+				}
+				fmt.Printf("schedule %d: swapped %s -> %s, reseated %d booking(s)\n", id, res.PreviousAirplane, res.Schedule.AirplaneCode, len(res.Moves))
+				if len(res.Moves) == 0 {
+					return nil
+				}
+				tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+				_, _ = fmt.Fprintln(tw, "REFERENCE\tOLD SEAT\tNEW SEAT")
+				for _, m := range res.Moves {
+					_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\n", m.Reference, m.From, m.To)
+				}
+				return tw.Flush()
+			})
+		},
+	}
+	cmd.Flags().StringVar(&airplaneCode, "airplane", "", "code of the airplane to fly the schedule")
+	_ = cmd.MarkFlagRequired("airplane")
+	return cmd
+}
+
+func newScheduleCancelCmd() *cobra.Command {
+	var reason string
+	cmd := &cobra.Command{
+		Use:   "cancel <id>",
+		Short: "Cancel a flight, keeping its bookings to be rebooked or refunded",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			return withScheduleUsecase(func(uc *usecase.ScheduleUsecase) error {
+				plan, err := uc.Cancel(context.Background(), id, reason)
+				if err != nil {
+					return err
+				}
+				s := plan.Schedule
+				fmt.Printf("cancelled schedule %d (%s %s): %s\n", s.ID, s.RouteCode, s.DepartureDate, s.CancelReason)
+				if len(plan.Displaced) == 0 {
+					fmt.Println("no bookings affected")
+					return nil
+				}
+				return writeReaccommodation(plan)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "why the flight is cancelled, up to 200 characters")
+	_ = cmd.MarkFlagRequired("reason")
+	return cmd
+}
+
+func newScheduleDisruptionsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "disruptions",
+		Short: "List bookings on cancelled flights still to be rebooked or refunded",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withScheduleUsecase(func(uc *usecase.ScheduleUsecase) error {
+				plans, err := uc.Disruptions(context.Background())
+				if err != nil {
+					return err
+				}
+				if len(plans) == 0 {
+					fmt.Println("no disrupted bookings")
+					return nil
+				}
+				for i := range plans {
+					fmt.Printf("schedule %d cancelled: %s\n", plans[i].Schedule.ID, plans[i].Schedule.CancelReason)
+					if err := writeReaccommodation(&plans[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func newScheduleDelayCmd() *cobra.Command {
+	var minutes int
+	var reason string
+	cmd := &cobra.Command{
+		Use:   "delay <id>",
+		Short: "Record a flight delay and report its knock-on flights and broken connections",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			return withScheduleUsecase(func(uc *usecase.ScheduleUsecase) error {
+				impact, err := uc.Delay(context.Background(), id, minutes, reason)
+				if err != nil {
+					return err
+				}
+				return writeDelayImpact(impact)
+			})
+		},
+	}
+	cmd.Flags().IntVar(&minutes, "minutes", 0, "minutes later than planned the flight is expected; 0 puts it back on time")
+	cmd.Flags().StringVar(&reason, "reason", "", "why the flight is delayed, up to 200 characters")
+	_ = cmd.MarkFlagRequired("minutes")
+	return cmd
+}
+
+func newScheduleGateCmd() *cobra.Command {
+	var departureGate, arrivalGate string
+	cmd := &cobra.Command{
+		Use:   "gate <id>",
+		Short: "Assign a flight's departure and arrival gates",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			id, err := strconv.ParseInt(args[0], 10, 64)
 			if err != nil {
 				return fmt.Errorf("parse id: %w", err)
 			}
+			if !cmd.Flags().Changed("departure") && !cmd.Flags().Changed("arrival") {
+				return fmt.Errorf("at least one of --departure or --arrival is required")
+			}
+			var departure, arrival *string
+			if cmd.Flags().Changed("departure") {
+				departure = &departureGate
+			}
+			if cmd.Flags().Changed("arrival") {
+				arrival = &arrivalGate
+			}
 			return withScheduleUsecase(func(uc *usecase.ScheduleUsecase) error {
-				if err := uc.Delete(context.Background(), id); err != nil {
+				s, err := uc.SetGates(context.Background(), id, departure, arrival)
+				if err != nil {
 					return err
 				}
-				fmt.Printf("deleted schedule %d\n", id)
+				fmt.Printf("schedule %d (%s %s) departure gate %s, arrival gate %s\n", s.ID, s.RouteCode, s.DepartureDate, dashIfEmpty(s.DepartureGate), dashIfEmpty(s.ArrivalGate))
 				return nil
 			})
 		},
 	}
+	cmd.Flags().StringVar(&departureGate, "departure", "", "gate at the origin airport, up to 8 characters; empty clears it")
+	cmd.Flags().StringVar(&arrivalGate, "arrival", "", "gate at the destination airport, up to 8 characters; empty clears it")
 	return cmd
 }
+
+// writeDelayImpact prints the flights a delay holds up, the connections it
+// breaks and the notifications it queued.
+func writeDelayImpact(impact *usecase.DelayImpact) error {
+	s := impact.Delayed[0]
+	if s.DelayMinutes == 0 {
+		fmt.Printf("schedule %d (%s %s) is back on time\n", s.ID, s.RouteCode, s.DepartureDate)
+	} else {
+		fmt.Printf("schedule %d (%s %s) delayed %dm: %s\n", s.ID, s.RouteCode, s.DepartureDate, s.DelayMinutes, s.DelayReason)
+	}
+	if len(impact.Delayed) > 1 {
+		fmt.Printf("%d later flight(s) of airplane %s held up:\n", len(impact.Delayed)-1, s.AirplaneCode)
+		tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "SCHEDULE\tROUTE\tPLANNED\tESTIMATED\tDELAY")
+		for _, f := range impact.Delayed[1:] {
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%dm\n", f.ID, f.RouteCode, formatLocalTime(f.LocalDeparture()), formatLocalTime(f.LocalEstimatedDeparture()), f.DelayMinutes)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(impact.Broken) > 0 {
+		fmt.Printf("%d connection(s) below the minimum connection time:\n", len(impact.Broken))
+		tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "PASSENGER\tINBOUND\tONWARD\tAIRPORT\tLAYOVER\tMINIMUM")
+		for _, c := range impact.Broken {
+			_, _ = fmt.Fprintf(tw, "%s\t%s (%d)\t%s (%d)\t%s\t%dm\t%dm\n", c.Inbound.PassengerName, c.Inbound.Reference, c.Arriving.ID, c.Onward.Reference, c.Departing.ID, c.AirportCode, int(c.Layover/time.Minute), int(c.Minimum/time.Minute))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("queued %d notification(s)\n", len(impact.Notifications))
+	return nil
+}
+
+// formatLocalTime renders an airport-local time with its zone abbreviation, or "-" when unknown.
+func formatLocalTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04 MST")
+}
+
+// formatDuration renders durations as hours and minutes (e.g. "2h05m"), or "-" when unknown.
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -2,9 +2,13 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
@@ -14,6 +18,14 @@ import (
 type fakeScheduleRepoCLI struct {
 	nextID int64
 	items  map[int64]domain.FlightSchedule
+	// booked and seats let Update refuse airplanes too small for a flight.
+	booked map[int64]int
+	seats  map[string]int
+	moves  []domain.SeatMove
+	// notices collects the notifications queued by delays.
+	notices []domain.Notification
+	// board holds the flights ListByAirport picks from.
+	board []domain.BoardFlight
 }
 
 func (f *fakeScheduleRepoCLI) GetByID(ctx context.Context, id int64) (*domain.FlightSchedule, error) {
@@ -47,15 +59,87 @@ func (f *fakeScheduleRepoCLI) List(ctx context.Context, routeCode string, limit,
 	return out, nil
 }
 
-func (f *fakeScheduleRepoCLI) Delete(ctx context.Context, id int64) error {
+func (f *fakeScheduleRepoCLI) ListByAirplane(ctx context.Context, airplaneCode string, limit, offset int) ([]domain.FlightSchedule, error) {
+	var out []domain.FlightSchedule
+	for _, item := range f.items {
+		if item.AirplaneCode == airplaneCode {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeScheduleRepoCLI) ListByAirport(ctx context.Context, airportCode string, arrivals bool, from, to time.Time, limit, offset int) ([]domain.BoardFlight, error) {
+	var out []domain.BoardFlight
+	for _, b := range f.board {
+		code, at := b.OriginCode, b.Schedule.DepartureAt
+		if arrivals {
+			code, at = b.DestinationCode, b.Schedule.ArrivalAt
+		}
+		if code == airportCode && !at.Before(from) && at.Before(to) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeScheduleRepoCLI) Update(ctx context.Context, s *domain.FlightSchedule) ([]domain.SeatMove, error) {
+	if _, ok := f.items[s.ID]; !ok {
+		return nil, domain.ErrScheduleNotFound
+	}
+	if capacity, ok := f.seats[s.AirplaneCode]; ok && f.booked[s.ID] > capacity {
+		return nil, &domain.CapacityError{ScheduleID: s.ID, Capacity: capacity, Booked: f.booked[s.ID]}
+	}
+	f.items[s.ID] = *s
+	return f.moves, nil
+}
+
+func (f *fakeScheduleRepoCLI) Cancel(ctx context.Context, id int64, reason string, at time.Time) (int, error) {
+	s, ok := f.items[id]
+	if !ok {
+		return 0, domain.ErrScheduleNotFound
+	}
+	if s.Cancelled() {
+		return 0, domain.ErrScheduleCancelled
+	}
+	s.Status = domain.ScheduleStatusCancelled
+	s.CancelReason = reason
+	f.items[id] = s
+	return 0, nil
+}
+
+func (f *fakeScheduleRepoCLI) Delay(ctx context.Context, delays []domain.ScheduleDelay, notices []domain.Notification) error {
+	for _, d := range delays {
+		s, ok := f.items[d.ScheduleID]
+		if !ok {
+			return domain.ErrScheduleNotFound
+		}
+		s.DelayMinutes, s.DelayReason = d.Minutes, d.Reason
+		f.items[d.ScheduleID] = s
+	}
+	f.notices = append(f.notices, notices...)
+	return nil
+}
+
+func (f *fakeScheduleRepoCLI) SetGates(ctx context.Context, id int64, departureGate, arrivalGate string) error {
+	s, ok := f.items[id]
+	if !ok {
+		return domain.ErrScheduleNotFound
+	}
+	s.DepartureGate, s.ArrivalGate = departureGate, arrivalGate
+	f.items[id] = s
+	return nil
+}
+
+func (f *fakeScheduleRepoCLI) Delete(ctx context.Context, id int64, cascade *domain.Dependents) (domain.Dependents, error) {
 	if f.items == nil {
 		f.items = make(map[int64]domain.FlightSchedule)
 	}
 	if _, ok := f.items[id]; !ok {
-		return domain.ErrScheduleNotFound
+		return domain.Dependents{}, domain.ErrScheduleNotFound
 	}
 	delete(f.items, id)
-	return nil
+	return domain.Dependents{}, nil
 }
 
 type fakeRouteRepoCLIForSchedule struct{ existing map[string]bool }
@@ -63,42 +147,110 @@ type fakeRouteRepoCLIForSchedule struct{ existing map[string]bool }
 func (f *fakeRouteRepoCLIForSchedule) Create(ctx context.Context, r *domain.Route) error { return nil }
 func (f *fakeRouteRepoCLIForSchedule) GetByCode(ctx context.Context, code string) (*domain.Route, error) {
 	if f.existing != nil && f.existing[code] {
-		return &domain.Route{Code: code}, nil
+		return &domain.Route{Code: code, OriginCode: "CGK", DestinationCode: "DPS"}, nil
 	}
 	return nil, domain.ErrRouteNotFound
 }
-func (f *fakeRouteRepoCLIForSchedule) List(ctx context.Context, limit, offset int) ([]domain.Route, error) {
+func (f *fakeRouteRepoCLIForSchedule) SetArchived(ctx context.Context, code string, archived bool) error {
+	return nil
+}
+
+func (f *fakeRouteRepoCLIForSchedule) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Route, error) {
+	var out []domain.Route
+	for code := range f.existing {
+		out = append(out, domain.Route{Code: code, OriginCode: "CGK", DestinationCode: "DPS"})
+	}
+	return out, nil
+}
+func (f *fakeRouteRepoCLIForSchedule) SetBlockTime(ctx context.Context, code string, minutes int) error {
+	return nil
+}
+func (f *fakeRouteRepoCLIForSchedule) Update(ctx context.Context, r *domain.Route, from time.Time, force bool) ([]domain.BookedFlight, error) {
 	return nil, nil
 }
-func (f *fakeRouteRepoCLIForSchedule) Delete(ctx context.Context, code string) error { return nil }
+func (f *fakeRouteRepoCLIForSchedule) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	return domain.Dependents{}, nil
+}
 
-type fakeAirplaneRepoCLIForSchedule struct{ existing map[string]bool }
+type fakeAirplaneRepoCLIForSchedule struct {
+	existing map[string]bool
+	types    map[string]string // airplane code to the IATA code of its type
+}
 
 func (f *fakeAirplaneRepoCLIForSchedule) Create(ctx context.Context, a *domain.Airplane) error {
 	return nil
 }
 func (f *fakeAirplaneRepoCLIForSchedule) GetByCode(ctx context.Context, code string) (*domain.Airplane, error) {
 	if f.existing != nil && f.existing[code] {
-		return &domain.Airplane{Code: code}, nil
+		return &domain.Airplane{Code: code, TypeIATACode: f.types[code]}, nil
 	}
 	return nil, domain.ErrAirplaneNotFound
 }
-func (f *fakeAirplaneRepoCLIForSchedule) List(ctx context.Context, limit, offset int) ([]domain.Airplane, error) {
-	return nil, nil
+func (f *fakeAirplaneRepoCLIForSchedule) SetArchived(ctx context.Context, code string, archived bool) error {
+	return nil
 }
-func (f *fakeAirplaneRepoCLIForSchedule) UpdateSeats(ctx context.Context, code string, seats int) error {
+
+func (f *fakeAirplaneRepoCLIForSchedule) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airplane, error) {
+	if offset > 0 {
+		return nil, nil
+	}
+	var out []domain.Airplane
+	for code := range f.existing {
+		out = append(out, domain.Airplane{Code: code, TypeIATACode: f.types[code]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out, nil
+}
+func (f *fakeAirplaneRepoCLIForSchedule) UpdateSeats(ctx context.Context, code string, seats int, from time.Time, force bool) (*domain.CapacityChange, error) {
+	return &domain.CapacityChange{}, nil
+}
+func (f *fakeAirplaneRepoCLIForSchedule) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	return domain.Dependents{}, nil
+}
+
+type fakeMaintenanceRepoCLI struct {
+	windows   []domain.MaintenanceWindow
+	conflicts []domain.MaintenanceConflict
+}
+
+func (f *fakeMaintenanceRepoCLI) Create(ctx context.Context, w *domain.MaintenanceWindow) error {
+	w.ID = int64(len(f.windows) + 1)
+	f.windows = append(f.windows, *w)
 	return nil
 }
-func (f *fakeAirplaneRepoCLIForSchedule) Delete(ctx context.Context, code string) error { return nil }
+func (f *fakeMaintenanceRepoCLI) ListByAirplane(ctx context.Context, airplaneCode string, from time.Time) ([]domain.MaintenanceWindow, error) {
+	var out []domain.MaintenanceWindow
+	for _, w := range f.windows {
+		if w.AirplaneCode == airplaneCode && w.EndsAt.After(from) {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+func (f *fakeMaintenanceRepoCLI) Overlapping(ctx context.Context, airplaneCode string, from, to time.Time) ([]domain.MaintenanceWindow, error) {
+	var out []domain.MaintenanceWindow
+	for _, w := range f.windows {
+		if w.AirplaneCode == airplaneCode && w.StartsAt.Before(to) && w.EndsAt.After(from) {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+func (f *fakeMaintenanceRepoCLI) BookedFlights(ctx context.Context, w domain.MaintenanceWindow) ([]domain.MaintenanceConflict, error) {
+	return f.conflicts, nil
+}
 
 func TestScheduleCLI_Flow(t *testing.T) {
-	oldDB, oldRepo, oldRouteRepo, oldPlaneRepo := newScheduleDB, newScheduleRepo, newScheduleRouteRepo, newScheduleAirplaneRepo
+	oldDB, oldRepo, oldRouteRepo, oldPlaneRepo, oldAirportRepo, oldMaintenanceRepo := newScheduleDB, newScheduleRepo, newScheduleRouteRepo, newScheduleAirplaneRepo, newScheduleAirportRepo, newScheduleMaintenanceRepo
 	t.Cleanup(func() {
 		newScheduleDB = oldDB
 		newScheduleRepo = oldRepo
 		newScheduleRouteRepo = oldRouteRepo
 		newScheduleAirplaneRepo = oldPlaneRepo
+		newScheduleAirportRepo = oldAirportRepo
+		newScheduleMaintenanceRepo = oldMaintenanceRepo
 	})
+	newScheduleMaintenanceRepo = func(*sqlx.DB) domain.MaintenanceRepository { return &fakeMaintenanceRepoCLI{} }
 	newScheduleDB = func(string) (*sqlx.DB, error) {
 		db, _, err := sqlmock.New()
 		if err != nil {
@@ -112,6 +264,9 @@ func TestScheduleCLI_Flow(t *testing.T) {
 	newScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
 	newScheduleRouteRepo = func(*sqlx.DB) domain.RouteRepository { return routes }
 	newScheduleAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository { return planes }
+	newScheduleAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true}}
+	}
 
 	t.Setenv("FLIGHT_DB_HOST", "localhost")
 
@@ -120,9 +275,27 @@ func TestScheduleCLI_Flow(t *testing.T) {
 		t.Fatalf("create: %v", err)
 	}
 
-	os.Args = []string{"flight-booking", "schedule", "list", "--route", "RT1"}
+	os.Args = []string{"flight-booking", "schedule", "create", "--route", "RT1", "--airplane", "A320", "--date", "2025-01-03", "--time", "08:30", "--arrival", "10:15"}
 	if err := Execute(); err != nil {
-		t.Fatalf("list: %v", err)
+		t.Fatalf("create with times: %v", err)
+	}
+	if got := schedules.items[2].Duration(); got != 105*time.Minute {
+		t.Fatalf("unexpected scheduled duration %s", got)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "create", "--route", "RT1", "--airplane", "A320", "--date", "2025-01-04", "--time", "8.30"}
+	if err := Execute(); err != domain.ErrInvalidScheduleTime {
+		t.Fatalf("want invalid time, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "list", "--route", "RT1"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("list: %v", err)
+		}
+	})
+	if !strings.Contains(out, "2025-01-03 08:30 UTC") || !strings.Contains(out, "2025-01-03 10:15 UTC") {
+		t.Fatalf("list should show local departure and arrival, got %q", out)
 	}
 
 	os.Args = []string{"flight-booking", "schedule", "delete", "1"}
@@ -148,12 +321,13 @@ func TestScheduleCLI_DeleteInvalidID(t *testing.T) {
 }
 
 func TestScheduleCLI_DeleteNotFound(t *testing.T) {
-	oldDB, oldRepo, oldRouteRepo, oldPlaneRepo := newScheduleDB, newScheduleRepo, newScheduleRouteRepo, newScheduleAirplaneRepo
+	oldDB, oldRepo, oldRouteRepo, oldPlaneRepo, oldAirportRepo := newScheduleDB, newScheduleRepo, newScheduleRouteRepo, newScheduleAirplaneRepo, newScheduleAirportRepo
 	t.Cleanup(func() {
 		newScheduleDB = oldDB
 		newScheduleRepo = oldRepo
 		newScheduleRouteRepo = oldRouteRepo
 		newScheduleAirplaneRepo = oldPlaneRepo
+		newScheduleAirportRepo = oldAirportRepo
 	})
 	newScheduleDB = func(string) (*sqlx.DB, error) {
 		db, _, _ := sqlmock.New()
@@ -165,6 +339,9 @@ func TestScheduleCLI_DeleteNotFound(t *testing.T) {
 	newScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
 	newScheduleRouteRepo = func(*sqlx.DB) domain.RouteRepository { return routes }
 	newScheduleAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository { return planes }
+	newScheduleAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true}}
+	}
 
 	t.Setenv("FLIGHT_DB_HOST", "localhost")
 	os.Args = []string{"flight-booking", "schedule", "delete", "1"}
@@ -172,3 +349,473 @@ func TestScheduleCLI_DeleteNotFound(t *testing.T) {
 		t.Fatalf("expected delete error")
 	}
 }
+
+type fakeInventoryRepoCLI struct {
+	drifts   []domain.InventoryDrift
+	repaired bool
+}
+
+func (f *fakeInventoryRepoCLI) Get(ctx context.Context, scheduleID int64) (*domain.SeatInventory, error) {
+	if scheduleID != 1 {
+		return nil, domain.ErrInventoryNotFound
+	}
+	return &domain.SeatInventory{ScheduleID: 1, Capacity: 180, Sold: 10, Held: 2}, nil
+}
+
+func (f *fakeInventoryRepoCLI) Reconcile(ctx context.Context, repair bool) ([]domain.InventoryDrift, error) {
+	f.repaired = repair
+	return f.drifts, nil
+}
+
+func TestScheduleCLI_Inventory(t *testing.T) {
+	oldDB, oldInventory := newScheduleDB, newInventoryRepo
+	t.Cleanup(func() {
+		newScheduleDB = oldDB
+		newInventoryRepo = oldInventory
+	})
+	newScheduleDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	repo := &fakeInventoryRepoCLI{drifts: []domain.InventoryDrift{
+		{Recorded: domain.SeatInventory{ScheduleID: 1, Capacity: 180, Sold: 9}, Expected: domain.SeatInventory{ScheduleID: 1, Capacity: 180, Sold: 10}},
+		{Expected: domain.SeatInventory{ScheduleID: 2, Capacity: 150}, Missing: true},
+	}}
+	newInventoryRepo = func(*sqlx.DB) domain.SeatInventoryRepository { return repo }
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "schedule", "inventory", "1"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("inventory: %v", err)
+		}
+	})
+	if !strings.Contains(out, "available: 168") {
+		t.Fatalf("unexpected inventory output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "reconcile-inventory"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+	})
+	if !strings.Contains(out, "2 schedule(s) drifted") || repo.repaired {
+		t.Fatalf("expected a dry-run report, got %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "reconcile-inventory", "--repair"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("repair: %v", err)
+		}
+	})
+	if !strings.Contains(out, "repaired 2 schedule(s)") || !repo.repaired {
+		t.Fatalf("expected a repair, got %q", out)
+	}
+}
+
+func TestScheduleCLI_SwapAircraft(t *testing.T) {
+	oldDB, oldRepo, oldRouteRepo, oldPlaneRepo, oldAirportRepo, oldBookingRepo, oldMaintenanceRepo := newScheduleDB, newScheduleRepo, newScheduleRouteRepo, newScheduleAirplaneRepo, newScheduleAirportRepo, newScheduleBookingRepo, newScheduleMaintenanceRepo
+	t.Cleanup(func() {
+		newScheduleDB = oldDB
+		newScheduleRepo = oldRepo
+		newScheduleRouteRepo = oldRouteRepo
+		newScheduleAirplaneRepo = oldPlaneRepo
+		newScheduleAirportRepo = oldAirportRepo
+		newScheduleBookingRepo = oldBookingRepo
+		newScheduleMaintenanceRepo = oldMaintenanceRepo
+	})
+	newScheduleDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	schedules := &fakeScheduleRepoCLI{
+		items:  map[int64]domain.FlightSchedule{1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: time.Date(2025, 1, 2, 1, 0, 0, 0, time.UTC)}},
+		booked: map[int64]int{1: 2},
+		seats:  map[string]int{"ATR": 1, "E190": 100},
+		moves:  []domain.SeatMove{{BookingID: 1, Reference: "K7QX2M", From: 150, To: 1}},
+	}
+	bookings := newFakeBookingRepoCLI()
+	bookings.items["K7QX2M"] = domain.Booking{ID: 1, Reference: "K7QX2M", ScheduleID: 1, PassengerName: "Alice", SeatNumber: 1, Status: domain.BookingStatusConfirmed}
+	bookings.items["P4RT8N"] = domain.Booking{ID: 2, Reference: "P4RT8N", ScheduleID: 1, PassengerName: "Bob", SeatNumber: 2, Status: domain.BookingStatusConfirmed}
+	newScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
+	newScheduleRouteRepo = func(*sqlx.DB) domain.RouteRepository {
+		return &fakeRouteRepoCLIForSchedule{existing: map[string]bool{"RT1": true}}
+	}
+	newScheduleAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository {
+		return &fakeAirplaneRepoCLIForSchedule{existing: map[string]bool{"A320": true, "ATR": true, "B737": true, "E190": true}}
+	}
+	newScheduleAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true}}
+	}
+	newScheduleBookingRepo = func(*sqlx.DB) domain.BookingRepository { return bookings }
+	maintenance := &fakeMaintenanceRepoCLI{windows: []domain.MaintenanceWindow{{ID: 1, AirplaneCode: "B737", StartsAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EndsAt: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Reason: "C-check"}}}
+	newScheduleMaintenanceRepo = func(*sqlx.DB) domain.MaintenanceRepository { return maintenance }
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "schedule", "swap-aircraft", "1", "--airplane", "B737"}
+	if err := Execute(); !errors.Is(err, domain.ErrAirplaneInMaintenance) || !strings.Contains(err.Error(), "C-check") {
+		t.Fatalf("want maintenance refusal, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "swap-aircraft", "1", "--airplane", "ATR"}
+	var err error
+	out := captureOutput(func() { err = Execute() })
+	if !errors.Is(err, domain.ErrCapacityBelowBookings) {
+		t.Fatalf("want capacity refusal, got %v", err)
+	}
+	if !strings.Contains(out, "1 booking(s) need another flight") || !strings.Contains(out, "P4RT8N") || strings.Contains(out, "K7QX2M") {
+		t.Fatalf("expected the newer booking offered reaccommodation, got %q", out)
+	}
+	if schedules.items[1].AirplaneCode != "A320" {
+		t.Fatalf("a refused swap must keep the airplane")
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "swap-aircraft", "1", "--airplane", "E190"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("swap: %v", err)
+		}
+	})
+	if !strings.Contains(out, "schedule 1: swapped A320 -> E190, reseated 1 booking(s)") || !strings.Contains(out, "K7QX2M") {
+		t.Fatalf("unexpected swap output %q", out)
+	}
+	if schedules.items[1].AirplaneCode != "E190" {
+		t.Fatalf("expected the schedule updated in place, got %+v", schedules.items[1])
+	}
+}
+
+func TestScheduleCLI_Rotation(t *testing.T) {
+	oldDB, oldRepo, oldRouteRepo, oldPlaneRepo, oldAirportRepo, oldMaintenanceRepo := newScheduleDB, newScheduleRepo, newScheduleRouteRepo, newScheduleAirplaneRepo, newScheduleAirportRepo, newScheduleMaintenanceRepo
+	t.Cleanup(func() {
+		newScheduleDB = oldDB
+		newScheduleRepo = oldRepo
+		newScheduleRouteRepo = oldRouteRepo
+		newScheduleAirplaneRepo = oldPlaneRepo
+		newScheduleAirportRepo = oldAirportRepo
+		newScheduleMaintenanceRepo = oldMaintenanceRepo
+	})
+	newScheduleMaintenanceRepo = func(*sqlx.DB) domain.MaintenanceRepository { return &fakeMaintenanceRepoCLI{} }
+	newScheduleDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	schedules := &fakeScheduleRepoCLI{}
+	newScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
+	// Every route of this fake flies CGK to DPS, so no two flights can chain.
+	newScheduleRouteRepo = func(*sqlx.DB) domain.RouteRepository {
+		return &fakeRouteRepoCLIForSchedule{existing: map[string]bool{"RT1": true}}
+	}
+	newScheduleAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository {
+		return &fakeAirplaneRepoCLIForSchedule{existing: map[string]bool{"A320": true}}
+	}
+	newScheduleAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true}}
+	}
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "schedule", "create", "--route", "RT1", "--airplane", "A320", "--date", "2025-01-02", "--time", "08:00", "--arrival", "10:00"}
+	if err := Execute(); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	os.Args = []string{"flight-booking", "schedule", "create", "--route", "RT1", "--airplane", "A320", "--date", "2025-01-02", "--time", "12:00", "--strict-rotation"}
+	if err := Execute(); !errors.Is(err, domain.ErrRotationConflict) || !strings.Contains(err.Error(), "departs CGK but schedule 1 lands at DPS") {
+		t.Fatalf("want strict rotation refusal, got %v", err)
+	}
+	os.Args = []string{"flight-booking", "schedule", "create", "--route", "RT1", "--airplane", "A320", "--date", "2025-01-02", "--time", "12:00"}
+	if err := Execute(); err != nil {
+		t.Fatalf("lenient create: %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "validate-rotation", "--airplane", "A320"}
+	var err error
+	out := captureOutput(func() { err = Execute() })
+	if err == nil || err.Error() != "1 rotation issue(s)" {
+		t.Fatalf("want one rotation issue, got %v", err)
+	}
+	if !strings.Contains(out, "airplane A320: 2 flight(s), minimum turnaround 0h30m") || !strings.Contains(out, "positioning") {
+		t.Fatalf("unexpected rotation report %q", out)
+	}
+}
+
+func TestScheduleCLI_CancelAndDisruptions(t *testing.T) {
+	oldDB, oldRepo, oldRouteRepo, oldPlaneRepo, oldAirportRepo, oldBookingRepo, oldMaintenanceRepo := newScheduleDB, newScheduleRepo, newScheduleRouteRepo, newScheduleAirplaneRepo, newScheduleAirportRepo, newScheduleBookingRepo, newScheduleMaintenanceRepo
+	t.Cleanup(func() {
+		newScheduleDB = oldDB
+		newScheduleRepo = oldRepo
+		newScheduleRouteRepo = oldRouteRepo
+		newScheduleAirplaneRepo = oldPlaneRepo
+		newScheduleAirportRepo = oldAirportRepo
+		newScheduleBookingRepo = oldBookingRepo
+		newScheduleMaintenanceRepo = oldMaintenanceRepo
+	})
+	newScheduleDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	schedules := &fakeScheduleRepoCLI{items: map[int64]domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: time.Date(2025, 1, 2, 1, 0, 0, 0, time.UTC), Status: domain.ScheduleStatusScheduled},
+	}}
+	bookings := newFakeBookingRepoCLI()
+	bookings.items["K7QX2M"] = domain.Booking{ID: 1, Reference: "K7QX2M", ScheduleID: 1, PassengerName: "Alice", SeatNumber: 1, Status: domain.BookingStatusConfirmed}
+	newScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
+	newScheduleRouteRepo = func(*sqlx.DB) domain.RouteRepository {
+		return &fakeRouteRepoCLIForSchedule{existing: map[string]bool{"RT1": true}}
+	}
+	newScheduleAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository {
+		return &fakeAirplaneRepoCLIForSchedule{existing: map[string]bool{"A320": true}}
+	}
+	newScheduleAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true}}
+	}
+	newScheduleBookingRepo = func(*sqlx.DB) domain.BookingRepository { return bookings }
+	newScheduleMaintenanceRepo = func(*sqlx.DB) domain.MaintenanceRepository { return &fakeMaintenanceRepoCLI{} }
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "schedule", "cancel", "1"}
+	if err := Execute(); err == nil || !strings.Contains(err.Error(), "reason") {
+		t.Fatalf("want missing reason error, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "cancel", "1", "--reason", "volcanic ash"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("cancel: %v", err)
+		}
+	})
+	if !strings.Contains(out, "cancelled schedule 1 (RT1 2025-01-02): volcanic ash") || !strings.Contains(out, "1 booking(s) need another flight") || !strings.Contains(out, "K7QX2M") {
+		t.Fatalf("unexpected cancel output %q", out)
+	}
+	if !schedules.items[1].Cancelled() {
+		t.Fatalf("expected the schedule kept and cancelled, got %+v", schedules.items[1])
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "cancel", "1", "--reason", "again"}
+	if err := Execute(); !errors.Is(err, domain.ErrScheduleCancelled) {
+		t.Fatalf("want already cancelled, got %v", err)
+	}
+
+	bookings.affected = map[string]bool{"K7QX2M": true}
+	os.Args = []string{"flight-booking", "schedule", "disruptions"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("disruptions: %v", err)
+		}
+	})
+	if !strings.Contains(out, "schedule 1 cancelled: volcanic ash") || !strings.Contains(out, "K7QX2M") {
+		t.Fatalf("unexpected disruptions output %q", out)
+	}
+
+	// Refunding the booking clears the list.
+	b := bookings.items["K7QX2M"]
+	b.Status = domain.BookingStatusCancelled
+	bookings.items["K7QX2M"] = b
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("disruptions: %v", err)
+		}
+	})
+	if !strings.Contains(out, "no disrupted bookings") {
+		t.Fatalf("unexpected disruptions output %q", out)
+	}
+}
+
+func TestScheduleCLI_Delay(t *testing.T) {
+	oldDB, oldRepo, oldRouteRepo, oldPlaneRepo, oldAirportRepo, oldBookingRepo, oldMaintenanceRepo, oldTripRepo := newScheduleDB, newScheduleRepo, newScheduleRouteRepo, newScheduleAirplaneRepo, newScheduleAirportRepo, newScheduleBookingRepo, newScheduleMaintenanceRepo, newScheduleTripRepo
+	t.Cleanup(func() {
+		newScheduleDB = oldDB
+		newScheduleRepo = oldRepo
+		newScheduleRouteRepo = oldRouteRepo
+		newScheduleAirplaneRepo = oldPlaneRepo
+		newScheduleAirportRepo = oldAirportRepo
+		newScheduleBookingRepo = oldBookingRepo
+		newScheduleMaintenanceRepo = oldMaintenanceRepo
+		newScheduleTripRepo = oldTripRepo
+	})
+	newScheduleDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	schedules := &fakeScheduleRepoCLI{items: map[int64]domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: day.Add(8 * time.Hour), ArrivalAt: day.Add(10 * time.Hour)},
+		2: {ID: 2, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: day.Add(10*time.Hour + 45*time.Minute), ArrivalAt: day.Add(12*time.Hour + 45*time.Minute)},
+		5: {ID: 5, RouteCode: "RT1", AirplaneCode: "B737", DepartureDate: "2025-01-02", DepartureAt: day.Add(11 * time.Hour), ArrivalAt: day.Add(13 * time.Hour)},
+	}}
+	bookings := newFakeBookingRepoCLI()
+	bookings.items["K7QX2M"] = domain.Booking{ID: 1, Reference: "K7QX2M", ScheduleID: 1, PassengerName: "Alice", SeatNumber: 1, Status: domain.BookingStatusConfirmed, TripID: 3}
+	trips := &fakeTripRepoCLI{connections: map[int64][]domain.Connection{1: {{
+		Inbound:     bookings.items["K7QX2M"],
+		Onward:      domain.Booking{ID: 2, Reference: "P4RT8N", ScheduleID: 5, PassengerName: "Alice", TripID: 3},
+		AirportCode: "DPS",
+	}}}}
+	newScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
+	newScheduleRouteRepo = func(*sqlx.DB) domain.RouteRepository {
+		return &fakeRouteRepoCLIForSchedule{existing: map[string]bool{"RT1": true}}
+	}
+	newScheduleAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository {
+		return &fakeAirplaneRepoCLIForSchedule{existing: map[string]bool{"A320": true, "B737": true}}
+	}
+	newScheduleAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true}}
+	}
+	newScheduleBookingRepo = func(*sqlx.DB) domain.BookingRepository { return bookings }
+	newScheduleMaintenanceRepo = func(*sqlx.DB) domain.MaintenanceRepository { return &fakeMaintenanceRepoCLI{} }
+	newScheduleTripRepo = func(*sqlx.DB) domain.TripRepository { return trips }
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "schedule", "delay", "1", "--minutes", "60"}
+	if err := Execute(); !errors.Is(err, domain.ErrInvalidDelay) {
+		t.Fatalf("want a reason required, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "delay", "1", "--minutes", "60", "--reason", "late crew"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("delay: %v", err)
+		}
+	})
+	for _, want := range []string{"schedule 1 (RT1 2025-01-02) delayed 60m: late crew", "1 later flight(s) of airplane A320 held up", "1 connection(s) below the minimum connection time", "K7QX2M (1)", "P4RT8N (5)", "queued 2 notification(s)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in %q", want, out)
+		}
+	}
+	if schedules.items[2].DelayMinutes != 45 || len(schedules.notices) != 2 {
+		t.Fatalf("expected the knock-on delay stored and notices queued, got %+v %+v", schedules.items[2], schedules.notices)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "delay", "1", "--minutes", "0"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("delay: %v", err)
+		}
+	})
+	if !strings.Contains(out, "schedule 1 (RT1 2025-01-02) is back on time") {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
+
+func TestScheduleCLI_Gate(t *testing.T) {
+	oldDB, oldRepo := newScheduleDB, newScheduleRepo
+	t.Cleanup(func() {
+		newScheduleDB = oldDB
+		newScheduleRepo = oldRepo
+	})
+	newScheduleDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	schedules := &fakeScheduleRepoCLI{items: map[int64]domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", ArrivalGate: "B2"},
+	}}
+	newScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "schedule", "gate", "1"}
+	if err := Execute(); err == nil || !strings.Contains(err.Error(), "--departure") {
+		t.Fatalf("want a gate required, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "gate", "1", "--departure", "a12"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("gate: %v", err)
+		}
+	})
+	if !strings.Contains(out, "schedule 1 (RT1 2025-01-02) departure gate A12, arrival gate B2") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "gate", "1", "--arrival", ""}
+	_ = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("gate: %v", err)
+		}
+	})
+	if s := schedules.items[1]; s.DepartureGate != "A12" || s.ArrivalGate != "" {
+		t.Fatalf("gates not stored: %+v", s)
+	}
+}
+
+func TestAirportCLI_Board(t *testing.T) {
+	oldDB, oldRepo, oldAirportRepo := newScheduleDB, newScheduleRepo, newScheduleAirportRepo
+	t.Cleanup(func() {
+		newScheduleDB = oldDB
+		newScheduleRepo = oldRepo
+		newScheduleAirportRepo = oldAirportRepo
+	})
+	newScheduleDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	schedules := &fakeScheduleRepoCLI{board: []domain.BoardFlight{
+		{Schedule: domain.FlightSchedule{ID: 1, RouteCode: "CGK-DPS", AirplaneCode: "A320", DepartureAt: day.Add(8 * time.Hour), ArrivalAt: day.Add(10 * time.Hour), DepartureGate: "A12"}, OriginCode: "CGK", DestinationCode: "DPS", Capacity: 180, Booked: 90},
+		{Schedule: domain.FlightSchedule{ID: 2, RouteCode: "CGK-DPS", AirplaneCode: "B737", DepartureAt: day.Add(23 * time.Hour), ArrivalAt: day.Add(25 * time.Hour), DelayMinutes: 90}, OriginCode: "CGK", DestinationCode: "DPS", Capacity: 100, Booked: 25},
+		{Schedule: domain.FlightSchedule{ID: 3, RouteCode: "DPS-CGK", AirplaneCode: "A320", DepartureAt: day.Add(12 * time.Hour), ArrivalAt: day.Add(14 * time.Hour), Status: domain.ScheduleStatusCancelled}, OriginCode: "DPS", DestinationCode: "CGK", Capacity: 180},
+	}}
+	newScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return schedules }
+	newScheduleAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true}}
+	}
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "airport", "board", "CGK", "--date", "2025-01-02"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("board: %v", err)
+		}
+	})
+	for _, want := range []string{"CGK departures on 2025-01-02 (UTC)", "DPS", "A12", "ON TIME", "50%", "DELAYED", "90m", "00:30 +1d", "25%"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in %q", want, out)
+		}
+	}
+	if strings.Contains(out, "DPS-CGK") {
+		t.Fatalf("arrival listed on the departure board: %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "airport", "board", "CGK", "--date", "2025-01-02", "--arrivals"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("board: %v", err)
+		}
+	})
+	for _, want := range []string{"CGK arrivals on 2025-01-02 (UTC)", "FROM", "DPS-CGK", "CANCELLED", "0%"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in %q", want, out)
+		}
+	}
+
+	os.Args = []string{"flight-booking", "airport", "board", "DPS", "--date", "2025-01-03", "--arrivals"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("board: %v", err)
+		}
+	})
+	if !strings.Contains(out, "CGK-DPS") || !strings.Contains(out, "01:00") {
+		t.Fatalf("unexpected output %q", out)
+	}
+}
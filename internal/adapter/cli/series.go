@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	sqlxrepo "github.com/ambiyansyah-risyal/flight-booking/internal/adapter/repository/sqlx"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/config"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
+)
+
+var newSeriesRepo = func(db *sqlx.DB) domain.ScheduleSeriesRepository { return sqlxrepo.NewScheduleSeriesRepository(db) }
+
+func withSeriesUsecase(run func(*usecase.SeriesUsecase) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := newScheduleDB(cfg.Database.DSN())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return run(usecase.NewSeriesUsecase(newSeriesRepo(db), newScheduleRouteRepo(db), newScheduleAirplaneRepo(db), newScheduleAirportRepo(db)).
+		WithMaintenance(newScheduleMaintenanceRepo(db)).
+		WithRotation(newScheduleRepo(db), cfg.Rotation.MinTurnaround, cfg.Rotation.Strict))
+}
+
+func newScheduleCreateSeriesCmd() *cobra.Command {
+	var routeCode, airplaneCode, from, to, days, departureTime, arrivalTime string
+	cmd := &cobra.Command{
+		Use:   "create-series",
+		Short: "Schedule a flight on selected weekdays over a date range",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSeriesUsecase(func(uc *usecase.SeriesUsecase) error {
+				res, err := uc.Create(context.Background(), routeCode, airplaneCode, from, to, days, departureTime, arrivalTime)
+				if err != nil {
+					return err
+				}
+				printSeriesResult(res)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&routeCode, "route", "", "route code")
+	cmd.Flags().StringVar(&airplaneCode, "airplane", "", "airplane code")
+	cmd.Flags().StringVar(&from, "from", "", "first departure date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&to, "to", "", "last departure date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&days, "days", "daily", "days of week to fly, e.g. Mon,Wed,Fri")
+	cmd.Flags().StringVar(&departureTime, "time", "", "local departure time at the origin (HH:MM)")
+	cmd.Flags().StringVar(&arrivalTime, "arrival", "", "local arrival time at the destination (HH:MM)")
+	_ = cmd.MarkFlagRequired("route")
+	_ = cmd.MarkFlagRequired("airplane")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	_ = cmd.MarkFlagRequired("time")
+	return cmd
+}
+
+func newScheduleSeriesCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "series", Short: "Manage recurring schedule series"}
+	cmd.AddCommand(newSeriesListCmd())
+	cmd.AddCommand(newSeriesShowCmd())
+	cmd.AddCommand(newSeriesExtendCmd())
+	cmd.AddCommand(newSeriesAmendCmd())
+	cmd.AddCommand(newSeriesCancelCmd())
+	return cmd
+}
+
+func newSeriesListCmd() *cobra.Command {
+	var limit, offset int
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List schedule series",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withSeriesUsecase(func(uc *usecase.SeriesUsecase) error {
+				items, err := uc.List(context.Background(), limit, offset)
+				if err != nil {
+					return err
+				}
+				tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+				_, _ = fmt.Fprintln(tw, "ID\tROUTE\tAIRPLANE\tFROM\tTO\tDAYS\tDEPARTS\tARRIVES\tSTATUS")
+				for _, s := range items {
+					_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.RouteCode, s.AirplaneCode, s.StartDate, s.EndDate, s.Days, s.DepartureTime, dashIfEmpty(s.ArrivalTime), seriesStatus(s))
+				}
+				return tw.Flush()
+			})
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 50, "max items to list")
+	cmd.Flags().IntVar(&offset, "offset", 0, "items to skip")
+	return cmd
+}
+
+func newSeriesShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show a series and the flights it scheduled",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			return withSeriesUsecase(func(uc *usecase.SeriesUsecase) error {
+				s, flights, err := uc.Get(context.Background(), id)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("series %d: %s with %s %s..%s %s departing %s arriving %s (%s)\n", s.ID, s.RouteCode, s.AirplaneCode, s.StartDate, s.EndDate, s.Days, s.DepartureTime, dashIfEmpty(s.ArrivalTime), seriesStatus(*s))
+				return writeSeriesFlights(flights)
+			})
+		},
+	}
+}
+
+func newSeriesExtendCmd() *cobra.Command {
+	var to string
+	cmd := &cobra.Command{
+		Use:   "extend <id>",
+		Short: "Move the end of a series to a later date and schedule the new flights",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			return withSeriesUsecase(func(uc *usecase.SeriesUsecase) error {
+				res, err := uc.Extend(context.Background(), id, to)
+				if err != nil {
+					return err
+				}
+				printSeriesResult(res)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&to, "to", "", "new last departure date (YYYY-MM-DD)")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func newSeriesAmendCmd() *cobra.Command {
+	var departureTime, arrivalTime string
+	cmd := &cobra.Command{
+		Use:   "amend <id>",
+		Short: "Change the times of a series and of its flights that have not departed",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			if departureTime == "" && arrivalTime == "" {
+				return fmt.Errorf("nothing to amend: pass --time and/or --arrival")
+			}
+			return withSeriesUsecase(func(uc *usecase.SeriesUsecase) error {
+				moved, err := uc.Amend(context.Background(), id, departureTime, arrivalTime)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("amended series %d: %d flight(s) moved\n", id, len(moved))
+				return writeSeriesFlights(moved)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&departureTime, "time", "", "new local departure time at the origin (HH:MM)")
+	cmd.Flags().StringVar(&arrivalTime, "arrival", "", "new local arrival time at the destination (HH:MM)")
+	return cmd
+}
+
+func newSeriesCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <id>",
+		Short: "Cancel a series and remove its unbooked future flights",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parse id: %w", err)
+			}
+			return withSeriesUsecase(func(uc *usecase.SeriesUsecase) error {
+				removed, kept, err := uc.Cancel(context.Background(), id)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("cancelled series %d: removed %d flight(s), kept %d with bookings\n", id, removed, kept)
+				return nil
+			})
+		},
+	}
+}
+
+func printSeriesResult(res *usecase.SeriesResult) {
+	s := res.Series
+	fmt.Printf("series %d: %s with %s %s..%s %s: scheduled %d flight(s), skipped %d existing\n", s.ID, s.RouteCode, s.AirplaneCode, s.StartDate, s.EndDate, s.Days, len(res.Created), res.Skipped)
+}
+
+func writeSeriesFlights(flights []domain.FlightSchedule) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tDEPARTURE\tDEPARTS\tARRIVES")
+	for _, f := range flights {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", f.ID, f.DepartureDate, formatLocalTime(f.LocalDeparture()), formatLocalTime(f.LocalArrival()))
+	}
+	return tw.Flush()
+}
+
+func seriesStatus(s domain.ScheduleSeries) string {
+	if s.Cancelled() {
+		return "cancelled"
+	}
+	return "active"
+}
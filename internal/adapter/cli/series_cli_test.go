@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type fakeSeriesRepoCLI struct {
+	items   []domain.ScheduleSeries
+	flights []domain.FlightSchedule
+}
+
+func (f *fakeSeriesRepoCLI) add(id int64, flights []domain.FlightSchedule) []domain.FlightSchedule {
+	var created []domain.FlightSchedule
+	for _, fl := range flights {
+		fl.ID = int64(len(f.flights) + 1)
+		fl.SeriesID = id
+		f.flights = append(f.flights, fl)
+		created = append(created, fl)
+	}
+	return created
+}
+
+func (f *fakeSeriesRepoCLI) Create(ctx context.Context, s *domain.ScheduleSeries, flights []domain.FlightSchedule) ([]domain.FlightSchedule, error) {
+	s.ID = int64(len(f.items) + 1)
+	f.items = append(f.items, *s)
+	return f.add(s.ID, flights), nil
+}
+
+func (f *fakeSeriesRepoCLI) GetByID(ctx context.Context, id int64) (*domain.ScheduleSeries, error) {
+	if id < 1 || int(id) > len(f.items) {
+		return nil, domain.ErrSeriesNotFound
+	}
+	s := f.items[id-1]
+	return &s, nil
+}
+
+func (f *fakeSeriesRepoCLI) List(ctx context.Context, limit, offset int) ([]domain.ScheduleSeries, error) {
+	return f.items, nil
+}
+
+func (f *fakeSeriesRepoCLI) Flights(ctx context.Context, id int64) ([]domain.FlightSchedule, error) {
+	var out []domain.FlightSchedule
+	for _, fl := range f.flights {
+		if fl.SeriesID == id {
+			out = append(out, fl)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSeriesRepoCLI) Extend(ctx context.Context, s *domain.ScheduleSeries, flights []domain.FlightSchedule) ([]domain.FlightSchedule, error) {
+	f.items[s.ID-1] = *s
+	return f.add(s.ID, flights), nil
+}
+
+func (f *fakeSeriesRepoCLI) Amend(ctx context.Context, s *domain.ScheduleSeries, flights []domain.FlightSchedule) error {
+	f.items[s.ID-1] = *s
+	return nil
+}
+
+func (f *fakeSeriesRepoCLI) Cancel(ctx context.Context, id int64, from time.Time) (int, int, error) {
+	f.items[id-1].CancelledAt = from.Format(time.RFC3339)
+	removed := 0
+	for _, fl := range f.flights {
+		if fl.SeriesID == id && !fl.DepartureAt.Before(from) {
+			removed++
+		}
+	}
+	return removed, 0, nil
+}
+
+func TestScheduleCLI_Series(t *testing.T) {
+	oldDB, oldSeriesRepo, oldRouteRepo, oldPlaneRepo, oldAirportRepo, oldMaintenanceRepo := newScheduleDB, newSeriesRepo, newScheduleRouteRepo, newScheduleAirplaneRepo, newScheduleAirportRepo, newScheduleMaintenanceRepo
+	t.Cleanup(func() {
+		newScheduleDB = oldDB
+		newSeriesRepo = oldSeriesRepo
+		newScheduleRouteRepo = oldRouteRepo
+		newScheduleAirplaneRepo = oldPlaneRepo
+		newScheduleAirportRepo = oldAirportRepo
+		newScheduleMaintenanceRepo = oldMaintenanceRepo
+	})
+	newScheduleDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	series := &fakeSeriesRepoCLI{}
+	newSeriesRepo = func(*sqlx.DB) domain.ScheduleSeriesRepository { return series }
+	newScheduleRouteRepo = func(*sqlx.DB) domain.RouteRepository {
+		return &fakeRouteRepoCLIForSchedule{existing: map[string]bool{"RT1": true}}
+	}
+	newScheduleAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository {
+		return &fakeAirplaneRepoCLIForSchedule{existing: map[string]bool{"A320": true}}
+	}
+	newScheduleAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true}}
+	}
+	newScheduleMaintenanceRepo = func(*sqlx.DB) domain.MaintenanceRepository { return &fakeMaintenanceRepoCLI{} }
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	// Far-future dates keep every flight ahead of the clock for amend and cancel.
+	os.Args = []string{"flight-booking", "schedule", "create-series", "--route", "RT1", "--airplane", "A320", "--from", "2099-01-01", "--to", "2099-01-31", "--days", "Mon,Wed,Fri", "--time", "08:30"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("create-series: %v", err)
+		}
+	})
+	if !strings.Contains(out, "series 1: RT1 with A320 2099-01-01..2099-01-31 Mon,Wed,Fri: scheduled 13 flight(s), skipped 0 existing") {
+		t.Fatalf("unexpected create-series output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "create-series", "--route", "RT1", "--airplane", "A320", "--from", "2099-01-01", "--to", "2099-01-31", "--days", "Funday", "--time", "08:30"}
+	if err := Execute(); err != domain.ErrInvalidSeriesDays {
+		t.Fatalf("want invalid days, got %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "series", "extend", "1", "--to", "2099-02-07"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("extend: %v", err)
+		}
+	})
+	if !strings.Contains(out, "scheduled 3 flight(s)") {
+		t.Fatalf("expected three more flights, got %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "series", "amend", "1", "--time", "09:15"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("amend: %v", err)
+		}
+	})
+	if !strings.Contains(out, "16 flight(s) moved") || !strings.Contains(out, "2099-01-02 09:15") {
+		t.Fatalf("unexpected amend output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "series", "cancel", "1"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("cancel: %v", err)
+		}
+	})
+	if !strings.Contains(out, "removed 16 flight(s)") {
+		t.Fatalf("unexpected cancel output %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "series", "list"}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("list: %v", err)
+		}
+	})
+	if !strings.Contains(out, "cancelled") || !strings.Contains(out, "09:15") {
+		t.Fatalf("expected the amended, cancelled series listed, got %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "series", "show", "x"}
+	if err := Execute(); err == nil || !strings.Contains(err.Error(), "parse id") {
+		t.Fatalf("want parse error, got %v", err)
+	}
+}
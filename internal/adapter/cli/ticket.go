@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	sqlxrepo "github.com/ambiyansyah-risyal/flight-booking/internal/adapter/repository/sqlx"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/config"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
+)
+
+func newTicketCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "ticket", Short: "Inspect e-tickets and update coupon status"}
+	cmd.AddCommand(newTicketGetCmd())
+	cmd.AddCommand(newTicketListCmd())
+	cmd.AddCommand(newTicketCouponCmd("checkin", "Check in a ticket coupon", (*usecase.TicketUsecase).CheckIn))
+	cmd.AddCommand(newTicketCouponCmd("flown", "Mark a ticket coupon as flown", (*usecase.TicketUsecase).MarkFlown))
+	cmd.AddCommand(newTicketCouponCmd("refund", "Refund an unused ticket coupon", (*usecase.TicketUsecase).Refund))
+	return cmd
+}
+
+var (
+	newTicketDB          = func(dsn string) (*sqlx.DB, error) { return sqlxrepo.New(dsn) }
+	newTicketRepo        = func(db *sqlx.DB) domain.TicketRepository { return sqlxrepo.NewTicketRepository(db) }
+	newTicketBookingRepo = func(db *sqlx.DB) domain.BookingRepository { return sqlxrepo.NewBookingRepository(db) }
+)
+
+func withTicketUsecase(run func(*usecase.TicketUsecase) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := newTicketDB(cfg.Database.DSN())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	uc := usecase.NewTicketUsecase(newTicketRepo(db), newTicketBookingRepo(db), cfg.Ticketing.AirlinePrefix)
+	return run(uc)
+}
+
+func writeTickets(tickets []domain.Ticket) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TICKET\tBOOKING\tPASSENGER\tCOUPON\tSCHEDULE\tSTATUS")
+	for _, t := range tickets {
+		for _, c := range t.Coupons {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\n", t.Number, t.BookingReference, t.PassengerName, c.Sequence, c.ScheduleID, c.Status)
+		}
+	}
+	return tw.Flush()
+}
+
+func newTicketGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <number>",
+		Short: "Show a ticket and its coupons",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number := args[0]
+			return withTicketUsecase(func(uc *usecase.TicketUsecase) error {
+				ticket, err := uc.GetByNumber(context.Background(), number)
+				if err != nil {
+					return err
+				}
+				return writeTickets([]domain.Ticket{*ticket})
+			})
+		},
+	}
+	return cmd
+}
+
+func newTicketListCmd() *cobra.Command {
+	var reference string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tickets issued for a booking",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withTicketUsecase(func(uc *usecase.TicketUsecase) error {
+				tickets, err := uc.ListByBooking(context.Background(), reference)
+				if err != nil {
+					return err
+				}
+				return writeTickets(tickets)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&reference, "booking", "", "booking reference")
+	_ = cmd.MarkFlagRequired("booking")
+	return cmd
+}
+
+func newTicketCouponCmd(use, short string, apply func(*usecase.TicketUsecase, context.Context, string, int) (*domain.Coupon, error)) *cobra.Command {
+	var sequence int
+	cmd := &cobra.Command{
+		Use:   use + " <number>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number := args[0]
+			return withTicketUsecase(func(uc *usecase.TicketUsecase) error {
+				coupon, err := apply(uc, context.Background(), number, sequence)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("ticket %s coupon %d -> %s\n", number, coupon.Sequence, coupon.Status)
+				return nil
+			})
+		},
+	}
+	cmd.Flags().IntVar(&sequence, "coupon", 1, "coupon sequence number")
+	return cmd
+}
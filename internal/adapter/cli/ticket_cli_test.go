@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type fakeTicketRepoCLI struct {
+	serial int64
+	nextID int64
+	items  map[string]domain.Ticket
+}
+
+func newFakeTicketRepoCLI() *fakeTicketRepoCLI {
+	return &fakeTicketRepoCLI{items: make(map[string]domain.Ticket)}
+}
+
+func (f *fakeTicketRepoCLI) NextSerial(ctx context.Context) (int64, error) {
+	f.serial++
+	return f.serial, nil
+}
+
+func (f *fakeTicketRepoCLI) Create(ctx context.Context, t *domain.Ticket) error {
+	f.nextID++
+	t.ID = f.nextID
+	for i := range t.Coupons {
+		f.nextID++
+		t.Coupons[i].ID = f.nextID
+	}
+	f.items[t.Number] = *t
+	return nil
+}
+
+func (f *fakeTicketRepoCLI) GetByNumber(ctx context.Context, number string) (*domain.Ticket, error) {
+	if t, ok := f.items[number]; ok {
+		copy := t
+		return &copy, nil
+	}
+	return nil, domain.ErrTicketNotFound
+}
+
+func (f *fakeTicketRepoCLI) ListByBooking(ctx context.Context, bookingID int64) ([]domain.Ticket, error) {
+	var out []domain.Ticket
+	for _, t := range f.items {
+		if t.BookingID == bookingID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeTicketRepoCLI) UpdateCouponStatus(ctx context.Context, couponID int64, from, to string) error {
+	for _, t := range f.items {
+		for i := range t.Coupons {
+			if t.Coupons[i].ID == couponID {
+				if t.Coupons[i].Status != from {
+					return domain.ErrInvalidCouponTransition
+				}
+				t.Coupons[i].Status = to
+				return nil
+			}
+		}
+	}
+	return domain.ErrCouponNotFound
+}
+
+func TestTicketCLI_Flow(t *testing.T) {
+	oldDB, oldRepo, oldBookingRepo := newTicketDB, newTicketRepo, newTicketBookingRepo
+	t.Cleanup(func() {
+		newTicketDB = oldDB
+		newTicketRepo = oldRepo
+		newTicketBookingRepo = oldBookingRepo
+	})
+	newTicketDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	bookings := newFakeBookingRepoCLI()
+	_ = bookings.Create(context.Background(), &domain.Booking{Reference: "BK-AAAAAA", ScheduleID: 1, PassengerName: "Alice", SeatNumber: 1, Status: domain.BookingStatusConfirmed})
+	tickets := newFakeTicketRepoCLI()
+	_ = tickets.Create(context.Background(), &domain.Ticket{Number: "1260000000011", BookingID: 1, BookingReference: "BK-AAAAAA", PassengerName: "Alice", Coupons: []domain.Coupon{{Sequence: 1, ScheduleID: 1, Status: domain.CouponStatusOpen}}})
+	newTicketRepo = func(*sqlx.DB) domain.TicketRepository { return tickets }
+	newTicketBookingRepo = func(*sqlx.DB) domain.BookingRepository { return bookings }
+
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	os.Args = []string{"flight-booking", "ticket", "get", "1260000000011"}
+	out := captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("get: %v", err)
+		}
+	})
+	if !strings.Contains(out, "BK-AAAAAA") || !strings.Contains(out, domain.CouponStatusOpen) {
+		t.Fatalf("unexpected get output: %q", out)
+	}
+
+	os.Args = []string{"flight-booking", "ticket", "list", "--booking", "BK-AAAAAA"}
+	if err := Execute(); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "ticket", "checkin", "1260000000011", "--coupon", "1"}
+	if err := Execute(); err != nil {
+		t.Fatalf("checkin: %v", err)
+	}
+	if got := tickets.items["1260000000011"].Coupons[0].Status; got != domain.CouponStatusCheckedIn {
+		t.Fatalf("expected coupon checked in, got %s", got)
+	}
+
+	os.Args = []string{"flight-booking", "ticket", "refund", "1260000000011"}
+	if err := Execute(); err != domain.ErrInvalidCouponTransition {
+		t.Fatalf("want invalid transition, got %v", err)
+	}
+}
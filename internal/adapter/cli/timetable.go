@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/adapter/ssim"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/config"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+	"github.com/spf13/cobra"
+)
+
+func withTimetableUsecase(run func(*usecase.TimetableUsecase) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	db, err := newScheduleDB(cfg.Database.DSN())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	series := usecase.NewSeriesUsecase(newSeriesRepo(db), newScheduleRouteRepo(db), newScheduleAirplaneRepo(db), newScheduleAirportRepo(db)).
+		WithMaintenance(newScheduleMaintenanceRepo(db)).
+		WithRotation(newScheduleRepo(db), cfg.Rotation.MinTurnaround, cfg.Rotation.Strict)
+	return run(usecase.NewTimetableUsecase(series, newScheduleRepo(db)))
+}
+
+func checkTimetableFormat(format string) error {
+	if format != "ssim" {
+		return fmt.Errorf("unsupported format %q: only ssim is supported", format)
+	}
+	return nil
+}
+
+func newScheduleImportCmd() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Create routes and schedule series from a timetable file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkTimetableFormat(format); err != nil {
+				return err
+			}
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer func() { _ = f.Close() }()
+			entries, problems, err := ssim.Decode(f)
+			if err != nil {
+				return fmt.Errorf("read %s: %w", args[0], err)
+			}
+			return withTimetableUsecase(func(uc *usecase.TimetableUsecase) error {
+				res, err := uc.Import(context.Background(), entries)
+				if err != nil {
+					return err
+				}
+				for _, r := range res.Routes {
+					fmt.Printf("added route %s: %s -> %s\n", r.Code, r.OriginCode, r.DestinationCode)
+				}
+				for i := range res.Series {
+					printSeriesResult(&res.Series[i])
+				}
+				problems = append(problems, res.Errors...)
+				sortImportErrors(problems)
+				for _, p := range problems {
+					fmt.Println(p.Error())
+				}
+				fmt.Printf("imported %d series, rejected %d line(s)\n", len(res.Series), len(problems))
+				if len(problems) > 0 {
+					return fmt.Errorf("%d line(s) rejected", len(problems))
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "ssim", "file format (ssim)")
+	return cmd
+}
+
+func newScheduleExportCmd() *cobra.Command {
+	var format, airline, output string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write active series and one-off flights as a timetable file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkTimetableFormat(format); err != nil {
+				return err
+			}
+			return withTimetableUsecase(func(uc *usecase.TimetableUsecase) error {
+				entries, err := uc.Export(context.Background())
+				if err != nil {
+					return err
+				}
+				var w io.Writer = os.Stdout
+				if output != "" {
+					f, err := os.Create(output)
+					if err != nil {
+						return err
+					}
+					defer func() { _ = f.Close() }()
+					w = f
+				}
+				if err := ssim.Encode(w, airline, time.Now(), entries); err != nil {
+					return err
+				}
+				if output != "" {
+					fmt.Printf("exported %d flight leg(s) to %s\n", len(entries), output)
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "ssim", "file format (ssim)")
+	cmd.Flags().StringVar(&airline, "airline", "ZZ", "airline designator written to the file")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write (default stdout)")
+	return cmd
+}
+
+// sortImportErrors orders problems from decoding and importing by line.
+func sortImportErrors(problems []usecase.ImportError) {
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestScheduleCLI_ImportExportSSIM(t *testing.T) {
+	oldDB, oldRepo, oldSeriesRepo, oldRouteRepo, oldPlaneRepo, oldAirportRepo, oldMaintenanceRepo := newScheduleDB, newScheduleRepo, newSeriesRepo, newScheduleRouteRepo, newScheduleAirplaneRepo, newScheduleAirportRepo, newScheduleMaintenanceRepo
+	t.Cleanup(func() {
+		newScheduleDB = oldDB
+		newScheduleRepo = oldRepo
+		newSeriesRepo = oldSeriesRepo
+		newScheduleRouteRepo = oldRouteRepo
+		newScheduleAirplaneRepo = oldPlaneRepo
+		newScheduleAirportRepo = oldAirportRepo
+		newScheduleMaintenanceRepo = oldMaintenanceRepo
+	})
+	newScheduleDB = func(string) (*sqlx.DB, error) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			return nil, fmt.Errorf("sqlmock: %w", err)
+		}
+		return sqlx.NewDb(db, "pgx"), nil
+	}
+	series := &fakeSeriesRepoCLI{}
+	newScheduleRepo = func(*sqlx.DB) domain.FlightScheduleRepository { return &fakeScheduleRepoCLI{} }
+	newSeriesRepo = func(*sqlx.DB) domain.ScheduleSeriesRepository { return series }
+	newScheduleRouteRepo = func(*sqlx.DB) domain.RouteRepository {
+		return &fakeRouteRepoCLIForSchedule{existing: map[string]bool{"RT1": true}}
+	}
+	newScheduleAirplaneRepo = func(*sqlx.DB) domain.AirplaneRepository {
+		return &fakeAirplaneRepoCLIForSchedule{existing: map[string]bool{"PK-GQA": true}, types: map[string]string{"PK-GQA": "320"}}
+	}
+	newScheduleAirportRepo = func(*sqlx.DB) domain.AirportRepository {
+		return &fakeAirportRepoCLI{existing: map[string]bool{"CGK": true, "DPS": true}}
+	}
+	newScheduleMaintenanceRepo = func(*sqlx.DB) domain.MaintenanceRepository { return &fakeMaintenanceRepoCLI{} }
+	t.Setenv("FLIGHT_DB_HOST", "localhost")
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.ssim")
+	legs := []string{
+		"2LZZ",
+		"3 ZZ 00010101J01JAN3031JAN301 3 5   CGK08300830+0700  DPS11201120+0800  320",
+		"3 ZZ 00020101J01JAN3031JAN301 3 5   CGK08300830+0700  KNO11201120+0700  320",
+		"3 ZZ 00030101J01JAN30",
+	}
+	if err := os.WriteFile(input, []byte(strings.Join(legs, "\n")+"\n"), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "import", "--format", "ssim", input}
+	var err error
+	out := captureOutput(func() { err = Execute() })
+	if err == nil || err.Error() != "2 line(s) rejected" {
+		t.Fatalf("want two rejected lines, got %v", err)
+	}
+	if !strings.Contains(out, "series 1: RT1 with PK-GQA 2030-01-01..2030-01-31 Mon,Wed,Fri: scheduled 13 flight(s)") ||
+		!strings.Contains(out, "line 3: airport not found") || !strings.Contains(out, "line 4: period to") {
+		t.Fatalf("unexpected import output %q", out)
+	}
+
+	output := filepath.Join(dir, "out.ssim")
+	os.Args = []string{"flight-booking", "schedule", "export", "--format", "ssim", "--airline", "FB", "--output", output}
+	out = captureOutput(func() {
+		if err := Execute(); err != nil {
+			t.Fatalf("export: %v", err)
+		}
+	})
+	if !strings.Contains(out, "exported 1 flight leg(s)") {
+		t.Fatalf("unexpected export output %q", out)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	if !strings.Contains(string(data), "3 FB 00010101J01JAN3031JAN301 3 5   CGK08300830+0000  DPS11201120+0000  320") {
+		t.Fatalf("unexpected export\n%s", data)
+	}
+
+	os.Args = []string{"flight-booking", "schedule", "export", "--format", "csv"}
+	if err := Execute(); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+		t.Fatalf("want unsupported format, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+const aircraftTypeColumns = `SELECT id, icao_code, iata_code, manufacturer, model, range_km, seat_rows, seat_layout, created_at FROM aircraft_types`
+
+// AircraftTypeRepository persists the aircraft type catalog using sqlx.
+type AircraftTypeRepository struct {
+	db *sqlx.DB
+}
+
+func NewAircraftTypeRepository(db *sqlx.DB) *AircraftTypeRepository {
+	return &AircraftTypeRepository{db: db}
+}
+
+func (r *AircraftTypeRepository) Create(ctx context.Context, t *domain.AircraftType) error {
+	query := `INSERT INTO aircraft_types (icao_code, iata_code, manufacturer, model, range_km, seat_rows, seat_layout) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id, created_at`
+	var createdAt time.Time
+	if err := r.db.QueryRowContext(ctx, query, t.ICAOCode, t.IATACode, t.Manufacturer, t.Model, t.RangeKm, t.SeatMap.Rows, t.SeatMap.Layout).Scan(&t.ID, &createdAt); err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrAircraftTypeExists
+		}
+		return err
+	}
+	t.CreatedAt = createdAt.Format(time.RFC3339)
+	return nil
+}
+
+func (r *AircraftTypeRepository) GetByCode(ctx context.Context, icaoCode string) (*domain.AircraftType, error) {
+	t, err := scanAircraftType(r.db.QueryRowxContext(ctx, aircraftTypeColumns+` WHERE icao_code=$1`, icaoCode))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrAircraftTypeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *AircraftTypeRepository) List(ctx context.Context, limit, offset int) ([]domain.AircraftType, error) {
+	rows, err := r.db.QueryxContext(ctx, aircraftTypeColumns+` ORDER BY icao_code LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []domain.AircraftType
+	for rows.Next() {
+		t, err := scanAircraftType(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *t)
+	}
+	return out, rows.Err()
+}
+
+func (r *AircraftTypeRepository) Delete(ctx context.Context, icaoCode string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM aircraft_types WHERE icao_code=$1`, icaoCode)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrAircraftTypeInUse
+		}
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrAircraftTypeNotFound
+	}
+	return nil
+}
+
+func scanAircraftType(row interface{ Scan(...any) error }) (*domain.AircraftType, error) {
+	var (
+		t         domain.AircraftType
+		createdAt time.Time
+	)
+	if err := row.Scan(&t.ID, &t.ICAOCode, &t.IATACode, &t.Manufacturer, &t.Model, &t.RangeKm, &t.SeatMap.Rows, &t.SeatMap.Layout, &createdAt); err != nil {
+		return nil, err
+	}
+	t.CreatedAt = createdAt.Format(time.RFC3339)
+	return &t, nil
+}
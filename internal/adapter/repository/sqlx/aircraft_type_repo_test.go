@@ -0,0 +1,68 @@
+package sqlxrepo
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+var aircraftTypeRowColumns = []string{"id", "icao_code", "iata_code", "manufacturer", "model", "range_km", "seat_rows", "seat_layout", "created_at"}
+
+func TestAircraftTypeRepository_CRUD(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewAircraftTypeRepository(db)
+	now := time.Now()
+
+	insert := regexp.QuoteMeta(`INSERT INTO aircraft_types (icao_code, iata_code, manufacturer, model, range_km, seat_rows, seat_layout) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id, created_at`)
+	mock.ExpectQuery(insert).WithArgs("A320", "320", "Airbus", "A320-200", 6100, 30, "3-3").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, now))
+	at := &domain.AircraftType{ICAOCode: "A320", IATACode: "320", Manufacturer: "Airbus", Model: "A320-200", RangeKm: 6100, SeatMap: domain.SeatMap{Rows: 30, Layout: "3-3"}}
+	if err := repo.Create(context.Background(), at); err != nil || at.ID != 1 {
+		t.Fatalf("create: %v id=%d", err, at.ID)
+	}
+	mock.ExpectQuery(insert).WillReturnError(errors.New("duplicate key value violates unique constraint"))
+	if err := repo.Create(context.Background(), at); err != domain.ErrAircraftTypeExists {
+		t.Fatalf("want exists, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(aircraftTypeColumns + ` WHERE icao_code=$1`)).WithArgs("A320").
+		WillReturnRows(sqlmock.NewRows(aircraftTypeRowColumns).AddRow(1, "A320", "320", "Airbus", "A320-200", 6100, 30, "3-3", now))
+	got, err := repo.GetByCode(context.Background(), "A320")
+	if err != nil || got.Name() != "Airbus A320-200" || got.SeatMap.Seats() != 180 {
+		t.Fatalf("get: %+v err=%v", got, err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(aircraftTypeColumns + ` WHERE icao_code=$1`)).WithArgs("B738").
+		WillReturnRows(sqlmock.NewRows(aircraftTypeRowColumns))
+	if _, err := repo.GetByCode(context.Background(), "B738"); err != domain.ErrAircraftTypeNotFound {
+		t.Fatalf("want not found, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(aircraftTypeColumns+` ORDER BY icao_code LIMIT $1 OFFSET $2`)).WithArgs(10, 0).
+		WillReturnRows(sqlmock.NewRows(aircraftTypeRowColumns).AddRow(1, "A320", "320", "Airbus", "A320-200", 6100, 30, "3-3", now))
+	if items, err := repo.List(context.Background(), 10, 0); err != nil || len(items) != 1 {
+		t.Fatalf("list: %v n=%d", err, len(items))
+	}
+
+	del := regexp.QuoteMeta(`DELETE FROM aircraft_types WHERE icao_code=$1`)
+	mock.ExpectExec(del).WithArgs("A320").WillReturnError(errors.New(`update or delete on table "aircraft_types" violates foreign key constraint`))
+	if err := repo.Delete(context.Background(), "A320"); err != domain.ErrAircraftTypeInUse {
+		t.Fatalf("want in use, got %v", err)
+	}
+	mock.ExpectExec(del).WithArgs("B738").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := repo.Delete(context.Background(), "B738"); err != domain.ErrAircraftTypeNotFound {
+		t.Fatalf("want not found, got %v", err)
+	}
+	mock.ExpectExec(del).WithArgs("A320").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.Delete(context.Background(), "A320"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
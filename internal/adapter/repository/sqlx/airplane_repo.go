@@ -13,13 +13,25 @@ import (
 
 type AirplaneRepository struct { db *sqlx.DB }
 
+// airplaneColumns reads airplanes with the name of their aircraft type.
+const airplaneColumns = `SELECT p.id, p.code, COALESCE(p.type_code, ''), COALESCE(t.manufacturer || ' ' || t.model, ''), COALESCE(t.iata_code, ''), p.seat_capacity, p.created_at, p.archived_at FROM airplanes p LEFT JOIN aircraft_types t ON t.icao_code = p.type_code`
+
+// futureInventoryQuery locks the inventory of an airplane's flights departing
+// from a given instant and returns their confirmed and held seats.
+const futureInventoryQuery = `SELECT i.schedule_id, i.sold + i.held FROM seat_inventory i JOIN flight_schedules s ON s.id = i.schedule_id WHERE s.airplane_code=$1 AND s.departure_at >= $2 ORDER BY s.departure_at, i.schedule_id FOR UPDATE OF i`
+
+// resizeInventoryQuery sets the capacity of one flight; blocked seats give
+// way to bookings when it shrinks.
+const resizeInventoryQuery = `UPDATE seat_inventory SET capacity=$2, blocked=LEAST(blocked, GREATEST($2 - sold - held, 0)), updated_at=now() WHERE schedule_id=$1`
+
 func NewAirplaneRepository(db *sqlx.DB) *AirplaneRepository { return &AirplaneRepository{db: db} }
 
 func (r *AirplaneRepository) Create(ctx context.Context, a *domain.Airplane) error {
-    q := `INSERT INTO airplanes (code, seat_capacity) VALUES ($1,$2) RETURNING id, created_at`
+    q := `INSERT INTO airplanes (code, type_code, seat_capacity) VALUES ($1,NULLIF($2,''),$3) RETURNING id, created_at`
     var created time.Time
-    if err := r.db.QueryRowContext(ctx, q, a.Code, a.SeatCapacity).Scan(&a.ID, &created); err != nil {
+    if err := r.db.QueryRowContext(ctx, q, a.Code, a.TypeCode, a.SeatCapacity).Scan(&a.ID, &created); err != nil {
         if localUniqueViolation(err) { return domain.ErrAirplaneExists }
+        if isForeignKeyViolation(err) { return domain.ErrAircraftTypeNotFound }
         return err
     }
     a.CreatedAt = created.Format(time.RFC3339)
@@ -27,47 +39,101 @@ func (r *AirplaneRepository) Create(ctx context.Context, a *domain.Airplane) err
 }
 
 func (r *AirplaneRepository) GetByCode(ctx context.Context, code string) (*domain.Airplane, error) {
-    var out domain.Airplane
-    var created time.Time
-    err := r.db.QueryRowContext(ctx, `SELECT id, code, seat_capacity, created_at FROM airplanes WHERE code=$1`, code).
-        Scan(&out.ID, &out.Code, &out.SeatCapacity, &created)
+    out, err := scanAirplane(r.db.QueryRowContext(ctx, airplaneColumns+` WHERE p.code=$1`, code))
     if err != nil {
         if errors.Is(err, sql.ErrNoRows) { return nil, domain.ErrAirplaneNotFound }
         return nil, err
     }
-    out.CreatedAt = created.Format(time.RFC3339)
-    return &out, nil
+    return out, nil
 }
 
-func (r *AirplaneRepository) List(ctx context.Context, limit, offset int) ([]domain.Airplane, error) {
-    rows, err := r.db.QueryxContext(ctx, `SELECT id, code, seat_capacity, created_at FROM airplanes ORDER BY code LIMIT $1 OFFSET $2`, limit, offset)
+func (r *AirplaneRepository) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airplane, error) {
+    rows, err := r.db.QueryxContext(ctx, airplaneColumns+activeOnly("p.", includeArchived)+` ORDER BY p.code LIMIT $1 OFFSET $2`, limit, offset)
     if err != nil { return nil, err }
     defer func(){ _ = rows.Close() }()
     var items []domain.Airplane
     for rows.Next() {
-        var a domain.Airplane
-        var created time.Time
-        if err := rows.Scan(&a.ID, &a.Code, &a.SeatCapacity, &created); err != nil { return nil, err }
-        a.CreatedAt = created.Format(time.RFC3339)
-        items = append(items, a)
+        a, err := scanAirplane(rows)
+        if err != nil { return nil, err }
+        items = append(items, *a)
     }
     return items, rows.Err()
 }
 
-func (r *AirplaneRepository) UpdateSeats(ctx context.Context, code string, seats int) error {
-    res, err := r.db.ExecContext(ctx, `UPDATE airplanes SET seat_capacity=$2 WHERE code=$1`, code, seats)
-    if err != nil { return err }
-    n, _ := res.RowsAffected()
-    if n == 0 { return domain.ErrAirplaneNotFound }
-    return nil
+// SetArchived archives the airplane, keeping when it first was, or restores it.
+func (r *AirplaneRepository) SetArchived(ctx context.Context, code string, archived bool) error {
+    return setArchived(ctx, r.db, "airplanes", code, archived, domain.ErrAirplaneNotFound)
 }
 
-func (r *AirplaneRepository) Delete(ctx context.Context, code string) error {
-    res, err := r.db.ExecContext(ctx, `DELETE FROM airplanes WHERE code=$1`, code)
-    if err != nil { return err }
+func scanAirplane(row interface{ Scan(...any) error }) (*domain.Airplane, error) {
+    var a domain.Airplane
+    var created time.Time
+    var archived sql.NullTime
+    if err := row.Scan(&a.ID, &a.Code, &a.TypeCode, &a.TypeName, &a.TypeIATACode, &a.SeatCapacity, &created, &archived); err != nil { return nil, err }
+    a.CreatedAt = created.Format(time.RFC3339)
+    a.ArchivedAt = archivedAt(archived)
+    return &a, nil
+}
+
+// UpdateSeats resizes the airplane and the seat inventory of its future flights
+// together; flights that have departed keep the capacity they flew with. The
+// inventory rows of the future flights are locked first, so no booking can
+// slip in between the capacity check and the resize. Forced changes leave the
+// inventory of overbooked flights as it is until their bookings are moved.
+func (r *AirplaneRepository) UpdateSeats(ctx context.Context, code string, seats int, from time.Time, force bool) (*domain.CapacityChange, error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil { return nil, err }
+    defer func() { _ = tx.Rollback() }()
+    res, err := tx.ExecContext(ctx, `UPDATE airplanes SET seat_capacity=$2 WHERE code=$1`, code, seats)
+    if err != nil { return nil, err }
     n, _ := res.RowsAffected()
-    if n == 0 { return domain.ErrAirplaneNotFound }
-    return nil
+    if n == 0 { return nil, domain.ErrAirplaneNotFound }
+
+    rows, err := tx.QueryContext(ctx, futureInventoryQuery, code, from.UTC())
+    if err != nil { return nil, err }
+    var future []domain.CapacityError
+    for rows.Next() {
+        f := domain.CapacityError{Capacity: seats}
+        if err := rows.Scan(&f.ScheduleID, &f.Booked); err != nil { _ = rows.Close(); return nil, err }
+        future = append(future, f)
+    }
+    if err := rows.Err(); err != nil { _ = rows.Close(); return nil, err }
+    _ = rows.Close()
+
+    change := &domain.CapacityChange{}
+    for _, f := range future {
+        if f.Booked > seats { change.Overbooked = append(change.Overbooked, f) }
+    }
+    if len(change.Overbooked) > 0 && !force {
+        return nil, &domain.FleetCapacityError{AirplaneCode: code, Capacity: seats, Flights: change.Overbooked}
+    }
+    for _, f := range future {
+        // Overbooked flights keep their seats until their bookings are moved.
+        if f.Booked > seats { continue }
+        moves, err := remapSeats(ctx, tx, f.ScheduleID, seats)
+        if err != nil { return nil, err }
+        change.Moves = append(change.Moves, moves...)
+        if _, err := tx.ExecContext(ctx, resizeInventoryQuery, f.ScheduleID, seats); err != nil { return nil, err }
+    }
+    if err := tx.Commit(); err != nil { return nil, err }
+    return change, nil
+}
+
+// airplaneDelete removes an airplane; flights and series restrict it, so
+// they are deleted first. Maintenance windows go with it.
+var airplaneDelete = deletePlan{
+    entity:     domain.EntityAirplane,
+    notFound:   domain.ErrAirplaneNotFound,
+    dependents: `SELECT 0, (SELECT COUNT(*) FROM flight_schedules s WHERE s.airplane_code = p.code), (SELECT COUNT(*) FROM schedule_series ss WHERE ss.airplane_code = p.code), (SELECT COUNT(*) FROM bookings b JOIN flight_schedules s ON s.id = b.schedule_id WHERE s.airplane_code = p.code), 0, (SELECT COUNT(*) FROM airplane_maintenance m WHERE m.airplane_code = p.code) FROM airplanes p WHERE p.code=$1 FOR UPDATE`,
+    statements: []string{
+        `DELETE FROM flight_schedules WHERE airplane_code=$1`,
+        `DELETE FROM schedule_series WHERE airplane_code=$1`,
+        `DELETE FROM airplanes WHERE code=$1`,
+    },
+}
+
+func (r *AirplaneRepository) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+    return airplaneDelete.exec(ctx, r.db, code, cascade)
 }
 
 // localUniqueViolation avoids colliding with airport repo's helper name in linters/build
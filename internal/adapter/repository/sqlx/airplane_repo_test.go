@@ -2,6 +2,7 @@ package sqlxrepo
 
 import (
     "context"
+    "errors"
     "fmt"
     "regexp"
     "testing"
@@ -24,24 +25,32 @@ func TestAirplaneRepo_Create_List_Update_Delete(t *testing.T) {
     defer cleanup()
     repo := NewAirplaneRepository(db)
     now := time.Now()
-    mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO airplanes (code, seat_capacity) VALUES ($1,$2) RETURNING id, created_at`)).
-        WithArgs("B737", 180).WillReturnRows(sqlmock.NewRows([]string{"id","created_at"}).AddRow(1, now))
+    mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO airplanes (code, type_code, seat_capacity) VALUES ($1,NULLIF($2,''),$3) RETURNING id, created_at`)).
+        WithArgs("B737", "", 180).WillReturnRows(sqlmock.NewRows([]string{"id","created_at"}).AddRow(1, now))
     a := &domain.Airplane{Code:"B737", SeatCapacity:180}
     if err := repo.Create(context.Background(), a); err != nil { t.Fatalf("create: %v", err) }
 
-    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, seat_capacity, created_at FROM airplanes ORDER BY code LIMIT $1 OFFSET $2`)).
+    mock.ExpectQuery(regexp.QuoteMeta(airplaneColumns+` WHERE p.archived_at IS NULL ORDER BY p.code LIMIT $1 OFFSET $2`)).
         WithArgs(10, 0).
-        WillReturnRows(sqlmock.NewRows([]string{"id","code","seat_capacity","created_at"}).AddRow(1,"B737",180, now))
-    items, err := repo.List(context.Background(), 10, 0)
+        WillReturnRows(sqlmock.NewRows([]string{"id","code","type_code","type_name","type_iata_code","seat_capacity","created_at","archived_at"}).AddRow(1,"B737","","","",180, now, nil))
+    items, err := repo.List(context.Background(), 10, 0, false)
     if err != nil || len(items) != 1 { t.Fatalf("list: %v n=%d", err, len(items)) }
 
+    mock.ExpectBegin()
     mock.ExpectExec(regexp.QuoteMeta(`UPDATE airplanes SET seat_capacity=$2 WHERE code=$1`)).
         WithArgs("B737", 200).WillReturnResult(sqlmock.NewResult(0,1))
-    if err := repo.UpdateSeats(context.Background(), "B737", 200); err != nil { t.Fatalf("update: %v", err) }
-
-    mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM airplanes WHERE code=$1`)).
-        WithArgs("B737").WillReturnResult(sqlmock.NewResult(0,1))
-    if err := repo.Delete(context.Background(), "B737"); err != nil { t.Fatalf("delete: %v", err) }
+    mock.ExpectQuery(regexp.QuoteMeta(futureInventoryQuery)).
+        WithArgs("B737", sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"schedule_id","booked"}).AddRow(7, 150))
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, reference, seat_number FROM bookings WHERE schedule_id=$1 AND status<>'CANCELLED' AND seat_number > $2 ORDER BY seat_number`)).
+        WithArgs(int64(7), 200).WillReturnRows(sqlmock.NewRows([]string{"id","reference","seat_number"}))
+    mock.ExpectExec(regexp.QuoteMeta(resizeInventoryQuery)).
+        WithArgs(int64(7), 200).WillReturnResult(sqlmock.NewResult(0,1))
+    mock.ExpectCommit()
+    if _, err := repo.UpdateSeats(context.Background(), "B737", 200, now, false); err != nil { t.Fatalf("update: %v", err) }
+
+    expectDependents(mock, airplaneDelete, "B737", &domain.Dependents{})
+    expectDeleted(mock, airplaneDelete, "B737")
+    if _, err := repo.Delete(context.Background(), "B737", nil); err != nil { t.Fatalf("delete: %v", err) }
 }
 
 type pqErr struct{ msg string }
@@ -52,27 +61,35 @@ func TestAirplaneRepo_Duplicate_NotFound_Paths(t *testing.T) {
     defer cleanup()
     repo := NewAirplaneRepository(db)
 
-    mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO airplanes (code, seat_capacity) VALUES ($1,$2) RETURNING id, created_at`)).
-        WithArgs("B737", 180).WillReturnError(&pqErr{msg:"duplicate key value violates unique constraint"})
+    mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO airplanes (code, type_code, seat_capacity) VALUES ($1,NULLIF($2,''),$3) RETURNING id, created_at`)).
+        WithArgs("B737", "", 180).WillReturnError(&pqErr{msg:"duplicate key value violates unique constraint"})
     if err := repo.Create(context.Background(), &domain.Airplane{Code:"B737", SeatCapacity:180}); err != domain.ErrAirplaneExists {
         t.Fatalf("want exists, got %v", err)
     }
 
-    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, seat_capacity, created_at FROM airplanes WHERE code=$1`)).
-        WithArgs("NONE").WillReturnRows(sqlmock.NewRows([]string{"id","code","seat_capacity","created_at"}))
+    mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO airplanes (code, type_code, seat_capacity) VALUES ($1,NULLIF($2,''),$3) RETURNING id, created_at`)).
+        WithArgs("PK-GQA", "A999", 180).WillReturnError(&pqErr{msg:"insert violates foreign key constraint \"airplanes_type_code_fkey\""})
+    if err := repo.Create(context.Background(), &domain.Airplane{Code:"PK-GQA", TypeCode:"A999", SeatCapacity:180}); err != domain.ErrAircraftTypeNotFound {
+        t.Fatalf("want type not found, got %v", err)
+    }
+
+    mock.ExpectQuery(regexp.QuoteMeta(airplaneColumns+` WHERE p.code=$1`)).
+        WithArgs("NONE").WillReturnRows(sqlmock.NewRows([]string{"id","code","type_code","type_name","type_iata_code","seat_capacity","created_at","archived_at"}))
     if _, err := repo.GetByCode(context.Background(), "NONE"); err != domain.ErrAirplaneNotFound {
         t.Fatalf("want not found, got %v", err)
     }
 
+    mock.ExpectBegin()
     mock.ExpectExec(regexp.QuoteMeta(`UPDATE airplanes SET seat_capacity=$2 WHERE code=$1`)).
         WithArgs("NONE", 100).WillReturnResult(sqlmock.NewResult(0,0))
-    if err := repo.UpdateSeats(context.Background(), "NONE", 100); err != domain.ErrAirplaneNotFound {
+    mock.ExpectRollback()
+    if _, err := repo.UpdateSeats(context.Background(), "NONE", 100, time.Now(), false); err != domain.ErrAirplaneNotFound {
         t.Fatalf("want not found update, got %v", err)
     }
 
-    mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM airplanes WHERE code=$1`)).
-        WithArgs("NONE").WillReturnResult(sqlmock.NewResult(0,0))
-    if err := repo.Delete(context.Background(), "NONE"); err != domain.ErrAirplaneNotFound {
+    expectDependents(mock, airplaneDelete, "NONE", nil)
+    mock.ExpectRollback()
+    if _, err := repo.Delete(context.Background(), "NONE", nil); err != domain.ErrAirplaneNotFound {
         t.Fatalf("want not found delete, got %v", err)
     }
 }
@@ -82,23 +99,23 @@ func TestAirplaneRepo_ListErrors_And_GetByCode(t *testing.T) {
     defer cleanup()
     repo := NewAirplaneRepository(db)
     // query error
-    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, seat_capacity, created_at FROM airplanes ORDER BY code LIMIT $1 OFFSET $2`)).
+    mock.ExpectQuery(regexp.QuoteMeta(airplaneColumns+` WHERE p.archived_at IS NULL ORDER BY p.code LIMIT $1 OFFSET $2`)).
         WithArgs(5, 0).WillReturnError(fmt.Errorf("db down"))
-    if _, err := repo.List(context.Background(), 5, 0); err == nil { t.Fatalf("expected error") }
+    if _, err := repo.List(context.Background(), 5, 0, false); err == nil { t.Fatalf("expected error") }
 
     // rows error
     now := time.Now()
-    rows := sqlmock.NewRows([]string{"id","code","seat_capacity","created_at"}).AddRow(1, "A320", 150, now)
+    rows := sqlmock.NewRows([]string{"id","code","type_code","type_name","type_iata_code","seat_capacity","created_at","archived_at"}).AddRow(1, "A320", "", "", "", 150, now, nil)
     rows.RowError(0, fmt.Errorf("scan error"))
-    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, seat_capacity, created_at FROM airplanes ORDER BY code LIMIT $1 OFFSET $2`)).
+    mock.ExpectQuery(regexp.QuoteMeta(airplaneColumns+` WHERE p.archived_at IS NULL ORDER BY p.code LIMIT $1 OFFSET $2`)).
         WithArgs(5, 0).WillReturnRows(rows)
-    if _, err := repo.List(context.Background(), 5, 0); err == nil { t.Fatalf("expected rows error") }
+    if _, err := repo.List(context.Background(), 5, 0, false); err == nil { t.Fatalf("expected rows error") }
 
     // get by code success
-    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, seat_capacity, created_at FROM airplanes WHERE code=$1`)).
-        WithArgs("A320").WillReturnRows(sqlmock.NewRows([]string{"id","code","seat_capacity","created_at"}).AddRow(2, "A320", 150, now))
+    mock.ExpectQuery(regexp.QuoteMeta(airplaneColumns+` WHERE p.code=$1`)).
+        WithArgs("A320").WillReturnRows(sqlmock.NewRows([]string{"id","code","type_code","type_name","type_iata_code","seat_capacity","created_at","archived_at"}).AddRow(2, "A320", "A320", "Airbus A320-200", "320", 150, now, nil))
     a, err := repo.GetByCode(context.Background(), "A320")
-    if err != nil || a.Code != "A320" { t.Fatalf("get success err=%v a=%+v", err, a) }
+    if err != nil || a.Code != "A320" || a.TypeName != "Airbus A320-200" || a.TypeIATACode != "320" { t.Fatalf("get success err=%v a=%+v", err, a) }
 }
 
 func TestAirplaneRepo_UpdateDelete_ErrExec(t *testing.T) {
@@ -107,9 +124,55 @@ func TestAirplaneRepo_UpdateDelete_ErrExec(t *testing.T) {
     repo := NewAirplaneRepository(db)
     mock.ExpectExec(regexp.QuoteMeta(`UPDATE airplanes SET seat_capacity=$2 WHERE code=$1`)).
         WithArgs("B737", 123).WillReturnError(fmt.Errorf("exec fail"))
-    if err := repo.UpdateSeats(context.Background(), "B737", 123); err == nil { t.Fatalf("expected exec error") }
+    if _, err := repo.UpdateSeats(context.Background(), "B737", 123, time.Now(), false); err == nil { t.Fatalf("expected exec error") }
 
-    mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM airplanes WHERE code=$1`)).
+    expectDependents(mock, airplaneDelete, "B737", &domain.Dependents{})
+    mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM flight_schedules WHERE airplane_code=$1`)).
         WithArgs("B737").WillReturnError(fmt.Errorf("exec fail"))
-    if err := repo.Delete(context.Background(), "B737"); err == nil { t.Fatalf("expected exec error") }
+    mock.ExpectRollback()
+    if _, err := repo.Delete(context.Background(), "B737", nil); err == nil { t.Fatalf("expected exec error") }
+}
+
+func TestAirplaneRepo_UpdateSeats_RefusesOverbookedFlights(t *testing.T) {
+    db, mock, cleanup := newMockAirDB(t)
+    defer cleanup()
+    repo := NewAirplaneRepository(db)
+    mock.ExpectBegin()
+    mock.ExpectExec(regexp.QuoteMeta(`UPDATE airplanes SET seat_capacity=$2 WHERE code=$1`)).
+        WithArgs("B737", 2).WillReturnResult(sqlmock.NewResult(0,1))
+    mock.ExpectQuery(regexp.QuoteMeta(futureInventoryQuery)).
+        WithArgs("B737", sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"schedule_id","booked"}).AddRow(7, 1).AddRow(8, 3))
+    mock.ExpectRollback()
+    _, err := repo.UpdateSeats(context.Background(), "B737", 2, time.Now(), false)
+    var fleet *domain.FleetCapacityError
+    if !errors.As(err, &fleet) || !errors.Is(err, domain.ErrCapacityBelowBookings) { t.Fatalf("want fleet capacity error, got %v", err) }
+    if len(fleet.Flights) != 1 || fleet.Flights[0].ScheduleID != 8 || fleet.Flights[0].Booked != 3 { t.Fatalf("unexpected flights %+v", fleet.Flights) }
+    if err := mock.ExpectationsWereMet(); err != nil { t.Fatalf("expectations: %v", err) }
+}
+
+func TestAirplaneRepo_UpdateSeats_ForceRemapsFittingFlights(t *testing.T) {
+    db, mock, cleanup := newMockAirDB(t)
+    defer cleanup()
+    repo := NewAirplaneRepository(db)
+    mock.ExpectBegin()
+    mock.ExpectExec(regexp.QuoteMeta(`UPDATE airplanes SET seat_capacity=$2 WHERE code=$1`)).
+        WithArgs("B737", 2).WillReturnResult(sqlmock.NewResult(0,1))
+    mock.ExpectQuery(regexp.QuoteMeta(futureInventoryQuery)).
+        WithArgs("B737", sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"schedule_id","booked"}).AddRow(7, 1).AddRow(8, 3))
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, reference, seat_number FROM bookings WHERE schedule_id=$1 AND status<>'CANCELLED' AND seat_number > $2 ORDER BY seat_number`)).
+        WithArgs(int64(7), 2).WillReturnRows(sqlmock.NewRows([]string{"id","reference","seat_number"}).AddRow(11, "REF11", 5))
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT n FROM generate_series(1, $2::int)`)).
+        WithArgs(int64(7), 2, 1).WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+    mock.ExpectExec(regexp.QuoteMeta(`UPDATE bookings SET seat_number=$2 WHERE id=$1`)).
+        WithArgs(int64(11), 1).WillReturnResult(sqlmock.NewResult(0,1))
+    // Schedule 8 stays as it is until its bookings are moved.
+    mock.ExpectExec(regexp.QuoteMeta(resizeInventoryQuery)).
+        WithArgs(int64(7), 2).WillReturnResult(sqlmock.NewResult(0,1))
+    mock.ExpectCommit()
+    change, err := repo.UpdateSeats(context.Background(), "B737", 2, time.Now(), true)
+    if err != nil { t.Fatalf("update: %v", err) }
+    want := domain.SeatMove{ScheduleID: 7, BookingID: 11, Reference: "REF11", From: 5, To: 1}
+    if len(change.Moves) != 1 || change.Moves[0] != want { t.Fatalf("unexpected moves %+v", change.Moves) }
+    if len(change.Overbooked) != 1 || change.Overbooked[0].ScheduleID != 8 { t.Fatalf("unexpected overbooked %+v", change.Overbooked) }
+    if err := mock.ExpectationsWereMet(); err != nil { t.Fatalf("expectations: %v", err) }
 }
@@ -10,6 +10,8 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+const airportColumns = `SELECT id, code, city, time_zone, min_connection_minutes, latitude, longitude, created_at, archived_at FROM airports`
+
 type AirportRepository struct {
 	db *sqlx.DB
 }
@@ -19,9 +21,13 @@ func NewAirportRepository(db *sqlx.DB) *AirportRepository {
 }
 
 func (r *AirportRepository) Create(ctx context.Context, a *domain.Airport) error {
-	query := `INSERT INTO airports (code, city) VALUES ($1, $2) RETURNING id, created_at`
+	query := `INSERT INTO airports (code, city, time_zone, min_connection_minutes, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+	if a.TimeZone == "" {
+		a.TimeZone = domain.DefaultTimeZone
+	}
+	lat, lon := positionArgs(a.Position)
 	var createdAt time.Time
-	if err := r.db.QueryRowContext(ctx, query, a.Code, a.City).Scan(&a.ID, &createdAt); err != nil {
+	if err := r.db.QueryRowContext(ctx, query, a.Code, a.City, a.TimeZone, a.MinConnectionMinutes, lat, lon).Scan(&a.ID, &createdAt); err != nil {
 		if isUniqueViolation(err) {
 			return domain.ErrAirportExists
 		}
@@ -32,34 +38,29 @@ func (r *AirportRepository) Create(ctx context.Context, a *domain.Airport) error
 }
 
 func (r *AirportRepository) GetByCode(ctx context.Context, code string) (*domain.Airport, error) {
-	var out domain.Airport
-	row := r.db.QueryRowxContext(ctx, `SELECT id, code, city, created_at FROM airports WHERE code=$1`, code)
-	var createdAt time.Time
-	if err := row.Scan(&out.ID, &out.Code, &out.City, &createdAt); err != nil {
+	out, err := scanAirport(r.db.QueryRowxContext(ctx, airportColumns+` WHERE code=$1`, code))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrAirportNotFound
 		}
 		return nil, err
 	}
-	out.CreatedAt = createdAt.Format(time.RFC3339)
-	return &out, nil
+	return out, nil
 }
 
-func (r *AirportRepository) List(ctx context.Context, limit, offset int) ([]domain.Airport, error) {
-	rows, err := r.db.QueryxContext(ctx, `SELECT id, code, city, created_at FROM airports ORDER BY code LIMIT $1 OFFSET $2`, limit, offset)
+func (r *AirportRepository) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airport, error) {
+	rows, err := r.db.QueryxContext(ctx, airportColumns+activeOnly("", includeArchived)+` ORDER BY code LIMIT $1 OFFSET $2`, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 	var items []domain.Airport
 	for rows.Next() {
-		var a domain.Airport
-		var createdAt time.Time
-		if err := rows.Scan(&a.ID, &a.Code, &a.City, &createdAt); err != nil {
+		a, err := scanAirport(rows)
+		if err != nil {
 			return nil, err
 		}
-		a.CreatedAt = createdAt.Format(time.RFC3339)
-		items = append(items, a)
+		items = append(items, *a)
 	}
 	return items, rows.Err()
 }
@@ -76,8 +77,9 @@ func (r *AirportRepository) Update(ctx context.Context, code string, city string
 	return nil
 }
 
-func (r *AirportRepository) Delete(ctx context.Context, code string) error {
-	res, err := r.db.ExecContext(ctx, `DELETE FROM airports WHERE code=$1`, code)
+// SetTimeZone changes the IANA time zone used for the airport's local times.
+func (r *AirportRepository) SetTimeZone(ctx context.Context, code string, timeZone string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE airports SET time_zone=$2 WHERE code=$1`, code, timeZone)
 	if err != nil {
 		return err
 	}
@@ -87,3 +89,83 @@ func (r *AirportRepository) Delete(ctx context.Context, code string) error {
 	}
 	return nil
 }
+
+// SetMinConnection changes the airport's minimum connection time; 0 restores the global default.
+func (r *AirportRepository) SetMinConnection(ctx context.Context, code string, minutes int) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE airports SET min_connection_minutes=$2 WHERE code=$1`, code, minutes)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return domain.ErrAirportNotFound
+	}
+	return nil
+}
+
+// SetPosition records the airport's coordinates; nil clears them.
+func (r *AirportRepository) SetPosition(ctx context.Context, code string, position *domain.Coordinates) error {
+	lat, lon := positionArgs(position)
+	res, err := r.db.ExecContext(ctx, `UPDATE airports SET latitude=$2, longitude=$3 WHERE code=$1`, code, lat, lon)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return domain.ErrAirportNotFound
+	}
+	return nil
+}
+
+// SetArchived archives the airport, keeping when it first was, or restores it.
+func (r *AirportRepository) SetArchived(ctx context.Context, code string, archived bool) error {
+	return setArchived(ctx, r.db, "airports", code, archived, domain.ErrAirportNotFound)
+}
+
+// airportDelete removes an airport; routes restrict it, so they are deleted
+// first, taking their flights, series and fares with them.
+var airportDelete = deletePlan{
+	entity:     domain.EntityAirport,
+	notFound:   domain.ErrAirportNotFound,
+	dependents: `SELECT (SELECT COUNT(*) FROM routes r WHERE r.origin_code = a.code OR r.destination_code = a.code), (SELECT COUNT(*) FROM flight_schedules s JOIN routes r ON r.code = s.route_code WHERE r.origin_code = a.code OR r.destination_code = a.code), (SELECT COUNT(*) FROM schedule_series ss JOIN routes r ON r.code = ss.route_code WHERE r.origin_code = a.code OR r.destination_code = a.code), (SELECT COUNT(*) FROM bookings b JOIN flight_schedules s ON s.id = b.schedule_id JOIN routes r ON r.code = s.route_code WHERE r.origin_code = a.code OR r.destination_code = a.code), (SELECT COUNT(*) FROM fares f JOIN routes r ON r.code = f.route_code WHERE r.origin_code = a.code OR r.destination_code = a.code), 0 FROM airports a WHERE a.code=$1 FOR UPDATE`,
+	statements: []string{
+		`DELETE FROM routes WHERE origin_code=$1 OR destination_code=$1`,
+		`DELETE FROM airports WHERE code=$1`,
+	},
+}
+
+func (r *AirportRepository) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	return airportDelete.exec(ctx, r.db, code, cascade)
+}
+
+func scanAirport(row interface{ Scan(...any) error }) (*domain.Airport, error) {
+	var (
+		a         domain.Airport
+		lat, lon  sql.NullFloat64
+		createdAt time.Time
+		archived  sql.NullTime
+	)
+	if err := row.Scan(&a.ID, &a.Code, &a.City, &a.TimeZone, &a.MinConnectionMinutes, &lat, &lon, &createdAt, &archived); err != nil {
+		return nil, err
+	}
+	a.Position = scanPosition(lat, lon)
+	a.CreatedAt = createdAt.Format(time.RFC3339)
+	a.ArchivedAt = archivedAt(archived)
+	return &a, nil
+}
+
+// positionArgs binds optional coordinates as nullable latitude and longitude.
+func positionArgs(p *domain.Coordinates) (sql.NullFloat64, sql.NullFloat64) {
+	if p == nil {
+		return sql.NullFloat64{}, sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: p.Latitude, Valid: true}, sql.NullFloat64{Float64: p.Longitude, Valid: true}
+}
+
+// scanPosition reads nullable latitude and longitude columns; nil when unset.
+func scanPosition(lat, lon sql.NullFloat64) *domain.Coordinates {
+	if !lat.Valid || !lon.Valid {
+		return nil
+	}
+	return &domain.Coordinates{Latitude: lat.Float64, Longitude: lon.Float64}
+}
@@ -24,8 +24,8 @@ func TestAirportRepo_Create_Success(t *testing.T) {
     defer cleanup()
     repo := NewAirportRepository(db)
     createdAt := time.Now()
-    mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO airports (code, city) VALUES ($1, $2) RETURNING id, created_at`)).
-        WithArgs("CGK", "Jakarta").
+    mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO airports (code, city, time_zone, min_connection_minutes, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`)).
+        WithArgs("CGK", "Jakarta", "UTC", 0, nil, nil).
         WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, createdAt))
 
     a := &domain.Airport{Code: "CGK", City: "Jakarta"}
@@ -37,8 +37,8 @@ func TestAirportRepo_Create_Duplicate(t *testing.T) {
     db, mock, cleanup := newMockDB(t)
     defer cleanup()
     repo := NewAirportRepository(db)
-    mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO airports (code, city) VALUES ($1, $2) RETURNING id, created_at`)).
-        WithArgs("CGK", "Jakarta").
+    mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO airports (code, city, time_zone, min_connection_minutes, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`)).
+        WithArgs("CGK", "Jakarta", "UTC", 0, nil, nil).
         WillReturnError(&pqError{msg: "duplicate key value violates unique constraint \"airports_code_key\""})
     a := &domain.Airport{Code: "CGK", City: "Jakarta"}
     if err := repo.Create(context.Background(), a); err != domain.ErrAirportExists {
@@ -50,9 +50,9 @@ func TestAirportRepo_GetByCode_NotFound(t *testing.T) {
     db, mock, cleanup := newMockDB(t)
     defer cleanup()
     repo := NewAirportRepository(db)
-    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, created_at FROM airports WHERE code=$1`)).
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, time_zone, min_connection_minutes, latitude, longitude, created_at, archived_at FROM airports WHERE code=$1`)).
         WithArgs("XXX").
-        WillReturnRows(sqlmock.NewRows([]string{"id", "code", "city", "created_at"}))
+        WillReturnRows(sqlmock.NewRows([]string{"id", "code", "city", "time_zone", "min_connection_minutes", "latitude", "longitude", "created_at", "archived_at"}))
     if _, err := repo.GetByCode(context.Background(), "XXX"); err != domain.ErrAirportNotFound {
         t.Fatalf("want not found, got %v", err)
     }
@@ -63,11 +63,11 @@ func TestAirportRepo_List_Update_Delete(t *testing.T) {
     defer cleanup()
     repo := NewAirportRepository(db)
     now := time.Now()
-    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, created_at FROM airports ORDER BY code LIMIT $1 OFFSET $2`)).
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, time_zone, min_connection_minutes, latitude, longitude, created_at, archived_at FROM airports WHERE archived_at IS NULL ORDER BY code LIMIT $1 OFFSET $2`)).
         WithArgs(2, 0).
-        WillReturnRows(sqlmock.NewRows([]string{"id","code","city","created_at"}).
-            AddRow(1,"CGK","Jakarta", now).AddRow(2,"DPS","Denpasar", now))
-    items, err := repo.List(context.Background(), 2, 0)
+        WillReturnRows(sqlmock.NewRows([]string{"id","code","city","time_zone","min_connection_minutes","latitude","longitude","created_at","archived_at"}).
+            AddRow(1,"CGK","Jakarta","Asia/Jakarta", 60, nil, nil, now, nil).AddRow(2,"DPS","Denpasar","Asia/Makassar", 0, nil, nil, now, nil))
+    items, err := repo.List(context.Background(), 2, 0, false)
     if err != nil || len(items) != 2 { t.Fatalf("list err=%v n=%d", err, len(items)) }
 
     mock.ExpectExec(regexp.QuoteMeta(`UPDATE airports SET city=$2 WHERE code=$1`)).
@@ -75,10 +75,9 @@ func TestAirportRepo_List_Update_Delete(t *testing.T) {
         WillReturnResult(sqlmock.NewResult(0, 1))
     if err := repo.Update(context.Background(), "CGK", "NewCity"); err != nil { t.Fatalf("update: %v", err) }
 
-    mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM airports WHERE code=$1`)).
-        WithArgs("CGK").
-        WillReturnResult(sqlmock.NewResult(0, 1))
-    if err := repo.Delete(context.Background(), "CGK"); err != nil { t.Fatalf("delete: %v", err) }
+    expectDependents(mock, airportDelete, "CGK", &domain.Dependents{})
+    expectDeleted(mock, airportDelete, "CGK")
+    if _, err := repo.Delete(context.Background(), "CGK", nil); err != nil { t.Fatalf("delete: %v", err) }
 }
 
 func TestAirportRepo_GetByCode_Success_UpdateDeleteNotFound(t *testing.T) {
@@ -86,11 +85,11 @@ func TestAirportRepo_GetByCode_Success_UpdateDeleteNotFound(t *testing.T) {
     defer cleanup()
     repo := NewAirportRepository(db)
     now := time.Now()
-    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, created_at FROM airports WHERE code=$1`)).
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, time_zone, min_connection_minutes, latitude, longitude, created_at, archived_at FROM airports WHERE code=$1`)).
         WithArgs("CGK").
-        WillReturnRows(sqlmock.NewRows([]string{"id","code","city","created_at"}).AddRow(1,"CGK","Jakarta", now))
+        WillReturnRows(sqlmock.NewRows([]string{"id","code","city","time_zone","min_connection_minutes","latitude","longitude","created_at","archived_at"}).AddRow(1,"CGK","Jakarta","Asia/Jakarta", 60, nil, nil, now, nil))
     a, err := repo.GetByCode(context.Background(), "CGK")
-    if err != nil || a.Code != "CGK" { t.Fatalf("get: %v a=%+v", err, a) }
+    if err != nil || a.Code != "CGK" || a.MinConnectionMinutes != 60 { t.Fatalf("get: %v a=%+v", err, a) }
 
     mock.ExpectExec(regexp.QuoteMeta(`UPDATE airports SET city=$2 WHERE code=$1`)).
         WithArgs("XXX", "City").
@@ -99,10 +98,9 @@ func TestAirportRepo_GetByCode_Success_UpdateDeleteNotFound(t *testing.T) {
         t.Fatalf("want not found on update, got %v", err)
     }
 
-    mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM airports WHERE code=$1`)).
-        WithArgs("XXX").
-        WillReturnResult(sqlmock.NewResult(0, 0))
-    if err := repo.Delete(context.Background(), "XXX"); err != domain.ErrAirportNotFound {
+    expectDependents(mock, airportDelete, "XXX", nil)
+    mock.ExpectRollback()
+    if _, err := repo.Delete(context.Background(), "XXX", nil); err != domain.ErrAirportNotFound {
         t.Fatalf("want not found on delete, got %v", err)
     }
 }
@@ -111,10 +109,10 @@ func TestAirportRepo_List_QueryError(t *testing.T) {
     db, mock, cleanup := newMockDB(t)
     defer cleanup()
     repo := NewAirportRepository(db)
-    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, created_at FROM airports ORDER BY code LIMIT $1 OFFSET $2`)).
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, time_zone, min_connection_minutes, latitude, longitude, created_at, archived_at FROM airports WHERE archived_at IS NULL ORDER BY code LIMIT $1 OFFSET $2`)).
         WithArgs(1, 0).
         WillReturnError(errors.New("db down"))
-    if _, err := repo.List(context.Background(), 1, 0); err == nil {
+    if _, err := repo.List(context.Background(), 1, 0, false); err == nil {
         t.Fatalf("expected error")
     }
 }
@@ -124,11 +122,11 @@ func TestAirportRepo_List_RowsError(t *testing.T) {
     defer cleanup()
     repo := NewAirportRepository(db)
     now := time.Now()
-    rows := sqlmock.NewRows([]string{"id","code","city","created_at"}).AddRow(1, "CGK", "Jakarta", now)
+    rows := sqlmock.NewRows([]string{"id","code","city","time_zone","min_connection_minutes","latitude","longitude","created_at","archived_at"}).AddRow(1, "CGK", "Jakarta", "Asia/Jakarta", 60, nil, nil, now, nil)
     rows.RowError(0, errors.New("row error"))
-    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, created_at FROM airports ORDER BY code LIMIT $1 OFFSET $2`)).
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, time_zone, min_connection_minutes, latitude, longitude, created_at, archived_at FROM airports WHERE archived_at IS NULL ORDER BY code LIMIT $1 OFFSET $2`)).
         WithArgs(10, 0).WillReturnRows(rows)
-    if _, err := repo.List(context.Background(), 10, 0); err == nil {
+    if _, err := repo.List(context.Background(), 10, 0, false); err == nil {
         t.Fatalf("expected rows error")
     }
 }
@@ -144,10 +142,12 @@ func TestAirportRepo_Update_Delete_Errors(t *testing.T) {
         t.Fatalf("expected update error")
     }
 
-    mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM airports WHERE code=$1`)).
+    mock.ExpectBegin()
+    mock.ExpectQuery(regexp.QuoteMeta(airportDelete.dependents)).
         WithArgs("CGK").
         WillReturnError(errors.New("delete failed"))
-    if err := repo.Delete(context.Background(), "CGK"); err == nil {
+    mock.ExpectRollback()
+    if _, err := repo.Delete(context.Background(), "CGK", nil); err == nil {
         t.Fatalf("expected delete error")
     }
 }
@@ -155,3 +155,61 @@ func TestAirportRepo_Update_Delete_Errors(t *testing.T) {
 // pqError is a minimal stub to simulate unique violation error messages.
 type pqError struct{ msg string }
 func (e *pqError) Error() string { return e.msg }
+
+func TestAirportRepo_SetTimeZone(t *testing.T) {
+    db, mock, cleanup := newMockDB(t)
+    defer cleanup()
+    repo := NewAirportRepository(db)
+    mock.ExpectExec(regexp.QuoteMeta(`UPDATE airports SET time_zone=$2 WHERE code=$1`)).
+        WithArgs("CGK", "Asia/Jakarta").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    if err := repo.SetTimeZone(context.Background(), "CGK", "Asia/Jakarta"); err != nil { t.Fatalf("set tz: %v", err) }
+
+    mock.ExpectExec(regexp.QuoteMeta(`UPDATE airports SET time_zone=$2 WHERE code=$1`)).
+        WithArgs("XXX", "UTC").
+        WillReturnResult(sqlmock.NewResult(0, 0))
+    if err := repo.SetTimeZone(context.Background(), "XXX", "UTC"); err != domain.ErrAirportNotFound {
+        t.Fatalf("want not found, got %v", err)
+    }
+}
+
+func TestAirportRepo_SetMinConnection(t *testing.T) {
+    db, mock, cleanup := newMockDB(t)
+    defer cleanup()
+    repo := NewAirportRepository(db)
+    mock.ExpectExec(regexp.QuoteMeta(`UPDATE airports SET min_connection_minutes=$2 WHERE code=$1`)).
+        WithArgs("CGK", 75).
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    if err := repo.SetMinConnection(context.Background(), "CGK", 75); err != nil { t.Fatalf("set mct: %v", err) }
+
+    mock.ExpectExec(regexp.QuoteMeta(`UPDATE airports SET min_connection_minutes=$2 WHERE code=$1`)).
+        WithArgs("XXX", 0).
+        WillReturnResult(sqlmock.NewResult(0, 0))
+    if err := repo.SetMinConnection(context.Background(), "XXX", 0); err != domain.ErrAirportNotFound {
+        t.Fatalf("want not found, got %v", err)
+    }
+}
+
+func TestAirportRepo_SetPosition(t *testing.T) {
+    db, mock, cleanup := newMockDB(t)
+    defer cleanup()
+    repo := NewAirportRepository(db)
+    mock.ExpectExec(regexp.QuoteMeta(`UPDATE airports SET latitude=$2, longitude=$3 WHERE code=$1`)).
+        WithArgs("CGK", -6.1256, 106.6559).
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    if err := repo.SetPosition(context.Background(), "CGK", &domain.Coordinates{Latitude: -6.1256, Longitude: 106.6559}); err != nil { t.Fatalf("set position: %v", err) }
+
+    mock.ExpectExec(regexp.QuoteMeta(`UPDATE airports SET latitude=$2, longitude=$3 WHERE code=$1`)).
+        WithArgs("XXX", nil, nil).
+        WillReturnResult(sqlmock.NewResult(0, 0))
+    if err := repo.SetPosition(context.Background(), "XXX", nil); err != domain.ErrAirportNotFound {
+        t.Fatalf("want not found, got %v", err)
+    }
+
+    now := time.Now()
+    mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, city, time_zone, min_connection_minutes, latitude, longitude, created_at, archived_at FROM airports WHERE code=$1`)).
+        WithArgs("CGK").
+        WillReturnRows(sqlmock.NewRows([]string{"id","code","city","time_zone","min_connection_minutes","latitude","longitude","created_at","archived_at"}).AddRow(1,"CGK","Jakarta","Asia/Jakarta", 60, -6.1256, 106.6559, now, nil))
+    a, err := repo.GetByCode(context.Background(), "CGK")
+    if err != nil || a.Position == nil || a.Position.Latitude != -6.1256 { t.Fatalf("get: %v %+v", err, a) }
+}
@@ -0,0 +1,44 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// setArchived stamps archived_at on the row of table with the given code, or
+// clears it to restore the row; archiving an archived row keeps its stamp.
+func setArchived(ctx context.Context, db *sqlx.DB, table, code string, archived bool, notFound error) error {
+	query := `UPDATE ` + table + ` SET archived_at=NULL WHERE code=$1`
+	if archived {
+		query = `UPDATE ` + table + ` SET archived_at=COALESCE(archived_at, now()) WHERE code=$1`
+	}
+	res, err := db.ExecContext(ctx, query, code)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// activeOnly filters a list query on alias's archived_at unless archived rows
+// are included.
+func activeOnly(alias string, includeArchived bool) string {
+	if includeArchived {
+		return ""
+	}
+	return ` WHERE ` + alias + `archived_at IS NULL`
+}
+
+// archivedAt formats a nullable archived_at column; empty when unset.
+func archivedAt(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339)
+}
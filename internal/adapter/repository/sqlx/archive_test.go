@@ -0,0 +1,67 @@
+package sqlxrepo
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func TestSetArchived(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE airports SET archived_at=COALESCE(archived_at, now()) WHERE code=$1`)).
+		WithArgs("DPS").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := NewAirportRepository(db).SetArchived(ctx, "DPS", true); err != nil {
+		t.Fatalf("archive airport: %v", err)
+	}
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE airplanes SET archived_at=NULL WHERE code=$1`)).
+		WithArgs("A320").WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := NewAirplaneRepository(db).SetArchived(ctx, "A320", false); err != nil {
+		t.Fatalf("restore airplane: %v", err)
+	}
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE routes SET archived_at=COALESCE(archived_at, now()) WHERE code=$1`)).
+		WithArgs("NONE").WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := NewRouteRepository(db).SetArchived(ctx, "NONE", true); !errors.Is(err, domain.ErrRouteNotFound) {
+		t.Fatalf("want route not found, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
+
+func TestList_IncludeArchived(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(airportColumns+` ORDER BY code LIMIT $1 OFFSET $2`)).
+		WithArgs(10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "city", "time_zone", "min_connection_minutes", "latitude", "longitude", "created_at", "archived_at"}).
+			AddRow(1, "CGK", "Jakarta", "Asia/Jakarta", 0, nil, nil, now, nil).
+			AddRow(2, "HLP", "Jakarta", "Asia/Jakarta", 0, nil, nil, now, now))
+	airports, err := NewAirportRepository(db).List(context.Background(), 10, 0, true)
+	if err != nil || len(airports) != 2 {
+		t.Fatalf("list airports: %v %+v", err, airports)
+	}
+	if airports[0].Archived() || !airports[1].Archived() || airports[1].ArchivedAt != now.Format(time.RFC3339) {
+		t.Fatalf("unexpected archive state: %+v", airports)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(routeColumns+` ORDER BY r.code LIMIT $1 OFFSET $2`)).
+		WithArgs(10, 0).
+		WillReturnRows(sqlmock.NewRows(routeRowColumns).AddRow(1, "RT1", "CGK", "DPS", 0, now, now, nil, nil, nil, nil))
+	routes, err := NewRouteRepository(db).List(context.Background(), 10, 0, true)
+	if err != nil || len(routes) != 1 || !routes[0].Archived() {
+		t.Fatalf("list routes: %v %+v", err, routes)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet: %v", err)
+	}
+}
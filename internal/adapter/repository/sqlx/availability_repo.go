@@ -0,0 +1,104 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// availabilitySelect joins schedules with their route, airports, seat inventory,
+// aircraft type and the cheapest fare applying on the departure date.
+const availabilitySelect = `SELECT s.id, s.route_code, s.airplane_code, s.departure_date, s.departure_at, s.arrival_at, o.time_zone, d.time_zone, s.created_at, r.origin_code, r.destination_code, i.capacity, i.sold + i.held + i.blocked AS booked, COALESCE(t.manufacturer || ' ' || t.model, ''), COALESCE(t.icao_code, ''), COALESCE(t.iata_code, ''), fa.id, fa.currency, fa.one_way_amount, fa.round_trip_amount FROM flight_schedules s JOIN routes r ON r.code = s.route_code JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code JOIN seat_inventory i ON i.schedule_id = s.id LEFT JOIN airplanes p ON p.code = s.airplane_code LEFT JOIN aircraft_types t ON t.icao_code = p.type_code LEFT JOIN LATERAL (SELECT f.id, f.currency, f.one_way_amount, f.round_trip_amount FROM fares f WHERE f.route_code = s.route_code AND (f.valid_from IS NULL OR f.valid_from <= s.departure_date) AND (f.valid_to IS NULL OR f.valid_to >= s.departure_date) ORDER BY f.one_way_amount, f.id LIMIT 1) fa ON true`
+
+// AvailabilityRepository answers flight search queries in a single statement.
+type AvailabilityRepository struct {
+	db *sqlx.DB
+}
+
+func NewAvailabilityRepository(db *sqlx.DB) *AvailabilityRepository {
+	return &AvailabilityRepository{db: db}
+}
+
+func (r *AvailabilityRepository) Search(ctx context.Context, q domain.AvailabilityQuery) ([]domain.FlightAvailability, error) {
+	// Cancelled flights stay on file for their bookings but are not for sale.
+	conds := []string{"s.status<>'CANCELLED'"}
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if q.OriginCode != "" {
+		add("r.origin_code=$%d", q.OriginCode)
+	}
+	if q.DestinationCode != "" {
+		add("r.destination_code=$%d", q.DestinationCode)
+	}
+	for _, d := range []struct{ cond, value string }{
+		{"s.departure_date=$%d", q.DepartureDate},
+		{"s.departure_date>=$%d", q.DateFrom},
+		{"s.departure_date<=$%d", q.DateTo},
+	} {
+		if d.value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", d.value)
+		if err != nil {
+			return nil, domain.ErrInvalidScheduleDate
+		}
+		add(d.cond, date)
+	}
+	if !q.DepartsAfter.IsZero() {
+		add("s.departure_at>=$%d", q.DepartsAfter.UTC())
+	}
+	if !q.DepartsBefore.IsZero() {
+		add("s.departure_at<=$%d", q.DepartsBefore.UTC())
+	}
+	query := availabilitySelect + " WHERE " + strings.Join(conds, " AND ") + " ORDER BY s.departure_at, s.id"
+
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var items []domain.FlightAvailability
+	for rows.Next() {
+		var (
+			a                                 domain.FlightAvailability
+			departure, departureAt, createdAt time.Time
+			arrivalAt                         sql.NullTime
+			fareID, oneWay, roundTrip         sql.NullInt64
+			currency                          sql.NullString
+		)
+		s := &a.Schedule
+		if err := rows.Scan(&s.ID, &s.RouteCode, &s.AirplaneCode, &departure, &departureAt, &arrivalAt, &s.OriginTimeZone, &s.DestinationTimeZone, &createdAt, &a.OriginCode, &a.DestinationCode, &a.SeatCapacity, &a.Booked, &a.AircraftType, &a.AircraftTypeCode, &a.AircraftTypeIATA, &fareID, &currency, &oneWay, &roundTrip); err != nil {
+			return nil, err
+		}
+		s.DepartureDate = departure.Format("2006-01-02")
+		s.DepartureAt = departureAt.UTC()
+		if arrivalAt.Valid {
+			s.ArrivalAt = arrivalAt.Time.UTC()
+		}
+		s.CreatedAt = createdAt.Format(time.RFC3339)
+		if fareID.Valid {
+			a.Fare = &domain.Fare{ID: fareID.Int64, RouteCode: s.RouteCode, OneWay: domain.Money{Amount: oneWay.Int64, Currency: currency.String}}
+			if roundTrip.Valid {
+				a.Fare.RoundTrip = domain.Money{Amount: roundTrip.Int64, Currency: currency.String}
+			}
+		}
+		items = append(items, a)
+	}
+	return items, rows.Err()
+}
+
+func (r *AvailabilityRepository) RouteExists(ctx context.Context, originCode, destinationCode string) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM routes WHERE origin_code=$1 AND destination_code=$2)`, originCode, destinationCode).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
@@ -0,0 +1,76 @@
+package sqlxrepo
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+var availabilityRowColumns = []string{"id", "route_code", "airplane_code", "departure_date", "departure_at", "arrival_at", "origin_tz", "destination_tz", "created_at", "origin_code", "destination_code", "seat_capacity", "booked", "aircraft_type", "aircraft_type_code", "aircraft_type_iata", "fare_id", "currency", "one_way_amount", "round_trip_amount"}
+
+func TestAvailabilityRepository_Search(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewAvailabilityRepository(db)
+	dep := time.Date(2025, 1, 2, 1, 30, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(availabilitySelect+` WHERE s.status<>'CANCELLED' AND r.origin_code=$1 AND r.destination_code=$2 AND s.departure_date=$3 ORDER BY s.departure_at, s.id`)).
+		WithArgs("CGK", "DPS", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(availabilityRowColumns).
+			AddRow(1, "RT1", "A320", dep, dep, dep.Add(2*time.Hour), "Asia/Jakarta", "Asia/Makassar", dep, "CGK", "DPS", 180, 179, "Airbus A320-200", "A320", "320", 3, "IDR", 85000000, nil).
+			AddRow(2, "RT1", "B737", dep, dep.Add(time.Hour), nil, "Asia/Jakarta", "Asia/Makassar", dep, "CGK", "DPS", 150, 150, "", "", "", nil, nil, nil, nil))
+	items, err := repo.Search(context.Background(), domain.AvailabilityQuery{OriginCode: "CGK", DestinationCode: "DPS", DepartureDate: "2025-01-02"})
+	if err != nil || len(items) != 2 {
+		t.Fatalf("search err=%v len=%d", err, len(items))
+	}
+	if items[0].SeatsAvailable() != 1 || items[0].Schedule.Duration() != 2*time.Hour || items[0].Schedule.LocalDeparture().Format("15:04") != "08:30" || items[0].AircraftType != "Airbus A320-200" {
+		t.Fatalf("unexpected first row: %+v", items[0])
+	}
+	if items[0].Fare == nil || items[0].Fare.OneWay.String() != "850000.00 IDR" || !items[0].Fare.RoundTrip.IsZero() {
+		t.Fatalf("unexpected fare: %+v", items[0].Fare)
+	}
+	if items[1].SeatsAvailable() != 0 || !items[1].Schedule.ArrivalAt.IsZero() || items[1].Fare != nil {
+		t.Fatalf("unexpected second row: %+v", items[1])
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(availabilitySelect+` WHERE s.status<>'CANCELLED' AND r.destination_code=$1 AND s.departure_at>=$2 AND s.departure_at<=$3 ORDER BY s.departure_at, s.id`)).
+		WithArgs("DPS", dep, dep.Add(time.Hour)).
+		WillReturnRows(sqlmock.NewRows(availabilityRowColumns))
+	if _, err := repo.Search(context.Background(), domain.AvailabilityQuery{DestinationCode: "DPS", DepartsAfter: dep, DepartsBefore: dep.Add(time.Hour)}); err != nil {
+		t.Fatalf("window search: %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(availabilitySelect+` WHERE s.status<>'CANCELLED' AND r.origin_code=$1 AND s.departure_date>=$2 AND s.departure_date<=$3 ORDER BY s.departure_at, s.id`)).
+		WithArgs("CGK", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)).
+		WillReturnRows(sqlmock.NewRows(availabilityRowColumns))
+	if _, err := repo.Search(context.Background(), domain.AvailabilityQuery{OriginCode: "CGK", DateFrom: "2025-03-01", DateTo: "2025-03-31"}); err != nil {
+		t.Fatalf("range search: %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(availabilitySelect + ` ORDER BY s.departure_at, s.id`)).
+		WillReturnError(errors.New("db down"))
+	if _, err := repo.Search(context.Background(), domain.AvailabilityQuery{}); err == nil {
+		t.Fatalf("expected query error")
+	}
+	if _, err := repo.Search(context.Background(), domain.AvailabilityQuery{DepartureDate: "bad"}); err != domain.ErrInvalidScheduleDate {
+		t.Fatalf("want invalid date, got %v", err)
+	}
+}
+
+func TestAvailabilityRepository_RouteExists(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewAvailabilityRepository(db)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM routes WHERE origin_code=$1 AND destination_code=$2)`)).
+		WithArgs("CGK", "DPS").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	ok, err := repo.RouteExists(context.Background(), "CGK", "DPS")
+	if err != nil || !ok {
+		t.Fatalf("route exists: ok=%v err=%v", ok, err)
+	}
+}
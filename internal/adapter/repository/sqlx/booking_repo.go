@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
@@ -19,32 +20,137 @@ func NewBookingRepository(db *sqlx.DB) *BookingRepository {
 	return &BookingRepository{db: db}
 }
 
+// Create locks the schedule's inventory row, claims a seat and inserts the booking
+// in one transaction, so concurrent bookings can never oversell the flight.
 func (r *BookingRepository) Create(ctx context.Context, b *domain.Booking) error {
-	query := `INSERT INTO bookings (reference, schedule_id, passenger_name, seat_number, status) VALUES ($1,$2,$3,$4,$5) RETURNING id, created_at`
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	seat, createdAt, err := insertBooking(ctx, tx, b)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	b.SeatNumber = seat
+	b.CreatedAt = createdAt.Format(time.RFC3339)
+	return nil
+}
+
+// insertBooking claims a seat on the booking's schedule under the inventory row
+// lock and inserts the booking, returning the seat it got. b.ID is set; the seat
+// and timestamp are left to the caller so nothing changes until commit.
+func insertBooking(ctx context.Context, tx *sqlx.Tx, b *domain.Booking) (int, time.Time, error) {
+	// The schedule row is locked too, so a cancellation either flags the
+	// booking or is seen here.
+	var inv domain.SeatInventory
+	var status string
+	if err := tx.QueryRowContext(ctx, `SELECT i.capacity, i.sold, i.held, i.blocked, s.status FROM seat_inventory i JOIN flight_schedules s ON s.id = i.schedule_id WHERE i.schedule_id=$1 FOR UPDATE`, b.ScheduleID).Scan(&inv.Capacity, &inv.Sold, &inv.Held, &inv.Blocked, &status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, time.Time{}, domain.ErrScheduleNotFound
+		}
+		return 0, time.Time{}, err
+	}
+	if status == domain.ScheduleStatusCancelled {
+		return 0, time.Time{}, domain.ErrScheduleCancelled
+	}
+	if inv.Available() <= 0 {
+		return 0, time.Time{}, domain.ErrFlightFull
+	}
+	// Keep the requested seat when it is free, otherwise take the lowest free one.
+	var seat int
+	if err := tx.QueryRowContext(ctx, `SELECT n FROM generate_series(1, $2::int) AS n WHERE NOT EXISTS (SELECT 1 FROM bookings WHERE schedule_id=$1 AND seat_number=n AND status<>'CANCELLED') ORDER BY n=$3 DESC, n LIMIT 1`, b.ScheduleID, inv.Capacity, b.SeatNumber).Scan(&seat); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, time.Time{}, domain.ErrFlightFull
+		}
+		return 0, time.Time{}, err
+	}
+
+	var tripID sql.NullInt64
+	if b.TripID > 0 {
+		tripID = sql.NullInt64{Int64: b.TripID, Valid: true}
+	}
+	query := `INSERT INTO bookings (reference, schedule_id, passenger_name, seat_number, status, trip_id) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id, created_at`
 	var createdAt time.Time
-	if err := r.db.QueryRowContext(ctx, query, b.Reference, b.ScheduleID, b.PassengerName, b.SeatNumber, b.Status).Scan(&b.ID, &createdAt); err != nil {
+	if err := tx.QueryRowContext(ctx, query, b.Reference, b.ScheduleID, b.PassengerName, seat, b.Status, tripID).Scan(&b.ID, &createdAt); err != nil {
 		if isUniqueViolation(err) {
-			return domain.ErrBookingExists
+			if strings.Contains(err.Error(), "bookings_schedule_seat") {
+				return 0, time.Time{}, domain.ErrSeatTaken
+			}
+			return 0, time.Time{}, domain.ErrBookingExists
 		}
 		if isForeignKeyViolation(err) {
-			return domain.ErrScheduleNotFound
+			return 0, time.Time{}, domain.ErrScheduleNotFound
 		}
+		return 0, time.Time{}, err
+	}
+	sold, held := domain.InventoryDelta("", b.Status)
+	if err := adjustInventory(ctx, tx, b.ScheduleID, sold, held); err != nil {
+		return 0, time.Time{}, err
+	}
+	return seat, createdAt, nil
+}
+
+// UpdateStatus changes a booking's status only if it still has the expected one
+// and moves its seat between the sold and held counts in the same transaction.
+func (r *BookingRepository) UpdateStatus(ctx context.Context, id int64, from, to string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
 		return err
 	}
-	b.CreatedAt = createdAt.Format(time.RFC3339)
+	defer func() { _ = tx.Rollback() }()
+
+	var scheduleID int64
+	if err := tx.QueryRowContext(ctx, `UPDATE bookings SET status=$3 WHERE id=$1 AND status=$2 RETURNING schedule_id`, id, from, to).Scan(&scheduleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidBookingTransition
+		}
+		return err
+	}
+	sold, held := domain.InventoryDelta(from, to)
+	if err := adjustInventory(ctx, tx, scheduleID, sold, held); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func adjustInventory(ctx context.Context, tx *sqlx.Tx, scheduleID int64, sold, held int) error {
+	res, err := tx.ExecContext(ctx, `UPDATE seat_inventory SET sold=sold+$2, held=held+$3, updated_at=now() WHERE schedule_id=$1`, scheduleID, sold, held)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return domain.ErrInventoryNotFound
+	}
 	return nil
 }
 
+// CountBySchedule reads the seats sold, held or blocked from the inventory row.
 func (r *BookingRepository) CountBySchedule(ctx context.Context, scheduleID int64) (int, error) {
 	var count int
-	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM bookings WHERE schedule_id=$1`, scheduleID).Scan(&count); err != nil {
+	if err := r.db.QueryRowContext(ctx, `SELECT COALESCE((SELECT sold + held + blocked FROM seat_inventory WHERE schedule_id=$1), 0)`, scheduleID).Scan(&count); err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
 func (r *BookingRepository) ListBySchedule(ctx context.Context, scheduleID int64, limit, offset int) ([]domain.Booking, error) {
-	rows, err := r.db.QueryxContext(ctx, `SELECT id, reference, schedule_id, passenger_name, seat_number, status, created_at FROM bookings WHERE schedule_id=$1 ORDER BY seat_number LIMIT $2 OFFSET $3`, scheduleID, limit, offset)
+	return r.list(ctx, `SELECT id, reference, schedule_id, passenger_name, seat_number, status, created_at FROM bookings WHERE schedule_id=$1 ORDER BY seat_number LIMIT $2 OFFSET $3`, scheduleID, limit, offset)
+}
+
+// ListAffected reads the bookings a cancellation flagged that agents have not
+// cancelled yet.
+func (r *BookingRepository) ListAffected(ctx context.Context, limit, offset int) ([]domain.Booking, error) {
+	return r.list(ctx, `SELECT id, reference, schedule_id, passenger_name, seat_number, status, created_at FROM bookings WHERE affected_at IS NOT NULL AND status<>'CANCELLED' ORDER BY schedule_id, seat_number LIMIT $1 OFFSET $2`, limit, offset)
+}
+
+func (r *BookingRepository) list(ctx context.Context, query string, args ...any) ([]domain.Booking, error) {
+	rows, err := r.db.QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -12,6 +12,12 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+const (
+	seatQuery          = `SELECT n FROM generate_series(1, $2::int) AS n WHERE NOT EXISTS (SELECT 1 FROM bookings WHERE schedule_id=$1 AND seat_number=n AND status<>'CANCELLED') ORDER BY n=$3 DESC, n LIMIT 1`
+	insertBookingQuery = `INSERT INTO bookings (reference, schedule_id, passenger_name, seat_number, status, trip_id) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id, created_at`
+	countQuery         = `SELECT COALESCE((SELECT sold + held + blocked FROM seat_inventory WHERE schedule_id=$1), 0)`
+)
+
 func newMockBookingDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock, func()) {
 	t.Helper()
 	db, mock, err := sqlmock.New()
@@ -27,19 +33,43 @@ func TestBookingRepository_Create_List_Get(t *testing.T) {
 	repo := NewBookingRepository(db)
 	now := time.Now()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO bookings (reference, schedule_id, passenger_name, seat_number, status) VALUES ($1,$2,$3,$4,$5) RETURNING id, created_at`)).
-		WithArgs("BK-AAAAAA", int64(1), "Alice", 1, domain.BookingStatusConfirmed).
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT i.capacity, i.sold, i.held, i.blocked, s.status FROM seat_inventory i JOIN flight_schedules s ON s.id = i.schedule_id WHERE i.schedule_id=$1 FOR UPDATE`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity", "sold", "held", "blocked", "status"}).AddRow(10, 3, 1, 0, "SCHEDULED"))
+	mock.ExpectQuery(regexp.QuoteMeta(seatQuery)).
+		WithArgs(int64(1), 10, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta(insertBookingQuery)).
+		WithArgs("BK-AAAAAA", int64(1), "Alice", 2, domain.BookingStatusConfirmed, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, now))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE seat_inventory SET sold=sold+$2, held=held+$3, updated_at=now() WHERE schedule_id=$1`)).
+		WithArgs(int64(1), 1, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
+	// Seat 1 is taken, so the lowest free seat is assigned instead.
 	booking := &domain.Booking{Reference: "BK-AAAAAA", ScheduleID: 1, PassengerName: "Alice", SeatNumber: 1, Status: domain.BookingStatusConfirmed}
 	if err := repo.Create(context.Background(), booking); err != nil {
 		t.Fatalf("create: %v", err)
 	}
-	if booking.ID != 1 {
-		t.Fatalf("expected id 1, got %d", booking.ID)
+	if booking.ID != 1 || booking.SeatNumber != 2 {
+		t.Fatalf("expected id 1 seat 2, got %d seat %d", booking.ID, booking.SeatNumber)
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM bookings WHERE schedule_id=$1`)).
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE bookings SET status=$3 WHERE id=$1 AND status=$2 RETURNING schedule_id`)).
+		WithArgs(int64(1), domain.BookingStatusConfirmed, domain.BookingStatusCancelled).
+		WillReturnRows(sqlmock.NewRows([]string{"schedule_id"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE seat_inventory SET sold=sold+$2, held=held+$3, updated_at=now() WHERE schedule_id=$1`)).
+		WithArgs(int64(1), -1, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := repo.UpdateStatus(context.Background(), 1, domain.BookingStatusConfirmed, domain.BookingStatusCancelled); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(countQuery)).
 		WithArgs(int64(1)).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 	count, err := repo.CountBySchedule(context.Background(), 1)
@@ -56,6 +86,15 @@ func TestBookingRepository_Create_List_Get(t *testing.T) {
 		t.Fatalf("list: err=%v len=%d", err, len(list))
 	}
 
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, reference, schedule_id, passenger_name, seat_number, status, created_at FROM bookings WHERE affected_at IS NOT NULL AND status<>'CANCELLED' ORDER BY schedule_id, seat_number LIMIT $1 OFFSET $2`)).
+		WithArgs(50, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reference", "schedule_id", "passenger_name", "seat_number", "status", "created_at"}).
+			AddRow(1, "BK-AAAAAA", 1, "Alice", 1, domain.BookingStatusConfirmed, now))
+	affected, err := repo.ListAffected(context.Background(), 50, 0)
+	if err != nil || len(affected) != 1 || affected[0].Reference != "BK-AAAAAA" {
+		t.Fatalf("list affected: err=%v got=%+v", err, affected)
+	}
+
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, reference, schedule_id, passenger_name, seat_number, status, created_at FROM bookings WHERE reference=$1`)).
 		WithArgs("BK-AAAAAA").
 		WillReturnRows(sqlmock.NewRows([]string{"id", "reference", "schedule_id", "passenger_name", "seat_number", "status", "created_at"}).
@@ -71,14 +110,73 @@ func TestBookingRepository_Errors(t *testing.T) {
 	defer cleanup()
 	repo := NewBookingRepository(db)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO bookings (reference, schedule_id, passenger_name, seat_number, status) VALUES ($1,$2,$3,$4,$5) RETURNING id, created_at`)).
-		WithArgs("BK-AAAAAA", int64(1), "Alice", 1, domain.BookingStatusConfirmed).
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT i.capacity, i.sold, i.held, i.blocked, s.status FROM seat_inventory i JOIN flight_schedules s ON s.id = i.schedule_id WHERE i.schedule_id=$1 FOR UPDATE`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity", "sold", "held", "blocked", "status"}).AddRow(10, 0, 0, 0, "SCHEDULED"))
+	mock.ExpectQuery(regexp.QuoteMeta(seatQuery)).
+		WithArgs(int64(1), 10, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(insertBookingQuery)).
+		WithArgs("BK-AAAAAA", int64(1), "Alice", 1, domain.BookingStatusConfirmed, nil).
 		WillReturnError(&pqErr{msg: "duplicate key value violates unique constraint"})
+	mock.ExpectRollback()
 	if err := repo.Create(context.Background(), &domain.Booking{Reference: "BK-AAAAAA", ScheduleID: 1, PassengerName: "Alice", SeatNumber: 1, Status: domain.BookingStatusConfirmed}); err != domain.ErrBookingExists {
 		t.Fatalf("want exists, got %v", err)
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM bookings WHERE schedule_id=$1`)).
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT i.capacity, i.sold, i.held, i.blocked, s.status FROM seat_inventory i JOIN flight_schedules s ON s.id = i.schedule_id WHERE i.schedule_id=$1 FOR UPDATE`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity", "sold", "held", "blocked", "status"}).AddRow(10, 0, 0, 0, "SCHEDULED"))
+	mock.ExpectQuery(regexp.QuoteMeta(seatQuery)).
+		WithArgs(int64(1), 10, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(insertBookingQuery)).
+		WithArgs("BK-BBBBBB", int64(1), "Bob", 1, domain.BookingStatusConfirmed, nil).
+		WillReturnError(&pqErr{msg: `duplicate key value violates unique constraint "bookings_schedule_seat_active_idx"`})
+	mock.ExpectRollback()
+	if err := repo.Create(context.Background(), &domain.Booking{Reference: "BK-BBBBBB", ScheduleID: 1, PassengerName: "Bob", SeatNumber: 1, Status: domain.BookingStatusConfirmed}); err != domain.ErrSeatTaken {
+		t.Fatalf("want seat taken, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT i.capacity, i.sold, i.held, i.blocked, s.status FROM seat_inventory i JOIN flight_schedules s ON s.id = i.schedule_id WHERE i.schedule_id=$1 FOR UPDATE`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity", "sold", "held", "blocked", "status"}).AddRow(10, 8, 1, 1, "SCHEDULED"))
+	mock.ExpectRollback()
+	if err := repo.Create(context.Background(), &domain.Booking{Reference: "BK-CCCCCC", ScheduleID: 1, PassengerName: "Carol", SeatNumber: 1, Status: domain.BookingStatusHeld}); err != domain.ErrFlightFull {
+		t.Fatalf("want flight full, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT i.capacity, i.sold, i.held, i.blocked, s.status FROM seat_inventory i JOIN flight_schedules s ON s.id = i.schedule_id WHERE i.schedule_id=$1 FOR UPDATE`)).
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity", "sold", "held", "blocked", "status"}))
+	mock.ExpectRollback()
+	if err := repo.Create(context.Background(), &domain.Booking{Reference: "BK-DDDDDD", ScheduleID: 9, PassengerName: "Dan", SeatNumber: 1, Status: domain.BookingStatusConfirmed}); err != domain.ErrScheduleNotFound {
+		t.Fatalf("want schedule not found, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT i.capacity, i.sold, i.held, i.blocked, s.status FROM seat_inventory i JOIN flight_schedules s ON s.id = i.schedule_id WHERE i.schedule_id=$1 FOR UPDATE`)).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity", "sold", "held", "blocked", "status"}).AddRow(10, 2, 0, 0, "CANCELLED"))
+	mock.ExpectRollback()
+	if err := repo.Create(context.Background(), &domain.Booking{Reference: "BK-EEEEEE", ScheduleID: 3, PassengerName: "Eve", SeatNumber: 1, Status: domain.BookingStatusConfirmed}); err != domain.ErrScheduleCancelled {
+		t.Fatalf("want schedule cancelled, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE bookings SET status=$3 WHERE id=$1 AND status=$2 RETURNING schedule_id`)).
+		WithArgs(int64(1), domain.BookingStatusHeld, domain.BookingStatusConfirmed).
+		WillReturnRows(sqlmock.NewRows([]string{"schedule_id"}))
+	mock.ExpectRollback()
+	if err := repo.UpdateStatus(context.Background(), 1, domain.BookingStatusHeld, domain.BookingStatusConfirmed); err != domain.ErrInvalidBookingTransition {
+		t.Fatalf("want invalid transition, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(countQuery)).
 		WithArgs(int64(2)).
 		WillReturnError(fmt.Errorf("db error"))
 	if _, err := repo.CountBySchedule(context.Background(), 2); err == nil {
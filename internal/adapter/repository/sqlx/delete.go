@@ -0,0 +1,58 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// deletePlan removes an entity, and with cascade what depends on it, in one
+// transaction.
+type deletePlan struct {
+	entity   string // one of the domain.Entity constants
+	notFound error
+	// dependents locks the entity's row and selects its dependent routes,
+	// schedules, series, bookings, fares and maintenance windows.
+	dependents string
+	// statements run in order with the entity's key as $1; the last one
+	// deletes the entity.
+	statements []string
+}
+
+// exec counts the entity's dependents and deletes it when there are none or
+// cascade holds the same counts; otherwise it fails with a *domain.InUseError,
+// or domain.ErrDependentsChanged when the dependents changed since cascade
+// was counted.
+func (p deletePlan) exec(ctx context.Context, db *sqlx.DB, key any, cascade *domain.Dependents) (domain.Dependents, error) {
+	var d domain.Dependents
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return d, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := tx.QueryRowxContext(ctx, p.dependents, key).Scan(&d.Routes, &d.Schedules, &d.Series, &d.Bookings, &d.Fares, &d.Maintenance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return d, p.notFound
+		}
+		return d, err
+	}
+	if cascade == nil && !d.Empty() {
+		return d, &domain.InUseError{Entity: p.entity, Key: fmt.Sprint(key), Dependents: d}
+	}
+	if cascade != nil && *cascade != d {
+		return d, fmt.Errorf("%s %v now has %s, not %s: %w", p.entity, key, d, *cascade, domain.ErrDependentsChanged)
+	}
+	for _, stmt := range p.statements {
+		if _, err := tx.ExecContext(ctx, stmt, key); err != nil {
+			return d, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return d, err
+	}
+	return d, nil
+}
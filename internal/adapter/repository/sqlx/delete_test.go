@@ -0,0 +1,117 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// expectDependents expects plan's transaction to begin and count the
+// dependents of key; a nil d finds no entity.
+func expectDependents(mock sqlmock.Sqlmock, plan deletePlan, key driver.Value, d *domain.Dependents) {
+	rows := sqlmock.NewRows([]string{"routes", "schedules", "series", "bookings", "fares", "maintenance"})
+	if d != nil {
+		rows.AddRow(d.Routes, d.Schedules, d.Series, d.Bookings, d.Fares, d.Maintenance)
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(plan.dependents)).WithArgs(key).WillReturnRows(rows)
+}
+
+// expectDeleted expects plan's statements to run for key and commit.
+func expectDeleted(mock sqlmock.Sqlmock, plan deletePlan, key driver.Value) {
+	for _, stmt := range plan.statements {
+		mock.ExpectExec(regexp.QuoteMeta(stmt)).WithArgs(key).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func TestDeletePlan_RefusesUnlessCascadeMatches(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewRouteRepository(db)
+	ctx := context.Background()
+	used := domain.Dependents{Schedules: 2, Series: 1, Bookings: 3, Fares: 1}
+
+	expectDependents(mock, routeDelete, "RT1", &used)
+	mock.ExpectRollback()
+	_, err := repo.Delete(ctx, "RT1", nil)
+	var inUse *domain.InUseError
+	if !errors.As(err, &inUse) || !errors.Is(err, domain.ErrRouteInUse) || inUse.Dependents != used {
+		t.Fatalf("want route in use, got %v", err)
+	}
+	if want := "route RT1 is in use: deleting it would remove 2 schedule(s), 1 series, 3 booking(s), 1 fare(s)"; err.Error() != want {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+
+	// A booking was made since the dependents were shown.
+	shown := domain.Dependents{Schedules: 2, Series: 1, Bookings: 2, Fares: 1}
+	expectDependents(mock, routeDelete, "RT1", &used)
+	mock.ExpectRollback()
+	if _, err := repo.Delete(ctx, "RT1", &shown); !errors.Is(err, domain.ErrDependentsChanged) {
+		t.Fatalf("want dependents changed, got %v", err)
+	}
+
+	expectDependents(mock, routeDelete, "RT1", &used)
+	expectDeleted(mock, routeDelete, "RT1")
+	removed, err := repo.Delete(ctx, "RT1", &used)
+	if err != nil || removed != used {
+		t.Fatalf("cascade: err=%v removed=%+v", err, removed)
+	}
+
+	expectDependents(mock, routeDelete, "RT1", &domain.Dependents{})
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM routes WHERE code=$1`)).WithArgs("RT1").WillReturnError(errors.New("exec fail"))
+	mock.ExpectRollback()
+	if _, err := repo.Delete(ctx, "RT1", nil); err == nil {
+		t.Fatalf("expected exec error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDeletePlan_CascadeStatements(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	expectDependents(mock, airplaneDelete, "A320", &domain.Dependents{Schedules: 1, Series: 1, Bookings: 2, Maintenance: 1})
+	mock.ExpectRollback()
+	if _, err := NewAirplaneRepository(db).Delete(ctx, "A320", nil); !errors.Is(err, domain.ErrAirplaneInUse) {
+		t.Fatalf("want airplane in use, got %v", err)
+	}
+	expectDependents(mock, airplaneDelete, "A320", &domain.Dependents{Schedules: 1})
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM flight_schedules WHERE airplane_code=$1`)).WithArgs("A320").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM schedule_series WHERE airplane_code=$1`)).WithArgs("A320").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM airplanes WHERE code=$1`)).WithArgs("A320").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if _, err := NewAirplaneRepository(db).Delete(ctx, "A320", &domain.Dependents{Schedules: 1}); err != nil {
+		t.Fatalf("airplane cascade: %v", err)
+	}
+
+	expectDependents(mock, airportDelete, "CGK", &domain.Dependents{Routes: 2, Schedules: 4})
+	mock.ExpectRollback()
+	if _, err := NewAirportRepository(db).Delete(ctx, "CGK", nil); !errors.Is(err, domain.ErrAirportInUse) {
+		t.Fatalf("want airport in use, got %v", err)
+	}
+	expectDependents(mock, airportDelete, "CGK", &domain.Dependents{Routes: 2, Schedules: 4})
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM routes WHERE origin_code=$1 OR destination_code=$1`)).WithArgs("CGK").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM airports WHERE code=$1`)).WithArgs("CGK").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if _, err := NewAirportRepository(db).Delete(ctx, "CGK", &domain.Dependents{Routes: 2, Schedules: 4}); err != nil {
+		t.Fatalf("airport cascade: %v", err)
+	}
+
+	expectDependents(mock, scheduleDelete, int64(5), &domain.Dependents{Bookings: 1})
+	mock.ExpectRollback()
+	if _, err := NewScheduleRepository(db).Delete(ctx, 5, nil); !errors.Is(err, domain.ErrScheduleInUse) || errors.Is(err, domain.ErrRouteInUse) {
+		t.Fatalf("want schedule in use, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
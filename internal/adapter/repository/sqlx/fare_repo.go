@@ -0,0 +1,108 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+const fareColumns = `SELECT id, route_code, currency, one_way_amount, round_trip_amount, valid_from, valid_to, created_at FROM fares`
+
+// FareRepository persists route fares using sqlx.
+type FareRepository struct {
+	db *sqlx.DB
+}
+
+func NewFareRepository(db *sqlx.DB) *FareRepository {
+	return &FareRepository{db: db}
+}
+
+func (r *FareRepository) Create(ctx context.Context, f *domain.Fare) error {
+	var roundTrip sql.NullInt64
+	if !f.RoundTrip.IsZero() {
+		roundTrip = sql.NullInt64{Int64: f.RoundTrip.Amount, Valid: true}
+	}
+	from, err := nullDate(f.ValidFrom)
+	if err != nil {
+		return err
+	}
+	to, err := nullDate(f.ValidTo)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO fares (route_code, currency, one_way_amount, round_trip_amount, valid_from, valid_to) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id, created_at`
+	var createdAt time.Time
+	if err := r.db.QueryRowContext(ctx, query, f.RouteCode, f.OneWay.Currency, f.OneWay.Amount, roundTrip, from, to).Scan(&f.ID, &createdAt); err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrRouteNotFound
+		}
+		return err
+	}
+	f.CreatedAt = createdAt.Format(time.RFC3339)
+	return nil
+}
+
+func (r *FareRepository) List(ctx context.Context, routeCode string, limit, offset int) ([]domain.Fare, error) {
+	query := fareColumns + ` ORDER BY route_code, one_way_amount, id LIMIT $1 OFFSET $2`
+	args := []any{limit, offset}
+	if routeCode != "" {
+		query = fareColumns + ` WHERE route_code=$1 ORDER BY one_way_amount, id LIMIT $2 OFFSET $3`
+		args = []any{routeCode, limit, offset}
+	}
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []domain.Fare
+	for rows.Next() {
+		var (
+			f         domain.Fare
+			roundTrip sql.NullInt64
+			from, to  sql.NullTime
+			createdAt time.Time
+		)
+		if err := rows.Scan(&f.ID, &f.RouteCode, &f.OneWay.Currency, &f.OneWay.Amount, &roundTrip, &from, &to, &createdAt); err != nil {
+			return nil, err
+		}
+		if roundTrip.Valid {
+			f.RoundTrip = domain.Money{Amount: roundTrip.Int64, Currency: f.OneWay.Currency}
+		}
+		if from.Valid {
+			f.ValidFrom = from.Time.Format("2006-01-02")
+		}
+		if to.Valid {
+			f.ValidTo = to.Time.Format("2006-01-02")
+		}
+		f.CreatedAt = createdAt.Format(time.RFC3339)
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+func (r *FareRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM fares WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return domain.ErrFareNotFound
+	}
+	return nil
+}
+
+// nullDate converts an optional YYYY-MM-DD string to a nullable DATE argument.
+func nullDate(date string) (sql.NullTime, error) {
+	if date == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return sql.NullTime{}, domain.ErrInvalidFareDates
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
@@ -0,0 +1,74 @@
+package sqlxrepo
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+var fareRowColumns = []string{"id", "route_code", "currency", "one_way_amount", "round_trip_amount", "valid_from", "valid_to", "created_at"}
+
+func TestFareRepository_Create_List_Delete(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewFareRepository(db)
+	now := time.Now()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO fares (route_code, currency, one_way_amount, round_trip_amount, valid_from, valid_to) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id, created_at`)).
+		WithArgs("RT1", "USD", int64(12000), int64(20000), from, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, now))
+	f := &domain.Fare{RouteCode: "RT1", OneWay: domain.Money{Amount: 12000, Currency: "USD"}, RoundTrip: domain.Money{Amount: 20000, Currency: "USD"}, ValidFrom: "2025-03-01"}
+	if err := repo.Create(context.Background(), f); err != nil || f.ID != 1 {
+		t.Fatalf("create: err=%v fare=%+v", err, f)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(fareColumns+` WHERE route_code=$1 ORDER BY one_way_amount, id LIMIT $2 OFFSET $3`)).
+		WithArgs("RT1", 50, 0).
+		WillReturnRows(sqlmock.NewRows(fareRowColumns).
+			AddRow(1, "RT1", "USD", 12000, 20000, from, nil, now).
+			AddRow(2, "RT1", "USD", 15000, nil, nil, nil, now))
+	fares, err := repo.List(context.Background(), "RT1", 50, 0)
+	if err != nil || len(fares) != 2 {
+		t.Fatalf("list: err=%v len=%d", err, len(fares))
+	}
+	if fares[0].RoundTrip.Amount != 20000 || fares[0].ValidFrom != "2025-03-01" || fares[0].ValidTo != "" || !fares[1].RoundTrip.IsZero() {
+		t.Fatalf("unexpected fares %+v", fares)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(fareColumns+` ORDER BY route_code, one_way_amount, id LIMIT $1 OFFSET $2`)).
+		WithArgs(50, 0).
+		WillReturnRows(sqlmock.NewRows(fareRowColumns))
+	if _, err := repo.List(context.Background(), "", 50, 0); err != nil {
+		t.Fatalf("list all: %v", err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM fares WHERE id=$1`)).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.Delete(context.Background(), 1); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
+
+func TestFareRepository_Errors(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewFareRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO fares`)).
+		WillReturnError(&pqErr{msg: `insert or update on table "fares" violates foreign key constraint`})
+	if err := repo.Create(context.Background(), &domain.Fare{RouteCode: "NONE", OneWay: domain.Money{Amount: 1, Currency: "USD"}}); err != domain.ErrRouteNotFound {
+		t.Fatalf("want route not found, got %v", err)
+	}
+	if err := repo.Create(context.Background(), &domain.Fare{RouteCode: "RT1", OneWay: domain.Money{Amount: 1, Currency: "USD"}, ValidTo: "bad"}); err != domain.ErrInvalidFareDates {
+		t.Fatalf("want invalid dates, got %v", err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM fares WHERE id=$1`)).WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := repo.Delete(context.Background(), 9); err != domain.ErrFareNotFound {
+		t.Fatalf("want fare not found, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package sqlxrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+const maintenanceColumns = `SELECT id, airplane_code, starts_at, ends_at, reason, created_at FROM airplane_maintenance`
+
+// maintenanceFlightsQuery selects an airplane's booked flights overlapping a
+// window, with scheduleColumns' columns followed by the confirmed and held
+// seat count. Flights without a planned arrival occupy their departure minute.
+const maintenanceFlightsQuery = `SELECT s.id, s.route_code, s.airplane_code, s.departure_date, s.departure_at, s.arrival_at, o.time_zone, d.time_zone, s.series_id, s.status, s.cancel_reason, s.delay_minutes, s.delay_reason, s.departure_gate, s.arrival_gate, s.created_at, i.sold + i.held FROM flight_schedules s JOIN routes r ON r.code = s.route_code JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code JOIN seat_inventory i ON i.schedule_id = s.id WHERE s.airplane_code=$1 AND s.status<>'CANCELLED' AND s.departure_at < $3 AND COALESCE(s.arrival_at, s.departure_at + INTERVAL '1 minute') > $2 AND i.sold + i.held > 0 ORDER BY s.departure_at, s.id`
+
+// MaintenanceRepository stores airplane maintenance windows using sqlx.
+type MaintenanceRepository struct {
+	db *sqlx.DB
+}
+
+func NewMaintenanceRepository(db *sqlx.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+func (r *MaintenanceRepository) Create(ctx context.Context, w *domain.MaintenanceWindow) error {
+	query := `INSERT INTO airplane_maintenance (airplane_code, starts_at, ends_at, reason) VALUES ($1,$2,$3,$4) RETURNING id, created_at`
+	var createdAt time.Time
+	if err := r.db.QueryRowContext(ctx, query, w.AirplaneCode, w.StartsAt, w.EndsAt, w.Reason).Scan(&w.ID, &createdAt); err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrAirplaneNotFound
+		}
+		return err
+	}
+	w.CreatedAt = createdAt.Format(time.RFC3339)
+	return nil
+}
+
+func (r *MaintenanceRepository) ListByAirplane(ctx context.Context, airplaneCode string, from time.Time) ([]domain.MaintenanceWindow, error) {
+	return r.list(ctx, maintenanceColumns+` WHERE airplane_code=$1 AND ends_at > $2 ORDER BY starts_at, id`, airplaneCode, from)
+}
+
+func (r *MaintenanceRepository) Overlapping(ctx context.Context, airplaneCode string, from, to time.Time) ([]domain.MaintenanceWindow, error) {
+	return r.list(ctx, maintenanceColumns+` WHERE airplane_code=$1 AND starts_at < $3 AND ends_at > $2 ORDER BY starts_at, id`, airplaneCode, from, to)
+}
+
+func (r *MaintenanceRepository) BookedFlights(ctx context.Context, w domain.MaintenanceWindow) ([]domain.MaintenanceConflict, error) {
+	rows, err := r.db.QueryxContext(ctx, maintenanceFlightsQuery, w.AirplaneCode, w.StartsAt, w.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []domain.MaintenanceConflict
+	for rows.Next() {
+		var booked int
+		sched, err := scanSchedule(scanFunc(func(dest ...any) error { return rows.Scan(append(dest, &booked)...) }))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, domain.MaintenanceConflict{Schedule: sched, Booked: booked})
+	}
+	return out, rows.Err()
+}
+
+func (r *MaintenanceRepository) list(ctx context.Context, query string, args ...any) ([]domain.MaintenanceWindow, error) {
+	rows, err := r.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []domain.MaintenanceWindow
+	for rows.Next() {
+		var (
+			w         domain.MaintenanceWindow
+			createdAt time.Time
+		)
+		if err := rows.Scan(&w.ID, &w.AirplaneCode, &w.StartsAt, &w.EndsAt, &w.Reason, &createdAt); err != nil {
+			return nil, err
+		}
+		w.StartsAt = w.StartsAt.UTC()
+		w.EndsAt = w.EndsAt.UTC()
+		w.CreatedAt = createdAt.Format(time.RFC3339)
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// scanFunc adapts a function to the Scan method scan helpers take, letting a
+// query select extra columns after the ones a helper reads.
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error { return f(dest...) }
@@ -0,0 +1,56 @@
+package sqlxrepo
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+var maintenanceRowColumns = []string{"id", "airplane_code", "starts_at", "ends_at", "reason", "created_at"}
+
+func TestMaintenanceRepository(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewMaintenanceRepository(db)
+	now := time.Now()
+	from := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 4, 8, 0, 0, 0, time.UTC)
+
+	insert := regexp.QuoteMeta(`INSERT INTO airplane_maintenance (airplane_code, starts_at, ends_at, reason) VALUES ($1,$2,$3,$4) RETURNING id, created_at`)
+	mock.ExpectQuery(insert).WithArgs("A320", from, to, "C-check").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, now))
+	w := &domain.MaintenanceWindow{AirplaneCode: "A320", StartsAt: from, EndsAt: to, Reason: "C-check"}
+	if err := repo.Create(context.Background(), w); err != nil || w.ID != 1 {
+		t.Fatalf("create: %v id=%d", err, w.ID)
+	}
+	mock.ExpectQuery(insert).WillReturnError(errors.New(`insert or update on table "airplane_maintenance" violates foreign key constraint`))
+	if err := repo.Create(context.Background(), &domain.MaintenanceWindow{AirplaneCode: "B777", StartsAt: from, EndsAt: to}); err != domain.ErrAirplaneNotFound {
+		t.Fatalf("want airplane not found, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(maintenanceColumns+` WHERE airplane_code=$1 AND ends_at > $2 ORDER BY starts_at, id`)).WithArgs("A320", from).
+		WillReturnRows(sqlmock.NewRows(maintenanceRowColumns).AddRow(1, "A320", from, to, "C-check", now))
+	if items, err := repo.ListByAirplane(context.Background(), "A320", from); err != nil || len(items) != 1 || items[0].Reason != "C-check" {
+		t.Fatalf("list: %+v err=%v", items, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(maintenanceColumns+` WHERE airplane_code=$1 AND starts_at < $3 AND ends_at > $2 ORDER BY starts_at, id`)).WithArgs("A320", from, to).
+		WillReturnRows(sqlmock.NewRows(maintenanceRowColumns).AddRow(1, "A320", from, to, "", now))
+	if items, err := repo.Overlapping(context.Background(), "A320", from, to); err != nil || len(items) != 1 || !items[0].EndsAt.Equal(to) {
+		t.Fatalf("overlapping: %+v err=%v", items, err)
+	}
+
+	departs := time.Date(2025, 1, 2, 23, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(maintenanceFlightsQuery)).WithArgs("A320", from, to).
+		WillReturnRows(sqlmock.NewRows(append(append([]string{}, scheduleRowColumns...), "booked")).
+			AddRow(7, "RT1", "A320", departs, departs, departs.Add(2*time.Hour), "Asia/Jakarta", "Asia/Makassar", nil, "SCHEDULED", "", 0, "", "", "", now, 3))
+	conflicts, err := repo.BookedFlights(context.Background(), *w)
+	if err != nil || len(conflicts) != 1 || conflicts[0].Booked != 3 || conflicts[0].Schedule.ID != 7 || conflicts[0].Schedule.OriginTimeZone != "Asia/Jakarta" {
+		t.Fatalf("booked flights: %+v err=%v", conflicts, err)
+	}
+}
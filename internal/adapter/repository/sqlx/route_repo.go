@@ -10,6 +10,19 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// routeColumns selects a route with the positions of its airports, from which
+// scanRoute computes the distance.
+const routeColumns = `SELECT r.id, r.code, r.origin_code, r.destination_code, r.block_minutes, r.created_at, r.archived_at, o.latitude, o.longitude, d.latitude, d.longitude FROM routes r JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code`
+
+// routeFlightsQuery selects a route's booked flights departing at or after $2,
+// with scheduleColumns' columns followed by the confirmed and held seat count,
+// locking their inventory.
+const routeFlightsQuery = `SELECT s.id, s.route_code, s.airplane_code, s.departure_date, s.departure_at, s.arrival_at, o.time_zone, d.time_zone, s.series_id, s.status, s.cancel_reason, s.delay_minutes, s.delay_reason, s.departure_gate, s.arrival_gate, s.created_at, i.sold + i.held FROM flight_schedules s JOIN routes r ON r.code = s.route_code JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code JOIN seat_inventory i ON i.schedule_id = s.id WHERE s.route_code=$1 AND s.departure_at >= $2 AND i.sold + i.held > 0 ORDER BY s.departure_at, s.id FOR UPDATE OF i`
+
+// redateFlightsQuery sets each flight's departure date to its local date at
+// the route's origin.
+const redateFlightsQuery = `UPDATE flight_schedules s SET departure_date = (s.departure_at AT TIME ZONE a.time_zone)::date FROM routes r JOIN airports a ON a.code = r.origin_code WHERE r.code=$1 AND s.route_code = r.code AND s.departure_date <> (s.departure_at AT TIME ZONE a.time_zone)::date`
+
 // RouteRepository persists routes using sqlx.
 type RouteRepository struct {
 	db *sqlx.DB
@@ -20,9 +33,9 @@ func NewRouteRepository(db *sqlx.DB) *RouteRepository {
 }
 
 func (r *RouteRepository) Create(ctx context.Context, route *domain.Route) error {
-	query := `INSERT INTO routes (code, origin_code, destination_code) VALUES ($1, $2, $3) RETURNING id, created_at`
+	query := `INSERT INTO routes (code, origin_code, destination_code, block_minutes) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
 	var createdAt time.Time
-	if err := r.db.QueryRowContext(ctx, query, route.Code, route.OriginCode, route.DestinationCode).Scan(&route.ID, &createdAt); err != nil {
+	if err := r.db.QueryRowContext(ctx, query, route.Code, route.OriginCode, route.DestinationCode, route.BlockMinutes).Scan(&route.ID, &createdAt); err != nil {
 		if isUniqueViolation(err) {
 			return domain.ErrRouteExists
 		}
@@ -33,40 +46,36 @@ func (r *RouteRepository) Create(ctx context.Context, route *domain.Route) error
 }
 
 func (r *RouteRepository) GetByCode(ctx context.Context, code string) (*domain.Route, error) {
-	var out domain.Route
-	row := r.db.QueryRowxContext(ctx, `SELECT id, code, origin_code, destination_code, created_at FROM routes WHERE code=$1`, code)
-	var createdAt time.Time
-	if err := row.Scan(&out.ID, &out.Code, &out.OriginCode, &out.DestinationCode, &createdAt); err != nil {
+	out, err := scanRoute(r.db.QueryRowxContext(ctx, routeColumns+` WHERE r.code=$1`, code))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrRouteNotFound
 		}
 		return nil, err
 	}
-	out.CreatedAt = createdAt.Format(time.RFC3339)
-	return &out, nil
+	return out, nil
 }
 
-func (r *RouteRepository) List(ctx context.Context, limit, offset int) ([]domain.Route, error) {
-	rows, err := r.db.QueryxContext(ctx, `SELECT id, code, origin_code, destination_code, created_at FROM routes ORDER BY code LIMIT $1 OFFSET $2`, limit, offset)
+func (r *RouteRepository) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Route, error) {
+	rows, err := r.db.QueryxContext(ctx, routeColumns+activeOnly("r.", includeArchived)+` ORDER BY r.code LIMIT $1 OFFSET $2`, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 	var out []domain.Route
 	for rows.Next() {
-		var route domain.Route
-		var createdAt time.Time
-		if err := rows.Scan(&route.ID, &route.Code, &route.OriginCode, &route.DestinationCode, &createdAt); err != nil {
+		route, err := scanRoute(rows)
+		if err != nil {
 			return nil, err
 		}
-		route.CreatedAt = createdAt.Format(time.RFC3339)
-		out = append(out, route)
+		out = append(out, *route)
 	}
 	return out, rows.Err()
 }
 
-func (r *RouteRepository) Delete(ctx context.Context, code string) error {
-	res, err := r.db.ExecContext(ctx, `DELETE FROM routes WHERE code=$1`, code)
+// SetBlockTime stores the route's scheduled block time; 0 clears it.
+func (r *RouteRepository) SetBlockTime(ctx context.Context, code string, minutes int) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE routes SET block_minutes=$2 WHERE code=$1`, code, minutes)
 	if err != nil {
 		return err
 	}
@@ -76,3 +85,88 @@ func (r *RouteRepository) Delete(ctx context.Context, code string) error {
 	}
 	return nil
 }
+
+// SetArchived archives the route, keeping when it first was, or restores it.
+func (r *RouteRepository) SetArchived(ctx context.Context, code string, archived bool) error {
+	return setArchived(ctx, r.db, "routes", code, archived, domain.ErrRouteNotFound)
+}
+
+// Update moves a route to other airports and re-dates its flights in the new
+// origin's time zone, in one transaction. When flights departing from from on
+// have bookings it rolls back with a *domain.RouteBookingsError, unless force
+// is set; the booked flights are returned either way.
+func (r *RouteRepository) Update(ctx context.Context, route *domain.Route, from time.Time, force bool) ([]domain.BookedFlight, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	res, err := tx.ExecContext(ctx, `UPDATE routes SET origin_code=$2, destination_code=$3 WHERE code=$1`, route.Code, route.OriginCode, route.DestinationCode)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, domain.ErrAirportNotFound
+		}
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, domain.ErrRouteNotFound
+	}
+	if _, err := tx.ExecContext(ctx, redateFlightsQuery, route.Code); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryxContext(ctx, routeFlightsQuery, route.Code, from.UTC())
+	if err != nil {
+		return nil, err
+	}
+	var booked []domain.BookedFlight
+	for rows.Next() {
+		var n int
+		sched, err := scanSchedule(scanFunc(func(dest ...any) error { return rows.Scan(append(dest, &n)...) }))
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		booked = append(booked, domain.BookedFlight{Schedule: sched, Booked: n})
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	_ = rows.Close()
+	if len(booked) > 0 && !force {
+		return nil, &domain.RouteBookingsError{RouteCode: route.Code, Flights: booked}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return booked, nil
+}
+
+// routeDelete removes a route; its flights, series and fares go with it.
+var routeDelete = deletePlan{
+	entity:     domain.EntityRoute,
+	notFound:   domain.ErrRouteNotFound,
+	dependents: `SELECT 0, (SELECT COUNT(*) FROM flight_schedules s WHERE s.route_code = r.code), (SELECT COUNT(*) FROM schedule_series ss WHERE ss.route_code = r.code), (SELECT COUNT(*) FROM bookings b JOIN flight_schedules s ON s.id = b.schedule_id WHERE s.route_code = r.code), (SELECT COUNT(*) FROM fares f WHERE f.route_code = r.code), 0 FROM routes r WHERE r.code=$1 FOR UPDATE`,
+	statements: []string{`DELETE FROM routes WHERE code=$1`},
+}
+
+func (r *RouteRepository) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	return routeDelete.exec(ctx, r.db, code, cascade)
+}
+
+func scanRoute(row interface{ Scan(...any) error }) (*domain.Route, error) {
+	var (
+		route                                  domain.Route
+		createdAt                              time.Time
+		archived                               sql.NullTime
+		originLat, originLon, destLat, destLon sql.NullFloat64
+	)
+	if err := row.Scan(&route.ID, &route.Code, &route.OriginCode, &route.DestinationCode, &route.BlockMinutes, &createdAt, &archived, &originLat, &originLon, &destLat, &destLon); err != nil {
+		return nil, err
+	}
+	route.DistanceKm = domain.GreatCircleKm(scanPosition(originLat, originLon), scanPosition(destLat, destLon))
+	route.CreatedAt = createdAt.Format(time.RFC3339)
+	route.ArchivedAt = archivedAt(archived)
+	return &route, nil
+}
@@ -11,14 +11,16 @@ import (
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
 )
 
+var routeRowColumns = []string{"id", "code", "origin_code", "destination_code", "block_minutes", "created_at", "archived_at", "o_latitude", "o_longitude", "d_latitude", "d_longitude"}
+
 func TestRouteRepository_Create_Get_List_Delete(t *testing.T) {
 	db, mock, cleanup := newMockDB(t)
 	defer cleanup()
 	repo := NewRouteRepository(db)
 	now := time.Now()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO routes (code, origin_code, destination_code) VALUES ($1, $2, $3) RETURNING id, created_at`)).
-		WithArgs("RT1", "CGK", "DPS").
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO routes (code, origin_code, destination_code, block_minutes) VALUES ($1, $2, $3, $4) RETURNING id, created_at`)).
+		WithArgs("RT1", "CGK", "DPS", 0).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, now))
 	r := &domain.Route{Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}
 	if err := repo.Create(context.Background(), r); err != nil {
@@ -28,26 +30,25 @@ func TestRouteRepository_Create_Get_List_Delete(t *testing.T) {
 		t.Fatalf("route fields not set: %+v", r)
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, origin_code, destination_code, created_at FROM routes WHERE code=$1`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.code, r.origin_code, r.destination_code, r.block_minutes, r.created_at, r.archived_at, o.latitude, o.longitude, d.latitude, d.longitude FROM routes r JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code WHERE r.code=$1`)).
 		WithArgs("RT1").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "origin_code", "destination_code", "created_at"}).AddRow(1, "RT1", "CGK", "DPS", now))
+		WillReturnRows(sqlmock.NewRows(routeRowColumns).AddRow(1, "RT1", "CGK", "DPS", 0, now, nil, nil, nil, nil, nil))
 	got, err := repo.GetByCode(context.Background(), "RT1")
 	if err != nil || got.Code != "RT1" {
 		t.Fatalf("get: err=%v route=%+v", err, got)
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, origin_code, destination_code, created_at FROM routes ORDER BY code LIMIT $1 OFFSET $2`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.code, r.origin_code, r.destination_code, r.block_minutes, r.created_at, r.archived_at, o.latitude, o.longitude, d.latitude, d.longitude FROM routes r JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code WHERE r.archived_at IS NULL ORDER BY r.code LIMIT $1 OFFSET $2`)).
 		WithArgs(10, 0).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "origin_code", "destination_code", "created_at"}).AddRow(1, "RT1", "CGK", "DPS", now))
-	list, err := repo.List(context.Background(), 10, 0)
+		WillReturnRows(sqlmock.NewRows(routeRowColumns).AddRow(1, "RT1", "CGK", "DPS", 0, now, nil, nil, nil, nil, nil))
+	list, err := repo.List(context.Background(), 10, 0, false)
 	if err != nil || len(list) != 1 {
 		t.Fatalf("list: err=%v len=%d", err, len(list))
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM routes WHERE code=$1`)).
-		WithArgs("RT1").
-		WillReturnResult(sqlmock.NewResult(0, 1))
-	if err := repo.Delete(context.Background(), "RT1"); err != nil {
+	expectDependents(mock, routeDelete, "RT1", &domain.Dependents{})
+	expectDeleted(mock, routeDelete, "RT1")
+	if _, err := repo.Delete(context.Background(), "RT1", nil); err != nil {
 		t.Fatalf("delete: %v", err)
 	}
 }
@@ -57,24 +58,23 @@ func TestRouteRepository_CreateDuplicate_GetNotFound_DeleteNotFound(t *testing.T
 	defer cleanup()
 	repo := NewRouteRepository(db)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO routes (code, origin_code, destination_code) VALUES ($1, $2, $3) RETURNING id, created_at`)).
-		WithArgs("RT1", "CGK", "DPS").
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO routes (code, origin_code, destination_code, block_minutes) VALUES ($1, $2, $3, $4) RETURNING id, created_at`)).
+		WithArgs("RT1", "CGK", "DPS", 0).
 		WillReturnError(&pqError{msg: "duplicate key value violates unique constraint"})
 	if err := repo.Create(context.Background(), &domain.Route{Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}); err != domain.ErrRouteExists {
 		t.Fatalf("want ErrRouteExists, got %v", err)
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, origin_code, destination_code, created_at FROM routes WHERE code=$1`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.code, r.origin_code, r.destination_code, r.block_minutes, r.created_at, r.archived_at, o.latitude, o.longitude, d.latitude, d.longitude FROM routes r JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code WHERE r.code=$1`)).
 		WithArgs("NONE").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "origin_code", "destination_code", "created_at"}))
+		WillReturnRows(sqlmock.NewRows(routeRowColumns))
 	if _, err := repo.GetByCode(context.Background(), "NONE"); err != domain.ErrRouteNotFound {
 		t.Fatalf("want ErrRouteNotFound, got %v", err)
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM routes WHERE code=$1`)).
-		WithArgs("NONE").
-		WillReturnResult(sqlmock.NewResult(0, 0))
-	if err := repo.Delete(context.Background(), "NONE"); err != domain.ErrRouteNotFound {
+	expectDependents(mock, routeDelete, "NONE", nil)
+	mock.ExpectRollback()
+	if _, err := repo.Delete(context.Background(), "NONE", nil); err != domain.ErrRouteNotFound {
 		t.Fatalf("want ErrRouteNotFound on delete, got %v", err)
 	}
 }
@@ -84,10 +84,91 @@ func TestRouteRepository_ListErrors(t *testing.T) {
 	defer cleanup()
 	repo := NewRouteRepository(db)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, code, origin_code, destination_code, created_at FROM routes ORDER BY code LIMIT $1 OFFSET $2`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT r.id, r.code, r.origin_code, r.destination_code, r.block_minutes, r.created_at, r.archived_at, o.latitude, o.longitude, d.latitude, d.longitude FROM routes r JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code WHERE r.archived_at IS NULL ORDER BY r.code LIMIT $1 OFFSET $2`)).
 		WithArgs(5, 0).
 		WillReturnError(errors.New("db down"))
-	if _, err := repo.List(context.Background(), 5, 0); err == nil {
+	if _, err := repo.List(context.Background(), 5, 0, false); err == nil {
 		t.Fatalf("expected list error")
 	}
 }
+
+func TestRouteRepository_DistanceAndBlockTime(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewRouteRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE r.code=$1`)).
+		WithArgs("CGK-DPS").
+		WillReturnRows(sqlmock.NewRows(routeRowColumns).AddRow(1, "CGK-DPS", "CGK", "DPS", 110, time.Now(), nil, -6.1256, 106.6559, -8.7482, 115.1672))
+	got, err := repo.GetByCode(context.Background(), "CGK-DPS")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.BlockMinutes != 110 || got.DistanceKm < 960 || got.DistanceKm > 1000 {
+		t.Fatalf("unexpected block time or distance: %+v", got)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE routes SET block_minutes=$2 WHERE code=$1`)).
+		WithArgs("CGK-DPS", 110).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.SetBlockTime(context.Background(), "CGK-DPS", 110); err != nil {
+		t.Fatalf("set block time: %v", err)
+	}
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE routes SET block_minutes=$2 WHERE code=$1`)).
+		WithArgs("NONE", 0).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := repo.SetBlockTime(context.Background(), "NONE", 0); !errors.Is(err, domain.ErrRouteNotFound) {
+		t.Fatalf("want ErrRouteNotFound, got %v", err)
+	}
+}
+
+func TestRouteRepository_Update(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewRouteRepository(db)
+	now := time.Now()
+	route := &domain.Route{Code: "RT1", OriginCode: "CGK", DestinationCode: "SUB"}
+	bookedRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(append(append([]string{}, scheduleRowColumns...), "booked")).
+			AddRow(7, "RT1", "A320", now, now, nil, "Asia/Jakarta", "Asia/Jakarta", nil, "SCHEDULED", "", 0, "", "", "", now, 2)
+	}
+	expectMove := func() {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE routes SET origin_code=$2, destination_code=$3 WHERE code=$1`)).
+			WithArgs("RT1", "CGK", "SUB").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(redateFlightsQuery)).
+			WithArgs("RT1").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+	}
+
+	expectMove()
+	mock.ExpectQuery(regexp.QuoteMeta(routeFlightsQuery)).
+		WithArgs("RT1", sqlmock.AnyArg()).
+		WillReturnRows(bookedRows())
+	mock.ExpectRollback()
+	_, err := repo.Update(context.Background(), route, now, false)
+	var bookingsErr *domain.RouteBookingsError
+	if !errors.As(err, &bookingsErr) || !errors.Is(err, domain.ErrRouteHasBookings) || len(bookingsErr.Flights) != 1 || bookingsErr.Flights[0].Booked != 2 {
+		t.Fatalf("want route bookings error, got %v", err)
+	}
+
+	expectMove()
+	mock.ExpectQuery(regexp.QuoteMeta(routeFlightsQuery)).
+		WithArgs("RT1", sqlmock.AnyArg()).
+		WillReturnRows(bookedRows())
+	mock.ExpectCommit()
+	booked, err := repo.Update(context.Background(), route, now, true)
+	if err != nil || len(booked) != 1 || booked[0].Schedule.ID != 7 {
+		t.Fatalf("forced update: err=%v booked=%+v", err, booked)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE routes SET origin_code=$2, destination_code=$3 WHERE code=$1`)).
+		WithArgs("NONE", "CGK", "SUB").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	if _, err := repo.Update(context.Background(), &domain.Route{Code: "NONE", OriginCode: "CGK", DestinationCode: "SUB"}, now, false); !errors.Is(err, domain.ErrRouteNotFound) {
+		t.Fatalf("want ErrRouteNotFound, got %v", err)
+	}
+}
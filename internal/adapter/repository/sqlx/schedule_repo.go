@@ -4,12 +4,31 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
 	"github.com/jmoiron/sqlx"
 )
 
+// scheduleColumns selects a schedule together with the time zones of its route's airports.
+const scheduleColumns = `SELECT s.id, s.route_code, s.airplane_code, s.departure_date, s.departure_at, s.arrival_at, o.time_zone, d.time_zone, s.series_id, s.status, s.cancel_reason, s.delay_minutes, s.delay_reason, s.departure_gate, s.arrival_gate, s.created_at FROM flight_schedules s JOIN routes r ON r.code = s.route_code JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code`
+
+// boardArrival is a flight's planned arrival, derived from its departure and
+// the route's block time when it has none; NULL when neither is known.
+const boardArrival = `COALESCE(s.arrival_at, s.departure_at + NULLIF(r.block_minutes, 0) * interval '1 minute')`
+
+// boardEstimatedDeparture and boardEstimatedArrival shift the planned times
+// by the flight's delay.
+const (
+	boardEstimatedDeparture = `(s.departure_at + s.delay_minutes * interval '1 minute')`
+	boardEstimatedArrival   = `(` + boardArrival + ` + s.delay_minutes * interval '1 minute')`
+)
+
+// boardColumns selects a schedule as scheduleColumns does, with boardArrival
+// as its arrival, followed by its route's airports and its seat load.
+const boardColumns = `SELECT s.id, s.route_code, s.airplane_code, s.departure_date, s.departure_at, ` + boardArrival + `, o.time_zone, d.time_zone, s.series_id, s.status, s.cancel_reason, s.delay_minutes, s.delay_reason, s.departure_gate, s.arrival_gate, s.created_at, r.origin_code, r.destination_code, COALESCE(i.capacity, 0), COALESCE(i.sold + i.held, 0) FROM flight_schedules s JOIN routes r ON r.code = s.route_code JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code LEFT JOIN seat_inventory i ON i.schedule_id = s.id`
+
 // ScheduleRepository stores flight schedules using sqlx.
 type ScheduleRepository struct {
 	db *sqlx.DB
@@ -24,31 +43,39 @@ func (r *ScheduleRepository) Create(ctx context.Context, sched *domain.FlightSch
 	if err != nil {
 		return domain.ErrInvalidScheduleDate
 	}
-	query := `INSERT INTO flight_schedules (route_code, airplane_code, departure_date) VALUES ($1, $2, $3) RETURNING id, departure_date, created_at`
+	// Schedules created without a time depart at midnight UTC of their date.
+	departureAt := sched.DepartureAt
+	if departureAt.IsZero() {
+		departureAt = departure
+	}
+	var arrivalAt sql.NullTime
+	if !sched.ArrivalAt.IsZero() {
+		arrivalAt = sql.NullTime{Time: sched.ArrivalAt.UTC(), Valid: true}
+	}
+	// The seat inventory row is created by the same statement, sized from the airplane.
+	query := `WITH s AS (INSERT INTO flight_schedules (route_code, airplane_code, departure_date, departure_at, arrival_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, airplane_code, departure_date, created_at), inv AS (INSERT INTO seat_inventory (schedule_id, capacity) SELECT s.id, a.seat_capacity FROM s JOIN airplanes a ON a.code = s.airplane_code) SELECT id, departure_date, created_at FROM s`
 	var storedDate, createdAt time.Time
-	if err := r.db.QueryRowContext(ctx, query, sched.RouteCode, sched.AirplaneCode, departure).Scan(&sched.ID, &storedDate, &createdAt); err != nil {
+	if err := r.db.QueryRowContext(ctx, query, sched.RouteCode, sched.AirplaneCode, departure, departureAt.UTC(), arrivalAt).Scan(&sched.ID, &storedDate, &createdAt); err != nil {
 		if isUniqueViolation(err) {
 			return domain.ErrScheduleExists
 		}
 		return err
 	}
 	sched.DepartureDate = storedDate.Format("2006-01-02")
+	sched.DepartureAt = departureAt.UTC()
 	sched.CreatedAt = createdAt.Format(time.RFC3339)
 	return nil
 }
 
 func (r *ScheduleRepository) GetByID(ctx context.Context, id int64) (*domain.FlightSchedule, error) {
-	row := r.db.QueryRowxContext(ctx, `SELECT id, route_code, airplane_code, departure_date, created_at FROM flight_schedules WHERE id=$1`, id)
-	var sched domain.FlightSchedule
-	var departure, createdAt time.Time
-	if err := row.Scan(&sched.ID, &sched.RouteCode, &sched.AirplaneCode, &departure, &createdAt); err != nil {
+	row := r.db.QueryRowxContext(ctx, scheduleColumns+` WHERE s.id=$1`, id)
+	sched, err := scanSchedule(row)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrScheduleNotFound
 		}
 		return nil, err
 	}
-	sched.DepartureDate = departure.Format("2006-01-02")
-	sched.CreatedAt = createdAt.Format(time.RFC3339)
 	return &sched, nil
 }
 
@@ -58,9 +85,9 @@ func (r *ScheduleRepository) List(ctx context.Context, routeCode string, limit,
 		err  error
 	)
 	if routeCode != "" {
-		rows, err = r.db.QueryxContext(ctx, `SELECT id, route_code, airplane_code, departure_date, created_at FROM flight_schedules WHERE route_code=$1 ORDER BY departure_date LIMIT $2 OFFSET $3`, routeCode, limit, offset)
+		rows, err = r.db.QueryxContext(ctx, scheduleColumns+` WHERE s.route_code=$1 ORDER BY s.departure_at LIMIT $2 OFFSET $3`, routeCode, limit, offset)
 	} else {
-		rows, err = r.db.QueryxContext(ctx, `SELECT id, route_code, airplane_code, departure_date, created_at FROM flight_schedules ORDER BY departure_date LIMIT $1 OFFSET $2`, limit, offset)
+		rows, err = r.db.QueryxContext(ctx, scheduleColumns+` ORDER BY s.departure_at LIMIT $1 OFFSET $2`, limit, offset)
 	}
 	if err != nil {
 		return nil, err
@@ -68,26 +95,267 @@ func (r *ScheduleRepository) List(ctx context.Context, routeCode string, limit,
 	defer func() { _ = rows.Close() }()
 	var items []domain.FlightSchedule
 	for rows.Next() {
-		var s domain.FlightSchedule
-		var departure, createdAt time.Time
-		if err := rows.Scan(&s.ID, &s.RouteCode, &s.AirplaneCode, &departure, &createdAt); err != nil {
+		s, err := scanSchedule(rows)
+		if err != nil {
 			return nil, err
 		}
-		s.DepartureDate = departure.Format("2006-01-02")
-		s.CreatedAt = createdAt.Format(time.RFC3339)
 		items = append(items, s)
 	}
 	return items, rows.Err()
 }
 
-func (r *ScheduleRepository) Delete(ctx context.Context, id int64) error {
-	res, err := r.db.ExecContext(ctx, `DELETE FROM flight_schedules WHERE id=$1`, id)
+func (r *ScheduleRepository) ListByAirplane(ctx context.Context, airplaneCode string, limit, offset int) ([]domain.FlightSchedule, error) {
+	rows, err := r.db.QueryxContext(ctx, scheduleColumns+` WHERE s.airplane_code=$1 ORDER BY s.departure_at, s.id LIMIT $2 OFFSET $3`, airplaneCode, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var items []domain.FlightSchedule
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	return items, rows.Err()
+}
+
+func (r *ScheduleRepository) ListByAirport(ctx context.Context, airportCode string, arrivals bool, from, to time.Time, limit, offset int) ([]domain.BoardFlight, error) {
+	query := boardColumns + ` WHERE r.origin_code=$1 AND ` + boardEstimatedDeparture + ` >= $2 AND ` + boardEstimatedDeparture + ` < $3 ORDER BY ` + boardEstimatedDeparture + `, s.id LIMIT $4 OFFSET $5`
+	if arrivals {
+		query = boardColumns + ` WHERE r.destination_code=$1 AND ` + boardEstimatedArrival + ` >= $2 AND ` + boardEstimatedArrival + ` < $3 ORDER BY ` + boardEstimatedArrival + `, s.id LIMIT $4 OFFSET $5`
+	}
+	rows, err := r.db.QueryxContext(ctx, query, airportCode, from.UTC(), to.UTC(), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var items []domain.BoardFlight
+	for rows.Next() {
+		var f domain.BoardFlight
+		sched, err := scanSchedule(scanFunc(func(dest ...any) error {
+			return rows.Scan(append(dest, &f.OriginCode, &f.DestinationCode, &f.Capacity, &f.Booked)...)
+		}))
+		if err != nil {
+			return nil, err
+		}
+		f.Schedule = sched
+		items = append(items, f)
+	}
+	return items, rows.Err()
+}
+
+// Update locks the schedule's inventory row, so bookings cannot slip in while
+// the airplane changes, then rewrites the schedule, remaps seats and resizes
+// the inventory in one transaction.
+func (r *ScheduleRepository) Update(ctx context.Context, sched *domain.FlightSchedule) ([]domain.SeatMove, error) {
+	departure, err := time.Parse("2006-01-02", sched.DepartureDate)
+	if err != nil {
+		return nil, domain.ErrInvalidScheduleDate
+	}
+	var arrivalAt sql.NullTime
+	if !sched.ArrivalAt.IsZero() {
+		arrivalAt = sql.NullTime{Time: sched.ArrivalAt.UTC(), Valid: true}
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var inv domain.SeatInventory
+	if err := tx.QueryRowContext(ctx, `SELECT sold, held, blocked FROM seat_inventory WHERE schedule_id=$1 FOR UPDATE`, sched.ID).Scan(&inv.Sold, &inv.Held, &inv.Blocked); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrScheduleNotFound
+		}
+		return nil, err
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT seat_capacity FROM airplanes WHERE code=$1`, sched.AirplaneCode).Scan(&inv.Capacity); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAirplaneNotFound
+		}
+		return nil, err
+	}
+	if booked := inv.Sold + inv.Held; booked > inv.Capacity {
+		return nil, &domain.CapacityError{ScheduleID: sched.ID, Capacity: inv.Capacity, Booked: booked}
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE flight_schedules SET airplane_code=$2, departure_date=$3, departure_at=$4, arrival_at=$5 WHERE id=$1`, sched.ID, sched.AirplaneCode, departure, sched.DepartureAt.UTC(), arrivalAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrScheduleExists
+		}
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, domain.ErrScheduleNotFound
+	}
+	moves, err := remapSeats(ctx, tx, sched.ID, inv.Capacity)
+	if err != nil {
+		return nil, err
+	}
+	// Blocked seats give way to bookings when the airplane shrinks.
+	if _, err := tx.ExecContext(ctx, `UPDATE seat_inventory SET capacity=$2, blocked=LEAST(blocked, $2 - sold - held), updated_at=now() WHERE schedule_id=$1`, sched.ID, inv.Capacity); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return moves, nil
+}
+
+// remapSeats moves active bookings seated beyond capacity to the lowest free
+// seats within it. The caller holds the inventory row lock and has checked
+// that every booking fits.
+func remapSeats(ctx context.Context, tx *sqlx.Tx, scheduleID int64, capacity int) ([]domain.SeatMove, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, reference, seat_number FROM bookings WHERE schedule_id=$1 AND status<>'CANCELLED' AND seat_number > $2 ORDER BY seat_number`, scheduleID, capacity)
+	if err != nil {
+		return nil, err
+	}
+	var moves []domain.SeatMove
+	for rows.Next() {
+		m := domain.SeatMove{ScheduleID: scheduleID}
+		if err := rows.Scan(&m.BookingID, &m.Reference, &m.From); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		moves = append(moves, m)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	_ = rows.Close()
+	if len(moves) == 0 {
+		return nil, nil
+	}
+
+	var free []int
+	if err := tx.SelectContext(ctx, &free, `SELECT n FROM generate_series(1, $2::int) AS n WHERE NOT EXISTS (SELECT 1 FROM bookings WHERE schedule_id=$1 AND seat_number=n AND status<>'CANCELLED') ORDER BY n LIMIT $3`, scheduleID, capacity, len(moves)); err != nil {
+		return nil, err
+	}
+	// Only possible when the inventory drifted from the bookings.
+	if len(free) < len(moves) {
+		return nil, domain.ErrCapacityBelowBookings
+	}
+	for i := range moves {
+		moves[i].To = free[i]
+		if _, err := tx.ExecContext(ctx, `UPDATE bookings SET seat_number=$2 WHERE id=$1`, moves[i].BookingID, moves[i].To); err != nil {
+			return nil, err
+		}
+	}
+	return moves, nil
+}
+
+// Cancel locks the schedule row, which bookings being made lock too, then
+// marks it cancelled and flags its bookings in one transaction, so no booking
+// slips onto the flight unflagged.
+func (r *ScheduleRepository) Cancel(ctx context.Context, id int64, reason string, at time.Time) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM flight_schedules WHERE id=$1 FOR UPDATE`, id).Scan(&status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, domain.ErrScheduleNotFound
+		}
+		return 0, err
+	}
+	if status == domain.ScheduleStatusCancelled {
+		return 0, domain.ErrScheduleCancelled
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE flight_schedules SET status='CANCELLED', cancel_reason=$2, cancelled_at=$3 WHERE id=$1`, id, reason, at.UTC()); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE bookings SET affected_at=$2 WHERE schedule_id=$1 AND status<>'CANCELLED'`, id, at.UTC())
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// Delay writes the delays in order and queues the notifications in one
+// transaction, so passengers are told exactly what was recorded. A delay whose
+// flight was cancelled or removed since it was computed rolls the whole delay
+// back rather than notifying passengers of a delay never stored.
+func (r *ScheduleRepository) Delay(ctx context.Context, delays []domain.ScheduleDelay, notices []domain.Notification) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, d := range delays {
+		res, err := tx.ExecContext(ctx, `UPDATE flight_schedules SET delay_minutes=$2, delay_reason=$3 WHERE id=$1 AND status<>'CANCELLED'`, d.ScheduleID, d.Minutes, d.Reason)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("schedule %d: %w", d.ScheduleID, domain.ErrScheduleChanged)
+		}
+	}
+	for _, n := range notices {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO notifications (booking_id, kind, message) VALUES ($1, $2, $3)`, n.BookingID, n.Kind, n.Message); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *ScheduleRepository) SetGates(ctx context.Context, id int64, departureGate, arrivalGate string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE flight_schedules SET departure_gate=$2, arrival_gate=$3 WHERE id=$1`, id, departureGate, arrivalGate)
 	if err != nil {
 		return err
 	}
-	n, _ := res.RowsAffected()
-	if n == 0 {
+	if n, _ := res.RowsAffected(); n == 0 {
 		return domain.ErrScheduleNotFound
 	}
 	return nil
 }
+
+// scheduleDelete removes a schedule; its bookings, inventory and coupons go
+// with it.
+var scheduleDelete = deletePlan{
+	entity:     domain.EntitySchedule,
+	notFound:   domain.ErrScheduleNotFound,
+	dependents: `SELECT 0, 0, 0, (SELECT COUNT(*) FROM bookings b WHERE b.schedule_id = s.id), 0, 0 FROM flight_schedules s WHERE s.id=$1 FOR UPDATE`,
+	statements: []string{`DELETE FROM flight_schedules WHERE id=$1`},
+}
+
+func (r *ScheduleRepository) Delete(ctx context.Context, id int64, cascade *domain.Dependents) (domain.Dependents, error) {
+	return scheduleDelete.exec(ctx, r.db, id, cascade)
+}
+
+func scanSchedule(row interface{ Scan(...any) error }) (domain.FlightSchedule, error) {
+	var s domain.FlightSchedule
+	var departure, departureAt, createdAt time.Time
+	var arrivalAt sql.NullTime
+	var seriesID sql.NullInt64
+	if err := row.Scan(&s.ID, &s.RouteCode, &s.AirplaneCode, &departure, &departureAt, &arrivalAt, &s.OriginTimeZone, &s.DestinationTimeZone, &seriesID, &s.Status, &s.CancelReason, &s.DelayMinutes, &s.DelayReason, &s.DepartureGate, &s.ArrivalGate, &createdAt); err != nil {
+		return s, err
+	}
+	s.SeriesID = seriesID.Int64
+	s.DepartureDate = departure.Format("2006-01-02")
+	s.DepartureAt = departureAt.UTC()
+	if arrivalAt.Valid {
+		s.ArrivalAt = arrivalAt.Time.UTC()
+	}
+	s.CreatedAt = createdAt.Format(time.RFC3339)
+	return s, nil
+}
@@ -12,14 +12,16 @@ import (
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
 )
 
+var scheduleRowColumns = []string{"id", "route_code", "airplane_code", "departure_date", "departure_at", "arrival_at", "origin_tz", "destination_tz", "series_id", "status", "cancel_reason", "delay_minutes", "delay_reason", "departure_gate", "arrival_gate", "created_at"}
+
 func TestScheduleRepository_Create_List_Delete(t *testing.T) {
 	db, mock, cleanup := newMockDB(t)
 	defer cleanup()
 	repo := NewScheduleRepository(db)
 	now := time.Now()
 
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO flight_schedules (route_code, airplane_code, departure_date) VALUES ($1, $2, $3) RETURNING id, departure_date, created_at`)).
-		WithArgs("RT1", "A320", sqlmock.AnyArg()).
+	mock.ExpectQuery(regexp.QuoteMeta(`WITH s AS (INSERT INTO flight_schedules (route_code, airplane_code, departure_date, departure_at, arrival_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, airplane_code, departure_date, created_at), inv AS (INSERT INTO seat_inventory (schedule_id, capacity) SELECT s.id, a.seat_capacity FROM s JOIN airplanes a ON a.code = s.airplane_code) SELECT id, departure_date, created_at FROM s`)).
+		WithArgs("RT1", "A320", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "departure_date", "created_at"}).AddRow(1, now, now))
 	sched := &domain.FlightSchedule{RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02"}
 	if err := repo.Create(context.Background(), sched); err != nil {
@@ -29,18 +31,17 @@ func TestScheduleRepository_Create_List_Delete(t *testing.T) {
 		t.Fatalf("schedule fields not set: %+v", sched)
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, route_code, airplane_code, departure_date, created_at FROM flight_schedules WHERE route_code=$1 ORDER BY departure_date LIMIT $2 OFFSET $3`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT s.id, s.route_code, s.airplane_code, s.departure_date, s.departure_at, s.arrival_at, o.time_zone, d.time_zone, s.series_id, s.status, s.cancel_reason, s.delay_minutes, s.delay_reason, s.departure_gate, s.arrival_gate, s.created_at FROM flight_schedules s JOIN routes r ON r.code = s.route_code JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code WHERE s.route_code=$1 ORDER BY s.departure_at LIMIT $2 OFFSET $3`)).
 		WithArgs("RT1", 10, 0).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "route_code", "airplane_code", "departure_date", "created_at"}).AddRow(1, "RT1", "A320", now, now))
+		WillReturnRows(sqlmock.NewRows(scheduleRowColumns).AddRow(1, "RT1", "A320", now, now, nil, "UTC", "UTC", nil, "SCHEDULED", "", 0, "", "", "", now))
 	list, err := repo.List(context.Background(), "RT1", 10, 0)
 	if err != nil || len(list) != 1 {
 		t.Fatalf("list err=%v len=%d", err, len(list))
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM flight_schedules WHERE id=$1`)).
-		WithArgs(int64(1)).
-		WillReturnResult(sqlmock.NewResult(0, 1))
-	if err := repo.Delete(context.Background(), 1); err != nil {
+	expectDependents(mock, scheduleDelete, int64(1), &domain.Dependents{})
+	expectDeleted(mock, scheduleDelete, int64(1))
+	if _, err := repo.Delete(context.Background(), 1, nil); err != nil {
 		t.Fatalf("delete: %v", err)
 	}
 }
@@ -54,17 +55,16 @@ func TestScheduleRepository_CreateDuplicate_InvalidDate_DeleteNotFound(t *testin
 		t.Fatalf("want invalid date, got %v", err)
 	}
 
-	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO flight_schedules (route_code, airplane_code, departure_date) VALUES ($1, $2, $3) RETURNING id, departure_date, created_at`)).
-		WithArgs("RT1", "A320", sqlmock.AnyArg()).
+	mock.ExpectQuery(regexp.QuoteMeta(`WITH s AS (INSERT INTO flight_schedules (route_code, airplane_code, departure_date, departure_at, arrival_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, airplane_code, departure_date, created_at), inv AS (INSERT INTO seat_inventory (schedule_id, capacity) SELECT s.id, a.seat_capacity FROM s JOIN airplanes a ON a.code = s.airplane_code) SELECT id, departure_date, created_at FROM s`)).
+		WithArgs("RT1", "A320", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnError(&pqError{msg: "duplicate key value violates unique constraint"})
 	if err := repo.Create(context.Background(), &domain.FlightSchedule{RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02"}); err != domain.ErrScheduleExists {
 		t.Fatalf("want schedule exists, got %v", err)
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM flight_schedules WHERE id=$1`)).
-		WithArgs(int64(99)).
-		WillReturnResult(sqlmock.NewResult(0, 0))
-	if err := repo.Delete(context.Background(), 99); err != domain.ErrScheduleNotFound {
+	expectDependents(mock, scheduleDelete, int64(99), nil)
+	mock.ExpectRollback()
+	if _, err := repo.Delete(context.Background(), 99, nil); err != domain.ErrScheduleNotFound {
 		t.Fatalf("want schedule not found, got %v", err)
 	}
 }
@@ -76,10 +76,10 @@ func TestScheduleRepository_GetByID(t *testing.T) {
 	now := time.Now()
 
 	// Test successful retrieval
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, route_code, airplane_code, departure_date, created_at FROM flight_schedules WHERE id=$1`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT s.id, s.route_code, s.airplane_code, s.departure_date, s.departure_at, s.arrival_at, o.time_zone, d.time_zone, s.series_id, s.status, s.cancel_reason, s.delay_minutes, s.delay_reason, s.departure_gate, s.arrival_gate, s.created_at FROM flight_schedules s JOIN routes r ON r.code = s.route_code JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code WHERE s.id=$1`)).
 		WithArgs(int64(1)).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "route_code", "airplane_code", "departure_date", "created_at"}).
-			AddRow(1, "R1", "A1", now, now))
+		WillReturnRows(sqlmock.NewRows(scheduleRowColumns).
+			AddRow(1, "R1", "A1", now, now, now.Add(2*time.Hour), "Asia/Jakarta", "Asia/Makassar", 7, "SCHEDULED", "", 0, "", "", "", now))
 	
 	sched, err := repo.GetByID(context.Background(), 1)
 	if err != nil {
@@ -88,9 +88,12 @@ func TestScheduleRepository_GetByID(t *testing.T) {
 	if sched.ID != 1 || sched.RouteCode != "R1" {
 		t.Fatalf("schedule fields not set correctly: %+v", sched)
 	}
+	if sched.Duration() != 2*time.Hour || sched.DestinationTimeZone != "Asia/Makassar" || sched.SeriesID != 7 {
+		t.Fatalf("schedule times not set correctly: %+v", sched)
+	}
 
 	// Test not found
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, route_code, airplane_code, departure_date, created_at FROM flight_schedules WHERE id=$1`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT s.id, s.route_code, s.airplane_code, s.departure_date, s.departure_at, s.arrival_at, o.time_zone, d.time_zone, s.series_id, s.status, s.cancel_reason, s.delay_minutes, s.delay_reason, s.departure_gate, s.arrival_gate, s.created_at FROM flight_schedules s JOIN routes r ON r.code = s.route_code JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code WHERE s.id=$1`)).
 		WithArgs(int64(99)).
 		WillReturnError(sql.ErrNoRows)
 	sched, err = repo.GetByID(context.Background(), 99)
@@ -107,10 +110,259 @@ func TestScheduleRepository_ListErrors(t *testing.T) {
 	defer cleanup()
 	repo := NewScheduleRepository(db)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, route_code, airplane_code, departure_date, created_at FROM flight_schedules ORDER BY departure_date LIMIT $1 OFFSET $2`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT s.id, s.route_code, s.airplane_code, s.departure_date, s.departure_at, s.arrival_at, o.time_zone, d.time_zone, s.series_id, s.status, s.cancel_reason, s.delay_minutes, s.delay_reason, s.departure_gate, s.arrival_gate, s.created_at FROM flight_schedules s JOIN routes r ON r.code = s.route_code JOIN airports o ON o.code = r.origin_code JOIN airports d ON d.code = r.destination_code ORDER BY s.departure_at LIMIT $1 OFFSET $2`)).
 		WithArgs(5, 0).
 		WillReturnError(errors.New("db down"))
 	if _, err := repo.List(context.Background(), "", 5, 0); err == nil {
 		t.Fatalf("expected list error")
 	}
 }
+
+func TestScheduleRepository_ListByAirplane(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewScheduleRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(scheduleColumns+` WHERE s.airplane_code=$1 ORDER BY s.departure_at, s.id LIMIT $2 OFFSET $3`)).
+		WithArgs("A320", 500, 0).
+		WillReturnRows(sqlmock.NewRows(scheduleRowColumns).
+			AddRow(1, "CGK-DPS", "A320", now, now, now.Add(2*time.Hour), "Asia/Jakarta", "Asia/Makassar", nil, "SCHEDULED", "", 0, "", "", "", now).
+			AddRow(2, "DPS-CGK", "A320", now, now.Add(3*time.Hour), nil, "Asia/Makassar", "Asia/Jakarta", nil, "SCHEDULED", "", 0, "", "", "", now))
+	items, err := repo.ListByAirplane(context.Background(), "A320", 500, 0)
+	if err != nil || len(items) != 2 || items[1].RouteCode != "DPS-CGK" {
+		t.Fatalf("list by airplane: %+v err=%v", items, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestScheduleRepository_UpdateRemapsSeats(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewScheduleRepository(db)
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	sched := &domain.FlightSchedule{ID: 7, RouteCode: "RT1", AirplaneCode: "E190", DepartureDate: "2025-01-02", DepartureAt: day.Add(2 * time.Hour)}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT sold, held, blocked FROM seat_inventory WHERE schedule_id=$1 FOR UPDATE`)).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"sold", "held", "blocked"}).AddRow(2, 1, 4))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT seat_capacity FROM airplanes WHERE code=$1`)).
+		WithArgs("E190").
+		WillReturnRows(sqlmock.NewRows([]string{"seat_capacity"}).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE flight_schedules SET airplane_code=$2, departure_date=$3, departure_at=$4, arrival_at=$5 WHERE id=$1`)).
+		WithArgs(int64(7), "E190", day, day.Add(2*time.Hour), nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, reference, seat_number FROM bookings WHERE schedule_id=$1 AND status<>'CANCELLED' AND seat_number > $2 ORDER BY seat_number`)).
+		WithArgs(int64(7), 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reference", "seat_number"}).AddRow(31, "AAAAAA", 150).AddRow(32, "BBBBBB", 151))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT n FROM generate_series(1, $2::int) AS n WHERE NOT EXISTS (SELECT 1 FROM bookings WHERE schedule_id=$1 AND seat_number=n AND status<>'CANCELLED') ORDER BY n LIMIT $3`)).
+		WithArgs(int64(7), 4, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(2).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE bookings SET seat_number=$2 WHERE id=$1`)).
+		WithArgs(int64(31), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE bookings SET seat_number=$2 WHERE id=$1`)).
+		WithArgs(int64(32), 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE seat_inventory SET capacity=$2, blocked=LEAST(blocked, $2 - sold - held), updated_at=now() WHERE schedule_id=$1`)).
+		WithArgs(int64(7), 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	moves, err := repo.Update(context.Background(), sched)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	want := []domain.SeatMove{{ScheduleID: 7, BookingID: 31, Reference: "AAAAAA", From: 150, To: 2}, {ScheduleID: 7, BookingID: 32, Reference: "BBBBBB", From: 151, To: 4}}
+	if len(moves) != 2 || moves[0] != want[0] || moves[1] != want[1] {
+		t.Fatalf("unexpected moves %+v", moves)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestScheduleRepository_UpdateRefusesSmallAirplane(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewScheduleRepository(db)
+	sched := &domain.FlightSchedule{ID: 7, RouteCode: "RT1", AirplaneCode: "ATR", DepartureDate: "2025-01-02"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT sold, held, blocked FROM seat_inventory WHERE schedule_id=$1 FOR UPDATE`)).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"sold", "held", "blocked"}).AddRow(70, 5, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT seat_capacity FROM airplanes WHERE code=$1`)).
+		WithArgs("ATR").
+		WillReturnRows(sqlmock.NewRows([]string{"seat_capacity"}).AddRow(72))
+	mock.ExpectRollback()
+
+	_, err := repo.Update(context.Background(), sched)
+	var capErr *domain.CapacityError
+	if !errors.As(err, &capErr) || capErr.Booked != 75 || capErr.Capacity != 72 || !errors.Is(err, domain.ErrCapacityBelowBookings) {
+		t.Fatalf("want capacity error, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT sold, held, blocked FROM seat_inventory WHERE schedule_id=$1 FOR UPDATE`)).
+		WithArgs(int64(8)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	if _, err := repo.Update(context.Background(), &domain.FlightSchedule{ID: 8, DepartureDate: "2025-01-02"}); err != domain.ErrScheduleNotFound {
+		t.Fatalf("want schedule not found, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestScheduleRepository_Cancel(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewScheduleRepository(db)
+	at := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT status FROM flight_schedules WHERE id=$1 FOR UPDATE`)).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.ScheduleStatusScheduled))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE flight_schedules SET status='CANCELLED', cancel_reason=$2, cancelled_at=$3 WHERE id=$1`)).
+		WithArgs(int64(7), "weather", at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE bookings SET affected_at=$2 WHERE schedule_id=$1 AND status<>'CANCELLED'`)).
+		WithArgs(int64(7), at).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+	affected, err := repo.Cancel(context.Background(), 7, "weather", at)
+	if err != nil || affected != 3 {
+		t.Fatalf("cancel: err=%v affected=%d", err, affected)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT status FROM flight_schedules WHERE id=$1 FOR UPDATE`)).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.ScheduleStatusCancelled))
+	mock.ExpectRollback()
+	if _, err := repo.Cancel(context.Background(), 7, "weather", at); err != domain.ErrScheduleCancelled {
+		t.Fatalf("want schedule cancelled, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT status FROM flight_schedules WHERE id=$1 FOR UPDATE`)).
+		WithArgs(int64(8)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	if _, err := repo.Cancel(context.Background(), 8, "weather", at); err != domain.ErrScheduleNotFound {
+		t.Fatalf("want schedule not found, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestScheduleRepository_Delay(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewScheduleRepository(db)
+	delays := []domain.ScheduleDelay{{ScheduleID: 7, Minutes: 45, Reason: "weather"}, {ScheduleID: 8, Minutes: 20, Reason: "late inbound aircraft from schedule 7"}}
+	notices := []domain.Notification{{BookingID: 31, Kind: domain.NotificationDelay, Message: "delayed"}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE flight_schedules SET delay_minutes=$2, delay_reason=$3 WHERE id=$1 AND status<>'CANCELLED'`)).
+		WithArgs(int64(7), 45, "weather").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE flight_schedules SET delay_minutes=$2, delay_reason=$3 WHERE id=$1 AND status<>'CANCELLED'`)).
+		WithArgs(int64(8), 20, "late inbound aircraft from schedule 7").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO notifications (booking_id, kind, message) VALUES ($1, $2, $3)`)).
+		WithArgs(int64(31), domain.NotificationDelay, "delayed").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	if err := repo.Delay(context.Background(), delays, notices); err != nil {
+		t.Fatalf("delay: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE flight_schedules SET delay_minutes=$2, delay_reason=$3 WHERE id=$1 AND status<>'CANCELLED'`)).
+		WithArgs(int64(9), 45, "weather").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	if err := repo.Delay(context.Background(), []domain.ScheduleDelay{{ScheduleID: 9, Minutes: 45, Reason: "weather"}}, nil); !errors.Is(err, domain.ErrScheduleChanged) {
+		t.Fatalf("want schedule changed, got %v", err)
+	}
+
+	// Schedule 8 was cancelled after the delay was propagated to it.
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE flight_schedules SET delay_minutes=$2, delay_reason=$3 WHERE id=$1 AND status<>'CANCELLED'`)).
+		WithArgs(int64(7), 45, "weather").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE flight_schedules SET delay_minutes=$2, delay_reason=$3 WHERE id=$1 AND status<>'CANCELLED'`)).
+		WithArgs(int64(8), 20, "late inbound aircraft from schedule 7").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	if err := repo.Delay(context.Background(), delays, notices); !errors.Is(err, domain.ErrScheduleChanged) {
+		t.Fatalf("want schedule changed, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestScheduleRepository_ListByAirport(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewScheduleRepository(db)
+	now := time.Now()
+	from := time.Date(2025, 1, 1, 17, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	boardRowColumns := append(append([]string{}, scheduleRowColumns...), "origin_code", "destination_code", "capacity", "booked")
+
+	mock.ExpectQuery(regexp.QuoteMeta(boardColumns+` WHERE r.origin_code=$1 AND (s.departure_at + s.delay_minutes * interval '1 minute') >= $2 AND (s.departure_at + s.delay_minutes * interval '1 minute') < $3 ORDER BY (s.departure_at + s.delay_minutes * interval '1 minute'), s.id LIMIT $4 OFFSET $5`)).
+		WithArgs("CGK", from, to, 500, 0).
+		WillReturnRows(sqlmock.NewRows(boardRowColumns).
+			AddRow(1, "CGK-DPS", "A320", now, now, now.Add(2*time.Hour), "Asia/Jakarta", "Asia/Makassar", nil, "SCHEDULED", "", 15, "weather", "A12", "", now, "CGK", "DPS", 180, 90))
+	items, err := repo.ListByAirport(context.Background(), "CGK", false, from, to, 500, 0)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("departures: %+v err=%v", items, err)
+	}
+	if f := items[0]; f.DestinationCode != "DPS" || f.Schedule.DepartureGate != "A12" || f.Schedule.DelayMinutes != 15 || f.LoadFactor() != 0.5 {
+		t.Fatalf("unexpected board flight %+v", f)
+	}
+
+	// Arrivals fall back on the route's block time when no arrival is planned.
+	estimatedArrival := `(COALESCE(s.arrival_at, s.departure_at + NULLIF(r.block_minutes, 0) * interval '1 minute') + s.delay_minutes * interval '1 minute')`
+	mock.ExpectQuery(regexp.QuoteMeta(boardColumns+` WHERE r.destination_code=$1 AND `+estimatedArrival+` >= $2 AND `+estimatedArrival+` < $3 ORDER BY `+estimatedArrival+`, s.id LIMIT $4 OFFSET $5`)).
+		WithArgs("CGK", from, to, 500, 0).
+		WillReturnError(errors.New("db down"))
+	if _, err := repo.ListByAirport(context.Background(), "CGK", true, from, to, 500, 0); err == nil {
+		t.Fatalf("expected arrivals error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestScheduleRepository_SetGates(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewScheduleRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE flight_schedules SET departure_gate=$2, arrival_gate=$3 WHERE id=$1`)).
+		WithArgs(int64(7), "A12", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.SetGates(context.Background(), 7, "A12", ""); err != nil {
+		t.Fatalf("set gates: %v", err)
+	}
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE flight_schedules SET departure_gate=$2, arrival_gate=$3 WHERE id=$1`)).
+		WithArgs(int64(9), "A12", "B3").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := repo.SetGates(context.Background(), 9, "A12", "B3"); err != domain.ErrScheduleNotFound {
+		t.Fatalf("want schedule not found, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
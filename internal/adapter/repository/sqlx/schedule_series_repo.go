@@ -0,0 +1,227 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+const seriesColumns = `SELECT id, route_code, airplane_code, start_date, end_date, days, departure_time, arrival_time, cancelled_at, created_at FROM schedule_series`
+
+// insertSeriesFlightQuery adds one flight of a series with its seat inventory.
+// A flight already on the route, airplane and date is left alone and no row is returned.
+const insertSeriesFlightQuery = `WITH s AS (INSERT INTO flight_schedules (route_code, airplane_code, departure_date, departure_at, arrival_at, series_id) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT ON CONSTRAINT flight_schedules_unique DO NOTHING RETURNING id, airplane_code, created_at), inv AS (INSERT INTO seat_inventory (schedule_id, capacity) SELECT s.id, a.seat_capacity FROM s JOIN airplanes a ON a.code = s.airplane_code) SELECT id, created_at FROM s`
+
+// ScheduleSeriesRepository stores schedule series and materialises their flights using sqlx.
+type ScheduleSeriesRepository struct {
+	db *sqlx.DB
+}
+
+func NewScheduleSeriesRepository(db *sqlx.DB) *ScheduleSeriesRepository {
+	return &ScheduleSeriesRepository{db: db}
+}
+
+// Create inserts the series and its flights in one transaction.
+func (r *ScheduleSeriesRepository) Create(ctx context.Context, s *domain.ScheduleSeries, flights []domain.FlightSchedule) ([]domain.FlightSchedule, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var arrival sql.NullString
+	if s.ArrivalTime != "" {
+		arrival = sql.NullString{String: s.ArrivalTime, Valid: true}
+	}
+	var id int64
+	var createdAt time.Time
+	if err := tx.QueryRowContext(ctx, `INSERT INTO schedule_series (route_code, airplane_code, start_date, end_date, days, departure_time, arrival_time) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id, created_at`,
+		s.RouteCode, s.AirplaneCode, s.StartDate, s.EndDate, int(s.Days), s.DepartureTime, arrival).Scan(&id, &createdAt); err != nil {
+		return nil, err
+	}
+	created, err := insertSeriesFlights(ctx, tx, id, flights)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.ID = id
+	s.CreatedAt = createdAt.Format(time.RFC3339)
+	return created, nil
+}
+
+func (r *ScheduleSeriesRepository) GetByID(ctx context.Context, id int64) (*domain.ScheduleSeries, error) {
+	s, err := scanSeries(r.db.QueryRowxContext(ctx, seriesColumns+` WHERE id=$1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSeriesNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *ScheduleSeriesRepository) List(ctx context.Context, limit, offset int) ([]domain.ScheduleSeries, error) {
+	rows, err := r.db.QueryxContext(ctx, seriesColumns+` ORDER BY id LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var items []domain.ScheduleSeries
+	for rows.Next() {
+		s, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	return items, rows.Err()
+}
+
+func (r *ScheduleSeriesRepository) Flights(ctx context.Context, id int64) ([]domain.FlightSchedule, error) {
+	rows, err := r.db.QueryxContext(ctx, scheduleColumns+` WHERE s.series_id=$1 ORDER BY s.departure_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var items []domain.FlightSchedule
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	return items, rows.Err()
+}
+
+// Extend moves the end date of an active series and adds its new flights in one transaction.
+func (r *ScheduleSeriesRepository) Extend(ctx context.Context, s *domain.ScheduleSeries, flights []domain.FlightSchedule) ([]domain.FlightSchedule, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `UPDATE schedule_series SET end_date=$2 WHERE id=$1 AND cancelled_at IS NULL`, s.ID, s.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, domain.ErrSeriesNotFound
+	}
+	created, err := insertSeriesFlights(ctx, tx, s.ID, flights)
+	if err != nil {
+		return nil, err
+	}
+	return created, tx.Commit()
+}
+
+// Amend stores the series' times and moves each given flight in one transaction.
+func (r *ScheduleSeriesRepository) Amend(ctx context.Context, s *domain.ScheduleSeries, flights []domain.FlightSchedule) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var arrival sql.NullString
+	if s.ArrivalTime != "" {
+		arrival = sql.NullString{String: s.ArrivalTime, Valid: true}
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE schedule_series SET departure_time=$2, arrival_time=$3 WHERE id=$1 AND cancelled_at IS NULL`, s.ID, s.DepartureTime, arrival)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrSeriesNotFound
+	}
+	for _, f := range flights {
+		if _, err := tx.ExecContext(ctx, `UPDATE flight_schedules SET departure_at=$2, arrival_at=$3 WHERE id=$1 AND series_id=$4`, f.ID, f.DepartureAt.UTC(), nullTime(f.ArrivalAt), s.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Cancel marks the series cancelled and removes its unbooked flights from the
+// given instant on in one transaction.
+func (r *ScheduleSeriesRepository) Cancel(ctx context.Context, id int64, from time.Time) (int, int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `UPDATE schedule_series SET cancelled_at=now() WHERE id=$1 AND cancelled_at IS NULL`, id)
+	if err != nil {
+		return 0, 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, 0, domain.ErrSeriesNotFound
+	}
+	res, err = tx.ExecContext(ctx, `DELETE FROM flight_schedules s WHERE s.series_id=$1 AND s.departure_at >= $2 AND NOT EXISTS (SELECT 1 FROM bookings b WHERE b.schedule_id = s.id)`, id, from.UTC())
+	if err != nil {
+		return 0, 0, err
+	}
+	removed, _ := res.RowsAffected()
+	var kept int
+	if err := tx.QueryRowContext(ctx, `SELECT count(*) FROM flight_schedules WHERE series_id=$1 AND departure_at >= $2`, id, from.UTC()).Scan(&kept); err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return int(removed), kept, nil
+}
+
+// insertSeriesFlights adds the flights that do not exist yet and returns them with their ids.
+func insertSeriesFlights(ctx context.Context, tx *sqlx.Tx, seriesID int64, flights []domain.FlightSchedule) ([]domain.FlightSchedule, error) {
+	var created []domain.FlightSchedule
+	for _, f := range flights {
+		var createdAt time.Time
+		err := tx.QueryRowContext(ctx, insertSeriesFlightQuery, f.RouteCode, f.AirplaneCode, f.DepartureDate, f.DepartureAt.UTC(), nullTime(f.ArrivalAt), seriesID).Scan(&f.ID, &createdAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		f.SeriesID = seriesID
+		f.CreatedAt = createdAt.Format(time.RFC3339)
+		created = append(created, f)
+	}
+	return created, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func scanSeries(row interface{ Scan(...any) error }) (domain.ScheduleSeries, error) {
+	var s domain.ScheduleSeries
+	var start, end, createdAt time.Time
+	var days int
+	var arrival sql.NullString
+	var cancelledAt sql.NullTime
+	if err := row.Scan(&s.ID, &s.RouteCode, &s.AirplaneCode, &start, &end, &days, &s.DepartureTime, &arrival, &cancelledAt, &createdAt); err != nil {
+		return s, err
+	}
+	s.StartDate = start.Format("2006-01-02")
+	s.EndDate = end.Format("2006-01-02")
+	s.Days = domain.Weekdays(days)
+	s.ArrivalTime = arrival.String
+	if cancelledAt.Valid {
+		s.CancelledAt = cancelledAt.Time.Format(time.RFC3339)
+	}
+	s.CreatedAt = createdAt.Format(time.RFC3339)
+	return s, nil
+}
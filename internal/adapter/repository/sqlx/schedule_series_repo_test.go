@@ -0,0 +1,99 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+var seriesRowColumns = []string{"id", "route_code", "airplane_code", "start_date", "end_date", "days", "departure_time", "arrival_time", "cancelled_at", "created_at"}
+
+func TestScheduleSeriesRepository_CreateSkipsExisting(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewScheduleSeriesRepository(db)
+	now := time.Now()
+	day := time.Date(2025, 1, 1, 1, 30, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO schedule_series (route_code, airplane_code, start_date, end_date, days, departure_time, arrival_time) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id, created_at`)).
+		WithArgs("RT1", "A320", "2025-01-01", "2025-01-03", 42, "08:30", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, now))
+	mock.ExpectQuery(regexp.QuoteMeta(insertSeriesFlightQuery)).
+		WithArgs("RT1", "A320", "2025-01-01", day, nil, int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+	mock.ExpectQuery(regexp.QuoteMeta(insertSeriesFlightQuery)).
+		WithArgs("RT1", "A320", "2025-01-03", day.AddDate(0, 0, 2), nil, int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(11, now))
+	mock.ExpectCommit()
+
+	series := &domain.ScheduleSeries{RouteCode: "RT1", AirplaneCode: "A320", StartDate: "2025-01-01", EndDate: "2025-01-03", Days: 42, DepartureTime: "08:30"}
+	created, err := repo.Create(context.Background(), series, []domain.FlightSchedule{
+		{RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-01", DepartureAt: day},
+		{RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-03", DepartureAt: day.AddDate(0, 0, 2)},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if series.ID != 3 || len(created) != 1 || created[0].ID != 11 || created[0].SeriesID != 3 {
+		t.Fatalf("expected the existing flight skipped, got series=%+v created=%+v", series, created)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestScheduleSeriesRepository_GetExtendCancel(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewScheduleSeriesRepository(db)
+	now := time.Now()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(seriesColumns + ` WHERE id=$1`)).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows(seriesRowColumns).AddRow(3, "RT1", "A320", start, start.AddDate(0, 1, 0), 42, "08:30", "11:20", nil, now))
+	series, err := repo.GetByID(context.Background(), 3)
+	if err != nil || series.Days.String() != "Mon,Wed,Fri" || series.EndDate != "2025-02-01" || series.ArrivalTime != "11:20" || series.Cancelled() {
+		t.Fatalf("unexpected series %+v err=%v", series, err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(seriesColumns + ` WHERE id=$1`)).
+		WithArgs(int64(9)).
+		WillReturnError(sql.ErrNoRows)
+	if _, err := repo.GetByID(context.Background(), 9); err != domain.ErrSeriesNotFound {
+		t.Fatalf("want series not found, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE schedule_series SET end_date=$2 WHERE id=$1 AND cancelled_at IS NULL`)).
+		WithArgs(int64(9), "2025-03-01").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	if _, err := repo.Extend(context.Background(), &domain.ScheduleSeries{ID: 9, EndDate: "2025-03-01"}, nil); err != domain.ErrSeriesNotFound {
+		t.Fatalf("want series not found on extend, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE schedule_series SET cancelled_at=now() WHERE id=$1 AND cancelled_at IS NULL`)).
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM flight_schedules s WHERE s.series_id=$1 AND s.departure_at >= $2 AND NOT EXISTS (SELECT 1 FROM bookings b WHERE b.schedule_id = s.id)`)).
+		WithArgs(int64(3), start).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM flight_schedules WHERE series_id=$1 AND departure_at >= $2`)).
+		WithArgs(int64(3), start).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectCommit()
+	removed, kept, err := repo.Cancel(context.Background(), 3, start)
+	if err != nil || removed != 4 || kept != 1 {
+		t.Fatalf("want 4 removed and 1 kept, got %d/%d err=%v", removed, kept, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// inventoryDriftQuery lists schedules whose inventory row is missing or disagrees
+// with the airplane capacity or the confirmed and held bookings.
+const inventoryDriftQuery = `SELECT s.id, a.seat_capacity, COALESCE(b.sold, 0), COALESCE(b.held, 0), i.schedule_id IS NULL, COALESCE(i.capacity, 0), COALESCE(i.sold, 0), COALESCE(i.held, 0), COALESCE(i.blocked, 0) FROM flight_schedules s JOIN airplanes a ON a.code = s.airplane_code LEFT JOIN seat_inventory i ON i.schedule_id = s.id LEFT JOIN (SELECT schedule_id, COUNT(*) FILTER (WHERE status='CONFIRMED') AS sold, COUNT(*) FILTER (WHERE status='HELD') AS held FROM bookings GROUP BY schedule_id) b ON b.schedule_id = s.id WHERE i.schedule_id IS NULL OR i.capacity <> a.seat_capacity OR i.sold <> COALESCE(b.sold, 0) OR i.held <> COALESCE(b.held, 0) ORDER BY s.id`
+
+// SeatInventoryRepository reads and repairs the seat_inventory table.
+type SeatInventoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewSeatInventoryRepository(db *sqlx.DB) *SeatInventoryRepository {
+	return &SeatInventoryRepository{db: db}
+}
+
+func (r *SeatInventoryRepository) Get(ctx context.Context, scheduleID int64) (*domain.SeatInventory, error) {
+	inv := domain.SeatInventory{ScheduleID: scheduleID}
+	var updatedAt time.Time
+	if err := r.db.QueryRowContext(ctx, `SELECT capacity, sold, held, blocked, updated_at FROM seat_inventory WHERE schedule_id=$1`, scheduleID).Scan(&inv.Capacity, &inv.Sold, &inv.Held, &inv.Blocked, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInventoryNotFound
+		}
+		return nil, err
+	}
+	inv.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &inv, nil
+}
+
+// Reconcile reports drifted inventory rows. With repair set it locks the table
+// against concurrent bookings, re-reads the drift and rewrites those rows, keeping
+// blocked seats as recorded.
+func (r *SeatInventoryRepository) Reconcile(ctx context.Context, repair bool) ([]domain.InventoryDrift, error) {
+	if !repair {
+		return listDrift(ctx, r.db)
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE seat_inventory IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+	drifts, err := listDrift(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range drifts {
+		e := d.Expected
+		if _, err := tx.ExecContext(ctx, `INSERT INTO seat_inventory (schedule_id, capacity, sold, held) VALUES ($1,$2,$3,$4) ON CONFLICT (schedule_id) DO UPDATE SET capacity=EXCLUDED.capacity, sold=EXCLUDED.sold, held=EXCLUDED.held, updated_at=now()`, e.ScheduleID, e.Capacity, e.Sold, e.Held); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return drifts, nil
+}
+
+func listDrift(ctx context.Context, q sqlx.QueryerContext) ([]domain.InventoryDrift, error) {
+	rows, err := q.QueryxContext(ctx, inventoryDriftQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var drifts []domain.InventoryDrift
+	for rows.Next() {
+		var d domain.InventoryDrift
+		if err := rows.Scan(&d.Expected.ScheduleID, &d.Expected.Capacity, &d.Expected.Sold, &d.Expected.Held, &d.Missing, &d.Recorded.Capacity, &d.Recorded.Sold, &d.Recorded.Held, &d.Recorded.Blocked); err != nil {
+			return nil, err
+		}
+		d.Recorded.ScheduleID = d.Expected.ScheduleID
+		d.Expected.Blocked = d.Recorded.Blocked
+		drifts = append(drifts, d)
+	}
+	return drifts, rows.Err()
+}
@@ -0,0 +1,66 @@
+package sqlxrepo
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+var driftColumns = []string{"id", "seat_capacity", "sold", "held", "missing", "capacity", "recorded_sold", "recorded_held", "blocked"}
+
+func TestSeatInventoryRepository_Get(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewSeatInventoryRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT capacity, sold, held, blocked, updated_at FROM seat_inventory WHERE schedule_id=$1`)).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity", "sold", "held", "blocked", "updated_at"}).AddRow(180, 10, 2, 1, time.Now()))
+	inv, err := repo.Get(context.Background(), 1)
+	if err != nil || inv.Available() != 167 {
+		t.Fatalf("get: err=%v inv=%+v", err, inv)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT capacity, sold, held, blocked, updated_at FROM seat_inventory WHERE schedule_id=$1`)).
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity", "sold", "held", "blocked", "updated_at"}))
+	if _, err := repo.Get(context.Background(), 2); err != domain.ErrInventoryNotFound {
+		t.Fatalf("want inventory not found, got %v", err)
+	}
+}
+
+func TestSeatInventoryRepository_Reconcile(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewSeatInventoryRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(inventoryDriftQuery)).
+		WillReturnRows(sqlmock.NewRows(driftColumns).AddRow(1, 180, 5, 1, false, 180, 4, 1, 2))
+	drifts, err := repo.Reconcile(context.Background(), false)
+	if err != nil || len(drifts) != 1 {
+		t.Fatalf("report: err=%v drifts=%+v", err, drifts)
+	}
+	if d := drifts[0]; d.Recorded.Sold != 4 || d.Expected.Sold != 5 || d.Expected.Blocked != 2 || d.Missing {
+		t.Fatalf("unexpected drift %+v", d)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE seat_inventory IN SHARE ROW EXCLUSIVE MODE`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(inventoryDriftQuery)).
+		WillReturnRows(sqlmock.NewRows(driftColumns).AddRow(2, 150, 0, 0, true, 0, 0, 0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO seat_inventory (schedule_id, capacity, sold, held) VALUES ($1,$2,$3,$4) ON CONFLICT (schedule_id) DO UPDATE SET capacity=EXCLUDED.capacity, sold=EXCLUDED.sold, held=EXCLUDED.held, updated_at=now()`)).
+		WithArgs(int64(2), 150, 0, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	drifts, err = repo.Reconcile(context.Background(), true)
+	if err != nil || len(drifts) != 1 || !drifts[0].Missing {
+		t.Fatalf("repair: err=%v drifts=%+v", err, drifts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// TicketRepository persists e-tickets and their coupons via sqlx.
+type TicketRepository struct {
+	db *sqlx.DB
+}
+
+func NewTicketRepository(db *sqlx.DB) *TicketRepository {
+	return &TicketRepository{db: db}
+}
+
+// NextSerial draws the next ticket serial from the database sequence.
+func (r *TicketRepository) NextSerial(ctx context.Context) (int64, error) {
+	var serial int64
+	if err := r.db.QueryRowContext(ctx, `SELECT nextval('ticket_serial_seq')`).Scan(&serial); err != nil {
+		return 0, err
+	}
+	return serial, nil
+}
+
+// Create stores the ticket and all of its coupons in a single transaction.
+func (r *TicketRepository) Create(ctx context.Context, t *domain.Ticket) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var issuedAt time.Time
+	if err := tx.QueryRowContext(ctx, `INSERT INTO tickets (number, booking_id, passenger_name) VALUES ($1,$2,$3) RETURNING id, issued_at`, t.Number, t.BookingID, t.PassengerName).Scan(&t.ID, &issuedAt); err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrTicketExists
+		}
+		if isForeignKeyViolation(err) {
+			return domain.ErrBookingNotFound
+		}
+		return err
+	}
+	for i := range t.Coupons {
+		c := &t.Coupons[i]
+		c.TicketID = t.ID
+		if err := tx.QueryRowContext(ctx, `INSERT INTO ticket_coupons (ticket_id, sequence, schedule_id, status) VALUES ($1,$2,$3,$4) RETURNING id`, c.TicketID, c.Sequence, c.ScheduleID, c.Status).Scan(&c.ID); err != nil {
+			if isForeignKeyViolation(err) {
+				return domain.ErrScheduleNotFound
+			}
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	t.IssuedAt = issuedAt.Format(time.RFC3339)
+	return nil
+}
+
+func (r *TicketRepository) GetByNumber(ctx context.Context, number string) (*domain.Ticket, error) {
+	row := r.db.QueryRowxContext(ctx, `SELECT t.id, t.number, t.booking_id, b.reference, t.passenger_name, t.issued_at FROM tickets t JOIN bookings b ON b.id = t.booking_id WHERE t.number=$1`, number)
+	var t domain.Ticket
+	var issuedAt time.Time
+	if err := row.Scan(&t.ID, &t.Number, &t.BookingID, &t.BookingReference, &t.PassengerName, &issuedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTicketNotFound
+		}
+		return nil, err
+	}
+	t.IssuedAt = issuedAt.Format(time.RFC3339)
+	coupons, err := r.listCoupons(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	t.Coupons = coupons
+	return &t, nil
+}
+
+func (r *TicketRepository) ListByBooking(ctx context.Context, bookingID int64) ([]domain.Ticket, error) {
+	rows, err := r.db.QueryxContext(ctx, `SELECT t.id, t.number, t.booking_id, b.reference, t.passenger_name, t.issued_at FROM tickets t JOIN bookings b ON b.id = t.booking_id WHERE t.booking_id=$1 ORDER BY t.number`, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var items []domain.Ticket
+	for rows.Next() {
+		var t domain.Ticket
+		var issuedAt time.Time
+		if err := rows.Scan(&t.ID, &t.Number, &t.BookingID, &t.BookingReference, &t.PassengerName, &issuedAt); err != nil {
+			return nil, err
+		}
+		t.IssuedAt = issuedAt.Format(time.RFC3339)
+		items = append(items, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range items {
+		coupons, err := r.listCoupons(ctx, items[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		items[i].Coupons = coupons
+	}
+	return items, nil
+}
+
+func (r *TicketRepository) UpdateCouponStatus(ctx context.Context, couponID int64, from, to string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE ticket_coupons SET status=$1, updated_at=now() WHERE id=$2 AND status=$3`, to, couponID, from)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return domain.ErrInvalidCouponTransition
+	}
+	return nil
+}
+
+func (r *TicketRepository) listCoupons(ctx context.Context, ticketID int64) ([]domain.Coupon, error) {
+	rows, err := r.db.QueryxContext(ctx, `SELECT id, ticket_id, sequence, schedule_id, status FROM ticket_coupons WHERE ticket_id=$1 ORDER BY sequence`, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var coupons []domain.Coupon
+	for rows.Next() {
+		var c domain.Coupon
+		if err := rows.Scan(&c.ID, &c.TicketID, &c.Sequence, &c.ScheduleID, &c.Status); err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, c)
+	}
+	return coupons, rows.Err()
+}
@@ -0,0 +1,103 @@
+package sqlxrepo
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func TestTicketRepository_NextSerial_Create_Get(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewTicketRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT nextval('ticket_serial_seq')`)).
+		WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(42))
+	serial, err := repo.NextSerial(context.Background())
+	if err != nil || serial != 42 {
+		t.Fatalf("next serial: err=%v serial=%d", err, serial)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO tickets (number, booking_id, passenger_name) VALUES ($1,$2,$3) RETURNING id, issued_at`)).
+		WithArgs("1260000000420", int64(7), "Alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "issued_at"}).AddRow(3, now))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO ticket_coupons (ticket_id, sequence, schedule_id, status) VALUES ($1,$2,$3,$4) RETURNING id`)).
+		WithArgs(int64(3), 1, int64(1), domain.CouponStatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectCommit()
+	tk := &domain.Ticket{Number: "1260000000420", BookingID: 7, PassengerName: "Alice", Coupons: []domain.Coupon{{Sequence: 1, ScheduleID: 1, Status: domain.CouponStatusOpen}}}
+	if err := repo.Create(context.Background(), tk); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if tk.ID != 3 || tk.Coupons[0].ID != 9 || tk.Coupons[0].TicketID != 3 || tk.IssuedAt == "" {
+		t.Fatalf("ticket fields not set: %+v", tk)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT t.id, t.number, t.booking_id, b.reference, t.passenger_name, t.issued_at FROM tickets t JOIN bookings b ON b.id = t.booking_id WHERE t.number=$1`)).
+		WithArgs("1260000000420").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "booking_id", "reference", "passenger_name", "issued_at"}).AddRow(3, "1260000000420", 7, "BK-AAAAAA", "Alice", now))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, ticket_id, sequence, schedule_id, status FROM ticket_coupons WHERE ticket_id=$1 ORDER BY sequence`)).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ticket_id", "sequence", "schedule_id", "status"}).AddRow(9, 3, 1, 1, domain.CouponStatusOpen))
+	got, err := repo.GetByNumber(context.Background(), "1260000000420")
+	if err != nil || got.BookingReference != "BK-AAAAAA" || len(got.Coupons) != 1 {
+		t.Fatalf("get: err=%v got=%+v", err, got)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT t.id, t.number, t.booking_id, b.reference, t.passenger_name, t.issued_at FROM tickets t JOIN bookings b ON b.id = t.booking_id WHERE t.booking_id=$1 ORDER BY t.number`)).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "booking_id", "reference", "passenger_name", "issued_at"}).AddRow(3, "1260000000420", 7, "BK-AAAAAA", "Alice", now))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, ticket_id, sequence, schedule_id, status FROM ticket_coupons WHERE ticket_id=$1 ORDER BY sequence`)).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ticket_id", "sequence", "schedule_id", "status"}).AddRow(9, 3, 1, 1, domain.CouponStatusOpen))
+	list, err := repo.ListByBooking(context.Background(), 7)
+	if err != nil || len(list) != 1 || len(list[0].Coupons) != 1 {
+		t.Fatalf("list: err=%v list=%+v", err, list)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE ticket_coupons SET status=$1, updated_at=now() WHERE id=$2 AND status=$3`)).
+		WithArgs(domain.CouponStatusCheckedIn, int64(9), domain.CouponStatusOpen).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.UpdateCouponStatus(context.Background(), 9, domain.CouponStatusOpen, domain.CouponStatusCheckedIn); err != nil {
+		t.Fatalf("update coupon: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTicketRepository_Errors(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := NewTicketRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO tickets (number, booking_id, passenger_name) VALUES ($1,$2,$3) RETURNING id, issued_at`)).
+		WithArgs("1260000000420", int64(7), "Alice").
+		WillReturnError(&pqErr{msg: "duplicate key value violates unique constraint"})
+	mock.ExpectRollback()
+	if err := repo.Create(context.Background(), &domain.Ticket{Number: "1260000000420", BookingID: 7, PassengerName: "Alice"}); err != domain.ErrTicketExists {
+		t.Fatalf("want ticket exists, got %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT t.id, t.number, t.booking_id, b.reference, t.passenger_name, t.issued_at FROM tickets t JOIN bookings b ON b.id = t.booking_id WHERE t.number=$1`)).
+		WithArgs("1260000000001").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "booking_id", "reference", "passenger_name", "issued_at"}))
+	if _, err := repo.GetByNumber(context.Background(), "1260000000001"); err != domain.ErrTicketNotFound {
+		t.Fatalf("want ticket not found, got %v", err)
+	}
+
+	// A coupon another command already moved, or one that does not exist, is not updated.
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE ticket_coupons SET status=$1, updated_at=now() WHERE id=$2 AND status=$3`)).
+		WithArgs(domain.CouponStatusFlown, int64(99), domain.CouponStatusCheckedIn).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := repo.UpdateCouponStatus(context.Background(), 99, domain.CouponStatusCheckedIn, domain.CouponStatusFlown); err != domain.ErrInvalidCouponTransition {
+		t.Fatalf("want invalid coupon transition, got %v", err)
+	}
+}
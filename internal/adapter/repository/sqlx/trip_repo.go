@@ -0,0 +1,130 @@
+package sqlxrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+const tripBookingsQuery = `SELECT b.id, b.reference, b.schedule_id, b.passenger_name, b.seat_number, b.status, b.created_at FROM bookings b JOIN flight_schedules s ON s.id = b.schedule_id WHERE b.trip_id=$1 ORDER BY s.departure_at, b.id`
+
+// tripConnectionsQuery pairs each active connecting trip booking on a schedule
+// with the trip's earliest later booking departing from the schedule's
+// destination. Round trips are left out: their return is not a connection.
+const tripConnectionsQuery = `SELECT DISTINCT ON (b.id) b.id, b.reference, b.schedule_id, b.passenger_name, b.seat_number, b.status, b.created_at, n.id, n.reference, n.schedule_id, n.passenger_name, n.seat_number, n.status, n.created_at, r.destination_code, b.trip_id FROM bookings b JOIN trips t ON t.id = b.trip_id AND t.kind='CONNECTING' JOIN flight_schedules s ON s.id = b.schedule_id JOIN routes r ON r.code = s.route_code JOIN bookings n ON n.trip_id = b.trip_id AND n.id <> b.id AND n.status<>'CANCELLED' JOIN flight_schedules ns ON ns.id = n.schedule_id JOIN routes nr ON nr.code = ns.route_code WHERE b.schedule_id=$1 AND b.status<>'CANCELLED' AND nr.origin_code = r.destination_code AND ns.departure_at > s.departure_at ORDER BY b.id, ns.departure_at, n.id`
+
+// TripRepository persists trips and their bookings using sqlx.
+type TripRepository struct {
+	db *sqlx.DB
+}
+
+func NewTripRepository(db *sqlx.DB) *TripRepository {
+	return &TripRepository{db: db}
+}
+
+// Create inserts the trip and claims a seat for each of its bookings in one
+// transaction. Inventory rows are locked in schedule order so that concurrent
+// trips over the same flights cannot deadlock.
+func (r *TripRepository) Create(ctx context.Context, t *domain.Trip) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var currency sql.NullString
+	var amount sql.NullInt64
+	if !t.Fare.IsZero() {
+		currency = sql.NullString{String: t.Fare.Currency, Valid: true}
+		amount = sql.NullInt64{Int64: t.Fare.Amount, Valid: true}
+	}
+	var tripID int64
+	var createdAt time.Time
+	if err := tx.QueryRowContext(ctx, `INSERT INTO trips (kind, passenger_name, fare_currency, fare_amount) VALUES ($1,$2,$3,$4) RETURNING id, created_at`, t.Kind, t.PassengerName, currency, amount).Scan(&tripID, &createdAt); err != nil {
+		return err
+	}
+
+	order := make([]int, len(t.Bookings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return t.Bookings[order[a]].ScheduleID < t.Bookings[order[b]].ScheduleID })
+	seats := make([]int, len(t.Bookings))
+	stamps := make([]time.Time, len(t.Bookings))
+	for _, i := range order {
+		t.Bookings[i].TripID = tripID
+		if seats[i], stamps[i], err = insertBooking(ctx, tx, &t.Bookings[i]); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	t.ID = tripID
+	t.CreatedAt = createdAt.Format(time.RFC3339)
+	for i := range t.Bookings {
+		t.Bookings[i].SeatNumber = seats[i]
+		t.Bookings[i].CreatedAt = stamps[i].Format(time.RFC3339)
+	}
+	return nil
+}
+
+func (r *TripRepository) GetByID(ctx context.Context, id int64) (*domain.Trip, error) {
+	var t domain.Trip
+	var currency sql.NullString
+	var amount sql.NullInt64
+	var createdAt time.Time
+	if err := r.db.QueryRowContext(ctx, `SELECT id, kind, passenger_name, fare_currency, fare_amount, created_at FROM trips WHERE id=$1`, id).Scan(&t.ID, &t.Kind, &t.PassengerName, &currency, &amount, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTripNotFound
+		}
+		return nil, err
+	}
+	if currency.Valid && amount.Valid {
+		t.Fare = domain.Money{Amount: amount.Int64, Currency: currency.String}
+	}
+	t.CreatedAt = createdAt.Format(time.RFC3339)
+
+	rows, err := r.db.QueryxContext(ctx, tripBookingsQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		b := domain.Booking{TripID: id}
+		var bookedAt time.Time
+		if err := rows.Scan(&b.ID, &b.Reference, &b.ScheduleID, &b.PassengerName, &b.SeatNumber, &b.Status, &bookedAt); err != nil {
+			return nil, err
+		}
+		b.CreatedAt = bookedAt.Format(time.RFC3339)
+		t.Bookings = append(t.Bookings, b)
+	}
+	return &t, rows.Err()
+}
+
+func (r *TripRepository) Connections(ctx context.Context, scheduleID int64) ([]domain.Connection, error) {
+	rows, err := r.db.QueryxContext(ctx, tripConnectionsQuery, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var items []domain.Connection
+	for rows.Next() {
+		var c domain.Connection
+		var inboundAt, onwardAt time.Time
+		in, on := &c.Inbound, &c.Onward
+		if err := rows.Scan(&in.ID, &in.Reference, &in.ScheduleID, &in.PassengerName, &in.SeatNumber, &in.Status, &inboundAt, &on.ID, &on.Reference, &on.ScheduleID, &on.PassengerName, &on.SeatNumber, &on.Status, &onwardAt, &c.AirportCode, &in.TripID); err != nil {
+			return nil, err
+		}
+		on.TripID = in.TripID
+		in.CreatedAt = inboundAt.Format(time.RFC3339)
+		on.CreatedAt = onwardAt.Format(time.RFC3339)
+		items = append(items, c)
+	}
+	return items, rows.Err()
+}
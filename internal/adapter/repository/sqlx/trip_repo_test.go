@@ -0,0 +1,113 @@
+package sqlxrepo
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func expectSeatClaim(mock sqlmock.Sqlmock, scheduleID int64, seat int, ref string, bookingID int64, now time.Time) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT i.capacity, i.sold, i.held, i.blocked, s.status FROM seat_inventory i JOIN flight_schedules s ON s.id = i.schedule_id WHERE i.schedule_id=$1 FOR UPDATE`)).
+		WithArgs(scheduleID).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity", "sold", "held", "blocked", "status"}).AddRow(10, 0, 0, 0, "SCHEDULED"))
+	mock.ExpectQuery(regexp.QuoteMeta(seatQuery)).
+		WithArgs(scheduleID, 10, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(seat))
+	mock.ExpectQuery(regexp.QuoteMeta(insertBookingQuery)).
+		WithArgs(ref, scheduleID, "Alice", seat, domain.BookingStatusConfirmed, int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(bookingID, now))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE seat_inventory SET sold=sold+$2, held=held+$3, updated_at=now() WHERE schedule_id=$1`)).
+		WithArgs(scheduleID, 1, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestTripRepository_CreateGet(t *testing.T) {
+	db, mock, cleanup := newMockBookingDB(t)
+	defer cleanup()
+	repo := NewTripRepository(db)
+	now := time.Now()
+
+	// The return flight has the lower schedule id, so its inventory row is locked first.
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO trips (kind, passenger_name, fare_currency, fare_amount) VALUES ($1,$2,$3,$4) RETURNING id, created_at`)).
+		WithArgs(domain.TripRoundTrip, "Alice", "USD", int64(18000)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, now))
+	expectSeatClaim(mock, 2, 3, "RETURN", 11, now)
+	expectSeatClaim(mock, 5, 1, "OUTBND", 12, now)
+	mock.ExpectCommit()
+
+	trip := &domain.Trip{Kind: domain.TripRoundTrip, PassengerName: "Alice", Fare: domain.Money{Amount: 18000, Currency: "USD"}, Bookings: []domain.Booking{
+		{Reference: "OUTBND", ScheduleID: 5, PassengerName: "Alice", SeatNumber: 1, Status: domain.BookingStatusConfirmed},
+		{Reference: "RETURN", ScheduleID: 2, PassengerName: "Alice", SeatNumber: 1, Status: domain.BookingStatusConfirmed},
+	}}
+	if err := repo.Create(context.Background(), trip); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if trip.ID != 7 || trip.Bookings[0].ID != 12 || trip.Bookings[1].SeatNumber != 3 || trip.Bookings[1].TripID != 7 {
+		t.Fatalf("unexpected trip %+v", trip)
+	}
+
+	// A full return flight rolls back the whole trip.
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO trips (kind, passenger_name, fare_currency, fare_amount) VALUES ($1,$2,$3,$4) RETURNING id, created_at`)).
+		WithArgs(domain.TripConnecting, "Alice", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(8, now))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT i.capacity, i.sold, i.held, i.blocked, s.status FROM seat_inventory i JOIN flight_schedules s ON s.id = i.schedule_id WHERE i.schedule_id=$1 FOR UPDATE`)).
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity", "sold", "held", "blocked", "status"}).AddRow(10, 10, 0, 0, "SCHEDULED"))
+	mock.ExpectRollback()
+	full := &domain.Trip{Kind: domain.TripConnecting, PassengerName: "Alice", Bookings: []domain.Booking{{Reference: "RETURN", ScheduleID: 2, PassengerName: "Alice", SeatNumber: 1, Status: domain.BookingStatusConfirmed}}}
+	if err := repo.Create(context.Background(), full); err != domain.ErrFlightFull || full.ID != 0 {
+		t.Fatalf("want flight full without a trip id, got %v id=%d", err, full.ID)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, kind, passenger_name, fare_currency, fare_amount, created_at FROM trips WHERE id=$1`)).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "passenger_name", "fare_currency", "fare_amount", "created_at"}).AddRow(7, domain.TripRoundTrip, "Alice", "USD", 18000, now))
+	mock.ExpectQuery(regexp.QuoteMeta(tripBookingsQuery)).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reference", "schedule_id", "passenger_name", "seat_number", "status", "created_at"}).
+			AddRow(12, "OUTBND", 5, "Alice", 1, domain.BookingStatusConfirmed, now).
+			AddRow(11, "RETURN", 2, "Alice", 3, domain.BookingStatusConfirmed, now))
+	got, err := repo.GetByID(context.Background(), 7)
+	if err != nil || got.Fare.Amount != 18000 || got.Kind != domain.TripRoundTrip || len(got.Bookings) != 2 || got.Bookings[0].Reference != "OUTBND" || got.Bookings[1].TripID != 7 {
+		t.Fatalf("unexpected trip %+v err=%v", got, err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, kind, passenger_name, fare_currency, fare_amount, created_at FROM trips WHERE id=$1`)).
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "passenger_name", "fare_currency", "fare_amount", "created_at"}))
+	if _, err := repo.GetByID(context.Background(), 9); err != domain.ErrTripNotFound {
+		t.Fatalf("want trip not found, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTripRepository_Connections(t *testing.T) {
+	db, mock, cleanup := newMockBookingDB(t)
+	defer cleanup()
+	repo := NewTripRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(tripConnectionsQuery)).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reference", "schedule_id", "passenger_name", "seat_number", "status", "created_at", "n_id", "n_reference", "n_schedule_id", "n_passenger_name", "n_seat_number", "n_status", "n_created_at", "destination_code", "trip_id"}).
+			AddRow(11, "INBND1", 5, "Alice", 3, domain.BookingStatusConfirmed, now, 12, "ONWRD1", 6, "Alice", 7, domain.BookingStatusConfirmed, now, "DPS", 7))
+	conns, err := repo.Connections(context.Background(), 5)
+	if err != nil || len(conns) != 1 {
+		t.Fatalf("connections: err=%v got=%+v", err, conns)
+	}
+	c := conns[0]
+	if c.Inbound.Reference != "INBND1" || c.Onward.ScheduleID != 6 || c.AirportCode != "DPS" || c.Onward.TripID != 7 {
+		t.Fatalf("unexpected connection %+v", c)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,412 @@
+// Package ssim reads and writes IATA SSIM Chapter 7 schedule files: fixed-width
+// 200-byte records where type 2 opens a carrier's data and each type 3 record
+// is one flight leg repeated over a period on selected days.
+package ssim
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+)
+
+// RecordLength is the width of every SSIM record.
+const RecordLength = 200
+
+// dateLayout is the SSIM DDMMMYY date, written in upper case.
+const dateLayout = "02Jan06"
+
+// ErrInvalidAirline reports an airline designator that is not two or three letters or digits.
+var ErrInvalidAirline = errors.New("invalid airline designator")
+
+// Decode reads type 2 and type 3 records into timetable entries. Header,
+// segment, trailer and zero-filled padding records are skipped. Records that
+// cannot be read are returned as line errors; only read failures abort.
+func Decode(r io.Reader) ([]usecase.TimetableEntry, []usecase.ImportError, error) {
+	var entries []usecase.TimetableEntry
+	var problems []usecase.ImportError
+	timeMode := byte(0)
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimRight(sc.Text(), " \r")
+		if text == "" {
+			continue
+		}
+		switch text[0] {
+		case '2':
+			if len(text) < 2 || (text[1] != 'U' && text[1] != 'L') {
+				problems = append(problems, usecase.ImportError{Line: line, Err: fmt.Errorf("carrier record: time mode must be U or L")})
+				timeMode = 0
+				continue
+			}
+			timeMode = text[1]
+		case '3':
+			if timeMode == 0 {
+				problems = append(problems, usecase.ImportError{Line: line, Err: fmt.Errorf("flight leg without a valid carrier record")})
+				continue
+			}
+			e, err := decodeLeg(pad(text), timeMode == 'U')
+			if err != nil {
+				problems = append(problems, usecase.ImportError{Line: line, Err: err})
+				continue
+			}
+			e.Line = line
+			entries = append(entries, e)
+		case '0', '1', '4', '5':
+		default:
+			problems = append(problems, usecase.ImportError{Line: line, Err: fmt.Errorf("unknown record type %q", text[0])})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+	return entries, problems, nil
+}
+
+// decodeLeg reads a type 3 record. With utc set, its times are UTC and are
+// moved to local time, together with the period and days when that changes
+// the departure date.
+func decodeLeg(rec string, utc bool) (usecase.TimetableEntry, error) {
+	var e usecase.TimetableEntry
+	from, err := parseDate(field(rec, 15, 21))
+	if err != nil {
+		return e, fmt.Errorf("period from: %w", err)
+	}
+	to, err := parseDate(field(rec, 22, 28))
+	if err != nil {
+		return e, fmt.Errorf("period to: %w", err)
+	}
+	days, err := parseDays(rec[28:35])
+	if err != nil {
+		return e, err
+	}
+	if rate := rec[35]; rate != ' ' && rate != '1' {
+		return e, fmt.Errorf("frequency rate %q is not supported", rate)
+	}
+	departure, err := parseClock(field(rec, 40, 43), field(rec, 48, 52), utc)
+	if err != nil {
+		return e, fmt.Errorf("departure time: %w", err)
+	}
+	e.OriginCode = field(rec, 37, 39)
+	e.DestinationCode = field(rec, 55, 57)
+	e.AircraftType = field(rec, 73, 75)
+	if e.AircraftType == "" {
+		return e, fmt.Errorf("missing aircraft type")
+	}
+	e.Series = domain.ScheduleSeries{
+		StartDate:     from.AddDate(0, 0, departure.dayShift).Format("2006-01-02"),
+		EndDate:       to.AddDate(0, 0, departure.dayShift).Format("2006-01-02"),
+		Days:          shiftDays(days, departure.dayShift),
+		DepartureTime: departure.clock,
+	}
+	if sta := field(rec, 62, 65); sta != "" {
+		arrival, err := parseClock(sta, field(rec, 66, 70), utc)
+		if err != nil {
+			return e, fmt.Errorf("arrival time: %w", err)
+		}
+		e.Series.ArrivalTime = arrival.clock
+	}
+	return e, nil
+}
+
+// Encode writes a complete data set for the airline: header, carrier, one
+// type 3 record per entry and a trailer, each block padded with zero records
+// to a multiple of five. Flight numbers are assigned in entry order. Times
+// are local, with the UTC offset of each entry's first flight.
+func Encode(w io.Writer, airline string, created time.Time, entries []usecase.TimetableEntry) error {
+	airline = strings.ToUpper(strings.TrimSpace(airline))
+	if !validAirline(airline) {
+		return ErrInvalidAirline
+	}
+	if len(entries) > 9999 {
+		return fmt.Errorf("ssim: %d entries exceed the four-digit flight number range", len(entries))
+	}
+	enc := &encoder{w: bufio.NewWriter(w)}
+
+	header := newRecord('1')
+	header.put(2, "AIRLINE STANDARD SCHEDULE DATA SET")
+	enc.write(header)
+	enc.padBlock()
+
+	first, last := "", ""
+	for _, e := range entries {
+		if first == "" || e.Series.StartDate < first {
+			first = e.Series.StartDate
+		}
+		if e.Series.EndDate > last {
+			last = e.Series.EndDate
+		}
+	}
+	carrier := newRecord('2')
+	carrier.put(2, "L")
+	carrier.put(3, airline)
+	if first != "" {
+		carrier.put(15, formatDate(first)+formatDate(last))
+	}
+	carrier.put(29, strings.ToUpper(created.Format(dateLayout)))
+	carrier.put(36, "FLIGHT-BOOKING TIMETABLE")
+	enc.write(carrier)
+	enc.padBlock()
+
+	for i, e := range entries {
+		rec, err := encodeLeg(airline, i+1, e)
+		if err != nil {
+			return err
+		}
+		enc.write(rec)
+	}
+	lastLeg := enc.serial
+	enc.padBlock()
+
+	trailer := newRecord('5')
+	trailer.put(3, airline)
+	trailer.put(188, fmt.Sprintf("%06d", lastLeg))
+	trailer.put(194, "E")
+	enc.write(trailer)
+	enc.padBlock()
+	if enc.err != nil {
+		return enc.err
+	}
+	return enc.w.Flush()
+}
+
+func encodeLeg(airline string, flightNumber int, e usecase.TimetableEntry) (*record, error) {
+	s := e.Series
+	for _, code := range []string{e.OriginCode, e.DestinationCode} {
+		if len(code) != 3 {
+			return nil, fmt.Errorf("ssim: airport %s is not a three-letter station code", code)
+		}
+	}
+	if e.AircraftType == "" {
+		return nil, fmt.Errorf("ssim: airplane %s has no aircraft type with an IATA code", s.AirplaneCode)
+	}
+	if len(e.AircraftType) != 3 {
+		return nil, fmt.Errorf("ssim: aircraft type %s of airplane %s is not a three-character IATA code", e.AircraftType, s.AirplaneCode)
+	}
+	rec := newRecord('3')
+	rec.put(3, airline)
+	rec.put(6, fmt.Sprintf("%04d", flightNumber))
+	rec.put(10, "0101J")
+	rec.put(15, formatDate(s.StartDate)+formatDate(s.EndDate))
+	rec.put(29, formatDays(s.Days))
+	rec.put(37, e.OriginCode)
+	std := strings.Replace(s.DepartureTime, ":", "", 1)
+	rec.put(40, std+std+formatOffset(e.FirstDeparture))
+	rec.put(55, e.DestinationCode)
+	rec.put(73, e.AircraftType)
+	rec.put(193, "0")
+	if !e.FirstArrival.IsZero() {
+		sta := strings.Replace(s.ArrivalTime, ":", "", 1)
+		rec.put(58, sta+sta+formatOffset(e.FirstArrival))
+		rec.put(194, formatDateVariation(e.FirstDeparture, e.FirstArrival))
+	}
+	return rec, nil
+}
+
+type record [RecordLength]byte
+
+func newRecord(kind byte) *record {
+	var r record
+	for i := range r {
+		r[i] = ' '
+	}
+	r[0] = kind
+	return &r
+}
+
+// put writes s starting at the 1-based column col, as the standard numbers them.
+func (r *record) put(col int, s string) {
+	copy(r[col-1:], s)
+}
+
+type encoder struct {
+	w      *bufio.Writer
+	serial int
+	err    error
+}
+
+// write numbers the record and writes it as one line.
+func (e *encoder) write(r *record) {
+	e.serial++
+	if r[0] != '0' {
+		r.put(195, fmt.Sprintf("%06d", e.serial))
+	}
+	if e.err == nil {
+		_, e.err = e.w.Write(append(r[:], '\n'))
+	}
+}
+
+// padBlock fills the current block of five records with zero records.
+func (e *encoder) padBlock() {
+	for e.serial%5 != 0 {
+		var zero record
+		for i := range zero {
+			zero[i] = '0'
+		}
+		e.write(&zero)
+	}
+}
+
+func validAirline(s string) bool {
+	if len(s) < 2 || len(s) > 3 {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// pad extends a record whose trailing spaces were trimmed.
+func pad(s string) string {
+	if len(s) >= RecordLength {
+		return s
+	}
+	return s + strings.Repeat(" ", RecordLength-len(s))
+}
+
+// field returns the trimmed 1-based inclusive column range.
+func field(rec string, from, to int) string {
+	return strings.TrimSpace(rec[from-1 : to])
+}
+
+func parseDate(s string) (time.Time, error) {
+	if strings.HasPrefix(s, "00") {
+		return time.Time{}, fmt.Errorf("open-ended period %q is not supported", s)
+	}
+	d, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return d, nil
+}
+
+func formatDate(iso string) string {
+	d, err := time.Parse("2006-01-02", iso)
+	if err != nil {
+		return ""
+	}
+	return strings.ToUpper(d.Format(dateLayout))
+}
+
+// parseDays reads the seven day-of-operation columns, where column n holds the
+// digit n on days flown and Monday is 1.
+func parseDays(s string) (domain.Weekdays, error) {
+	var days domain.Weekdays
+	for i := 0; i < 7; i++ {
+		switch s[i] {
+		case ' ':
+		case byte('1' + i):
+			days |= 1 << ((i + 1) % 7)
+		default:
+			return 0, fmt.Errorf("invalid days of operation %q", s)
+		}
+	}
+	if days == 0 {
+		return 0, fmt.Errorf("no days of operation")
+	}
+	return days, nil
+}
+
+func formatDays(days domain.Weekdays) string {
+	out := []byte("       ")
+	for i := 0; i < 7; i++ {
+		if days.Has(time.Weekday((i + 1) % 7)) {
+			out[i] = byte('1' + i)
+		}
+	}
+	return string(out)
+}
+
+// shiftDays moves every day in the set n days later, or earlier when negative.
+func shiftDays(days domain.Weekdays, n int) domain.Weekdays {
+	n = ((n % 7) + 7) % 7
+	var out domain.Weekdays
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if days.Has(d) {
+			out |= 1 << ((int(d) + n) % 7)
+		}
+	}
+	return out
+}
+
+type clock struct {
+	clock    string // HH:MM local
+	dayShift int    // days the local time lies after the record's date
+}
+
+// parseClock reads an HHMM time. With utc set, the time is UTC and the
+// +HHMM/-HHMM variation moves it to local time.
+func parseClock(hhmm, variation string, utc bool) (clock, error) {
+	t, err := time.Parse("1504", hhmm)
+	if err != nil {
+		return clock{}, fmt.Errorf("invalid time %q", hhmm)
+	}
+	minutes := t.Hour()*60 + t.Minute()
+	if utc {
+		offset, err := parseOffset(variation)
+		if err != nil {
+			return clock{}, err
+		}
+		minutes += offset
+	}
+	shift := 0
+	for minutes < 0 {
+		minutes += 24 * 60
+		shift--
+	}
+	for minutes >= 24*60 {
+		minutes -= 24 * 60
+		shift++
+	}
+	return clock{clock: fmt.Sprintf("%02d:%02d", minutes/60, minutes%60), dayShift: shift}, nil
+}
+
+// parseOffset reads a UTC/local time variation such as +0700 in minutes.
+func parseOffset(s string) (int, error) {
+	if len(s) != 5 || (s[0] != '+' && s[0] != '-') {
+		return 0, fmt.Errorf("invalid UTC variation %q", s)
+	}
+	h, errH := strconv.Atoi(s[1:3])
+	m, errM := strconv.Atoi(s[3:5])
+	if errH != nil || errM != nil || m >= 60 {
+		return 0, fmt.Errorf("invalid UTC variation %q", s)
+	}
+	minutes := h*60 + m
+	if s[0] == '-' {
+		minutes = -minutes
+	}
+	return minutes, nil
+}
+
+func formatOffset(t time.Time) string {
+	_, seconds := t.Zone()
+	sign := '+'
+	if seconds < 0 {
+		sign, seconds = '-', -seconds
+	}
+	minutes := seconds / 60
+	return fmt.Sprintf("%c%02d%02d", sign, minutes/60, minutes%60)
+}
+
+// formatDateVariation is the arrival's local date relative to the departure's:
+// a digit for later days or A for the day before.
+func formatDateVariation(departure, arrival time.Time) string {
+	dy, dm, dd := departure.Date()
+	ay, am, ad := arrival.Date()
+	days := int(time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC).Sub(time.Date(dy, dm, dd, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	if days < 0 {
+		return "A"
+	}
+	return strconv.Itoa(days)
+}
@@ -0,0 +1,160 @@
+package ssim
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+	"github.com/ambiyansyah-risyal/flight-booking/internal/usecase"
+)
+
+// leg builds a type 3 record from 1-based column values.
+func leg(fields map[int]string) string {
+	r := newRecord('3')
+	for col, v := range fields {
+		r.put(col, v)
+	}
+	return string(r[:])
+}
+
+func TestDecode_LocalAndUTC(t *testing.T) {
+	local := leg(map[int]string{3: "ZZ", 6: "0001", 15: "01JAN25", 22: "31JAN25", 29: "1 3 5  ", 37: "CGK", 40: "08300830+0700", 55: "DPS", 58: "11201120+0800", 73: "320"})
+	// 23:30 UTC on Sundays is 06:30 on Mondays in Jakarta.
+	utc := leg(map[int]string{3: "ZZ", 6: "0002", 15: "05JAN25", 22: "26JAN25", 29: "      7", 37: "CGK", 40: "23302330+0700", 55: "DPS", 73: "320"})
+	input := strings.Join([]string{
+		string(newRecord('1')[:]),
+		"2LZZ",
+		local,
+		"2UZZ",
+		strings.TrimRight(utc, " "),
+		strings.Repeat("0", RecordLength),
+	}, "\n")
+
+	entries, problems, err := Decode(strings.NewReader(input))
+	if err != nil || len(problems) != 0 {
+		t.Fatalf("decode: problems=%v err=%v", problems, err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("want two entries, got %+v", entries)
+	}
+	first := entries[0]
+	want := domain.ScheduleSeries{StartDate: "2025-01-01", EndDate: "2025-01-31", Days: 1<<time.Monday | 1<<time.Wednesday | 1<<time.Friday, DepartureTime: "08:30", ArrivalTime: "11:20"}
+	if first.Line != 3 || first.OriginCode != "CGK" || first.DestinationCode != "DPS" || first.AircraftType != "320" || first.Series != want {
+		t.Fatalf("unexpected local entry %+v", first)
+	}
+	second := entries[1]
+	if second.Line != 5 || second.Series.StartDate != "2025-01-06" || second.Series.EndDate != "2025-01-27" || second.Series.Days.String() != "Mon" || second.Series.DepartureTime != "06:30" || second.Series.ArrivalTime != "" {
+		t.Fatalf("expected the UTC leg moved to local Mondays, got %+v", second.Series)
+	}
+}
+
+func TestDecode_LineErrors(t *testing.T) {
+	good := leg(map[int]string{3: "ZZ", 15: "01JAN25", 22: "31JAN25", 29: "1234567", 37: "CGK", 40: "0830", 55: "DPS", 73: "320"})
+	input := strings.Join([]string{
+		good,
+		"2LZZ",
+		leg(map[int]string{15: "01JAN25", 22: "00XXX00", 29: "1234567", 37: "CGK", 40: "0830", 55: "DPS"}),
+		leg(map[int]string{15: "01JAN25", 22: "31JAN25", 29: "1x     ", 37: "CGK", 40: "0830", 55: "DPS"}),
+		leg(map[int]string{15: "01JAN25", 22: "31JAN25", 29: "1234567", 36: "2", 37: "CGK", 40: "0830", 55: "DPS"}),
+		leg(map[int]string{15: "01JAN25", 22: "31JAN25", 29: "1234567", 37: "CGK", 40: "2561", 55: "DPS"}),
+		"9 what is this",
+		leg(map[int]string{15: "01JAN25", 22: "31JAN25", 29: "1234567", 37: "CGK", 40: "0830", 55: "DPS"}),
+		good,
+	}, "\n")
+
+	entries, problems, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Line != 9 {
+		t.Fatalf("want only the last leg decoded, got %+v", entries)
+	}
+	wantLines := []int{1, 3, 4, 5, 6, 7, 8}
+	if len(problems) != len(wantLines) {
+		t.Fatalf("want %d problems, got %v", len(wantLines), problems)
+	}
+	for i, p := range problems {
+		if p.Line != wantLines[i] {
+			t.Fatalf("problem %d on line %d, want %d: %v", i, p.Line, wantLines[i], p)
+		}
+	}
+	if !strings.Contains(problems[1].Error(), "open-ended") || !strings.Contains(problems[3].Error(), "frequency rate") ||
+		!strings.Contains(problems[6].Error(), "missing aircraft type") {
+		t.Fatalf("unexpected messages %v", problems)
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+	makassar, _ := time.LoadLocation("Asia/Makassar")
+	entries := []usecase.TimetableEntry{
+		{
+			OriginCode: "CGK", DestinationCode: "DPS",
+			AircraftType:   "320",
+			Series:         domain.ScheduleSeries{AirplaneCode: "PK-GQA", StartDate: "2025-01-01", EndDate: "2025-01-31", Days: 1<<time.Monday | 1<<time.Friday, DepartureTime: "23:30", ArrivalTime: "02:20"},
+			FirstDeparture: time.Date(2025, 1, 3, 23, 30, 0, 0, jakarta),
+			FirstArrival:   time.Date(2025, 1, 4, 2, 20, 0, 0, makassar),
+		},
+		{
+			OriginCode: "DPS", DestinationCode: "CGK",
+			AircraftType:   "320",
+			Series:         domain.ScheduleSeries{AirplaneCode: "PK-GQA", StartDate: "2025-02-02", EndDate: "2025-02-02", Days: 1 << time.Sunday, DepartureTime: "07:00"},
+			FirstDeparture: time.Date(2025, 2, 2, 7, 0, 0, 0, makassar),
+		},
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, "zz", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), entries); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 20 {
+		t.Fatalf("want four padded blocks of five records, got %d", len(lines))
+	}
+	for i, l := range lines {
+		if len(l) != RecordLength {
+			t.Fatalf("record %d is %d bytes", i+1, len(l))
+		}
+	}
+	if lines[5][:30] != "2LZZ          01JAN2502FEB2501" || lines[6] != strings.Repeat("0", RecordLength) {
+		t.Fatalf("unexpected carrier block %q / %q", lines[5][:30], lines[6][:10])
+	}
+	first := lines[10]
+	if first[:75] != "3 ZZ 00010101J01JAN2531JAN251   5   CGK23302330+0700  DPS02200220+0800  320" || first[192:] != "01000011" {
+		t.Fatalf("unexpected leg %q ... %q", first[:75], first[192:])
+	}
+	if trailer := lines[15]; trailer[:5] != "5 ZZ " || trailer[187:] != "000012E000016" {
+		t.Fatalf("unexpected trailer %q", trailer[187:])
+	}
+
+	decoded, problems, err := Decode(&buf)
+	if err != nil || len(problems) != 0 || len(decoded) != 2 {
+		t.Fatalf("decode: %+v problems=%v err=%v", decoded, problems, err)
+	}
+	for i, d := range decoded {
+		want := entries[i].Series
+		want.AirplaneCode = ""
+		if d.Series != want || d.AircraftType != entries[i].AircraftType || d.OriginCode != entries[i].OriginCode || d.DestinationCode != entries[i].DestinationCode {
+			t.Fatalf("entry %d did not round trip: %+v", i, d)
+		}
+	}
+}
+
+func TestEncode_Rejects(t *testing.T) {
+	entry := usecase.TimetableEntry{OriginCode: "CGK", DestinationCode: "DPS", Series: domain.ScheduleSeries{AirplaneCode: "PK-GQA", StartDate: "2025-01-01", EndDate: "2025-01-01", Days: 1 << time.Wednesday, DepartureTime: "08:00"}}
+	if err := Encode(&bytes.Buffer{}, "Z", time.Now(), nil); err != ErrInvalidAirline {
+		t.Fatalf("want invalid airline, got %v", err)
+	}
+	if err := Encode(&bytes.Buffer{}, "ZZ", time.Now(), []usecase.TimetableEntry{entry}); err == nil || !strings.Contains(err.Error(), "PK-GQA has no aircraft type") {
+		t.Fatalf("want untyped airplane rejected, got %v", err)
+	}
+	entry.AircraftType = "A320"
+	if err := Encode(&bytes.Buffer{}, "ZZ", time.Now(), []usecase.TimetableEntry{entry}); err == nil || !strings.Contains(err.Error(), "three-character IATA code") {
+		t.Fatalf("want ICAO designator rejected, got %v", err)
+	}
+	entry.AircraftType, entry.OriginCode = "320", "WIII"
+	if err := Encode(&bytes.Buffer{}, "ZZ", time.Now(), []usecase.TimetableEntry{entry}); err == nil || !strings.Contains(err.Error(), "station code") {
+		t.Fatalf("want four-letter airport rejected, got %v", err)
+	}
+}
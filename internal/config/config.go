@@ -14,7 +14,40 @@ import (
 )
 
 type Config struct {
-    Database DatabaseConfig `mapstructure:"db"`
+    Database  DatabaseConfig  `mapstructure:"db"`
+    Ticketing TicketingConfig `mapstructure:"ticketing"`
+    Booking   BookingConfig   `mapstructure:"booking"`
+    Transit   TransitConfig   `mapstructure:"transit"`
+    Rotation  RotationConfig  `mapstructure:"rotation"`
+}
+
+// RotationConfig governs how an airplane's consecutive flights must chain.
+type RotationConfig struct {
+    MinTurnaround time.Duration `mapstructure:"min_turnaround"`
+    // Strict rejects new schedules that break their airplane's rotation.
+    Strict bool `mapstructure:"strict"`
+}
+
+// TransitConfig bounds the layover accepted between connecting flights.
+type TransitConfig struct {
+    // MinConnection applies at airports without their own minimum connection time.
+    MinConnection time.Duration `mapstructure:"min_connection"`
+    MaxLayover    time.Duration `mapstructure:"max_layover"`
+    // MaxCircuity caps the distance flown through connections as a multiple of the direct distance; 0 disables it.
+    MaxCircuity float64 `mapstructure:"max_circuity"`
+}
+
+// BookingConfig controls how booking record locators are generated.
+type BookingConfig struct {
+    ReferencePrefix   string `mapstructure:"reference_prefix"`
+    ReferenceLength   int    `mapstructure:"reference_length"`
+    ReferenceAlphabet string `mapstructure:"reference_alphabet"`
+}
+
+// TicketingConfig controls e-ticket numbering.
+type TicketingConfig struct {
+    // AirlinePrefix is the 3-digit accounting code that starts every ticket number.
+    AirlinePrefix string `mapstructure:"airline_prefix"`
 }
 
 type DatabaseConfig struct {
@@ -68,6 +101,15 @@ func Load() (*Config, error) {
     v.SetDefault("db.max_idle_conns", 10)
     v.SetDefault("db.conn_max_lifetime", "30m")
     v.SetDefault("db.conn_max_idle_time", "5m")
+    v.SetDefault("ticketing.airline_prefix", "126")
+    v.SetDefault("booking.reference_prefix", "")
+    v.SetDefault("booking.reference_length", 6)
+    v.SetDefault("booking.reference_alphabet", "23456789ABCDEFGHJKLMNPQRSTUVWXYZ")
+    v.SetDefault("transit.min_connection", "45m")
+    v.SetDefault("transit.max_layover", "24h")
+    v.SetDefault("transit.max_circuity", 2.0)
+    v.SetDefault("rotation.min_turnaround", "30m")
+    v.SetDefault("rotation.strict", false)
 
     // Config file discovery: flag may set it externally (root.go), otherwise search
     if v.ConfigFileUsed() == "" {
@@ -125,6 +167,18 @@ func validate(c *Config) error {
     default:
         return fmt.Errorf("db.sslmode invalid: %s", c.Database.SSLMode)
     }
+    if p := c.Ticketing.AirlinePrefix; len(p) != 3 || strings.Trim(p, "0123456789") != "" {
+        return fmt.Errorf("ticketing.airline_prefix invalid: %s", p)
+    }
+    if c.Transit.MinConnection < 0 || c.Transit.MaxLayover <= 0 || c.Transit.MaxLayover < c.Transit.MinConnection {
+        return fmt.Errorf("transit window invalid: min_connection=%s max_layover=%s", c.Transit.MinConnection, c.Transit.MaxLayover)
+    }
+    if c.Transit.MaxCircuity != 0 && c.Transit.MaxCircuity < 1 {
+        return fmt.Errorf("transit.max_circuity invalid: %g", c.Transit.MaxCircuity)
+    }
+    if c.Rotation.MinTurnaround < 0 {
+        return fmt.Errorf("rotation.min_turnaround invalid: %s", c.Rotation.MinTurnaround)
+    }
     return nil
 }
 
@@ -3,6 +3,7 @@ package config
 import (
     "os"
     "testing"
+    "time"
 )
 
 func TestDefaultsAndDSN(t *testing.T) {
@@ -104,3 +105,53 @@ func TestDiscoverConfig_NoFile(t *testing.T) {
         t.Fatalf("expected no files discovered, got %v", got)
     }
 }
+
+func TestTicketingAirlinePrefix(t *testing.T) {
+    t.Setenv("FLIGHT_DB_HOST", "localhost")
+    cfg, err := Load()
+    if err != nil { t.Fatalf("load: %v", err) }
+    if cfg.Ticketing.AirlinePrefix != "126" {
+        t.Fatalf("expected default airline prefix, got %q", cfg.Ticketing.AirlinePrefix)
+    }
+    t.Setenv("FLIGHT_TICKETING_AIRLINE_PREFIX", "12A")
+    if _, err := Load(); err == nil {
+        t.Fatalf("expected airline prefix validation error")
+    }
+}
+
+func TestTransitWindow(t *testing.T) {
+    t.Setenv("FLIGHT_DB_HOST", "localhost")
+    cfg, err := Load()
+    if err != nil { t.Fatalf("load: %v", err) }
+    if cfg.Transit.MinConnection != 45*time.Minute || cfg.Transit.MaxLayover != 24*time.Hour || cfg.Transit.MaxCircuity != 2 {
+        t.Fatalf("unexpected transit defaults: %+v", cfg.Transit)
+    }
+    t.Setenv("FLIGHT_TRANSIT_MAX_CIRCUITY", "0.5")
+    if _, err := Load(); err == nil {
+        t.Fatalf("expected circuity below the direct distance rejected")
+    }
+    t.Setenv("FLIGHT_TRANSIT_MAX_CIRCUITY", "0")
+    t.Setenv("FLIGHT_TRANSIT_MIN_CONNECTION", "3h")
+    t.Setenv("FLIGHT_TRANSIT_MAX_LAYOVER", "2h")
+    if _, err := Load(); err == nil {
+        t.Fatalf("expected transit window validation error")
+    }
+}
+
+func TestRotationConfig(t *testing.T) {
+    t.Setenv("FLIGHT_DB_HOST", "localhost")
+    cfg, err := Load()
+    if err != nil { t.Fatalf("load: %v", err) }
+    if cfg.Rotation.MinTurnaround != 30*time.Minute || cfg.Rotation.Strict {
+        t.Fatalf("unexpected rotation defaults: %+v", cfg.Rotation)
+    }
+    t.Setenv("FLIGHT_ROTATION_STRICT", "true")
+    t.Setenv("FLIGHT_ROTATION_MIN_TURNAROUND", "45m")
+    if cfg, err = Load(); err != nil || !cfg.Rotation.Strict || cfg.Rotation.MinTurnaround != 45*time.Minute {
+        t.Fatalf("unexpected rotation config %+v err=%v", cfg, err)
+    }
+    t.Setenv("FLIGHT_ROTATION_MIN_TURNAROUND", "-1m")
+    if _, err := Load(); err == nil {
+        t.Fatalf("expected rotation validation error")
+    }
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maxSeatsAbreast bounds how many seats one row of a seat map may hold.
+const maxSeatsAbreast = 10
+
+// AircraftType is a catalog entry for an aircraft model. Airplanes are
+// individual airframes, identified by their registration, that reference a
+// type by its ICAO designator.
+type AircraftType struct {
+	ID           int64
+	ICAOCode     string // ICAO type designator such as "A320"; the catalog key
+	IATACode     string // IATA type code such as "320"
+	Manufacturer string
+	Model        string
+	RangeKm      int
+	SeatMap      SeatMap // default cabin for new airplanes of this type
+	CreatedAt    string
+}
+
+// SeatMap describes a single-class cabin: Rows rows of seats grouped between
+// aisles as in Layout, for example "3-3" or "2-4-2".
+type SeatMap struct {
+	Rows   int
+	Layout string
+}
+
+// Abreast is the number of seats in one row, or zero when Layout is malformed.
+func (m SeatMap) Abreast() int {
+	total := 0
+	for _, group := range strings.Split(m.Layout, "-") {
+		n, err := strconv.Atoi(group)
+		if err != nil || n <= 0 {
+			return 0
+		}
+		total += n
+	}
+	return total
+}
+
+// Seats is the seat count of the map, or zero when it is malformed.
+func (m SeatMap) Seats() int { return m.Rows * m.Abreast() }
+
+func (m SeatMap) String() string { return strconv.Itoa(m.Rows) + "x" + m.Layout }
+
+// Name is the manufacturer and model, as shown to travellers.
+func (t AircraftType) Name() string {
+	return strings.TrimSpace(t.Manufacturer + " " + t.Model)
+}
+
+func (t *AircraftType) Normalize() {
+	t.ICAOCode = strings.ToUpper(strings.TrimSpace(t.ICAOCode))
+	t.IATACode = strings.ToUpper(strings.TrimSpace(t.IATACode))
+	t.Manufacturer = strings.TrimSpace(t.Manufacturer)
+	t.Model = strings.TrimSpace(t.Model)
+	t.SeatMap.Layout = strings.TrimSpace(t.SeatMap.Layout)
+}
+
+func (t AircraftType) Validate() error {
+	if !typeCode(t.ICAOCode, 2, 4) {
+		return ErrInvalidAircraftTypeCode
+	}
+	if !typeCode(t.IATACode, 3, 3) {
+		return ErrInvalidAircraftTypeCode
+	}
+	if t.Manufacturer == "" || t.Model == "" || len(t.Manufacturer) > 64 || len(t.Model) > 64 {
+		return ErrInvalidAircraftTypeName
+	}
+	if t.RangeKm <= 0 {
+		return ErrInvalidAircraftRange
+	}
+	if abreast := t.SeatMap.Abreast(); t.SeatMap.Rows <= 0 || abreast == 0 || abreast > maxSeatsAbreast {
+		return ErrInvalidSeatMap
+	}
+	return nil
+}
+
+// typeCode reports whether code is between min and max letters or digits.
+func typeCode(code string, min, max int) bool {
+	if len(code) < min || len(code) > max {
+		return false
+	}
+	return strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") == ""
+}
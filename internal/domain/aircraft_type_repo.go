@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+// AircraftTypeRepository stores the aircraft type catalog.
+type AircraftTypeRepository interface {
+	Create(ctx context.Context, t *AircraftType) error
+	GetByCode(ctx context.Context, icaoCode string) (*AircraftType, error)
+	List(ctx context.Context, limit, offset int) ([]AircraftType, error)
+	// Delete fails with ErrAircraftTypeInUse while airplanes reference the type.
+	Delete(ctx context.Context, icaoCode string) error
+}
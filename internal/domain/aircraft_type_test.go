@@ -0,0 +1,48 @@
+package domain
+
+import "testing"
+
+func TestSeatMap(t *testing.T) {
+	for _, tc := range []struct {
+		m     SeatMap
+		seats int
+	}{
+		{SeatMap{Rows: 30, Layout: "3-3"}, 180},
+		{SeatMap{Rows: 20, Layout: "2-4-2"}, 160},
+		{SeatMap{Rows: 18, Layout: "2-2"}, 72},
+		{SeatMap{Rows: 10, Layout: "3-"}, 0},
+		{SeatMap{Rows: 10, Layout: "abc"}, 0},
+	} {
+		if got := tc.m.Seats(); got != tc.seats {
+			t.Fatalf("%s: want %d seats, got %d", tc.m, tc.seats, got)
+		}
+	}
+}
+
+func TestAircraftTypeNormalizeAndValidate(t *testing.T) {
+	valid := AircraftType{ICAOCode: " b738 ", IATACode: "73h", Manufacturer: "Boeing", Model: "737-800", RangeKm: 5400, SeatMap: SeatMap{Rows: 32, Layout: "3-3"}}
+	valid.Normalize()
+	if valid.ICAOCode != "B738" || valid.IATACode != "73H" || valid.Name() != "Boeing 737-800" {
+		t.Fatalf("unexpected normalized type %+v", valid)
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	for _, tc := range []struct {
+		mutate func(*AircraftType)
+		want   error
+	}{
+		{func(a *AircraftType) { a.ICAOCode = "B7-8" }, ErrInvalidAircraftTypeCode},
+		{func(a *AircraftType) { a.IATACode = "7378" }, ErrInvalidAircraftTypeCode},
+		{func(a *AircraftType) { a.Model = "" }, ErrInvalidAircraftTypeName},
+		{func(a *AircraftType) { a.RangeKm = 0 }, ErrInvalidAircraftRange},
+		{func(a *AircraftType) { a.SeatMap.Rows = 0 }, ErrInvalidSeatMap},
+		{func(a *AircraftType) { a.SeatMap.Layout = "4-4-4" }, ErrInvalidSeatMap},
+	} {
+		a := valid
+		tc.mutate(&a)
+		if err := a.Validate(); err != tc.want {
+			t.Fatalf("%+v: want %v, got %v", a, tc.want, err)
+		}
+	}
+}
@@ -4,15 +4,27 @@ import (
     "strings"
 )
 
+// Airplane is a single airframe. Code is its registration, such as "PK-GQA";
+// TypeCode references the aircraft type catalog and is empty for airplanes
+// registered before it. TypeName and TypeIATACode are read from the catalog
+// and not stored.
 type Airplane struct {
     ID          int64
     Code        string
+    TypeCode    string
+    TypeName    string
+    TypeIATACode string
     SeatCapacity int
     CreatedAt   string
+    ArchivedAt  string // RFC3339; empty while the airplane is in service
 }
 
+// Archived reports whether the airplane was retired from new schedules.
+func (a Airplane) Archived() bool { return a.ArchivedAt != "" }
+
 func (a *Airplane) Normalize() {
     a.Code = strings.ToUpper(strings.TrimSpace(a.Code))
+    a.TypeCode = strings.ToUpper(strings.TrimSpace(a.TypeCode))
 }
 
 func (a Airplane) Validate() error {
@@ -1,12 +1,29 @@
 package domain
 
-import "context"
+import (
+    "context"
+    "time"
+)
 
+// AirplaneRepository defines storage operations for airplanes. GetByCode also
+// finds archived airplanes, which List leaves out unless includeArchived is set.
 type AirplaneRepository interface {
     Create(ctx context.Context, a *Airplane) error
     GetByCode(ctx context.Context, code string) (*Airplane, error)
-    List(ctx context.Context, limit, offset int) ([]Airplane, error)
-    UpdateSeats(ctx context.Context, code string, seats int) error
-    Delete(ctx context.Context, code string) error
+    List(ctx context.Context, limit, offset int, includeArchived bool) ([]Airplane, error)
+    // UpdateSeats resizes the airplane and the seat inventory of its flights
+    // departing from from on. Flights whose bookings would not fit fail the
+    // change with a *FleetCapacityError unless force is set, when they keep
+    // their inventory until the change is made again after their bookings
+    // are moved; bookings on seats beyond the new capacity of the other
+    // flights move to the lowest free seats.
+    UpdateSeats(ctx context.Context, code string, seats int, from time.Time, force bool) (*CapacityChange, error)
+    // SetArchived archives the airplane, keeping when it first was, or restores it.
+    SetArchived(ctx context.Context, code string, archived bool) error
+    // Delete removes the airplane. While flights, series or maintenance
+    // windows depend on it it fails with an *InUseError, unless cascade holds
+    // their counts: they are then removed too, with the flights' bookings. It
+    // fails with ErrDependentsChanged when the counts differ.
+    Delete(ctx context.Context, code string, cascade *Dependents) (Dependents, error)
 }
 
@@ -2,20 +2,40 @@ package domain
 
 import (
     "strings"
+    "time"
 
     gv "github.com/asaskevich/govalidator"
 )
 
+// DefaultTimeZone is assumed for airports created without an explicit zone.
+const DefaultTimeZone = "UTC"
+
 type Airport struct {
     ID        int64
     Code      string
     City      string
+    TimeZone  string // IANA zone name, e.g. Asia/Jakarta
+    // MinConnectionMinutes overrides the global minimum connection time; 0 uses the default.
+    MinConnectionMinutes int
+    // Position is the airport's latitude and longitude; nil when unknown.
+    Position  *Coordinates
     CreatedAt string // RFC3339, left as string for portability in domain
+    ArchivedAt string // RFC3339; empty while the airport is in use
 }
 
+// Archived reports whether the airport was retired from new routes.
+func (a Airport) Archived() bool { return a.ArchivedAt != "" }
+
+// MaxMinConnectionMinutes caps per-airport minimum connection times at one day.
+const MaxMinConnectionMinutes = 24 * 60
+
 func (a *Airport) Normalize() {
     a.Code = strings.ToUpper(strings.TrimSpace(a.Code))
     a.City = strings.TrimSpace(a.City)
+    a.TimeZone = strings.TrimSpace(a.TimeZone)
+    if a.TimeZone == "" {
+        a.TimeZone = DefaultTimeZone
+    }
 }
 
 func (a Airport) Validate() error {
@@ -25,6 +45,40 @@ func (a Airport) Validate() error {
     if gv.IsNull(a.City) {
         return ErrInvalidAirportCity
     }
+    if _, err := LoadTimeZone(a.TimeZone); err != nil {
+        return err
+    }
+    if a.MinConnectionMinutes < 0 || a.MinConnectionMinutes > MaxMinConnectionMinutes {
+        return ErrInvalidMinConnection
+    }
+    if a.Position != nil {
+        if err := a.Position.Validate(); err != nil { return err }
+    }
     return nil
 }
 
+// Location returns the airport's time zone, falling back to UTC when unset or unknown.
+func (a Airport) Location() *time.Location {
+    loc, err := LoadTimeZone(a.TimeZone)
+    if err != nil {
+        return time.UTC
+    }
+    return loc
+}
+
+// LoadTimeZone resolves an IANA zone name. An empty name means UTC; the
+// process-local zone is rejected because it differs between hosts.
+func LoadTimeZone(name string) (*time.Location, error) {
+    name = strings.TrimSpace(name)
+    if name == "" {
+        return time.UTC, nil
+    }
+    if name == "Local" {
+        return nil, ErrInvalidAirportTimeZone
+    }
+    loc, err := time.LoadLocation(name)
+    if err != nil {
+        return nil, ErrInvalidAirportTimeZone
+    }
+    return loc, nil
+}
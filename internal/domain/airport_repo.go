@@ -2,12 +2,23 @@ package domain
 
 import "context"
 
-// AirportRepository defines storage operations for airports.
+// AirportRepository defines storage operations for airports. GetByCode also
+// finds archived airports, which List leaves out unless includeArchived is set.
 type AirportRepository interface {
     Create(ctx context.Context, a *Airport) error
     GetByCode(ctx context.Context, code string) (*Airport, error)
-    List(ctx context.Context, limit, offset int) ([]Airport, error)
+    List(ctx context.Context, limit, offset int, includeArchived bool) ([]Airport, error)
     Update(ctx context.Context, code string, city string) error
-    Delete(ctx context.Context, code string) error
+    SetTimeZone(ctx context.Context, code string, timeZone string) error
+    SetMinConnection(ctx context.Context, code string, minutes int) error
+    // SetPosition records the airport's coordinates; nil clears them.
+    SetPosition(ctx context.Context, code string, position *Coordinates) error
+    // SetArchived archives the airport, keeping when it first was, or restores it.
+    SetArchived(ctx context.Context, code string, archived bool) error
+    // Delete removes the airport. While routes start or end there it fails
+    // with an *InUseError, unless cascade holds the dependents' counts: the
+    // routes are then removed too, with their flights, series, fares and
+    // bookings. It fails with ErrDependentsChanged when the counts differ.
+    Delete(ctx context.Context, code string, cascade *Dependents) (Dependents, error)
 }
 
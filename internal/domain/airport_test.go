@@ -39,3 +39,37 @@ func TestAirportValidate_Errors(t *testing.T) {
     }
 }
 
+
+func TestAirportTimeZone(t *testing.T) {
+    a := &Airport{Code: "DPS", City: "Denpasar", TimeZone: " Asia/Makassar "}
+    a.Normalize()
+    if err := a.Validate(); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if a.Location().String() != "Asia/Makassar" {
+        t.Fatalf("expected Asia/Makassar, got %s", a.Location())
+    }
+
+    legacy := &Airport{Code: "CGK", City: "Jakarta"}
+    legacy.Normalize()
+    if legacy.TimeZone != DefaultTimeZone {
+        t.Fatalf("expected default zone, got %q", legacy.TimeZone)
+    }
+
+    for _, tz := range []string{"Mars/Olympus", "Local"} {
+        if err := (Airport{Code: "CGK", City: "Jakarta", TimeZone: tz}).Validate(); err != ErrInvalidAirportTimeZone {
+            t.Fatalf("want time zone err for %q, got %v", tz, err)
+        }
+    }
+}
+
+func TestAirportMinConnection(t *testing.T) {
+    for _, m := range []int{-1, MaxMinConnectionMinutes + 1} {
+        if err := (Airport{Code: "CGK", City: "Jakarta", MinConnectionMinutes: m}).Validate(); err != ErrInvalidMinConnection {
+            t.Fatalf("want min connection err for %d, got %v", m, err)
+        }
+    }
+    if err := (Airport{Code: "CGK", City: "Jakarta", MinConnectionMinutes: 90}).Validate(); err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+}
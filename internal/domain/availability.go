@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// FlightAvailability is a read model joining a schedule with its route and seat
+// inventory. Booked counts every seat that cannot be sold: sold, held or blocked.
+type FlightAvailability struct {
+	Schedule        FlightSchedule
+	OriginCode      string
+	DestinationCode string
+	SeatCapacity    int
+	Booked          int
+	AircraftType    string // name of the airplane's aircraft type; empty when untyped
+	// AircraftTypeCode and AircraftTypeIATA are the type's ICAO designator
+	// and IATA code; empty when the airplane is untyped.
+	AircraftTypeCode string
+	AircraftTypeIATA string
+	Fare             *Fare // cheapest fare applying on the departure date; nil when none is published
+}
+
+// SeatsAvailable is the number of unsold seats, never negative.
+func (a FlightAvailability) SeatsAvailable() int {
+	if a.Booked >= a.SeatCapacity {
+		return 0
+	}
+	return a.SeatCapacity - a.Booked
+}
+
+// OfAircraftType reports whether the flight's airplane is of the aircraft
+// type named by its ICAO designator, IATA code or name, ignoring case.
+func (a FlightAvailability) OfAircraftType(aircraftType string) bool {
+	aircraftType = strings.TrimSpace(aircraftType)
+	if aircraftType == "" {
+		return false
+	}
+	for _, v := range []string{a.AircraftTypeCode, a.AircraftTypeIATA, a.AircraftType} {
+		if v != "" && strings.EqualFold(v, aircraftType) {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailabilityQuery filters availability rows. Zero values leave a filter unset.
+type AvailabilityQuery struct {
+	OriginCode      string
+	DestinationCode string
+	DepartureDate   string    // YYYY-MM-DD local date at the origin
+	DateFrom        string    // inclusive lower bound on the local departure date
+	DateTo          string    // inclusive upper bound on the local departure date
+	DepartsAfter    time.Time // inclusive lower bound on the departure instant
+	DepartsBefore   time.Time // inclusive upper bound on the departure instant
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+// AvailabilityRepository answers seat availability questions for flight search.
+type AvailabilityRepository interface {
+	// Search returns matching schedules ordered by departure instant.
+	Search(ctx context.Context, q AvailabilityQuery) ([]FlightAvailability, error)
+	// RouteExists reports whether any route connects origin to destination.
+	RouteExists(ctx context.Context, originCode, destinationCode string) (bool, error)
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Board remarks shown for a flight.
+const (
+	RemarkOnTime    = "ON TIME"
+	RemarkDelayed   = "DELAYED"
+	RemarkCancelled = "CANCELLED"
+)
+
+// BoardFlight is a flight on an airport's departure or arrival board, with
+// the route's airports and the seats sold or held against its capacity.
+type BoardFlight struct {
+	Schedule        FlightSchedule
+	OriginCode      string
+	DestinationCode string
+	Capacity        int
+	Booked          int
+}
+
+// LoadFactor is the share of the flight's seats sold or held, from 0 to 1;
+// zero when the flight has no seats.
+func (f BoardFlight) LoadFactor() float64 {
+	if f.Capacity <= 0 {
+		return 0
+	}
+	return float64(f.Booked) / float64(f.Capacity)
+}
+
+// Remark summarizes the flight's state for the board.
+func (f BoardFlight) Remark() string {
+	switch {
+	case f.Schedule.Cancelled():
+		return RemarkCancelled
+	case f.Schedule.DelayMinutes > 0:
+		return RemarkDelayed
+	default:
+		return RemarkOnTime
+	}
+}
+
+// BoardDay returns the UTC instants bounding a local calendar date at an
+// airport: its first instant and the first instant of the next day, which
+// are 23 or 25 hours apart on daylight saving changes.
+func BoardDay(date string, loc *time.Location) (time.Time, time.Time, error) {
+	day, err := time.Parse("2006-01-02", strings.TrimSpace(date))
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidScheduleDate
+	}
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	return from.UTC(), from.AddDate(0, 0, 1).UTC(), nil
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoardFlight_LoadFactorRemark(t *testing.T) {
+	f := BoardFlight{Capacity: 180, Booked: 45}
+	if f.LoadFactor() != 0.25 || f.Remark() != RemarkOnTime {
+		t.Fatalf("unexpected load %v remark %q", f.LoadFactor(), f.Remark())
+	}
+	if (BoardFlight{}).LoadFactor() != 0 {
+		t.Fatal("want no load without seats")
+	}
+	f.Schedule.DelayMinutes = 20
+	if f.Remark() != RemarkDelayed {
+		t.Fatalf("want delayed, got %q", f.Remark())
+	}
+	f.Schedule.Status = ScheduleStatusCancelled
+	if f.Remark() != RemarkCancelled {
+		t.Fatalf("want cancelled, got %q", f.Remark())
+	}
+}
+
+func TestBoardDay(t *testing.T) {
+	jakarta, _ := LoadTimeZone("Asia/Jakarta")
+	from, to, err := BoardDay("2025-01-02", jakarta)
+	if err != nil || !from.Equal(time.Date(2025, 1, 1, 17, 0, 0, 0, time.UTC)) || to.Sub(from) != 24*time.Hour {
+		t.Fatalf("unexpected day %v - %v err=%v", from, to, err)
+	}
+	// The spring-forward day is an hour short.
+	newYork, _ := LoadTimeZone("America/New_York")
+	if from, to, err := BoardDay("2025-03-09", newYork); err != nil || to.Sub(from) != 23*time.Hour {
+		t.Fatalf("unexpected day %v - %v err=%v", from, to, err)
+	}
+	if _, _, err := BoardDay("2025-13-01", time.UTC); err != ErrInvalidScheduleDate {
+		t.Fatalf("want invalid date, got %v", err)
+	}
+}
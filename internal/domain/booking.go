@@ -4,10 +4,11 @@ import "strings"
 
 const (
 	BookingStatusConfirmed = "CONFIRMED"
+	BookingStatusHeld      = "HELD"
 	BookingStatusCancelled = "CANCELLED"
 )
 
-// Booking represents a confirmed seat for a passenger on a scheduled flight.
+// Booking represents a confirmed or held seat for a passenger on a scheduled flight.
 type Booking struct {
 	ID            int64
 	Reference     string
@@ -15,6 +16,7 @@ type Booking struct {
 	PassengerName string
 	SeatNumber    int
 	Status        string
+	TripID        int64 // trip the booking belongs to; zero for a standalone booking
 	CreatedAt     string
 }
 
@@ -40,10 +42,22 @@ func (b Booking) Validate() error {
 		return ErrInvalidSeatNumber
 	}
 	switch b.Status {
-	case BookingStatusConfirmed, BookingStatusCancelled:
+	case BookingStatusConfirmed, BookingStatusHeld, BookingStatusCancelled:
 		// ok
 	default:
 		return ErrInvalidBookingStatus
 	}
 	return nil
 }
+
+// CanTransition reports whether the booking may move to the next status. Held
+// seats are confirmed or released; confirmed bookings can only be cancelled.
+func (b Booking) CanTransition(next string) bool {
+	switch b.Status {
+	case BookingStatusHeld:
+		return next == BookingStatusConfirmed || next == BookingStatusCancelled
+	case BookingStatusConfirmed:
+		return next == BookingStatusCancelled
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "strings"
+
+// LocatorAlphabet is the default record locator alphabet. It leaves out 0/O and
+// 1/I so references can be read out over the phone without ambiguity.
+const LocatorAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// ReferenceFormat describes how booking references are generated.
+type ReferenceFormat struct {
+	Prefix   string
+	Length   int
+	Alphabet string
+}
+
+// DefaultReferenceFormat yields airline-style six-character locators such as "K7QX2M".
+var DefaultReferenceFormat = ReferenceFormat{Length: 6, Alphabet: LocatorAlphabet}
+
+// Normalize uppercases the prefix and alphabet and fills unset fields with defaults.
+func (f *ReferenceFormat) Normalize() {
+	f.Prefix = strings.ToUpper(strings.TrimSpace(f.Prefix))
+	f.Alphabet = strings.ToUpper(strings.TrimSpace(f.Alphabet))
+	if f.Alphabet == "" {
+		f.Alphabet = LocatorAlphabet
+	}
+	if f.Length == 0 {
+		f.Length = DefaultReferenceFormat.Length
+	}
+}
+
+// Validate ensures generated references satisfy Booking.Validate and that the
+// alphabet is large enough to make collisions rare.
+func (f ReferenceFormat) Validate() error {
+	if f.Length <= 0 {
+		return ErrInvalidReferenceFormat
+	}
+	if total := len(f.Prefix) + f.Length; total < 6 || total > 32 {
+		return ErrInvalidReferenceFormat
+	}
+	seen := make(map[rune]bool, len(f.Alphabet))
+	for _, c := range f.Alphabet {
+		if !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			return ErrInvalidReferenceFormat
+		}
+		if seen[c] {
+			return ErrInvalidReferenceFormat
+		}
+		seen[c] = true
+	}
+	if len(seen) < 10 {
+		return ErrInvalidReferenceFormat
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReferenceFormatValidate(t *testing.T) {
+	f := ReferenceFormat{Prefix: " bk- ", Length: 6}
+	f.Normalize()
+	if f.Prefix != "BK-" || f.Alphabet != LocatorAlphabet {
+		t.Fatalf("normalize failed: %+v", f)
+	}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if strings.ContainsAny(LocatorAlphabet, "0O1I") {
+		t.Fatalf("default alphabet must not contain ambiguous characters")
+	}
+	cases := []ReferenceFormat{
+		{Length: 5, Alphabet: LocatorAlphabet},
+		{Length: 33, Alphabet: LocatorAlphabet},
+		{Length: 6, Alphabet: "ABC"},
+		{Length: 6, Alphabet: "AABCDEFGHJK"},
+		{Length: 6, Alphabet: "ABCDEFGHJK-"},
+	}
+	for _, tc := range cases {
+		if err := tc.Validate(); err != ErrInvalidReferenceFormat {
+			t.Fatalf("want format err for %+v, got %v", tc, err)
+		}
+	}
+}
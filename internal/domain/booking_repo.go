@@ -4,8 +4,18 @@ import "context"
 
 // BookingRepository defines persistence operations for flight bookings.
 type BookingRepository interface {
+	// Create stores the booking and claims its seat in the schedule's inventory in
+	// one transaction. It fails with ErrFlightFull when no seat is left and moves
+	// the booking to the lowest free seat when the requested one is taken.
 	Create(ctx context.Context, b *Booking) error
+	// UpdateStatus moves a booking from one status to another and adjusts the
+	// schedule's inventory in the same transaction.
+	UpdateStatus(ctx context.Context, id int64, from, to string) error
+	// CountBySchedule returns the seats sold, held or blocked on a schedule.
 	CountBySchedule(ctx context.Context, scheduleID int64) (int, error)
 	ListBySchedule(ctx context.Context, scheduleID int64, limit, offset int) ([]Booking, error)
 	GetByReference(ctx context.Context, reference string) (*Booking, error)
+	// ListAffected returns bookings flagged by a flight cancellation that are
+	// not cancelled yet, ordered by schedule and seat.
+	ListAffected(ctx context.Context, limit, offset int) ([]Booking, error)
 }
@@ -45,3 +45,18 @@ func TestBookingValidateErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestBookingCanTransition(t *testing.T) {
+	held := Booking{Status: BookingStatusHeld}
+	confirmed := Booking{Status: BookingStatusConfirmed}
+	cancelled := Booking{Status: BookingStatusCancelled}
+	if !held.CanTransition(BookingStatusConfirmed) || !held.CanTransition(BookingStatusCancelled) {
+		t.Fatalf("held bookings should confirm or cancel")
+	}
+	if !confirmed.CanTransition(BookingStatusCancelled) || confirmed.CanTransition(BookingStatusHeld) {
+		t.Fatalf("confirmed bookings should only cancel")
+	}
+	if cancelled.CanTransition(BookingStatusConfirmed) {
+		t.Fatalf("cancelled bookings are final")
+	}
+}
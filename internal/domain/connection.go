@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// ConnectionPolicy bounds the time a passenger may spend between two legs of a transit itinerary.
+type ConnectionPolicy struct {
+	// MinConnection applies at airports without their own minimum connection time.
+	MinConnection time.Duration
+	MaxLayover    time.Duration
+	// MaxCircuity caps the great-circle distance flown through connecting
+	// airports as a multiple of the direct distance; 0 disables the check.
+	MaxCircuity float64
+}
+
+// DefaultConnectionPolicy allows connections from 45 minutes up to a full day on
+// the ground that fly at most twice the direct distance.
+var DefaultConnectionPolicy = ConnectionPolicy{MinConnection: 45 * time.Minute, MaxLayover: 24 * time.Hour, MaxCircuity: 2}
+
+// Validate ensures the window is non-empty and the circuity, when set, allows
+// at least the direct distance.
+func (p ConnectionPolicy) Validate() error {
+	if p.MinConnection < 0 || p.MaxLayover <= 0 || p.MaxLayover < p.MinConnection {
+		return ErrInvalidConnectionPolicy
+	}
+	if p.MaxCircuity != 0 && p.MaxCircuity < 1 {
+		return ErrInvalidConnectionPolicy
+	}
+	return nil
+}
+
+// Circuitous reports whether flying flownKm to cover directKm exceeds the
+// policy's circuity. Unknown distances are never circuitous.
+func (p ConnectionPolicy) Circuitous(flownKm, directKm int) bool {
+	return p.MaxCircuity > 0 && directKm > 0 && float64(flownKm) > p.MaxCircuity*float64(directKm)
+}
+
+// MinimumAt returns the minimum connection time at the airport, falling back to the policy default.
+func (p ConnectionPolicy) MinimumAt(a *Airport) time.Duration {
+	if a != nil && a.MinConnectionMinutes > 0 {
+		return time.Duration(a.MinConnectionMinutes) * time.Minute
+	}
+	return p.MinConnection
+}
+
+// Connect reports the layover between arriving on first and departing on second
+// and whether it fits between minimum and the policy's maximum layover. The
+// comparison uses absolute instants, so connections past midnight are allowed.
+// A first leg without a planned arrival can never be connected.
+func (p ConnectionPolicy) Connect(first, second FlightSchedule, minimum time.Duration) (time.Duration, bool) {
+	if first.ArrivalAt.IsZero() || second.DepartureAt.IsZero() {
+		return 0, false
+	}
+	layover := second.DepartureAt.Sub(first.ArrivalAt)
+	return layover, layover >= minimum && layover <= p.MaxLayover
+}
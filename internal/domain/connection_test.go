@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConnectionPolicy_Connect(t *testing.T) {
+	p := ConnectionPolicy{MinConnection: time.Hour, MaxLayover: 6 * time.Hour}
+	arrive := time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC)
+	first := FlightSchedule{DepartureAt: arrive.Add(-2 * time.Hour), ArrivalAt: arrive}
+
+	cases := []struct {
+		name    string
+		depart  time.Time
+		minimum time.Duration
+		ok      bool
+	}{
+		{"too tight", arrive.Add(30 * time.Minute), p.MinConnection, false},
+		{"exactly minimum", arrive.Add(time.Hour), p.MinConnection, true},
+		{"after midnight", arrive.Add(5 * time.Hour), p.MinConnection, true},
+		{"beyond max layover", arrive.Add(7 * time.Hour), p.MinConnection, false},
+		{"airport minimum", arrive.Add(90 * time.Minute), 2 * time.Hour, false},
+		{"departs before arrival", arrive.Add(-time.Hour), 0, false},
+	}
+	for _, tc := range cases {
+		layover, ok := p.Connect(first, FlightSchedule{DepartureAt: tc.depart}, tc.minimum)
+		if ok != tc.ok {
+			t.Fatalf("%s: ok=%v layover=%s", tc.name, ok, layover)
+		}
+	}
+	if _, ok := p.Connect(FlightSchedule{DepartureAt: arrive}, FlightSchedule{DepartureAt: arrive.Add(2 * time.Hour)}, 0); ok {
+		t.Fatalf("first leg without arrival must not connect")
+	}
+}
+
+func TestConnectionPolicy_MinimumAtAndValidate(t *testing.T) {
+	p := DefaultConnectionPolicy
+	if p.MinimumAt(nil) != 45*time.Minute || p.MinimumAt(&Airport{Code: "SUB"}) != 45*time.Minute {
+		t.Fatalf("expected global default")
+	}
+	if p.MinimumAt(&Airport{Code: "CGK", MinConnectionMinutes: 90}) != 90*time.Minute {
+		t.Fatalf("expected airport override")
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("default policy invalid: %v", err)
+	}
+	if err := (ConnectionPolicy{MinConnection: 2 * time.Hour, MaxLayover: time.Hour}).Validate(); err != ErrInvalidConnectionPolicy {
+		t.Fatalf("want invalid policy, got %v", err)
+	}
+	if err := (ConnectionPolicy{MaxLayover: time.Hour, MaxCircuity: 0.5}).Validate(); err != ErrInvalidConnectionPolicy {
+		t.Fatalf("want invalid circuity, got %v", err)
+	}
+}
+
+func TestConnectionPolicy_Circuitous(t *testing.T) {
+	p := DefaultConnectionPolicy
+	if p.Circuitous(1900, 1000) || !p.Circuitous(2100, 1000) {
+		t.Fatalf("expected a detour beyond twice the direct distance to be circuitous")
+	}
+	if p.Circuitous(2100, 0) {
+		t.Fatalf("unknown direct distance must not be circuitous")
+	}
+	if (ConnectionPolicy{MaxLayover: time.Hour}).Circuitous(5000, 100) {
+		t.Fatalf("zero circuity disables the check")
+	}
+}
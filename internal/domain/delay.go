@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxDelayMinutes caps a recorded delay at one day; later than that the
+// flight should be cancelled and its bookings moved.
+const MaxDelayMinutes = 24 * 60
+
+// Notification kinds.
+const (
+	NotificationDelay            = "DELAY"
+	NotificationMissedConnection = "MISSED_CONNECTION"
+)
+
+// ScheduleDelay sets how many minutes later than planned a flight is expected.
+type ScheduleDelay struct {
+	ScheduleID int64
+	Minutes    int
+	Reason     string
+}
+
+// NormalizeDelay trims the reason of a delay and checks both: minutes runs
+// from 0, which puts the flight back on time, to MaxDelayMinutes, and a
+// delayed flight needs a reason that fits its column.
+func NormalizeDelay(d ScheduleDelay) (ScheduleDelay, error) {
+	d.Reason = strings.TrimSpace(d.Reason)
+	if d.Minutes < 0 || d.Minutes > MaxDelayMinutes || len(d.Reason) > 200 {
+		return d, ErrInvalidDelay
+	}
+	if d.Minutes == 0 {
+		d.Reason = ""
+	} else if d.Reason == "" {
+		return d, ErrInvalidDelay
+	}
+	return d, nil
+}
+
+// PropagateDelay returns the knock-on delays of delaying the flight id of an
+// airplane's rotation, given in departure order, by minutes. Each later flight
+// departs no sooner than minTurnaround after the previous one is expected to
+// land. A later flight whose delay came from an earlier propagation from id is
+// recomputed, so lowering or clearing the delay lowers or clears it too.
+// Propagation stops at the first flight whose delay already is what the chain
+// asks, or is its own and at least as long, or after a flight without a
+// planned arrival.
+func PropagateDelay(legs []RotationLeg, id int64, minutes int, minTurnaround time.Duration) []ScheduleDelay {
+	k := -1
+	for i, leg := range legs {
+		if leg.Schedule.ID == id {
+			k = i
+			break
+		}
+	}
+	if k < 0 {
+		return nil
+	}
+	reason := fmt.Sprintf("late inbound aircraft from schedule %d", id)
+	prev := legs[k].Schedule
+	prev.DelayMinutes = minutes
+	var delays []ScheduleDelay
+	for _, leg := range legs[k+1:] {
+		if prev.ArrivalAt.IsZero() {
+			break
+		}
+		next := leg.Schedule
+		late := prev.EstimatedArrival().Add(minTurnaround).Sub(next.DepartureAt)
+		knockOn := 0
+		if late > 0 {
+			knockOn = int((late + time.Minute - 1) / time.Minute)
+		}
+		propagated := next.DelayReason == reason
+		if knockOn == next.DelayMinutes || (knockOn < next.DelayMinutes && !propagated) {
+			break
+		}
+		d := ScheduleDelay{ScheduleID: next.ID, Minutes: knockOn, Reason: reason}
+		if knockOn == 0 {
+			d.Reason = ""
+		}
+		next.DelayMinutes, next.DelayReason = d.Minutes, d.Reason
+		delays = append(delays, d)
+		prev = next
+	}
+	return delays
+}
+
+// Notification is a message to the passenger of a booking, queued for delivery.
+type Notification struct {
+	BookingID int64
+	Kind      string
+	Message   string
+}
+
+// Connection is a passenger's onward flight, booked on the same trip, from the
+// airport an inbound flight lands at.
+type Connection struct {
+	Inbound     Booking
+	Onward      Booking
+	AirportCode string // where the passenger changes flights
+}
+
+// BrokenConnection is a connection whose layover no longer makes the minimum
+// connection time at its airport once delays are counted.
+type BrokenConnection struct {
+	Connection
+	Arriving  FlightSchedule
+	Departing FlightSchedule
+	Layover   time.Duration // negative when the onward flight leaves first
+	Minimum   time.Duration
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeDelay(t *testing.T) {
+	d, err := NormalizeDelay(ScheduleDelay{ScheduleID: 1, Minutes: 45, Reason: "  late crew "})
+	if err != nil || d.Reason != "late crew" {
+		t.Fatalf("unexpected delay %+v err=%v", d, err)
+	}
+	// Back on time: the reason is dropped.
+	if d, err := NormalizeDelay(ScheduleDelay{ScheduleID: 1, Reason: "fixed"}); err != nil || d.Reason != "" {
+		t.Fatalf("unexpected delay %+v err=%v", d, err)
+	}
+	for _, bad := range []ScheduleDelay{
+		{Minutes: -1, Reason: "x"},
+		{Minutes: MaxDelayMinutes + 1, Reason: "x"},
+		{Minutes: 10},
+		{Minutes: 10, Reason: strings.Repeat("x", 201)},
+	} {
+		if _, err := NormalizeDelay(bad); err != ErrInvalidDelay {
+			t.Fatalf("want invalid delay for %+v, got %v", bad, err)
+		}
+	}
+}
+
+func TestPropagateDelay(t *testing.T) {
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	leg := func(id int64, dep, arr time.Duration) RotationLeg {
+		s := FlightSchedule{ID: id, DepartureAt: day.Add(dep)}
+		if arr > 0 {
+			s.ArrivalAt = day.Add(arr)
+		}
+		return RotationLeg{Schedule: s}
+	}
+	legs := []RotationLeg{
+		leg(1, 8*time.Hour, 10*time.Hour),
+		leg(2, 10*time.Hour+45*time.Minute, 12*time.Hour+45*time.Minute),
+		leg(3, 14*time.Hour, 16*time.Hour),
+		leg(4, 16*time.Hour+40*time.Minute, 18*time.Hour+40*time.Minute),
+	}
+
+	// An hour late, flight 2 loses 45 minutes and flight 3's slack absorbs the rest.
+	got := PropagateDelay(legs, 1, 60, 30*time.Minute)
+	if len(got) != 1 || got[0].ScheduleID != 2 || got[0].Minutes != 45 || got[0].Reason != "late inbound aircraft from schedule 1" {
+		t.Fatalf("unexpected knock-on %+v", got)
+	}
+
+	got = PropagateDelay(legs, 1, 120, 30*time.Minute)
+	if len(got) != 3 || got[0].Minutes != 105 || got[1].Minutes != 60 || got[2].Minutes != 50 {
+		t.Fatalf("unexpected knock-on %+v", got)
+	}
+
+	// Putting flight 1 back on time clears the delays it caused, and
+	// shortening its delay shortens them.
+	delayed := append([]RotationLeg(nil), legs...)
+	for _, d := range got {
+		for i := range delayed {
+			if delayed[i].Schedule.ID == d.ScheduleID {
+				delayed[i].Schedule.DelayMinutes, delayed[i].Schedule.DelayReason = d.Minutes, d.Reason
+			}
+		}
+	}
+	delayed[0].Schedule.DelayMinutes = 120
+	cleared := PropagateDelay(delayed, 1, 0, 30*time.Minute)
+	if len(cleared) != 3 || cleared[0].ScheduleID != 2 || cleared[0].Minutes != 0 || cleared[0].Reason != "" || cleared[2].Minutes != 0 {
+		t.Fatalf("want the knock-on delays cleared, got %+v", cleared)
+	}
+	if lowered := PropagateDelay(delayed, 1, 60, 30*time.Minute); len(lowered) != 3 || lowered[0].Minutes != 45 || lowered[1].Minutes != 0 || lowered[2].Minutes != 0 {
+		t.Fatalf("want the knock-on delays lowered, got %+v", lowered)
+	}
+
+	// A flight already delayed as much stops the chain.
+	legs[1].Schedule.DelayMinutes = 120
+	if got := PropagateDelay(legs, 1, 60, 30*time.Minute); len(got) != 0 {
+		t.Fatalf("want no knock-on, got %+v", got)
+	}
+	legs[1].Schedule.DelayMinutes = 0
+
+	// Without a planned arrival the airplane's return is unknown.
+	legs[0] = leg(1, 8*time.Hour, 0)
+	if got := PropagateDelay(legs, 1, 120, 30*time.Minute); len(got) != 0 {
+		t.Fatalf("want no knock-on, got %+v", got)
+	}
+	if got := PropagateDelay(legs, 9, 120, 30*time.Minute); got != nil {
+		t.Fatalf("want nothing for an unknown flight, got %+v", got)
+	}
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Entities a delete can be refused for.
+const (
+	EntityAirport  = "airport"
+	EntityAirplane = "airplane"
+	EntityRoute    = "route"
+	EntitySchedule = "schedule"
+)
+
+// Dependents counts the records removed along with a deleted entity.
+type Dependents struct {
+	Routes      int
+	Schedules   int
+	Series      int
+	Bookings    int
+	Fares       int
+	Maintenance int
+}
+
+// Empty reports whether nothing depends on the entity.
+func (d Dependents) Empty() bool { return d == Dependents{} }
+
+// String lists the non-zero counts, e.g. "2 schedule(s), 3 booking(s)".
+func (d Dependents) String() string {
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		noun string
+	}{
+		{d.Routes, "route(s)"},
+		{d.Schedules, "schedule(s)"},
+		{d.Series, "series"},
+		{d.Bookings, "booking(s)"},
+		{d.Fares, "fare(s)"},
+		{d.Maintenance, "maintenance window(s)"},
+	} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.noun))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing else"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// InUseError refuses to delete an entity other records depend on.
+type InUseError struct {
+	Entity     string // one of the Entity constants
+	Key        string
+	Dependents Dependents
+}
+
+func (e *InUseError) Error() string {
+	return fmt.Sprintf("%s %s is in use: deleting it would remove %s", e.Entity, e.Key, e.Dependents)
+}
+
+// Is matches the entity's in-use error, e.g. ErrRouteInUse.
+func (e *InUseError) Is(target error) bool {
+	switch e.Entity {
+	case EntityAirport:
+		return target == ErrAirportInUse
+	case EntityAirplane:
+		return target == ErrAirplaneInUse
+	case EntityRoute:
+		return target == ErrRouteInUse
+	case EntitySchedule:
+		return target == ErrScheduleInUse
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDependents_String(t *testing.T) {
+	if got := (Dependents{}).String(); got != "nothing else" {
+		t.Fatalf("empty dependents: %q", got)
+	}
+	d := Dependents{Routes: 1, Schedules: 2, Series: 1, Bookings: 5, Fares: 0, Maintenance: 1}
+	if got, want := d.String(), "1 route(s), 2 schedule(s), 1 series, 5 booking(s), 1 maintenance window(s)"; got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+	if d.Empty() || !(Dependents{}).Empty() {
+		t.Fatalf("unexpected Empty")
+	}
+}
+
+func TestInUseError_Is(t *testing.T) {
+	cases := map[string]error{
+		EntityAirport:  ErrAirportInUse,
+		EntityAirplane: ErrAirplaneInUse,
+		EntityRoute:    ErrRouteInUse,
+		EntitySchedule: ErrScheduleInUse,
+	}
+	for entity, want := range cases {
+		err := error(&InUseError{Entity: entity, Key: "X", Dependents: Dependents{Bookings: 1}})
+		if !errors.Is(err, want) {
+			t.Fatalf("%s: want %v", entity, want)
+		}
+		for other, sentinel := range cases {
+			if other != entity && errors.Is(err, sentinel) {
+				t.Fatalf("%s error matches %v", entity, sentinel)
+			}
+		}
+	}
+	err := &InUseError{Entity: EntityAirport, Key: "CGK", Dependents: Dependents{Routes: 2}}
+	if want := "airport CGK is in use: deleting it would remove 2 route(s)"; err.Error() != want {
+		t.Fatalf("got %q", err.Error())
+	}
+}
@@ -3,29 +3,97 @@ package domain
 import "errors"
 
 var (
-	ErrInvalidAirportCode      = errors.New("invalid airport code")
-	ErrInvalidAirportCity      = errors.New("invalid airport city")
-	ErrAirportExists           = errors.New("airport already exists")
-	ErrAirportNotFound         = errors.New("airport not found")
-	ErrInvalidAirplaneCode     = errors.New("invalid airplane code")
-	ErrInvalidSeatCapacity     = errors.New("invalid seat capacity")
-	ErrAirplaneExists          = errors.New("airplane already exists")
-	ErrAirplaneNotFound        = errors.New("airplane not found")
-	ErrInvalidRouteCode        = errors.New("invalid route code")
-	ErrInvalidRouteAirports    = errors.New("invalid route airports")
-	ErrRouteExists             = errors.New("route already exists")
-	ErrRouteNotFound           = errors.New("route not found")
-	ErrInvalidScheduleRoute    = errors.New("invalid schedule route")
-	ErrInvalidScheduleAirplane = errors.New("invalid schedule airplane")
-	ErrInvalidScheduleDate     = errors.New("invalid schedule date")
-	ErrInvalidScheduleID       = errors.New("invalid schedule id")
-	ErrScheduleExists          = errors.New("schedule already exists")
-	ErrScheduleNotFound        = errors.New("schedule not found")
-	ErrInvalidPassengerName    = errors.New("invalid passenger name")
-	ErrInvalidBookingReference = errors.New("invalid booking reference")
-	ErrInvalidSeatNumber       = errors.New("invalid seat number")
-	ErrInvalidBookingStatus    = errors.New("invalid booking status")
-	ErrBookingExists           = errors.New("booking already exists")
-	ErrBookingNotFound         = errors.New("booking not found")
-	ErrFlightFull              = errors.New("flight fully booked")
+	ErrInvalidAirportCode       = errors.New("invalid airport code")
+	ErrInvalidAirportCity       = errors.New("invalid airport city")
+	ErrInvalidAirportTimeZone   = errors.New("invalid airport time zone")
+	ErrInvalidMinConnection     = errors.New("invalid minimum connection time")
+	ErrAirportExists            = errors.New("airport already exists")
+	ErrAirportNotFound          = errors.New("airport not found")
+	ErrInvalidAirplaneCode      = errors.New("invalid airplane code")
+	ErrInvalidSeatCapacity      = errors.New("invalid seat capacity")
+	ErrAirplaneExists           = errors.New("airplane already exists")
+	ErrAirplaneNotFound         = errors.New("airplane not found")
+	ErrInvalidRouteCode         = errors.New("invalid route code")
+	ErrInvalidRouteAirports     = errors.New("invalid route airports")
+	ErrRouteExists              = errors.New("route already exists")
+	ErrRouteNotFound            = errors.New("route not found")
+	ErrInvalidScheduleRoute     = errors.New("invalid schedule route")
+	ErrInvalidScheduleAirplane  = errors.New("invalid schedule airplane")
+	ErrInvalidScheduleDate      = errors.New("invalid schedule date")
+	ErrInvalidScheduleTime      = errors.New("invalid schedule time")
+	ErrInvalidScheduleID        = errors.New("invalid schedule id")
+	ErrScheduleExists           = errors.New("schedule already exists")
+	ErrScheduleNotFound         = errors.New("schedule not found")
+	ErrScheduleCancelled        = errors.New("schedule is cancelled")
+	ErrScheduleChanged          = errors.New("schedule changed meanwhile; try again")
+	ErrInvalidCancelReason      = errors.New("invalid cancellation reason")
+	ErrInvalidDelay             = errors.New("invalid delay")
+	ErrInvalidGate              = errors.New("invalid gate")
+	ErrInvalidPassengerName     = errors.New("invalid passenger name")
+	ErrInvalidBookingReference  = errors.New("invalid booking reference")
+	ErrInvalidSeatNumber        = errors.New("invalid seat number")
+	ErrInvalidBookingStatus     = errors.New("invalid booking status")
+	ErrBookingExists            = errors.New("booking already exists")
+	ErrBookingNotFound          = errors.New("booking not found")
+	ErrSeatTaken                = errors.New("seat already taken")
+	ErrInvalidBookingTransition = errors.New("invalid booking status transition")
+	ErrInventoryNotFound        = errors.New("seat inventory not found")
+	ErrInvalidReferenceFormat   = errors.New("invalid booking reference format")
+	ErrReferenceExhausted       = errors.New("could not allocate a unique booking reference")
+	ErrFlightFull               = errors.New("flight fully booked")
+	ErrInvalidAirlinePrefix     = errors.New("invalid airline prefix")
+	ErrInvalidTicketNumber      = errors.New("invalid ticket number")
+	ErrTicketExists             = errors.New("ticket already exists")
+	ErrTicketNotFound           = errors.New("ticket not found")
+	ErrCouponNotFound           = errors.New("coupon not found")
+	ErrInvalidCouponStatus      = errors.New("invalid coupon status")
+	ErrInvalidCouponTransition  = errors.New("invalid coupon status transition")
+	ErrInvalidConnectionPolicy  = errors.New("invalid connection policy")
+	ErrInvalidMaxStops          = errors.New("invalid maximum number of stops")
+	ErrInvalidFareAmount        = errors.New("invalid fare amount")
+	ErrInvalidCurrency          = errors.New("invalid currency")
+	ErrInvalidFareDates         = errors.New("invalid fare validity dates")
+	ErrInvalidFareID            = errors.New("invalid fare id")
+	ErrFareNotFound             = errors.New("fare not found")
+	ErrInvalidFlexDays          = errors.New("invalid flexible date range")
+	ErrInvalidMonth             = errors.New("invalid month")
+	ErrInvalidReturnDate        = errors.New("return must depart after the outbound flight arrives")
+	ErrInvalidRoundTrip         = errors.New("return flight does not reverse the outbound route")
+	ErrInvalidItinerary         = errors.New("flights do not connect")
+	ErrInvalidTripID            = errors.New("invalid trip id")
+	ErrTripNotFound             = errors.New("trip not found")
+	ErrTripsNotConfigured       = errors.New("trip storage is not configured")
+	ErrInvalidPassengerCount    = errors.New("invalid passenger count")
+	ErrInvalidSortKey           = errors.New("invalid sort key")
+	ErrInvalidTimeWindow        = errors.New("invalid departure time window")
+	ErrInvalidSeriesDays        = errors.New("invalid days of week")
+	ErrInvalidSeriesDates       = errors.New("invalid series date range")
+	ErrInvalidSeriesID          = errors.New("invalid series id")
+	ErrSeriesNotFound           = errors.New("schedule series not found")
+	ErrSeriesCancelled          = errors.New("schedule series is cancelled")
+	ErrCapacityBelowBookings    = errors.New("airplane has fewer seats than the flight's bookings")
+	ErrInvalidAircraftTypeCode  = errors.New("invalid aircraft type code")
+	ErrInvalidAircraftTypeName  = errors.New("invalid aircraft manufacturer or model")
+	ErrInvalidAircraftRange     = errors.New("invalid aircraft range")
+	ErrInvalidSeatMap           = errors.New("invalid seat map")
+	ErrAircraftTypeExists       = errors.New("aircraft type already exists")
+	ErrAircraftTypeNotFound     = errors.New("aircraft type not found")
+	ErrAircraftTypeInUse        = errors.New("aircraft type is used by airplanes")
+	ErrNoAirplaneOfType         = errors.New("no active airplane of this aircraft type")
+	ErrRotationConflict         = errors.New("schedule breaks the airplane's rotation")
+	ErrInvalidMaintenanceWindow = errors.New("invalid maintenance window")
+	ErrInvalidMaintenanceReason = errors.New("invalid maintenance reason")
+	ErrAirplaneInMaintenance    = errors.New("airplane is in maintenance")
+	ErrInvalidCoordinates       = errors.New("invalid latitude or longitude")
+	ErrInvalidBlockTime         = errors.New("invalid block time")
+	ErrRouteDistanceUnknown     = errors.New("route distance unknown: both airports need a position")
+	ErrRouteHasBookings         = errors.New("route has future flights with bookings")
+	ErrAirportInUse             = errors.New("airport is used by routes")
+	ErrAirplaneInUse            = errors.New("airplane is used by flights")
+	ErrRouteInUse               = errors.New("route has flights or fares")
+	ErrScheduleInUse            = errors.New("schedule has bookings")
+	ErrDependentsChanged        = errors.New("dependents changed since they were counted")
+	ErrAirportArchived          = errors.New("airport is archived")
+	ErrAirplaneArchived         = errors.New("airplane is archived")
+	ErrRouteArchived            = errors.New("route is archived")
 )
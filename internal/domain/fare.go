@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Money is an amount in hundredths of a currency unit.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// ParseMoney reads a decimal amount with at most two fractional digits, such as
+// "850000" or "129.99", in the given ISO 4217 currency.
+func ParseMoney(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !validCurrency(currency) {
+		return Money{}, ErrInvalidCurrency
+	}
+	amount = strings.TrimSpace(amount)
+	whole, frac, hasFrac := strings.Cut(amount, ".")
+	if whole == "" || len(frac) > 2 || (hasFrac && frac == "") {
+		return Money{}, ErrInvalidFareAmount
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	if strings.Trim(whole+frac, "0123456789") != "" {
+		return Money{}, ErrInvalidFareAmount
+	}
+	v, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || v <= 0 {
+		return Money{}, ErrInvalidFareAmount
+	}
+	return Money{Amount: v, Currency: currency}, nil
+}
+
+// IsZero reports whether no amount is set.
+func (m Money) IsZero() bool { return m.Currency == "" }
+
+// Add sums two amounts of the same currency; ok is false when the currencies differ.
+func (m Money) Add(o Money) (sum Money, ok bool) {
+	if m.Currency != o.Currency {
+		return Money{}, false
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, true
+}
+
+func (m Money) String() string {
+	if m.IsZero() {
+		return "-"
+	}
+	return fmt.Sprintf("%d.%02d %s", m.Amount/100, m.Amount%100, m.Currency)
+}
+
+func validCurrency(c string) bool {
+	return len(c) == 3 && strings.Trim(c, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == ""
+}
+
+// Fare is a published price on a route. RoundTrip, when set, is the price of an
+// outbound and return journey booked together. ValidFrom and ValidTo bound the
+// departure dates the fare applies to; empty means open-ended.
+type Fare struct {
+	ID        int64
+	RouteCode string
+	OneWay    Money
+	RoundTrip Money
+	ValidFrom string
+	ValidTo   string
+	CreatedAt string
+}
+
+// Normalize trims and uppercases fare identifiers.
+func (f *Fare) Normalize() {
+	f.RouteCode = strings.ToUpper(strings.TrimSpace(f.RouteCode))
+	f.OneWay.Currency = strings.ToUpper(strings.TrimSpace(f.OneWay.Currency))
+	f.RoundTrip.Currency = strings.ToUpper(strings.TrimSpace(f.RoundTrip.Currency))
+	f.ValidFrom = strings.TrimSpace(f.ValidFrom)
+	f.ValidTo = strings.TrimSpace(f.ValidTo)
+}
+
+// Validate ensures the fare has a route, positive amounts in one currency and an ordered validity window.
+func (f Fare) Validate() error {
+	if len(f.RouteCode) == 0 || len(f.RouteCode) > 16 {
+		return ErrInvalidRouteCode
+	}
+	if !validCurrency(f.OneWay.Currency) {
+		return ErrInvalidCurrency
+	}
+	if f.OneWay.Amount <= 0 {
+		return ErrInvalidFareAmount
+	}
+	if !f.RoundTrip.IsZero() && (f.RoundTrip.Currency != f.OneWay.Currency || f.RoundTrip.Amount <= 0) {
+		return ErrInvalidFareAmount
+	}
+	var from, to time.Time
+	var err error
+	if f.ValidFrom != "" {
+		if from, err = time.Parse("2006-01-02", f.ValidFrom); err != nil {
+			return ErrInvalidFareDates
+		}
+	}
+	if f.ValidTo != "" {
+		if to, err = time.Parse("2006-01-02", f.ValidTo); err != nil {
+			return ErrInvalidFareDates
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return ErrInvalidFareDates
+	}
+	return nil
+}
+
+// AppliesOn reports whether the fare covers a YYYY-MM-DD departure date.
+func (f Fare) AppliesOn(date string) bool {
+	return (f.ValidFrom == "" || f.ValidFrom <= date) && (f.ValidTo == "" || date <= f.ValidTo)
+}
+
+// CheapestFare returns the lowest one-way fare applying on date, or nil when none does.
+func CheapestFare(fares []Fare, date string) *Fare {
+	var best *Fare
+	for i := range fares {
+		if !fares[i].AppliesOn(date) {
+			continue
+		}
+		if best == nil || fares[i].OneWay.Amount < best.OneWay.Amount {
+			best = &fares[i]
+		}
+	}
+	return best
+}
+
+// RoundTripPrice prices an outbound and return journey booked together. The
+// outbound fare's round-trip amount applies when it is published; otherwise the
+// two one-way fares are added. It is zero when either direction is unpriced.
+func RoundTripPrice(outbound, inbound *Fare) Money {
+	if outbound != nil && !outbound.RoundTrip.IsZero() {
+		return outbound.RoundTrip
+	}
+	if outbound == nil || inbound == nil {
+		return Money{}
+	}
+	sum, _ := outbound.OneWay.Add(inbound.OneWay)
+	return sum
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+// FareRepository defines storage operations for route fares.
+type FareRepository interface {
+	Create(ctx context.Context, f *Fare) error
+	// List returns fares ordered by route and amount; an empty routeCode lists every route.
+	List(ctx context.Context, routeCode string, limit, offset int) ([]Fare, error)
+	Delete(ctx context.Context, id int64) error
+}
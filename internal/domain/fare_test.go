@@ -0,0 +1,93 @@
+package domain
+
+import "testing"
+
+func TestParseMoney(t *testing.T) {
+	cases := []struct {
+		amount, currency string
+		want             Money
+		err              error
+	}{
+		{"850000", "idr", Money{85000000, "IDR"}, nil},
+		{"129.9", "USD", Money{12990, "USD"}, nil},
+		{"0.05", "USD", Money{5, "USD"}, nil},
+		{"0", "USD", Money{}, ErrInvalidFareAmount},
+		{"1.234", "USD", Money{}, ErrInvalidFareAmount},
+		{"1.", "USD", Money{}, ErrInvalidFareAmount},
+		{"-5", "USD", Money{}, ErrInvalidFareAmount},
+		{"10", "US", Money{}, ErrInvalidCurrency},
+	}
+	for _, tc := range cases {
+		got, err := ParseMoney(tc.amount, tc.currency)
+		if err != tc.err || got != tc.want {
+			t.Fatalf("ParseMoney(%q, %q) = %+v, %v", tc.amount, tc.currency, got, err)
+		}
+	}
+	if s := (Money{12990, "USD"}).String(); s != "129.90 USD" {
+		t.Fatalf("unexpected format %q", s)
+	}
+	if _, ok := (Money{1, "USD"}).Add(Money{1, "IDR"}); ok {
+		t.Fatalf("adding different currencies should fail")
+	}
+}
+
+func TestFareValidate(t *testing.T) {
+	f := Fare{RouteCode: " rt1 ", OneWay: Money{100, "usd"}, RoundTrip: Money{180, "usd"}, ValidFrom: "2025-03-01", ValidTo: "2025-03-31"}
+	f.Normalize()
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	bad := f
+	bad.RoundTrip.Currency = "IDR"
+	if err := bad.Validate(); err != ErrInvalidFareAmount {
+		t.Fatalf("want invalid amount for mixed currency, got %v", err)
+	}
+	bad = f
+	bad.ValidTo = "2025-02-01"
+	if err := bad.Validate(); err != ErrInvalidFareDates {
+		t.Fatalf("want invalid dates, got %v", err)
+	}
+	bad = f
+	bad.RouteCode = ""
+	if err := bad.Validate(); err != ErrInvalidRouteCode {
+		t.Fatalf("want invalid route, got %v", err)
+	}
+}
+
+func TestCheapestFare(t *testing.T) {
+	fares := []Fare{
+		{ID: 1, OneWay: Money{300, "USD"}},
+		{ID: 2, OneWay: Money{200, "USD"}, ValidFrom: "2025-03-10", ValidTo: "2025-03-20"},
+	}
+	if f := CheapestFare(fares, "2025-03-15"); f == nil || f.ID != 2 {
+		t.Fatalf("expected the seasonal fare, got %+v", f)
+	}
+	if f := CheapestFare(fares, "2025-03-21"); f == nil || f.ID != 1 {
+		t.Fatalf("expected the base fare, got %+v", f)
+	}
+	if f := CheapestFare(nil, "2025-03-21"); f != nil {
+		t.Fatalf("expected no fare, got %+v", f)
+	}
+}
+
+func TestRoundTripPrice(t *testing.T) {
+	out := &Fare{OneWay: Money{10000, "USD"}}
+	back := &Fare{OneWay: Money{12000, "USD"}}
+	if got := RoundTripPrice(out, back); got != (Money{22000, "USD"}) {
+		t.Fatalf("expected summed one-way fares, got %v", got)
+	}
+	out.RoundTrip = Money{18000, "USD"}
+	if got := RoundTripPrice(out, back); got != out.RoundTrip {
+		t.Fatalf("expected the round-trip fare, got %v", got)
+	}
+	if got := RoundTripPrice(out, nil); got != out.RoundTrip {
+		t.Fatalf("round-trip fare should not need a return fare, got %v", got)
+	}
+	out.RoundTrip = Money{}
+	if got := RoundTripPrice(out, nil); !got.IsZero() {
+		t.Fatalf("expected no price for an unpriced return, got %v", got)
+	}
+	if got := RoundTripPrice(out, &Fare{OneWay: Money{1, "IDR"}}); !got.IsZero() {
+		t.Fatalf("expected no price across currencies, got %v", got)
+	}
+}
@@ -5,13 +5,33 @@ import (
 	"time"
 )
 
+// Schedule statuses. A cancelled flight is kept with its bookings so they can
+// be rebooked or refunded.
+const (
+	ScheduleStatusScheduled = "SCHEDULED"
+	ScheduleStatusCancelled = "CANCELLED"
+)
+
 // FlightSchedule represents a planned flight on a specific date for a given route and airplane.
 type FlightSchedule struct {
 	ID            int64
 	RouteCode     string
 	AirplaneCode  string
-	DepartureDate string // YYYY-MM-DD in UTC
-	CreatedAt     string
+	DepartureDate string    // YYYY-MM-DD, local calendar date at the origin airport
+	DepartureAt   time.Time // departure instant in UTC
+	ArrivalAt     time.Time // arrival instant in UTC; zero when not yet planned
+	SeriesID      int64     // series that generated the flight; zero for one-off flights
+	Status        string    // ScheduleStatusScheduled or ScheduleStatusCancelled
+	CancelReason  string    // why the flight was cancelled; empty otherwise
+	DelayMinutes  int       // how much later than planned the flight is expected
+	DelayReason   string    // why the flight is delayed; empty when on time
+	DepartureGate string    // gate at the origin airport; empty until assigned
+	ArrivalGate   string    // gate at the destination airport; empty until assigned
+	// OriginTimeZone and DestinationTimeZone are the IANA zones of the route's
+	// airports. They are filled when reading schedules and never stored.
+	OriginTimeZone      string
+	DestinationTimeZone string
+	CreatedAt           string
 }
 
 // Normalize trims and uppercases codes to keep consistency across adapters.
@@ -21,7 +41,8 @@ func (s *FlightSchedule) Normalize() {
 	s.DepartureDate = strings.TrimSpace(s.DepartureDate)
 }
 
-// Validate checks that codes are present and the departure date is a valid ISO date.
+// Validate checks that codes are present, the departure date is a valid ISO date
+// and, when both instants are known, that the flight lands after it departs.
 func (s FlightSchedule) Validate() error {
 	if len(strings.TrimSpace(s.RouteCode)) == 0 || len(s.RouteCode) > 16 {
 		return ErrInvalidScheduleRoute
@@ -32,5 +53,136 @@ func (s FlightSchedule) Validate() error {
 	if _, err := time.Parse("2006-01-02", s.DepartureDate); err != nil {
 		return ErrInvalidScheduleDate
 	}
+	if !s.ArrivalAt.IsZero() && !s.ArrivalAt.After(s.DepartureAt) {
+		return ErrInvalidScheduleTime
+	}
 	return nil
 }
+
+// Cancelled reports whether the flight was cancelled.
+func (s FlightSchedule) Cancelled() bool {
+	return s.Status == ScheduleStatusCancelled
+}
+
+// Delay is how much later than planned the flight is expected.
+func (s FlightSchedule) Delay() time.Duration {
+	return time.Duration(s.DelayMinutes) * time.Minute
+}
+
+// EstimatedDeparture is the planned departure shifted by the delay.
+func (s FlightSchedule) EstimatedDeparture() time.Time {
+	return s.DepartureAt.Add(s.Delay())
+}
+
+// EstimatedArrival is the planned arrival shifted by the delay, or zero when
+// the arrival is not planned.
+func (s FlightSchedule) EstimatedArrival() time.Time {
+	if s.ArrivalAt.IsZero() {
+		return s.ArrivalAt
+	}
+	return s.ArrivalAt.Add(s.Delay())
+}
+
+// NormalizeCancelReason trims a cancellation reason and checks that it is
+// present and fits its column.
+func NormalizeCancelReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > 200 {
+		return "", ErrInvalidCancelReason
+	}
+	return reason, nil
+}
+
+// NormalizeGate trims and uppercases a gate and checks that it fits its
+// column and has no inner spaces. An empty gate clears the assignment.
+func NormalizeGate(gate string) (string, error) {
+	gate = strings.ToUpper(strings.TrimSpace(gate))
+	if len(gate) > 8 || strings.ContainsAny(gate, " \t") {
+		return "", ErrInvalidGate
+	}
+	return gate, nil
+}
+
+// LocalDeparture returns the departure instant in the origin airport's time zone.
+func (s FlightSchedule) LocalDeparture() time.Time {
+	return inZone(s.DepartureAt, s.OriginTimeZone)
+}
+
+// LocalArrival returns the arrival instant in the destination airport's time zone.
+func (s FlightSchedule) LocalArrival() time.Time {
+	return inZone(s.ArrivalAt, s.DestinationTimeZone)
+}
+
+// LocalEstimatedDeparture returns the estimated departure in the origin
+// airport's time zone.
+func (s FlightSchedule) LocalEstimatedDeparture() time.Time {
+	return inZone(s.EstimatedDeparture(), s.OriginTimeZone)
+}
+
+// LocalEstimatedArrival returns the estimated arrival in the destination
+// airport's time zone.
+func (s FlightSchedule) LocalEstimatedArrival() time.Time {
+	return inZone(s.EstimatedArrival(), s.DestinationTimeZone)
+}
+
+// Duration is the scheduled block time, or zero when the arrival is unknown.
+func (s FlightSchedule) Duration() time.Duration {
+	if s.ArrivalAt.IsZero() || s.DepartureAt.IsZero() {
+		return 0
+	}
+	return s.ArrivalAt.Sub(s.DepartureAt)
+}
+
+// ResolveScheduleTimes converts a local departure date and wall-clock times into
+// UTC instants. departureClock is read in the origin zone; arrivalClock, when
+// given, in the destination zone on whichever day first follows the departure,
+// so overnight flights and date-line crossings land on the right date.
+func ResolveScheduleTimes(date, departureClock, arrivalClock string, origin, destination *time.Location) (time.Time, time.Time, error) {
+	day, err := time.Parse("2006-01-02", strings.TrimSpace(date))
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidScheduleDate
+	}
+	if strings.TrimSpace(departureClock) == "" {
+		departureClock = "00:00"
+	}
+	dh, dm, err := parseClock(departureClock)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	departure := time.Date(day.Year(), day.Month(), day.Day(), dh, dm, 0, 0, origin).UTC()
+	if strings.TrimSpace(arrivalClock) == "" {
+		return departure, time.Time{}, nil
+	}
+	ah, am, err := parseClock(arrivalClock)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	// Start the day before to allow for westbound date-line crossings.
+	for offset := -1; offset <= 2; offset++ {
+		d := day.AddDate(0, 0, offset)
+		arrival := time.Date(d.Year(), d.Month(), d.Day(), ah, am, 0, 0, destination).UTC()
+		if arrival.After(departure) {
+			return departure, arrival, nil
+		}
+	}
+	return time.Time{}, time.Time{}, ErrInvalidScheduleTime
+}
+
+func parseClock(clock string) (int, int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, 0, ErrInvalidScheduleTime
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+func inZone(t time.Time, zone string) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	loc, err := LoadTimeZone(zone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc)
+}
@@ -1,11 +1,44 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // FlightScheduleRepository handles persistence for scheduled flights.
 type FlightScheduleRepository interface {
 	Create(ctx context.Context, s *FlightSchedule) error
 	GetByID(ctx context.Context, id int64) (*FlightSchedule, error)
 	List(ctx context.Context, routeCode string, limit, offset int) ([]FlightSchedule, error)
-	Delete(ctx context.Context, id int64) error
+	// ListByAirplane returns an airplane's schedules ordered by departure instant.
+	ListByAirplane(ctx context.Context, airplaneCode string, limit, offset int) ([]FlightSchedule, error)
+	// ListByAirport returns the flights departing from the airport, or with
+	// arrivals those arriving at it, at estimated instants in [from, to),
+	// ordered by that instant, then by ID, and with their seat load. The
+	// estimated instant is the planned one shifted by the delay; a flight
+	// without a planned arrival arrives its route's block time after it
+	// departs, and is left off the arrivals when the route has none.
+	ListByAirport(ctx context.Context, airportCode string, arrivals bool, from, to time.Time, limit, offset int) ([]BoardFlight, error)
+	// Update rewrites the schedule's airplane, date and times in place, keeping
+	// its bookings. In the same transaction the seat inventory is resized to the
+	// airplane and bookings on seats the airplane lacks move to the lowest free
+	// seats. It fails with a *CapacityError when the bookings do not fit.
+	Update(ctx context.Context, s *FlightSchedule) ([]SeatMove, error)
+	// Cancel marks the schedule cancelled with the reason and flags its
+	// bookings that are not cancelled as affected, returning how many. The
+	// schedule and its bookings are kept. It fails with ErrScheduleCancelled
+	// when the schedule already is.
+	Cancel(ctx context.Context, id int64, reason string, at time.Time) (int, error)
+	// Delay records the delays and queues the notifications in one
+	// transaction. It fails with ErrScheduleChanged, recording nothing, when
+	// any delay's schedule no longer exists or has been cancelled.
+	Delay(ctx context.Context, delays []ScheduleDelay, notices []Notification) error
+	// SetGates stores the schedule's departure and arrival gates. It fails
+	// with ErrScheduleNotFound when the schedule does not exist.
+	SetGates(ctx context.Context, id int64, departureGate, arrivalGate string) error
+	// Delete removes the schedule. It fails with an *InUseError while the
+	// schedule has bookings, unless cascade holds their count: the bookings
+	// are then removed too and counted in the result. It fails with
+	// ErrDependentsChanged when the count differs.
+	Delete(ctx context.Context, id int64, cascade *Dependents) (Dependents, error)
 }
@@ -1,6 +1,10 @@
 package domain
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestFlightScheduleNormalizeValidate(t *testing.T) {
 	s := &FlightSchedule{RouteCode: " rt-01 ", AirplaneCode: " a320 ", DepartureDate: "2025-01-02"}
@@ -28,3 +32,84 @@ func TestFlightScheduleValidate_Errors(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeCancelReason(t *testing.T) {
+	if got, err := NormalizeCancelReason("  crew shortage "); err != nil || got != "crew shortage" {
+		t.Fatalf("unexpected reason %q err=%v", got, err)
+	}
+	for _, reason := range []string{"", "   ", strings.Repeat("x", 201)} {
+		if _, err := NormalizeCancelReason(reason); err != ErrInvalidCancelReason {
+			t.Fatalf("want invalid reason for %q, got %v", reason, err)
+		}
+	}
+	if (FlightSchedule{Status: ScheduleStatusScheduled}).Cancelled() || !(FlightSchedule{Status: ScheduleStatusCancelled}).Cancelled() {
+		t.Fatalf("unexpected Cancelled result")
+	}
+}
+
+func TestResolveScheduleTimes_Overnight(t *testing.T) {
+	jakarta, _ := LoadTimeZone("Asia/Jakarta")
+	tokyo, _ := LoadTimeZone("Asia/Tokyo")
+	dep, arr, err := ResolveScheduleTimes("2025-03-15", "23:10", "08:25", jakarta, tokyo)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if got := dep.Format(time.RFC3339); got != "2025-03-15T16:10:00Z" {
+		t.Fatalf("unexpected departure %s", got)
+	}
+	if got := arr.In(tokyo).Format("2006-01-02 15:04"); got != "2025-03-16 08:25" {
+		t.Fatalf("overnight arrival should land next day, got %s", got)
+	}
+	s := FlightSchedule{RouteCode: "R1", AirplaneCode: "A1", DepartureDate: "2025-03-15", DepartureAt: dep, ArrivalAt: arr, OriginTimeZone: "Asia/Jakarta", DestinationTimeZone: "Asia/Tokyo"}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if s.Duration() != 7*time.Hour+15*time.Minute {
+		t.Fatalf("unexpected duration %s", s.Duration())
+	}
+	if s.LocalDeparture().Format("15:04") != "23:10" || s.LocalArrival().Format("15:04") != "08:25" {
+		t.Fatalf("local times wrong: %s %s", s.LocalDeparture(), s.LocalArrival())
+	}
+}
+
+func TestResolveScheduleTimes_DateLineAndErrors(t *testing.T) {
+	tokyo, _ := LoadTimeZone("Asia/Tokyo")
+	honolulu, _ := LoadTimeZone("Pacific/Honolulu")
+	dep, arr, err := ResolveScheduleTimes("2025-01-02", "21:00", "09:30", tokyo, honolulu)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if got := arr.In(honolulu).Format("2006-01-02"); got != "2025-01-02" {
+		t.Fatalf("eastbound date-line arrival should keep the local date, got %s", got)
+	}
+	if arr.Sub(dep) != 7*time.Hour+30*time.Minute {
+		t.Fatalf("unexpected duration %s", arr.Sub(dep))
+	}
+	if _, arr, err := ResolveScheduleTimes("2025-01-02", "", "", time.UTC, time.UTC); err != nil || !arr.IsZero() {
+		t.Fatalf("departure-only schedule: err=%v arr=%s", err, arr)
+	}
+	if _, _, err := ResolveScheduleTimes("2025-01-02", "25:00", "", time.UTC, time.UTC); err != ErrInvalidScheduleTime {
+		t.Fatalf("want time err, got %v", err)
+	}
+	if _, _, err := ResolveScheduleTimes("bad", "10:00", "", time.UTC, time.UTC); err != ErrInvalidScheduleDate {
+		t.Fatalf("want date err, got %v", err)
+	}
+	s := FlightSchedule{RouteCode: "R1", AirplaneCode: "A1", DepartureDate: "2025-01-02", DepartureAt: dep, ArrivalAt: dep}
+	if err := s.Validate(); err != ErrInvalidScheduleTime {
+		t.Fatalf("want time err for zero-length flight, got %v", err)
+	}
+}
+
+func TestNormalizeGate(t *testing.T) {
+	if g, err := NormalizeGate(" a12 "); err != nil || g != "A12" {
+		t.Fatalf("unexpected gate %q err=%v", g, err)
+	}
+	if g, err := NormalizeGate(" "); err != nil || g != "" {
+		t.Fatalf("want a cleared gate, got %q err=%v", g, err)
+	}
+	for _, bad := range []string{"GATE-1234", "A 12"} {
+		if _, err := NormalizeGate(bad); err != ErrInvalidGate {
+			t.Fatalf("want invalid gate for %q, got %v", bad, err)
+		}
+	}
+}
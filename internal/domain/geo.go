@@ -0,0 +1,39 @@
+package domain
+
+import "math"
+
+// earthRadiusKm is the mean radius used for great-circle distances.
+const earthRadiusKm = 6371.0
+
+// Coordinates is a position on the Earth in decimal degrees.
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Validate ensures the latitude is within ±90 and the longitude within ±180 degrees.
+func (c Coordinates) Validate() error {
+	if math.IsNaN(c.Latitude) || math.IsNaN(c.Longitude) || c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+		return ErrInvalidCoordinates
+	}
+	return nil
+}
+
+// DistanceKm returns the great-circle distance to another position using the
+// haversine formula.
+func (c Coordinates) DistanceKm(to Coordinates) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(to.Latitude - c.Latitude)
+	dLon := rad(to.Longitude - c.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rad(c.Latitude))*math.Cos(rad(to.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// GreatCircleKm returns the distance between two airport positions rounded to
+// the kilometre, or 0 when either position is unknown.
+func GreatCircleKm(from, to *Coordinates) int {
+	if from == nil || to == nil {
+		return 0
+	}
+	return int(math.Round(from.DistanceKm(*to)))
+}
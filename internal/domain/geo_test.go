@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestCoordinates_DistanceAndValidate(t *testing.T) {
+	cgk := &Coordinates{Latitude: -6.1256, Longitude: 106.6559}
+	dps := &Coordinates{Latitude: -8.7482, Longitude: 115.1672}
+	if km := GreatCircleKm(cgk, dps); km != 983 {
+		t.Fatalf("CGK-DPS: want 983 km, got %d", km)
+	}
+	if km := GreatCircleKm(cgk, cgk); km != 0 {
+		t.Fatalf("same airport: want 0 km, got %d", km)
+	}
+	if km := GreatCircleKm(cgk, nil); km != 0 {
+		t.Fatalf("unknown position: want 0 km, got %d", km)
+	}
+	if err := cgk.Validate(); err != nil {
+		t.Fatalf("valid coordinates rejected: %v", err)
+	}
+	for _, c := range []Coordinates{{Latitude: 91}, {Latitude: -91}, {Longitude: 181}, {Longitude: -180.5}} {
+		if err := c.Validate(); err != ErrInvalidCoordinates {
+			t.Fatalf("%+v: want invalid coordinates, got %v", c, err)
+		}
+	}
+}
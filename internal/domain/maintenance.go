@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maintenanceReasonMax bounds the free-text reason of a maintenance window.
+const maintenanceReasonMax = 200
+
+// maintenanceTimeLayout renders window boundaries in errors.
+const maintenanceTimeLayout = "2006-01-02 15:04 MST"
+
+// MaintenanceWindow is a period in which an airplane is out of service and
+// cannot be assigned to flights. StartsAt is inclusive and EndsAt exclusive.
+type MaintenanceWindow struct {
+	ID           int64
+	AirplaneCode string
+	StartsAt     time.Time
+	EndsAt       time.Time
+	Reason       string
+	CreatedAt    string
+}
+
+func (m *MaintenanceWindow) Normalize() {
+	m.AirplaneCode = strings.ToUpper(strings.TrimSpace(m.AirplaneCode))
+	m.Reason = strings.TrimSpace(m.Reason)
+	m.StartsAt = m.StartsAt.UTC()
+	m.EndsAt = m.EndsAt.UTC()
+}
+
+func (m MaintenanceWindow) Validate() error {
+	if m.AirplaneCode == "" || len(m.AirplaneCode) > 16 {
+		return ErrInvalidAirplaneCode
+	}
+	if m.StartsAt.IsZero() || !m.EndsAt.After(m.StartsAt) {
+		return ErrInvalidMaintenanceWindow
+	}
+	if len(m.Reason) > maintenanceReasonMax {
+		return ErrInvalidMaintenanceReason
+	}
+	return nil
+}
+
+// Blocks reports whether the window falls within the time the flight keeps
+// its airplane busy.
+func (m MaintenanceWindow) Blocks(s FlightSchedule) bool {
+	from, to := FlightSpan(s)
+	return from.Before(m.EndsAt) && to.After(m.StartsAt)
+}
+
+// FlightSpan is the time a flight keeps its airplane busy: from departure to
+// arrival, or the departure minute when the arrival is not planned.
+func FlightSpan(s FlightSchedule) (time.Time, time.Time) {
+	if s.ArrivalAt.After(s.DepartureAt) {
+		return s.DepartureAt, s.ArrivalAt
+	}
+	return s.DepartureAt, s.DepartureAt.Add(time.Minute)
+}
+
+// ParseMaintenanceTime reads a maintenance boundary given as a UTC date
+// ("2025-01-02", meaning midnight), a UTC date and time ("2025-01-02T08:00")
+// or an RFC 3339 timestamp.
+func ParseMaintenanceTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, ErrInvalidMaintenanceWindow
+}
+
+// MaintenanceError rejects assigning an airplane to a flight that falls in one
+// of its maintenance windows.
+type MaintenanceError struct {
+	Window MaintenanceWindow
+}
+
+func (e *MaintenanceError) Error() string {
+	msg := fmt.Sprintf("airplane %s is in maintenance from %s to %s", e.Window.AirplaneCode, e.Window.StartsAt.Format(maintenanceTimeLayout), e.Window.EndsAt.Format(maintenanceTimeLayout))
+	if e.Window.Reason != "" {
+		msg += " (" + e.Window.Reason + ")"
+	}
+	return msg
+}
+
+// Is lets errors.Is match ErrAirplaneInMaintenance.
+func (e *MaintenanceError) Is(target error) bool { return target == ErrAirplaneInMaintenance }
+
+// MaintenanceConflict is a flight with confirmed or held bookings that falls
+// in a maintenance window of its airplane.
+type MaintenanceConflict struct {
+	Schedule FlightSchedule
+	Booked   int
+}
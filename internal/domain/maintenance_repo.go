@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MaintenanceRepository stores airplane maintenance windows.
+type MaintenanceRepository interface {
+	Create(ctx context.Context, w *MaintenanceWindow) error
+	// ListByAirplane returns the airplane's windows ending after from, by start.
+	ListByAirplane(ctx context.Context, airplaneCode string, from time.Time) ([]MaintenanceWindow, error)
+	// Overlapping returns the airplane's windows overlapping [from, to), by start.
+	Overlapping(ctx context.Context, airplaneCode string, from, to time.Time) ([]MaintenanceWindow, error)
+	// BookedFlights returns the airplane's flights within the window that have
+	// confirmed or held bookings, by departure.
+	BookedFlights(ctx context.Context, w MaintenanceWindow) ([]MaintenanceConflict, error)
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	start := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	w := MaintenanceWindow{AirplaneCode: " a320 ", StartsAt: start, EndsAt: start.Add(24 * time.Hour), Reason: " C-check "}
+	w.Normalize()
+	if err := w.Validate(); err != nil || w.AirplaneCode != "A320" || w.Reason != "C-check" {
+		t.Fatalf("unexpected window %+v err=%v", w, err)
+	}
+	if err := (MaintenanceWindow{AirplaneCode: "A320", StartsAt: start, EndsAt: start}).Validate(); err != ErrInvalidMaintenanceWindow {
+		t.Fatalf("want empty window rejected, got %v", err)
+	}
+	if err := (MaintenanceWindow{AirplaneCode: "A320", StartsAt: start, EndsAt: start.Add(time.Hour), Reason: strings.Repeat("x", 201)}).Validate(); err != ErrInvalidMaintenanceReason {
+		t.Fatalf("want long reason rejected, got %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		flight  FlightSchedule
+		blocked bool
+	}{
+		{"lands inside", FlightSchedule{DepartureAt: start.Add(-time.Hour), ArrivalAt: start.Add(time.Minute)}, true},
+		{"lands as it starts", FlightSchedule{DepartureAt: start.Add(-time.Hour), ArrivalAt: start}, false},
+		{"departs as it ends", FlightSchedule{DepartureAt: start.Add(24 * time.Hour)}, false},
+		{"unplanned arrival inside", FlightSchedule{DepartureAt: start}, true},
+	}
+	for _, c := range cases {
+		if got := w.Blocks(c.flight); got != c.blocked {
+			t.Errorf("%s: blocks = %v, want %v", c.name, got, c.blocked)
+		}
+	}
+
+	err := error(&MaintenanceError{Window: w})
+	if !errors.Is(err, ErrAirplaneInMaintenance) || err.Error() != "airplane A320 is in maintenance from 2025-01-03 00:00 UTC to 2025-01-04 00:00 UTC (C-check)" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestParseMaintenanceTime(t *testing.T) {
+	want := time.Date(2025, 1, 3, 8, 0, 0, 0, time.UTC)
+	for _, in := range []string{"2025-01-03T08:00", "2025-01-03T15:00:00+07:00"} {
+		if got, err := ParseMaintenanceTime(in); err != nil || !got.Equal(want) || got.Location() != time.UTC {
+			t.Errorf("%s: got %v err=%v", in, got, err)
+		}
+	}
+	if got, err := ParseMaintenanceTime("2025-01-03"); err != nil || !got.Equal(want.Add(-8*time.Hour)) {
+		t.Errorf("date: got %v err=%v", got, err)
+	}
+	if _, err := ParseMaintenanceTime("03/01/2025"); err != ErrInvalidMaintenanceWindow {
+		t.Errorf("want invalid, got %v", err)
+	}
+}
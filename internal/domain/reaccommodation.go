@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SeatMove records a booking moved to another seat on the same flight.
+type SeatMove struct {
+	ScheduleID int64
+	BookingID  int64
+	Reference  string
+	From       int
+	To         int
+}
+
+// CapacityError reports a flight whose confirmed and held bookings do not fit
+// the seats of the airplane it was to fly with.
+type CapacityError struct {
+	ScheduleID int64
+	Capacity   int
+	Booked     int
+}
+
+func (e *CapacityError) Error() string {
+	return fmt.Sprintf("schedule %d: %d booking(s) do not fit %d seat(s)", e.ScheduleID, e.Booked, e.Capacity)
+}
+
+// Is lets errors.Is match ErrCapacityBelowBookings.
+func (e *CapacityError) Is(target error) bool { return target == ErrCapacityBelowBookings }
+
+// Shortfall is the number of bookings left without a seat.
+func (e *CapacityError) Shortfall() int { return e.Booked - e.Capacity }
+
+// FleetCapacityError reports the future flights of an airplane whose confirmed
+// and held bookings would not fit a new seat capacity.
+type FleetCapacityError struct {
+	AirplaneCode string
+	Capacity     int
+	Flights      []CapacityError
+}
+
+func (e *FleetCapacityError) Error() string {
+	flights := make([]string, 0, len(e.Flights))
+	for _, f := range e.Flights {
+		flights = append(flights, fmt.Sprintf("schedule %d (%d booked)", f.ScheduleID, f.Booked))
+	}
+	return fmt.Sprintf("airplane %s: %d future flight(s) have more bookings than %d seat(s): %s", e.AirplaneCode, len(e.Flights), e.Capacity, strings.Join(flights, ", "))
+}
+
+// Is lets errors.Is match ErrCapacityBelowBookings.
+func (e *FleetCapacityError) Is(target error) bool { return target == ErrCapacityBelowBookings }
+
+// CapacityChange reports what resizing an airplane did to its future flights:
+// bookings reseated within the new capacity and, when forced, flights left
+// with more bookings than seats.
+type CapacityChange struct {
+	Moves      []SeatMove
+	Overbooked []CapacityError
+}
+
+// Reaccommodation lists the bookings of a flight that no longer fit and other
+// flights on the same route that still have seats for them.
+type Reaccommodation struct {
+	Schedule     FlightSchedule
+	Displaced    []Booking
+	Alternatives []SeatOffer
+}
+
+// SeatOffer is a flight with seats left for sale.
+type SeatOffer struct {
+	Schedule  FlightSchedule
+	SeatsLeft int
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rotation issue kinds.
+const (
+	RotationPositioning = "positioning" // departs from an airport other than where the airplane landed
+	RotationOverlap     = "overlap"     // departs before the previous flight lands
+	RotationTurnaround  = "turnaround"  // on the ground for less than the minimum turnaround
+)
+
+// RotationLeg is one flight of an airplane's rotation with its route's airports.
+type RotationLeg struct {
+	Schedule        FlightSchedule
+	OriginCode      string
+	DestinationCode string
+}
+
+// RotationIssue is a break between two consecutive flights of the same airplane.
+type RotationIssue struct {
+	Kind     string
+	Previous RotationLeg
+	Next     RotationLeg
+	Ground   time.Duration // from landing to the next departure; negative when the flights overlap
+}
+
+func (i RotationIssue) String() string {
+	prev, next := i.Previous.Schedule, i.Next.Schedule
+	switch i.Kind {
+	case RotationPositioning:
+		return fmt.Sprintf("schedule %s departs %s but schedule %s lands at %s", scheduleLabel(next), i.Next.OriginCode, scheduleLabel(prev), i.Previous.DestinationCode)
+	case RotationOverlap:
+		return fmt.Sprintf("schedule %s departs %s before schedule %s lands", scheduleLabel(next), (-i.Ground).String(), scheduleLabel(prev))
+	default:
+		return fmt.Sprintf("schedule %s departs %s after schedule %s lands", scheduleLabel(next), i.Ground.String(), scheduleLabel(prev))
+	}
+}
+
+// scheduleLabel names a schedule by ID, or as new before it is stored.
+func scheduleLabel(s FlightSchedule) string {
+	if s.ID == 0 {
+		return "(new)"
+	}
+	return fmt.Sprint(s.ID)
+}
+
+// Involves reports whether the issue concerns the schedule with the given ID.
+func (i RotationIssue) Involves(id int64) bool {
+	return i.Previous.Schedule.ID == id || i.Next.Schedule.ID == id
+}
+
+// CheckRotation orders an airplane's flights by departure and reports every
+// consecutive pair that does not chain: the next flight must leave from the
+// airport the previous one landed at, after it landed and at least
+// minTurnaround later. Timing is not checked after a flight without a planned
+// arrival.
+func CheckRotation(legs []RotationLeg, minTurnaround time.Duration) []RotationIssue {
+	ordered := append([]RotationLeg(nil), legs...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i].Schedule, ordered[j].Schedule
+		if !a.DepartureAt.Equal(b.DepartureAt) {
+			return a.DepartureAt.Before(b.DepartureAt)
+		}
+		return a.ID < b.ID
+	})
+	var issues []RotationIssue
+	for k := 1; k < len(ordered); k++ {
+		prev, next := ordered[k-1], ordered[k]
+		if prev.DestinationCode != next.OriginCode {
+			issues = append(issues, RotationIssue{Kind: RotationPositioning, Previous: prev, Next: next})
+		}
+		if prev.Schedule.ArrivalAt.IsZero() {
+			continue
+		}
+		ground := next.Schedule.DepartureAt.Sub(prev.Schedule.ArrivalAt)
+		switch {
+		case ground < 0:
+			issues = append(issues, RotationIssue{Kind: RotationOverlap, Previous: prev, Next: next, Ground: ground})
+		case ground < minTurnaround:
+			issues = append(issues, RotationIssue{Kind: RotationTurnaround, Previous: prev, Next: next, Ground: ground})
+		}
+	}
+	return issues
+}
+
+// RotationError rejects a schedule that breaks its airplane's rotation.
+type RotationError struct {
+	AirplaneCode string
+	Issues       []RotationIssue
+}
+
+func (e *RotationError) Error() string {
+	parts := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		parts[i] = issue.String()
+	}
+	return fmt.Sprintf("airplane %s rotation broken: %s", e.AirplaneCode, strings.Join(parts, "; "))
+}
+
+func (e *RotationError) Is(target error) bool { return target == ErrRotationConflict }
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckRotation(t *testing.T) {
+	t0 := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	leg := func(id int64, from, to string, dep time.Duration, block time.Duration) RotationLeg {
+		s := FlightSchedule{ID: id, DepartureAt: t0.Add(dep)}
+		if block > 0 {
+			s.ArrivalAt = s.DepartureAt.Add(block)
+		}
+		return RotationLeg{Schedule: s, OriginCode: from, DestinationCode: to}
+	}
+	legs := []RotationLeg{
+		leg(4, "SUB", "CGK", 12*time.Hour, 0),
+		leg(1, "CGK", "DPS", 0, 2*time.Hour),
+		leg(2, "DPS", "CGK", 2*time.Hour+20*time.Minute, 2*time.Hour),
+		leg(3, "CGK", "SUB", 4*time.Hour, time.Hour),
+		leg(5, "SUB", "CGK", 13*time.Hour, time.Hour),
+	}
+	issues := CheckRotation(legs, 30*time.Minute)
+	if len(issues) != 3 {
+		t.Fatalf("want three issues, got %+v", issues)
+	}
+	want := []struct {
+		kind       string
+		prev, next int64
+		ground     time.Duration
+	}{
+		{RotationTurnaround, 1, 2, 20 * time.Minute},
+		{RotationOverlap, 2, 3, -20 * time.Minute},
+		{RotationPositioning, 4, 5, 0},
+	}
+	for i, w := range want {
+		got := issues[i]
+		if got.Kind != w.kind || got.Previous.Schedule.ID != w.prev || got.Next.Schedule.ID != w.next || got.Ground != w.ground {
+			t.Fatalf("issue %d: want %+v, got %s %d->%d %s", i, w, got.Kind, got.Previous.Schedule.ID, got.Next.Schedule.ID, got.Ground)
+		}
+	}
+	if s := issues[1].String(); s != "schedule 3 departs 20m0s before schedule 2 lands" {
+		t.Fatalf("unexpected message %q", s)
+	}
+	if got := CheckRotation(legs[1:3], 20*time.Minute); len(got) != 0 {
+		t.Fatalf("want a 20 minute turnaround accepted, got %+v", got)
+	}
+}
+
+func TestRotationError(t *testing.T) {
+	err := error(&RotationError{AirplaneCode: "PK-GQA", Issues: []RotationIssue{{
+		Kind:     RotationPositioning,
+		Previous: RotationLeg{Schedule: FlightSchedule{ID: 3}, DestinationCode: "SUB"},
+		Next:     RotationLeg{OriginCode: "DPS"},
+	}}})
+	if !errors.Is(err, ErrRotationConflict) {
+		t.Fatalf("want rotation conflict, got %v", err)
+	}
+	if err.Error() != "airplane PK-GQA rotation broken: schedule (new) departs DPS but schedule 3 lands at SUB" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+}
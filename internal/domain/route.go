@@ -1,14 +1,36 @@
 package domain
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
-// Route represents a direct path between two airports.
+// MaxBlockMinutes caps a route's scheduled block time at one day.
+const MaxBlockMinutes = 24 * 60
+
+// Route represents a direct path between two airports. DistanceKm is the
+// great-circle distance between the airports, computed from their positions
+// and 0 when either is unknown. BlockMinutes is the scheduled gate-to-gate
+// time; 0 means none is set. ArchivedAt is set once the route is retired
+// from new schedules.
 type Route struct {
 	ID              int64
 	Code            string
 	OriginCode      string
 	DestinationCode string
+	DistanceKm      int
+	BlockMinutes    int
 	CreatedAt       string
+	ArchivedAt      string
+}
+
+// Archived reports whether the route was retired from new schedules.
+func (r Route) Archived() bool { return r.ArchivedAt != "" }
+
+// BlockTime is the scheduled block time, or 0 when none is set.
+func (r Route) BlockTime() time.Duration {
+	return time.Duration(r.BlockMinutes) * time.Minute
 }
 
 // Normalize trims whitespace and uppercases route identifiers and airport codes.
@@ -32,5 +54,32 @@ func (r Route) Validate() error {
 	if r.OriginCode == r.DestinationCode {
 		return ErrInvalidRouteAirports
 	}
+	if r.BlockMinutes < 0 || r.BlockMinutes > MaxBlockMinutes {
+		return ErrInvalidBlockTime
+	}
 	return nil
 }
+
+// BookedFlight is a flight with its confirmed and held bookings.
+type BookedFlight struct {
+	Schedule FlightSchedule
+	Booked   int
+}
+
+// RouteBookingsError refuses to move a route to other airports while its
+// future flights carry bookings.
+type RouteBookingsError struct {
+	RouteCode string
+	Flights   []BookedFlight
+}
+
+func (e *RouteBookingsError) Error() string {
+	flights := make([]string, 0, len(e.Flights))
+	for _, f := range e.Flights {
+		flights = append(flights, fmt.Sprintf("schedule %d (%d booked)", f.Schedule.ID, f.Booked))
+	}
+	return fmt.Sprintf("route %s: %d future flight(s) have bookings: %s", e.RouteCode, len(e.Flights), strings.Join(flights, ", "))
+}
+
+// Is lets errors.Is match ErrRouteHasBookings.
+func (e *RouteBookingsError) Is(target error) bool { return target == ErrRouteHasBookings }
@@ -1,11 +1,31 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
-// RouteRepository defines storage operations for flight routes.
+// RouteRepository defines storage operations for flight routes. Routes are
+// read with their distance computed from the airports' positions. GetByCode
+// also finds archived routes, which List leaves out unless includeArchived is
+// set.
 type RouteRepository interface {
 	Create(ctx context.Context, r *Route) error
 	GetByCode(ctx context.Context, code string) (*Route, error)
-	List(ctx context.Context, limit, offset int) ([]Route, error)
-	Delete(ctx context.Context, code string) error
+	List(ctx context.Context, limit, offset int, includeArchived bool) ([]Route, error)
+	// SetBlockTime stores the route's scheduled block time; 0 clears it.
+	SetBlockTime(ctx context.Context, code string, minutes int) error
+	// SetArchived archives the route, keeping when it first was, or restores it.
+	SetArchived(ctx context.Context, code string, archived bool) error
+	// Update moves the route to r's origin and destination, re-dating its
+	// flights in the new origin's time zone. When flights departing at or
+	// after from have confirmed or held bookings it fails with a
+	// *RouteBookingsError, unless force is set; forced updates keep those
+	// bookings and return the flights.
+	Update(ctx context.Context, r *Route, from time.Time, force bool) ([]BookedFlight, error)
+	// Delete removes the route. While flights, series or fares depend on it
+	// it fails with an *InUseError, unless cascade holds their counts: they
+	// are then removed too, with the flights' bookings, and counted in the
+	// result. It fails with ErrDependentsChanged when the counts differ.
+	Delete(ctx context.Context, code string, cascade *Dependents) (Dependents, error)
 }
@@ -22,6 +22,8 @@ func TestRouteValidate_Errors(t *testing.T) {
 		{Route{Code: "R1", OriginCode: "", DestinationCode: "DPS"}, ErrInvalidRouteAirports},
 		{Route{Code: "R1", OriginCode: "CGK", DestinationCode: ""}, ErrInvalidRouteAirports},
 		{Route{Code: "R1", OriginCode: "CGK", DestinationCode: "CGK"}, ErrInvalidRouteAirports},
+		{Route{Code: "R1", OriginCode: "CGK", DestinationCode: "DPS", BlockMinutes: -1}, ErrInvalidBlockTime},
+		{Route{Code: "R1", OriginCode: "CGK", DestinationCode: "DPS", BlockMinutes: MaxBlockMinutes + 1}, ErrInvalidBlockTime},
 	}
 	for _, tc := range cases {
 		if err := tc.r.Validate(); err != tc.err {
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxSeriesDays bounds how long a single series may run, so one command cannot
+// materialise years of flights by mistake.
+const MaxSeriesDays = 366
+
+// Weekdays is a set of days of the week, one bit per time.Weekday.
+type Weekdays uint8
+
+// AllWeekdays operates every day.
+const AllWeekdays Weekdays = 1<<7 - 1
+
+var weekdayNames = [...]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// ParseWeekdays reads a comma separated list of three-letter day names such as
+// "Mon,Wed,Fri", case-insensitively. "daily" selects every day.
+func ParseWeekdays(s string) (Weekdays, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "daily") {
+		return AllWeekdays, nil
+	}
+	var days Weekdays
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		found := false
+		for d, name := range weekdayNames {
+			if strings.EqualFold(part, name) {
+				days |= 1 << d
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, ErrInvalidSeriesDays
+		}
+	}
+	return days, nil
+}
+
+// Has reports whether d is in the set.
+func (w Weekdays) Has(d time.Weekday) bool {
+	return w&(1<<d) != 0
+}
+
+// String lists the days Monday first, for example "Mon,Wed,Fri".
+func (w Weekdays) String() string {
+	if w == AllWeekdays {
+		return "daily"
+	}
+	var names []string
+	for i := 1; i <= 7; i++ {
+		if d := time.Weekday(i % 7); w.Has(d) {
+			names = append(names, weekdayNames[d])
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ScheduleSeries is a recurring pattern of flights on a route: one departure on
+// each selected weekday between two dates. The flights it generates are
+// ordinary schedules that remember the series they belong to.
+type ScheduleSeries struct {
+	ID            int64
+	RouteCode     string
+	AirplaneCode  string
+	StartDate     string // YYYY-MM-DD, first possible departure date
+	EndDate       string // YYYY-MM-DD, last possible departure date
+	Days          Weekdays
+	DepartureTime string // HH:MM local to the origin airport
+	ArrivalTime   string // HH:MM local to the destination airport; empty when unplanned
+	CancelledAt   string // RFC3339; empty while the series is active
+	CreatedAt     string
+}
+
+// Normalize trims and uppercases codes and trims dates and times.
+func (s *ScheduleSeries) Normalize() {
+	s.RouteCode = strings.ToUpper(strings.TrimSpace(s.RouteCode))
+	s.AirplaneCode = strings.ToUpper(strings.TrimSpace(s.AirplaneCode))
+	s.StartDate = strings.TrimSpace(s.StartDate)
+	s.EndDate = strings.TrimSpace(s.EndDate)
+	s.DepartureTime = strings.TrimSpace(s.DepartureTime)
+	s.ArrivalTime = strings.TrimSpace(s.ArrivalTime)
+}
+
+// Validate checks the codes, that the dates form a range of at most
+// MaxSeriesDays, that at least one weekday is selected and that the times are
+// valid clock times.
+func (s ScheduleSeries) Validate() error {
+	if err := (FlightSchedule{RouteCode: s.RouteCode, AirplaneCode: s.AirplaneCode, DepartureDate: s.StartDate}).Validate(); err != nil {
+		return err
+	}
+	start, end, err := s.dateRange()
+	if err != nil {
+		return err
+	}
+	if end.Before(start) || end.Sub(start) >= MaxSeriesDays*24*time.Hour {
+		return ErrInvalidSeriesDates
+	}
+	if s.Days == 0 || s.Days > AllWeekdays {
+		return ErrInvalidSeriesDays
+	}
+	if _, _, err := parseClock(s.DepartureTime); err != nil {
+		return err
+	}
+	if s.ArrivalTime != "" {
+		if _, _, err := parseClock(s.ArrivalTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cancelled reports whether the series was cancelled.
+func (s ScheduleSeries) Cancelled() bool {
+	return s.CancelledAt != ""
+}
+
+// Dates lists the departure dates of the series after the given date (exclusive;
+// empty means from the start date), in order.
+func (s ScheduleSeries) Dates(after string) []string {
+	start, end, err := s.dateRange()
+	if err != nil {
+		return nil
+	}
+	var dates []string
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		if s.Days.Has(d.Weekday()) && date > after {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+func (s ScheduleSeries) dateRange() (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01-02", s.StartDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidSeriesDates
+	}
+	end, err := time.Parse("2006-01-02", s.EndDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidSeriesDates
+	}
+	return start, end, nil
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ScheduleSeriesRepository persists recurring schedule patterns together with
+// the flights they generate.
+type ScheduleSeriesRepository interface {
+	// Create stores the series and each of its flights that does not already
+	// exist on the route, airplane and date, returning the flights created.
+	Create(ctx context.Context, s *ScheduleSeries, flights []FlightSchedule) ([]FlightSchedule, error)
+	GetByID(ctx context.Context, id int64) (*ScheduleSeries, error)
+	List(ctx context.Context, limit, offset int) ([]ScheduleSeries, error)
+	// Flights returns the schedules generated by the series, earliest first.
+	Flights(ctx context.Context, id int64) ([]FlightSchedule, error)
+	// Extend stores the series' new end date and adds its flights like Create.
+	Extend(ctx context.Context, s *ScheduleSeries, flights []FlightSchedule) ([]FlightSchedule, error)
+	// Amend stores the series' times and moves the given flights to their new times.
+	Amend(ctx context.Context, s *ScheduleSeries, flights []FlightSchedule) error
+	// Cancel marks the series cancelled and deletes its flights departing at or
+	// after from that have no bookings. Flights with bookings are kept.
+	Cancel(ctx context.Context, id int64, from time.Time) (removed, kept int, err error)
+}
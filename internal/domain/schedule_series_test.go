@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWeekdays(t *testing.T) {
+	days, err := ParseWeekdays("mon, Wed,FRI")
+	if err != nil || days.String() != "Mon,Wed,Fri" {
+		t.Fatalf("want Mon,Wed,Fri, got %q err=%v", days, err)
+	}
+	if days, err := ParseWeekdays("Daily"); err != nil || days != AllWeekdays || days.String() != "daily" {
+		t.Fatalf("want every day, got %q err=%v", days, err)
+	}
+	if days, _ := ParseWeekdays("Sun,Sat"); days.String() != "Sat,Sun" {
+		t.Fatalf("want weekend listed Monday first, got %q", days)
+	}
+	for _, bad := range []string{"", "Monday", "Mon,,Fri", "Mon;Fri"} {
+		if _, err := ParseWeekdays(bad); err != ErrInvalidSeriesDays {
+			t.Fatalf("%q: want invalid days, got %v", bad, err)
+		}
+	}
+}
+
+func TestScheduleSeriesValidateAndDates(t *testing.T) {
+	days, _ := ParseWeekdays("Mon,Wed,Fri")
+	s := ScheduleSeries{RouteCode: " rt1 ", AirplaneCode: "a320", StartDate: "2025-01-01", EndDate: "2025-01-12", Days: days, DepartureTime: "08:30", ArrivalTime: "11:20"}
+	s.Normalize()
+	if err := s.Validate(); err != nil {
+		t.Fatalf("expected valid series, got %v", err)
+	}
+	if s.RouteCode != "RT1" || s.AirplaneCode != "A320" {
+		t.Fatalf("expected normalised codes, got %+v", s)
+	}
+	// 2025-01-01 is a Wednesday.
+	if got := strings.Join(s.Dates(""), " "); got != "2025-01-01 2025-01-03 2025-01-06 2025-01-08 2025-01-10" {
+		t.Fatalf("unexpected dates %s", got)
+	}
+	if got := strings.Join(s.Dates("2025-01-08"), " "); got != "2025-01-10" {
+		t.Fatalf("unexpected dates after 2025-01-08: %s", got)
+	}
+
+	cases := []struct {
+		edit func(s *ScheduleSeries)
+		want error
+	}{
+		{func(s *ScheduleSeries) { s.EndDate = "2024-12-31" }, ErrInvalidSeriesDates},
+		{func(s *ScheduleSeries) { s.EndDate = "2026-01-02" }, ErrInvalidSeriesDates},
+		{func(s *ScheduleSeries) { s.EndDate = "soon" }, ErrInvalidSeriesDates},
+		{func(s *ScheduleSeries) { s.StartDate = "bad" }, ErrInvalidScheduleDate},
+		{func(s *ScheduleSeries) { s.Days = 0 }, ErrInvalidSeriesDays},
+		{func(s *ScheduleSeries) { s.DepartureTime = "8am" }, ErrInvalidScheduleTime},
+		{func(s *ScheduleSeries) { s.ArrivalTime = "25:00" }, ErrInvalidScheduleTime},
+		{func(s *ScheduleSeries) { s.RouteCode = "" }, ErrInvalidScheduleRoute},
+	}
+	for i, tc := range cases {
+		bad := s
+		tc.edit(&bad)
+		if err := bad.Validate(); err != tc.want {
+			t.Fatalf("case %d: want %v, got %v", i, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+// SeatInventory is the materialized seat count of one schedule. Sold and Held
+// follow the schedule's confirmed and held bookings; Blocked seats are withheld
+// from sale by operations.
+type SeatInventory struct {
+	ScheduleID int64
+	Capacity   int
+	Sold       int
+	Held       int
+	Blocked    int
+	UpdatedAt  string
+}
+
+// Taken is the number of seats that cannot be sold.
+func (s SeatInventory) Taken() int {
+	return s.Sold + s.Held + s.Blocked
+}
+
+// Available is the number of seats left for sale, never negative.
+func (s SeatInventory) Available() int {
+	if s.Taken() >= s.Capacity {
+		return 0
+	}
+	return s.Capacity - s.Taken()
+}
+
+// InventoryDrift reports a schedule whose recorded inventory disagrees with its
+// airplane capacity or bookings. Missing is set when no inventory row exists.
+type InventoryDrift struct {
+	Recorded SeatInventory
+	Expected SeatInventory
+	Missing  bool
+}
+
+// InventoryDelta is the change in sold and held seats caused by moving a booking
+// from one status to another.
+func InventoryDelta(from, to string) (sold, held int) {
+	count := func(status string) (int, int) {
+		switch status {
+		case BookingStatusConfirmed:
+			return 1, 0
+		case BookingStatusHeld:
+			return 0, 1
+		}
+		return 0, 0
+	}
+	fromSold, fromHeld := count(from)
+	toSold, toHeld := count(to)
+	return toSold - fromSold, toHeld - fromHeld
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+// SeatInventoryRepository reads and reconciles materialized seat inventory.
+type SeatInventoryRepository interface {
+	Get(ctx context.Context, scheduleID int64) (*SeatInventory, error)
+	// Reconcile compares every schedule's inventory against its airplane and
+	// bookings. With repair set, drifted rows are rewritten in one transaction.
+	Reconcile(ctx context.Context, repair bool) ([]InventoryDrift, error)
+}
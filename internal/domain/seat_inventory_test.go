@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestSeatInventoryAvailable(t *testing.T) {
+	inv := SeatInventory{Capacity: 10, Sold: 4, Held: 2, Blocked: 1}
+	if inv.Taken() != 7 || inv.Available() != 3 {
+		t.Fatalf("taken=%d available=%d", inv.Taken(), inv.Available())
+	}
+	inv.Capacity = 5
+	if inv.Available() != 0 {
+		t.Fatalf("oversold inventory should report zero, got %d", inv.Available())
+	}
+}
+
+func TestInventoryDelta(t *testing.T) {
+	cases := []struct {
+		from, to   string
+		sold, held int
+	}{
+		{"", BookingStatusConfirmed, 1, 0},
+		{"", BookingStatusHeld, 0, 1},
+		{BookingStatusHeld, BookingStatusConfirmed, 1, -1},
+		{BookingStatusHeld, BookingStatusCancelled, 0, -1},
+		{BookingStatusConfirmed, BookingStatusCancelled, -1, 0},
+	}
+	for _, tc := range cases {
+		if sold, held := InventoryDelta(tc.from, tc.to); sold != tc.sold || held != tc.held {
+			t.Fatalf("%q -> %q: sold=%d held=%d", tc.from, tc.to, sold, held)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	CouponStatusOpen      = "OPEN"
+	CouponStatusCheckedIn = "CHECKED-IN"
+	CouponStatusFlown     = "FLOWN"
+	CouponStatusRefunded  = "REFUNDED"
+)
+
+// TicketNumberLength is the length of an e-ticket number: a 3-digit airline
+// prefix, a 9-digit serial and a single check digit.
+const TicketNumberLength = 13
+
+// MaxTicketSerial is the largest serial that fits in a ticket number.
+const MaxTicketSerial = 999999999
+
+// Ticket is an issued e-ticket document for a booking.
+type Ticket struct {
+	ID               int64
+	Number           string
+	BookingID        int64
+	BookingReference string
+	PassengerName    string
+	Coupons          []Coupon
+	IssuedAt         string
+}
+
+// Coupon is the part of a ticket that entitles the passenger to one flight segment.
+type Coupon struct {
+	ID         int64
+	TicketID   int64
+	Sequence   int
+	ScheduleID int64
+	Status     string
+}
+
+// NewTicketNumber builds a 13-digit ticket number from an airline prefix and a serial.
+// The check digit is the serial modulo 7, as used for airline accountable documents.
+func NewTicketNumber(airlinePrefix string, serial int64) (string, error) {
+	if !isDigits(airlinePrefix) || len(airlinePrefix) != 3 {
+		return "", ErrInvalidAirlinePrefix
+	}
+	if serial <= 0 || serial > MaxTicketSerial {
+		return "", ErrInvalidTicketNumber
+	}
+	return fmt.Sprintf("%s%09d%d", airlinePrefix, serial, serial%7), nil
+}
+
+// ValidTicketNumber reports whether number has the right shape and check digit.
+func ValidTicketNumber(number string) bool {
+	if len(number) != TicketNumberLength || !isDigits(number) {
+		return false
+	}
+	var serial int64
+	for _, c := range number[3:12] {
+		serial = serial*10 + int64(c-'0')
+	}
+	return serial > 0 && int64(number[12]-'0') == serial%7
+}
+
+// Normalize trims ticket fields and uppercases coupon statuses.
+func (t *Ticket) Normalize() {
+	t.Number = strings.TrimSpace(t.Number)
+	t.PassengerName = strings.TrimSpace(t.PassengerName)
+	for i := range t.Coupons {
+		t.Coupons[i].Status = strings.ToUpper(strings.TrimSpace(t.Coupons[i].Status))
+	}
+}
+
+// Validate ensures the ticket has a valid number, a booking and at least one coupon.
+func (t Ticket) Validate() error {
+	if !ValidTicketNumber(t.Number) {
+		return ErrInvalidTicketNumber
+	}
+	if t.BookingID <= 0 {
+		return ErrBookingNotFound
+	}
+	if name := strings.TrimSpace(t.PassengerName); len(name) == 0 || len(name) > 128 {
+		return ErrInvalidPassengerName
+	}
+	if len(t.Coupons) == 0 {
+		return ErrCouponNotFound
+	}
+	for i, c := range t.Coupons {
+		if c.Sequence != i+1 {
+			return ErrCouponNotFound
+		}
+		if c.ScheduleID <= 0 {
+			return ErrInvalidScheduleID
+		}
+		if !validCouponStatus(c.Status) {
+			return ErrInvalidCouponStatus
+		}
+	}
+	return nil
+}
+
+// Coupon returns the coupon with the given sequence number.
+func (t Ticket) Coupon(sequence int) (*Coupon, error) {
+	for i := range t.Coupons {
+		if t.Coupons[i].Sequence == sequence {
+			return &t.Coupons[i], nil
+		}
+	}
+	return nil, ErrCouponNotFound
+}
+
+// CanTransition reports whether a coupon may move from its current status to next.
+// Checked-in coupons may be reopened (offloaded); flown and refunded coupons are final.
+func (c Coupon) CanTransition(next string) bool {
+	switch c.Status {
+	case CouponStatusOpen:
+		return next == CouponStatusCheckedIn || next == CouponStatusRefunded
+	case CouponStatusCheckedIn:
+		return next == CouponStatusFlown || next == CouponStatusOpen
+	default:
+		return false
+	}
+}
+
+func validCouponStatus(status string) bool {
+	switch status {
+	case CouponStatusOpen, CouponStatusCheckedIn, CouponStatusFlown, CouponStatusRefunded:
+		return true
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "context"
+
+// TicketRepository persists issued tickets and their coupons.
+type TicketRepository interface {
+	NextSerial(ctx context.Context) (int64, error)
+	Create(ctx context.Context, t *Ticket) error
+	GetByNumber(ctx context.Context, number string) (*Ticket, error)
+	ListByBooking(ctx context.Context, bookingID int64) ([]Ticket, error)
+	// UpdateCouponStatus moves a coupon from one status to another. It fails
+	// with ErrInvalidCouponTransition when the coupon is not in from, so
+	// concurrent changes cannot both succeed.
+	UpdateCouponStatus(ctx context.Context, couponID int64, from, to string) error
+}
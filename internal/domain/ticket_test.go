@@ -0,0 +1,82 @@
+package domain
+
+import "testing"
+
+func TestNewTicketNumber(t *testing.T) {
+	num, err := NewTicketNumber("126", 1234567)
+	if err != nil {
+		t.Fatalf("new ticket number: %v", err)
+	}
+	if num != "1260012345675" {
+		t.Fatalf("unexpected number %q", num)
+	}
+	if !ValidTicketNumber(num) {
+		t.Fatalf("expected %q to be valid", num)
+	}
+	if _, err := NewTicketNumber("12", 1); err != ErrInvalidAirlinePrefix {
+		t.Fatalf("want prefix err, got %v", err)
+	}
+	if _, err := NewTicketNumber("126", 0); err != ErrInvalidTicketNumber {
+		t.Fatalf("want number err, got %v", err)
+	}
+	if _, err := NewTicketNumber("126", MaxTicketSerial+1); err != ErrInvalidTicketNumber {
+		t.Fatalf("want number err for overflow, got %v", err)
+	}
+}
+
+func TestValidTicketNumber_Rejects(t *testing.T) {
+	for _, in := range []string{"", "126001234567", "1260012345674", "12600123456A5", "1260000000000"} {
+		if ValidTicketNumber(in) {
+			t.Fatalf("expected %q to be invalid", in)
+		}
+	}
+}
+
+func TestTicketValidate(t *testing.T) {
+	tk := Ticket{
+		Number:        "1260012345675",
+		BookingID:     1,
+		PassengerName: " Alice ",
+		Coupons:       []Coupon{{Sequence: 1, ScheduleID: 10, Status: "open"}},
+	}
+	tk.Normalize()
+	if err := tk.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if c, err := tk.Coupon(1); err != nil || c.ScheduleID != 10 {
+		t.Fatalf("coupon lookup: %v %+v", err, c)
+	}
+	if _, err := tk.Coupon(2); err != ErrCouponNotFound {
+		t.Fatalf("want coupon not found, got %v", err)
+	}
+
+	bad := tk
+	bad.Coupons = []Coupon{{Sequence: 1, ScheduleID: 10, Status: "LOST"}}
+	if err := bad.Validate(); err != ErrInvalidCouponStatus {
+		t.Fatalf("want status err, got %v", err)
+	}
+	bad.Coupons = nil
+	if err := bad.Validate(); err != ErrCouponNotFound {
+		t.Fatalf("want coupon err, got %v", err)
+	}
+}
+
+func TestCouponCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{CouponStatusOpen, CouponStatusCheckedIn, true},
+		{CouponStatusOpen, CouponStatusRefunded, true},
+		{CouponStatusOpen, CouponStatusFlown, false},
+		{CouponStatusCheckedIn, CouponStatusFlown, true},
+		{CouponStatusCheckedIn, CouponStatusOpen, true},
+		{CouponStatusFlown, CouponStatusRefunded, false},
+		{CouponStatusRefunded, CouponStatusOpen, false},
+	}
+	for _, tc := range cases {
+		if got := (Coupon{Status: tc.from}).CanTransition(tc.to); got != tc.want {
+			t.Fatalf("%s -> %s: want %v, got %v", tc.from, tc.to, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "strings"
+
+// Trip kinds.
+const (
+	TripRoundTrip  = "ROUND_TRIP" // an outbound and a return flight; nobody connects between them
+	TripConnecting = "CONNECTING" // one journey over connecting flights
+)
+
+// Trip groups bookings a passenger made together, such as the outbound and
+// return flights of a round trip or the legs of a connecting journey, with
+// the price of the whole journey.
+type Trip struct {
+	ID            int64
+	Kind          string
+	PassengerName string
+	Fare          Money // zero when no fare applies
+	Bookings      []Booking
+	CreatedAt     string
+}
+
+// Validate ensures every booking of the trip is sound and belongs to its passenger.
+func (t Trip) Validate() error {
+	name := strings.TrimSpace(t.PassengerName)
+	if len(name) == 0 || len(name) > 128 {
+		return ErrInvalidPassengerName
+	}
+	if len(t.Bookings) == 0 {
+		return ErrInvalidScheduleID
+	}
+	for _, b := range t.Bookings {
+		if err := b.Validate(); err != nil {
+			return err
+		}
+		if b.PassengerName != name {
+			return ErrInvalidPassengerName
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "context"
+
+// TripRepository persists trips together with their bookings.
+type TripRepository interface {
+	// Create stores the trip and all of its bookings in one transaction, claiming
+	// a seat on every schedule or none at all. It sets the IDs, seats and
+	// timestamps of the trip and its bookings.
+	Create(ctx context.Context, t *Trip) error
+	// GetByID returns a trip with its bookings in departure order.
+	GetByID(ctx context.Context, id int64) (*Trip, error)
+	// Connections returns, for each booking on the schedule that is not
+	// cancelled and belongs to a connecting trip, the trip's next booking that
+	// departs, after the schedule, from the airport it lands at.
+	Connections(ctx context.Context, scheduleID int64) ([]Connection, error)
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "testing"
+
+func TestTripValidate(t *testing.T) {
+	leg := Booking{ScheduleID: 1, PassengerName: "Alice", Reference: "K7QX2M", SeatNumber: 1, Status: BookingStatusConfirmed}
+	trip := Trip{PassengerName: "Alice", Bookings: []Booking{leg, leg}}
+	if err := trip.Validate(); err != nil {
+		t.Fatalf("expected valid trip, got %v", err)
+	}
+	if err := (Trip{PassengerName: "Alice"}).Validate(); err != ErrInvalidScheduleID {
+		t.Fatalf("want invalid schedule id for an empty trip, got %v", err)
+	}
+	other := leg
+	other.PassengerName = "Bob"
+	if err := (Trip{PassengerName: "Alice", Bookings: []Booking{leg, other}}).Validate(); err != ErrInvalidPassengerName {
+		t.Fatalf("want invalid passenger for mixed names, got %v", err)
+	}
+	bad := leg
+	bad.SeatNumber = 0
+	if err := (Trip{PassengerName: "Alice", Bookings: []Booking{bad}}).Validate(); err != ErrInvalidSeatNumber {
+		t.Fatalf("want invalid seat, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// AircraftTypeUsecase manages the aircraft type catalog.
+type AircraftTypeUsecase struct {
+	repo    domain.AircraftTypeRepository
+	timeout time.Duration
+}
+
+func NewAircraftTypeUsecase(r domain.AircraftTypeRepository) *AircraftTypeUsecase {
+	return &AircraftTypeUsecase{repo: r, timeout: 5 * time.Second}
+}
+
+func (u *AircraftTypeUsecase) Create(ctx context.Context, t domain.AircraftType) (*domain.AircraftType, error) {
+	t.Normalize()
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	if err := u.repo.Create(ctx, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (u *AircraftTypeUsecase) List(ctx context.Context, limit, offset int) ([]domain.AircraftType, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.repo.List(ctx, limit, offset)
+}
+
+func (u *AircraftTypeUsecase) Delete(ctx context.Context, icaoCode string) error {
+	code := strings.ToUpper(strings.TrimSpace(icaoCode))
+	if code == "" {
+		return domain.ErrInvalidAircraftTypeCode
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.repo.Delete(ctx, code)
+}
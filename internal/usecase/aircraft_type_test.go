@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+type fakeAircraftTypeRepo struct {
+	items map[string]domain.AircraftType
+	inUse map[string]bool
+}
+
+func (f *fakeAircraftTypeRepo) Create(ctx context.Context, t *domain.AircraftType) error {
+	if f.items == nil {
+		f.items = make(map[string]domain.AircraftType)
+	}
+	if _, ok := f.items[t.ICAOCode]; ok {
+		return domain.ErrAircraftTypeExists
+	}
+	t.ID = int64(len(f.items) + 1)
+	f.items[t.ICAOCode] = *t
+	return nil
+}
+
+func (f *fakeAircraftTypeRepo) GetByCode(ctx context.Context, icaoCode string) (*domain.AircraftType, error) {
+	t, ok := f.items[icaoCode]
+	if !ok {
+		return nil, domain.ErrAircraftTypeNotFound
+	}
+	return &t, nil
+}
+
+func (f *fakeAircraftTypeRepo) List(ctx context.Context, limit, offset int) ([]domain.AircraftType, error) {
+	var out []domain.AircraftType
+	for _, t := range f.items {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (f *fakeAircraftTypeRepo) Delete(ctx context.Context, icaoCode string) error {
+	if f.inUse[icaoCode] {
+		return domain.ErrAircraftTypeInUse
+	}
+	if _, ok := f.items[icaoCode]; !ok {
+		return domain.ErrAircraftTypeNotFound
+	}
+	delete(f.items, icaoCode)
+	return nil
+}
+
+func TestAircraftTypeUsecase(t *testing.T) {
+	repo := &fakeAircraftTypeRepo{inUse: map[string]bool{"B738": true}}
+	uc := NewAircraftTypeUsecase(repo)
+
+	created, err := uc.Create(context.Background(), domain.AircraftType{ICAOCode: " a320 ", IATACode: "320", Manufacturer: " Airbus ", Model: "A320-200", RangeKm: 6100, SeatMap: domain.SeatMap{Rows: 30, Layout: "3-3"}})
+	if err != nil || created.ICAOCode != "A320" || created.Name() != "Airbus A320-200" || created.SeatMap.Seats() != 180 {
+		t.Fatalf("unexpected type %+v err=%v", created, err)
+	}
+	if _, err := uc.Create(context.Background(), domain.AircraftType{ICAOCode: "A330", IATACode: "330", Manufacturer: "Airbus", Model: "A330-300", RangeKm: 11750, SeatMap: domain.SeatMap{Rows: 40, Layout: "2-4-x"}}); err != domain.ErrInvalidSeatMap {
+		t.Fatalf("want invalid seat map, got %v", err)
+	}
+	if items, err := uc.List(context.Background(), 0, -1); err != nil || len(items) != 1 {
+		t.Fatalf("list: %v n=%d", err, len(items))
+	}
+	if err := uc.Delete(context.Background(), " "); err != domain.ErrInvalidAircraftTypeCode {
+		t.Fatalf("want invalid code, got %v", err)
+	}
+	if err := uc.Delete(context.Background(), "b738"); err != domain.ErrAircraftTypeInUse {
+		t.Fatalf("want in use, got %v", err)
+	}
+	if err := uc.Delete(context.Background(), "a320"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
@@ -8,47 +8,122 @@ import (
 )
 
 type AirplaneUsecase struct {
-    repo    domain.AirplaneRepository
-    timeout time.Duration
+    repo      domain.AirplaneRepository
+    types     domain.AircraftTypeRepository
+    schedules domain.FlightScheduleRepository
+    bookings  domain.BookingRepository
+    timeout   time.Duration
+    now       func() time.Time
 }
 
 func NewAirplaneUsecase(r domain.AirplaneRepository) *AirplaneUsecase {
-    return &AirplaneUsecase{repo: r, timeout: 5 * time.Second}
+    return &AirplaneUsecase{repo: r, timeout: 5 * time.Second, now: time.Now}
 }
 
-func (u *AirplaneUsecase) Create(ctx context.Context, code string, seats int) (*domain.Airplane, error) {
-    a := &domain.Airplane{Code: code, SeatCapacity: seats}
+// WithTypes lets Create register airplanes of a catalog aircraft type.
+func (u *AirplaneUsecase) WithTypes(types domain.AircraftTypeRepository) *AirplaneUsecase {
+    u.types = types
+    return u
+}
+
+// WithReaccommodation lets forced capacity changes plan new flights for the
+// bookings that no longer fit.
+func (u *AirplaneUsecase) WithReaccommodation(schedules domain.FlightScheduleRepository, bookings domain.BookingRepository) *AirplaneUsecase {
+    u.schedules = schedules
+    u.bookings = bookings
+    return u
+}
+
+// SeatChange reports a capacity change: bookings reseated within the new
+// capacity and, when forced, a reaccommodation plan per overbooked flight.
+type SeatChange struct {
+    Moves            []domain.SeatMove
+    Overbooked       []domain.CapacityError
+    Reaccommodations []domain.Reaccommodation
+}
+
+// Create registers an airplane. With a typeCode the airplane references that
+// aircraft type and, when seats is zero, takes its seat count from the type's
+// default seat map.
+func (u *AirplaneUsecase) Create(ctx context.Context, code, typeCode string, seats int) (*domain.Airplane, error) {
+    a := &domain.Airplane{Code: code, TypeCode: typeCode, SeatCapacity: seats}
     a.Normalize()
-    if err := a.Validate(); err != nil { return nil, err }
     ctx, cancel := context.WithTimeout(ctx, u.timeout)
     defer cancel()
+    if a.TypeCode != "" {
+        if u.types == nil { return nil, domain.ErrAircraftTypeNotFound }
+        t, err := u.types.GetByCode(ctx, a.TypeCode)
+        if err != nil { return nil, err }
+        if a.SeatCapacity == 0 { a.SeatCapacity = t.SeatMap.Seats() }
+        a.TypeName = t.Name()
+    }
+    if err := a.Validate(); err != nil { return nil, err }
     if err := u.repo.Create(ctx, a); err != nil { return nil, err }
     return a, nil
 }
 
-func (u *AirplaneUsecase) List(ctx context.Context, limit, offset int) ([]domain.Airplane, error) {
+// List returns airplanes sorted by code; archived ones only with includeArchived.
+func (u *AirplaneUsecase) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airplane, error) {
     if limit <= 0 || limit > 500 { limit = 50 }
     if offset < 0 { offset = 0 }
     ctx, cancel := context.WithTimeout(ctx, u.timeout)
     defer cancel()
-    return u.repo.List(ctx, limit, offset)
+    return u.repo.List(ctx, limit, offset, includeArchived)
 }
 
-func (u *AirplaneUsecase) UpdateSeats(ctx context.Context, code string, seats int) error {
+// UpdateSeats changes an airplane's seat capacity. It fails with a
+// *domain.FleetCapacityError listing the future flights whose bookings would
+// not fit, unless force is set; forced changes report those bookings instead.
+func (u *AirplaneUsecase) UpdateSeats(ctx context.Context, code string, seats int, force bool) (*SeatChange, error) {
     a := domain.Airplane{Code: code, SeatCapacity: seats}
     a.Normalize()
-    if err := a.Validate(); err != nil { return err }
+    if err := a.Validate(); err != nil { return nil, err }
     ctx, cancel := context.WithTimeout(ctx, u.timeout)
     defer cancel()
-    return u.repo.UpdateSeats(ctx, a.Code, a.SeatCapacity)
+    change, err := u.repo.UpdateSeats(ctx, a.Code, a.SeatCapacity, u.now(), force)
+    if err != nil { return nil, err }
+    res := &SeatChange{Moves: change.Moves, Overbooked: change.Overbooked}
+    if u.schedules == nil || u.bookings == nil { return res, nil }
+    for _, f := range change.Overbooked {
+        sched, err := u.schedules.GetByID(ctx, f.ScheduleID)
+        if err != nil { return nil, err }
+        plan, err := planReaccommodation(ctx, u.schedules, u.bookings, u.repo, *sched, f.Capacity)
+        if err != nil { return nil, err }
+        res.Reaccommodations = append(res.Reaccommodations, *plan)
+    }
+    return res, nil
 }
 
-func (u *AirplaneUsecase) Delete(ctx context.Context, code string) error {
+// Archive retires an airplane: it leaves the airplane list and is refused for
+// new schedules, while the flights it already has keep it.
+func (u *AirplaneUsecase) Archive(ctx context.Context, code string) error {
+    return u.setArchived(ctx, code, true)
+}
+
+// Restore brings an archived airplane back into service.
+func (u *AirplaneUsecase) Restore(ctx context.Context, code string) error {
+    return u.setArchived(ctx, code, false)
+}
+
+func (u *AirplaneUsecase) setArchived(ctx context.Context, code string, archived bool) error {
     a := domain.Airplane{Code: code, SeatCapacity: 1}
     a.Normalize()
     if err := a.Validate(); err != nil { return err }
     ctx, cancel := context.WithTimeout(ctx, u.timeout)
     defer cancel()
-    return u.repo.Delete(ctx, a.Code)
+    return u.repo.SetArchived(ctx, a.Code, archived)
+}
+
+// Delete permanently removes an airplane. While flights, series or maintenance windows
+// use it, it fails with a *domain.InUseError unless cascade holds the
+// dependents it reported, and with domain.ErrDependentsChanged when they
+// differ; the result counts what was removed along with it.
+func (u *AirplaneUsecase) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+    a := domain.Airplane{Code: code, SeatCapacity: 1}
+    a.Normalize()
+    if err := a.Validate(); err != nil { return domain.Dependents{}, err }
+    ctx, cancel := context.WithTimeout(ctx, u.timeout)
+    defer cancel()
+    return u.repo.Delete(ctx, a.Code, cascade)
 }
 
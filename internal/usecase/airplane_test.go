@@ -2,7 +2,9 @@ package usecase
 
 import (
     "context"
+    "errors"
     "testing"
+    "time"
 
     "github.com/ambiyansyah-risyal/flight-booking/internal/domain"
 )
@@ -12,6 +14,7 @@ type fakeAirplaneRepo struct{
     createErr error
     updateErr error
     deleteErr error
+    change *domain.CapacityChange
 }
 
 func (f *fakeAirplaneRepo) Create(ctx context.Context, a *domain.Airplane) error {
@@ -19,25 +22,45 @@ func (f *fakeAirplaneRepo) Create(ctx context.Context, a *domain.Airplane) error
     f.list = append(f.list, domain.Airplane{Code:a.Code, SeatCapacity:a.SeatCapacity})
     return nil
 }
-func (f *fakeAirplaneRepo) GetByCode(ctx context.Context, code string) (*domain.Airplane, error) { return nil, nil }
-func (f *fakeAirplaneRepo) List(ctx context.Context, limit, offset int) ([]domain.Airplane, error) { return f.list, nil }
-func (f *fakeAirplaneRepo) UpdateSeats(ctx context.Context, code string, seats int) error { if f.updateErr!=nil {return f.updateErr}; return nil }
-func (f *fakeAirplaneRepo) Delete(ctx context.Context, code string) error { if f.deleteErr!=nil {return f.deleteErr}; return nil }
+func (f *fakeAirplaneRepo) GetByCode(ctx context.Context, code string) (*domain.Airplane, error) {
+    for i := range f.list { if f.list[i].Code == code { return &f.list[i], nil } }
+    return nil, domain.ErrAirplaneNotFound
+}
+func (f *fakeAirplaneRepo) SetArchived(ctx context.Context, code string, archived bool) error { return nil }
+
+func (f *fakeAirplaneRepo) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airplane, error) { return f.list, nil }
+func (f *fakeAirplaneRepo) UpdateSeats(ctx context.Context, code string, seats int, from time.Time, force bool) (*domain.CapacityChange, error) { 
+    if f.updateErr!=nil {return nil, f.updateErr}
+    if f.change!=nil {return f.change, nil}
+    return &domain.CapacityChange{}, nil
+}
+func (f *fakeAirplaneRepo) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) { if f.deleteErr!=nil {return domain.Dependents{}, f.deleteErr}; return domain.Dependents{}, nil }
 
 func TestAirplaneUsecase_Create_List(t *testing.T) {
     r := &fakeAirplaneRepo{}
     uc := NewAirplaneUsecase(r)
-    if _, err := uc.Create(context.Background(), " ab ", 10); err != nil { t.Fatalf("create: %v", err) }
-    items, err := uc.List(context.Background(), 1000, -1)
+    if _, err := uc.Create(context.Background(), " ab ", "", 10); err != nil { t.Fatalf("create: %v", err) }
+    items, err := uc.List(context.Background(), 1000, -1, false)
     if err != nil || len(items) != 1 { t.Fatalf("list: %v n=%d", err, len(items)) }
 }
 
+func TestAirplaneUsecase_CreateWithType(t *testing.T) {
+    r := &fakeAirplaneRepo{}
+    types := &fakeAircraftTypeRepo{items: map[string]domain.AircraftType{"A320": {ICAOCode: "A320", Manufacturer: "Airbus", Model: "A320-200", SeatMap: domain.SeatMap{Rows: 30, Layout: "3-3"}}}}
+    uc := NewAirplaneUsecase(r).WithTypes(types)
+    a, err := uc.Create(context.Background(), " pk-gqa ", "a320", 0)
+    if err != nil || a.Code != "PK-GQA" || a.TypeCode != "A320" || a.TypeName != "Airbus A320-200" || a.SeatCapacity != 180 { t.Fatalf("unexpected airplane %+v err=%v", a, err) }
+    if a, err := uc.Create(context.Background(), "PK-GQB", "A320", 156); err != nil || a.SeatCapacity != 156 { t.Fatalf("want explicit seats kept, got %+v err=%v", a, err) }
+    if _, err := uc.Create(context.Background(), "PK-GQC", "B738", 0); err != domain.ErrAircraftTypeNotFound { t.Fatalf("want type not found, got %v", err) }
+    if _, err := NewAirplaneUsecase(r).Create(context.Background(), "PK-GQD", "A320", 0); err != domain.ErrAircraftTypeNotFound { t.Fatalf("want type not found without a catalog, got %v", err) }
+}
+
 func TestAirplaneUsecase_Update_Delete_Validate(t *testing.T) {
     r := &fakeAirplaneRepo{}
     uc := NewAirplaneUsecase(r)
-    if err := uc.UpdateSeats(context.Background(), "", 1); err != domain.ErrInvalidAirplaneCode { t.Fatalf("want code err: %v", err) }
-    if err := uc.UpdateSeats(context.Background(), "OK", 0); err != domain.ErrInvalidSeatCapacity { t.Fatalf("want seat err: %v", err) }
-    if err := uc.Delete(context.Background(), ""); err != domain.ErrInvalidAirplaneCode { t.Fatalf("want code err: %v", err) }
+    if _, err := uc.UpdateSeats(context.Background(), "", 1, false); err != domain.ErrInvalidAirplaneCode { t.Fatalf("want code err: %v", err) }
+    if _, err := uc.UpdateSeats(context.Background(), "OK", 0, false); err != domain.ErrInvalidSeatCapacity { t.Fatalf("want seat err: %v", err) }
+    if _, err := uc.Delete(context.Background(), "", nil); err != domain.ErrInvalidAirplaneCode { t.Fatalf("want code err: %v", err) }
 }
 
 func TestAirplaneUsecase_Update_Delete_Success(t *testing.T) {
@@ -45,12 +68,12 @@ func TestAirplaneUsecase_Update_Delete_Success(t *testing.T) {
     uc := NewAirplaneUsecase(r)
     
     // Test successful update
-    if err := uc.UpdateSeats(context.Background(), "TEST", 150); err != nil {
+    if _, err := uc.UpdateSeats(context.Background(), "TEST", 150, false); err != nil {
         t.Fatalf("update seats failed: %v", err)
     }
     
     // Test successful delete
-    if err := uc.Delete(context.Background(), "TEST"); err != nil {
+    if _, err := uc.Delete(context.Background(), "TEST", nil); err != nil {
         t.Fatalf("delete failed: %v", err)
     }
 }
@@ -60,12 +83,44 @@ func TestAirplaneUsecase_Update_Delete_RepoErrors(t *testing.T) {
     uc := NewAirplaneUsecase(r)
     
     // Test repo error for update
-    if err := uc.UpdateSeats(context.Background(), "TEST", 150); err != domain.ErrAirplaneNotFound {
+    if _, err := uc.UpdateSeats(context.Background(), "TEST", 150, false); err != domain.ErrAirplaneNotFound {
         t.Fatalf("want repo error: %v", err)
     }
     
     // Test repo error for delete
-    if err := uc.Delete(context.Background(), "TEST"); err != domain.ErrAirplaneNotFound {
+    if _, err := uc.Delete(context.Background(), "TEST", nil); err != domain.ErrAirplaneNotFound {
         t.Fatalf("want repo error: %v", err)
     }
 }
+
+func TestAirplaneUsecase_UpdateSeats_ForcePlansReaccommodation(t *testing.T) {
+    t0 := time.Date(2025, 1, 2, 1, 0, 0, 0, time.UTC)
+    r := &fakeAirplaneRepo{
+        list: []domain.Airplane{{Code: "A320", SeatCapacity: 2}, {Code: "B737", SeatCapacity: 180}},
+        change: &domain.CapacityChange{
+            Moves:      []domain.SeatMove{{ScheduleID: 2, BookingID: 9, Reference: "EEEEEE", From: 5, To: 1}},
+            Overbooked: []domain.CapacityError{{ScheduleID: 1, Capacity: 2, Booked: 3}},
+        },
+    }
+    schedules := &fakeScheduleRepo{items: []domain.FlightSchedule{
+        {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: t0},
+        {ID: 2, RouteCode: "RT1", AirplaneCode: "B737", DepartureDate: "2025-01-03", DepartureAt: t0.AddDate(0, 0, 1)},
+    }}
+    bookings := &mockBookingRepo{count: 10, bookings: map[string]*domain.Booking{
+        "AAAAAA": {ID: 1, Reference: "AAAAAA", ScheduleID: 1, SeatNumber: 1, Status: domain.BookingStatusConfirmed},
+        "BBBBBB": {ID: 2, Reference: "BBBBBB", ScheduleID: 1, SeatNumber: 2, Status: domain.BookingStatusConfirmed},
+        "CCCCCC": {ID: 3, Reference: "CCCCCC", ScheduleID: 1, SeatNumber: 3, Status: domain.BookingStatusConfirmed},
+    }}
+    uc := NewAirplaneUsecase(r).WithReaccommodation(schedules, bookings)
+
+    res, err := uc.UpdateSeats(context.Background(), "a320", 2, true)
+    if err != nil { t.Fatalf("update: %v", err) }
+    if len(res.Moves) != 1 || len(res.Overbooked) != 1 || len(res.Reaccommodations) != 1 { t.Fatalf("unexpected change %+v", res) }
+    plan := res.Reaccommodations[0]
+    if plan.Schedule.ID != 1 || len(plan.Displaced) != 1 || plan.Displaced[0].Reference != "CCCCCC" { t.Fatalf("unexpected displaced %+v", plan.Displaced) }
+    if len(plan.Alternatives) != 1 || plan.Alternatives[0].Schedule.ID != 2 || plan.Alternatives[0].SeatsLeft != 170 { t.Fatalf("unexpected alternatives %+v", plan.Alternatives) }
+
+    r.change = nil
+    r.updateErr = &domain.FleetCapacityError{AirplaneCode: "A320", Capacity: 2, Flights: []domain.CapacityError{{ScheduleID: 1, Capacity: 2, Booked: 3}}}
+    if _, err := uc.UpdateSeats(context.Background(), "A320", 2, false); !errors.Is(err, domain.ErrCapacityBelowBookings) { t.Fatalf("want refusal, got %v", err) }
+}
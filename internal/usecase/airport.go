@@ -16,8 +16,9 @@ func NewAirportUsecase(repo domain.AirportRepository) *AirportUsecase {
     return &AirportUsecase{repo: repo, timeout: 5 * time.Second}
 }
 
-func (u *AirportUsecase) Create(ctx context.Context, code, city string) (*domain.Airport, error) {
-    a := &domain.Airport{Code: code, City: city}
+// Create stores a new airport; an empty time zone defaults to UTC.
+func (u *AirportUsecase) Create(ctx context.Context, code, city, timeZone string) (*domain.Airport, error) {
+    a := &domain.Airport{Code: code, City: city, TimeZone: timeZone}
     a.Normalize()
     if err := a.Validate(); err != nil {
         return nil, err
@@ -30,12 +31,13 @@ func (u *AirportUsecase) Create(ctx context.Context, code, city string) (*domain
     return a, nil
 }
 
-func (u *AirportUsecase) List(ctx context.Context, limit, offset int) ([]domain.Airport, error) {
+// List returns airports sorted by code; archived ones only with includeArchived.
+func (u *AirportUsecase) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airport, error) {
     if limit <= 0 || limit > 500 { limit = 50 }
     if offset < 0 { offset = 0 }
     ctx, cancel := context.WithTimeout(ctx, u.timeout)
     defer cancel()
-    return u.repo.List(ctx, limit, offset)
+    return u.repo.List(ctx, limit, offset, includeArchived)
 }
 
 func (u *AirportUsecase) Update(ctx context.Context, code, city string) error {
@@ -47,12 +49,66 @@ func (u *AirportUsecase) Update(ctx context.Context, code, city string) error {
     return u.repo.Update(ctx, a.Code, a.City)
 }
 
-func (u *AirportUsecase) Delete(ctx context.Context, code string) error {
+// SetTimeZone changes the IANA time zone used to display the airport's local times.
+func (u *AirportUsecase) SetTimeZone(ctx context.Context, code, timeZone string) error {
+    a := domain.Airport{Code: code, City: "x", TimeZone: timeZone}
+    a.Normalize()
+    if err := a.Validate(); err != nil { return err }
+    ctx, cancel := context.WithTimeout(ctx, u.timeout)
+    defer cancel()
+    return u.repo.SetTimeZone(ctx, a.Code, a.TimeZone)
+}
+
+// SetMinConnection sets the airport's minimum connection time in minutes; 0 falls back to the global default.
+func (u *AirportUsecase) SetMinConnection(ctx context.Context, code string, minutes int) error {
+    a := domain.Airport{Code: code, City: "x", MinConnectionMinutes: minutes}
+    a.Normalize()
+    if err := a.Validate(); err != nil { return err }
+    ctx, cancel := context.WithTimeout(ctx, u.timeout)
+    defer cancel()
+    return u.repo.SetMinConnection(ctx, a.Code, a.MinConnectionMinutes)
+}
+
+// SetPosition records the airport's latitude and longitude in decimal degrees.
+func (u *AirportUsecase) SetPosition(ctx context.Context, code string, latitude, longitude float64) error {
+    a := domain.Airport{Code: code, City: "x", Position: &domain.Coordinates{Latitude: latitude, Longitude: longitude}}
+    a.Normalize()
+    if err := a.Validate(); err != nil { return err }
+    ctx, cancel := context.WithTimeout(ctx, u.timeout)
+    defer cancel()
+    return u.repo.SetPosition(ctx, a.Code, a.Position)
+}
+
+// Archive retires an airport: it leaves the airport list and takes no new
+// routes, while its routes, flights and bookings stay as they are.
+func (u *AirportUsecase) Archive(ctx context.Context, code string) error {
+    return u.setArchived(ctx, code, true)
+}
+
+// Restore brings an archived airport back into use.
+func (u *AirportUsecase) Restore(ctx context.Context, code string) error {
+    return u.setArchived(ctx, code, false)
+}
+
+func (u *AirportUsecase) setArchived(ctx context.Context, code string, archived bool) error {
     a := domain.Airport{Code: code, City: "x"}
     a.Normalize()
     if err := a.Validate(); err != nil { return err }
     ctx, cancel := context.WithTimeout(ctx, u.timeout)
     defer cancel()
-    return u.repo.Delete(ctx, a.Code)
+    return u.repo.SetArchived(ctx, a.Code, archived)
+}
+
+// Delete permanently removes an airport. While routes use it, it fails with a
+// *domain.InUseError unless cascade holds the dependents it reported, and
+// with domain.ErrDependentsChanged when they differ; the result counts what
+// was removed along with it.
+func (u *AirportUsecase) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+    a := domain.Airport{Code: code, City: "x"}
+    a.Normalize()
+    if err := a.Validate(); err != nil { return domain.Dependents{}, err }
+    ctx, cancel := context.WithTimeout(ctx, u.timeout)
+    defer cancel()
+    return u.repo.Delete(ctx, a.Code, cascade)
 }
 
@@ -19,7 +19,7 @@ type fakeAirportRepo struct{
 
 func (f *fakeAirportRepo) Create(ctx context.Context, a *domain.Airport) error {
     if f.createErr != nil { return f.createErr }
-    f.created = append(f.created, &domain.Airport{Code: a.Code, City: a.City})
+    f.created = append(f.created, &domain.Airport{Code: a.Code, City: a.City, TimeZone: a.TimeZone})
     return nil
 }
 
@@ -30,9 +30,13 @@ func (f *fakeAirportRepo) GetByCode(ctx context.Context, code string) (*domain.A
     return nil, domain.ErrAirportNotFound
 }
 
-func (f *fakeAirportRepo) List(ctx context.Context, limit, offset int) ([]domain.Airport, error) {
+func (f *fakeAirportRepo) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airport, error) {
     f.lastLimit, f.lastOffset = limit, offset
-    return f.list, nil
+    var out []domain.Airport
+    for _, a := range f.list {
+        if includeArchived || !a.Archived() { out = append(out, a) }
+    }
+    return out, nil
 }
 
 func (f *fakeAirportRepo) Update(ctx context.Context, code string, city string) error {
@@ -43,21 +47,55 @@ func (f *fakeAirportRepo) Update(ctx context.Context, code string, city string)
     return domain.ErrAirportNotFound
 }
 
-func (f *fakeAirportRepo) Delete(ctx context.Context, code string) error {
-    if f.deleteErr != nil { return f.deleteErr }
+func (f *fakeAirportRepo) SetTimeZone(ctx context.Context, code string, timeZone string) error {
+    for i := range f.list {
+        if f.list[i].Code == code { f.list[i].TimeZone = timeZone; return nil }
+    }
+    return domain.ErrAirportNotFound
+}
+
+func (f *fakeAirportRepo) SetMinConnection(ctx context.Context, code string, minutes int) error {
+    for i := range f.list {
+        if f.list[i].Code == code { f.list[i].MinConnectionMinutes = minutes; return nil }
+    }
+    return domain.ErrAirportNotFound
+}
+
+func (f *fakeAirportRepo) SetPosition(ctx context.Context, code string, position *domain.Coordinates) error {
     for i := range f.list {
-        if f.list[i].Code == code { f.list = append(f.list[:i], f.list[i+1:]...); return nil }
+        if f.list[i].Code == code { f.list[i].Position = position; return nil }
     }
     return domain.ErrAirportNotFound
 }
 
+func (f *fakeAirportRepo) SetArchived(ctx context.Context, code string, archived bool) error {
+    for i := range f.list {
+        if f.list[i].Code != code { continue }
+        if !archived {
+            f.list[i].ArchivedAt = ""
+        } else if f.list[i].ArchivedAt == "" {
+            f.list[i].ArchivedAt = "2025-01-01T00:00:00Z"
+        }
+        return nil
+    }
+    return domain.ErrAirportNotFound
+}
+
+func (f *fakeAirportRepo) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+    if f.deleteErr != nil { return domain.Dependents{}, f.deleteErr }
+    for i := range f.list {
+        if f.list[i].Code == code { f.list = append(f.list[:i], f.list[i+1:]...); return domain.Dependents{}, nil }
+    }
+    return domain.Dependents{}, domain.ErrAirportNotFound
+}
+
 func TestAirportUsecase_Create_ValidatesAndCallsRepo(t *testing.T) {
     repo := &fakeAirportRepo{}
     uc := NewAirportUsecase(repo)
 
-    got, err := uc.Create(context.Background(), "cgk", " Jakarta ")
+    got, err := uc.Create(context.Background(), "cgk", " Jakarta ", "")
     if err != nil { t.Fatalf("unexpected err: %v", err) }
-    if got.Code != "CGK" || got.City != "Jakarta" {
+    if got.Code != "CGK" || got.City != "Jakarta" || got.TimeZone != domain.DefaultTimeZone {
         t.Fatalf("normalized mismatch: %+v", got)
     }
     if len(repo.created) != 1 || repo.created[0].Code != "CGK" {
@@ -68,7 +106,7 @@ func TestAirportUsecase_Create_ValidatesAndCallsRepo(t *testing.T) {
 func TestAirportUsecase_Create_Invalid(t *testing.T) {
     repo := &fakeAirportRepo{}
     uc := NewAirportUsecase(repo)
-    if _, err := uc.Create(context.Background(), "", "City"); err != domain.ErrInvalidAirportCode {
+    if _, err := uc.Create(context.Background(), "", "City", ""); err != domain.ErrInvalidAirportCode {
         t.Fatalf("want ErrInvalidAirportCode, got %v", err)
     }
     if len(repo.created) != 0 { t.Fatalf("repo should not be called on invalid input") }
@@ -77,7 +115,7 @@ func TestAirportUsecase_Create_Invalid(t *testing.T) {
 func TestAirportUsecase_List_Pagination(t *testing.T) {
     repo := &fakeAirportRepo{list: []domain.Airport{{Code:"CGK", City:"Jakarta"}}}
     uc := NewAirportUsecase(repo)
-    items, err := uc.List(context.Background(), 10, 5)
+    items, err := uc.List(context.Background(), 10, 5, false)
     if err != nil { t.Fatalf("unexpected err: %v", err) }
     if len(items) != 1 { t.Fatalf("want 1 item, got %d", len(items)) }
     if repo.lastLimit != 10 || repo.lastOffset != 5 {
@@ -92,14 +130,14 @@ func TestAirportUsecase_Update_Delete(t *testing.T) {
         t.Fatalf("update err: %v", err)
     }
     if repo.list[0].City != "NewCity" { t.Fatalf("city not updated: %+v", repo.list[0]) }
-    if err := uc.Delete(context.Background(), "cgk"); err != nil { t.Fatalf("delete err: %v", err) }
+    if _, err := uc.Delete(context.Background(), "cgk", nil); err != nil { t.Fatalf("delete err: %v", err) }
     if len(repo.list) != 0 { t.Fatalf("item not deleted") }
 }
 
 func TestAirportUsecase_List_Defaults(t *testing.T) {
     repo := &fakeAirportRepo{list: []domain.Airport{}}
     uc := NewAirportUsecase(repo)
-    if _, err := uc.List(context.Background(), 9999, -1); err != nil {
+    if _, err := uc.List(context.Background(), 9999, -1, false); err != nil {
         t.Fatalf("unexpected err: %v", err)
     }
     if repo.lastLimit != 50 || repo.lastOffset != 0 {
@@ -110,7 +148,7 @@ func TestAirportUsecase_List_Defaults(t *testing.T) {
 func TestAirportUsecase_Create_RepoError(t *testing.T) {
     repo := &fakeAirportRepo{createErr: domain.ErrAirportExists}
     uc := NewAirportUsecase(repo)
-    if _, err := uc.Create(context.Background(), "CGK", "Jakarta"); err != domain.ErrAirportExists {
+    if _, err := uc.Create(context.Background(), "CGK", "Jakarta", "Asia/Jakarta"); err != domain.ErrAirportExists {
         t.Fatalf("want ErrAirportExists, got %v", err)
     }
 }
@@ -126,7 +164,48 @@ func TestAirportUsecase_Update_Invalid(t *testing.T) {
 func TestAirportUsecase_Delete_Invalid(t *testing.T) {
     repo := &fakeAirportRepo{}
     uc := NewAirportUsecase(repo)
-    if err := uc.Delete(context.Background(), ""); err != domain.ErrInvalidAirportCode {
+    if _, err := uc.Delete(context.Background(), "", nil); err != domain.ErrInvalidAirportCode {
         t.Fatalf("expected code validation error, got %v", err)
     }
 }
+
+func TestAirportUsecase_SetTimeZone(t *testing.T) {
+    repo := &fakeAirportRepo{list: []domain.Airport{{Code:"CGK", City:"Jakarta", TimeZone:"UTC"}}}
+    uc := NewAirportUsecase(repo)
+    if err := uc.SetTimeZone(context.Background(), "cgk", " Asia/Jakarta "); err != nil {
+        t.Fatalf("set tz err: %v", err)
+    }
+    if repo.list[0].TimeZone != "Asia/Jakarta" { t.Fatalf("zone not updated: %+v", repo.list[0]) }
+    if err := uc.SetTimeZone(context.Background(), "CGK", "Mars/Olympus"); err != domain.ErrInvalidAirportTimeZone {
+        t.Fatalf("want invalid zone, got %v", err)
+    }
+    if _, err := uc.Create(context.Background(), "DPS", "Denpasar", "Local"); err != domain.ErrInvalidAirportTimeZone {
+        t.Fatalf("want host-local zone rejected, got %v", err)
+    }
+}
+
+func TestAirportUsecase_SetMinConnection(t *testing.T) {
+    repo := &fakeAirportRepo{list: []domain.Airport{{Code:"CGK", City:"Jakarta"}}}
+    uc := NewAirportUsecase(repo)
+    if err := uc.SetMinConnection(context.Background(), "cgk", 75); err != nil {
+        t.Fatalf("set mct err: %v", err)
+    }
+    if repo.list[0].MinConnectionMinutes != 75 { t.Fatalf("mct not updated: %+v", repo.list[0]) }
+    if err := uc.SetMinConnection(context.Background(), "CGK", -5); err != domain.ErrInvalidMinConnection {
+        t.Fatalf("want invalid mct, got %v", err)
+    }
+}
+
+func TestAirportUsecase_ArchiveRestore(t *testing.T) {
+    repo := &fakeAirportRepo{list: []domain.Airport{{Code:"CGK", City:"Jakarta"}, {Code:"HLP", City:"Jakarta"}}}
+    uc := NewAirportUsecase(repo)
+    if err := uc.Archive(context.Background(), " hlp "); err != nil { t.Fatalf("archive: %v", err) }
+    items, err := uc.List(context.Background(), 10, 0, false)
+    if err != nil || len(items) != 1 || items[0].Code != "CGK" { t.Fatalf("archived airport still listed: %v %+v", err, items) }
+    if items, _ = uc.List(context.Background(), 10, 0, true); len(items) != 2 { t.Fatalf("want archived airport with includeArchived, got %+v", items) }
+    if a, err := repo.GetByCode(context.Background(), "HLP"); err != nil || !a.Archived() { t.Fatalf("archived airport should still resolve: %v %+v", err, a) }
+    if err := uc.Restore(context.Background(), "HLP"); err != nil { t.Fatalf("restore: %v", err) }
+    if items, _ = uc.List(context.Background(), 10, 0, false); len(items) != 2 { t.Fatalf("restored airport not listed: %+v", items) }
+    if err := uc.Archive(context.Background(), "XXX"); err != domain.ErrAirportNotFound { t.Fatalf("want not found, got %v", err) }
+    if err := uc.Restore(context.Background(), ""); err != domain.ErrInvalidAirportCode { t.Fatalf("want invalid code, got %v", err) }
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// activeAirport loads an airport for a new route; archived airports fail with
+// domain.ErrAirportArchived.
+func activeAirport(ctx context.Context, airports domain.AirportRepository, code string) (*domain.Airport, error) {
+	a, err := airports.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if a.Archived() {
+		return nil, domain.ErrAirportArchived
+	}
+	return a, nil
+}
+
+// activeAirplane loads an airplane for new flights; archived airplanes fail
+// with domain.ErrAirplaneArchived.
+func activeAirplane(ctx context.Context, airplanes domain.AirplaneRepository, code string) (*domain.Airplane, error) {
+	a, err := airplanes.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if a.Archived() {
+		return nil, domain.ErrAirplaneArchived
+	}
+	return a, nil
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// repositoryAvailability answers availability queries by composing the CRUD
+// repositories: one schedule and fare list per route plus an airplane lookup and
+// a booking count per schedule. Every list is read page by page to the end, so
+// no route, schedule or fare is left out. It is the fallback when no set-based
+// AvailabilityRepository is configured.
+type repositoryAvailability struct {
+	bookings  domain.BookingRepository
+	schedules domain.FlightScheduleRepository
+	routes    domain.RouteRepository
+	airplanes domain.AirplaneRepository
+	fares     domain.FareRepository
+}
+
+// NewRepositoryAvailability adapts the CRUD repositories to domain.AvailabilityRepository.
+// fareRepo may be nil, in which case no fares are reported.
+func NewRepositoryAvailability(bookRepo domain.BookingRepository, scheduleRepo domain.FlightScheduleRepository, routeRepo domain.RouteRepository, airplaneRepo domain.AirplaneRepository, fareRepo domain.FareRepository) domain.AvailabilityRepository {
+	return &repositoryAvailability{bookings: bookRepo, schedules: scheduleRepo, routes: routeRepo, airplanes: airplaneRepo, fares: fareRepo}
+}
+
+func (r *repositoryAvailability) Search(ctx context.Context, q domain.AvailabilityQuery) ([]domain.FlightAvailability, error) {
+	routes, err := r.allRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	planes := make(map[string]domain.Airplane)
+	var items []domain.FlightAvailability
+	for _, route := range routes {
+		if q.OriginCode != "" && route.OriginCode != q.OriginCode {
+			continue
+		}
+		if q.DestinationCode != "" && route.DestinationCode != q.DestinationCode {
+			continue
+		}
+		schedules, fares, err := r.routeFlights(ctx, route.Code)
+		if err != nil {
+			return nil, err
+		}
+		for _, sched := range schedules {
+			if sched.Cancelled() {
+				continue
+			}
+			if q.DepartureDate != "" && sched.DepartureDate != q.DepartureDate {
+				continue
+			}
+			if (q.DateFrom != "" && sched.DepartureDate < q.DateFrom) || (q.DateTo != "" && sched.DepartureDate > q.DateTo) {
+				continue
+			}
+			if !q.DepartsAfter.IsZero() && (sched.DepartureAt.IsZero() || sched.DepartureAt.Before(q.DepartsAfter)) {
+				continue
+			}
+			if !q.DepartsBefore.IsZero() && (sched.DepartureAt.IsZero() || sched.DepartureAt.After(q.DepartsBefore)) {
+				continue
+			}
+			plane, ok := planes[sched.AirplaneCode]
+			if !ok {
+				// A schedule whose airplane is gone is reported with no seats
+				// rather than failing the whole search.
+				ap, err := r.airplanes.GetByCode(ctx, sched.AirplaneCode)
+				switch {
+				case errors.Is(err, domain.ErrAirplaneNotFound):
+					plane = domain.Airplane{Code: sched.AirplaneCode}
+				case err != nil:
+					return nil, err
+				default:
+					plane = *ap
+				}
+				planes[sched.AirplaneCode] = plane
+			}
+			booked, err := r.bookings.CountBySchedule(ctx, sched.ID)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, domain.FlightAvailability{
+				Schedule:         sched,
+				OriginCode:       route.OriginCode,
+				DestinationCode:  route.DestinationCode,
+				SeatCapacity:     plane.SeatCapacity,
+				Booked:           booked,
+				AircraftType:     plane.TypeName,
+				AircraftTypeCode: plane.TypeCode,
+				AircraftTypeIATA: plane.TypeIATACode,
+				Fare:             domain.CheapestFare(fares, sched.DepartureDate),
+			})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Schedule, items[j].Schedule
+		if !a.DepartureAt.Equal(b.DepartureAt) {
+			return a.DepartureAt.Before(b.DepartureAt)
+		}
+		return a.ID < b.ID
+	})
+	return items, nil
+}
+
+func (r *repositoryAvailability) RouteExists(ctx context.Context, originCode, destinationCode string) (bool, error) {
+	routes, err := r.allRoutes(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, route := range routes {
+		if route.OriginCode == originCode && route.DestinationCode == destinationCode {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// allRoutes lists every route, archived ones included: flights already
+// scheduled on them are still sold.
+func (r *repositoryAvailability) allRoutes(ctx context.Context) ([]domain.Route, error) {
+	var routes []domain.Route
+	for offset := 0; ; offset += pageSize {
+		page, err := r.routes.List(ctx, pageSize, offset, true)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, page...)
+		if len(page) < pageSize {
+			return routes, nil
+		}
+	}
+}
+
+// routeFlights lists every schedule and fare of a route.
+func (r *repositoryAvailability) routeFlights(ctx context.Context, routeCode string) ([]domain.FlightSchedule, []domain.Fare, error) {
+	var schedules []domain.FlightSchedule
+	for offset := 0; ; offset += pageSize {
+		page, err := r.schedules.List(ctx, routeCode, pageSize, offset)
+		if err != nil {
+			return nil, nil, err
+		}
+		schedules = append(schedules, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+	if r.fares == nil {
+		return schedules, nil, nil
+	}
+	var fares []domain.Fare
+	for offset := 0; ; offset += pageSize {
+		page, err := r.fares.List(ctx, routeCode, pageSize, offset)
+		if err != nil {
+			return nil, nil, err
+		}
+		fares = append(fares, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+	return schedules, fares, nil
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// pagedRouteRepo and pagedScheduleRepo honour limit and offset like the
+// database does.
+type pagedRouteRepo struct {
+	mockRouteRepo
+	items []domain.Route
+}
+
+func (p *pagedRouteRepo) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Route, error) {
+	if offset >= len(p.items) {
+		return nil, nil
+	}
+	return p.items[offset:min(offset+limit, len(p.items))], nil
+}
+
+type pagedScheduleRepo struct {
+	mockScheduleRepo
+	items []domain.FlightSchedule
+}
+
+func (p *pagedScheduleRepo) List(ctx context.Context, routeCode string, limit, offset int) ([]domain.FlightSchedule, error) {
+	var out []domain.FlightSchedule
+	for _, s := range p.items {
+		if s.RouteCode == routeCode {
+			out = append(out, s)
+		}
+	}
+	if offset >= len(out) {
+		return nil, nil
+	}
+	return out[offset:min(offset+limit, len(out))], nil
+}
+
+func TestRepositoryAvailability_ReadsEveryPage(t *testing.T) {
+	routes := &pagedRouteRepo{}
+	for i := 0; i < pageSize; i++ {
+		routes.items = append(routes.items, domain.Route{Code: fmt.Sprintf("R%03d", i), OriginCode: "SUB", DestinationCode: "DPS"})
+	}
+	routes.items = append(routes.items, domain.Route{Code: "CGK-DPS", OriginCode: "CGK", DestinationCode: "DPS"})
+	schedules := &pagedScheduleRepo{}
+	for i := 1; i <= pageSize+1; i++ {
+		schedules.items = append(schedules.items, domain.FlightSchedule{ID: int64(i), RouteCode: "CGK-DPS", AirplaneCode: "A320", DepartureDate: "2025-01-02"})
+	}
+	schedules.items[pageSize].DepartureDate = "2025-01-03"
+	avail := NewRepositoryAvailability(&mockBookingRepo{}, schedules, routes, &mockAirplaneRepo{}, nil)
+
+	if ok, err := avail.RouteExists(context.Background(), "CGK", "DPS"); err != nil || !ok {
+		t.Fatalf("want the route past the first page found, got %v err=%v", ok, err)
+	}
+	items, err := avail.Search(context.Background(), domain.AvailabilityQuery{OriginCode: "CGK", DestinationCode: "DPS", DepartureDate: "2025-01-03"})
+	if err != nil || len(items) != 1 || items[0].Schedule.ID != int64(pageSize+1) {
+		t.Fatalf("want the schedule past the first page found, got %+v err=%v", items, err)
+	}
+}
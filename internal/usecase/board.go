@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// AirportBoard lists the flights departing from, or with Arrivals arriving
+// at, an airport on a local calendar date, in estimated time order.
+type AirportBoard struct {
+	Airport  domain.Airport
+	Date     string
+	Arrivals bool
+	Flights  []domain.BoardFlight
+}
+
+// Board returns the airport's departures, or with arrivals its arrivals, on
+// date: the flights estimated to depart or arrive between the airport's local
+// midnights, cancelled ones included, ordered by that estimate. A delay can
+// move a flight onto the next day's board. Flights without a planned arrival
+// arrive their route's block time after departing. Archived airports keep
+// their boards.
+func (u *ScheduleUsecase) Board(ctx context.Context, airportCode, date string, arrivals bool) (*AirportBoard, error) {
+	a := domain.Airport{Code: airportCode, City: "x"}
+	a.Normalize()
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	airport, err := u.airports.GetByCode(ctx, a.Code)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := domain.BoardDay(date, airport.Location())
+	if err != nil {
+		return nil, err
+	}
+	board := &AirportBoard{Airport: *airport, Date: from.In(airport.Location()).Format("2006-01-02"), Arrivals: arrivals}
+	for offset := 0; ; offset += pageSize {
+		page, err := u.schedules.ListByAirport(ctx, airport.Code, arrivals, from, to, pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		board.Flights = append(board.Flights, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+	return board, nil
+}
+
+// SetGates assigns a flight's departure and arrival gates. A nil gate keeps
+// the current one and an empty gate clears it. It fails with
+// domain.ErrScheduleCancelled on a cancelled flight.
+func (u *ScheduleUsecase) SetGates(ctx context.Context, id int64, departureGate, arrivalGate *string) (*domain.FlightSchedule, error) {
+	if id <= 0 {
+		return nil, domain.ErrInvalidScheduleID
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	sched, err := u.schedules.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sched.Cancelled() {
+		return nil, domain.ErrScheduleCancelled
+	}
+	if departureGate != nil {
+		if sched.DepartureGate, err = domain.NormalizeGate(*departureGate); err != nil {
+			return nil, err
+		}
+	}
+	if arrivalGate != nil {
+		if sched.ArrivalGate, err = domain.NormalizeGate(*arrivalGate); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.schedules.SetGates(ctx, sched.ID, sched.DepartureGate, sched.ArrivalGate); err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func TestScheduleUsecase_Board(t *testing.T) {
+	// Jakarta's 2 January runs from 17:00 UTC on 1 January.
+	day := time.Date(2025, 1, 1, 17, 0, 0, 0, time.UTC)
+	flight := func(id int64, origin, destination string, departs time.Duration) domain.BoardFlight {
+		s := domain.FlightSchedule{ID: id, DepartureAt: day.Add(departs), ArrivalAt: day.Add(departs + 2*time.Hour)}
+		return domain.BoardFlight{Schedule: s, OriginCode: origin, DestinationCode: destination, Capacity: 180, Booked: 90}
+	}
+	repo := &fakeScheduleRepo{board: []domain.BoardFlight{
+		flight(1, "CGK", "DPS", 2*time.Hour),
+		flight(2, "CGK", "DPS", 23*time.Hour),
+		flight(3, "CGK", "DPS", 25*time.Hour),
+		flight(4, "DPS", "CGK", -time.Hour),
+	}}
+	uc := NewScheduleUsecase(repo, &fakeRouteRepoSched{}, &fakeAirplaneRepoSched{}, newSchedAirports())
+
+	board, err := uc.Board(context.Background(), " cgk ", "2025-01-02", false)
+	if err != nil {
+		t.Fatalf("board: %v", err)
+	}
+	if board.Airport.Code != "CGK" || board.Date != "2025-01-02" || board.Arrivals || len(board.Flights) != 2 || board.Flights[0].Schedule.ID != 1 || board.Flights[1].Schedule.ID != 2 {
+		t.Fatalf("unexpected departures %+v", board)
+	}
+	// Flight 4 leaves the day before and lands after local midnight.
+	board, err = uc.Board(context.Background(), "CGK", "2025-01-02", true)
+	if err != nil || !board.Arrivals || len(board.Flights) != 1 || board.Flights[0].Schedule.ID != 4 {
+		t.Fatalf("unexpected arrivals %+v err=%v", board, err)
+	}
+
+	if _, err := uc.Board(context.Background(), "XXX", "2025-01-02", false); err != domain.ErrAirportNotFound {
+		t.Fatalf("want airport not found, got %v", err)
+	}
+	if _, err := uc.Board(context.Background(), "CGK", "02/01/2025", false); err != domain.ErrInvalidScheduleDate {
+		t.Fatalf("want invalid date, got %v", err)
+	}
+}
+
+func TestScheduleUsecase_SetGates(t *testing.T) {
+	repo := &fakeScheduleRepo{items: []domain.FlightSchedule{
+		{ID: 1, RouteCode: "RT1", DepartureGate: "A1", ArrivalGate: "B2"},
+		{ID: 2, RouteCode: "RT1", Status: domain.ScheduleStatusCancelled},
+	}}
+	uc := NewScheduleUsecase(repo, &fakeRouteRepoSched{}, &fakeAirplaneRepoSched{}, newSchedAirports())
+	gate := func(g string) *string { return &g }
+
+	sched, err := uc.SetGates(context.Background(), 1, gate(" a12 "), nil)
+	if err != nil || sched.DepartureGate != "A12" || sched.ArrivalGate != "B2" {
+		t.Fatalf("unexpected gates %+v err=%v", sched, err)
+	}
+	if _, err := uc.SetGates(context.Background(), 1, nil, gate("")); err != nil || repo.items[0].DepartureGate != "A12" || repo.items[0].ArrivalGate != "" {
+		t.Fatalf("gates not stored: %+v err=%v", repo.items[0], err)
+	}
+
+	if _, err := uc.SetGates(context.Background(), 1, gate("TOO-LONG-1"), nil); err != domain.ErrInvalidGate {
+		t.Fatalf("want invalid gate, got %v", err)
+	}
+	if _, err := uc.SetGates(context.Background(), 2, gate("A1"), nil); err != domain.ErrScheduleCancelled {
+		t.Fatalf("want schedule cancelled, got %v", err)
+	}
+	if _, err := uc.SetGates(context.Background(), 0, gate("A1"), nil); err != domain.ErrInvalidScheduleID {
+		t.Fatalf("want invalid id, got %v", err)
+	}
+}
@@ -2,9 +2,9 @@ package usecase
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,313 +18,426 @@ type FlightOption struct {
 	OriginCode      string
 	DestinationCode string
 	AirplaneCode    string
+	AircraftType    string // aircraft type name; empty when the airplane has no type
 	DepartureDate   string
+	DepartureTime   time.Time // local time at the origin airport
+	ArrivalTime     time.Time // local time at the destination airport; zero when unplanned
 	SeatsAvailable  int
 	TotalSeats      int
+	Fare            *domain.Fare // cheapest applicable fare; nil when none is published
 }
 
 // BookingUsecase coordinates booking workflows across repositories.
 type BookingUsecase struct {
-	bookings    domain.BookingRepository
-	schedules   domain.FlightScheduleRepository
-	routes      domain.RouteRepository
-	airplanes   domain.AirplaneRepository
-	timeout     time.Duration
-	generateRef func() string
+	bookings     domain.BookingRepository
+	schedules    domain.FlightScheduleRepository
+	routes       domain.RouteRepository
+	airplanes    domain.AirplaneRepository
+	airports     domain.AirportRepository
+	availability domain.AvailabilityRepository
+	trips        domain.TripRepository
+	fares        domain.FareRepository
+	inventory    domain.SeatInventoryRepository
+	ticketing    *TicketUsecase
+	connections  domain.ConnectionPolicy
+	timeout      time.Duration
+	generateRef  func(ctx context.Context) (string, error)
 }
 
 // NewBookingUsecase builds a BookingUsecase with sane defaults.
 func NewBookingUsecase(bookRepo domain.BookingRepository, scheduleRepo domain.FlightScheduleRepository, routeRepo domain.RouteRepository, airplaneRepo domain.AirplaneRepository) *BookingUsecase {
+	// The default format is statically valid, so construction cannot fail.
+	refs, _ := NewReferenceGenerator(bookRepo, domain.DefaultReferenceFormat)
 	return &BookingUsecase{
-		bookings:    bookRepo,
-		schedules:   scheduleRepo,
-		routes:      routeRepo,
-		airplanes:   airplaneRepo,
-		timeout:     5 * time.Second,
-		generateRef: defaultBookingReference,
+		bookings:     bookRepo,
+		schedules:    scheduleRepo,
+		routes:       routeRepo,
+		airplanes:    airplaneRepo,
+		availability: NewRepositoryAvailability(bookRepo, scheduleRepo, routeRepo, airplaneRepo, nil),
+		connections:  domain.DefaultConnectionPolicy,
+		timeout:      5 * time.Second,
+		generateRef:  refs.Generate,
 	}
 }
 
+// WithReferenceGenerator replaces the default six-character locator generator.
+func (u *BookingUsecase) WithReferenceGenerator(g *ReferenceGenerator) *BookingUsecase {
+	u.generateRef = g.Generate
+	return u
+}
+
+// WithAvailability replaces the repository-composed availability lookups used by
+// search with a set-based implementation such as a single SQL query.
+func (u *BookingUsecase) WithAvailability(a domain.AvailabilityRepository) *BookingUsecase {
+	u.availability = a
+	return u
+}
+
+// WithTrips enables booking several flights together, such as round trips, as one trip.
+func (u *BookingUsecase) WithTrips(t domain.TripRepository) *BookingUsecase {
+	u.trips = t
+	return u
+}
+
+// WithFares lets trips be priced from the fares published on their routes.
+func (u *BookingUsecase) WithFares(f domain.FareRepository) *BookingUsecase {
+	u.fares = f
+	return u
+}
+
+// WithInventory checks new bookings against each flight's seat inventory, whose
+// capacity can differ from the airplane's after a forced seat change.
+func (u *BookingUsecase) WithInventory(i domain.SeatInventoryRepository) *BookingUsecase {
+	u.inventory = i
+	return u
+}
+
+// WithTicketing enables e-ticket issuance for every booking created by the usecase.
+func (u *BookingUsecase) WithTicketing(t *TicketUsecase) *BookingUsecase {
+	u.ticketing = t
+	return u
+}
+
+// WithConnectionPolicy sets the layover window for transit search. Per-airport
+// minimum connection times are read from airports when it is non-nil.
+func (u *BookingUsecase) WithConnectionPolicy(airports domain.AirportRepository, policy domain.ConnectionPolicy) *BookingUsecase {
+	u.airports = airports
+	u.connections = policy
+	return u
+}
+
 // SearchDirectFlights finds direct schedules between two airports with available seats.
 func (u *BookingUsecase) SearchDirectFlights(ctx context.Context, originCode, destinationCode, departureDate string) ([]FlightOption, error) {
-	origin := strings.ToUpper(strings.TrimSpace(originCode))
-	destination := strings.ToUpper(strings.TrimSpace(destinationCode))
-	date := strings.TrimSpace(departureDate)
+	return u.FindDirectFlights(ctx, SearchQuery{OriginCode: originCode, DestinationCode: destinationCode, DepartureDate: departureDate})
+}
 
-	if len(origin) == 0 || len(origin) > 8 {
-		return nil, domain.ErrInvalidRouteAirports
+// FindDirectFlights finds direct flights matching the query, earliest first
+// unless another sort key is given, using a single availability query for the
+// whole date range.
+func (u *BookingUsecase) FindDirectFlights(ctx context.Context, q SearchQuery) ([]FlightOption, error) {
+	c, err := q.criteria()
+	if err != nil {
+		return nil, err
 	}
-	if len(destination) == 0 || len(destination) > 8 {
-		return nil, domain.ErrInvalidRouteAirports
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	rows, err := u.availability.Search(ctx, c.firstLegQuery(domain.AvailabilityQuery{OriginCode: c.origin, DestinationCode: c.destination}))
+	if err != nil {
+		return nil, c.trace.failed(1, c.origin, c.destination, err)
 	}
-	if origin == destination {
-		return nil, domain.ErrInvalidRouteAirports
+	if len(rows) == 0 {
+		exists, err := u.availability.RouteExists(ctx, c.origin, c.destination)
+		if err != nil {
+			return nil, c.trace.failed(1, c.origin, c.destination, err)
+		}
+		if !exists {
+			return nil, domain.ErrRouteNotFound
+		}
+		return nil, nil
 	}
 
-	if date != "" {
-		if _, err := time.Parse("2006-01-02", date); err != nil {
-			return nil, domain.ErrInvalidScheduleDate
+	var options []FlightOption
+	for _, row := range rows {
+		reason, detail := c.rejectFirstLeg(row)
+		c.trace.candidate(1, row, reason, detail)
+		if reason != "" {
+			continue
 		}
+		options = append(options, flightOption(row))
+	}
+	if c.sortBy != "" {
+		sort.SliceStable(options, c.lessBy(func(i int) journey { return directJourney(options[i]) }))
 	}
+	return options, nil
+}
 
-	ctx, cancel := context.WithTimeout(ctx, u.timeout)
-	defer cancel()
+// TransitOption captures a connecting flight route with remaining seat inventory on each leg.
+type TransitOption struct {
+	FirstLeg       FlightOption
+	SecondLeg      FlightOption
+	Intermediate   string        // Intermediate airport code
+	TotalAvailable int           // Limited by the leg with fewer seats
+	Layover        time.Duration // Time on the ground at the intermediate airport
+	TotalDuration  time.Duration // First departure to final arrival; zero when the second leg's arrival is unplanned
+	TotalFare      domain.Money  // Sum of both legs' fares; zero unless both are priced in one currency
+}
 
-	routes, err := u.routes.List(ctx, 500, 0)
+// SearchTransitFlights finds connecting schedules between two airports via intermediate airports with available seats on both legs.
+// The date applies to the first leg; the second leg must depart within the connection window after the first
+// lands, which may be on a later day. Candidates for each leg are fetched with one availability query.
+func (u *BookingUsecase) SearchTransitFlights(ctx context.Context, originCode, destinationCode, departureDate string) ([]TransitOption, error) {
+	return u.FindTransitFlights(ctx, SearchQuery{OriginCode: originCode, DestinationCode: destinationCode, DepartureDate: departureDate})
+}
+
+// FindTransitFlights is SearchTransitFlights for a full query: the dates, window
+// and airplane apply to the first leg and every leg needs a seat per passenger.
+func (u *BookingUsecase) FindTransitFlights(ctx context.Context, q SearchQuery) ([]TransitOption, error) {
+	c, err := q.criteria()
 	if err != nil {
 		return nil, err
 	}
+	origin, destination := c.origin, c.destination
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
 
-	var matched []domain.Route
-	for _, route := range routes {
-		if route.OriginCode == origin && route.DestinationCode == destination {
-			matched = append(matched, route)
+	rows, err := u.availability.Search(ctx, c.firstLegQuery(domain.AvailabilityQuery{OriginCode: origin}))
+	if err != nil {
+		return nil, c.trace.failed(1, origin, "", err)
+	}
+	var (
+		firsts        []domain.FlightAvailability
+		earliest, end time.Time
+	)
+	for _, row := range rows {
+		if row.DestinationCode == destination {
+			continue
+		}
+		reason, detail := c.rejectFirstLeg(row)
+		if reason == "" && row.Schedule.ArrivalAt.IsZero() {
+			// Without a planned arrival there is no way to honour the minimum connection time.
+			reason, detail = RejectNoArrival, "arrival time unplanned"
+		}
+		if reason != "" {
+			c.trace.candidate(1, row, reason, detail)
+			continue
+		}
+		firsts = append(firsts, row)
+		if earliest.IsZero() || row.Schedule.ArrivalAt.Before(earliest) {
+			earliest = row.Schedule.ArrivalAt
+		}
+		if row.Schedule.ArrivalAt.After(end) {
+			end = row.Schedule.ArrivalAt
 		}
 	}
-	if len(matched) == 0 {
-		return nil, domain.ErrRouteNotFound
+	if len(firsts) == 0 {
+		return nil, nil
 	}
 
-	planeCache := make(map[string]domain.Airplane)
-	var options []FlightOption
+	rows, err = u.availability.Search(ctx, domain.AvailabilityQuery{
+		DestinationCode: destination,
+		DepartsAfter:    earliest,
+		DepartsBefore:   end.Add(u.connections.MaxLayover),
+	})
+	if err != nil {
+		return nil, c.trace.failed(2, "", destination, err)
+	}
+	seconds := make(map[string][]domain.FlightAvailability)
+	for _, row := range rows {
+		if reason, detail := c.rejectLeg(row); reason != "" {
+			c.trace.candidate(2, row, reason, detail)
+			continue
+		}
+		seconds[row.OriginCode] = append(seconds[row.OriginCode], row)
+	}
 
-	for _, route := range matched {
-		schedules, err := u.schedules.List(ctx, route.Code, 500, 0)
+	minimums := make(map[string]time.Duration)
+	distances := u.distances()
+	var validTransitOptions []TransitOption
+	for _, first := range firsts {
+		intermediate := first.DestinationCode
+		flown, direct, circuitous, err := distances.circuitous(ctx, u.connections, origin, intermediate, destination)
 		if err != nil {
-			return nil, err
+			return nil, c.trace.failed(1, intermediate, destination, err)
 		}
-		for _, sched := range schedules {
-			if date != "" && sched.DepartureDate != date {
-				continue
-			}
-			plane, ok := planeCache[sched.AirplaneCode]
-			if !ok {
-				ap, err := u.airplanes.GetByCode(ctx, sched.AirplaneCode)
-				if err != nil {
-					return nil, err
-				}
-				plane = *ap
-				planeCache[sched.AirplaneCode] = plane
+		if circuitous {
+			c.trace.candidate(1, first, RejectCircuitous, fmt.Sprintf("%d km via %s, %d km direct", flown, intermediate, direct))
+			continue
+		}
+		candidates := seconds[intermediate]
+		if len(candidates) == 0 {
+			c.trace.candidate(1, first, RejectNoOnwardFlight, fmt.Sprintf("no bookable %s-%s flight", intermediate, destination))
+			continue
+		}
+		minimum, ok := minimums[intermediate]
+		if !ok {
+			if minimum, err = u.minConnectionAt(ctx, intermediate); err != nil {
+				return nil, c.trace.failed(2, intermediate, destination, err)
 			}
-			if plane.SeatCapacity <= 0 {
+			minimums[intermediate] = minimum
+		}
+		connected := false
+		for _, second := range candidates {
+			layover, reason, detail := u.rejectConnection(first.Schedule, second.Schedule, minimum)
+			if reason != "" {
+				c.trace.connection(first, second, reason, detail)
 				continue
 			}
-			count, err := u.bookings.CountBySchedule(ctx, sched.ID)
-			if err != nil {
-				return nil, err
+			connected = true
+
+			// The total available seats is limited by the leg with fewer seats
+			totalAvailable := first.SeatsAvailable()
+			if second.SeatsAvailable() < totalAvailable {
+				totalAvailable = second.SeatsAvailable()
 			}
-			available := plane.SeatCapacity - count
-			if available <= 0 {
-				continue
+
+			var total time.Duration
+			if !second.Schedule.ArrivalAt.IsZero() {
+				total = second.Schedule.ArrivalAt.Sub(first.Schedule.DepartureAt)
 			}
-			options = append(options, FlightOption{
-				ScheduleID:      sched.ID,
-				RouteCode:       route.Code,
-				OriginCode:      origin,
-				DestinationCode: destination,
-				AirplaneCode:    sched.AirplaneCode,
-				DepartureDate:   sched.DepartureDate,
-				SeatsAvailable:  available,
-				TotalSeats:      plane.SeatCapacity,
+
+			validTransitOptions = append(validTransitOptions, TransitOption{
+				FirstLeg:       flightOption(first),
+				SecondLeg:      flightOption(second),
+				Intermediate:   intermediate,
+				TotalAvailable: totalAvailable,
+				Layover:        layover,
+				TotalDuration:  total,
+				TotalFare:      totalFare(first.Fare, second.Fare),
 			})
 		}
+		if connected {
+			c.trace.candidate(1, first, "", "")
+		} else {
+			c.trace.candidate(1, first, RejectNoOnwardFlight, fmt.Sprintf("no %s-%s flight within the connection window", intermediate, destination))
+		}
 	}
 
-	return options, nil
+	if c.sortBy != "" {
+		sort.SliceStable(validTransitOptions, c.lessBy(func(i int) journey { return transitJourney(validTransitOptions[i]) }))
+	}
+	return validTransitOptions, nil
 }
 
-// TransitOption captures a connecting flight route with remaining seat inventory on each leg.
-type TransitOption struct {
-	FirstLeg       FlightOption
-	SecondLeg      FlightOption
-	Intermediate   string // Intermediate airport code
-	TotalAvailable int    // Limited by the leg with fewer seats
+// MaxFlexDays bounds how many days either side of the requested date a flexible search covers.
+const MaxFlexDays = 7
+
+// searchDates is the inclusive range of local departure dates a search covers; empty means any date.
+type searchDates struct{ from, to string }
+
+func (d searchDates) contains(date string) bool {
+	return d.from == "" || (d.from <= date && date <= d.to)
 }
 
-// SearchTransitFlights finds connecting schedules between two airports via intermediate airports with available seats on both legs.
-func (u *BookingUsecase) SearchTransitFlights(ctx context.Context, originCode, destinationCode, departureDate string) ([]TransitOption, error) {
+// widen extends the range by days on either side; an empty range stays empty.
+func (d searchDates) widen(days int) searchDates {
+	if d.from == "" {
+		return d
+	}
+	from, errFrom := time.Parse("2006-01-02", d.from)
+	to, errTo := time.Parse("2006-01-02", d.to)
+	if errFrom != nil || errTo != nil {
+		return d
+	}
+	return searchDates{from: from.AddDate(0, 0, -days).Format("2006-01-02"), to: to.AddDate(0, 0, days).Format("2006-01-02")}
+}
+
+// query restricts an availability query to the range.
+func (d searchDates) query(q domain.AvailabilityQuery) domain.AvailabilityQuery {
+	if d.from != "" && d.from == d.to {
+		q.DepartureDate = d.from
+	} else {
+		q.DateFrom, q.DateTo = d.from, d.to
+	}
+	return q
+}
+
+// normalizeSearch validates and canonicalises the airport codes and the optional
+// date of a search, widening the date by flexDays on either side.
+func normalizeSearch(originCode, destinationCode, departureDate string, flexDays int) (string, string, searchDates, error) {
 	origin := strings.ToUpper(strings.TrimSpace(originCode))
 	destination := strings.ToUpper(strings.TrimSpace(destinationCode))
 	date := strings.TrimSpace(departureDate)
 
 	if len(origin) == 0 || len(origin) > 8 {
-		return nil, domain.ErrInvalidRouteAirports
+		return "", "", searchDates{}, domain.ErrInvalidRouteAirports
 	}
 	if len(destination) == 0 || len(destination) > 8 {
-		return nil, domain.ErrInvalidRouteAirports
+		return "", "", searchDates{}, domain.ErrInvalidRouteAirports
 	}
 	if origin == destination {
-		return nil, domain.ErrInvalidRouteAirports
+		return "", "", searchDates{}, domain.ErrInvalidRouteAirports
 	}
-
-	if date != "" {
-		if _, err := time.Parse("2006-01-02", date); err != nil {
-			return nil, domain.ErrInvalidScheduleDate
-		}
+	if flexDays < 0 || flexDays > MaxFlexDays || (flexDays > 0 && date == "") {
+		return "", "", searchDates{}, domain.ErrInvalidFlexDays
 	}
-
-	ctx, cancel := context.WithTimeout(ctx, u.timeout)
-	defer cancel()
-
-	// First, get all routes that start from the origin
-	originRoutes, err := u.routes.List(ctx, 500, 0)
+	if date == "" {
+		return origin, destination, searchDates{}, nil
+	}
+	day, err := time.Parse("2006-01-02", date)
 	if err != nil {
-		return nil, err
+		return "", "", searchDates{}, domain.ErrInvalidScheduleDate
 	}
+	return origin, destination, searchDates{
+		from: day.AddDate(0, 0, -flexDays).Format("2006-01-02"),
+		to:   day.AddDate(0, 0, flexDays).Format("2006-01-02"),
+	}, nil
+}
 
-	var validTransitOptions []TransitOption
-
-	// Find intermediate airports - airports reachable from origin but not the destination
-	var intermediateAirports []string
-	for _, route := range originRoutes {
-		if route.OriginCode == origin && route.DestinationCode != destination {
-			// Check if there are schedules for this route on the specified date
-			schedules, err := u.schedules.List(ctx, route.Code, 500, 0)
-			if err != nil {
-				continue // Skip this route if there's an error
-			}
-			
-			hasScheduleOnDate := false
-			for _, sched := range schedules {
-				if date == "" || sched.DepartureDate == date {
-					hasScheduleOnDate = true
-					break
-				}
-			}
-			
-			if hasScheduleOnDate {
-				// Add the destination of this route as a potential intermediate airport
-				intermediateAirports = append(intermediateAirports, route.DestinationCode)
-			}
-		}
+// flightOption converts an availability row into a bookable flight option.
+func flightOption(a domain.FlightAvailability) FlightOption {
+	return FlightOption{
+		ScheduleID:      a.Schedule.ID,
+		RouteCode:       a.Schedule.RouteCode,
+		OriginCode:      a.OriginCode,
+		DestinationCode: a.DestinationCode,
+		AirplaneCode:    a.Schedule.AirplaneCode,
+		AircraftType:    a.AircraftType,
+		DepartureDate:   a.Schedule.DepartureDate,
+		DepartureTime:   a.Schedule.LocalDeparture(),
+		ArrivalTime:     a.Schedule.LocalArrival(),
+		SeatsAvailable:  a.SeatsAvailable(),
+		TotalSeats:      a.SeatCapacity,
+		Fare:            a.Fare,
 	}
+}
 
-	// For each potential intermediate airport, check if there's a route from there to the destination
-	for _, intermediate := range intermediateAirports {
-		// Get the first leg (origin to intermediate)
-		firstLegRoutes := []domain.Route{}
-		for _, route := range originRoutes {
-			if route.OriginCode == origin && route.DestinationCode == intermediate {
-				firstLegRoutes = append(firstLegRoutes, route)
-			}
+// totalFare sums the one-way fares of a journey's legs. It is zero when any leg
+// is unpriced or the legs are priced in different currencies.
+func totalFare(fares ...*domain.Fare) domain.Money {
+	var total domain.Money
+	for i, f := range fares {
+		if f == nil {
+			return domain.Money{}
 		}
-		
-		if len(firstLegRoutes) == 0 {
+		if i == 0 {
+			total = f.OneWay
 			continue
 		}
-		
-		// Get the second leg (intermediate to destination)
-		secondLegRoutes := []domain.Route{}
-		for _, route := range originRoutes {
-			if route.OriginCode == intermediate && route.DestinationCode == destination {
-				secondLegRoutes = append(secondLegRoutes, route)
-			}
-		}
-		
-		if len(secondLegRoutes) == 0 {
-			continue
+		var ok bool
+		if total, ok = total.Add(f.OneWay); !ok {
+			return domain.Money{}
 		}
+	}
+	return total
+}
 
-		// Find valid schedules for the first leg
-		for _, firstRoute := range firstLegRoutes {
-			firstSchedules, err := u.schedules.List(ctx, firstRoute.Code, 500, 0)
-			if err != nil {
-				continue
-			}
+// minConnectionAt resolves the minimum connection time at an airport, using the
+// policy default when no airport repository is configured or the airport has no override.
+func (u *BookingUsecase) minConnectionAt(ctx context.Context, code string) (time.Duration, error) {
+	return minimumConnection(ctx, u.airports, u.connections, code)
+}
 
-			for _, firstSched := range firstSchedules {
-				if date != "" && firstSched.DepartureDate != date {
-					continue
-				}
-
-				// Get available seats for first leg
-				firstPlane, err := u.airplanes.GetByCode(ctx, firstSched.AirplaneCode)
-				if err != nil {
-					continue
-				}
-				firstBooked, err := u.bookings.CountBySchedule(ctx, firstSched.ID)
-				if err != nil {
-					continue
-				}
-				firstAvailable := firstPlane.SeatCapacity - firstBooked
-
-				if firstAvailable <= 0 {
-					continue
-				}
-
-				// Now find valid schedules for the second leg
-				for _, secondRoute := range secondLegRoutes {
-					secondSchedules, err := u.schedules.List(ctx, secondRoute.Code, 500, 0)
-					if err != nil {
-						continue
-					}
-
-					for _, secondSched := range secondSchedules {
-						// In a real system, you'd check that the second leg departs after the first leg arrives
-						// For now, we just ensure the second leg is on the requested date if specified
-						if date != "" && secondSched.DepartureDate != date {
-							continue
-						}
-
-						// Get available seats for second leg
-						secondPlane, err := u.airplanes.GetByCode(ctx, secondSched.AirplaneCode)
-						if err != nil {
-							continue
-						}
-						secondBooked, err := u.bookings.CountBySchedule(ctx, secondSched.ID)
-						if err != nil {
-							continue
-						}
-						secondAvailable := secondPlane.SeatCapacity - secondBooked
-
-						if secondAvailable <= 0 {
-							continue
-						}
-
-						// The total available seats is limited by the leg with fewer seats
-						totalAvailable := firstAvailable
-						if secondAvailable < totalAvailable {
-							totalAvailable = secondAvailable
-						}
-
-						if totalAvailable > 0 {
-							transitOption := TransitOption{
-								FirstLeg: FlightOption{
-									ScheduleID:      firstSched.ID,
-									RouteCode:       firstRoute.Code,
-									OriginCode:      firstRoute.OriginCode,
-									DestinationCode: firstRoute.DestinationCode,
-									AirplaneCode:    firstSched.AirplaneCode,
-									DepartureDate:   firstSched.DepartureDate,
-									SeatsAvailable:  firstAvailable,
-									TotalSeats:      firstPlane.SeatCapacity,
-								},
-								SecondLeg: FlightOption{
-									ScheduleID:      secondSched.ID,
-									RouteCode:       secondRoute.Code,
-									OriginCode:      secondRoute.OriginCode,
-									DestinationCode: secondRoute.DestinationCode,
-									AirplaneCode:    secondSched.AirplaneCode,
-									DepartureDate:   secondSched.DepartureDate,
-									SeatsAvailable:  secondAvailable,
-									TotalSeats:      secondPlane.SeatCapacity,
-								},
-								Intermediate:   intermediate,
-								TotalAvailable: totalAvailable,
-							}
-							validTransitOptions = append(validTransitOptions, transitOption)
-						}
-					}
-				}
-			}
-		}
+// minimumConnection resolves the minimum connection time at an airport from
+// its override in airports, which may be nil, or the policy default.
+func minimumConnection(ctx context.Context, airports domain.AirportRepository, policy domain.ConnectionPolicy, code string) (time.Duration, error) {
+	if airports == nil {
+		return policy.MinConnection, nil
 	}
-
-	return validTransitOptions, nil
+	airport, err := airports.GetByCode(ctx, code)
+	if errors.Is(err, domain.ErrAirportNotFound) {
+		return policy.MinConnection, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return policy.MinimumAt(airport), nil
 }
 
 // Create generates a booking for a passenger on a given schedule with automatic seat assignment.
 func (u *BookingUsecase) Create(ctx context.Context, scheduleID int64, passengerName string) (*domain.Booking, error) {
+	return u.book(ctx, scheduleID, passengerName, domain.BookingStatusConfirmed)
+}
+
+// Hold reserves a seat without ticketing it. The seat stays out of sale until the
+// booking is confirmed or cancelled.
+func (u *BookingUsecase) Hold(ctx context.Context, scheduleID int64, passengerName string) (*domain.Booking, error) {
+	return u.book(ctx, scheduleID, passengerName, domain.BookingStatusHeld)
+}
+
+func (u *BookingUsecase) book(ctx context.Context, scheduleID int64, passengerName string, status string) (*domain.Booking, error) {
 	if scheduleID <= 0 {
 		return nil, domain.ErrInvalidScheduleID
 	}
@@ -339,6 +452,69 @@ func (u *BookingUsecase) Create(ctx context.Context, scheduleID int64, passenger
 	if err != nil {
 		return nil, err
 	}
+	booking, err := u.newBooking(ctx, sched, passengerName, status)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		if err := u.assignReference(ctx, booking); err != nil {
+			return nil, err
+		}
+		err = u.bookings.Create(ctx, booking)
+		if err == nil {
+			break
+		}
+		// Another booking may claim the reference between the uniqueness check and the insert.
+		if !errors.Is(err, domain.ErrBookingExists) {
+			return nil, err
+		}
+		if attempt >= maxReferenceAttempts {
+			return nil, domain.ErrReferenceExhausted
+		}
+	}
+	if status == domain.BookingStatusConfirmed {
+		if err := u.issueTicket(ctx, booking, domain.BookingStatusCancelled); err != nil {
+			return nil, err
+		}
+	}
+	return booking, nil
+}
+
+// issueTicket tickets a confirmed booking when ticketing is enabled. When
+// issuing fails the booking is moved to revert, so no seat stays confirmed
+// without a ticket, and the issuing error is returned.
+func (u *BookingUsecase) issueTicket(ctx context.Context, booking *domain.Booking, revert string) error {
+	if u.ticketing == nil {
+		return nil
+	}
+	_, err := u.ticketing.Issue(ctx, booking)
+	if err == nil {
+		return nil
+	}
+	if rerr := u.revertBooking(ctx, booking, revert); rerr != nil {
+		return errors.Join(err, rerr)
+	}
+	return err
+}
+
+// revertBooking moves a booking back after a failed step. It runs on its own
+// timeout so it still completes when the failure was the caller's deadline.
+func (u *BookingUsecase) revertBooking(ctx context.Context, booking *domain.Booking, status string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), u.timeout)
+	defer cancel()
+	if err := u.bookings.UpdateStatus(ctx, booking.ID, booking.Status, status); err != nil {
+		return err
+	}
+	booking.Status = status
+	return nil
+}
+
+// newBooking checks that the schedule is not cancelled and still has a seat,
+// counting against its seat inventory when one is configured, and prepares an unreferenced booking on it with a provisional seat number.
+func (u *BookingUsecase) newBooking(ctx context.Context, sched *domain.FlightSchedule, passengerName, status string) (*domain.Booking, error) {
+	if sched.Cancelled() {
+		return nil, domain.ErrScheduleCancelled
+	}
 	plane, err := u.airplanes.GetByCode(ctx, sched.AirplaneCode)
 	if err != nil {
 		return nil, err
@@ -346,31 +522,98 @@ func (u *BookingUsecase) Create(ctx context.Context, scheduleID int64, passenger
 	if plane.SeatCapacity <= 0 {
 		return nil, domain.ErrInvalidSeatCapacity
 	}
-	count, err := u.bookings.CountBySchedule(ctx, scheduleID)
+	capacity := plane.SeatCapacity
+	if u.inventory != nil {
+		// Flights left overbooked by a forced seat change keep their old
+		// capacity, which is what the repository books against.
+		inv, err := u.inventory.Get(ctx, sched.ID)
+		switch {
+		case err == nil:
+			capacity = inv.Capacity
+		case !errors.Is(err, domain.ErrInventoryNotFound):
+			return nil, err
+		}
+	}
+	count, err := u.bookings.CountBySchedule(ctx, sched.ID)
 	if err != nil {
 		return nil, err
 	}
-	if count >= plane.SeatCapacity {
+	if count >= capacity {
 		return nil, domain.ErrFlightFull
 	}
-
-	booking := &domain.Booking{
-		Reference:     u.generateRef(),
-		ScheduleID:    scheduleID,
+	return &domain.Booking{
+		ScheduleID:    sched.ID,
 		PassengerName: passengerName,
-		SeatNumber:    count + 1,
-		Status:        domain.BookingStatusConfirmed,
+		SeatNumber:    count + 1, // the repository may move it to the lowest free seat
+		Status:        status,
+	}, nil
+}
+
+// assignReference gives the booking a fresh reference and validates it.
+func (u *BookingUsecase) assignReference(ctx context.Context, booking *domain.Booking) error {
+	ref, err := u.generateRef(ctx)
+	if err != nil {
+		return err
 	}
+	booking.Reference = ref
 	booking.Normalize()
-	if err := booking.Validate(); err != nil {
+	return booking.Validate()
+}
+
+// Confirm turns a held booking into a confirmed one and tickets it. When the
+// ticket cannot be issued the booking goes back to held.
+func (u *BookingUsecase) Confirm(ctx context.Context, reference string) (*domain.Booking, error) {
+	booking, err := u.transition(ctx, reference, domain.BookingStatusConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.issueTicket(ctx, booking, domain.BookingStatusHeld); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// Cancel releases the booking's seat back to inventory and refunds its open coupons.
+func (u *BookingUsecase) Cancel(ctx context.Context, reference string) (*domain.Booking, error) {
+	booking, err := u.transition(ctx, reference, domain.BookingStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	if u.ticketing != nil {
+		if err := u.ticketing.refundOpen(ctx, booking.ID); err != nil {
+			return nil, err
+		}
+	}
+	return booking, nil
+}
+
+func (u *BookingUsecase) transition(ctx context.Context, reference, status string) (*domain.Booking, error) {
+	booking, err := u.GetByReference(ctx, reference)
+	if err != nil {
 		return nil, err
 	}
-	if err := u.bookings.Create(ctx, booking); err != nil {
+	if !booking.CanTransition(status) {
+		return nil, domain.ErrInvalidBookingTransition
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	if err := u.bookings.UpdateStatus(ctx, booking.ID, booking.Status, status); err != nil {
 		return nil, err
 	}
+	booking.Status = status
 	return booking, nil
 }
 
+// Tickets returns the e-tickets issued for a booking, or none when ticketing is disabled.
+func (u *BookingUsecase) Tickets(ctx context.Context, bookingID int64) ([]domain.Ticket, error) {
+	if u.ticketing == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.ticketing.tickets.ListByBooking(ctx, bookingID)
+}
+
 // GetByReference fetches a previously created booking using its confirmation reference.
 func (u *BookingUsecase) ListBySchedule(ctx context.Context, scheduleID int64, limit, offset int) ([]domain.Booking, error) {
 	if scheduleID <= 0 {
@@ -399,11 +642,3 @@ func (u *BookingUsecase) GetByReference(ctx context.Context, reference string) (
 	defer cancel()
 	return u.bookings.GetByReference(ctx, ref)
 }
-
-func defaultBookingReference() string {
-	buf := make([]byte, 5)
-	if _, err := rand.Read(buf); err == nil {
-		return fmt.Sprintf("BK-%s", strings.ToUpper(hex.EncodeToString(buf)))
-	}
-	return fmt.Sprintf("BK-%d", time.Now().UnixNano())
-}
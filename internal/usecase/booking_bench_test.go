@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// roundTripLatency approximates one database round trip.
+const roundTripLatency = 20 * time.Microsecond
+
+type roundTrips struct{ n int }
+
+func (r *roundTrips) hit() {
+	r.n++
+	time.Sleep(roundTripLatency)
+}
+
+type slowBookingRepo struct {
+	*mockBookingRepo
+	trips *roundTrips
+}
+
+func (s slowBookingRepo) CountBySchedule(ctx context.Context, id int64) (int, error) {
+	s.trips.hit()
+	return s.mockBookingRepo.CountBySchedule(ctx, id)
+}
+
+type slowScheduleRepo struct {
+	*mockScheduleRepo
+	trips *roundTrips
+}
+
+func (s slowScheduleRepo) List(ctx context.Context, routeCode string, limit, offset int) ([]domain.FlightSchedule, error) {
+	s.trips.hit()
+	return s.mockScheduleRepo.List(ctx, routeCode, limit, offset)
+}
+
+type slowRouteRepo struct {
+	*mockRouteRepo
+	trips *roundTrips
+}
+
+func (s slowRouteRepo) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Route, error) {
+	s.trips.hit()
+	return s.mockRouteRepo.List(ctx, limit, offset, includeArchived)
+}
+
+type slowAirplaneRepo struct {
+	*mockAirplaneRepo
+	trips *roundTrips
+}
+
+func (s slowAirplaneRepo) GetByCode(ctx context.Context, code string) (*domain.Airplane, error) {
+	s.trips.hit()
+	return s.mockAirplaneRepo.GetByCode(ctx, code)
+}
+
+// setAvailability models the single-statement query: the whole search costs one round trip.
+type setAvailability struct {
+	domain.AvailabilityRepository
+	trips *roundTrips
+}
+
+func (s setAvailability) Search(ctx context.Context, q domain.AvailabilityQuery) ([]domain.FlightAvailability, error) {
+	s.trips.hit()
+	return s.AvailabilityRepository.Search(ctx, q)
+}
+
+func (s setAvailability) RouteExists(ctx context.Context, origin, destination string) (bool, error) {
+	s.trips.hit()
+	return s.AvailabilityRepository.RouteExists(ctx, origin, destination)
+}
+
+// benchNetwork builds a hub network: every spoke connects to HUB and back, with
+// several departures a day on each route.
+func benchNetwork() (*mockBookingRepo, *mockScheduleRepo, *mockRouteRepo, *mockAirplaneRepo) {
+	routes := &mockRouteRepo{routes: map[string]*domain.Route{}}
+	schedules := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{}}
+	planes := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 180}}}
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var id int64
+	addRoute := func(origin, destination string) {
+		code := origin + "-" + destination
+		routes.routes[code] = &domain.Route{Code: code, OriginCode: origin, DestinationCode: destination}
+		for h := 6; h < 22; h += 3 {
+			id++
+			dep := base.Add(time.Duration(h) * time.Hour)
+			schedules.schedules[id] = &domain.FlightSchedule{
+				ID: id, RouteCode: code, AirplaneCode: "A320",
+				DepartureDate: "2025-01-01", DepartureAt: dep, ArrivalAt: dep.Add(90 * time.Minute),
+			}
+		}
+	}
+	for i := 0; i < 8; i++ {
+		spoke := fmt.Sprintf("S%02d", i)
+		addRoute(spoke, "HUB")
+		addRoute("HUB", spoke)
+	}
+	addRoute("S00", "S01")
+	return &mockBookingRepo{count: 12}, schedules, routes, planes
+}
+
+func benchUsecases() (repository, setBased *BookingUsecase, repoTrips, setTrips *roundTrips) {
+	bookings, schedules, routes, planes := benchNetwork()
+	repoTrips, setTrips = &roundTrips{}, &roundTrips{}
+	repository = NewBookingUsecase(
+		slowBookingRepo{bookings, repoTrips},
+		slowScheduleRepo{schedules, repoTrips},
+		slowRouteRepo{routes, repoTrips},
+		slowAirplaneRepo{planes, repoTrips},
+	)
+	setBased = NewBookingUsecase(bookings, schedules, routes, planes).
+		WithAvailability(setAvailability{NewRepositoryAvailability(bookings, schedules, routes, planes, nil), setTrips})
+	return repository, setBased, repoTrips, setTrips
+}
+
+func benchmarkSearch(b *testing.B, search func(uc *BookingUsecase) error) {
+	repository, setBased, repoTrips, setTrips := benchUsecases()
+	for _, bc := range []struct {
+		name  string
+		uc    *BookingUsecase
+		trips *roundTrips
+	}{
+		{"repository", repository, repoTrips},
+		{"set-based", setBased, setTrips},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			bc.trips.n = 0
+			for i := 0; i < b.N; i++ {
+				if err := search(bc.uc); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(bc.trips.n)/float64(b.N), "roundtrips/op")
+		})
+	}
+}
+
+func BenchmarkSearchDirectFlights(b *testing.B) {
+	benchmarkSearch(b, func(uc *BookingUsecase) error {
+		_, err := uc.SearchDirectFlights(context.Background(), "S00", "S01", "2025-01-01")
+		return err
+	})
+}
+
+func BenchmarkSearchTransitFlights(b *testing.B) {
+	benchmarkSearch(b, func(uc *BookingUsecase) error {
+		_, err := uc.SearchTransitFlights(context.Background(), "S00", "S05", "2025-01-01")
+		return err
+	})
+}
@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
 )
@@ -11,6 +13,8 @@ import (
 type mockBookingRepo struct {
 	bookings map[string]*domain.Booking
 	count    int
+	// affected lists the references flagged by a flight cancellation.
+	affected []string
 }
 
 func (m *mockBookingRepo) Create(ctx context.Context, booking *domain.Booking) error {
@@ -32,10 +36,33 @@ func (m *mockBookingRepo) GetByReference(ctx context.Context, reference string)
 	return booking, nil
 }
 
+func (m *mockBookingRepo) UpdateStatus(ctx context.Context, id int64, from, to string) error {
+	for _, b := range m.bookings {
+		if b.ID == id {
+			if b.Status != from {
+				return domain.ErrInvalidBookingTransition
+			}
+			b.Status = to
+			return nil
+		}
+	}
+	return domain.ErrBookingNotFound
+}
+
 func (m *mockBookingRepo) CountBySchedule(ctx context.Context, scheduleID int64) (int, error) {
 	return m.count, nil
 }
 
+func (m *mockBookingRepo) ListAffected(ctx context.Context, limit, offset int) ([]domain.Booking, error) {
+	var result []domain.Booking
+	for _, ref := range m.affected {
+		if b, ok := m.bookings[ref]; ok && b.Status != domain.BookingStatusCancelled {
+			result = append(result, *b)
+		}
+	}
+	return result, nil
+}
+
 func (m *mockBookingRepo) ListBySchedule(ctx context.Context, scheduleID int64, limit, offset int) ([]domain.Booking, error) {
 	if m.bookings == nil {
 		return []domain.Booking{}, nil
@@ -103,12 +130,52 @@ func (m *mockScheduleRepo) List(ctx context.Context, routeCode string, limit, of
 	return result, nil
 }
 
-func (m *mockScheduleRepo) Delete(ctx context.Context, id int64) error {
+func (m *mockScheduleRepo) ListByAirplane(ctx context.Context, airplaneCode string, limit, offset int) ([]domain.FlightSchedule, error) {
+	var result []domain.FlightSchedule
+	for _, schedule := range m.schedules {
+		if schedule.AirplaneCode == airplaneCode {
+			result = append(result, *schedule)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockScheduleRepo) ListByAirport(ctx context.Context, airportCode string, arrivals bool, from, to time.Time, limit, offset int) ([]domain.BoardFlight, error) {
+	return nil, nil
+}
+
+func (m *mockScheduleRepo) Update(ctx context.Context, schedule *domain.FlightSchedule) ([]domain.SeatMove, error) {
+	if _, ok := m.schedules[schedule.ID]; !ok {
+		return nil, domain.ErrScheduleNotFound
+	}
+	m.schedules[schedule.ID] = schedule
+	return nil, nil
+}
+
+func (m *mockScheduleRepo) Cancel(ctx context.Context, id int64, reason string, at time.Time) (int, error) {
+	schedule, ok := m.schedules[id]
+	if !ok {
+		return 0, domain.ErrScheduleNotFound
+	}
+	schedule.Status = domain.ScheduleStatusCancelled
+	schedule.CancelReason = reason
+	return 0, nil
+}
+
+func (m *mockScheduleRepo) Delay(ctx context.Context, delays []domain.ScheduleDelay, notices []domain.Notification) error {
+	return nil
+}
+
+func (m *mockScheduleRepo) SetGates(ctx context.Context, id int64, departureGate, arrivalGate string) error {
+	return nil
+}
+
+func (m *mockScheduleRepo) Delete(ctx context.Context, id int64, cascade *domain.Dependents) (domain.Dependents, error) {
 	if m.schedules == nil {
-		return domain.ErrScheduleNotFound
+		return domain.Dependents{}, domain.ErrScheduleNotFound
 	}
 	delete(m.schedules, id)
-	return nil
+	return domain.Dependents{}, nil
 }
 
 type mockRouteRepo struct {
@@ -134,7 +201,9 @@ func (m *mockRouteRepo) GetByCode(ctx context.Context, code string) (*domain.Rou
 	return route, nil
 }
 
-func (m *mockRouteRepo) List(ctx context.Context, limit, offset int) ([]domain.Route, error) {
+func (m *mockRouteRepo) SetArchived(ctx context.Context, code string, archived bool) error { return nil }
+
+func (m *mockRouteRepo) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Route, error) {
 	if m.routes == nil {
 		return []domain.Route{}, nil
 	}
@@ -146,14 +215,32 @@ func (m *mockRouteRepo) List(ctx context.Context, limit, offset int) ([]domain.R
 	return result, nil
 }
 
-func (m *mockRouteRepo) Delete(ctx context.Context, code string) error {
-	if m.routes == nil {
+func (m *mockRouteRepo) SetBlockTime(ctx context.Context, code string, minutes int) error {
+	route, exists := m.routes[code]
+	if !exists {
 		return domain.ErrRouteNotFound
 	}
-	delete(m.routes, code)
+	route.BlockMinutes = minutes
 	return nil
 }
 
+func (m *mockRouteRepo) Update(ctx context.Context, r *domain.Route, from time.Time, force bool) ([]domain.BookedFlight, error) {
+	route, exists := m.routes[r.Code]
+	if !exists {
+		return nil, domain.ErrRouteNotFound
+	}
+	route.OriginCode, route.DestinationCode = r.OriginCode, r.DestinationCode
+	return nil, nil
+}
+
+func (m *mockRouteRepo) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	if m.routes == nil {
+		return domain.Dependents{}, domain.ErrRouteNotFound
+	}
+	delete(m.routes, code)
+	return domain.Dependents{}, nil
+}
+
 type mockAirplaneRepo struct {
 	airplanes map[string]*domain.Airplane
 }
@@ -177,7 +264,9 @@ func (m *mockAirplaneRepo) GetByCode(ctx context.Context, code string) (*domain.
 	return plane, nil
 }
 
-func (m *mockAirplaneRepo) List(ctx context.Context, limit, offset int) ([]domain.Airplane, error) {
+func (m *mockAirplaneRepo) SetArchived(ctx context.Context, code string, archived bool) error { return nil }
+
+func (m *mockAirplaneRepo) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airplane, error) {
 	if m.airplanes == nil {
 		return []domain.Airplane{}, nil
 	}
@@ -189,24 +278,24 @@ func (m *mockAirplaneRepo) List(ctx context.Context, limit, offset int) ([]domai
 	return result, nil
 }
 
-func (m *mockAirplaneRepo) UpdateSeats(ctx context.Context, code string, seats int) error {
+func (m *mockAirplaneRepo) UpdateSeats(ctx context.Context, code string, seats int, from time.Time, force bool) (*domain.CapacityChange, error) {
 	if m.airplanes == nil {
-		return domain.ErrAirplaneNotFound
+		return nil, domain.ErrAirplaneNotFound
 	}
 	plane, exists := m.airplanes[code]
 	if !exists {
-		return domain.ErrAirplaneNotFound
+		return nil, domain.ErrAirplaneNotFound
 	}
 	plane.SeatCapacity = seats
-	return nil
+	return &domain.CapacityChange{}, nil
 }
 
-func (m *mockAirplaneRepo) Delete(ctx context.Context, code string) error {
+func (m *mockAirplaneRepo) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
 	if m.airplanes == nil {
-		return domain.ErrAirplaneNotFound
+		return domain.Dependents{}, domain.ErrAirplaneNotFound
 	}
 	delete(m.airplanes, code)
-	return nil
+	return domain.Dependents{}, nil
 }
 
 func TestBookingUsecase_SearchDirectFlights_InvalidParams(t *testing.T) {
@@ -566,25 +655,170 @@ func TestBookingUsecase_GetByReference_Valid(t *testing.T) {
 
 func TestDefaultBookingReference(t *testing.T) {
 	// Test that the default reference generation works
-	ref1 := defaultBookingReference()
-	ref2 := defaultBookingReference()
+	uc := NewBookingUsecase(&mockBookingRepo{}, &mockScheduleRepo{}, &mockRouteRepo{}, &mockAirplaneRepo{})
+	ref1, err1 := uc.generateRef(context.Background())
+	ref2, err2 := uc.generateRef(context.Background())
+	if err1 != nil || err2 != nil {
+		t.Fatalf("generate: %v %v", err1, err2)
+	}
 	
-	// Basic format check
-	if len(ref1) < 6 || len(ref2) < 6 {
-		t.Errorf("generated reference is too short: %s, %s", ref1, ref2)
+	// Basic format check: six-character locators
+	if len(ref1) != 6 || len(ref2) != 6 {
+		t.Errorf("expected six-character references, got %s, %s", ref1, ref2)
 	}
 	
 	if ref1 == ref2 {
 		t.Errorf("expected different references, got the same: %s", ref1)
 	}
 	
-	// Check prefix
-	if !hasPrefix(ref1, "BK-") || !hasPrefix(ref2, "BK-") {
-		t.Errorf("generated references don't have 'BK-' prefix: %s, %s", ref1, ref2)
+	// Check alphabet excludes ambiguous characters
+	if strings.ContainsAny(ref1+ref2, "0O1I") {
+		t.Errorf("generated references contain ambiguous characters: %s, %s", ref1, ref2)
+	}
+}
+
+func TestBookingUsecase_SearchTransitFlights_ConnectionWindow(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatalf("parse %s: %v", s, err)
+		}
+		return v
+	}
+	scheduleRepo := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{
+		// CGK -> SUB lands at 22:00 UTC.
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-01", DepartureAt: at("2025-01-01T20:30:00Z"), ArrivalAt: at("2025-01-01T22:00:00Z")},
+		// Too tight for the 60 minute minimum at SUB.
+		2: {ID: 2, RouteCode: "RT2", AirplaneCode: "B737", DepartureDate: "2025-01-01", DepartureAt: at("2025-01-01T22:40:00Z"), ArrivalAt: at("2025-01-01T23:40:00Z")},
+		// Next-day connection after midnight.
+		3: {ID: 3, RouteCode: "RT2", AirplaneCode: "B737", DepartureDate: "2025-01-02", DepartureAt: at("2025-01-02T06:00:00Z"), ArrivalAt: at("2025-01-02T07:00:00Z")},
+		// Beyond the maximum layover.
+		4: {ID: 4, RouteCode: "RT2", AirplaneCode: "B737", DepartureDate: "2025-01-03", DepartureAt: at("2025-01-03T06:00:00Z"), ArrivalAt: at("2025-01-03T07:00:00Z")},
+		// Departs before the first leg lands.
+		5: {ID: 5, RouteCode: "RT2", AirplaneCode: "B737", DepartureDate: "2025-01-01", DepartureAt: at("2025-01-01T21:00:00Z"), ArrivalAt: at("2025-01-01T22:00:00Z")},
+	}}
+	routeRepo := &mockRouteRepo{routes: map[string]*domain.Route{
+		"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "SUB"},
+		"RT2": {Code: "RT2", OriginCode: "SUB", DestinationCode: "DPS"},
+	}}
+	airplaneRepo := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{
+		"A320": {Code: "A320", SeatCapacity: 150},
+		"B737": {Code: "B737", SeatCapacity: 180},
+	}}
+	airports := &fakeAirportRepo{list: []domain.Airport{{Code: "SUB", City: "Surabaya", MinConnectionMinutes: 60}}}
+
+	uc := NewBookingUsecase(&mockBookingRepo{}, scheduleRepo, routeRepo, airplaneRepo)
+	uc.WithConnectionPolicy(airports, domain.ConnectionPolicy{MinConnection: 30 * time.Minute, MaxLayover: 12 * time.Hour})
+
+	options, err := uc.SearchTransitFlights(context.Background(), "CGK", "DPS", "2025-01-01")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(options) != 1 || options[0].SecondLeg.ScheduleID != 3 {
+		t.Fatalf("expected only the next-day connection, got %+v", options)
+	}
+	if options[0].Layover != 8*time.Hour || options[0].TotalDuration != 10*time.Hour+30*time.Minute {
+		t.Fatalf("unexpected layover %s / journey %s", options[0].Layover, options[0].TotalDuration)
+	}
+
+	// Without the airport override the 40 minute connection satisfies the 30 minute default.
+	airports.list[0].MinConnectionMinutes = 0
+	options, err = uc.SearchTransitFlights(context.Background(), "CGK", "DPS", "2025-01-01")
+	if err != nil || len(options) != 2 {
+		t.Fatalf("expected tight and next-day connections, err=%v options=%+v", err, options)
+	}
+}
+
+type stubAvailability struct {
+	rows     []domain.FlightAvailability
+	exists   bool
+	searches []domain.AvailabilityQuery
+}
+
+func (s *stubAvailability) Search(ctx context.Context, q domain.AvailabilityQuery) ([]domain.FlightAvailability, error) {
+	s.searches = append(s.searches, q)
+	return s.rows, nil
+}
+
+func (s *stubAvailability) RouteExists(ctx context.Context, origin, destination string) (bool, error) {
+	return s.exists, nil
+}
+
+func TestBookingUsecase_SearchDirectFlights_UsesAvailability(t *testing.T) {
+	avail := &stubAvailability{rows: []domain.FlightAvailability{
+		{Schedule: domain.FlightSchedule{ID: 1, RouteCode: "RT1"}, OriginCode: "CGK", DestinationCode: "DPS", SeatCapacity: 2, Booked: 2},
+		{Schedule: domain.FlightSchedule{ID: 2, RouteCode: "RT1"}, OriginCode: "CGK", DestinationCode: "DPS", SeatCapacity: 2, Booked: 1},
+	}}
+	uc := NewBookingUsecase(&mockBookingRepo{}, &mockScheduleRepo{}, &mockRouteRepo{}, &mockAirplaneRepo{}).WithAvailability(avail)
+
+	options, err := uc.SearchDirectFlights(context.Background(), "cgk", "dps", "2025-01-01")
+	if err != nil || len(options) != 1 || options[0].ScheduleID != 2 || options[0].SeatsAvailable != 1 {
+		t.Fatalf("expected only the schedule with seats, err=%v options=%+v", err, options)
+	}
+	want := domain.AvailabilityQuery{OriginCode: "CGK", DestinationCode: "DPS", DepartureDate: "2025-01-01"}
+	if len(avail.searches) != 1 || avail.searches[0] != want {
+		t.Fatalf("expected one availability query %+v, got %+v", want, avail.searches)
+	}
+
+	avail.rows = nil
+	if _, err := uc.SearchDirectFlights(context.Background(), "CGK", "DPS", ""); err != domain.ErrRouteNotFound {
+		t.Fatalf("want route not found, got %v", err)
+	}
+	avail.exists = true
+	if options, err := uc.SearchDirectFlights(context.Background(), "CGK", "DPS", ""); err != nil || len(options) != 0 {
+		t.Fatalf("expected no options on an existing route, err=%v options=%+v", err, options)
 	}
 }
 
-// Helper function to check if a string starts with a prefix
-func hasPrefix(s, prefix string) bool {
-	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
-}
\ No newline at end of file
+func TestBookingUsecase_Create_OverbookedAfterForcedShrink(t *testing.T) {
+	// A forced shrink to 2 seats left flight 1 overbooked on its old 4-seat
+	// inventory with 3 sold, so one seat is still for sale.
+	schedules := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-01"}}}
+	airplanes := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 2}}}
+	avail := &stubAvailability{rows: []domain.FlightAvailability{
+		{Schedule: *schedules.schedules[1], OriginCode: "CGK", DestinationCode: "DPS", SeatCapacity: 4, Booked: 3},
+	}}
+	inventory := &fakeInventoryRepo{items: map[int64]domain.SeatInventory{1: {ScheduleID: 1, Capacity: 4, Sold: 3}}}
+	bookings := &mockBookingRepo{count: 3}
+	uc := NewBookingUsecase(bookings, schedules, &mockRouteRepo{}, airplanes).WithAvailability(avail).WithInventory(inventory)
+
+	options, err := uc.SearchDirectFlights(context.Background(), "CGK", "DPS", "2025-01-01")
+	if err != nil || len(options) != 1 || options[0].SeatsAvailable != 1 {
+		t.Fatalf("expected one seat left on the overbooked flight, err=%v options=%+v", err, options)
+	}
+	booking, err := uc.Create(context.Background(), 1, "Passenger Name")
+	if err != nil || booking.SeatNumber != 4 {
+		t.Fatalf("expected the last inventory seat to be bookable, err=%v booking=%+v", err, booking)
+	}
+
+	bookings.count = 4
+	if _, err := uc.Create(context.Background(), 1, "Passenger Name"); err != domain.ErrFlightFull {
+		t.Fatalf("want flight full once the inventory is sold out, got %v", err)
+	}
+}
+
+func TestBookingUsecase_HoldConfirmCancel(t *testing.T) {
+	bookings := &mockBookingRepo{}
+	schedules := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-01"}}}
+	planes := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 10}}}
+	uc := NewBookingUsecase(bookings, schedules, &mockRouteRepo{}, planes)
+
+	held, err := uc.Hold(context.Background(), 1, "Alice")
+	if err != nil || held.Status != domain.BookingStatusHeld {
+		t.Fatalf("hold: err=%v booking=%+v", err, held)
+	}
+	confirmed, err := uc.Confirm(context.Background(), held.Reference)
+	if err != nil || confirmed.Status != domain.BookingStatusConfirmed {
+		t.Fatalf("confirm: err=%v booking=%+v", err, confirmed)
+	}
+	if _, err := uc.Confirm(context.Background(), held.Reference); err != domain.ErrInvalidBookingTransition {
+		t.Fatalf("want invalid transition, got %v", err)
+	}
+	cancelled, err := uc.Cancel(context.Background(), held.Reference)
+	if err != nil || cancelled.Status != domain.BookingStatusCancelled {
+		t.Fatalf("cancel: err=%v booking=%+v", err, cancelled)
+	}
+	if _, err := uc.Cancel(context.Background(), held.Reference); err != domain.ErrInvalidBookingTransition {
+		t.Fatalf("want invalid transition on cancelled booking, got %v", err)
+	}
+}
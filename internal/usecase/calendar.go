@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// CalendarDay summarises direct availability on one departure date.
+type CalendarDay struct {
+	Date         string
+	Flights      int          // Flights with at least one seat left
+	SeatsLeft    int          // Seats left across those flights
+	CheapestFare domain.Money // Lowest one-way fare among them; zero when none is priced
+}
+
+// Calendar returns one entry per day of a YYYY-MM month with the number of direct
+// flights that still have seats, the seats left and the cheapest fare. The whole
+// month is read with a single availability query.
+func (u *BookingUsecase) Calendar(ctx context.Context, originCode, destinationCode, month string) ([]CalendarDay, error) {
+	first, err := time.Parse("2006-01", strings.TrimSpace(month))
+	if err != nil {
+		return nil, domain.ErrInvalidMonth
+	}
+	origin, destination, _, err := normalizeSearch(originCode, destinationCode, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	last := first.AddDate(0, 1, -1)
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	rows, err := u.availability.Search(ctx, domain.AvailabilityQuery{
+		OriginCode:      origin,
+		DestinationCode: destination,
+		DateFrom:        first.Format("2006-01-02"),
+		DateTo:          last.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		exists, err := u.availability.RouteExists(ctx, origin, destination)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, domain.ErrRouteNotFound
+		}
+	}
+
+	days := make([]CalendarDay, 0, last.Day())
+	index := make(map[string]int, last.Day())
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		index[date] = len(days)
+		days = append(days, CalendarDay{Date: date})
+	}
+	for _, row := range rows {
+		i, ok := index[row.Schedule.DepartureDate]
+		if !ok || row.SeatsAvailable() <= 0 {
+			continue
+		}
+		day := &days[i]
+		day.Flights++
+		day.SeatsLeft += row.SeatsAvailable()
+		if row.Fare != nil && (day.CheapestFare.IsZero() || (row.Fare.OneWay.Currency == day.CheapestFare.Currency && row.Fare.OneWay.Amount < day.CheapestFare.Amount)) {
+			day.CheapestFare = row.Fare.OneWay
+		}
+	}
+	return days, nil
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func TestBookingUsecase_Calendar(t *testing.T) {
+	cheap := &domain.Fare{OneWay: domain.Money{Amount: 80000, Currency: "USD"}}
+	dear := &domain.Fare{OneWay: domain.Money{Amount: 120000, Currency: "USD"}}
+	avail := &stubAvailability{rows: []domain.FlightAvailability{
+		{Schedule: domain.FlightSchedule{ID: 1, DepartureDate: "2025-02-03"}, SeatCapacity: 10, Booked: 4, Fare: dear},
+		{Schedule: domain.FlightSchedule{ID: 2, DepartureDate: "2025-02-03"}, SeatCapacity: 5, Booked: 0, Fare: cheap},
+		{Schedule: domain.FlightSchedule{ID: 3, DepartureDate: "2025-02-10"}, SeatCapacity: 5, Booked: 5, Fare: cheap},
+		{Schedule: domain.FlightSchedule{ID: 4, DepartureDate: "2025-02-28"}, SeatCapacity: 3, Booked: 1},
+	}}
+	uc := NewBookingUsecase(&mockBookingRepo{}, &mockScheduleRepo{}, &mockRouteRepo{}, &mockAirplaneRepo{}).WithAvailability(avail)
+
+	days, err := uc.Calendar(context.Background(), "cgk", "dps", "2025-02")
+	if err != nil || len(days) != 28 {
+		t.Fatalf("expected 28 days, err=%v len=%d", err, len(days))
+	}
+	want := domain.AvailabilityQuery{OriginCode: "CGK", DestinationCode: "DPS", DateFrom: "2025-02-01", DateTo: "2025-02-28"}
+	if len(avail.searches) != 1 || avail.searches[0] != want {
+		t.Fatalf("expected one availability query %+v, got %+v", want, avail.searches)
+	}
+	if d := days[2]; d.Date != "2025-02-03" || d.Flights != 2 || d.SeatsLeft != 11 || d.CheapestFare != cheap.OneWay {
+		t.Fatalf("unexpected day %+v", d)
+	}
+	if d := days[9]; d.Flights != 0 || !d.CheapestFare.IsZero() {
+		t.Fatalf("full flights should not count, got %+v", d)
+	}
+	if d := days[27]; d.Flights != 1 || d.SeatsLeft != 2 || !d.CheapestFare.IsZero() {
+		t.Fatalf("unpriced flight should count without a fare, got %+v", d)
+	}
+
+	if _, err := uc.Calendar(context.Background(), "CGK", "DPS", "2025-13"); err != domain.ErrInvalidMonth {
+		t.Fatalf("want invalid month, got %v", err)
+	}
+	avail.rows = nil
+	if _, err := uc.Calendar(context.Background(), "CGK", "DPS", "2025-02"); err != domain.ErrRouteNotFound {
+		t.Fatalf("want route not found, got %v", err)
+	}
+}
+
+func TestBookingUsecase_FindDirectFlights_Flex(t *testing.T) {
+	fare := &domain.Fare{OneWay: domain.Money{Amount: 50000, Currency: "IDR"}}
+	avail := &stubAvailability{rows: []domain.FlightAvailability{
+		{Schedule: domain.FlightSchedule{ID: 1, DepartureDate: "2025-03-13"}, SeatCapacity: 2, Fare: fare},
+	}}
+	uc := NewBookingUsecase(&mockBookingRepo{}, &mockScheduleRepo{}, &mockRouteRepo{}, &mockAirplaneRepo{}).WithAvailability(avail)
+
+	options, err := uc.FindDirectFlights(context.Background(), SearchQuery{OriginCode: "CGK", DestinationCode: "DPS", DepartureDate: "2025-03-15", FlexDays: 3})
+	if err != nil || len(options) != 1 || options[0].Fare != fare {
+		t.Fatalf("expected the priced option, err=%v options=%+v", err, options)
+	}
+	want := domain.AvailabilityQuery{OriginCode: "CGK", DestinationCode: "DPS", DateFrom: "2025-03-12", DateTo: "2025-03-18"}
+	if avail.searches[0] != want {
+		t.Fatalf("expected range query %+v, got %+v", want, avail.searches[0])
+	}
+
+	for _, flex := range []int{-1, MaxFlexDays + 1} {
+		if _, err := uc.FindDirectFlights(context.Background(), SearchQuery{OriginCode: "CGK", DestinationCode: "DPS", DepartureDate: "2025-03-15", FlexDays: flex}); err != domain.ErrInvalidFlexDays {
+			t.Fatalf("flex %d: want invalid flex days, got %v", flex, err)
+		}
+	}
+	if _, err := uc.FindDirectFlights(context.Background(), SearchQuery{OriginCode: "CGK", DestinationCode: "DPS", FlexDays: 2}); err != domain.ErrInvalidFlexDays {
+		t.Fatalf("want invalid flex days without a date, got %v", err)
+	}
+}
+
+func TestTotalFare(t *testing.T) {
+	a := &domain.Fare{OneWay: domain.Money{Amount: 100, Currency: "USD"}}
+	b := &domain.Fare{OneWay: domain.Money{Amount: 250, Currency: "USD"}}
+	c := &domain.Fare{OneWay: domain.Money{Amount: 250, Currency: "EUR"}}
+	if got := totalFare(a, b); got != (domain.Money{Amount: 350, Currency: "USD"}) {
+		t.Fatalf("unexpected total %v", got)
+	}
+	if got := totalFare(a, nil); !got.IsZero() {
+		t.Fatalf("unpriced leg should zero the total, got %v", got)
+	}
+	if got := totalFare(a, c); !got.IsZero() {
+		t.Fatalf("mixed currencies should zero the total, got %v", got)
+	}
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// Cancel cancels a flight operationally. The schedule and its bookings are
+// kept: the bookings are flagged as affected and returned as the flight's
+// disruption, with later flights on its route that have seats, for agents to
+// rebook or refund. It fails with domain.ErrScheduleCancelled when the flight
+// already is.
+func (u *ScheduleUsecase) Cancel(ctx context.Context, id int64, reason string) (*domain.Reaccommodation, error) {
+	if id <= 0 {
+		return nil, domain.ErrInvalidScheduleID
+	}
+	reason, err := domain.NormalizeCancelReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	if _, err := u.schedules.Cancel(ctx, id, reason, u.now()); err != nil {
+		return nil, err
+	}
+	sched, err := u.schedules.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if u.bookings == nil {
+		return &domain.Reaccommodation{Schedule: *sched}, nil
+	}
+	affected, err := activeBookings(ctx, u.bookings, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.disruption(ctx, *sched, affected)
+}
+
+// Disruptions lists, per cancelled flight, the affected bookings agents have
+// not dealt with yet, with later flights on its route that have seats. A
+// booking leaves the list once it is cancelled, after a rebooking or a refund.
+func (u *ScheduleUsecase) Disruptions(ctx context.Context) ([]domain.Reaccommodation, error) {
+	if u.bookings == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	var affected []domain.Booking
+	for offset := 0; ; offset += pageSize {
+		page, err := u.bookings.ListAffected(ctx, pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		affected = append(affected, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+	// Affected bookings come grouped by schedule.
+	var plans []domain.Reaccommodation
+	for start := 0; start < len(affected); {
+		end := start + 1
+		for end < len(affected) && affected[end].ScheduleID == affected[start].ScheduleID {
+			end++
+		}
+		sched, err := u.schedules.GetByID(ctx, affected[start].ScheduleID)
+		if err != nil {
+			return nil, err
+		}
+		plan, err := u.disruption(ctx, *sched, affected[start:end])
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, *plan)
+		start = end
+	}
+	return plans, nil
+}
+
+// disruption pairs the affected bookings of a cancelled flight with the later
+// flights on its route that could take them.
+func (u *ScheduleUsecase) disruption(ctx context.Context, sched domain.FlightSchedule, affected []domain.Booking) (*domain.Reaccommodation, error) {
+	later, err := u.schedules.List(ctx, sched.RouteCode, pageSize, 0)
+	if err != nil {
+		return nil, err
+	}
+	offers, err := seatOffers(ctx, u.bookings, u.airplanes, sched, later)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Reaccommodation{Schedule: sched, Displaced: affected, Alternatives: offers}, nil
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func TestScheduleUsecase_CancelAndDisruptions(t *testing.T) {
+	t0 := time.Date(2025, 1, 2, 1, 0, 0, 0, time.UTC)
+	repo := &fakeScheduleRepo{items: []domain.FlightSchedule{
+		{ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: t0},
+		{ID: 2, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-03", DepartureAt: t0.AddDate(0, 0, 1)},
+		{ID: 3, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-04", DepartureAt: t0.AddDate(0, 0, 2), Status: domain.ScheduleStatusCancelled},
+	}}
+	planes := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 180}}}
+	bookings := &mockBookingRepo{count: 100, bookings: map[string]*domain.Booking{
+		"AAAAAA": {ID: 1, Reference: "AAAAAA", ScheduleID: 1, SeatNumber: 1, Status: domain.BookingStatusConfirmed},
+		"BBBBBB": {ID: 2, Reference: "BBBBBB", ScheduleID: 1, SeatNumber: 2, Status: domain.BookingStatusHeld},
+		"XXXXXX": {ID: 3, Reference: "XXXXXX", ScheduleID: 1, SeatNumber: 3, Status: domain.BookingStatusCancelled},
+	}}
+	uc := NewScheduleUsecase(repo, &fakeRouteRepoSched{}, planes, newSchedAirports()).WithBookings(bookings)
+
+	plan, err := uc.Cancel(context.Background(), 1, "  weather  ")
+	if err != nil || !plan.Schedule.Cancelled() || plan.Schedule.CancelReason != "weather" {
+		t.Fatalf("unexpected cancellation %+v err=%v", plan, err)
+	}
+	if len(plan.Displaced) != 2 {
+		t.Fatalf("want both active bookings affected, got %+v", plan.Displaced)
+	}
+	// The cancelled flight on day three is not offered.
+	if len(plan.Alternatives) != 1 || plan.Alternatives[0].Schedule.ID != 2 || plan.Alternatives[0].SeatsLeft != 80 {
+		t.Fatalf("unexpected alternatives %+v", plan.Alternatives)
+	}
+	if _, err := uc.Cancel(context.Background(), 1, "weather"); err != domain.ErrScheduleCancelled {
+		t.Fatalf("want schedule cancelled, got %v", err)
+	}
+	if _, err := uc.Cancel(context.Background(), 2, "  "); err != domain.ErrInvalidCancelReason {
+		t.Fatalf("want invalid reason, got %v", err)
+	}
+	if _, err := uc.SwapAirplane(context.Background(), 1, "A320"); err != domain.ErrScheduleCancelled {
+		t.Fatalf("want swap refused on a cancelled flight, got %v", err)
+	}
+
+	// An agent refunded AAAAAA; only BBBBBB is still to be handled.
+	bookings.affected = []string{"AAAAAA", "BBBBBB"}
+	bookings.bookings["AAAAAA"].Status = domain.BookingStatusCancelled
+	plans, err := uc.Disruptions(context.Background())
+	if err != nil || len(plans) != 1 || plans[0].Schedule.ID != 1 {
+		t.Fatalf("unexpected disruptions %+v err=%v", plans, err)
+	}
+	if len(plans[0].Displaced) != 1 || plans[0].Displaced[0].Reference != "BBBBBB" || len(plans[0].Alternatives) != 1 {
+		t.Fatalf("unexpected disruption %+v", plans[0])
+	}
+}
+
+func TestBookingUsecase_RefusesCancelledSchedule(t *testing.T) {
+	schedules := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", Status: domain.ScheduleStatusCancelled},
+	}}
+	planes := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 180}}}
+	uc := NewBookingUsecase(&mockBookingRepo{}, schedules, &mockRouteRepo{}, planes)
+	if _, err := uc.Create(context.Background(), 1, "Alice"); err != domain.ErrScheduleCancelled {
+		t.Fatalf("want schedule cancelled, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// DelayImpact reports a recorded delay: the flights it delays, the delayed
+// one first and then the airplane's later flights it holds up, the trip
+// connections that no longer make their minimum connection time and the
+// notifications queued for passengers.
+type DelayImpact struct {
+	Delayed       []domain.FlightSchedule
+	Broken        []domain.BrokenConnection
+	Notifications []domain.Notification
+}
+
+// WithTrips lets delays find the connecting passengers they strand.
+func (u *ScheduleUsecase) WithTrips(t domain.TripRepository) *ScheduleUsecase {
+	u.trips = t
+	return u
+}
+
+// WithMinConnection sets the minimum connection time at airports without
+// their own.
+func (u *ScheduleUsecase) WithMinConnection(d time.Duration) *ScheduleUsecase {
+	u.minConnection = d
+	return u
+}
+
+// Delay records that a flight is expected minutes later than planned; 0 puts
+// it back on time. The delay carries over to the airplane's later flights that
+// can no longer keep the minimum turnaround; lowering it lowers or clears the
+// knock-on delays it caused. Passengers on every flight whose
+// delay changed are notified, as are those whose trip connection no longer
+// makes the minimum connection time. It fails with domain.ErrScheduleCancelled
+// on a cancelled flight, and with domain.ErrScheduleChanged when it or one of
+// the later flights is cancelled or removed meanwhile.
+func (u *ScheduleUsecase) Delay(ctx context.Context, id int64, minutes int, reason string) (*DelayImpact, error) {
+	if id <= 0 {
+		return nil, domain.ErrInvalidScheduleID
+	}
+	delay, err := domain.NormalizeDelay(domain.ScheduleDelay{ScheduleID: id, Minutes: minutes, Reason: reason})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	sched, err := u.schedules.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sched.Cancelled() {
+		return nil, domain.ErrScheduleCancelled
+	}
+	legs, err := u.guard.rotationLegs(ctx, sched.AirplaneCode, nil)
+	if err != nil {
+		return nil, err
+	}
+	flights := map[int64]domain.FlightSchedule{sched.ID: *sched}
+	for _, leg := range legs {
+		flights[leg.Schedule.ID] = leg.Schedule
+	}
+	delays := append([]domain.ScheduleDelay{delay}, domain.PropagateDelay(legs, id, delay.Minutes, u.guard.minTurnaround)...)
+
+	impact := &DelayImpact{}
+	var changed []domain.FlightSchedule
+	for _, d := range delays {
+		f := flights[d.ScheduleID]
+		previous := f.DelayMinutes
+		f.DelayMinutes, f.DelayReason = d.Minutes, d.Reason
+		flights[f.ID] = f
+		impact.Delayed = append(impact.Delayed, f)
+		if f.DelayMinutes != previous {
+			changed = append(changed, f)
+		}
+	}
+	if impact.Broken, err = u.brokenConnections(ctx, impact.Delayed, flights); err != nil {
+		return nil, err
+	}
+	if impact.Notifications, err = u.delayNotices(ctx, changed, impact.Broken); err != nil {
+		return nil, err
+	}
+	if err := u.schedules.Delay(ctx, delays, impact.Notifications); err != nil {
+		return nil, err
+	}
+	return impact, nil
+}
+
+// brokenConnections finds the connections of connecting trips out of the
+// delayed flights whose layover, with both flights at their estimated times,
+// falls below the minimum connection time. flights holds the delayed flights with their new
+// delays; other onward flights are loaded as stored.
+func (u *ScheduleUsecase) brokenConnections(ctx context.Context, delayed []domain.FlightSchedule, flights map[int64]domain.FlightSchedule) ([]domain.BrokenConnection, error) {
+	if u.trips == nil {
+		return nil, nil
+	}
+	policy := domain.ConnectionPolicy{MinConnection: u.minConnection}
+	var broken []domain.BrokenConnection
+	for _, arriving := range delayed {
+		if arriving.ArrivalAt.IsZero() {
+			continue
+		}
+		conns, err := u.trips.Connections(ctx, arriving.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range conns {
+			departing, ok := flights[c.Onward.ScheduleID]
+			if !ok {
+				s, err := u.schedules.GetByID(ctx, c.Onward.ScheduleID)
+				if err != nil {
+					return nil, err
+				}
+				departing = *s
+				flights[s.ID] = departing
+			}
+			// Bookings on cancelled flights are handled as disruptions.
+			if departing.Cancelled() {
+				continue
+			}
+			minimum, err := minimumConnection(ctx, u.airports, policy, c.AirportCode)
+			if err != nil {
+				return nil, err
+			}
+			layover := departing.EstimatedDeparture().Sub(arriving.EstimatedArrival())
+			if layover < minimum {
+				broken = append(broken, domain.BrokenConnection{Connection: c, Arriving: arriving, Departing: departing, Layover: layover, Minimum: minimum})
+			}
+		}
+	}
+	return broken, nil
+}
+
+// delayNotices tells the passengers of every flight whose delay changed and
+// of every broken connection.
+func (u *ScheduleUsecase) delayNotices(ctx context.Context, changed []domain.FlightSchedule, broken []domain.BrokenConnection) ([]domain.Notification, error) {
+	var notices []domain.Notification
+	if u.bookings != nil {
+		for _, f := range changed {
+			active, err := activeBookings(ctx, u.bookings, f.ID)
+			if err != nil {
+				return nil, err
+			}
+			msg := fmt.Sprintf("%s on %s (schedule %d) is back on time, departing %s", f.RouteCode, f.DepartureDate, f.ID, f.LocalEstimatedDeparture().Format("2006-01-02 15:04 MST"))
+			if f.DelayMinutes > 0 {
+				msg = fmt.Sprintf("%s on %s (schedule %d) is delayed %d min and now departs %s: %s", f.RouteCode, f.DepartureDate, f.ID, f.DelayMinutes, f.LocalEstimatedDeparture().Format("2006-01-02 15:04 MST"), f.DelayReason)
+			}
+			for _, b := range active {
+				notices = append(notices, domain.Notification{BookingID: b.ID, Kind: domain.NotificationDelay, Message: msg})
+			}
+		}
+	}
+	for _, c := range broken {
+		msg := fmt.Sprintf("your connection at %s from schedule %d to schedule %d (booking %s) now leaves %d min against a %d min minimum; please contact us to rebook", c.AirportCode, c.Arriving.ID, c.Departing.ID, c.Onward.Reference, int(c.Layover/time.Minute), int(c.Minimum/time.Minute))
+		notices = append(notices, domain.Notification{BookingID: c.Inbound.ID, Kind: domain.NotificationMissedConnection, Message: msg})
+	}
+	return notices, nil
+}
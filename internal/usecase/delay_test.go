@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func TestScheduleUsecase_Delay(t *testing.T) {
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	repo := &fakeScheduleRepo{items: []domain.FlightSchedule{
+		{ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: day.Add(8 * time.Hour), ArrivalAt: day.Add(10 * time.Hour)},
+		{ID: 2, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: day.Add(10*time.Hour + 45*time.Minute), ArrivalAt: day.Add(12*time.Hour + 45*time.Minute)},
+		{ID: 5, RouteCode: "RT1", AirplaneCode: "B737", DepartureDate: "2025-01-02", DepartureAt: day.Add(11 * time.Hour), ArrivalAt: day.Add(13 * time.Hour)},
+	}}
+	bookings := &mockBookingRepo{bookings: map[string]*domain.Booking{
+		"AAAAAA": {ID: 1, Reference: "AAAAAA", ScheduleID: 1, SeatNumber: 1, Status: domain.BookingStatusConfirmed, TripID: 7},
+		"BBBBBB": {ID: 2, Reference: "BBBBBB", ScheduleID: 2, SeatNumber: 1, Status: domain.BookingStatusConfirmed},
+	}}
+	trips := &fakeTripRepo{connections: map[int64][]domain.Connection{1: {{
+		Inbound:     *bookings.bookings["AAAAAA"],
+		Onward:      domain.Booking{ID: 9, Reference: "ONWARD", ScheduleID: 5, TripID: 7},
+		AirportCode: "DPS",
+	}}}}
+	uc := NewScheduleUsecase(repo, &fakeRouteRepoSched{items: map[string]bool{"RT1": true}}, &fakeAirplaneRepoSched{items: map[string]bool{"A320": true}}, newSchedAirports()).
+		WithBookings(bookings).
+		WithTrips(trips)
+
+	// Ten minutes fit in the turnaround and keep the connection above its 45 minute minimum.
+	impact, err := uc.Delay(context.Background(), 1, 10, " late crew ")
+	if err != nil || len(impact.Delayed) != 1 || len(impact.Broken) != 0 || len(impact.Notifications) != 1 {
+		t.Fatalf("unexpected impact %+v err=%v", impact, err)
+	}
+
+	impact, err = uc.Delay(context.Background(), 1, 60, "late crew")
+	if err != nil {
+		t.Fatalf("delay: %v", err)
+	}
+	if len(impact.Delayed) != 2 || impact.Delayed[0].ID != 1 || impact.Delayed[1].ID != 2 || impact.Delayed[1].DelayMinutes != 45 {
+		t.Fatalf("want the next rotation held up, got %+v", impact.Delayed)
+	}
+	if repo.items[0].DelayMinutes != 60 || repo.items[0].DelayReason != "late crew" || repo.items[1].DelayMinutes != 45 {
+		t.Fatalf("delays not stored: %+v", repo.items)
+	}
+	if len(impact.Broken) != 1 || impact.Broken[0].Onward.Reference != "ONWARD" || impact.Broken[0].Layover != 0 || impact.Broken[0].Minimum != 45*time.Minute {
+		t.Fatalf("unexpected broken connections %+v", impact.Broken)
+	}
+	var delayNotices, missed int
+	for _, n := range impact.Notifications {
+		switch n.Kind {
+		case domain.NotificationDelay:
+			delayNotices++
+		case domain.NotificationMissedConnection:
+			missed++
+			if n.BookingID != 1 || !strings.Contains(n.Message, "schedule 5") {
+				t.Fatalf("unexpected missed connection notice %+v", n)
+			}
+		}
+	}
+	if delayNotices != 2 || missed != 1 || len(repo.notices) < len(impact.Notifications) {
+		t.Fatalf("unexpected notifications %+v", impact.Notifications)
+	}
+
+	if _, err := uc.Delay(context.Background(), 1, 30, ""); err != domain.ErrInvalidDelay {
+		t.Fatalf("want invalid delay, got %v", err)
+	}
+	repo.items[2].Status = domain.ScheduleStatusCancelled
+	if _, err := uc.Delay(context.Background(), 5, 30, "weather"); err != domain.ErrScheduleCancelled {
+		t.Fatalf("want schedule cancelled, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// airportDistances measures great-circle distances between airports during one
+// search, loading each airport's position at most once.
+type airportDistances struct {
+	airports  domain.AirportRepository
+	positions map[string]*domain.Coordinates
+}
+
+// distances starts a distance cache for one search. It measures nothing when
+// circuity is not limited or no airport repository is configured.
+func (u *BookingUsecase) distances() *airportDistances {
+	if u.connections.MaxCircuity <= 0 || u.airports == nil {
+		return nil
+	}
+	return &airportDistances{airports: u.airports, positions: make(map[string]*domain.Coordinates)}
+}
+
+// position returns the airport's coordinates, or nil when they are unknown.
+func (d *airportDistances) position(ctx context.Context, code string) (*domain.Coordinates, error) {
+	if p, ok := d.positions[code]; ok {
+		return p, nil
+	}
+	airport, err := d.airports.GetByCode(ctx, code)
+	if err != nil && !errors.Is(err, domain.ErrAirportNotFound) {
+		return nil, err
+	}
+	var p *domain.Coordinates
+	if airport != nil {
+		p = airport.Position
+	}
+	d.positions[code] = p
+	return p, nil
+}
+
+// along returns the distance flown through the airports in order; ok is false
+// when any of their positions is unknown.
+func (d *airportDistances) along(ctx context.Context, path ...string) (km int, ok bool, err error) {
+	if d == nil {
+		return 0, false, nil
+	}
+	var prev *domain.Coordinates
+	for i, code := range path {
+		p, err := d.position(ctx, code)
+		if err != nil || p == nil {
+			return 0, false, err
+		}
+		if i > 0 {
+			km += domain.GreatCircleKm(prev, p)
+		}
+		prev = p
+	}
+	return km, true, nil
+}
+
+// circuitous reports whether flying through the airports of path, from its
+// first to its last, exceeds the policy's circuity, with the distance flown and
+// the direct distance. Paths through an airport of unknown position pass.
+func (d *airportDistances) circuitous(ctx context.Context, policy domain.ConnectionPolicy, path ...string) (flown, direct int, bad bool, err error) {
+	flown, ok, err := d.along(ctx, path...)
+	if err != nil || !ok {
+		return 0, 0, false, err
+	}
+	direct, _, err = d.along(ctx, path[0], path[len(path)-1])
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return flown, direct, policy.Circuitous(flown, direct), nil
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// FareUsecase manages the fares published on routes.
+type FareUsecase struct {
+	fares   domain.FareRepository
+	routes  domain.RouteRepository
+	timeout time.Duration
+}
+
+// NewFareUsecase constructs a FareUsecase with default timeout.
+func NewFareUsecase(fareRepo domain.FareRepository, routeRepo domain.RouteRepository) *FareUsecase {
+	return &FareUsecase{fares: fareRepo, routes: routeRepo, timeout: 5 * time.Second}
+}
+
+// Create publishes a fare on a route. roundTrip may be empty when the route has
+// no round-trip price; validFrom and validTo are optional YYYY-MM-DD bounds.
+func (u *FareUsecase) Create(ctx context.Context, routeCode, amount, roundTrip, currency, validFrom, validTo string) (*domain.Fare, error) {
+	oneWay, err := domain.ParseMoney(amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	f := &domain.Fare{RouteCode: routeCode, OneWay: oneWay, ValidFrom: validFrom, ValidTo: validTo}
+	if strings.TrimSpace(roundTrip) != "" {
+		if f.RoundTrip, err = domain.ParseMoney(roundTrip, currency); err != nil {
+			return nil, err
+		}
+	}
+	f.Normalize()
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	if _, err := u.routes.GetByCode(ctx, f.RouteCode); err != nil {
+		return nil, err
+	}
+	if err := u.fares.Create(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// CreateByDistance publishes a one-way fare priced at ratePerKm times the
+// route's great-circle distance. It fails with domain.ErrRouteDistanceUnknown
+// when either airport of the route has no position.
+func (u *FareUsecase) CreateByDistance(ctx context.Context, routeCode, ratePerKm, currency, validFrom, validTo string) (*domain.Fare, error) {
+	rate, err := domain.ParseMoney(ratePerKm, currency)
+	if err != nil {
+		return nil, err
+	}
+	f := &domain.Fare{RouteCode: routeCode, OneWay: rate, ValidFrom: validFrom, ValidTo: validTo}
+	f.Normalize()
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	route, err := u.routes.GetByCode(ctx, f.RouteCode)
+	if err != nil {
+		return nil, err
+	}
+	if route.DistanceKm <= 0 {
+		return nil, domain.ErrRouteDistanceUnknown
+	}
+	f.OneWay.Amount = rate.Amount * int64(route.DistanceKm)
+	if err := u.fares.Create(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// List returns fares, optionally filtered by route code.
+func (u *FareUsecase) List(ctx context.Context, routeCode string, limit, offset int) ([]domain.Fare, error) {
+	routeCode = strings.ToUpper(strings.TrimSpace(routeCode))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.fares.List(ctx, routeCode, limit, offset)
+}
+
+// Delete withdraws a fare by identifier.
+func (u *FareUsecase) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return domain.ErrInvalidFareID
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.fares.Delete(ctx, id)
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+type fakeFareRepo struct {
+	items []domain.Fare
+}
+
+func (f *fakeFareRepo) Create(ctx context.Context, fare *domain.Fare) error {
+	fare.ID = int64(len(f.items) + 1)
+	f.items = append(f.items, *fare)
+	return nil
+}
+
+func (f *fakeFareRepo) List(ctx context.Context, routeCode string, limit, offset int) ([]domain.Fare, error) {
+	var out []domain.Fare
+	for _, item := range f.items {
+		if routeCode == "" || item.RouteCode == routeCode {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeFareRepo) Delete(ctx context.Context, id int64) error {
+	for i, item := range f.items {
+		if item.ID == id {
+			f.items = append(f.items[:i], f.items[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrFareNotFound
+}
+
+func TestFareUsecase(t *testing.T) {
+	repo := &fakeFareRepo{}
+	uc := NewFareUsecase(repo, &fakeRouteRepoSched{items: map[string]bool{"RT1": true}})
+	ctx := context.Background()
+
+	f, err := uc.Create(ctx, "rt1", "129.90", "220", "usd", "2025-03-01", "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if f.ID != 1 || f.RouteCode != "RT1" || f.OneWay.Amount != 12990 || f.RoundTrip.Amount != 22000 || f.RoundTrip.Currency != "USD" {
+		t.Fatalf("unexpected fare %+v", f)
+	}
+	if _, err := uc.Create(ctx, "RT2", "10", "", "USD", "", ""); err != domain.ErrRouteNotFound {
+		t.Fatalf("want route not found, got %v", err)
+	}
+	if _, err := uc.Create(ctx, "RT1", "0", "", "USD", "", ""); err != domain.ErrInvalidFareAmount {
+		t.Fatalf("want invalid amount, got %v", err)
+	}
+	if _, err := uc.Create(ctx, "RT1", "10", "", "US", "", ""); err != domain.ErrInvalidCurrency {
+		t.Fatalf("want invalid currency, got %v", err)
+	}
+	if _, err := uc.Create(ctx, "RT1", "10", "", "USD", "2025-03-02", "2025-03-01"); err != domain.ErrInvalidFareDates {
+		t.Fatalf("want invalid dates, got %v", err)
+	}
+
+	items, err := uc.List(ctx, "rt1", 0, -1)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("list: err=%v items=%+v", err, items)
+	}
+	if err := uc.Delete(ctx, 0); err != domain.ErrInvalidFareID {
+		t.Fatalf("want invalid fare id, got %v", err)
+	}
+	if err := uc.Delete(ctx, 1); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := uc.Delete(ctx, 1); err != domain.ErrFareNotFound {
+		t.Fatalf("want fare not found, got %v", err)
+	}
+}
+
+func TestFareUsecase_CreateByDistance(t *testing.T) {
+	repo := &fakeFareRepo{}
+	routes := &fakeRouteRepo{items: map[string]domain.Route{
+		"CGK-DPS": {Code: "CGK-DPS", OriginCode: "CGK", DestinationCode: "DPS", DistanceKm: 983},
+		"CGK-SUB": {Code: "CGK-SUB", OriginCode: "CGK", DestinationCode: "SUB"},
+	}}
+	uc := NewFareUsecase(repo, routes)
+	ctx := context.Background()
+
+	f, err := uc.CreateByDistance(ctx, "cgk-dps", "1500", "idr", "", "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if f.OneWay.Amount != 983*150000 || f.OneWay.Currency != "IDR" || !f.RoundTrip.IsZero() {
+		t.Fatalf("unexpected fare %+v", f)
+	}
+	if _, err := uc.CreateByDistance(ctx, "CGK-SUB", "1500", "IDR", "", ""); err != domain.ErrRouteDistanceUnknown {
+		t.Fatalf("want distance unknown, got %v", err)
+	}
+	if _, err := uc.CreateByDistance(ctx, "CGK-DPS", "0", "IDR", "", ""); err != domain.ErrInvalidFareAmount {
+		t.Fatalf("want invalid amount, got %v", err)
+	}
+}
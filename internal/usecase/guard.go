@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// airplaneGuard checks that airplanes can fly the flights given to them. The
+// schedule and series usecases share it, so every path that adds a flight or
+// changes its airplane or times runs the same checks, imports included.
+type airplaneGuard struct {
+	// maintenance, when set, keeps airplanes off flights during their
+	// maintenance windows; see maintenance.go.
+	maintenance domain.MaintenanceRepository
+	// schedules and routes load an airplane's rotation, which minTurnaround
+	// and strictRotation govern; see rotation.go.
+	schedules      domain.FlightScheduleRepository
+	routes         domain.RouteRepository
+	minTurnaround  time.Duration
+	strictRotation bool
+}
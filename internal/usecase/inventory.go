@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// InventoryUsecase exposes materialized seat inventory and its reconciliation.
+type InventoryUsecase struct {
+	inventory domain.SeatInventoryRepository
+	timeout   time.Duration
+}
+
+// NewInventoryUsecase constructs an InventoryUsecase with default timeout.
+func NewInventoryUsecase(repo domain.SeatInventoryRepository) *InventoryUsecase {
+	return &InventoryUsecase{inventory: repo, timeout: 5 * time.Second}
+}
+
+// Get returns the seat inventory of a schedule.
+func (u *InventoryUsecase) Get(ctx context.Context, scheduleID int64) (*domain.SeatInventory, error) {
+	if scheduleID <= 0 {
+		return nil, domain.ErrInvalidScheduleID
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.inventory.Get(ctx, scheduleID)
+}
+
+// Reconcile reports schedules whose inventory drifted from their bookings and,
+// when repair is set, rewrites them.
+func (u *InventoryUsecase) Reconcile(ctx context.Context, repair bool) ([]domain.InventoryDrift, error) {
+	// Reconciliation scans every schedule, so it gets more room than a single lookup.
+	ctx, cancel := context.WithTimeout(ctx, 6*u.timeout)
+	defer cancel()
+	return u.inventory.Reconcile(ctx, repair)
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+type fakeInventoryRepo struct {
+	items    map[int64]domain.SeatInventory
+	drifts   []domain.InventoryDrift
+	repaired bool
+}
+
+func (f *fakeInventoryRepo) Get(ctx context.Context, scheduleID int64) (*domain.SeatInventory, error) {
+	inv, ok := f.items[scheduleID]
+	if !ok {
+		return nil, domain.ErrInventoryNotFound
+	}
+	return &inv, nil
+}
+
+func (f *fakeInventoryRepo) Reconcile(ctx context.Context, repair bool) ([]domain.InventoryDrift, error) {
+	f.repaired = repair
+	return f.drifts, nil
+}
+
+func TestInventoryUsecase(t *testing.T) {
+	repo := &fakeInventoryRepo{
+		items:  map[int64]domain.SeatInventory{1: {ScheduleID: 1, Capacity: 10, Sold: 3}},
+		drifts: []domain.InventoryDrift{{Missing: true}},
+	}
+	uc := NewInventoryUsecase(repo)
+	if _, err := uc.Get(context.Background(), 0); err != domain.ErrInvalidScheduleID {
+		t.Fatalf("want invalid schedule id, got %v", err)
+	}
+	inv, err := uc.Get(context.Background(), 1)
+	if err != nil || inv.Available() != 7 {
+		t.Fatalf("get: err=%v inv=%+v", err, inv)
+	}
+	drifts, err := uc.Reconcile(context.Background(), true)
+	if err != nil || len(drifts) != 1 || !repo.repaired {
+		t.Fatalf("reconcile: err=%v drifts=%+v repaired=%v", err, drifts, repo.repaired)
+	}
+}
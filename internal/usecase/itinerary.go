@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+const (
+	// MaxItineraryStops bounds --max-stops so the search space stays small.
+	MaxItineraryStops = 4
+	// maxItineraryResults caps how many itineraries a search returns.
+	maxItineraryResults = 20
+	// maxItineraryExpansions guards against dense schedules exploding the search.
+	maxItineraryExpansions = 20000
+)
+
+// Itinerary is a journey made of one or more connecting legs.
+type Itinerary struct {
+	Legs           []FlightOption
+	Layovers       []time.Duration // Layovers[i] is the time on the ground between Legs[i] and Legs[i+1]
+	TotalAvailable int             // Limited by the leg with fewer seats
+	TotalDuration  time.Duration   // First departure to final arrival
+	TotalFare      domain.Money    // Sum of the legs' fares; zero unless every leg is priced in one currency
+}
+
+// Stops is the number of intermediate airports.
+func (it Itinerary) Stops() int {
+	if len(it.Legs) == 0 {
+		return 0
+	}
+	return len(it.Legs) - 1
+}
+
+// SearchItineraries finds journeys from origin to destination with at most maxStops
+// connections, shortest total duration first. It is a time-dependent best-first
+// search: partial journeys are expanded in order of elapsed time, every connection
+// must satisfy the connection policy, no airport is visited twice and, where
+// airport positions are known, no journey strays beyond the policy's circuity. The date
+// applies to the first leg only. Schedules without a planned arrival cannot be
+// timed and are skipped. All candidate legs are loaded with one availability
+// query and the graph is searched in memory.
+func (u *BookingUsecase) SearchItineraries(ctx context.Context, originCode, destinationCode, departureDate string, maxStops int) ([]Itinerary, error) {
+	return u.FindItineraries(ctx, SearchQuery{OriginCode: originCode, DestinationCode: destinationCode, DepartureDate: departureDate, MaxStops: maxStops})
+}
+
+// FindItineraries is SearchItineraries for a full query with q.MaxStops
+// connections at most. Legs without a seat per passenger or flown by another
+// airplane are left out of the graph; the dates and window apply to the first leg.
+func (u *BookingUsecase) FindItineraries(ctx context.Context, q SearchQuery) ([]Itinerary, error) {
+	c, err := q.criteria()
+	if err != nil {
+		return nil, err
+	}
+	origin, destination, dates, maxStops := c.origin, c.destination, c.dates, q.MaxStops
+	if maxStops < 0 || maxStops > MaxItineraryStops {
+		return nil, domain.ErrInvalidMaxStops
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	rows, err := u.availability.Search(ctx, itineraryWindow(dates, maxStops, u.connections.MaxLayover))
+	if err != nil {
+		return nil, err
+	}
+	// Every usable leg, grouped by the airport it departs from.
+	graph := make(map[string][]itineraryLeg)
+	for _, row := range rows {
+		if row.Schedule.ArrivalAt.IsZero() || !c.bookable(row) {
+			continue
+		}
+		graph[row.OriginCode] = append(graph[row.OriginCode], itineraryLeg{option: flightOption(row), schedule: row.Schedule})
+	}
+
+	distances := u.distances()
+	// detour reports whether a journey through the visited airports and on to
+	// next cannot reach the destination within the policy's circuity.
+	detour := func(visited []string, next string) (bool, error) {
+		path := append(append(make([]string, 0, len(visited)+2), visited...), next)
+		if next != destination {
+			path = append(path, destination)
+		}
+		_, _, bad, err := distances.circuitous(ctx, u.connections, path...)
+		return bad, err
+	}
+
+	queue := &itineraryQueue{}
+	for _, leg := range graph[origin] {
+		if !dates.contains(leg.schedule.DepartureDate) || !c.departsInWindow(leg.option.DepartureTime) {
+			continue
+		}
+		if bad, err := detour([]string{origin}, leg.option.DestinationCode); err != nil {
+			return nil, err
+		} else if bad {
+			continue
+		}
+		heap.Push(queue, &partialItinerary{
+			legs:     []itineraryLeg{leg},
+			airports: []string{origin, leg.option.DestinationCode},
+		})
+	}
+
+	minimums := make(map[string]time.Duration)
+	var results []Itinerary
+	for expansions := 0; queue.Len() > 0 && expansions < maxItineraryExpansions; expansions++ {
+		p := heap.Pop(queue).(*partialItinerary)
+		here := p.airports[len(p.airports)-1]
+		if here == destination {
+			results = append(results, p.itinerary())
+			if len(results) >= maxItineraryResults {
+				break
+			}
+			continue
+		}
+		if len(p.legs) > maxStops {
+			continue
+		}
+		minimum, ok := minimums[here]
+		if !ok {
+			var err error
+			if minimum, err = u.minConnectionAt(ctx, here); err != nil {
+				return nil, err
+			}
+			minimums[here] = minimum
+		}
+		last := p.legs[len(p.legs)-1]
+		for _, leg := range graph[here] {
+			if p.visited(leg.option.DestinationCode) {
+				continue
+			}
+			layover, ok := u.connections.Connect(last.schedule, leg.schedule, minimum)
+			if !ok {
+				continue
+			}
+			if bad, err := detour(p.airports, leg.option.DestinationCode); err != nil {
+				return nil, err
+			} else if bad {
+				continue
+			}
+			heap.Push(queue, p.extend(leg, layover))
+		}
+	}
+	if c.sortBy != "" {
+		sort.SliceStable(results, c.lessBy(func(i int) journey { return itineraryJourney(results[i]) }))
+	}
+	return results, nil
+}
+
+// itineraryLeg pairs a bookable flight option with the schedule it came from.
+type itineraryLeg struct {
+	option   FlightOption
+	schedule domain.FlightSchedule
+}
+
+type partialItinerary struct {
+	legs     []itineraryLeg
+	layovers []time.Duration
+	airports []string // origin followed by each leg's destination
+}
+
+func (p *partialItinerary) elapsed() time.Duration {
+	return p.legs[len(p.legs)-1].schedule.ArrivalAt.Sub(p.legs[0].schedule.DepartureAt)
+}
+
+func (p *partialItinerary) visited(code string) bool {
+	for _, a := range p.airports {
+		if a == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *partialItinerary) extend(leg itineraryLeg, layover time.Duration) *partialItinerary {
+	next := &partialItinerary{
+		legs:     make([]itineraryLeg, 0, len(p.legs)+1),
+		layovers: make([]time.Duration, 0, len(p.layovers)+1),
+		airports: make([]string, 0, len(p.airports)+1),
+	}
+	next.legs = append(append(next.legs, p.legs...), leg)
+	next.layovers = append(append(next.layovers, p.layovers...), layover)
+	next.airports = append(append(next.airports, p.airports...), leg.option.DestinationCode)
+	return next
+}
+
+func (p *partialItinerary) itinerary() Itinerary {
+	it := Itinerary{Layovers: p.layovers, TotalDuration: p.elapsed()}
+	fares := make([]*domain.Fare, 0, len(p.legs))
+	for i, leg := range p.legs {
+		fares = append(fares, leg.option.Fare)
+		it.Legs = append(it.Legs, leg.option)
+		if i == 0 || leg.option.SeatsAvailable < it.TotalAvailable {
+			it.TotalAvailable = leg.option.SeatsAvailable
+		}
+	}
+	it.TotalFare = totalFare(fares...)
+	return it
+}
+
+// itineraryQueue is a min-heap of partial journeys ordered by elapsed time, then
+// earliest departure, then fewest legs. Elapsed time never decreases as legs are
+// added, so complete journeys are popped shortest first.
+type itineraryQueue []*partialItinerary
+
+func (q itineraryQueue) Len() int { return len(q) }
+
+func (q itineraryQueue) Less(i, j int) bool {
+	a, b := q[i], q[j]
+	if ea, eb := a.elapsed(), b.elapsed(); ea != eb {
+		return ea < eb
+	}
+	if da, db := a.legs[0].schedule.DepartureAt, b.legs[0].schedule.DepartureAt; !da.Equal(db) {
+		return da.Before(db)
+	}
+	return len(a.legs) < len(b.legs)
+}
+
+func (q itineraryQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *itineraryQueue) Push(x any) { *q = append(*q, x.(*partialItinerary)) }
+
+func (q *itineraryQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
+
+// itineraryWindow bounds the schedules loaded for a search. With dates, legs may
+// depart from the start of the first local day (origin offsets reach UTC-12..+14)
+// until every allowed connection has used its full layover, assuming no leg is
+// longer than a day.
+func itineraryWindow(dates searchDates, maxStops int, maxLayover time.Duration) domain.AvailabilityQuery {
+	if dates.from == "" {
+		return domain.AvailabilityQuery{}
+	}
+	first, _ := time.Parse("2006-01-02", dates.from)
+	last, _ := time.Parse("2006-01-02", dates.to)
+	return domain.AvailabilityQuery{
+		DepartsAfter:  first.Add(-14 * time.Hour),
+		DepartsBefore: last.Add(36*time.Hour + time.Duration(maxStops)*(maxLayover+24*time.Hour)),
+	}
+}
+
+// BookItinerary books a passenger on connecting flights, given in flying
+// order, as one connecting trip: either every seat is taken or none is. Each
+// flight must depart from the airport the previous one lands at, within the
+// connection policy's window, so that delays can find the passenger's
+// connections. The trip is priced with the sum of each leg's cheapest one-way
+// fare when all are published in one currency. It fails with
+// domain.ErrInvalidItinerary when the flights do not connect.
+func (u *BookingUsecase) BookItinerary(ctx context.Context, scheduleIDs []int64, passengerName string) (*domain.Trip, error) {
+	if len(scheduleIDs) < 2 || len(scheduleIDs) > MaxItineraryStops+1 {
+		return nil, domain.ErrInvalidItinerary
+	}
+	seen := make(map[int64]bool)
+	for _, id := range scheduleIDs {
+		if id <= 0 || seen[id] {
+			return nil, domain.ErrInvalidScheduleID
+		}
+		seen[id] = true
+	}
+	passengerName = strings.TrimSpace(passengerName)
+	if len(passengerName) == 0 {
+		return nil, domain.ErrInvalidPassengerName
+	}
+	if u.trips == nil {
+		return nil, domain.ErrTripsNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	trip := &domain.Trip{Kind: domain.TripConnecting, PassengerName: passengerName}
+	var fares []*domain.Fare
+	var prev *domain.FlightSchedule
+	var prevRoute *domain.Route
+	for _, id := range scheduleIDs {
+		sched, err := u.schedules.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		route, err := u.routes.GetByCode(ctx, sched.RouteCode)
+		if err != nil {
+			return nil, err
+		}
+		if prev != nil {
+			if err := u.checkConnection(ctx, prev, prevRoute, sched, route); err != nil {
+				return nil, err
+			}
+		}
+		b, err := u.newBooking(ctx, sched, passengerName, domain.BookingStatusConfirmed)
+		if err != nil {
+			return nil, err
+		}
+		trip.Bookings = append(trip.Bookings, *b)
+		fare, err := u.cheapestFare(ctx, sched)
+		if err != nil {
+			return nil, err
+		}
+		fares = append(fares, fare)
+		prev, prevRoute = sched, route
+	}
+	if u.fares != nil {
+		trip.Fare = totalFare(fares...)
+	}
+	if err := u.createTrip(ctx, trip); err != nil {
+		return nil, err
+	}
+	return trip, nil
+}
+
+// checkConnection fails with domain.ErrInvalidItinerary unless the next
+// flight departs from where the previous one lands, within the connection
+// policy's layover window at that airport.
+func (u *BookingUsecase) checkConnection(ctx context.Context, prev *domain.FlightSchedule, prevRoute *domain.Route, next *domain.FlightSchedule, nextRoute *domain.Route) error {
+	if nextRoute.OriginCode != prevRoute.DestinationCode {
+		return fmt.Errorf("schedule %d lands at %s but schedule %d departs from %s: %w", prev.ID, prevRoute.DestinationCode, next.ID, nextRoute.OriginCode, domain.ErrInvalidItinerary)
+	}
+	if prev.ArrivalAt.IsZero() {
+		return fmt.Errorf("schedule %d has no planned arrival to connect from: %w", prev.ID, domain.ErrInvalidItinerary)
+	}
+	minimum, err := u.minConnectionAt(ctx, nextRoute.OriginCode)
+	if err != nil {
+		return err
+	}
+	layover := next.DepartureAt.Sub(prev.ArrivalAt)
+	if layover < minimum || layover > u.connections.MaxLayover {
+		return fmt.Errorf("%d min layover at %s is outside %d-%d min: %w", int(layover/time.Minute), nextRoute.OriginCode, int(minimum/time.Minute), int(u.connections.MaxLayover/time.Minute), domain.ErrInvalidItinerary)
+	}
+	return nil
+}
+
+// cheapestFare is the cheapest fare valid on the flight's departure date; nil
+// when none is published or fares are not configured.
+func (u *BookingUsecase) cheapestFare(ctx context.Context, sched *domain.FlightSchedule) (*domain.Fare, error) {
+	if u.fares == nil {
+		return nil, nil
+	}
+	fares, err := u.fares.List(ctx, sched.RouteCode, 500, 0)
+	if err != nil {
+		return nil, err
+	}
+	return domain.CheapestFare(fares, sched.DepartureDate), nil
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func newItineraryUsecase(t *testing.T) *BookingUsecase {
+	t.Helper()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	leg := func(id int64, route string, depHour, arrHour float64) *domain.FlightSchedule {
+		dep := base.Add(time.Duration(depHour * float64(time.Hour)))
+		return &domain.FlightSchedule{
+			ID: id, RouteCode: route, AirplaneCode: "A320",
+			DepartureDate: dep.Format("2006-01-02"),
+			DepartureAt:   dep,
+			ArrivalAt:     base.Add(time.Duration(arrHour * float64(time.Hour))),
+		}
+	}
+	schedules := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{
+		1: leg(1, "CGK-DPS", 6, 16),   // slow direct: 10h
+		2: leg(2, "CGK-SUB", 6, 7.5),  // first leg of the one-stop
+		3: leg(3, "SUB-DPS", 8.5, 10), // one-stop total 4h
+		4: leg(4, "SUB-UPG", 8.5, 10),
+		5: leg(5, "UPG-DPS", 11, 12),  // two-stop total 6h
+		6: leg(6, "SUB-CGK", 8.5, 10), // would revisit CGK
+		7: leg(7, "CGK-DPS", 9, 11),
+	}}
+	// Schedule 7 has no planned arrival, so it cannot be part of an itinerary.
+	schedules.schedules[7].ArrivalAt = time.Time{}
+	routes := &mockRouteRepo{routes: map[string]*domain.Route{
+		"CGK-DPS": {Code: "CGK-DPS", OriginCode: "CGK", DestinationCode: "DPS"},
+		"CGK-SUB": {Code: "CGK-SUB", OriginCode: "CGK", DestinationCode: "SUB"},
+		"SUB-DPS": {Code: "SUB-DPS", OriginCode: "SUB", DestinationCode: "DPS"},
+		"SUB-UPG": {Code: "SUB-UPG", OriginCode: "SUB", DestinationCode: "UPG"},
+		"UPG-DPS": {Code: "UPG-DPS", OriginCode: "UPG", DestinationCode: "DPS"},
+		"SUB-CGK": {Code: "SUB-CGK", OriginCode: "SUB", DestinationCode: "CGK"},
+	}}
+	planes := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 10}}}
+	uc := NewBookingUsecase(&mockBookingRepo{count: 3}, schedules, routes, planes)
+	uc.WithConnectionPolicy(nil, domain.ConnectionPolicy{MinConnection: 30 * time.Minute, MaxLayover: 6 * time.Hour})
+	return uc
+}
+
+func TestBookingUsecase_SearchItineraries_RanksByDuration(t *testing.T) {
+	uc := newItineraryUsecase(t)
+
+	got, err := uc.SearchItineraries(context.Background(), "cgk", "dps", "2025-01-01", 2)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected three itineraries, got %d: %+v", len(got), got)
+	}
+	wantStops := []int{1, 2, 0}
+	wantDurations := []time.Duration{4 * time.Hour, 6 * time.Hour, 10 * time.Hour}
+	for i, it := range got {
+		if it.Stops() != wantStops[i] || it.TotalDuration != wantDurations[i] {
+			t.Fatalf("itinerary %d: stops=%d duration=%s", i, it.Stops(), it.TotalDuration)
+		}
+		if it.Legs[0].OriginCode != "CGK" || it.Legs[len(it.Legs)-1].DestinationCode != "DPS" {
+			t.Fatalf("itinerary %d does not connect CGK to DPS: %+v", i, it.Legs)
+		}
+		if len(it.Layovers) != it.Stops() || it.TotalAvailable != 7 {
+			t.Fatalf("itinerary %d: layovers=%v seats=%d", i, it.Layovers, it.TotalAvailable)
+		}
+	}
+	if got[1].Layovers[0] != time.Hour || got[1].Layovers[1] != time.Hour {
+		t.Fatalf("unexpected layovers %v", got[1].Layovers)
+	}
+}
+
+func TestBookingUsecase_SearchItineraries_MaxStops(t *testing.T) {
+	uc := newItineraryUsecase(t)
+
+	direct, err := uc.SearchItineraries(context.Background(), "CGK", "DPS", "", 0)
+	if err != nil || len(direct) != 1 || direct[0].Legs[0].ScheduleID != 1 {
+		t.Fatalf("expected only the timed direct flight, err=%v got=%+v", err, direct)
+	}
+	oneStop, err := uc.SearchItineraries(context.Background(), "CGK", "DPS", "", 1)
+	if err != nil || len(oneStop) != 2 {
+		t.Fatalf("expected direct and one-stop itineraries, err=%v got=%d", err, len(oneStop))
+	}
+	if _, err := uc.SearchItineraries(context.Background(), "CGK", "DPS", "", MaxItineraryStops+1); err != domain.ErrInvalidMaxStops {
+		t.Fatalf("want invalid max stops, got %v", err)
+	}
+	if _, err := uc.SearchItineraries(context.Background(), "CGK", "CGK", "", 1); err != domain.ErrInvalidRouteAirports {
+		t.Fatalf("want invalid airports, got %v", err)
+	}
+	if _, err := uc.SearchItineraries(context.Background(), "CGK", "DPS", "01-01-2025", 1); err != domain.ErrInvalidScheduleDate {
+		t.Fatalf("want invalid date, got %v", err)
+	}
+}
+
+// newPositionedAirports places the itinerary fixture's airports on the map.
+func newPositionedAirports() *fakeAirportRepo {
+	return &fakeAirportRepo{list: []domain.Airport{
+		{Code: "CGK", Position: &domain.Coordinates{Latitude: -6.1256, Longitude: 106.6559}},
+		{Code: "SUB", Position: &domain.Coordinates{Latitude: -7.3798, Longitude: 112.7868}},
+		{Code: "DPS", Position: &domain.Coordinates{Latitude: -8.7482, Longitude: 115.1672}},
+		{Code: "UPG", Position: &domain.Coordinates{Latitude: -5.0616, Longitude: 119.5540}},
+	}}
+}
+
+func TestBookingUsecase_Circuity(t *testing.T) {
+	uc := newItineraryUsecase(t)
+	// SUB-UPG-DPS flies 1425 km to cover 303 km.
+	transit, err := uc.SearchTransitFlights(context.Background(), "SUB", "DPS", "2025-01-01")
+	if err != nil || len(transit) != 1 || transit[0].Intermediate != "UPG" {
+		t.Fatalf("without positions the detour is allowed, err=%v got=%+v", err, transit)
+	}
+
+	uc.WithConnectionPolicy(newPositionedAirports(), domain.ConnectionPolicy{MinConnection: 30 * time.Minute, MaxLayover: 6 * time.Hour, MaxCircuity: 2})
+	trace := &SearchTrace{}
+	transit, err = uc.FindTransitFlights(context.Background(), SearchQuery{OriginCode: "SUB", DestinationCode: "DPS", DepartureDate: "2025-01-01", Explain: trace})
+	if err != nil || len(transit) != 0 {
+		t.Fatalf("expected the detour via UPG to be dropped, err=%v got=%+v", err, transit)
+	}
+	rejected := false
+	for _, e := range trace.Rejected() {
+		if e.ScheduleID == 4 && e.Reason == RejectCircuitous {
+			rejected = true
+		}
+	}
+	if !rejected {
+		t.Fatalf("expected schedule 4 rejected as circuitous, got %+v", trace.Rejected())
+	}
+
+	// CGK-SUB-UPG-DPS flies 2116 km against 983 km direct; CGK-SUB-DPS stays.
+	got, err := uc.SearchItineraries(context.Background(), "CGK", "DPS", "2025-01-01", 2)
+	if err != nil || len(got) != 2 || got[0].Stops() != 1 || got[1].Stops() != 0 {
+		t.Fatalf("expected the two-stop itinerary pruned, err=%v got=%+v", err, got)
+	}
+}
+
+func TestBookingUsecase_BookItinerary(t *testing.T) {
+	uc := newItineraryUsecase(t)
+	ctx := context.Background()
+
+	if _, err := uc.BookItinerary(ctx, []int64{2, 3}, "Alice"); err != domain.ErrTripsNotConfigured {
+		t.Fatalf("want trips not configured, got %v", err)
+	}
+	uc.WithTrips(&fakeTripRepo{}).WithFares(&fakeFareRepo{items: []domain.Fare{
+		{ID: 1, RouteCode: "SUB-UPG", OneWay: domain.Money{Amount: 40, Currency: "USD"}},
+		{ID: 2, RouteCode: "UPG-DPS", OneWay: domain.Money{Amount: 60, Currency: "USD"}},
+	}})
+
+	trip, err := uc.BookItinerary(ctx, []int64{4, 5}, " Alice ")
+	if err != nil {
+		t.Fatalf("book itinerary: %v", err)
+	}
+	if trip.Kind != domain.TripConnecting || len(trip.Bookings) != 2 || trip.Bookings[0].ScheduleID != 4 || trip.Bookings[1].ScheduleID != 5 {
+		t.Fatalf("unexpected trip %+v", trip)
+	}
+	if trip.Fare != (domain.Money{Amount: 100, Currency: "USD"}) {
+		t.Fatalf("expected summed one-way fares, got %v", trip.Fare)
+	}
+	if trip, err = uc.BookItinerary(ctx, []int64{2, 3}, "Bob"); err != nil || !trip.Fare.IsZero() {
+		t.Fatalf("expected an unpriced trip without fares, err=%v trip=%+v", err, trip)
+	}
+
+	cases := []struct {
+		ids  []int64
+		want error
+	}{
+		{[]int64{2}, domain.ErrInvalidItinerary},
+		{[]int64{2, 2}, domain.ErrInvalidScheduleID},
+		{[]int64{2, 0}, domain.ErrInvalidScheduleID},
+		{[]int64{2, 5}, domain.ErrInvalidItinerary}, // lands at SUB, departs from UPG
+		{[]int64{3, 2}, domain.ErrInvalidItinerary}, // lands at DPS, departs from CGK
+		{[]int64{7, 3}, domain.ErrInvalidItinerary}, // no planned arrival
+		{[]int64{2, 9}, domain.ErrScheduleNotFound},
+	}
+	for _, tc := range cases {
+		if _, err := uc.BookItinerary(ctx, tc.ids, "Alice"); !errors.Is(err, tc.want) {
+			t.Fatalf("BookItinerary(%v): want %v, got %v", tc.ids, tc.want, err)
+		}
+	}
+
+	uc.WithConnectionPolicy(nil, domain.ConnectionPolicy{MinConnection: 90 * time.Minute, MaxLayover: 6 * time.Hour})
+	if _, err := uc.BookItinerary(ctx, []int64{2, 3}, "Carol"); !errors.Is(err, domain.ErrInvalidItinerary) {
+		t.Fatalf("want a too-short connection rejected, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// MaintenanceUsecase records airplane maintenance windows.
+type MaintenanceUsecase struct {
+	repo      domain.MaintenanceRepository
+	airplanes domain.AirplaneRepository
+	timeout   time.Duration
+	now       func() time.Time
+}
+
+// NewMaintenanceUsecase constructs a MaintenanceUsecase with default timeout.
+func NewMaintenanceUsecase(r domain.MaintenanceRepository, airplanes domain.AirplaneRepository) *MaintenanceUsecase {
+	return &MaintenanceUsecase{repo: r, airplanes: airplanes, timeout: 5 * time.Second, now: time.Now}
+}
+
+// MaintenanceResult reports a recorded window and the already booked flights
+// of the airplane that fall in it and need another airplane.
+type MaintenanceResult struct {
+	Window    *domain.MaintenanceWindow
+	Conflicts []domain.MaintenanceConflict
+}
+
+// Add records that the airplane is out of service from from until to, both
+// parsed with domain.ParseMaintenanceTime. Booked flights in the window are
+// kept and reported.
+func (u *MaintenanceUsecase) Add(ctx context.Context, airplaneCode, from, to, reason string) (*MaintenanceResult, error) {
+	w := &domain.MaintenanceWindow{AirplaneCode: airplaneCode, Reason: reason}
+	var err error
+	if w.StartsAt, err = domain.ParseMaintenanceTime(from); err != nil {
+		return nil, err
+	}
+	if w.EndsAt, err = domain.ParseMaintenanceTime(to); err != nil {
+		return nil, err
+	}
+	w.Normalize()
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	if _, err := u.airplanes.GetByCode(ctx, w.AirplaneCode); err != nil {
+		return nil, err
+	}
+	if err := u.repo.Create(ctx, w); err != nil {
+		return nil, err
+	}
+	conflicts, err := u.repo.BookedFlights(ctx, *w)
+	if err != nil {
+		return nil, err
+	}
+	return &MaintenanceResult{Window: w, Conflicts: conflicts}, nil
+}
+
+// List returns the airplane's windows that have not ended yet.
+func (u *MaintenanceUsecase) List(ctx context.Context, airplaneCode string) ([]domain.MaintenanceWindow, error) {
+	a := domain.Airplane{Code: airplaneCode, SeatCapacity: 1}
+	a.Normalize()
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.repo.ListByAirplane(ctx, a.Code, u.now())
+}
+
+// WithMaintenance makes Create and SwapAirplane refuse airplanes that are in
+// maintenance during the flight with a *domain.MaintenanceError.
+func (u *ScheduleUsecase) WithMaintenance(m domain.MaintenanceRepository) *ScheduleUsecase {
+	u.guard.maintenance = m
+	return u
+}
+
+// WithMaintenance makes Create, Extend and Amend refuse a series whose
+// airplane is in maintenance during one of its flights with a
+// *domain.MaintenanceError.
+func (u *SeriesUsecase) WithMaintenance(m domain.MaintenanceRepository) *SeriesUsecase {
+	u.guard.maintenance = m
+	return u
+}
+
+// checkMaintenance fails with a *domain.MaintenanceError when a maintenance
+// window of a flight's airplane overlaps the flight. Each airplane's windows
+// are read once, over the span of all its flights.
+func (g *airplaneGuard) checkMaintenance(ctx context.Context, flights []domain.FlightSchedule) error {
+	if g.maintenance == nil {
+		return nil
+	}
+	var codes []string
+	spans := make(map[string][2]time.Time)
+	for _, f := range flights {
+		from, to := domain.FlightSpan(f)
+		span, ok := spans[f.AirplaneCode]
+		if !ok {
+			codes = append(codes, f.AirplaneCode)
+			span = [2]time.Time{from, to}
+		}
+		if from.Before(span[0]) {
+			span[0] = from
+		}
+		if to.After(span[1]) {
+			span[1] = to
+		}
+		spans[f.AirplaneCode] = span
+	}
+	for _, code := range codes {
+		windows, err := g.maintenance.Overlapping(ctx, code, spans[code][0], spans[code][1])
+		if err != nil {
+			return err
+		}
+		for _, f := range flights {
+			for _, w := range windows {
+				if f.AirplaneCode == code && w.Blocks(f) {
+					return &domain.MaintenanceError{Window: w}
+				}
+			}
+		}
+	}
+	return nil
+}
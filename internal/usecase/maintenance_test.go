@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+type fakeMaintenanceRepo struct {
+	windows   []domain.MaintenanceWindow
+	conflicts []domain.MaintenanceConflict
+	listFrom  time.Time
+}
+
+func (f *fakeMaintenanceRepo) Create(ctx context.Context, w *domain.MaintenanceWindow) error {
+	w.ID = int64(len(f.windows) + 1)
+	f.windows = append(f.windows, *w)
+	return nil
+}
+
+func (f *fakeMaintenanceRepo) ListByAirplane(ctx context.Context, airplaneCode string, from time.Time) ([]domain.MaintenanceWindow, error) {
+	f.listFrom = from
+	var out []domain.MaintenanceWindow
+	for _, w := range f.windows {
+		if w.AirplaneCode == airplaneCode && w.EndsAt.After(from) {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeMaintenanceRepo) Overlapping(ctx context.Context, airplaneCode string, from, to time.Time) ([]domain.MaintenanceWindow, error) {
+	var out []domain.MaintenanceWindow
+	for _, w := range f.windows {
+		if w.AirplaneCode == airplaneCode && w.StartsAt.Before(to) && w.EndsAt.After(from) {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeMaintenanceRepo) BookedFlights(ctx context.Context, w domain.MaintenanceWindow) ([]domain.MaintenanceConflict, error) {
+	var out []domain.MaintenanceConflict
+	for _, c := range f.conflicts {
+		if w.Blocks(c.Schedule) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func TestMaintenanceUsecase_Add(t *testing.T) {
+	booked := domain.FlightSchedule{ID: 7, AirplaneCode: "A320", DepartureAt: time.Date(2025, 1, 2, 23, 0, 0, 0, time.UTC), ArrivalAt: time.Date(2025, 1, 3, 1, 0, 0, 0, time.UTC)}
+	later := domain.FlightSchedule{ID: 8, AirplaneCode: "A320", DepartureAt: time.Date(2025, 1, 4, 8, 0, 0, 0, time.UTC)}
+	repo := &fakeMaintenanceRepo{conflicts: []domain.MaintenanceConflict{{Schedule: booked, Booked: 2}, {Schedule: later, Booked: 1}}}
+	uc := NewMaintenanceUsecase(repo, &fakeAirplaneRepoSched{items: map[string]bool{"A320": true}})
+	uc.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	res, err := uc.Add(context.Background(), " a320 ", "2025-01-03", "2025-01-04T08:00", " C-check ")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	w := res.Window
+	if w.ID != 1 || w.AirplaneCode != "A320" || w.Reason != "C-check" || !w.StartsAt.Equal(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)) || !w.EndsAt.Equal(time.Date(2025, 1, 4, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected window %+v", w)
+	}
+	// The overnight flight lands inside the window; the later one leaves as it ends.
+	if len(res.Conflicts) != 1 || res.Conflicts[0].Schedule.ID != 7 {
+		t.Fatalf("want only the overnight flight reported, got %+v", res.Conflicts)
+	}
+
+	if _, err := uc.Add(context.Background(), "A320", "2025-01-04", "2025-01-03", ""); err != domain.ErrInvalidMaintenanceWindow {
+		t.Fatalf("want invalid window, got %v", err)
+	}
+	if _, err := uc.Add(context.Background(), "A320", "tomorrow", "2025-01-03", ""); err != domain.ErrInvalidMaintenanceWindow {
+		t.Fatalf("want unparsable start rejected, got %v", err)
+	}
+	if _, err := uc.Add(context.Background(), "B777", "2025-01-03", "2025-01-04", ""); err != domain.ErrAirplaneNotFound {
+		t.Fatalf("want airplane not found, got %v", err)
+	}
+
+	items, err := uc.List(context.Background(), "a320")
+	if err != nil || len(items) != 1 || !repo.listFrom.Equal(uc.now()) {
+		t.Fatalf("unexpected list %+v err=%v", items, err)
+	}
+}
+
+func TestScheduleUsecase_MaintenanceBlocksAssignment(t *testing.T) {
+	maintenance := &fakeMaintenanceRepo{windows: []domain.MaintenanceWindow{
+		{ID: 1, AirplaneCode: "B737", StartsAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), EndsAt: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Reason: "C-check"},
+	}}
+	repo := &fakeScheduleRepo{}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true, "B737": true}}
+	uc := NewScheduleUsecase(repo, &fakeRouteRepoSched{items: map[string]bool{"RT1": true}}, planes, newSchedAirports()).WithMaintenance(maintenance)
+
+	// 06:00 in Jakarta is 23:00 UTC the day before, outside the window.
+	if _, err := uc.Create(context.Background(), "RT1", "B737", "2025-01-02", "06:00", "07:55"); err != nil {
+		t.Fatalf("flight before maintenance: %v", err)
+	}
+	_, err := uc.Create(context.Background(), "RT1", "B737", "2025-01-02", "08:00", "")
+	var mErr *domain.MaintenanceError
+	if !errors.As(err, &mErr) || !errors.Is(err, domain.ErrAirplaneInMaintenance) || mErr.Window.Reason != "C-check" {
+		t.Fatalf("want maintenance refusal, got %v", err)
+	}
+
+	sched, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-02", "08:00", "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := uc.SwapAirplane(context.Background(), sched.ID, "B737"); !errors.Is(err, domain.ErrAirplaneInMaintenance) {
+		t.Fatalf("want swap refused, got %v", err)
+	}
+	if got, _ := repo.GetByID(context.Background(), sched.ID); got.AirplaneCode != "A320" {
+		t.Fatalf("a refused swap must keep the airplane, got %+v", got)
+	}
+}
+
+func TestSeriesUsecase_MaintenanceBlocksFlights(t *testing.T) {
+	maintenance := &fakeMaintenanceRepo{windows: []domain.MaintenanceWindow{
+		{ID: 1, AirplaneCode: "A320", StartsAt: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), EndsAt: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC), Reason: "C-check"},
+		{ID: 2, AirplaneCode: "A320", StartsAt: time.Date(2025, 1, 8, 4, 0, 0, 0, time.UTC), EndsAt: time.Date(2025, 1, 8, 6, 0, 0, 0, time.UTC), Reason: "engine wash"},
+	}}
+	repo := &fakeSeriesRepo{}
+	uc := newSeriesUsecase(repo).WithMaintenance(maintenance)
+	uc.now = func() time.Time { return time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC) }
+
+	// 08:30 in Jakarta is 01:30 UTC: the Wednesday in the C-check is refused.
+	if _, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-01", "2025-01-21", "Wed", "08:30", ""); !errors.Is(err, domain.ErrAirplaneInMaintenance) {
+		t.Fatalf("want series refused, got %v", err)
+	}
+	if len(repo.items) != 0 {
+		t.Fatalf("a refused series must not be stored")
+	}
+	if _, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-01", "2025-01-08", "Wed", "08:30", ""); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var mErr *domain.MaintenanceError
+	if _, err := uc.Extend(context.Background(), 1, "2025-01-21"); !errors.As(err, &mErr) || mErr.Window.ID != 1 {
+		t.Fatalf("want extension refused, got %v", err)
+	}
+	// 12:00 in Jakarta moves the 2025-01-08 flight into the engine wash.
+	if _, err := uc.Amend(context.Background(), 1, "12:00", ""); !errors.As(err, &mErr) || mErr.Window.ID != 2 {
+		t.Fatalf("want amendment refused, got %v", err)
+	}
+	if len(repo.flights) != 2 || repo.flights[1].LocalDeparture().Format("15:04") != "08:30" {
+		t.Fatalf("refused changes must leave the flights alone, got %+v", repo.flights)
+	}
+}
+
+func TestTimetableUsecase_ImportMaintenanceRejectsLine(t *testing.T) {
+	maintenance := &fakeMaintenanceRepo{windows: []domain.MaintenanceWindow{
+		{ID: 1, AirplaneCode: "PK-GQA", StartsAt: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), EndsAt: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+	}}
+	routes := &fakeRouteRepo{items: map[string]domain.Route{"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}}}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"PK-GQA": true}, types: map[string]string{"PK-GQA": "320"}}
+	uc := NewTimetableUsecase(NewSeriesUsecase(&fakeSeriesRepo{}, routes, planes, newSchedAirports()).WithMaintenance(maintenance), &fakeScheduleRepo{})
+
+	res, err := uc.Import(context.Background(), []TimetableEntry{
+		{Line: 3, OriginCode: "CGK", DestinationCode: "DPS", AircraftType: "320", Series: domain.ScheduleSeries{StartDate: "2025-01-01", EndDate: "2025-01-07", Days: domain.AllWeekdays, DepartureTime: "08:30"}},
+		{Line: 4, OriginCode: "CGK", DestinationCode: "DPS", AircraftType: "320", Series: domain.ScheduleSeries{StartDate: "2025-01-13", EndDate: "2025-01-19", Days: domain.AllWeekdays, DepartureTime: "08:30"}},
+	})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(res.Series) != 1 || len(res.Errors) != 1 || res.Errors[0].Line != 4 || !errors.Is(res.Errors[0], domain.ErrAirplaneInMaintenance) {
+		t.Fatalf("want the line flying into maintenance rejected, got %+v", res)
+	}
+}
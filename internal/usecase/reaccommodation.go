@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// maxSeatOffers bounds how many alternative flights a reaccommodation offers.
+const maxSeatOffers = 5
+
+// planReaccommodation picks the bookings of sched that do not fit capacity and
+// the later flights on its route with seats left. Held bookings give way
+// first, then the most recent confirmed ones.
+func planReaccommodation(ctx context.Context, schedules domain.FlightScheduleRepository, bookings domain.BookingRepository, airplanes domain.AirplaneRepository, sched domain.FlightSchedule, capacity int) (*domain.Reaccommodation, error) {
+	active, err := activeBookings(ctx, bookings, sched.ID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		if held := active[i].Status == domain.BookingStatusHeld; held != (active[j].Status == domain.BookingStatusHeld) {
+			return held
+		}
+		return active[i].ID > active[j].ID
+	})
+	plan := &domain.Reaccommodation{Schedule: sched}
+	if over := len(active) - capacity; over > 0 {
+		plan.Displaced = active[:over]
+	}
+
+	later, err := schedules.List(ctx, sched.RouteCode, pageSize, 0)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Alternatives, err = seatOffers(ctx, bookings, airplanes, sched, later); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// activeBookings loads the confirmed and held bookings of a flight.
+func activeBookings(ctx context.Context, bookings domain.BookingRepository, scheduleID int64) ([]domain.Booking, error) {
+	var active []domain.Booking
+	for offset := 0; ; offset += pageSize {
+		page, err := bookings.ListBySchedule(ctx, scheduleID, pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range page {
+			if b.Status != domain.BookingStatusCancelled {
+				active = append(active, b)
+			}
+		}
+		if len(page) < pageSize {
+			break
+		}
+	}
+	return active, nil
+}
+
+// seatOffers picks, in order, the candidates departing after sched that are
+// not cancelled and still have seats left, up to maxSeatOffers.
+func seatOffers(ctx context.Context, bookings domain.BookingRepository, airplanes domain.AirplaneRepository, sched domain.FlightSchedule, candidates []domain.FlightSchedule) ([]domain.SeatOffer, error) {
+	var offers []domain.SeatOffer
+	seats := make(map[string]int)
+	for _, f := range candidates {
+		if len(offers) == maxSeatOffers {
+			break
+		}
+		if f.ID == sched.ID || f.Cancelled() || !f.DepartureAt.After(sched.DepartureAt) {
+			continue
+		}
+		if _, ok := seats[f.AirplaneCode]; !ok {
+			plane, err := airplanes.GetByCode(ctx, f.AirplaneCode)
+			if err != nil {
+				return nil, err
+			}
+			seats[f.AirplaneCode] = plane.SeatCapacity
+		}
+		taken, err := bookings.CountBySchedule(ctx, f.ID)
+		if err != nil {
+			return nil, err
+		}
+		if left := seats[f.AirplaneCode] - taken; left > 0 {
+			offers = append(offers, domain.SeatOffer{Schedule: f, SeatsLeft: left})
+		}
+	}
+	return offers, nil
+}
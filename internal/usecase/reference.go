@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// maxReferenceAttempts bounds how many candidates are tried before giving up.
+const maxReferenceAttempts = 10
+
+// ReferenceGenerator produces booking record locators that are not yet in use.
+type ReferenceGenerator struct {
+	bookings domain.BookingRepository
+	format   domain.ReferenceFormat
+	random   io.Reader
+}
+
+// NewReferenceGenerator builds a generator for the given format, checking uniqueness against bookings.
+func NewReferenceGenerator(bookings domain.BookingRepository, format domain.ReferenceFormat) (*ReferenceGenerator, error) {
+	format.Normalize()
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+	return &ReferenceGenerator{bookings: bookings, format: format, random: rand.Reader}, nil
+}
+
+// Generate returns a fresh reference, retrying when a candidate already belongs to a booking.
+func (g *ReferenceGenerator) Generate(ctx context.Context) (string, error) {
+	for attempt := 0; attempt < maxReferenceAttempts; attempt++ {
+		candidate, err := g.candidate()
+		if err != nil {
+			return "", err
+		}
+		_, err = g.bookings.GetByReference(ctx, candidate)
+		if errors.Is(err, domain.ErrBookingNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", domain.ErrReferenceExhausted
+}
+
+func (g *ReferenceGenerator) candidate() (string, error) {
+	var b strings.Builder
+	b.WriteString(g.format.Prefix)
+	size := big.NewInt(int64(len(g.format.Alphabet)))
+	for i := 0; i < g.format.Length; i++ {
+		n, err := rand.Int(g.random, size)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(g.format.Alphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// conflictingBookingRepo reports every reference in taken as already booked
+// and can fail the first few inserts with a reference collision.
+type conflictingBookingRepo struct {
+	mockBookingRepo
+	taken       map[string]bool
+	failInserts int
+	inserts     int
+	lookupErr   error
+}
+
+func (r *conflictingBookingRepo) GetByReference(ctx context.Context, reference string) (*domain.Booking, error) {
+	if r.lookupErr != nil {
+		return nil, r.lookupErr
+	}
+	if r.taken[reference] {
+		return &domain.Booking{Reference: reference}, nil
+	}
+	return nil, domain.ErrBookingNotFound
+}
+
+func (r *conflictingBookingRepo) Create(ctx context.Context, b *domain.Booking) error {
+	r.inserts++
+	if r.inserts <= r.failInserts {
+		return domain.ErrBookingExists
+	}
+	return r.mockBookingRepo.Create(ctx, b)
+}
+
+func TestReferenceGenerator_RetriesOnConflict(t *testing.T) {
+	repo := &conflictingBookingRepo{taken: map[string]bool{"222222": true}}
+	g, err := NewReferenceGenerator(repo, domain.ReferenceFormat{Length: 6})
+	if err != nil {
+		t.Fatalf("new generator: %v", err)
+	}
+	// The first candidate decodes to "222222" (taken), the second to "333333".
+	g.random = bytes.NewReader(append(bytes.Repeat([]byte{0}, 6), bytes.Repeat([]byte{1}, 6)...))
+	ref, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if ref != "333333" {
+		t.Fatalf("expected retry to yield 333333, got %s", ref)
+	}
+}
+
+func TestReferenceGenerator_FormatAndErrors(t *testing.T) {
+	repo := &conflictingBookingRepo{}
+	g, err := NewReferenceGenerator(repo, domain.ReferenceFormat{Prefix: "fb", Length: 8})
+	if err != nil {
+		t.Fatalf("new generator: %v", err)
+	}
+	ref, err := g.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if len(ref) != 10 || !strings.HasPrefix(ref, "FB") {
+		t.Fatalf("unexpected reference %q", ref)
+	}
+	for _, c := range ref[2:] {
+		if !strings.ContainsRune(domain.LocatorAlphabet, c) {
+			t.Fatalf("reference %q uses character outside alphabet", ref)
+		}
+	}
+
+	if _, err := NewReferenceGenerator(repo, domain.ReferenceFormat{Length: 3}); err != domain.ErrInvalidReferenceFormat {
+		t.Fatalf("want invalid format, got %v", err)
+	}
+
+	repo.lookupErr = errors.New("db down")
+	if _, err := g.Generate(context.Background()); err == nil || err.Error() != "db down" {
+		t.Fatalf("want lookup error surfaced, got %v", err)
+	}
+
+	repo.lookupErr = nil
+	g.random = bytes.NewReader(make([]byte, maxReferenceAttempts*8))
+	repo.taken = map[string]bool{"FB22222222": true}
+	if _, err := g.Generate(context.Background()); err != domain.ErrReferenceExhausted {
+		t.Fatalf("want exhausted, got %v", err)
+	}
+}
+
+func TestBookingUsecase_Create_RetriesReferenceCollision(t *testing.T) {
+	repo := &conflictingBookingRepo{failInserts: 2}
+	scheduleRepo := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-01"},
+	}}
+	airplaneRepo := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 10}}}
+	uc := NewBookingUsecase(repo, scheduleRepo, &mockRouteRepo{}, airplaneRepo)
+
+	booking, err := uc.Create(context.Background(), 1, "Alice")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if repo.inserts != 3 || len(booking.Reference) != 6 {
+		t.Fatalf("expected third insert to succeed with a locator, inserts=%d ref=%q", repo.inserts, booking.Reference)
+	}
+
+	repo.inserts, repo.failInserts = 0, maxReferenceAttempts
+	if _, err := uc.Create(context.Background(), 1, "Bob"); err != domain.ErrReferenceExhausted {
+		t.Fatalf("want exhausted after repeated collisions, got %v", err)
+	}
+}
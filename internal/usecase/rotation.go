@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// defaultMinTurnaround is the ground time between flights when none is configured.
+const defaultMinTurnaround = 30 * time.Minute
+
+// RotationReport lists an airplane's flights in departure order and every
+// break in their chaining.
+type RotationReport struct {
+	AirplaneCode  string
+	MinTurnaround time.Duration
+	Legs          []domain.RotationLeg
+	Issues        []domain.RotationIssue
+}
+
+// WithMinTurnaround sets the ground time an airplane needs between flights.
+func (u *ScheduleUsecase) WithMinTurnaround(d time.Duration) *ScheduleUsecase {
+	u.guard.minTurnaround = d
+	return u
+}
+
+// WithStrictRotation makes Create and SwapAirplane reject flights that break
+// their airplane's rotation with a *domain.RotationError.
+func (u *ScheduleUsecase) WithStrictRotation(strict bool) *ScheduleUsecase {
+	u.guard.strictRotation = strict
+	return u
+}
+
+// WithRotation lets the series read airplanes' flights from schedules and,
+// when strict, makes Create, Extend and Amend reject series whose flights
+// break their airplane's rotation with a *domain.RotationError.
+func (u *SeriesUsecase) WithRotation(schedules domain.FlightScheduleRepository, minTurnaround time.Duration, strict bool) *SeriesUsecase {
+	u.guard.schedules, u.guard.minTurnaround, u.guard.strictRotation = schedules, minTurnaround, strict
+	return u
+}
+
+// ValidateRotation checks that an airplane's flights chain: each departs from
+// where the previous one landed, after at least the minimum turnaround.
+func (u *ScheduleUsecase) ValidateRotation(ctx context.Context, airplaneCode string) (*RotationReport, error) {
+	airplaneCode = strings.ToUpper(strings.TrimSpace(airplaneCode))
+	if airplaneCode == "" || len(airplaneCode) > 16 {
+		return nil, domain.ErrInvalidScheduleAirplane
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	if _, err := u.airplanes.GetByCode(ctx, airplaneCode); err != nil {
+		return nil, err
+	}
+	legs, err := u.guard.rotationLegs(ctx, airplaneCode, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &RotationReport{
+		AirplaneCode:  airplaneCode,
+		MinTurnaround: u.guard.minTurnaround,
+		Legs:          legs,
+		Issues:        domain.CheckRotation(legs, u.guard.minTurnaround),
+	}, nil
+}
+
+// checkRotation fails, with strict rotation, with a *domain.RotationError
+// when the not yet stored flights in changed break the rotation of the
+// airplane: when its flights with changed applied have breaks they did not
+// have before. Breaks the airplane already had are left to ValidateRotation.
+func (g *airplaneGuard) checkRotation(ctx context.Context, airplaneCode string, changed []domain.FlightSchedule) error {
+	if !g.strictRotation || g.schedules == nil {
+		return nil
+	}
+	stored, err := g.airplaneFlights(ctx, airplaneCode)
+	if err != nil {
+		return err
+	}
+	return g.rotationBreaks(ctx, airplaneCode, stored, changed)
+}
+
+// checkRotations is checkRotation for changed flights that may be flown by
+// different airplanes, such as a series' flights after a swap: each airplane's
+// rotation is checked with the changed flights it now flies.
+func (g *airplaneGuard) checkRotations(ctx context.Context, changed []domain.FlightSchedule) error {
+	seen := make(map[string]bool)
+	for _, c := range changed {
+		if seen[c.AirplaneCode] || c.Cancelled() {
+			continue
+		}
+		seen[c.AirplaneCode] = true
+		if err := g.checkRotation(ctx, c.AirplaneCode, changed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSeriesRotation is checkRotation for the new flights of a series,
+// leaving out those on the route and date of a stored flight of the
+// airplane, which the series repository skips.
+func (g *airplaneGuard) checkSeriesRotation(ctx context.Context, airplaneCode string, flights []domain.FlightSchedule) error {
+	if !g.strictRotation || g.schedules == nil {
+		return nil
+	}
+	stored, err := g.airplaneFlights(ctx, airplaneCode)
+	if err != nil {
+		return err
+	}
+	scheduled := make(map[string]bool)
+	for _, s := range stored {
+		scheduled[s.RouteCode+" "+s.DepartureDate] = true
+	}
+	var added []domain.FlightSchedule
+	for _, f := range flights {
+		if !scheduled[f.RouteCode+" "+f.DepartureDate] {
+			added = append(added, f)
+		}
+	}
+	return g.rotationBreaks(ctx, airplaneCode, stored, added)
+}
+
+// rotationBreaks fails with a *domain.RotationError listing the breaks that
+// applying changed to the airplane's stored flights introduces.
+func (g *airplaneGuard) rotationBreaks(ctx context.Context, airplaneCode string, stored, changed []domain.FlightSchedule) error {
+	before, err := g.legs(ctx, stored)
+	if err != nil {
+		return err
+	}
+	after, err := g.legs(ctx, applyChanges(stored, changed, airplaneCode))
+	if err != nil {
+		return err
+	}
+	type issueKey struct {
+		kind           string
+		previous, next int64
+	}
+	known := make(map[issueKey]bool)
+	for _, issue := range domain.CheckRotation(before, g.minTurnaround) {
+		known[issueKey{issue.Kind, issue.Previous.Schedule.ID, issue.Next.Schedule.ID}] = true
+	}
+	var issues []domain.RotationIssue
+	for _, issue := range domain.CheckRotation(after, g.minTurnaround) {
+		if !known[issueKey{issue.Kind, issue.Previous.Schedule.ID, issue.Next.Schedule.ID}] {
+			issues = append(issues, issue)
+		}
+	}
+	if len(issues) > 0 {
+		return &domain.RotationError{AirplaneCode: airplaneCode, Issues: issues}
+	}
+	return nil
+}
+
+// applyChanges returns the airplane's stored flights with changed applied: a
+// changed flight replaces the stored one with its ID, or is added when new.
+// Changed flights now flown by another airplane or cancelled drop out.
+func applyChanges(stored, changed []domain.FlightSchedule, airplaneCode string) []domain.FlightSchedule {
+	replaced := make(map[int64]bool)
+	for _, c := range changed {
+		if c.ID != 0 {
+			replaced[c.ID] = true
+		}
+	}
+	var out []domain.FlightSchedule
+	for _, s := range stored {
+		if !replaced[s.ID] {
+			out = append(out, s)
+		}
+	}
+	for _, c := range changed {
+		if c.AirplaneCode == airplaneCode && !c.Cancelled() {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// rotationLegs loads an airplane's flights with their airports, with changed
+// applied as by applyChanges, ordered by departure.
+func (g *airplaneGuard) rotationLegs(ctx context.Context, airplaneCode string, changed []domain.FlightSchedule) ([]domain.RotationLeg, error) {
+	stored, err := g.airplaneFlights(ctx, airplaneCode)
+	if err != nil {
+		return nil, err
+	}
+	return g.legs(ctx, applyChanges(stored, changed, airplaneCode))
+}
+
+// airplaneFlights loads an airplane's stored flights. Cancelled flights are
+// left out: the airplane does not fly them.
+func (g *airplaneGuard) airplaneFlights(ctx context.Context, airplaneCode string) ([]domain.FlightSchedule, error) {
+	var schedules []domain.FlightSchedule
+	for offset := 0; ; offset += pageSize {
+		page, err := g.schedules.ListByAirplane(ctx, airplaneCode, pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range page {
+			if !s.Cancelled() {
+				schedules = append(schedules, s)
+			}
+		}
+		if len(page) < pageSize {
+			return schedules, nil
+		}
+	}
+}
+
+// legs looks up the airports of each flight and orders them by departure.
+func (g *airplaneGuard) legs(ctx context.Context, schedules []domain.FlightSchedule) ([]domain.RotationLeg, error) {
+	routes := make(map[string]*domain.Route)
+	legs := make([]domain.RotationLeg, 0, len(schedules))
+	for _, s := range schedules {
+		route, ok := routes[s.RouteCode]
+		if !ok {
+			r, err := g.routes.GetByCode(ctx, s.RouteCode)
+			if err != nil {
+				return nil, err
+			}
+			route = r
+			routes[s.RouteCode] = r
+		}
+		legs = append(legs, domain.RotationLeg{Schedule: s, OriginCode: route.OriginCode, DestinationCode: route.DestinationCode})
+	}
+	sort.SliceStable(legs, func(i, j int) bool {
+		a, b := legs[i].Schedule, legs[j].Schedule
+		if !a.DepartureAt.Equal(b.DepartureAt) {
+			return a.DepartureAt.Before(b.DepartureAt)
+		}
+		return a.ID < b.ID
+	})
+	return legs, nil
+}
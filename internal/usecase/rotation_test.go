@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func newRotationRoutes() *fakeRouteRepo {
+	return &fakeRouteRepo{items: map[string]domain.Route{
+		"CGK-DPS": {Code: "CGK-DPS", OriginCode: "CGK", DestinationCode: "DPS"},
+		"DPS-CGK": {Code: "DPS-CGK", OriginCode: "DPS", DestinationCode: "CGK"},
+	}}
+}
+
+func TestScheduleUsecase_ValidateRotation(t *testing.T) {
+	t0 := time.Date(2025, 1, 2, 1, 0, 0, 0, time.UTC)
+	repo := &fakeScheduleRepo{items: []domain.FlightSchedule{
+		{ID: 2, RouteCode: "DPS-CGK", AirplaneCode: "A320", DepartureAt: t0.Add(2*time.Hour + 10*time.Minute), ArrivalAt: t0.Add(4 * time.Hour)},
+		{ID: 1, RouteCode: "CGK-DPS", AirplaneCode: "A320", DepartureAt: t0, ArrivalAt: t0.Add(2 * time.Hour)},
+		{ID: 3, RouteCode: "DPS-CGK", AirplaneCode: "A320", DepartureAt: t0.Add(6 * time.Hour)},
+		{ID: 4, RouteCode: "CGK-DPS", AirplaneCode: "B737", DepartureAt: t0},
+	}}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true}}
+	uc := NewScheduleUsecase(repo, newRotationRoutes(), planes, newSchedAirports())
+
+	report, err := uc.ValidateRotation(context.Background(), " a320 ")
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(report.Legs) != 3 || report.Legs[0].Schedule.ID != 1 || report.Legs[2].Schedule.ID != 3 || report.MinTurnaround != 30*time.Minute {
+		t.Fatalf("unexpected legs %+v", report)
+	}
+	if len(report.Issues) != 2 || report.Issues[0].Kind != domain.RotationTurnaround || report.Issues[1].Kind != domain.RotationPositioning {
+		t.Fatalf("unexpected issues %+v", report.Issues)
+	}
+	if report, err := uc.WithMinTurnaround(10*time.Minute).ValidateRotation(context.Background(), "A320"); err != nil || len(report.Issues) != 1 {
+		t.Fatalf("want only the positioning issue with a shorter turnaround, got %+v err=%v", report, err)
+	}
+	if _, err := uc.ValidateRotation(context.Background(), "B777"); err != domain.ErrAirplaneNotFound {
+		t.Fatalf("want airplane not found, got %v", err)
+	}
+	if _, err := uc.ValidateRotation(context.Background(), " "); err != domain.ErrInvalidScheduleAirplane {
+		t.Fatalf("want invalid airplane, got %v", err)
+	}
+}
+
+func TestScheduleUsecase_CreateStrictRotation(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true}}
+	uc := NewScheduleUsecase(repo, newRotationRoutes(), planes, newSchedAirports()).WithStrictRotation(true)
+
+	if _, err := uc.Create(context.Background(), "CGK-DPS", "A320", "2025-01-02", "08:00", "11:00"); err != nil {
+		t.Fatalf("first flight: %v", err)
+	}
+	// The first flight lands at 11:00 in Denpasar; leaving at 11:15 is inside the turnaround.
+	_, err := uc.Create(context.Background(), "DPS-CGK", "A320", "2025-01-02", "11:15", "")
+	var rot *domain.RotationError
+	if !errors.As(err, &rot) || !errors.Is(err, domain.ErrRotationConflict) || len(rot.Issues) != 1 || rot.Issues[0].Kind != domain.RotationTurnaround {
+		t.Fatalf("want turnaround refusal, got %v", err)
+	}
+	if _, err := uc.Create(context.Background(), "CGK-DPS", "A320", "2025-01-02", "14:00", ""); !errors.Is(err, domain.ErrRotationConflict) {
+		t.Fatalf("want positioning refusal, got %v", err)
+	}
+	if _, err := uc.Create(context.Background(), "DPS-CGK", "A320", "2025-01-02", "12:00", ""); err != nil {
+		t.Fatalf("chained flight: %v", err)
+	}
+	if len(repo.items) != 2 {
+		t.Fatalf("want refused flights not stored, got %d", len(repo.items))
+	}
+	if _, err := uc.WithStrictRotation(false).Create(context.Background(), "CGK-DPS", "A320", "2025-01-02", "09:00", ""); err != nil {
+		t.Fatalf("lenient create: %v", err)
+	}
+}
+
+func TestScheduleUsecase_SwapStrictRotation(t *testing.T) {
+	t0 := time.Date(2025, 1, 2, 1, 0, 0, 0, time.UTC)
+	repo := &fakeScheduleRepo{items: []domain.FlightSchedule{
+		{ID: 1, RouteCode: "CGK-DPS", AirplaneCode: "A320", DepartureAt: t0, ArrivalAt: t0.Add(2 * time.Hour)},
+		{ID: 2, RouteCode: "DPS-CGK", AirplaneCode: "B737", DepartureAt: t0.Add(2*time.Hour + 10*time.Minute)},
+	}}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true, "B737": true}}
+	uc := NewScheduleUsecase(repo, newRotationRoutes(), planes, newSchedAirports()).WithStrictRotation(true)
+
+	// A320 lands at 03:00 UTC and would leave again ten minutes later.
+	var rot *domain.RotationError
+	if _, err := uc.SwapAirplane(context.Background(), 2, "A320"); !errors.As(err, &rot) || rot.AirplaneCode != "A320" || rot.Issues[0].Kind != domain.RotationTurnaround {
+		t.Fatalf("want turnaround refusal, got %v", err)
+	}
+	if repo.items[1].AirplaneCode != "B737" {
+		t.Fatalf("a refused swap must keep the airplane, got %+v", repo.items[1])
+	}
+	if _, err := uc.WithStrictRotation(false).SwapAirplane(context.Background(), 2, "A320"); err != nil {
+		t.Fatalf("lenient swap: %v", err)
+	}
+}
+
+func TestSeriesUsecase_StrictRotation(t *testing.T) {
+	// A320 flies CGK 08:00 -> DPS 11:00 local on 2025-01-01.
+	schedules := &fakeScheduleRepo{items: []domain.FlightSchedule{
+		{ID: 100, RouteCode: "CGK-DPS", AirplaneCode: "A320", DepartureDate: "2025-01-01", DepartureAt: time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC), ArrivalAt: time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)},
+	}}
+	repo := &fakeSeriesRepo{}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true}}
+	uc := NewSeriesUsecase(repo, newRotationRoutes(), planes, newSchedAirports()).WithRotation(schedules, 30*time.Minute, true)
+	uc.now = func() time.Time { return time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC) }
+
+	var rot *domain.RotationError
+	if _, err := uc.Create(context.Background(), "DPS-CGK", "A320", "2025-01-01", "2025-01-01", "daily", "11:15", ""); !errors.As(err, &rot) || rot.Issues[0].Kind != domain.RotationTurnaround {
+		t.Fatalf("want turnaround refusal, got %v", err)
+	}
+	// The stored flight is skipped rather than reported as overlapping itself.
+	if _, err := uc.Create(context.Background(), "CGK-DPS", "A320", "2025-01-01", "2025-01-01", "daily", "08:00", ""); err != nil {
+		t.Fatalf("series over a stored flight: %v", err)
+	}
+	res, err := uc.Create(context.Background(), "DPS-CGK", "A320", "2025-01-01", "2025-01-01", "daily", "12:00", "")
+	if err != nil {
+		t.Fatalf("chained series: %v", err)
+	}
+	schedules.items = append(schedules.items, res.Created...)
+
+	if _, err := uc.Amend(context.Background(), res.Series.ID, "11:10", ""); !errors.Is(err, domain.ErrRotationConflict) {
+		t.Fatalf("want amendment refused, got %v", err)
+	}
+	// On 2025-01-02 the airplane is still in Jakarta, not Denpasar.
+	if _, err := uc.Extend(context.Background(), res.Series.ID, "2025-01-02"); !errors.As(err, &rot) || rot.Issues[0].Kind != domain.RotationPositioning {
+		t.Fatalf("want extension refused, got %v", err)
+	}
+	if got := repo.flights[len(repo.flights)-1].LocalDeparture().Format("15:04"); len(repo.flights) != 2 || got != "12:00" {
+		t.Fatalf("refused changes must leave the flights alone, got %+v", repo.flights)
+	}
+}
+
+func TestTimetableUsecase_ImportStrictRotation(t *testing.T) {
+	schedules := &fakeScheduleRepo{items: []domain.FlightSchedule{
+		{ID: 100, RouteCode: "CGK-DPS", AirplaneCode: "PK-GQA", DepartureDate: "2025-01-01", DepartureAt: time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC), ArrivalAt: time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)},
+	}}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"PK-GQA": true}, types: map[string]string{"PK-GQA": "320"}}
+	series := NewSeriesUsecase(&fakeSeriesRepo{}, newRotationRoutes(), planes, newSchedAirports()).WithRotation(schedules, 30*time.Minute, true)
+	uc := NewTimetableUsecase(series, schedules)
+
+	res, err := uc.Import(context.Background(), []TimetableEntry{
+		{Line: 3, OriginCode: "DPS", DestinationCode: "CGK", AircraftType: "320", Series: domain.ScheduleSeries{StartDate: "2025-01-01", EndDate: "2025-01-01", Days: domain.AllWeekdays, DepartureTime: "10:15"}},
+	})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(res.Series) != 0 || len(res.Errors) != 1 || !errors.Is(res.Errors[0], domain.ErrRotationConflict) {
+		t.Fatalf("want the line leaving before the airplane lands rejected, got %+v", res)
+	}
+}
+
+func TestSeriesUsecase_AmendSwappedFlightRotation(t *testing.T) {
+	// B737 flies CGK 08:00 -> DPS 11:00 local on 2025-01-01.
+	schedules := &fakeScheduleRepo{items: []domain.FlightSchedule{
+		{ID: 100, RouteCode: "CGK-DPS", AirplaneCode: "B737", DepartureDate: "2025-01-01", DepartureAt: time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC), ArrivalAt: time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)},
+	}}
+	repo := &fakeSeriesRepo{}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true, "B737": true}}
+	uc := NewSeriesUsecase(repo, newRotationRoutes(), planes, newSchedAirports()).WithRotation(schedules, 30*time.Minute, true)
+	uc.now = func() time.Time { return time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC) }
+
+	res, err := uc.Create(context.Background(), "DPS-CGK", "A320", "2025-01-01", "2025-01-01", "daily", "12:00", "")
+	if err != nil || len(res.Created) != 1 {
+		t.Fatalf("create: %+v err=%v", res, err)
+	}
+	// The series' flight was swapped onto the B737, which lands just before it.
+	repo.flights[0].AirplaneCode = "B737"
+	schedules.items = append(schedules.items, repo.flights[0])
+
+	var rot *domain.RotationError
+	if _, err := uc.Amend(context.Background(), res.Series.ID, "11:10", ""); !errors.As(err, &rot) || rot.AirplaneCode != "B737" || rot.Issues[0].Kind != domain.RotationTurnaround {
+		t.Fatalf("want the swapped flight refused on the B737 rotation, got %v", err)
+	}
+	if moved, err := uc.Amend(context.Background(), res.Series.ID, "11:40", ""); err != nil || len(moved) != 1 || moved[0].AirplaneCode != "B737" {
+		t.Fatalf("want the amendment allowed after the turnaround, got %+v err=%v", moved, err)
+	}
+}
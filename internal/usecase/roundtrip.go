@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// RoundTripOption pairs an outbound direct flight with a return that departs after it lands.
+type RoundTripOption struct {
+	Outbound FlightOption
+	Return   FlightOption
+	Fare     domain.Money // round-trip price; zero when either direction is unpriced
+}
+
+// SearchRoundTrips pairs direct flights from origin to destination on the
+// departure date with flights back on the return date.
+func (u *BookingUsecase) SearchRoundTrips(ctx context.Context, originCode, destinationCode, departureDate, returnDate string) ([]RoundTripOption, error) {
+	return u.FindRoundTrips(ctx, SearchQuery{OriginCode: originCode, DestinationCode: destinationCode, DepartureDate: departureDate, ReturnDate: returnDate})
+}
+
+// FindRoundTrips pairs direct outbound flights on q.DepartureDate with flights
+// back on q.ReturnDate, both widened by q.FlexDays. Only pairs whose return
+// departs after the outbound arrives are offered, earliest outbound first unless
+// another sort key is given. Passengers and airplane apply to both directions,
+// the time-of-day window to the outbound flight.
+func (u *BookingUsecase) FindRoundTrips(ctx context.Context, q SearchQuery) ([]RoundTripOption, error) {
+	departureDate, returnDate := strings.TrimSpace(q.DepartureDate), strings.TrimSpace(q.ReturnDate)
+	departs, err := time.Parse("2006-01-02", departureDate)
+	if err != nil {
+		return nil, domain.ErrInvalidScheduleDate
+	}
+	returns, err := time.Parse("2006-01-02", returnDate)
+	if err != nil {
+		return nil, domain.ErrInvalidScheduleDate
+	}
+	if returns.Before(departs) {
+		return nil, domain.ErrInvalidReturnDate
+	}
+	c, err := q.criteria()
+	if err != nil {
+		return nil, err
+	}
+
+	outboundQuery := q
+	outboundQuery.SortBy = ""
+	outbound, err := u.FindDirectFlights(ctx, outboundQuery)
+	if err != nil {
+		return nil, err
+	}
+	if len(outbound) == 0 {
+		return nil, nil
+	}
+	inboundQuery := outboundQuery
+	inboundQuery.OriginCode, inboundQuery.DestinationCode = q.DestinationCode, q.OriginCode
+	inboundQuery.DepartureDate = returnDate
+	inboundQuery.DepartAfter, inboundQuery.DepartBefore = "", ""
+	inbound, err := u.FindDirectFlights(ctx, inboundQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []RoundTripOption
+	for _, out := range outbound {
+		for _, back := range inbound {
+			if !returnsAfter(out.DepartureDate, out.DepartureTime, out.ArrivalTime, back.DepartureDate, back.DepartureTime) {
+				continue
+			}
+			options = append(options, RoundTripOption{Outbound: out, Return: back, Fare: domain.RoundTripPrice(out.Fare, back.Fare)})
+		}
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if !a.Outbound.DepartureTime.Equal(b.Outbound.DepartureTime) {
+			return a.Outbound.DepartureTime.Before(b.Outbound.DepartureTime)
+		}
+		return a.Return.DepartureTime.Before(b.Return.DepartureTime)
+	})
+	if c.sortBy != "" {
+		sort.SliceStable(options, c.lessBy(func(i int) journey { return roundTripJourney(options[i]) }))
+	}
+	return options, nil
+}
+
+// returnsAfter reports whether a return departing at back leaves after the
+// outbound flight lands. Without a planned arrival the outbound departure is used;
+// without times only the dates are compared.
+func returnsAfter(outDate string, outDeparts, outArrives time.Time, backDate string, backDeparts time.Time) bool {
+	if backDate < outDate {
+		return false
+	}
+	lands := outArrives
+	if lands.IsZero() {
+		lands = outDeparts
+	}
+	if lands.IsZero() || backDeparts.IsZero() {
+		return true
+	}
+	return backDeparts.After(lands)
+}
+
+// BookRoundTrip books a passenger on an outbound flight and the return flight
+// back as one trip: either both seats are taken or neither is. The trip is
+// priced with the round-trip fare of the outbound route when one is published.
+func (u *BookingUsecase) BookRoundTrip(ctx context.Context, outboundID, returnID int64, passengerName string) (*domain.Trip, error) {
+	if outboundID <= 0 || returnID <= 0 || outboundID == returnID {
+		return nil, domain.ErrInvalidScheduleID
+	}
+	passengerName = strings.TrimSpace(passengerName)
+	if len(passengerName) == 0 {
+		return nil, domain.ErrInvalidPassengerName
+	}
+	if u.trips == nil {
+		return nil, domain.ErrTripsNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	out, err := u.schedules.GetByID(ctx, outboundID)
+	if err != nil {
+		return nil, err
+	}
+	back, err := u.schedules.GetByID(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+	outRoute, err := u.routes.GetByCode(ctx, out.RouteCode)
+	if err != nil {
+		return nil, err
+	}
+	backRoute, err := u.routes.GetByCode(ctx, back.RouteCode)
+	if err != nil {
+		return nil, err
+	}
+	if outRoute.OriginCode != backRoute.DestinationCode || outRoute.DestinationCode != backRoute.OriginCode {
+		return nil, domain.ErrInvalidRoundTrip
+	}
+	if !returnsAfter(out.DepartureDate, out.DepartureAt, out.ArrivalAt, back.DepartureDate, back.DepartureAt) {
+		return nil, domain.ErrInvalidReturnDate
+	}
+
+	trip := &domain.Trip{Kind: domain.TripRoundTrip, PassengerName: passengerName}
+	for _, sched := range []*domain.FlightSchedule{out, back} {
+		b, err := u.newBooking(ctx, sched, passengerName, domain.BookingStatusConfirmed)
+		if err != nil {
+			return nil, err
+		}
+		trip.Bookings = append(trip.Bookings, *b)
+	}
+	if trip.Fare, err = u.roundTripFare(ctx, out, back); err != nil {
+		return nil, err
+	}
+	if err := u.createTrip(ctx, trip); err != nil {
+		return nil, err
+	}
+	return trip, nil
+}
+
+// createTrip stores a trip with a fresh reference for each booking, retrying
+// on collisions, and tickets its bookings. A ticketing failure voids the trip.
+func (u *BookingUsecase) createTrip(ctx context.Context, trip *domain.Trip) error {
+	for attempt := 1; ; attempt++ {
+		for i := range trip.Bookings {
+			if err := u.assignReference(ctx, &trip.Bookings[i]); err != nil {
+				return err
+			}
+		}
+		if err := trip.Validate(); err != nil {
+			return err
+		}
+		err := u.trips.Create(ctx, trip)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, domain.ErrBookingExists) {
+			return err
+		}
+		if attempt >= maxReferenceAttempts {
+			return domain.ErrReferenceExhausted
+		}
+	}
+	if u.ticketing != nil {
+		for i := range trip.Bookings {
+			if _, err := u.ticketing.Issue(ctx, &trip.Bookings[i]); err != nil {
+				return u.voidTrip(ctx, trip, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// voidTrip undoes a trip whose ticketing failed at booking index failed: the
+// tickets already issued are refunded and every booking is cancelled, so the
+// trip holds no seats. It returns cause joined with any error undoing it.
+func (u *BookingUsecase) voidTrip(ctx context.Context, trip *domain.Trip, failed int, cause error) error {
+	var errs []error
+	for i := range trip.Bookings {
+		if i < failed {
+			if err := u.ticketing.refundOpen(context.WithoutCancel(ctx), trip.Bookings[i].ID); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := u.revertBooking(ctx, &trip.Bookings[i], domain.BookingStatusCancelled); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return cause
+	}
+	return errors.Join(append([]error{cause}, errs...)...)
+}
+
+// roundTripFare prices an outbound and return schedule from the cheapest fares
+// valid on their departure dates; it is zero when fares are not configured.
+func (u *BookingUsecase) roundTripFare(ctx context.Context, out, back *domain.FlightSchedule) (domain.Money, error) {
+	if u.fares == nil {
+		return domain.Money{}, nil
+	}
+	outFares, err := u.fares.List(ctx, out.RouteCode, 500, 0)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	backFares, err := u.fares.List(ctx, back.RouteCode, 500, 0)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	return domain.RoundTripPrice(domain.CheapestFare(outFares, out.DepartureDate), domain.CheapestFare(backFares, back.DepartureDate)), nil
+}
+
+// GetTrip fetches a trip with its bookings.
+func (u *BookingUsecase) GetTrip(ctx context.Context, id int64) (*domain.Trip, error) {
+	if id <= 0 {
+		return nil, domain.ErrInvalidTripID
+	}
+	if u.trips == nil {
+		return nil, domain.ErrTripsNotConfigured
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.trips.GetByID(ctx, id)
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+type fakeTripRepo struct {
+	trips     map[int64]*domain.Trip
+	createErr error
+	// connections lists the onward trip bookings out of each schedule.
+	connections map[int64][]domain.Connection
+	// bookings, when set, receives the bookings of created trips.
+	bookings *mockBookingRepo
+}
+
+func (f *fakeTripRepo) Create(ctx context.Context, t *domain.Trip) error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	if f.trips == nil {
+		f.trips = make(map[int64]*domain.Trip)
+	}
+	t.ID = int64(len(f.trips) + 1)
+	for i := range t.Bookings {
+		t.Bookings[i].ID = t.ID*10 + int64(i)
+		t.Bookings[i].TripID = t.ID
+		if f.bookings != nil {
+			b := t.Bookings[i]
+			f.bookings.bookings[b.Reference] = &b
+		}
+	}
+	f.trips[t.ID] = t
+	return nil
+}
+
+func (f *fakeTripRepo) GetByID(ctx context.Context, id int64) (*domain.Trip, error) {
+	t, ok := f.trips[id]
+	if !ok {
+		return nil, domain.ErrTripNotFound
+	}
+	return t, nil
+}
+
+func (f *fakeTripRepo) Connections(ctx context.Context, scheduleID int64) ([]domain.Connection, error) {
+	return f.connections[scheduleID], nil
+}
+
+func TestBookingUsecase_SearchRoundTrips(t *testing.T) {
+	day := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	out := domain.FlightAvailability{
+		Schedule:   domain.FlightSchedule{ID: 1, DepartureDate: "2025-03-15", DepartureAt: day.Add(8 * time.Hour), ArrivalAt: day.Add(10 * time.Hour)},
+		OriginCode: "CGK", DestinationCode: "DPS", SeatCapacity: 2,
+		Fare: &domain.Fare{OneWay: domain.Money{Amount: 100, Currency: "USD"}, RoundTrip: domain.Money{Amount: 180, Currency: "USD"}},
+	}
+	sameDayEarly := domain.FlightAvailability{
+		Schedule:   domain.FlightSchedule{ID: 2, DepartureDate: "2025-03-15", DepartureAt: day.Add(9 * time.Hour)},
+		OriginCode: "DPS", DestinationCode: "CGK", SeatCapacity: 2,
+	}
+	sameDayLate := domain.FlightAvailability{
+		Schedule:   domain.FlightSchedule{ID: 3, DepartureDate: "2025-03-15", DepartureAt: day.Add(18 * time.Hour)},
+		OriginCode: "DPS", DestinationCode: "CGK", SeatCapacity: 2,
+	}
+	avail := &byOriginAvailability{rows: map[string][]domain.FlightAvailability{
+		"CGK": {out},
+		"DPS": {sameDayEarly, sameDayLate},
+	}}
+	uc := NewBookingUsecase(&mockBookingRepo{}, &mockScheduleRepo{}, &mockRouteRepo{}, &mockAirplaneRepo{}).WithAvailability(avail)
+
+	options, err := uc.SearchRoundTrips(context.Background(), "CGK", "DPS", "2025-03-15", "2025-03-15")
+	if err != nil || len(options) != 1 {
+		t.Fatalf("expected one pair, err=%v options=%+v", err, options)
+	}
+	if options[0].Return.ScheduleID != 3 || options[0].Fare != out.Fare.RoundTrip {
+		t.Fatalf("expected the late return at the round-trip fare, got %+v", options[0])
+	}
+
+	if _, err := uc.SearchRoundTrips(context.Background(), "CGK", "DPS", "2025-03-15", "2025-03-14"); err != domain.ErrInvalidReturnDate {
+		t.Fatalf("want invalid return date, got %v", err)
+	}
+	if _, err := uc.SearchRoundTrips(context.Background(), "CGK", "DPS", "", "2025-03-16"); err != domain.ErrInvalidScheduleDate {
+		t.Fatalf("want invalid date without an outbound date, got %v", err)
+	}
+}
+
+// byOriginAvailability answers searches with the rows departing from the queried origin.
+type byOriginAvailability struct {
+	rows map[string][]domain.FlightAvailability
+}
+
+func (b *byOriginAvailability) Search(ctx context.Context, q domain.AvailabilityQuery) ([]domain.FlightAvailability, error) {
+	return b.rows[q.OriginCode], nil
+}
+
+func (b *byOriginAvailability) RouteExists(ctx context.Context, origin, destination string) (bool, error) {
+	return true, nil
+}
+
+func TestBookingUsecase_BookRoundTrip(t *testing.T) {
+	day := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	schedules := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "OUT", AirplaneCode: "A320", DepartureDate: "2025-03-15", DepartureAt: day.Add(8 * time.Hour), ArrivalAt: day.Add(10 * time.Hour)},
+		2: {ID: 2, RouteCode: "BACK", AirplaneCode: "A320", DepartureDate: "2025-03-20", DepartureAt: day.Add(5*24*time.Hour + 9*time.Hour)},
+		3: {ID: 3, RouteCode: "BACK", AirplaneCode: "A320", DepartureDate: "2025-03-15", DepartureAt: day.Add(9 * time.Hour)},
+		4: {ID: 4, RouteCode: "OTHER", AirplaneCode: "A320", DepartureDate: "2025-03-20"},
+	}}
+	routes := &mockRouteRepo{routes: map[string]*domain.Route{
+		"OUT":   {Code: "OUT", OriginCode: "CGK", DestinationCode: "DPS"},
+		"BACK":  {Code: "BACK", OriginCode: "DPS", DestinationCode: "CGK"},
+		"OTHER": {Code: "OTHER", OriginCode: "DPS", DestinationCode: "SUB"},
+	}}
+	airplanes := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 2}}}
+	fares := &fakeFareRepo{items: []domain.Fare{
+		{ID: 1, RouteCode: "OUT", OneWay: domain.Money{Amount: 100, Currency: "USD"}},
+		{ID: 2, RouteCode: "BACK", OneWay: domain.Money{Amount: 120, Currency: "USD"}},
+	}}
+	trips := &fakeTripRepo{}
+	uc := NewBookingUsecase(&mockBookingRepo{}, schedules, routes, airplanes)
+	ctx := context.Background()
+
+	if _, err := uc.BookRoundTrip(ctx, 1, 2, "Alice"); err != domain.ErrTripsNotConfigured {
+		t.Fatalf("want trips not configured, got %v", err)
+	}
+	uc.WithTrips(trips).WithFares(fares)
+
+	trip, err := uc.BookRoundTrip(ctx, 1, 2, " Alice ")
+	if err != nil {
+		t.Fatalf("book round trip: %v", err)
+	}
+	if len(trip.Bookings) != 2 || trip.Bookings[0].ScheduleID != 1 || trip.Bookings[1].ScheduleID != 2 || trip.Bookings[0].Reference == trip.Bookings[1].Reference {
+		t.Fatalf("unexpected bookings %+v", trip.Bookings)
+	}
+	if trip.Kind != domain.TripRoundTrip {
+		t.Fatalf("expected a round trip, got %q", trip.Kind)
+	}
+	if trip.Fare != (domain.Money{Amount: 220, Currency: "USD"}) {
+		t.Fatalf("expected summed one-way fares, got %v", trip.Fare)
+	}
+	if got, err := uc.GetTrip(ctx, trip.ID); err != nil || got != trip {
+		t.Fatalf("get trip: err=%v trip=%+v", err, got)
+	}
+
+	cases := []struct {
+		out, back int64
+		name      string
+		want      error
+	}{
+		{1, 1, "Alice", domain.ErrInvalidScheduleID},
+		{1, 2, " ", domain.ErrInvalidPassengerName},
+		{1, 3, "Alice", domain.ErrInvalidReturnDate},
+		{1, 4, "Alice", domain.ErrInvalidRoundTrip},
+		{1, 9, "Alice", domain.ErrScheduleNotFound},
+	}
+	for _, tc := range cases {
+		if _, err := uc.BookRoundTrip(ctx, tc.out, tc.back, tc.name); err != tc.want {
+			t.Fatalf("BookRoundTrip(%d, %d, %q): want %v, got %v", tc.out, tc.back, tc.name, tc.want, err)
+		}
+	}
+	trips.createErr = domain.ErrFlightFull
+	if _, err := uc.BookRoundTrip(ctx, 1, 2, "Bob"); err != domain.ErrFlightFull {
+		t.Fatalf("want flight full, got %v", err)
+	}
+	if _, err := uc.GetTrip(ctx, 0); err != domain.ErrInvalidTripID {
+		t.Fatalf("want invalid trip id, got %v", err)
+	}
+}
+
+func TestBookingUsecase_BookRoundTrip_TicketingFailureVoidsTrip(t *testing.T) {
+	day := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	schedules := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "OUT", AirplaneCode: "A320", DepartureDate: "2025-03-15", DepartureAt: day.Add(8 * time.Hour), ArrivalAt: day.Add(10 * time.Hour)},
+		2: {ID: 2, RouteCode: "BACK", AirplaneCode: "A320", DepartureDate: "2025-03-20", DepartureAt: day.Add(5*24*time.Hour + 9*time.Hour)},
+	}}
+	routes := &mockRouteRepo{routes: map[string]*domain.Route{
+		"OUT":  {Code: "OUT", OriginCode: "CGK", DestinationCode: "DPS"},
+		"BACK": {Code: "BACK", OriginCode: "DPS", DestinationCode: "CGK"},
+	}}
+	airplanes := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 2}}}
+	bookings := &mockBookingRepo{bookings: map[string]*domain.Booking{}}
+	tickets := newFakeTicketRepo()
+	tickets.failAt = 2
+	uc := NewBookingUsecase(bookings, schedules, routes, airplanes).
+		WithTrips(&fakeTripRepo{bookings: bookings}).
+		WithTicketing(NewTicketUsecase(tickets, bookings, "126"))
+
+	if _, err := uc.BookRoundTrip(context.Background(), 1, 2, "Alice"); !errors.Is(err, errTicketing) {
+		t.Fatalf("want the ticketing error, got %v", err)
+	}
+	if len(bookings.bookings) != 2 {
+		t.Fatalf("expected both trip bookings stored, got %d", len(bookings.bookings))
+	}
+	for ref, b := range bookings.bookings {
+		if b.Status != domain.BookingStatusCancelled {
+			t.Fatalf("want booking %s cancelled, got %s", ref, b.Status)
+		}
+	}
+	for _, ticket := range tickets.tickets {
+		if ticket.Coupons[0].Status != domain.CouponStatusRefunded {
+			t.Fatalf("want the outbound ticket refunded, got %+v", ticket.Coupons)
+		}
+	}
+}
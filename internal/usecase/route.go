@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
@@ -9,17 +10,41 @@ import (
 
 // RouteUsecase orchestrates business rules around managing flight routes.
 type RouteUsecase struct {
-	routes   domain.RouteRepository
-	airports domain.AirportRepository
-	timeout  time.Duration
+	routes    domain.RouteRepository
+	airports  domain.AirportRepository
+	schedules domain.FlightScheduleRepository
+	bookings  domain.BookingRepository
+	airplanes domain.AirplaneRepository
+	timeout   time.Duration
+	now       func() time.Time
 }
 
 // NewRouteUsecase creates a new route usecase with sensible defaults.
 func NewRouteUsecase(routeRepo domain.RouteRepository, airportRepo domain.AirportRepository) *RouteUsecase {
-	return &RouteUsecase{routes: routeRepo, airports: airportRepo, timeout: 5 * time.Second}
+	return &RouteUsecase{routes: routeRepo, airports: airportRepo, timeout: 5 * time.Second, now: time.Now}
 }
 
-// Create stores a new route after validating the payload and ensuring airports exist.
+// WithReaccommodation lets forced updates offer other flights to the bookings
+// on the route's future flights.
+func (u *RouteUsecase) WithReaccommodation(schedules domain.FlightScheduleRepository, bookings domain.BookingRepository, airplanes domain.AirplaneRepository) *RouteUsecase {
+	u.schedules = schedules
+	u.bookings = bookings
+	u.airplanes = airplanes
+	return u
+}
+
+// RouteChange reports a route update: the route as stored and, when forced,
+// a reaccommodation plan per future flight whose bookings were for the old
+// airports.
+type RouteChange struct {
+	Route            *domain.Route
+	Previous         domain.Route
+	Reaccommodations []domain.Reaccommodation
+}
+
+// Create stores a new route after validating the payload and ensuring airports
+// exist and are not archived. The route's distance is the great-circle
+// distance between them.
 func (u *RouteUsecase) Create(ctx context.Context, code, origin, destination string) (*domain.Route, error) {
 	r := &domain.Route{Code: code, OriginCode: origin, DestinationCode: destination}
 	r.Normalize()
@@ -30,20 +55,129 @@ func (u *RouteUsecase) Create(ctx context.Context, code, origin, destination str
 	ctx, cancel := context.WithTimeout(ctx, u.timeout)
 	defer cancel()
 
-	if _, err := u.airports.GetByCode(ctx, r.OriginCode); err != nil {
+	from, err := activeAirport(ctx, u.airports, r.OriginCode)
+	if err != nil {
 		return nil, err
 	}
-	if _, err := u.airports.GetByCode(ctx, r.DestinationCode); err != nil {
+	to, err := activeAirport(ctx, u.airports, r.DestinationCode)
+	if err != nil {
 		return nil, err
 	}
 	if err := u.routes.Create(ctx, r); err != nil {
 		return nil, err
 	}
+	r.DistanceKm = domain.GreatCircleKm(from.Position, to.Position)
 	return r, nil
 }
 
-// List returns paginated routes sorted by code.
-func (u *RouteUsecase) List(ctx context.Context, limit, offset int) ([]domain.Route, error) {
+// SetBlockTime sets the route's scheduled block time, rounded to the minute;
+// 0 clears it.
+func (u *RouteUsecase) SetBlockTime(ctx context.Context, code string, block time.Duration) error {
+	r := domain.Route{Code: code, OriginCode: "X", DestinationCode: "Y", BlockMinutes: int(block.Round(time.Minute) / time.Minute)}
+	r.Normalize()
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.routes.SetBlockTime(ctx, r.Code, r.BlockMinutes)
+}
+
+// Update moves a route to another origin or destination; an empty code keeps
+// the current airport. It fails with a *domain.RouteBookingsError when future
+// flights of the route have bookings, unless reaccommodate is set: the route
+// then moves and every booking on those flights is reported with later
+// flights between the old airports that have seats.
+func (u *RouteUsecase) Update(ctx context.Context, code, origin, destination string, reaccommodate bool) (*RouteChange, error) {
+	r := &domain.Route{Code: code, OriginCode: origin, DestinationCode: destination}
+	r.Normalize()
+	if r.Code == "" || len(r.Code) > 16 {
+		return nil, domain.ErrInvalidRouteCode
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	current, err := u.routes.GetByCode(ctx, r.Code)
+	if err != nil {
+		return nil, err
+	}
+	if r.OriginCode == "" {
+		r.OriginCode = current.OriginCode
+	}
+	if r.DestinationCode == "" {
+		r.DestinationCode = current.DestinationCode
+	}
+	r.BlockMinutes = current.BlockMinutes
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	change := &RouteChange{Route: current, Previous: *current}
+	if r.OriginCode == current.OriginCode && r.DestinationCode == current.DestinationCode {
+		return change, nil
+	}
+	for _, airport := range []string{r.OriginCode, r.DestinationCode} {
+		if _, err := activeAirport(ctx, u.airports, airport); err != nil {
+			return nil, err
+		}
+	}
+	booked, err := u.routes.Update(ctx, r, u.now(), reaccommodate)
+	if err != nil {
+		return nil, err
+	}
+	if change.Route, err = u.routes.GetByCode(ctx, r.Code); err != nil {
+		return nil, err
+	}
+	if len(booked) == 0 || u.schedules == nil || u.bookings == nil || u.airplanes == nil {
+		return change, nil
+	}
+	alternatives, err := u.flightsBetween(ctx, current.OriginCode, current.DestinationCode, r.Code)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range booked {
+		plan := domain.Reaccommodation{Schedule: f.Schedule}
+		if plan.Displaced, err = activeBookings(ctx, u.bookings, f.Schedule.ID); err != nil {
+			return nil, err
+		}
+		if plan.Alternatives, err = seatOffers(ctx, u.bookings, u.airplanes, f.Schedule, alternatives); err != nil {
+			return nil, err
+		}
+		change.Reaccommodations = append(change.Reaccommodations, plan)
+	}
+	return change, nil
+}
+
+// flightsBetween lists, by departure, the flights of every route other than
+// except that links origin to destination.
+func (u *RouteUsecase) flightsBetween(ctx context.Context, origin, destination, except string) ([]domain.FlightSchedule, error) {
+	var flights []domain.FlightSchedule
+	for offset := 0; ; offset += pageSize {
+		routes, err := u.routes.List(ctx, pageSize, offset, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range routes {
+			if r.Code == except || r.OriginCode != origin || r.DestinationCode != destination {
+				continue
+			}
+			page, err := u.schedules.List(ctx, r.Code, pageSize, 0)
+			if err != nil {
+				return nil, err
+			}
+			flights = append(flights, page...)
+		}
+		if len(routes) < pageSize {
+			break
+		}
+	}
+	sort.SliceStable(flights, func(i, j int) bool { return flights[i].DepartureAt.Before(flights[j].DepartureAt) })
+	return flights, nil
+}
+
+// List returns paginated routes sorted by code; archived ones only with
+// includeArchived.
+func (u *RouteUsecase) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Route, error) {
 	if limit <= 0 || limit > 500 {
 		limit = 50
 	}
@@ -52,11 +186,21 @@ func (u *RouteUsecase) List(ctx context.Context, limit, offset int) ([]domain.Ro
 	}
 	ctx, cancel := context.WithTimeout(ctx, u.timeout)
 	defer cancel()
-	return u.routes.List(ctx, limit, offset)
+	return u.routes.List(ctx, limit, offset, includeArchived)
+}
+
+// Archive retires a route: it leaves the route list and takes no new
+// schedules or series, while its flights already scheduled keep flying.
+func (u *RouteUsecase) Archive(ctx context.Context, code string) error {
+	return u.setArchived(ctx, code, true)
 }
 
-// Delete removes a route by code.
-func (u *RouteUsecase) Delete(ctx context.Context, code string) error {
+// Restore brings an archived route back into use.
+func (u *RouteUsecase) Restore(ctx context.Context, code string) error {
+	return u.setArchived(ctx, code, false)
+}
+
+func (u *RouteUsecase) setArchived(ctx context.Context, code string, archived bool) error {
 	r := domain.Route{Code: code, OriginCode: "X", DestinationCode: "Y"}
 	r.Normalize()
 	if err := r.Validate(); err != nil {
@@ -64,5 +208,20 @@ func (u *RouteUsecase) Delete(ctx context.Context, code string) error {
 	}
 	ctx, cancel := context.WithTimeout(ctx, u.timeout)
 	defer cancel()
-	return u.routes.Delete(ctx, r.Code)
+	return u.routes.SetArchived(ctx, r.Code, archived)
+}
+
+// Delete permanently removes a route by code. While flights, series or fares use it, it
+// fails with a *domain.InUseError unless cascade holds the dependents it
+// reported, and with domain.ErrDependentsChanged when they differ; the result
+// counts what was removed along with it.
+func (u *RouteUsecase) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	r := domain.Route{Code: code, OriginCode: "X", DestinationCode: "Y"}
+	r.Normalize()
+	if err := r.Validate(); err != nil {
+		return domain.Dependents{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.routes.Delete(ctx, r.Code, cascade)
 }
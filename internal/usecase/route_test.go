@@ -2,13 +2,17 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
 )
 
 type fakeRouteRepo struct {
 	items     map[string]domain.Route
+	booked    []domain.BookedFlight
+	used      domain.Dependents
 	createErr error
 	deleteErr error
 }
@@ -31,7 +35,11 @@ func (f *fakeRouteRepo) GetByCode(ctx context.Context, code string) (*domain.Rou
 	return nil, domain.ErrRouteNotFound
 }
 
-func (f *fakeRouteRepo) List(ctx context.Context, limit, offset int) ([]domain.Route, error) {
+func (f *fakeRouteRepo) SetArchived(ctx context.Context, code string, archived bool) error {
+	return nil
+}
+
+func (f *fakeRouteRepo) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Route, error) {
 	var out []domain.Route
 	for _, r := range f.items {
 		out = append(out, r)
@@ -39,36 +47,84 @@ func (f *fakeRouteRepo) List(ctx context.Context, limit, offset int) ([]domain.R
 	return out, nil
 }
 
-func (f *fakeRouteRepo) Delete(ctx context.Context, code string) error {
+func (f *fakeRouteRepo) SetBlockTime(ctx context.Context, code string, minutes int) error {
+	r, ok := f.items[code]
+	if !ok {
+		return domain.ErrRouteNotFound
+	}
+	r.BlockMinutes = minutes
+	f.items[code] = r
+	return nil
+}
+
+func (f *fakeRouteRepo) Update(ctx context.Context, r *domain.Route, from time.Time, force bool) ([]domain.BookedFlight, error) {
+	current, ok := f.items[r.Code]
+	if !ok {
+		return nil, domain.ErrRouteNotFound
+	}
+	if len(f.booked) > 0 && !force {
+		return nil, &domain.RouteBookingsError{RouteCode: r.Code, Flights: f.booked}
+	}
+	current.OriginCode, current.DestinationCode = r.OriginCode, r.DestinationCode
+	f.items[r.Code] = current
+	return f.booked, nil
+}
+
+func (f *fakeRouteRepo) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
 	if f.deleteErr != nil {
-		return f.deleteErr
+		return domain.Dependents{}, f.deleteErr
 	}
 	if f.items == nil {
 		f.items = make(map[string]domain.Route)
 	}
 	if _, ok := f.items[code]; !ok {
-		return domain.ErrRouteNotFound
+		return domain.Dependents{}, domain.ErrRouteNotFound
+	}
+	if !f.used.Empty() && cascade == nil {
+		return f.used, &domain.InUseError{Entity: domain.EntityRoute, Key: code, Dependents: f.used}
 	}
 	delete(f.items, code)
-	return nil
+	return f.used, nil
 }
 
-type fakeAirportRepoRoute struct{ existing map[string]bool }
+type fakeAirportRepoRoute struct {
+	existing  map[string]bool
+	archived  map[string]bool
+	positions map[string]*domain.Coordinates
+}
 
 func (f *fakeAirportRepoRoute) Create(ctx context.Context, a *domain.Airport) error { return nil }
 func (f *fakeAirportRepoRoute) GetByCode(ctx context.Context, code string) (*domain.Airport, error) {
 	if f.existing != nil && f.existing[code] {
-		return &domain.Airport{Code: code}, nil
+		a := &domain.Airport{Code: code, Position: f.positions[code]}
+		if f.archived[code] {
+			a.ArchivedAt = "2025-01-01T00:00:00Z"
+		}
+		return a, nil
 	}
 	return nil, domain.ErrAirportNotFound
 }
-func (f *fakeAirportRepoRoute) List(ctx context.Context, limit, offset int) ([]domain.Airport, error) {
+func (f *fakeAirportRepoRoute) SetArchived(ctx context.Context, code string, archived bool) error {
+	return nil
+}
+func (f *fakeAirportRepoRoute) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airport, error) {
 	return nil, nil
 }
 func (f *fakeAirportRepoRoute) Update(ctx context.Context, code string, city string) error {
 	return nil
 }
-func (f *fakeAirportRepoRoute) Delete(ctx context.Context, code string) error { return nil }
+func (f *fakeAirportRepoRoute) SetTimeZone(ctx context.Context, code string, timeZone string) error {
+	return nil
+}
+func (f *fakeAirportRepoRoute) SetMinConnection(ctx context.Context, code string, minutes int) error {
+	return nil
+}
+func (f *fakeAirportRepoRoute) SetPosition(ctx context.Context, code string, position *domain.Coordinates) error {
+	return nil
+}
+func (f *fakeAirportRepoRoute) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	return domain.Dependents{}, nil
+}
 
 func TestRouteUsecase_Create_List_Delete(t *testing.T) {
 	rr := &fakeRouteRepo{items: make(map[string]domain.Route)}
@@ -77,11 +133,11 @@ func TestRouteUsecase_Create_List_Delete(t *testing.T) {
 	if _, err := uc.Create(context.Background(), " rt1 ", " cgk ", " dps "); err != nil {
 		t.Fatalf("create: %v", err)
 	}
-	items, err := uc.List(context.Background(), -1, -1)
+	items, err := uc.List(context.Background(), -1, -1, false)
 	if err != nil || len(items) != 1 {
 		t.Fatalf("list: err=%v len=%d", err, len(items))
 	}
-	if err := uc.Delete(context.Background(), "rt1"); err != nil {
+	if _, err := uc.Delete(context.Background(), "rt1", nil); err != nil {
 		t.Fatalf("delete: %v", err)
 	}
 }
@@ -95,11 +151,132 @@ func TestRouteUsecase_Create_AirportMissing(t *testing.T) {
 	}
 }
 
+func TestRouteUsecase_RefusesArchivedAirports(t *testing.T) {
+	rr := &fakeRouteRepo{items: map[string]domain.Route{"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}}}
+	ar := &fakeAirportRepoRoute{existing: map[string]bool{"CGK": true, "DPS": true, "HLP": true}, archived: map[string]bool{"HLP": true}}
+	uc := NewRouteUsecase(rr, ar)
+	if _, err := uc.Create(context.Background(), "R1", "HLP", "DPS"); err != domain.ErrAirportArchived {
+		t.Fatalf("want airport archived, got %v", err)
+	}
+	if _, err := uc.Update(context.Background(), "RT1", "HLP", "", false); err != domain.ErrAirportArchived {
+		t.Fatalf("want airport archived on update, got %v", err)
+	}
+	if _, ok := rr.items["R1"]; ok || rr.items["RT1"].OriginCode != "CGK" {
+		t.Fatalf("routes should be unchanged, got %+v", rr.items)
+	}
+}
+
 func TestRouteUsecase_Delete_InvalidCode(t *testing.T) {
 	rr := &fakeRouteRepo{}
 	ar := &fakeAirportRepoRoute{}
 	uc := NewRouteUsecase(rr, ar)
-	if err := uc.Delete(context.Background(), ""); err != domain.ErrInvalidRouteCode {
+	if _, err := uc.Delete(context.Background(), "", nil); err != domain.ErrInvalidRouteCode {
 		t.Fatalf("want invalid route code, got %v", err)
 	}
 }
+
+func TestRouteUsecase_DistanceAndBlockTime(t *testing.T) {
+	rr := &fakeRouteRepo{items: make(map[string]domain.Route)}
+	ar := &fakeAirportRepoRoute{
+		existing:  map[string]bool{"CGK": true, "DPS": true, "SUB": true},
+		positions: map[string]*domain.Coordinates{"CGK": {Latitude: -6.1256, Longitude: 106.6559}, "DPS": {Latitude: -8.7482, Longitude: 115.1672}},
+	}
+	uc := NewRouteUsecase(rr, ar)
+	ctx := context.Background()
+	r, err := uc.Create(ctx, "CGK-DPS", "CGK", "DPS")
+	if err != nil || r.DistanceKm != 983 {
+		t.Fatalf("create: err=%v route=%+v", err, r)
+	}
+	if r, err := uc.Create(ctx, "CGK-SUB", "CGK", "SUB"); err != nil || r.DistanceKm != 0 {
+		t.Fatalf("expected unknown distance without a SUB position: err=%v route=%+v", err, r)
+	}
+
+	if err := uc.SetBlockTime(ctx, "cgk-dps", time.Hour+50*time.Minute+20*time.Second); err != nil {
+		t.Fatalf("set block time: %v", err)
+	}
+	if got := rr.items["CGK-DPS"].BlockMinutes; got != 110 {
+		t.Fatalf("want 110 block minutes, got %d", got)
+	}
+	if err := uc.SetBlockTime(ctx, "CGK-DPS", 25*time.Hour); err != domain.ErrInvalidBlockTime {
+		t.Fatalf("want invalid block time, got %v", err)
+	}
+	if err := uc.SetBlockTime(ctx, "NONE", time.Hour); err != domain.ErrRouteNotFound {
+		t.Fatalf("want route not found, got %v", err)
+	}
+}
+
+func TestRouteUsecase_Update(t *testing.T) {
+	t0 := time.Date(2025, 1, 2, 1, 0, 0, 0, time.UTC)
+	rr := &fakeRouteRepo{items: map[string]domain.Route{
+		"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"},
+		"RT2": {Code: "RT2", OriginCode: "CGK", DestinationCode: "DPS"},
+	}}
+	ar := &fakeAirportRepoRoute{existing: map[string]bool{"CGK": true, "DPS": true, "SUB": true}}
+	schedules := &fakeScheduleRepo{items: []domain.FlightSchedule{
+		{ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: t0},
+		{ID: 2, RouteCode: "RT2", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: t0.Add(4 * time.Hour)},
+		{ID: 3, RouteCode: "RT2", AirplaneCode: "A320", DepartureDate: "2025-01-01", DepartureAt: t0.Add(-time.Hour)},
+	}}
+	bookings := &mockBookingRepo{count: 1, bookings: map[string]*domain.Booking{
+		"AAAAAA": {ID: 1, Reference: "AAAAAA", ScheduleID: 1, SeatNumber: 1, Status: domain.BookingStatusConfirmed},
+		"BBBBBB": {ID: 2, Reference: "BBBBBB", ScheduleID: 1, SeatNumber: 2, Status: domain.BookingStatusCancelled},
+	}}
+	airplanes := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 3}}}
+	uc := NewRouteUsecase(rr, ar).WithReaccommodation(schedules, bookings, airplanes)
+	ctx := context.Background()
+
+	if _, err := uc.Update(ctx, "RT1", "", "XXX", false); err != domain.ErrAirportNotFound {
+		t.Fatalf("want airport not found, got %v", err)
+	}
+	if _, err := uc.Update(ctx, "RT1", "DPS", "", false); err != domain.ErrInvalidRouteAirports {
+		t.Fatalf("want invalid route airports, got %v", err)
+	}
+	if _, err := uc.Update(ctx, "NONE", "", "SUB", false); err != domain.ErrRouteNotFound {
+		t.Fatalf("want route not found, got %v", err)
+	}
+	if change, err := uc.Update(ctx, "rt1", "cgk", "dps", false); err != nil || change.Route.DestinationCode != "DPS" {
+		t.Fatalf("unchanged airports should be a no-op: err=%v change=%+v", err, change)
+	}
+
+	rr.booked = []domain.BookedFlight{{Schedule: schedules.items[0], Booked: 1}}
+	if _, err := uc.Update(ctx, "RT1", "", "SUB", false); !errors.Is(err, domain.ErrRouteHasBookings) {
+		t.Fatalf("want refusal, got %v", err)
+	}
+	if got := rr.items["RT1"].DestinationCode; got != "DPS" {
+		t.Fatalf("refused update moved the route to %s", got)
+	}
+
+	change, err := uc.Update(ctx, "rt1", "", "sub", true)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if change.Previous.DestinationCode != "DPS" || change.Route.DestinationCode != "SUB" || len(change.Reaccommodations) != 1 {
+		t.Fatalf("unexpected change %+v", change)
+	}
+	plan := change.Reaccommodations[0]
+	if len(plan.Displaced) != 1 || plan.Displaced[0].Reference != "AAAAAA" {
+		t.Fatalf("unexpected displaced %+v", plan.Displaced)
+	}
+	if len(plan.Alternatives) != 1 || plan.Alternatives[0].Schedule.ID != 2 || plan.Alternatives[0].SeatsLeft != 2 {
+		t.Fatalf("unexpected alternatives %+v", plan.Alternatives)
+	}
+}
+
+func TestRouteUsecase_Delete_InUse(t *testing.T) {
+	used := domain.Dependents{Schedules: 2, Bookings: 3}
+	rr := &fakeRouteRepo{items: map[string]domain.Route{"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}}, used: used}
+	uc := NewRouteUsecase(rr, &fakeAirportRepoRoute{})
+	if _, err := uc.Delete(context.Background(), "rt1", nil); !errors.Is(err, domain.ErrRouteInUse) {
+		t.Fatalf("want route in use, got %v", err)
+	}
+	if _, ok := rr.items["RT1"]; !ok {
+		t.Fatalf("refused delete removed the route")
+	}
+	removed, err := uc.Delete(context.Background(), "rt1", &used)
+	if err != nil || removed != used {
+		t.Fatalf("cascade: err=%v removed=%+v", err, removed)
+	}
+	if _, ok := rr.items["RT1"]; ok {
+		t.Fatalf("route still stored after cascade")
+	}
+}
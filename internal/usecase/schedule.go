@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -13,16 +14,39 @@ type ScheduleUsecase struct {
 	schedules domain.FlightScheduleRepository
 	routes    domain.RouteRepository
 	airplanes domain.AirplaneRepository
+	airports  domain.AirportRepository
+	bookings  domain.BookingRepository
 	timeout   time.Duration
+	// guard keeps airplanes off flights they cannot fly; see guard.go.
+	guard airplaneGuard
+	// trips and minConnection let delays find broken connections; see delay.go.
+	trips         domain.TripRepository
+	minConnection time.Duration
+	now           func() time.Time
 }
 
 // NewScheduleUsecase constructs a ScheduleUsecase with default timeout.
-func NewScheduleUsecase(repo domain.FlightScheduleRepository, routeRepo domain.RouteRepository, airplaneRepo domain.AirplaneRepository) *ScheduleUsecase {
-	return &ScheduleUsecase{schedules: repo, routes: routeRepo, airplanes: airplaneRepo, timeout: 5 * time.Second}
+func NewScheduleUsecase(repo domain.FlightScheduleRepository, routeRepo domain.RouteRepository, airplaneRepo domain.AirplaneRepository, airportRepo domain.AirportRepository) *ScheduleUsecase {
+	return &ScheduleUsecase{schedules: repo, routes: routeRepo, airplanes: airplaneRepo, airports: airportRepo, timeout: 5 * time.Second,
+		guard: airplaneGuard{schedules: repo, routes: routeRepo, minTurnaround: defaultMinTurnaround}, minConnection: domain.DefaultConnectionPolicy.MinConnection, now: time.Now}
 }
 
-// Create validates references and stores a new flight schedule.
-func (u *ScheduleUsecase) Create(ctx context.Context, routeCode, airplaneCode, departureDate string) (*domain.FlightSchedule, error) {
+// WithBookings lets equipment swaps that do not fit the bookings offer a
+// reaccommodation plan and cancellations list the bookings they affect.
+func (u *ScheduleUsecase) WithBookings(b domain.BookingRepository) *ScheduleUsecase {
+	u.bookings = b
+	return u
+}
+
+// Create validates references, refusing archived routes and airplanes, and
+// stores a new flight schedule. departureTime and arrivalTime are local HH:MM
+// wall-clock times at the route's origin and destination airports; an empty
+// departure means local midnight and an empty arrival defaults to the route's
+// block time, or leaves the arrival unplanned when the route has none. It
+// fails with a *domain.MaintenanceError when the airplane is in maintenance
+// during the flight and, with strict rotation, with a *domain.RotationError
+// when the flight does not chain with the airplane's other flights.
+func (u *ScheduleUsecase) Create(ctx context.Context, routeCode, airplaneCode, departureDate, departureTime, arrivalTime string) (*domain.FlightSchedule, error) {
 	sched := &domain.FlightSchedule{RouteCode: routeCode, AirplaneCode: airplaneCode, DepartureDate: departureDate}
 	sched.Normalize()
 	if err := sched.Validate(); err != nil {
@@ -32,10 +56,23 @@ func (u *ScheduleUsecase) Create(ctx context.Context, routeCode, airplaneCode, d
 	ctx, cancel := context.WithTimeout(ctx, u.timeout)
 	defer cancel()
 
-	if _, err := u.routes.GetByCode(ctx, sched.RouteCode); err != nil {
+	route, origin, destination, err := routeZones(ctx, u.routes, u.airports, sched.RouteCode)
+	if err != nil {
+		return nil, err
+	}
+	if route.Archived() {
+		return nil, domain.ErrRouteArchived
+	}
+	if _, err := activeAirplane(ctx, u.airplanes, sched.AirplaneCode); err != nil {
 		return nil, err
 	}
-	if _, err := u.airplanes.GetByCode(ctx, sched.AirplaneCode); err != nil {
+	if err := placeSchedule(sched, departureTime, arrivalTime, origin, destination, route.BlockTime()); err != nil {
+		return nil, err
+	}
+	if err := u.guard.checkMaintenance(ctx, []domain.FlightSchedule{*sched}); err != nil {
+		return nil, err
+	}
+	if err := u.guard.checkRotation(ctx, sched.AirplaneCode, []domain.FlightSchedule{*sched}); err != nil {
 		return nil, err
 	}
 	if err := u.schedules.Create(ctx, sched); err != nil {
@@ -58,12 +95,117 @@ func (u *ScheduleUsecase) List(ctx context.Context, routeCode string, limit, off
 	return u.schedules.List(ctx, routeCode, limit, offset)
 }
 
-// Delete removes a schedule by identifier.
-func (u *ScheduleUsecase) Delete(ctx context.Context, id int64) error {
+// Delete removes a schedule by identifier. While it has bookings it fails
+// with a *domain.InUseError unless cascade holds the dependents it reported,
+// and with domain.ErrDependentsChanged when they differ; the result counts
+// the bookings removed along with it.
+func (u *ScheduleUsecase) Delete(ctx context.Context, id int64, cascade *domain.Dependents) (domain.Dependents, error) {
+	if id <= 0 {
+		return domain.Dependents{}, domain.ErrInvalidScheduleID
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.schedules.Delete(ctx, id, cascade)
+}
+
+// SwapResult reports an equipment swap. Moves lists bookings reseated because
+// the new airplane lacks their seat. When the bookings do not fit, the swap is
+// refused and Reaccommodation, if bookings are configured, offers other flights.
+type SwapResult struct {
+	Schedule         *domain.FlightSchedule
+	PreviousAirplane string
+	Moves            []domain.SeatMove
+	Reaccommodation  *domain.Reaccommodation
+}
+
+// SwapAirplane moves a schedule to another airplane in place, keeping its
+// bookings. It fails with a *domain.MaintenanceError when the new airplane is
+// in maintenance during the flight, with strict rotation with a
+// *domain.RotationError when the flight does not chain with the new
+// airplane's other flights, with a *domain.CapacityError when it
+// has fewer seats than the confirmed and held bookings, with
+// domain.ErrAirplaneArchived when it is archived and with
+// domain.ErrScheduleCancelled when the flight was cancelled.
+func (u *ScheduleUsecase) SwapAirplane(ctx context.Context, id int64, airplaneCode string) (*SwapResult, error) {
 	if id <= 0 {
-		return domain.ErrInvalidScheduleID
+		return nil, domain.ErrInvalidScheduleID
+	}
+	airplaneCode = strings.ToUpper(strings.TrimSpace(airplaneCode))
+	if airplaneCode == "" || len(airplaneCode) > 16 {
+		return nil, domain.ErrInvalidScheduleAirplane
 	}
+
 	ctx, cancel := context.WithTimeout(ctx, u.timeout)
 	defer cancel()
-	return u.schedules.Delete(ctx, id)
+
+	sched, err := u.schedules.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sched.Cancelled() {
+		return nil, domain.ErrScheduleCancelled
+	}
+	if _, err := activeAirplane(ctx, u.airplanes, airplaneCode); err != nil {
+		return nil, err
+	}
+	res := &SwapResult{Schedule: sched, PreviousAirplane: sched.AirplaneCode}
+	updated := *sched
+	updated.AirplaneCode = airplaneCode
+	if err := u.guard.checkMaintenance(ctx, []domain.FlightSchedule{updated}); err != nil {
+		return nil, err
+	}
+	if err := u.guard.checkRotation(ctx, updated.AirplaneCode, []domain.FlightSchedule{updated}); err != nil {
+		return nil, err
+	}
+	moves, err := u.schedules.Update(ctx, &updated)
+	if err != nil {
+		var capErr *domain.CapacityError
+		if errors.As(err, &capErr) && u.bookings != nil {
+			plan, planErr := planReaccommodation(ctx, u.schedules, u.bookings, u.airplanes, *sched, capErr.Capacity)
+			if planErr != nil {
+				return nil, planErr
+			}
+			res.Reaccommodation = plan
+			return res, err
+		}
+		return nil, err
+	}
+	res.Schedule = &updated
+	res.Moves = moves
+	return res, nil
+}
+
+// routeZones looks up the route and returns it with the time zones of its
+// origin and destination airports.
+func routeZones(ctx context.Context, routes domain.RouteRepository, airports domain.AirportRepository, routeCode string) (*domain.Route, *time.Location, *time.Location, error) {
+	route, err := routes.GetByCode(ctx, routeCode)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	origin, err := airports.GetByCode(ctx, route.OriginCode)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	destination, err := airports.GetByCode(ctx, route.DestinationCode)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return route, origin.Location(), destination.Location(), nil
+}
+
+// placeSchedule sets the UTC departure and arrival of a schedule from local
+// wall-clock times at its airports. Without an arrival time the flight lands
+// after the route's block time, or has no planned arrival when block is 0.
+func placeSchedule(sched *domain.FlightSchedule, departureTime, arrivalTime string, origin, destination *time.Location, block time.Duration) error {
+	var err error
+	sched.DepartureAt, sched.ArrivalAt, err = domain.ResolveScheduleTimes(sched.DepartureDate, departureTime, arrivalTime, origin, destination)
+	if err != nil {
+		return err
+	}
+	if sched.ArrivalAt.IsZero() && block > 0 {
+		sched.ArrivalAt = sched.DepartureAt.Add(block)
+	}
+	sched.OriginTimeZone = origin.String()
+	sched.DestinationTimeZone = destination.String()
+	return nil
 }
@@ -2,7 +2,10 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
 )
@@ -11,6 +14,11 @@ type fakeScheduleRepo struct {
 	items     []domain.FlightSchedule
 	createErr error
 	deleteErr error
+	updateErr error
+	moves     []domain.SeatMove
+	notices   []domain.Notification
+	// board holds the flights ListByAirport picks from.
+	board []domain.BoardFlight
 }
 
 func (f *fakeScheduleRepo) Create(ctx context.Context, s *domain.FlightSchedule) error {
@@ -42,56 +50,186 @@ func (f *fakeScheduleRepo) List(ctx context.Context, routeCode string, limit, of
 	return out, nil
 }
 
-func (f *fakeScheduleRepo) Delete(ctx context.Context, id int64) error {
+func (f *fakeScheduleRepo) ListByAirplane(ctx context.Context, airplaneCode string, limit, offset int) ([]domain.FlightSchedule, error) {
+	var out []domain.FlightSchedule
+	for _, item := range f.items {
+		if item.AirplaneCode == airplaneCode {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeScheduleRepo) ListByAirport(ctx context.Context, airportCode string, arrivals bool, from, to time.Time, limit, offset int) ([]domain.BoardFlight, error) {
+	var out []domain.BoardFlight
+	for _, b := range f.board {
+		code, at := b.OriginCode, b.Schedule.DepartureAt
+		if arrivals {
+			code, at = b.DestinationCode, b.Schedule.ArrivalAt
+		}
+		if code == airportCode && !at.Before(from) && at.Before(to) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeScheduleRepo) Update(ctx context.Context, s *domain.FlightSchedule) ([]domain.SeatMove, error) {
+	if f.updateErr != nil {
+		return nil, f.updateErr
+	}
+	for i := range f.items {
+		if f.items[i].ID == s.ID {
+			f.items[i] = *s
+			return f.moves, nil
+		}
+	}
+	return nil, domain.ErrScheduleNotFound
+}
+
+func (f *fakeScheduleRepo) Cancel(ctx context.Context, id int64, reason string, at time.Time) (int, error) {
+	for i := range f.items {
+		if f.items[i].ID != id {
+			continue
+		}
+		if f.items[i].Cancelled() {
+			return 0, domain.ErrScheduleCancelled
+		}
+		f.items[i].Status = domain.ScheduleStatusCancelled
+		f.items[i].CancelReason = reason
+		return 0, nil
+	}
+	return 0, domain.ErrScheduleNotFound
+}
+
+func (f *fakeScheduleRepo) Delay(ctx context.Context, delays []domain.ScheduleDelay, notices []domain.Notification) error {
+	for _, d := range delays {
+		for i := range f.items {
+			if f.items[i].ID == d.ScheduleID {
+				f.items[i].DelayMinutes, f.items[i].DelayReason = d.Minutes, d.Reason
+			}
+		}
+	}
+	f.notices = append(f.notices, notices...)
+	return nil
+}
+
+func (f *fakeScheduleRepo) SetGates(ctx context.Context, id int64, departureGate, arrivalGate string) error {
+	for i := range f.items {
+		if f.items[i].ID == id {
+			f.items[i].DepartureGate, f.items[i].ArrivalGate = departureGate, arrivalGate
+			return nil
+		}
+	}
+	return domain.ErrScheduleNotFound
+}
+
+func (f *fakeScheduleRepo) Delete(ctx context.Context, id int64, cascade *domain.Dependents) (domain.Dependents, error) {
 	if f.deleteErr != nil {
-		return f.deleteErr
+		return domain.Dependents{}, f.deleteErr
 	}
 	for i, item := range f.items {
 		if item.ID == id {
 			f.items = append(f.items[:i], f.items[i+1:]...)
-			return nil
+			return domain.Dependents{}, nil
 		}
 	}
-	return domain.ErrScheduleNotFound
+	return domain.Dependents{}, domain.ErrScheduleNotFound
 }
 
-type fakeRouteRepoSched struct{ items map[string]bool }
+type fakeRouteRepoSched struct {
+	items        map[string]bool
+	archived     map[string]bool
+	blockMinutes int
+}
 
 func (f *fakeRouteRepoSched) Create(ctx context.Context, r *domain.Route) error { return nil }
 func (f *fakeRouteRepoSched) GetByCode(ctx context.Context, code string) (*domain.Route, error) {
 	if f.items != nil && f.items[code] {
-		return &domain.Route{Code: code}, nil
+		r := &domain.Route{Code: code, OriginCode: "CGK", DestinationCode: "DPS", BlockMinutes: f.blockMinutes}
+		if f.archived[code] {
+			r.ArchivedAt = "2025-01-01T00:00:00Z"
+		}
+		return r, nil
 	}
 	return nil, domain.ErrRouteNotFound
 }
-func (f *fakeRouteRepoSched) List(ctx context.Context, limit, offset int) ([]domain.Route, error) {
+func (f *fakeRouteRepoSched) SetArchived(ctx context.Context, code string, archived bool) error {
+	if f.archived == nil {
+		f.archived = make(map[string]bool)
+	}
+	f.archived[code] = archived
+	return nil
+}
+func (f *fakeRouteRepoSched) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Route, error) {
 	return nil, nil
 }
-func (f *fakeRouteRepoSched) Delete(ctx context.Context, code string) error { return nil }
+func (f *fakeRouteRepoSched) SetBlockTime(ctx context.Context, code string, minutes int) error {
+	return nil
+}
+func (f *fakeRouteRepoSched) Update(ctx context.Context, r *domain.Route, from time.Time, force bool) ([]domain.BookedFlight, error) {
+	return nil, nil
+}
+func (f *fakeRouteRepoSched) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	return domain.Dependents{}, nil
+}
 
-type fakeAirplaneRepoSched struct{ items map[string]bool }
+type fakeAirplaneRepoSched struct {
+	items, archived map[string]bool
+	types           map[string]string // airplane code to the IATA code of its type
+}
 
 func (f *fakeAirplaneRepoSched) Create(ctx context.Context, a *domain.Airplane) error { return nil }
 func (f *fakeAirplaneRepoSched) GetByCode(ctx context.Context, code string) (*domain.Airplane, error) {
 	if f.items != nil && f.items[code] {
-		return &domain.Airplane{Code: code}, nil
+		a := &domain.Airplane{Code: code, TypeIATACode: f.types[code]}
+		if f.archived[code] {
+			a.ArchivedAt = "2025-01-01T00:00:00Z"
+		}
+		return a, nil
 	}
 	return nil, domain.ErrAirplaneNotFound
 }
-func (f *fakeAirplaneRepoSched) List(ctx context.Context, limit, offset int) ([]domain.Airplane, error) {
-	return nil, nil
-}
-func (f *fakeAirplaneRepoSched) UpdateSeats(ctx context.Context, code string, seats int) error {
+func (f *fakeAirplaneRepoSched) SetArchived(ctx context.Context, code string, archived bool) error {
+	if f.archived == nil {
+		f.archived = make(map[string]bool)
+	}
+	f.archived[code] = archived
 	return nil
 }
-func (f *fakeAirplaneRepoSched) Delete(ctx context.Context, code string) error { return nil }
+func (f *fakeAirplaneRepoSched) List(ctx context.Context, limit, offset int, includeArchived bool) ([]domain.Airplane, error) {
+	var out []domain.Airplane
+	for code := range f.items {
+		if a, _ := f.GetByCode(ctx, code); includeArchived || !a.Archived() {
+			out = append(out, *a)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	if offset >= len(out) {
+		return nil, nil
+	}
+	return out[offset:min(offset+limit, len(out))], nil
+}
+func (f *fakeAirplaneRepoSched) UpdateSeats(ctx context.Context, code string, seats int, from time.Time, force bool) (*domain.CapacityChange, error) {
+	return &domain.CapacityChange{}, nil
+}
+func (f *fakeAirplaneRepoSched) Delete(ctx context.Context, code string, cascade *domain.Dependents) (domain.Dependents, error) {
+	return domain.Dependents{}, nil
+}
+
+func newSchedAirports() *fakeAirportRepo {
+	return &fakeAirportRepo{list: []domain.Airport{
+		{Code: "CGK", City: "Jakarta", TimeZone: "Asia/Jakarta"},
+		{Code: "DPS", City: "Denpasar", TimeZone: "Asia/Makassar"},
+	}}
+}
 
 func TestScheduleUsecase_Create_List_Delete(t *testing.T) {
 	repo := &fakeScheduleRepo{}
 	routes := &fakeRouteRepoSched{items: map[string]bool{"RT1": true}}
 	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true}}
-	uc := NewScheduleUsecase(repo, routes, planes)
-	sched, err := uc.Create(context.Background(), " rt1 ", " a320 ", "2025-01-02")
+	uc := NewScheduleUsecase(repo, routes, planes, newSchedAirports())
+	sched, err := uc.Create(context.Background(), " rt1 ", " a320 ", "2025-01-02", "", "")
 	if err != nil {
 		t.Fatalf("create: %v", err)
 	}
@@ -102,7 +240,7 @@ func TestScheduleUsecase_Create_List_Delete(t *testing.T) {
 	if err != nil || len(items) != 1 {
 		t.Fatalf("list err=%v len=%d", err, len(items))
 	}
-	if err := uc.Delete(context.Background(), sched.ID); err != nil {
+	if _, err := uc.Delete(context.Background(), sched.ID, nil); err != nil {
 		t.Fatalf("delete: %v", err)
 	}
 }
@@ -111,21 +249,126 @@ func TestScheduleUsecase_Create_MissingReferences(t *testing.T) {
 	repo := &fakeScheduleRepo{}
 	routes := &fakeRouteRepoSched{items: map[string]bool{"RT1": true}}
 	planes := &fakeAirplaneRepoSched{}
-	uc := NewScheduleUsecase(repo, routes, planes)
-	if _, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-01"); err != domain.ErrAirplaneNotFound {
+	uc := NewScheduleUsecase(repo, routes, planes, newSchedAirports())
+	if _, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-01", "", ""); err != domain.ErrAirplaneNotFound {
 		t.Fatalf("want airplane not found, got %v", err)
 	}
 	planes.items = map[string]bool{"A320": true}
 	routes.items = map[string]bool{}
-	if _, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-01"); err != domain.ErrRouteNotFound {
+	if _, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-01", "", ""); err != domain.ErrRouteNotFound {
 		t.Fatalf("want route not found, got %v", err)
 	}
 }
 
+func TestScheduleUsecase_Create_RefusesArchived(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	routes := &fakeRouteRepoSched{items: map[string]bool{"RT1": true}, archived: map[string]bool{"RT1": true}}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true}}
+	uc := NewScheduleUsecase(repo, routes, planes, newSchedAirports())
+	if _, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-02", "", ""); err != domain.ErrRouteArchived {
+		t.Fatalf("want route archived, got %v", err)
+	}
+	routes.archived = nil
+	planes.archived = map[string]bool{"A320": true}
+	if _, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-02", "", ""); err != domain.ErrAirplaneArchived {
+		t.Fatalf("want airplane archived, got %v", err)
+	}
+	if len(repo.items) != 0 {
+		t.Fatalf("no flight should be stored, got %+v", repo.items)
+	}
+}
+
 func TestScheduleUsecase_Delete_InvalidID(t *testing.T) {
 	repo := &fakeScheduleRepo{}
-	uc := NewScheduleUsecase(repo, &fakeRouteRepoSched{}, &fakeAirplaneRepoSched{})
-	if err := uc.Delete(context.Background(), 0); err != domain.ErrInvalidScheduleID {
+	uc := NewScheduleUsecase(repo, &fakeRouteRepoSched{}, &fakeAirplaneRepoSched{}, newSchedAirports())
+	if _, err := uc.Delete(context.Background(), 0, nil); err != domain.ErrInvalidScheduleID {
+		t.Fatalf("want invalid schedule id, got %v", err)
+	}
+}
+
+func TestScheduleUsecase_Create_LocalTimes(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	routes := &fakeRouteRepoSched{items: map[string]bool{"RT1": true}}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true}}
+	uc := NewScheduleUsecase(repo, routes, planes, newSchedAirports())
+	sched, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-02", "22:30", "01:20")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if got := sched.DepartureAt.Format(time.RFC3339); got != "2025-01-02T15:30:00Z" {
+		t.Fatalf("departure should be stored in UTC, got %s", got)
+	}
+	if got := sched.LocalArrival().Format("2006-01-02 15:04"); got != "2025-01-03 01:20" {
+		t.Fatalf("overnight arrival should land next day, got %s", got)
+	}
+	if sched.Duration() != 110*time.Minute {
+		t.Fatalf("unexpected duration %s", sched.Duration())
+	}
+	if _, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-02", "8am", ""); err != domain.ErrInvalidScheduleTime {
+		t.Fatalf("want invalid time, got %v", err)
+	}
+}
+
+func TestScheduleUsecase_Create_DefaultsArrivalToBlockTime(t *testing.T) {
+	repo := &fakeScheduleRepo{}
+	routes := &fakeRouteRepoSched{items: map[string]bool{"RT1": true}, blockMinutes: 110}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true}}
+	uc := NewScheduleUsecase(repo, routes, planes, newSchedAirports())
+	sched, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-02", "22:30", "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if sched.Duration() != 110*time.Minute || sched.LocalArrival().Format("2006-01-02 15:04") != "2025-01-03 01:20" {
+		t.Fatalf("expected the route's block time, got arrival %s", sched.LocalArrival())
+	}
+	sched, err = uc.Create(context.Background(), "RT1", "A320", "2025-01-03", "22:30", "00:50")
+	if err != nil || sched.Duration() != 80*time.Minute {
+		t.Fatalf("an explicit arrival wins over the block time: err=%v duration=%s", err, sched.Duration())
+	}
+}
+
+func TestScheduleUsecase_SwapAirplane(t *testing.T) {
+	t0 := time.Date(2025, 1, 2, 1, 0, 0, 0, time.UTC)
+	repo := &fakeScheduleRepo{items: []domain.FlightSchedule{
+		{ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-02", DepartureAt: t0},
+		{ID: 2, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-03", DepartureAt: t0.AddDate(0, 0, 1)},
+	}, moves: []domain.SeatMove{{BookingID: 9, Reference: "AAAAAA", From: 150, To: 3}}}
+	planes := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{
+		"A320": {Code: "A320", SeatCapacity: 180},
+		"ATR":  {Code: "ATR", SeatCapacity: 2},
+	}}
+	bookings := &mockBookingRepo{count: 100, bookings: map[string]*domain.Booking{
+		"AAAAAA": {ID: 1, Reference: "AAAAAA", ScheduleID: 1, SeatNumber: 1, Status: domain.BookingStatusConfirmed},
+		"BBBBBB": {ID: 2, Reference: "BBBBBB", ScheduleID: 1, SeatNumber: 2, Status: domain.BookingStatusHeld},
+		"CCCCCC": {ID: 3, Reference: "CCCCCC", ScheduleID: 1, SeatNumber: 3, Status: domain.BookingStatusConfirmed},
+		"DDDDDD": {ID: 4, Reference: "DDDDDD", ScheduleID: 1, SeatNumber: 4, Status: domain.BookingStatusConfirmed},
+		"XXXXXX": {ID: 5, Reference: "XXXXXX", ScheduleID: 1, SeatNumber: 4, Status: domain.BookingStatusCancelled},
+	}}
+	uc := NewScheduleUsecase(repo, &fakeRouteRepoSched{}, planes, newSchedAirports()).WithBookings(bookings)
+
+	res, err := uc.SwapAirplane(context.Background(), 1, " atr ")
+	if err != nil || res.PreviousAirplane != "A320" || res.Schedule.AirplaneCode != "ATR" || len(res.Moves) != 1 || repo.items[0].AirplaneCode != "ATR" {
+		t.Fatalf("unexpected swap %+v err=%v", res, err)
+	}
+
+	// Four active bookings on two seats: the held one and the newest confirmed one give way.
+	repo.updateErr = &domain.CapacityError{ScheduleID: 1, Capacity: 2, Booked: 4}
+	res, err = uc.SwapAirplane(context.Background(), 1, "ATR")
+	if !errors.Is(err, domain.ErrCapacityBelowBookings) || res == nil || res.Reaccommodation == nil {
+		t.Fatalf("want refusal with a reaccommodation plan, got %+v err=%v", res, err)
+	}
+	plan := res.Reaccommodation
+	if len(plan.Displaced) != 2 || plan.Displaced[0].Reference != "BBBBBB" || plan.Displaced[1].Reference != "DDDDDD" {
+		t.Fatalf("unexpected displaced bookings %+v", plan.Displaced)
+	}
+	if len(plan.Alternatives) != 1 || plan.Alternatives[0].Schedule.ID != 2 || plan.Alternatives[0].SeatsLeft != 80 {
+		t.Fatalf("unexpected alternatives %+v", plan.Alternatives)
+	}
+
+	if _, err := uc.SwapAirplane(context.Background(), 1, "B737"); err != domain.ErrAirplaneNotFound {
+		t.Fatalf("want airplane not found, got %v", err)
+	}
+	if _, err := uc.SwapAirplane(context.Background(), 0, "ATR"); err != domain.ErrInvalidScheduleID {
 		t.Fatalf("want invalid schedule id, got %v", err)
 	}
 }
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// MaxPassengers bounds how many travellers one search can ask seats for.
+const MaxPassengers = 9
+
+// Sort keys accepted by SearchQuery.SortBy.
+const (
+	SortDeparture = "departure" // earliest first departure first
+	SortSeats     = "seats"     // most seats left first
+	SortFare      = "fare"      // cheapest first; unpriced journeys last
+	SortDuration  = "duration"  // shortest journey first; unknown durations last
+	SortStops     = "stops"     // fewest connections first
+)
+
+// SearchQuery describes a flight search: the airports and dates, how many
+// travellers need seats, which journeys to keep and how to order them.
+type SearchQuery struct {
+	OriginCode      string
+	DestinationCode string
+	DepartureDate   string // optional YYYY-MM-DD of the first leg
+	ReturnDate      string // FindRoundTrips only: YYYY-MM-DD of the return flight
+	FlexDays        int    // also search up to this many days either side of the dates
+	Passengers      int    // seats needed on every leg; zero means one
+	AircraftType    string // keep only journeys flown entirely by this aircraft type: ICAO or IATA code, or name
+	MaxStops        int    // FindItineraries only: at most this many connections
+	// DepartAfter and DepartBefore bound the first departure as HH:MM local to
+	// the origin; either may be empty. The window wraps past midnight when
+	// DepartAfter is later than DepartBefore.
+	DepartAfter  string
+	DepartBefore string
+	SortBy       string // one of the Sort keys; empty keeps each search's natural order
+	// Explain, when set, receives every candidate FindDirectFlights and
+	// FindTransitFlights examined, including first legs up to a week either
+	// side of the searched dates, and why each one was left out. Itinerary and
+	// round-trip searches do not explain themselves.
+	Explain *SearchTrace
+}
+
+// searchCriteria is a validated and normalised SearchQuery.
+type searchCriteria struct {
+	origin, destination string
+	dates               searchDates
+	seats               int
+	aircraftType        string
+	windowed            bool
+	after, before       int // minutes after local midnight
+	sortBy              string
+	trace               *SearchTrace
+}
+
+func (q SearchQuery) criteria() (searchCriteria, error) {
+	origin, destination, dates, err := normalizeSearch(q.OriginCode, q.DestinationCode, q.DepartureDate, q.FlexDays)
+	if err != nil {
+		return searchCriteria{}, err
+	}
+	c := searchCriteria{
+		origin:       origin,
+		destination:  destination,
+		dates:        dates,
+		seats:        q.Passengers,
+		aircraftType: strings.TrimSpace(q.AircraftType),
+		sortBy:       strings.ToLower(strings.TrimSpace(q.SortBy)),
+		before:       24*60 - 1,
+		trace:        q.Explain,
+	}
+	if c.seats == 0 {
+		c.seats = 1
+	}
+	if c.seats < 0 || c.seats > MaxPassengers {
+		return searchCriteria{}, domain.ErrInvalidPassengerCount
+	}
+	switch c.sortBy {
+	case "", SortDeparture, SortSeats, SortFare, SortDuration, SortStops:
+	default:
+		return searchCriteria{}, domain.ErrInvalidSortKey
+	}
+	if after := strings.TrimSpace(q.DepartAfter); after != "" {
+		if c.after, err = minuteOfDay(after); err != nil {
+			return searchCriteria{}, err
+		}
+		c.windowed = true
+	}
+	if before := strings.TrimSpace(q.DepartBefore); before != "" {
+		if c.before, err = minuteOfDay(before); err != nil {
+			return searchCriteria{}, err
+		}
+		c.windowed = true
+	}
+	return c, nil
+}
+
+func minuteOfDay(hhmm string) (int, error) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return 0, domain.ErrInvalidTimeWindow
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// bookable reports whether a leg has a seat for every traveller and is flown by
+// the requested aircraft type.
+func (c searchCriteria) bookable(row domain.FlightAvailability) bool {
+	reason, _ := c.rejectLeg(row)
+	return reason == ""
+}
+
+// departsInWindow reports whether a first leg leaving at local time t is inside
+// the time-of-day window.
+func (c searchCriteria) departsInWindow(t time.Time) bool {
+	if !c.windowed {
+		return true
+	}
+	if t.IsZero() {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if c.after <= c.before {
+		return c.after <= m && m <= c.before
+	}
+	return m >= c.after || m <= c.before
+}
+
+// journey is the sortable summary shared by every kind of search result.
+type journey struct {
+	departs  time.Time
+	duration time.Duration // zero when unknown
+	seats    int
+	fare     domain.Money
+	stops    int
+}
+
+// less orders journeys by the sort key, then by first departure.
+func (c searchCriteria) less(a, b journey) bool {
+	switch c.sortBy {
+	case SortSeats:
+		if a.seats != b.seats {
+			return a.seats > b.seats
+		}
+	case SortFare:
+		if a.fare.IsZero() != b.fare.IsZero() {
+			return !a.fare.IsZero()
+		}
+		// Amounts in different currencies are not comparable, so fares are
+		// grouped by currency code and ordered by amount within each.
+		if a.fare.Currency != b.fare.Currency {
+			return a.fare.Currency < b.fare.Currency
+		}
+		if a.fare.Amount != b.fare.Amount {
+			return a.fare.Amount < b.fare.Amount
+		}
+	case SortDuration:
+		if (a.duration == 0) != (b.duration == 0) {
+			return a.duration != 0
+		}
+		if a.duration != b.duration {
+			return a.duration < b.duration
+		}
+	case SortStops:
+		if a.stops != b.stops {
+			return a.stops < b.stops
+		}
+	}
+	return a.departs.Before(b.departs)
+}
+
+// lessBy adapts less to sort.SliceStable for a slice whose i-th journey is view(i).
+func (c searchCriteria) lessBy(view func(i int) journey) func(i, j int) bool {
+	return func(i, j int) bool { return c.less(view(i), view(j)) }
+}
+
+func directJourney(o FlightOption) journey {
+	j := journey{departs: o.DepartureTime, seats: o.SeatsAvailable}
+	if !o.ArrivalTime.IsZero() {
+		j.duration = o.ArrivalTime.Sub(o.DepartureTime)
+	}
+	if o.Fare != nil {
+		j.fare = o.Fare.OneWay
+	}
+	return j
+}
+
+func transitJourney(o TransitOption) journey {
+	return journey{departs: o.FirstLeg.DepartureTime, duration: o.TotalDuration, seats: o.TotalAvailable, fare: o.TotalFare, stops: 1}
+}
+
+func itineraryJourney(it Itinerary) journey {
+	j := journey{duration: it.TotalDuration, seats: it.TotalAvailable, fare: it.TotalFare, stops: it.Stops()}
+	if len(it.Legs) > 0 {
+		j.departs = it.Legs[0].DepartureTime
+	}
+	return j
+}
+
+func roundTripJourney(o RoundTripOption) journey {
+	j := directJourney(o.Outbound)
+	back := directJourney(o.Return)
+	if j.duration != 0 && back.duration != 0 {
+		j.duration += back.duration
+	} else {
+		j.duration = 0
+	}
+	if back.seats < j.seats {
+		j.seats = back.seats
+	}
+	j.fare = o.Fare
+	return j
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func TestSearchQuery_Criteria(t *testing.T) {
+	base := SearchQuery{OriginCode: "CGK", DestinationCode: "DPS"}
+	cases := []struct {
+		edit func(q *SearchQuery)
+		want error
+	}{
+		{func(q *SearchQuery) { q.Passengers = -1 }, domain.ErrInvalidPassengerCount},
+		{func(q *SearchQuery) { q.Passengers = MaxPassengers + 1 }, domain.ErrInvalidPassengerCount},
+		{func(q *SearchQuery) { q.SortBy = "price" }, domain.ErrInvalidSortKey},
+		{func(q *SearchQuery) { q.DepartAfter = "25:00" }, domain.ErrInvalidTimeWindow},
+		{func(q *SearchQuery) { q.DepartBefore = "noon" }, domain.ErrInvalidTimeWindow},
+		{func(q *SearchQuery) { q.SortBy = " Fare " }, nil},
+	}
+	for i, tc := range cases {
+		q := base
+		tc.edit(&q)
+		if _, err := q.criteria(); err != tc.want {
+			t.Fatalf("case %d: want %v, got %v", i, tc.want, err)
+		}
+	}
+	c, err := base.criteria()
+	if err != nil || c.seats != 1 || c.windowed {
+		t.Fatalf("expected one seat and no window by default, got %+v err=%v", c, err)
+	}
+}
+
+func TestSearchCriteria_DepartsInWindow(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2025, 1, 1, h, m, 0, 0, time.UTC) }
+	day, _ := SearchQuery{OriginCode: "A", DestinationCode: "B", DepartAfter: "06:00", DepartBefore: "12:00"}.criteria()
+	if !day.departsInWindow(at(6, 0)) || !day.departsInWindow(at(12, 0)) || day.departsInWindow(at(12, 1)) || day.departsInWindow(time.Time{}) {
+		t.Fatalf("unexpected daytime window")
+	}
+	night, _ := SearchQuery{OriginCode: "A", DestinationCode: "B", DepartAfter: "22:00", DepartBefore: "02:00"}.criteria()
+	if !night.departsInWindow(at(23, 30)) || !night.departsInWindow(at(1, 0)) || night.departsInWindow(at(12, 0)) {
+		t.Fatalf("unexpected overnight window")
+	}
+	open, _ := SearchQuery{OriginCode: "A", DestinationCode: "B", DepartAfter: "18:00"}.criteria()
+	if !open.departsInWindow(at(23, 59)) || open.departsInWindow(at(17, 59)) {
+		t.Fatalf("unexpected open-ended window")
+	}
+}
+
+func TestBookingUsecase_FindDirectFlights_FilterAndSort(t *testing.T) {
+	day := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	row := func(id int64, plane string, hour, minutes, capacity, booked int, fare int64) domain.FlightAvailability {
+		r := domain.FlightAvailability{
+			Schedule:   domain.FlightSchedule{ID: id, AirplaneCode: plane, DepartureDate: "2025-03-15", DepartureAt: day.Add(time.Duration(hour) * time.Hour), ArrivalAt: day.Add(time.Duration(hour)*time.Hour + time.Duration(minutes)*time.Minute)},
+			OriginCode: "CGK", DestinationCode: "DPS", SeatCapacity: capacity, Booked: booked,
+		}
+		// Registrations differ from the type: PK-A320 is an A320, PK-B737 a 737-800.
+		r.Schedule.AirplaneCode = "PK-" + plane
+		r.AircraftTypeCode, r.AircraftTypeIATA, r.AircraftType = "A320", "320", "Airbus A320-200"
+		if plane == "B737" {
+			r.AircraftTypeCode, r.AircraftTypeIATA, r.AircraftType = "B738", "738", "Boeing 737-800"
+		}
+		if fare > 0 {
+			r.Fare = &domain.Fare{OneWay: domain.Money{Amount: fare, Currency: "USD"}}
+		}
+		return r
+	}
+	avail := &stubAvailability{rows: []domain.FlightAvailability{
+		row(1, "A320", 6, 120, 10, 9, 300),  // one seat left
+		row(2, "A320", 9, 90, 10, 5, 200),   // five seats, fastest
+		row(3, "B737", 12, 150, 10, 2, 0),   // eight seats, unpriced
+		row(4, "A320", 20, 100, 10, 7, 100), // three seats, cheapest
+	}}
+	uc := NewBookingUsecase(&mockBookingRepo{}, &mockScheduleRepo{}, &mockRouteRepo{}, &mockAirplaneRepo{}).WithAvailability(avail)
+	ids := func(q SearchQuery) []int64 {
+		t.Helper()
+		q.OriginCode, q.DestinationCode, q.DepartureDate = "CGK", "DPS", "2025-03-15"
+		options, err := uc.FindDirectFlights(context.Background(), q)
+		if err != nil {
+			t.Fatalf("search %+v: %v", q, err)
+		}
+		var out []int64
+		for _, o := range options {
+			out = append(out, o.ScheduleID)
+		}
+		return out
+	}
+	check := func(name string, got []int64, want ...int64) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s: want %v, got %v", name, want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: want %v, got %v", name, want, got)
+			}
+		}
+	}
+
+	check("default", ids(SearchQuery{}), 1, 2, 3, 4)
+	check("passengers", ids(SearchQuery{Passengers: 3}), 2, 3, 4)
+	check("aircraft type", ids(SearchQuery{AircraftType: "a320"}), 1, 2, 4)
+	check("aircraft type by IATA code", ids(SearchQuery{AircraftType: "738"}), 3)
+	check("aircraft type by name", ids(SearchQuery{AircraftType: "boeing 737-800"}), 3)
+	check("window", ids(SearchQuery{DepartAfter: "08:00", DepartBefore: "13:00"}), 2, 3)
+	check("seats", ids(SearchQuery{SortBy: SortSeats}), 3, 2, 4, 1)
+	check("fare", ids(SearchQuery{SortBy: SortFare}), 4, 2, 1, 3)
+	check("duration", ids(SearchQuery{SortBy: SortDuration}), 2, 4, 1, 3)
+	check("departure", ids(SearchQuery{SortBy: SortDeparture, Passengers: 2}), 2, 3, 4)
+
+	// A cheaper-looking amount in another currency is not mixed in with USD.
+	eur := row(5, "A320", 22, 100, 10, 0, 150)
+	eur.Fare.OneWay.Currency = "EUR"
+	avail.rows = append(avail.rows, eur)
+	check("fare across currencies", ids(SearchQuery{SortBy: SortFare}), 5, 4, 2, 1, 3)
+}
+
+func TestBookingUsecase_FindItineraries_SortByStops(t *testing.T) {
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	leg := func(id int64, from, to string, dep, arr time.Duration) domain.FlightAvailability {
+		return domain.FlightAvailability{
+			Schedule:   domain.FlightSchedule{ID: id, DepartureDate: "2025-01-01", DepartureAt: day.Add(dep), ArrivalAt: day.Add(arr)},
+			OriginCode: from, DestinationCode: to, SeatCapacity: 5,
+		}
+	}
+	avail := &stubAvailability{rows: []domain.FlightAvailability{
+		leg(1, "CGK", "SUB", 6*time.Hour, 7*time.Hour),
+		leg(2, "SUB", "DPS", 8*time.Hour, 9*time.Hour),
+		leg(3, "CGK", "DPS", 10*time.Hour, 14*time.Hour),
+	}}
+	uc := NewBookingUsecase(&mockBookingRepo{}, &mockScheduleRepo{}, &mockRouteRepo{}, &mockAirplaneRepo{}).WithAvailability(avail)
+
+	q := SearchQuery{OriginCode: "CGK", DestinationCode: "DPS", DepartureDate: "2025-01-01", MaxStops: 1}
+	natural, err := uc.FindItineraries(context.Background(), q)
+	if err != nil || len(natural) != 2 || natural[0].Stops() != 1 {
+		t.Fatalf("expected the shorter one-stop journey first, err=%v got %+v", err, natural)
+	}
+	q.SortBy = SortStops
+	sorted, err := uc.FindItineraries(context.Background(), q)
+	if err != nil || len(sorted) != 2 || sorted[0].Stops() != 0 {
+		t.Fatalf("expected the non-stop journey first, err=%v got %+v", err, sorted)
+	}
+}
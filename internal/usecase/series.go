@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// SeriesUsecase manages recurring schedule series and the flights they generate.
+type SeriesUsecase struct {
+	series    domain.ScheduleSeriesRepository
+	routes    domain.RouteRepository
+	airplanes domain.AirplaneRepository
+	airports  domain.AirportRepository
+	// guard keeps airplanes off flights they cannot fly; see guard.go.
+	guard   airplaneGuard
+	timeout time.Duration
+	now     func() time.Time
+}
+
+// SeriesResult reports the flights a series operation created. Skipped counts
+// occurrences that already existed on the same route, airplane and date.
+type SeriesResult struct {
+	Series  *domain.ScheduleSeries
+	Created []domain.FlightSchedule
+	Skipped int
+}
+
+// NewSeriesUsecase constructs a SeriesUsecase with default timeout.
+func NewSeriesUsecase(seriesRepo domain.ScheduleSeriesRepository, routeRepo domain.RouteRepository, airplaneRepo domain.AirplaneRepository, airportRepo domain.AirportRepository) *SeriesUsecase {
+	return &SeriesUsecase{series: seriesRepo, routes: routeRepo, airplanes: airplaneRepo, airports: airportRepo,
+		guard: airplaneGuard{routes: routeRepo, minTurnaround: defaultMinTurnaround}, timeout: 5 * time.Second, now: time.Now}
+}
+
+// Create stores a series flying the route on the given days (such as
+// "Mon,Wed,Fri" or "daily") between from and to, and schedules each occurrence.
+// Times are local HH:MM at the route's airports; arrivalTime may be empty, in
+// which case flights land after the route's block time when it has one. It
+// fails with a *domain.MaintenanceError when the airplane is in maintenance
+// during one of the flights and, with strict rotation, with a
+// *domain.RotationError when they do not chain with its other flights.
+func (u *SeriesUsecase) Create(ctx context.Context, routeCode, airplaneCode, from, to, days, departureTime, arrivalTime string) (*SeriesResult, error) {
+	weekdays, err := domain.ParseWeekdays(days)
+	if err != nil {
+		return nil, err
+	}
+	return u.create(ctx, &domain.ScheduleSeries{RouteCode: routeCode, AirplaneCode: airplaneCode, StartDate: from, EndDate: to, Days: weekdays, DepartureTime: departureTime, ArrivalTime: arrivalTime})
+}
+
+func (u *SeriesUsecase) create(ctx context.Context, s *domain.ScheduleSeries) (*SeriesResult, error) {
+	s.Normalize()
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	flights, err := u.occurrences(ctx, s, "")
+	if err != nil {
+		return nil, err
+	}
+	created, err := u.series.Create(ctx, s, flights)
+	if err != nil {
+		return nil, err
+	}
+	return &SeriesResult{Series: s, Created: created, Skipped: len(flights) - len(created)}, nil
+}
+
+// Get returns a series with the flights it generated.
+func (u *SeriesUsecase) Get(ctx context.Context, id int64) (*domain.ScheduleSeries, []domain.FlightSchedule, error) {
+	if id <= 0 {
+		return nil, nil, domain.ErrInvalidSeriesID
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	s, err := u.series.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	flights, err := u.series.Flights(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, flights, nil
+}
+
+// List returns series in creation order.
+func (u *SeriesUsecase) List(ctx context.Context, limit, offset int) ([]domain.ScheduleSeries, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.series.List(ctx, limit, offset)
+}
+
+// Extend moves the end of an active series to a later date and schedules the
+// occurrences after the old end.
+func (u *SeriesUsecase) Extend(ctx context.Context, id int64, to string) (*SeriesResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	s, err := u.active(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previousEnd := s.EndDate
+	s.EndDate = to
+	s.Normalize()
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	if s.EndDate <= previousEnd {
+		return nil, domain.ErrInvalidSeriesDates
+	}
+	flights, err := u.occurrences(ctx, s, previousEnd)
+	if err != nil {
+		return nil, err
+	}
+	created, err := u.series.Extend(ctx, s, flights)
+	if err != nil {
+		return nil, err
+	}
+	return &SeriesResult{Series: s, Created: created, Skipped: len(flights) - len(created)}, nil
+}
+
+// Amend changes the local departure and arrival times of an active series and
+// moves its flights that have not departed yet. An empty time keeps the current one.
+// It returns the flights that were moved, or a *domain.MaintenanceError when one
+// would fall in a maintenance window of the airplane flying it and, with strict
+// rotation, a *domain.RotationError when they would no longer chain.
+func (u *SeriesUsecase) Amend(ctx context.Context, id int64, departureTime, arrivalTime string) ([]domain.FlightSchedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	s, err := u.active(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if departureTime != "" {
+		s.DepartureTime = departureTime
+	}
+	if arrivalTime != "" {
+		s.ArrivalTime = arrivalTime
+	}
+	s.Normalize()
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	route, origin, destination, err := routeZones(ctx, u.routes, u.airports, s.RouteCode)
+	if err != nil {
+		return nil, err
+	}
+	flights, err := u.series.Flights(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := u.now()
+	var moved []domain.FlightSchedule
+	for _, f := range flights {
+		if !f.DepartureAt.After(now) {
+			continue
+		}
+		if err := placeSchedule(&f, s.DepartureTime, s.ArrivalTime, origin, destination, route.BlockTime()); err != nil {
+			return nil, err
+		}
+		moved = append(moved, f)
+	}
+	// Flights swapped onto another airplane are checked against its
+	// maintenance and rotation rather than the series'.
+	if err := u.guard.checkMaintenance(ctx, moved); err != nil {
+		return nil, err
+	}
+	if err := u.guard.checkRotations(ctx, moved); err != nil {
+		return nil, err
+	}
+	if err := u.series.Amend(ctx, s, moved); err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+// Cancel stops an active series. Its flights that have not departed and have no
+// bookings are removed; flights with bookings are kept and counted.
+func (u *SeriesUsecase) Cancel(ctx context.Context, id int64) (removed, kept int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	if _, err := u.active(ctx, id); err != nil {
+		return 0, 0, err
+	}
+	return u.series.Cancel(ctx, id, u.now())
+}
+
+// active loads a series that has not been cancelled.
+func (u *SeriesUsecase) active(ctx context.Context, id int64) (*domain.ScheduleSeries, error) {
+	if id <= 0 {
+		return nil, domain.ErrInvalidSeriesID
+	}
+	s, err := u.series.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Cancelled() {
+		return nil, domain.ErrSeriesCancelled
+	}
+	return s, nil
+}
+
+// occurrences builds the flights of the series departing after the given date,
+// checking the route, its airports and the airplane, which must be free of
+// maintenance on each of them and, with strict rotation, chain them.
+func (u *SeriesUsecase) occurrences(ctx context.Context, s *domain.ScheduleSeries, after string) ([]domain.FlightSchedule, error) {
+	route, origin, destination, err := routeZones(ctx, u.routes, u.airports, s.RouteCode)
+	if err != nil {
+		return nil, err
+	}
+	if route.Archived() {
+		return nil, domain.ErrRouteArchived
+	}
+	if _, err := activeAirplane(ctx, u.airplanes, s.AirplaneCode); err != nil {
+		return nil, err
+	}
+	var flights []domain.FlightSchedule
+	for _, date := range s.Dates(after) {
+		f := domain.FlightSchedule{RouteCode: s.RouteCode, AirplaneCode: s.AirplaneCode, DepartureDate: date}
+		if err := placeSchedule(&f, s.DepartureTime, s.ArrivalTime, origin, destination, route.BlockTime()); err != nil {
+			return nil, err
+		}
+		flights = append(flights, f)
+	}
+	if err := u.guard.checkMaintenance(ctx, flights); err != nil {
+		return nil, err
+	}
+	if err := u.guard.checkSeriesRotation(ctx, s.AirplaneCode, flights); err != nil {
+		return nil, err
+	}
+	return flights, nil
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// fakeSeriesRepo keeps series and their flights in memory. Flights on a date in
+// existing are treated as already scheduled and skipped.
+type fakeSeriesRepo struct {
+	items    map[int64]*domain.ScheduleSeries
+	flights  []domain.FlightSchedule
+	existing map[string]bool
+	booked   map[int64]bool
+}
+
+func (f *fakeSeriesRepo) insert(id int64, flights []domain.FlightSchedule) []domain.FlightSchedule {
+	var created []domain.FlightSchedule
+	for _, fl := range flights {
+		if f.existing[fl.DepartureDate] {
+			continue
+		}
+		fl.ID = int64(len(f.flights) + 1)
+		fl.SeriesID = id
+		f.flights = append(f.flights, fl)
+		created = append(created, fl)
+	}
+	return created
+}
+
+func (f *fakeSeriesRepo) Create(ctx context.Context, s *domain.ScheduleSeries, flights []domain.FlightSchedule) ([]domain.FlightSchedule, error) {
+	if f.items == nil {
+		f.items = make(map[int64]*domain.ScheduleSeries)
+	}
+	s.ID = int64(len(f.items) + 1)
+	stored := *s
+	f.items[s.ID] = &stored
+	return f.insert(s.ID, flights), nil
+}
+
+func (f *fakeSeriesRepo) GetByID(ctx context.Context, id int64) (*domain.ScheduleSeries, error) {
+	s, ok := f.items[id]
+	if !ok {
+		return nil, domain.ErrSeriesNotFound
+	}
+	out := *s
+	return &out, nil
+}
+
+func (f *fakeSeriesRepo) List(ctx context.Context, limit, offset int) ([]domain.ScheduleSeries, error) {
+	var out []domain.ScheduleSeries
+	for id := int64(1); id <= int64(len(f.items)); id++ {
+		out = append(out, *f.items[id])
+	}
+	return out, nil
+}
+
+func (f *fakeSeriesRepo) Flights(ctx context.Context, id int64) ([]domain.FlightSchedule, error) {
+	var out []domain.FlightSchedule
+	for _, fl := range f.flights {
+		if fl.SeriesID == id {
+			out = append(out, fl)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSeriesRepo) Extend(ctx context.Context, s *domain.ScheduleSeries, flights []domain.FlightSchedule) ([]domain.FlightSchedule, error) {
+	f.items[s.ID].EndDate = s.EndDate
+	return f.insert(s.ID, flights), nil
+}
+
+func (f *fakeSeriesRepo) Amend(ctx context.Context, s *domain.ScheduleSeries, flights []domain.FlightSchedule) error {
+	f.items[s.ID].DepartureTime, f.items[s.ID].ArrivalTime = s.DepartureTime, s.ArrivalTime
+	for _, moved := range flights {
+		for i := range f.flights {
+			if f.flights[i].ID == moved.ID {
+				f.flights[i] = moved
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeSeriesRepo) Cancel(ctx context.Context, id int64, from time.Time) (int, int, error) {
+	f.items[id].CancelledAt = from.Format(time.RFC3339)
+	var removed, kept int
+	var remaining []domain.FlightSchedule
+	for _, fl := range f.flights {
+		switch {
+		case fl.SeriesID != id || fl.DepartureAt.Before(from):
+		case f.booked[fl.ID]:
+			kept++
+		default:
+			removed++
+			continue
+		}
+		remaining = append(remaining, fl)
+	}
+	f.flights = remaining
+	return removed, kept, nil
+}
+
+func newSeriesUsecase(repo *fakeSeriesRepo) *SeriesUsecase {
+	routes := &fakeRouteRepoSched{items: map[string]bool{"RT1": true}}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"A320": true}}
+	return NewSeriesUsecase(repo, routes, planes, newSchedAirports())
+}
+
+func TestSeriesUsecase_CreateSkipsExisting(t *testing.T) {
+	repo := &fakeSeriesRepo{existing: map[string]bool{"2025-01-03": true}}
+	uc := newSeriesUsecase(repo)
+
+	res, err := uc.Create(context.Background(), "rt1", "a320", "2025-01-01", "2025-01-12", "Mon,Wed,Fri", "08:30", "11:20")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if res.Series.ID != 1 || len(res.Created) != 4 || res.Skipped != 1 {
+		t.Fatalf("expected four flights and one skipped, got %+v", res)
+	}
+	first := res.Created[0]
+	if first.DepartureDate != "2025-01-01" || first.DepartureAt.Format(time.RFC3339) != "2025-01-01T01:30:00Z" || first.SeriesID != 1 {
+		t.Fatalf("unexpected first flight %+v", first)
+	}
+
+	if _, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-01", "2025-01-12", "Someday", "08:30", ""); err != domain.ErrInvalidSeriesDays {
+		t.Fatalf("want invalid days, got %v", err)
+	}
+	if _, err := uc.Create(context.Background(), "RT1", "B737", "2025-01-01", "2025-01-12", "daily", "08:30", ""); err != domain.ErrAirplaneNotFound {
+		t.Fatalf("want airplane not found, got %v", err)
+	}
+}
+
+func TestSeriesUsecase_ExtendAmendCancel(t *testing.T) {
+	repo := &fakeSeriesRepo{booked: map[int64]bool{}}
+	uc := newSeriesUsecase(repo)
+	res, err := uc.Create(context.Background(), "RT1", "A320", "2025-01-01", "2025-01-07", "Wed", "08:30", "")
+	if err != nil || len(res.Created) != 1 {
+		t.Fatalf("create: %+v err=%v", res, err)
+	}
+
+	if _, err := uc.Extend(context.Background(), 1, "2025-01-05"); err != domain.ErrInvalidSeriesDates {
+		t.Fatalf("want an earlier end rejected, got %v", err)
+	}
+	ext, err := uc.Extend(context.Background(), 1, "2025-01-21")
+	if err != nil || len(ext.Created) != 2 || ext.Created[0].DepartureDate != "2025-01-08" || ext.Series.EndDate != "2025-01-21" {
+		t.Fatalf("expected the next two Wednesdays, got %+v err=%v", ext, err)
+	}
+
+	// Only flights that have not departed move.
+	uc.now = func() time.Time { return time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC) }
+	moved, err := uc.Amend(context.Background(), 1, "09:00", "12:00")
+	if err != nil || len(moved) != 1 || moved[0].DepartureDate != "2025-01-15" {
+		t.Fatalf("expected only the 2025-01-15 flight moved, got %+v err=%v", moved, err)
+	}
+	if got := repo.flights[2].LocalDeparture().Format("15:04"); got != "09:00" {
+		t.Fatalf("expected the amended departure stored, got %s", got)
+	}
+	if got := repo.flights[0].LocalDeparture().Format("15:04"); got != "08:30" {
+		t.Fatalf("expected departed flights left alone, got %s", got)
+	}
+
+	uc.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+	repo.booked[2] = true
+	removed, kept, err := uc.Cancel(context.Background(), 1)
+	if err != nil || removed != 2 || kept != 1 {
+		t.Fatalf("want two removed and the booked flight kept, got %d/%d err=%v", removed, kept, err)
+	}
+	if _, _, err := uc.Cancel(context.Background(), 1); err != domain.ErrSeriesCancelled {
+		t.Fatalf("want cancelled series rejected, got %v", err)
+	}
+	if _, err := uc.Extend(context.Background(), 1, "2025-02-01"); err != domain.ErrSeriesCancelled {
+		t.Fatalf("want cancelled series not extended, got %v", err)
+	}
+	if _, _, err := uc.Get(context.Background(), 0); err != domain.ErrInvalidSeriesID {
+		t.Fatalf("want invalid series id, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// DefaultAirlinePrefix is the ticketing prefix used when none is configured.
+const DefaultAirlinePrefix = "126"
+
+// TicketUsecase issues e-tickets for bookings and moves their coupons through check-in, boarding and refund.
+type TicketUsecase struct {
+	tickets       domain.TicketRepository
+	bookings      domain.BookingRepository
+	airlinePrefix string
+	timeout       time.Duration
+}
+
+// NewTicketUsecase builds a TicketUsecase issuing numbers under the given airline prefix.
+func NewTicketUsecase(ticketRepo domain.TicketRepository, bookingRepo domain.BookingRepository, airlinePrefix string) *TicketUsecase {
+	prefix := strings.TrimSpace(airlinePrefix)
+	if prefix == "" {
+		prefix = DefaultAirlinePrefix
+	}
+	return &TicketUsecase{tickets: ticketRepo, bookings: bookingRepo, airlinePrefix: prefix, timeout: 5 * time.Second}
+}
+
+// Issue creates a ticket for the booking with one open coupon per flight segment.
+func (u *TicketUsecase) Issue(ctx context.Context, booking *domain.Booking, scheduleIDs ...int64) (*domain.Ticket, error) {
+	if booking == nil || booking.ID <= 0 {
+		return nil, domain.ErrBookingNotFound
+	}
+	if len(scheduleIDs) == 0 {
+		scheduleIDs = []int64{booking.ScheduleID}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	serial, err := u.tickets.NextSerial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	number, err := domain.NewTicketNumber(u.airlinePrefix, serial)
+	if err != nil {
+		return nil, err
+	}
+	ticket := &domain.Ticket{
+		Number:           number,
+		BookingID:        booking.ID,
+		BookingReference: booking.Reference,
+		PassengerName:    booking.PassengerName,
+	}
+	for i, id := range scheduleIDs {
+		ticket.Coupons = append(ticket.Coupons, domain.Coupon{Sequence: i + 1, ScheduleID: id, Status: domain.CouponStatusOpen})
+	}
+	ticket.Normalize()
+	if err := ticket.Validate(); err != nil {
+		return nil, err
+	}
+	if err := u.tickets.Create(ctx, ticket); err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// GetByNumber fetches a ticket and its coupons.
+func (u *TicketUsecase) GetByNumber(ctx context.Context, number string) (*domain.Ticket, error) {
+	number = strings.TrimSpace(number)
+	if !domain.ValidTicketNumber(number) {
+		return nil, domain.ErrInvalidTicketNumber
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.tickets.GetByNumber(ctx, number)
+}
+
+// ListByBooking returns the tickets issued for a booking reference.
+func (u *TicketUsecase) ListByBooking(ctx context.Context, reference string) ([]domain.Ticket, error) {
+	ref := strings.ToUpper(strings.TrimSpace(reference))
+	if len(ref) < 6 || len(ref) > 32 {
+		return nil, domain.ErrInvalidBookingReference
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	booking, err := u.bookings.GetByReference(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return u.tickets.ListByBooking(ctx, booking.ID)
+}
+
+// CheckIn marks a coupon as checked in for its flight.
+func (u *TicketUsecase) CheckIn(ctx context.Context, number string, sequence int) (*domain.Coupon, error) {
+	return u.transition(ctx, number, sequence, domain.CouponStatusCheckedIn)
+}
+
+// MarkFlown records that the passenger travelled on the coupon's flight.
+func (u *TicketUsecase) MarkFlown(ctx context.Context, number string, sequence int) (*domain.Coupon, error) {
+	return u.transition(ctx, number, sequence, domain.CouponStatusFlown)
+}
+
+// Refund refunds an unused coupon.
+func (u *TicketUsecase) Refund(ctx context.Context, number string, sequence int) (*domain.Coupon, error) {
+	return u.transition(ctx, number, sequence, domain.CouponStatusRefunded)
+}
+
+// refundOpen refunds every unused coupon of a booking's tickets. A coupon
+// another command moves on meanwhile is left as that command set it.
+func (u *TicketUsecase) refundOpen(ctx context.Context, bookingID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	tickets, err := u.tickets.ListByBooking(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+	for _, t := range tickets {
+		for _, c := range t.Coupons {
+			if c.Status != domain.CouponStatusOpen {
+				continue
+			}
+			err := u.tickets.UpdateCouponStatus(ctx, c.ID, domain.CouponStatusOpen, domain.CouponStatusRefunded)
+			if err != nil && !errors.Is(err, domain.ErrInvalidCouponTransition) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (u *TicketUsecase) transition(ctx context.Context, number string, sequence int, status string) (*domain.Coupon, error) {
+	ticket, err := u.GetByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	coupon, err := ticket.Coupon(sequence)
+	if err != nil {
+		return nil, err
+	}
+	if !coupon.CanTransition(status) {
+		return nil, domain.ErrInvalidCouponTransition
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	// The update is guarded on the status read, so a concurrent change fails
+	// with domain.ErrInvalidCouponTransition rather than being overwritten.
+	if err := u.tickets.UpdateCouponStatus(ctx, coupon.ID, coupon.Status, status); err != nil {
+		return nil, err
+	}
+	coupon.Status = status
+	return coupon, nil
+}
@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+type fakeTicketRepo struct {
+	serial  int64
+	nextID  int64
+	tickets map[string]*domain.Ticket
+	// failAt makes the failAt-th and later creates fail; 0 never fails.
+	failAt  int
+	created int
+}
+
+func newFakeTicketRepo() *fakeTicketRepo {
+	return &fakeTicketRepo{tickets: make(map[string]*domain.Ticket)}
+}
+
+func (f *fakeTicketRepo) NextSerial(ctx context.Context) (int64, error) {
+	f.serial++
+	return f.serial, nil
+}
+
+func (f *fakeTicketRepo) Create(ctx context.Context, t *domain.Ticket) error {
+	f.created++
+	if f.failAt > 0 && f.created >= f.failAt {
+		return errTicketing
+	}
+	if _, ok := f.tickets[t.Number]; ok {
+		return domain.ErrTicketExists
+	}
+	f.nextID++
+	t.ID = f.nextID
+	for i := range t.Coupons {
+		f.nextID++
+		t.Coupons[i].ID = f.nextID
+		t.Coupons[i].TicketID = t.ID
+	}
+	copy := *t
+	copy.Coupons = append([]domain.Coupon(nil), t.Coupons...)
+	f.tickets[t.Number] = &copy
+	return nil
+}
+
+func (f *fakeTicketRepo) GetByNumber(ctx context.Context, number string) (*domain.Ticket, error) {
+	t, ok := f.tickets[number]
+	if !ok {
+		return nil, domain.ErrTicketNotFound
+	}
+	copy := *t
+	copy.Coupons = append([]domain.Coupon(nil), t.Coupons...)
+	return &copy, nil
+}
+
+func (f *fakeTicketRepo) ListByBooking(ctx context.Context, bookingID int64) ([]domain.Ticket, error) {
+	var out []domain.Ticket
+	for _, t := range f.tickets {
+		if t.BookingID == bookingID {
+			out = append(out, *t)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeTicketRepo) UpdateCouponStatus(ctx context.Context, couponID int64, from, to string) error {
+	for _, t := range f.tickets {
+		for i := range t.Coupons {
+			if t.Coupons[i].ID == couponID {
+				if t.Coupons[i].Status != from {
+					return domain.ErrInvalidCouponTransition
+				}
+				t.Coupons[i].Status = to
+				return nil
+			}
+		}
+	}
+	return domain.ErrCouponNotFound
+}
+
+func TestTicketUsecase_IssueAndLifecycle(t *testing.T) {
+	tickets := newFakeTicketRepo()
+	bookings := &mockBookingRepo{}
+	uc := NewTicketUsecase(tickets, bookings, "")
+	booking := &domain.Booking{ID: 5, Reference: "BK-AAAAAA", ScheduleID: 10, PassengerName: "Alice"}
+
+	ticket, err := uc.Issue(context.Background(), booking)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if ticket.Number != "1260000000011" {
+		t.Fatalf("unexpected ticket number %q", ticket.Number)
+	}
+	if len(ticket.Coupons) != 1 || ticket.Coupons[0].ScheduleID != 10 || ticket.Coupons[0].Status != domain.CouponStatusOpen {
+		t.Fatalf("unexpected coupons: %+v", ticket.Coupons)
+	}
+
+	if _, err := uc.MarkFlown(context.Background(), ticket.Number, 1); err != domain.ErrInvalidCouponTransition {
+		t.Fatalf("want invalid transition, got %v", err)
+	}
+	c, err := uc.CheckIn(context.Background(), ticket.Number, 1)
+	if err != nil || c.Status != domain.CouponStatusCheckedIn {
+		t.Fatalf("check in: err=%v coupon=%+v", err, c)
+	}
+	c, err = uc.MarkFlown(context.Background(), ticket.Number, 1)
+	if err != nil || c.Status != domain.CouponStatusFlown {
+		t.Fatalf("flown: err=%v coupon=%+v", err, c)
+	}
+	if _, err := uc.Refund(context.Background(), ticket.Number, 1); err != domain.ErrInvalidCouponTransition {
+		t.Fatalf("want refund rejected after flight, got %v", err)
+	}
+	if _, err := uc.CheckIn(context.Background(), ticket.Number, 2); err != domain.ErrCouponNotFound {
+		t.Fatalf("want coupon not found, got %v", err)
+	}
+}
+
+// racingTicketRepo checks every coupon in right after it is read, as a
+// concurrent command would.
+type racingTicketRepo struct {
+	*fakeTicketRepo
+}
+
+func (r racingTicketRepo) GetByNumber(ctx context.Context, number string) (*domain.Ticket, error) {
+	t, err := r.fakeTicketRepo.GetByNumber(ctx, number)
+	if err == nil {
+		for i := range r.tickets[number].Coupons {
+			r.tickets[number].Coupons[i].Status = domain.CouponStatusCheckedIn
+		}
+	}
+	return t, err
+}
+
+func TestTicketUsecase_ConcurrentTransitionFails(t *testing.T) {
+	tickets := newFakeTicketRepo()
+	ticket, err := NewTicketUsecase(tickets, &mockBookingRepo{}, "").Issue(context.Background(), &domain.Booking{ID: 5, Reference: "BK-AAAAAA", ScheduleID: 10, PassengerName: "Alice"})
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	uc := NewTicketUsecase(racingTicketRepo{tickets}, &mockBookingRepo{}, "")
+	if _, err := uc.Refund(context.Background(), ticket.Number, 1); err != domain.ErrInvalidCouponTransition {
+		t.Fatalf("want the refund to lose to the check-in, got %v", err)
+	}
+	if got := tickets.tickets[ticket.Number].Coupons[0].Status; got != domain.CouponStatusCheckedIn {
+		t.Fatalf("want the check-in kept, got %s", got)
+	}
+}
+
+func TestTicketUsecase_MultiSegmentAndLookup(t *testing.T) {
+	tickets := newFakeTicketRepo()
+	bookings := &mockBookingRepo{bookings: map[string]*domain.Booking{
+		"BK-AAAAAA": {ID: 5, Reference: "BK-AAAAAA", ScheduleID: 10, PassengerName: "Alice"},
+	}}
+	uc := NewTicketUsecase(tickets, bookings, "990")
+
+	ticket, err := uc.Issue(context.Background(), bookings.bookings["BK-AAAAAA"], 10, 11)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if len(ticket.Coupons) != 2 || ticket.Coupons[1].Sequence != 2 || ticket.Coupons[1].ScheduleID != 11 {
+		t.Fatalf("unexpected coupons: %+v", ticket.Coupons)
+	}
+	list, err := uc.ListByBooking(context.Background(), "bk-aaaaaa")
+	if err != nil || len(list) != 1 {
+		t.Fatalf("list by booking: err=%v len=%d", err, len(list))
+	}
+	if _, err := uc.GetByNumber(context.Background(), "123"); err != domain.ErrInvalidTicketNumber {
+		t.Fatalf("want invalid number, got %v", err)
+	}
+	if _, err := uc.Issue(context.Background(), &domain.Booking{}); err != domain.ErrBookingNotFound {
+		t.Fatalf("want booking not found, got %v", err)
+	}
+	if _, err := NewTicketUsecase(tickets, bookings, "AB1").Issue(context.Background(), bookings.bookings["BK-AAAAAA"]); err != domain.ErrInvalidAirlinePrefix {
+		t.Fatalf("want invalid prefix, got %v", err)
+	}
+}
+
+func TestBookingUsecase_Create_IssuesTicket(t *testing.T) {
+	// The mock booking repository does not assign identifiers, which ticketing requires.
+	bookingRepo := &idAssigningBookingRepo{BookingRepository: &mockBookingRepo{}, next: 40}
+	scheduleRepo := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-01"},
+	}}
+	airplaneRepo := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 10}}}
+	tickets := newFakeTicketRepo()
+	uc := NewBookingUsecase(bookingRepo, scheduleRepo, &mockRouteRepo{}, airplaneRepo)
+	uc.WithTicketing(NewTicketUsecase(tickets, bookingRepo, "126"))
+	uc.generateRef = func(context.Context) (string, error) { return "BK-TICKET", nil }
+
+	booking, err := uc.Create(context.Background(), 1, "Alice")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	issued, err := uc.Tickets(context.Background(), booking.ID)
+	if err != nil || len(issued) != 1 {
+		t.Fatalf("tickets: err=%v len=%d", err, len(issued))
+	}
+	if issued[0].Coupons[0].ScheduleID != 1 {
+		t.Fatalf("coupon should reference schedule 1: %+v", issued[0].Coupons[0])
+	}
+
+	if _, err := uc.Cancel(context.Background(), booking.Reference); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	issued, _ = uc.Tickets(context.Background(), booking.ID)
+	if issued[0].Coupons[0].Status != domain.CouponStatusRefunded {
+		t.Fatalf("cancel should refund the open coupon, got %s", issued[0].Coupons[0].Status)
+	}
+}
+
+var errTicketing = errors.New("ticketing down")
+
+func TestBookingUsecase_TicketingFailureReleasesSeat(t *testing.T) {
+	bookingRepo := &idAssigningBookingRepo{BookingRepository: &mockBookingRepo{bookings: map[string]*domain.Booking{}}, next: 40}
+	scheduleRepo := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{
+		1: {ID: 1, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: "2025-01-01"},
+	}}
+	airplaneRepo := &mockAirplaneRepo{airplanes: map[string]*domain.Airplane{"A320": {Code: "A320", SeatCapacity: 10}}}
+	tickets := newFakeTicketRepo()
+	tickets.failAt = 1
+	uc := NewBookingUsecase(bookingRepo, scheduleRepo, &mockRouteRepo{}, airplaneRepo)
+	uc.WithTicketing(NewTicketUsecase(tickets, bookingRepo, "126"))
+	refs := []string{"BK-FAILED", "BK-HELD"}
+	uc.generateRef = func(context.Context) (string, error) {
+		ref := refs[0]
+		refs = refs[1:]
+		return ref, nil
+	}
+
+	if _, err := uc.Create(context.Background(), 1, "Alice"); !errors.Is(err, errTicketing) {
+		t.Fatalf("want the ticketing error, got %v", err)
+	}
+	if b, _ := bookingRepo.GetByReference(context.Background(), "BK-FAILED"); b.Status != domain.BookingStatusCancelled {
+		t.Fatalf("want the unticketed booking cancelled, got %s", b.Status)
+	}
+
+	held, err := uc.Hold(context.Background(), 1, "Bob")
+	if err != nil {
+		t.Fatalf("hold: %v", err)
+	}
+	if _, err := uc.Confirm(context.Background(), held.Reference); !errors.Is(err, errTicketing) {
+		t.Fatalf("want the ticketing error, got %v", err)
+	}
+	if b, _ := bookingRepo.GetByReference(context.Background(), held.Reference); b.Status != domain.BookingStatusHeld {
+		t.Fatalf("want the booking back on hold, got %s", b.Status)
+	}
+}
+
+type idAssigningBookingRepo struct {
+	domain.BookingRepository
+	next int64
+}
+
+func (r *idAssigningBookingRepo) Create(ctx context.Context, b *domain.Booking) error {
+	r.next++
+	b.ID = r.next
+	return r.BookingRepository.Create(ctx, b)
+}
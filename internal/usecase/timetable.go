@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// TimetableEntry is one recurring flight in an exchanged timetable: a leg
+// between two airports flown on selected weekdays over a period.
+type TimetableEntry struct {
+	Line            int // source line of an imported entry, for error reports
+	OriginCode      string
+	DestinationCode string
+	// Series carries the period, days and local times. Its route code is filled
+	// on export and resolved from the airports on import.
+	Series domain.ScheduleSeries
+	// AircraftType is the IATA code of the airplane's type. It is filled on
+	// export and, on import, picks the airplane when Series has none.
+	AircraftType string
+	// FirstDeparture and FirstArrival are the local instants of the first
+	// flight, filled on export so encoders can state UTC offsets and overnight
+	// arrivals. FirstArrival is zero when the arrival is unplanned.
+	FirstDeparture time.Time
+	FirstArrival   time.Time
+}
+
+// ImportError ties a rejected timetable entry to its line in the source file.
+type ImportError struct {
+	Line int
+	Err  error
+}
+
+func (e ImportError) Error() string { return fmt.Sprintf("line %d: %v", e.Line, e.Err) }
+
+func (e ImportError) Unwrap() error { return e.Err }
+
+// ImportResult lists the series an import created, the routes it had to add
+// and the entries it rejected.
+type ImportResult struct {
+	Series []SeriesResult
+	Routes []domain.Route
+	Errors []ImportError
+}
+
+// TimetableUsecase moves schedules in and out of the system as recurring
+// timetable entries.
+type TimetableUsecase struct {
+	series    *SeriesUsecase
+	schedules domain.FlightScheduleRepository
+	timeout   time.Duration
+}
+
+// NewTimetableUsecase constructs a TimetableUsecase on top of the series usecase.
+func NewTimetableUsecase(series *SeriesUsecase, scheduleRepo domain.FlightScheduleRepository) *TimetableUsecase {
+	return &TimetableUsecase{series: series, schedules: scheduleRepo, timeout: 5 * time.Second}
+}
+
+// Import creates a series for each entry, adding a route coded ORIGIN-DESTINATION
+// when none links the two airports yet. Both airports must already exist. An
+// entry without an airplane is flown by the first active airplane, by
+// registration, of its aircraft type; it fails with domain.ErrNoAirplaneOfType
+// when the fleet has none. Entries that fail are reported with their line and
+// skipped.
+func (u *TimetableUsecase) Import(ctx context.Context, entries []TimetableEntry) (*ImportResult, error) {
+	routes, err := u.routeIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fleet, err := u.fleetByType(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := &ImportResult{}
+	for _, e := range entries {
+		if e.Series.AirplaneCode == "" {
+			code, ok := fleet[strings.ToUpper(strings.TrimSpace(e.AircraftType))]
+			if !ok {
+				res.Errors = append(res.Errors, ImportError{Line: e.Line, Err: fmt.Errorf("aircraft type %q: %w", e.AircraftType, domain.ErrNoAirplaneOfType)})
+				continue
+			}
+			e.Series.AirplaneCode = code
+		}
+		routeCode, created, err := u.importRoute(ctx, routes, e)
+		if created != nil {
+			res.Routes = append(res.Routes, *created)
+		}
+		if err != nil {
+			res.Errors = append(res.Errors, ImportError{Line: e.Line, Err: err})
+			continue
+		}
+		s := e.Series
+		s.RouteCode = routeCode
+		sr, err := u.series.create(ctx, &s)
+		if err != nil {
+			res.Errors = append(res.Errors, ImportError{Line: e.Line, Err: err})
+			continue
+		}
+		res.Series = append(res.Series, *sr)
+	}
+	return res, nil
+}
+
+// Export lists every active series and every flight outside a series as
+// timetable entries, series first, with the IATA code of each airplane's type;
+// it is empty for untyped airplanes.
+func (u *TimetableUsecase) Export(ctx context.Context) ([]TimetableEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	routes := make(map[string]*exportRoute)
+	route := func(code string) (*exportRoute, error) {
+		if r, ok := routes[code]; ok {
+			return r, nil
+		}
+		rt, origin, destination, err := routeZones(ctx, u.series.routes, u.series.airports, code)
+		if err != nil {
+			return nil, err
+		}
+		r := &exportRoute{route: *rt, origin: origin, destination: destination}
+		routes[code] = r
+		return r, nil
+	}
+	types := make(map[string]string)
+	aircraftType := func(code string) (string, error) {
+		if t, ok := types[code]; ok {
+			return t, nil
+		}
+		a, err := u.series.airplanes.GetByCode(ctx, code)
+		if err != nil {
+			return "", err
+		}
+		types[code] = a.TypeIATACode
+		return a.TypeIATACode, nil
+	}
+
+	var out []TimetableEntry
+	for offset := 0; ; offset += pageSize {
+		page, err := u.series.series.List(ctx, pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range page {
+			dates := s.Dates("")
+			if s.Cancelled() || len(dates) == 0 {
+				continue
+			}
+			r, err := route(s.RouteCode)
+			if err != nil {
+				return nil, err
+			}
+			first := domain.FlightSchedule{DepartureDate: dates[0]}
+			if err := placeSchedule(&first, s.DepartureTime, s.ArrivalTime, r.origin, r.destination, r.route.BlockTime()); err != nil {
+				return nil, err
+			}
+			e := r.entry(s, first)
+			if e.AircraftType, err = aircraftType(s.AirplaneCode); err != nil {
+				return nil, err
+			}
+			out = append(out, e)
+		}
+		if len(page) < pageSize {
+			break
+		}
+	}
+	for offset := 0; ; offset += pageSize {
+		page, err := u.schedules.List(ctx, "", pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range page {
+			if f.SeriesID != 0 {
+				continue
+			}
+			r, err := route(f.RouteCode)
+			if err != nil {
+				return nil, err
+			}
+			day, err := time.Parse("2006-01-02", f.DepartureDate)
+			if err != nil {
+				return nil, domain.ErrInvalidScheduleDate
+			}
+			s := domain.ScheduleSeries{RouteCode: f.RouteCode, AirplaneCode: f.AirplaneCode, StartDate: f.DepartureDate, EndDate: f.DepartureDate,
+				Days: 1 << day.Weekday(), DepartureTime: f.LocalDeparture().Format("15:04")}
+			if !f.ArrivalAt.IsZero() {
+				s.ArrivalTime = f.LocalArrival().Format("15:04")
+			}
+			e := r.entry(s, f)
+			if e.AircraftType, err = aircraftType(f.AirplaneCode); err != nil {
+				return nil, err
+			}
+			out = append(out, e)
+		}
+		if len(page) < pageSize {
+			break
+		}
+	}
+	return out, nil
+}
+
+// pageSize is the largest page the repositories hand out.
+const pageSize = 500
+
+type exportRoute struct {
+	route               domain.Route
+	origin, destination *time.Location
+}
+
+func (r *exportRoute) entry(s domain.ScheduleSeries, first domain.FlightSchedule) TimetableEntry {
+	e := TimetableEntry{OriginCode: r.route.OriginCode, DestinationCode: r.route.DestinationCode, Series: s, FirstDeparture: first.DepartureAt.In(r.origin)}
+	if !first.ArrivalAt.IsZero() {
+		e.FirstArrival = first.ArrivalAt.In(r.destination)
+	}
+	return e
+}
+
+// routeIndex maps "ORIGIN-DESTINATION" to the code of a route between them.
+func (u *TimetableUsecase) routeIndex(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	index := make(map[string]string)
+	for offset := 0; ; offset += pageSize {
+		page, err := u.series.routes.List(ctx, pageSize, offset, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range page {
+			key := r.OriginCode + "-" + r.DestinationCode
+			if _, ok := index[key]; !ok {
+				index[key] = r.Code
+			}
+		}
+		if len(page) < pageSize {
+			return index, nil
+		}
+	}
+}
+
+// fleetByType maps the IATA code of each aircraft type to its first active
+// airplane by registration.
+func (u *TimetableUsecase) fleetByType(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	fleet := make(map[string]string)
+	for offset := 0; ; offset += pageSize {
+		page, err := u.series.airplanes.List(ctx, pageSize, offset, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range page {
+			if _, ok := fleet[a.TypeIATACode]; a.TypeIATACode != "" && !ok {
+				fleet[a.TypeIATACode] = a.Code
+			}
+		}
+		if len(page) < pageSize {
+			return fleet, nil
+		}
+	}
+}
+
+// importRoute finds the route between the entry's airports, creating it when
+// missing. It returns the route it created, if any.
+func (u *TimetableUsecase) importRoute(ctx context.Context, index map[string]string, e TimetableEntry) (string, *domain.Route, error) {
+	r := &domain.Route{OriginCode: e.OriginCode, DestinationCode: e.DestinationCode}
+	r.Normalize()
+	r.Code = r.OriginCode + "-" + r.DestinationCode
+	if code, ok := index[r.Code]; ok {
+		return code, nil, nil
+	}
+	if err := r.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	if _, err := activeAirport(ctx, u.series.airports, r.OriginCode); err != nil {
+		return "", nil, err
+	}
+	if _, err := activeAirport(ctx, u.series.airports, r.DestinationCode); err != nil {
+		return "", nil, err
+	}
+	if _, err := activeAirplane(ctx, u.series.airplanes, e.Series.AirplaneCode); err != nil {
+		return "", nil, err
+	}
+	if err := u.series.routes.Create(ctx, r); err != nil {
+		return "", nil, err
+	}
+	index[r.Code] = r.Code
+	return r.Code, r, nil
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+func TestTimetableUsecase_Import(t *testing.T) {
+	routes := &fakeRouteRepo{items: map[string]domain.Route{"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}}}
+	airports := newSchedAirports()
+	airports.list = append(airports.list, domain.Airport{Code: "SUB", City: "Surabaya", TimeZone: "Asia/Jakarta"})
+	// PK-GQA and PK-GQB are both A320s; PK-GQA is archived.
+	planes := &fakeAirplaneRepoSched{
+		items:    map[string]bool{"PK-GQA": true, "PK-GQB": true, "PK-LKA": true},
+		archived: map[string]bool{"PK-GQA": true},
+		types:    map[string]string{"PK-GQA": "320", "PK-GQB": "320"},
+	}
+	uc := NewTimetableUsecase(NewSeriesUsecase(&fakeSeriesRepo{}, routes, planes, airports), &fakeScheduleRepo{})
+
+	series := domain.ScheduleSeries{StartDate: "2025-01-01", EndDate: "2025-01-07", Days: domain.AllWeekdays, DepartureTime: "08:30"}
+	res, err := uc.Import(context.Background(), []TimetableEntry{
+		{Line: 3, OriginCode: "CGK", DestinationCode: "DPS", AircraftType: "320", Series: series},
+		{Line: 4, OriginCode: "SUB", DestinationCode: "CGK", AircraftType: "320", Series: series},
+		{Line: 5, OriginCode: "CGK", DestinationCode: "KNO", AircraftType: "320", Series: series},
+		{Line: 6, OriginCode: "SUB", DestinationCode: "DPS", AircraftType: "737", Series: series},
+	})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(res.Series) != 2 || res.Series[0].Series.RouteCode != "RT1" || res.Series[0].Series.AirplaneCode != "PK-GQB" || res.Series[1].Series.RouteCode != "SUB-CGK" || len(res.Series[1].Created) != 7 {
+		t.Fatalf("expected series on the existing and a new route, got %+v", res.Series)
+	}
+	if len(res.Routes) != 1 || res.Routes[0].Code != "SUB-CGK" {
+		t.Fatalf("expected one route added, got %+v", res.Routes)
+	}
+	if len(res.Errors) != 2 || res.Errors[0].Line != 5 || !errors.Is(res.Errors[0], domain.ErrAirportNotFound) ||
+		res.Errors[1].Line != 6 || !errors.Is(res.Errors[1], domain.ErrNoAirplaneOfType) {
+		t.Fatalf("expected line errors for the unknown airport and aircraft type, got %v", res.Errors)
+	}
+	if _, ok := routes.items["SUB-DPS"]; ok {
+		t.Fatalf("a rejected line must not add its route")
+	}
+}
+
+func TestTimetableUsecase_Export(t *testing.T) {
+	routes := &fakeRouteRepo{items: map[string]domain.Route{"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}}}
+	planes := &fakeAirplaneRepoSched{items: map[string]bool{"320": true}, types: map[string]string{"320": "320"}}
+	seriesRepo := &fakeSeriesRepo{}
+	schedules := &fakeScheduleRepo{}
+	uc := NewTimetableUsecase(NewSeriesUsecase(seriesRepo, routes, planes, newSchedAirports()), schedules)
+
+	if _, err := uc.series.Create(context.Background(), "RT1", "320", "2025-01-01", "2025-01-31", "Fri", "23:30", "02:20"); err != nil {
+		t.Fatalf("create series: %v", err)
+	}
+	if _, err := uc.series.Create(context.Background(), "RT1", "320", "2025-02-01", "2025-02-28", "daily", "10:00", ""); err != nil {
+		t.Fatalf("create series: %v", err)
+	}
+	seriesRepo.items[2].CancelledAt = "2025-01-15T00:00:00Z"
+	schedules.items = []domain.FlightSchedule{
+		{ID: 1, RouteCode: "RT1", AirplaneCode: "320", DepartureDate: "2025-01-03", SeriesID: 1},
+		{ID: 2, RouteCode: "RT1", AirplaneCode: "320", DepartureDate: "2025-03-02", DepartureAt: time.Date(2025, 3, 2, 0, 15, 0, 0, time.UTC), OriginTimeZone: "Asia/Jakarta"},
+	}
+
+	entries, err := uc.Export(context.Background())
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("want the active series and the one-off flight, got %+v", entries)
+	}
+	first := entries[0]
+	if first.OriginCode != "CGK" || first.AircraftType != "320" || first.DestinationCode != "DPS" || first.Series.Days.String() != "Fri" ||
+		first.FirstDeparture.Format("2006-01-02 15:04 -0700") != "2025-01-03 23:30 +0700" ||
+		first.FirstArrival.Format("2006-01-02 15:04 -0700") != "2025-01-04 02:20 +0800" {
+		t.Fatalf("unexpected series entry %+v", first)
+	}
+	oneOff := entries[1].Series
+	if oneOff.StartDate != "2025-03-02" || oneOff.EndDate != "2025-03-02" || oneOff.Days.String() != "Sun" || oneOff.DepartureTime != "07:15" || oneOff.ArrivalTime != "" || !entries[1].FirstArrival.IsZero() {
+		t.Fatalf("unexpected one-off entry %+v", entries[1])
+	}
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// Reasons a search trace gives for leaving a candidate out of the results.
+const (
+	RejectWrongDate          = "wrong date"           // departs outside the requested dates
+	RejectFull               = "full"                 // fewer seats left than travellers
+	RejectUnknownAirplane    = "unknown airplane"     // the airplane is missing or has no seats configured
+	RejectOtherAircraftType  = "other aircraft type"  // flown by a different aircraft type than requested
+	RejectOutsideWindow      = "outside time window"  // departs outside the time-of-day window
+	RejectNoArrival          = "no planned arrival"   // a connection cannot be timed
+	RejectNoOnwardFlight     = "no onward flight"     // nothing leaves the connecting airport for the destination
+	RejectConnectionTooShort = "connection too short" // departs before the minimum connection time
+	RejectConnectionTooLong  = "connection too long"  // departs after the maximum layover
+	RejectCircuitous         = "circuitous"           // flies too far out of the way of the direct route
+	RejectRepositoryError    = "repository error"     // a lookup failed; the search returns the error
+)
+
+// TraceEntry records one candidate a search looked at. Reason is empty when the
+// candidate was kept. Connection rejections name the second leg in ScheduleID
+// and the first leg in After.
+type TraceEntry struct {
+	Leg             int // 1 for direct flights and first legs, 2 for second legs
+	ScheduleID      int64
+	After           int64
+	RouteCode       string
+	OriginCode      string
+	DestinationCode string
+	DepartureDate   string
+	Reason          string
+	Detail          string
+}
+
+// SearchTrace collects the candidates examined by FindDirectFlights and
+// FindTransitFlights when set on SearchQuery.Explain. Explaining a dated search
+// also loads first legs on other dates so they can be reported as wrong-date.
+type SearchTrace struct {
+	Entries []TraceEntry
+}
+
+// Rejected returns the entries that did not make it into the results.
+func (t *SearchTrace) Rejected() []TraceEntry {
+	if t == nil {
+		return nil
+	}
+	var out []TraceEntry
+	for _, e := range t.Entries {
+		if e.Reason != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (t *SearchTrace) add(e TraceEntry) {
+	if t != nil {
+		t.Entries = append(t.Entries, e)
+	}
+}
+
+// candidate records a leg; reason and detail are empty when it was kept.
+func (t *SearchTrace) candidate(leg int, row domain.FlightAvailability, reason, detail string) {
+	t.add(TraceEntry{
+		Leg:             leg,
+		ScheduleID:      row.Schedule.ID,
+		RouteCode:       row.Schedule.RouteCode,
+		OriginCode:      row.OriginCode,
+		DestinationCode: row.DestinationCode,
+		DepartureDate:   row.Schedule.DepartureDate,
+		Reason:          reason,
+		Detail:          detail,
+	})
+}
+
+// connection records a second leg that cannot be reached from the first.
+func (t *SearchTrace) connection(first, second domain.FlightAvailability, reason, detail string) {
+	t.add(TraceEntry{
+		Leg:             2,
+		ScheduleID:      second.Schedule.ID,
+		After:           first.Schedule.ID,
+		RouteCode:       second.Schedule.RouteCode,
+		OriginCode:      second.OriginCode,
+		DestinationCode: second.DestinationCode,
+		DepartureDate:   second.Schedule.DepartureDate,
+		Reason:          reason,
+		Detail:          detail,
+	})
+}
+
+// failed records a lookup error and returns it so callers can surface it.
+func (t *SearchTrace) failed(leg int, origin, destination string, err error) error {
+	t.add(TraceEntry{Leg: leg, OriginCode: origin, DestinationCode: destination, Reason: RejectRepositoryError, Detail: err.Error()})
+	return err
+}
+
+// rejectLeg explains why a leg cannot be sold to the query's travellers, or
+// returns an empty reason when it can.
+func (c searchCriteria) rejectLeg(row domain.FlightAvailability) (reason, detail string) {
+	switch {
+	case row.SeatCapacity <= 0:
+		return RejectUnknownAirplane, fmt.Sprintf("airplane %s has no seats", row.Schedule.AirplaneCode)
+	case row.SeatsAvailable() < c.seats:
+		return RejectFull, fmt.Sprintf("%d of %d seats left, %d needed", max(row.SeatsAvailable(), 0), row.SeatCapacity, c.seats)
+	case c.aircraftType != "" && !row.OfAircraftType(c.aircraftType):
+		if row.AircraftTypeCode == "" {
+			return RejectOtherAircraftType, fmt.Sprintf("flown by untyped %s", row.Schedule.AirplaneCode)
+		}
+		return RejectOtherAircraftType, fmt.Sprintf("flown by %s (%s)", row.Schedule.AirplaneCode, row.AircraftTypeCode)
+	}
+	return "", ""
+}
+
+// rejectFirstLeg adds the date and time-of-day checks that only apply to the
+// first leg of a journey. Dates are only checked when firstLegQuery widened the
+// availability query; otherwise the repository already filtered them.
+func (c searchCriteria) rejectFirstLeg(row domain.FlightAvailability) (reason, detail string) {
+	if c.trace != nil && !c.dates.contains(row.Schedule.DepartureDate) {
+		return RejectWrongDate, fmt.Sprintf("departs %s, wanted %s..%s", row.Schedule.DepartureDate, c.dates.from, c.dates.to)
+	}
+	if reason, detail = c.rejectLeg(row); reason != "" {
+		return reason, detail
+	}
+	if local := row.Schedule.LocalDeparture(); !c.departsInWindow(local) {
+		return RejectOutsideWindow, fmt.Sprintf("departs %s local", local.Format("15:04"))
+	}
+	return "", ""
+}
+
+// rejectConnection explains why second cannot follow first given the minimum
+// connection time at the intermediate airport.
+func (u *BookingUsecase) rejectConnection(first, second domain.FlightSchedule, minimum time.Duration) (layover time.Duration, reason, detail string) {
+	layover, ok := u.connections.Connect(first, second, minimum)
+	switch {
+	case ok:
+		return layover, "", ""
+	case first.ArrivalAt.IsZero() || second.DepartureAt.IsZero():
+		return 0, RejectNoArrival, "departure or arrival time unplanned"
+	case layover < minimum:
+		return layover, RejectConnectionTooShort, fmt.Sprintf("layover %s, minimum %s", layover, minimum)
+	default:
+		return layover, RejectConnectionTooLong, fmt.Sprintf("layover %s, maximum %s", layover, u.connections.MaxLayover)
+	}
+}
+
+// explainDays is how many days either side of the searched dates an explained
+// search loads, so near misses are reported as wrong-date without loading
+// every flight on the route.
+const explainDays = 7
+
+// firstLegQuery restricts q to the searched dates. An explained search loads
+// explainDays more on either side, to be reported as wrong-date.
+func (c searchCriteria) firstLegQuery(q domain.AvailabilityQuery) domain.AvailabilityQuery {
+	if c.trace != nil {
+		return c.dates.widen(explainDays).query(q)
+	}
+	return c.dates.query(q)
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ambiyansyah-risyal/flight-booking/internal/domain"
+)
+
+// brokenAirportRepo fails every airport lookup.
+type brokenAirportRepo struct {
+	fakeAirportRepo
+	err error
+}
+
+func (b *brokenAirportRepo) GetByCode(ctx context.Context, code string) (*domain.Airport, error) {
+	return nil, b.err
+}
+
+func traceReasons(trace *SearchTrace) map[int64]string {
+	reasons := make(map[int64]string)
+	for _, e := range trace.Entries {
+		reasons[e.ScheduleID] = e.Reason
+	}
+	return reasons
+}
+
+func TestBookingUsecase_FindDirectFlights_Explain(t *testing.T) {
+	day := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	row := func(id int64, date string, capacity, booked int) domain.FlightAvailability {
+		return domain.FlightAvailability{
+			Schedule:   domain.FlightSchedule{ID: id, RouteCode: "RT1", AirplaneCode: "A320", DepartureDate: date, DepartureAt: day.Add(8 * time.Hour)},
+			OriginCode: "CGK", DestinationCode: "DPS", SeatCapacity: capacity, Booked: booked,
+		}
+	}
+	avail := &stubAvailability{rows: []domain.FlightAvailability{
+		row(1, "2025-03-15", 2, 0),
+		row(2, "2025-03-15", 2, 2),
+		row(3, "2025-03-15", 0, 0),
+		row(4, "2025-03-16", 2, 0),
+	}}
+	uc := NewBookingUsecase(&mockBookingRepo{}, &mockScheduleRepo{}, &mockRouteRepo{}, &mockAirplaneRepo{}).WithAvailability(avail)
+
+	trace := &SearchTrace{}
+	options, err := uc.FindDirectFlights(context.Background(), SearchQuery{OriginCode: "CGK", DestinationCode: "DPS", DepartureDate: "2025-03-15", Explain: trace})
+	if err != nil || len(options) != 1 || options[0].ScheduleID != 1 {
+		t.Fatalf("expected only schedule 1, err=%v options=%+v", err, options)
+	}
+	want := map[int64]string{1: "", 2: RejectFull, 3: RejectUnknownAirplane, 4: RejectWrongDate}
+	if got := traceReasons(trace); len(got) != len(want) || got[1] != want[1] || got[2] != want[2] || got[3] != want[3] || got[4] != want[4] {
+		t.Fatalf("want reasons %v, got %v", want, got)
+	}
+	if len(trace.Rejected()) != 3 {
+		t.Fatalf("expected three rejections, got %+v", trace.Rejected())
+	}
+	// Explaining widens the query by a week either side so other dates can be reported.
+	if last := avail.searches[len(avail.searches)-1]; last.DepartureDate != "" || last.DateFrom != "2025-03-08" || last.DateTo != "2025-03-22" {
+		t.Fatalf("expected a two-week query when explaining, got %+v", last)
+	}
+}
+
+func TestBookingUsecase_FindTransitFlights_Explain(t *testing.T) {
+	day := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	leg := func(id int64, origin, destination string, departs, arrives time.Duration, booked int) domain.FlightAvailability {
+		return domain.FlightAvailability{
+			Schedule:   domain.FlightSchedule{ID: id, RouteCode: origin + destination, AirplaneCode: "A320", DepartureDate: "2025-03-15", DepartureAt: day.Add(departs), ArrivalAt: day.Add(arrives)},
+			OriginCode: origin, DestinationCode: destination, SeatCapacity: 2, Booked: booked,
+		}
+	}
+	avail := &byOriginAvailability{rows: map[string][]domain.FlightAvailability{
+		"CGK": {
+			leg(1, "CGK", "SUB", 8*time.Hour, 9*time.Hour, 0),
+			leg(2, "CGK", "UPG", 8*time.Hour, 10*time.Hour, 0),
+		},
+		"": {
+			leg(3, "SUB", "DPS", 9*time.Hour+10*time.Minute, 10*time.Hour, 0),
+			leg(4, "SUB", "DPS", 12*time.Hour, 13*time.Hour, 0),
+			leg(5, "SUB", "DPS", 14*time.Hour, 15*time.Hour, 2),
+		},
+	}}
+	uc := NewBookingUsecase(&mockBookingRepo{}, &mockScheduleRepo{}, &mockRouteRepo{}, &mockAirplaneRepo{}).
+		WithAvailability(avail).
+		WithConnectionPolicy(nil, domain.ConnectionPolicy{MinConnection: 45 * time.Minute, MaxLayover: 24 * time.Hour})
+
+	trace := &SearchTrace{}
+	options, err := uc.FindTransitFlights(context.Background(), SearchQuery{OriginCode: "CGK", DestinationCode: "DPS", DepartureDate: "2025-03-15", Explain: trace})
+	if err != nil || len(options) != 1 || options[0].SecondLeg.ScheduleID != 4 {
+		t.Fatalf("expected only the 12:00 connection, err=%v options=%+v", err, options)
+	}
+	got := traceReasons(trace)
+	if got[1] != "" || got[2] != RejectNoOnwardFlight || got[3] != RejectConnectionTooShort || got[5] != RejectFull {
+		t.Fatalf("unexpected reasons %v", got)
+	}
+	for _, e := range trace.Entries {
+		if e.ScheduleID == 3 && e.After != 1 {
+			t.Fatalf("expected the short connection to name its first leg, got %+v", e)
+		}
+	}
+
+	// Lookup failures are returned and recorded instead of skipping the airport.
+	boom := errors.New("airports unavailable")
+	uc.WithConnectionPolicy(&brokenAirportRepo{err: boom}, domain.DefaultConnectionPolicy)
+	trace = &SearchTrace{}
+	if _, err := uc.FindTransitFlights(context.Background(), SearchQuery{OriginCode: "CGK", DestinationCode: "DPS", Explain: trace}); !errors.Is(err, boom) {
+		t.Fatalf("want the airport error surfaced, got %v", err)
+	}
+	rejected := trace.Rejected()
+	if last := rejected[len(rejected)-1]; last.Reason != RejectRepositoryError || last.OriginCode != "SUB" {
+		t.Fatalf("expected a repository error at SUB, got %+v", last)
+	}
+}
+
+func TestRepositoryAvailability_UnknownAirplane(t *testing.T) {
+	routes := &mockRouteRepo{routes: map[string]*domain.Route{"RT1": {Code: "RT1", OriginCode: "CGK", DestinationCode: "DPS"}}}
+	schedules := &mockScheduleRepo{schedules: map[int64]*domain.FlightSchedule{1: {ID: 1, RouteCode: "RT1", AirplaneCode: "GONE", DepartureDate: "2025-03-15"}}}
+	avail := NewRepositoryAvailability(&mockBookingRepo{}, schedules, routes, &mockAirplaneRepo{}, nil)
+
+	rows, err := avail.Search(context.Background(), domain.AvailabilityQuery{OriginCode: "CGK"})
+	if err != nil || len(rows) != 1 || rows[0].SeatCapacity != 0 {
+		t.Fatalf("expected the schedule reported without seats, err=%v rows=%+v", err, rows)
+	}
+}
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ticket serials come from a dedicated sequence so concurrent issuance never collides.
+CREATE SEQUENCE IF NOT EXISTS ticket_serial_seq START 1 MAXVALUE 999999999 NO CYCLE;
+
+CREATE TABLE IF NOT EXISTS tickets (
+    id SERIAL PRIMARY KEY,
+    number CHAR(13) NOT NULL UNIQUE,
+    booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    passenger_name VARCHAR(128) NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS tickets_booking_id_idx ON tickets (booking_id);
+
+CREATE TABLE IF NOT EXISTS ticket_coupons (
+    id SERIAL PRIMARY KEY,
+    ticket_id INTEGER NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    sequence SMALLINT NOT NULL CHECK (sequence > 0),
+    schedule_id INTEGER NOT NULL REFERENCES flight_schedules(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT ticket_coupons_ticket_sequence_unique UNIQUE (ticket_id, sequence)
+);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE tickets, ticket_coupons TO flight_app;
+GRANT USAGE, SELECT ON SEQUENCE tickets_id_seq, ticket_coupons_id_seq, ticket_serial_seq TO flight_app;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ticket_coupons;
+DROP TABLE IF EXISTS tickets;
+DROP SEQUENCE IF EXISTS ticket_serial_seq;
+-- +goose StatementEnd
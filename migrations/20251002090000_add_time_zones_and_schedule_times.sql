@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE airports ADD COLUMN IF NOT EXISTS time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+UPDATE airports SET time_zone = 'Asia/Jakarta' WHERE code = 'CGK' AND time_zone = 'UTC';
+UPDATE airports SET time_zone = 'Asia/Makassar' WHERE code = 'DPS' AND time_zone = 'UTC';
+
+ALTER TABLE flight_schedules ADD COLUMN IF NOT EXISTS departure_at TIMESTAMPTZ;
+ALTER TABLE flight_schedules ADD COLUMN IF NOT EXISTS arrival_at TIMESTAMPTZ;
+-- Existing schedules only carried a date; treat them as departing at local midnight.
+UPDATE flight_schedules fs
+SET departure_at = (fs.departure_date::timestamp AT TIME ZONE o.time_zone)
+FROM routes r
+JOIN airports o ON o.code = r.origin_code
+WHERE r.code = fs.route_code AND fs.departure_at IS NULL;
+UPDATE flight_schedules SET departure_at = departure_date::timestamp AT TIME ZONE 'UTC' WHERE departure_at IS NULL;
+ALTER TABLE flight_schedules ALTER COLUMN departure_at SET NOT NULL;
+ALTER TABLE flight_schedules ADD CONSTRAINT flight_schedules_arrival_check CHECK (arrival_at IS NULL OR arrival_at > departure_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flight_schedules DROP CONSTRAINT IF EXISTS flight_schedules_arrival_check;
+ALTER TABLE flight_schedules DROP COLUMN IF EXISTS arrival_at;
+ALTER TABLE flight_schedules DROP COLUMN IF EXISTS departure_at;
+ALTER TABLE airports DROP COLUMN IF EXISTS time_zone;
+-- +goose StatementEnd
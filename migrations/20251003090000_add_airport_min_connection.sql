@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- 0 means the airport uses the globally configured minimum connection time.
+ALTER TABLE airports ADD COLUMN IF NOT EXISTS min_connection_minutes INTEGER NOT NULL DEFAULT 0
+    CONSTRAINT airports_min_connection_check CHECK (min_connection_minutes BETWEEN 0 AND 1440);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE airports DROP COLUMN IF EXISTS min_connection_minutes;
+-- +goose StatementEnd
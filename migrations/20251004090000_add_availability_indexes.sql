@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Support the availability read model used by flight search.
+CREATE INDEX IF NOT EXISTS routes_origin_destination_idx ON routes (origin_code, destination_code);
+CREATE INDEX IF NOT EXISTS routes_destination_idx ON routes (destination_code);
+CREATE INDEX IF NOT EXISTS flight_schedules_route_date_idx ON flight_schedules (route_code, departure_date);
+CREATE INDEX IF NOT EXISTS flight_schedules_departure_at_idx ON flight_schedules (departure_at);
+-- Booking counts use the (schedule_id, seat_number) unique index.
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS flight_schedules_departure_at_idx;
+DROP INDEX IF EXISTS flight_schedules_route_date_idx;
+DROP INDEX IF EXISTS routes_destination_idx;
+DROP INDEX IF EXISTS routes_origin_destination_idx;
+-- +goose StatementEnd
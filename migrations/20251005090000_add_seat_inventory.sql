@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per schedule, maintained in the same transaction as every booking
+-- change. Booking writes lock the row, which serialises seat allocation.
+CREATE TABLE IF NOT EXISTS seat_inventory (
+    schedule_id INTEGER PRIMARY KEY REFERENCES flight_schedules(id) ON DELETE CASCADE,
+    capacity INTEGER NOT NULL CHECK (capacity >= 0),
+    sold INTEGER NOT NULL DEFAULT 0 CHECK (sold >= 0),
+    held INTEGER NOT NULL DEFAULT 0 CHECK (held >= 0),
+    blocked INTEGER NOT NULL DEFAULT 0 CHECK (blocked >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO seat_inventory (schedule_id, capacity, sold, held)
+SELECT s.id, a.seat_capacity,
+       COUNT(b.id) FILTER (WHERE b.status = 'CONFIRMED'),
+       COUNT(b.id) FILTER (WHERE b.status = 'HELD')
+FROM flight_schedules s
+JOIN airplanes a ON a.code = s.airplane_code
+LEFT JOIN bookings b ON b.schedule_id = s.id
+GROUP BY s.id, a.seat_capacity
+ON CONFLICT (schedule_id) DO NOTHING;
+
+-- Cancelled bookings release their seat number for reuse.
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_schedule_seat_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS bookings_schedule_seat_active_idx ON bookings (schedule_id, seat_number) WHERE status <> 'CANCELLED';
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE seat_inventory TO flight_app;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS bookings_schedule_seat_active_idx;
+ALTER TABLE bookings ADD CONSTRAINT bookings_schedule_seat_unique UNIQUE (schedule_id, seat_number);
+DROP TABLE IF EXISTS seat_inventory;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Amounts are stored in hundredths of the currency unit.
+CREATE TABLE IF NOT EXISTS fares (
+    id SERIAL PRIMARY KEY,
+    route_code VARCHAR(16) NOT NULL REFERENCES routes(code) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    one_way_amount BIGINT NOT NULL CHECK (one_way_amount > 0),
+    round_trip_amount BIGINT CHECK (round_trip_amount > 0),
+    valid_from DATE,
+    valid_to DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT fares_validity_check CHECK (valid_from IS NULL OR valid_to IS NULL OR valid_from <= valid_to)
+);
+CREATE INDEX IF NOT EXISTS fares_route_code_idx ON fares (route_code, one_way_amount);
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE fares TO flight_app;
+GRANT USAGE, SELECT ON SEQUENCE fares_id_seq TO flight_app;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS fares;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A trip groups bookings made together, such as the two directions of a round trip.
+CREATE TABLE IF NOT EXISTS trips (
+    id SERIAL PRIMARY KEY,
+    passenger_name VARCHAR(128) NOT NULL,
+    fare_currency CHAR(3),
+    fare_amount BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT trips_fare_check CHECK ((fare_currency IS NULL) = (fare_amount IS NULL))
+);
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS trip_id INTEGER REFERENCES trips(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS bookings_trip_id_idx ON bookings (trip_id) WHERE trip_id IS NOT NULL;
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE trips TO flight_app;
+GRANT USAGE, SELECT ON SEQUENCE trips_id_seq TO flight_app;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS bookings_trip_id_idx;
+ALTER TABLE bookings DROP COLUMN IF EXISTS trip_id;
+DROP TABLE IF EXISTS trips;
+-- +goose StatementEnd
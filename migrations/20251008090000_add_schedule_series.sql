@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A series is a recurring pattern of flights; the flights it generates point back to it.
+CREATE TABLE IF NOT EXISTS schedule_series (
+    id SERIAL PRIMARY KEY,
+    route_code VARCHAR(16) NOT NULL REFERENCES routes(code) ON DELETE CASCADE,
+    airplane_code VARCHAR(16) NOT NULL REFERENCES airplanes(code) ON DELETE RESTRICT,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    days SMALLINT NOT NULL,
+    departure_time CHAR(5) NOT NULL,
+    arrival_time CHAR(5),
+    cancelled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT schedule_series_dates_check CHECK (start_date <= end_date),
+    CONSTRAINT schedule_series_days_check CHECK (days BETWEEN 1 AND 127)
+);
+ALTER TABLE flight_schedules ADD COLUMN IF NOT EXISTS series_id INTEGER REFERENCES schedule_series(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS flight_schedules_series_id_idx ON flight_schedules (series_id) WHERE series_id IS NOT NULL;
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE schedule_series TO flight_app;
+GRANT USAGE, SELECT ON SEQUENCE schedule_series_id_seq TO flight_app;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS flight_schedules_series_id_idx;
+ALTER TABLE flight_schedules DROP COLUMN IF EXISTS series_id;
+DROP TABLE IF EXISTS schedule_series;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Aircraft type catalog; airplanes are registrations referencing a type.
+CREATE TABLE IF NOT EXISTS aircraft_types (
+    id SERIAL PRIMARY KEY,
+    icao_code VARCHAR(4) NOT NULL UNIQUE,
+    iata_code VARCHAR(3) NOT NULL,
+    manufacturer VARCHAR(64) NOT NULL,
+    model VARCHAR(64) NOT NULL,
+    range_km INT NOT NULL CHECK (range_km > 0),
+    seat_rows INT NOT NULL CHECK (seat_rows > 0),
+    seat_layout VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+ALTER TABLE airplanes ADD COLUMN IF NOT EXISTS type_code VARCHAR(4) REFERENCES aircraft_types(icao_code) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS airplanes_type_code_idx ON airplanes (type_code) WHERE type_code IS NOT NULL;
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE aircraft_types TO flight_app;
+GRANT USAGE, SELECT ON SEQUENCE aircraft_types_id_seq TO flight_app;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS airplanes_type_code_idx;
+ALTER TABLE airplanes DROP COLUMN IF EXISTS type_code;
+DROP TABLE IF EXISTS aircraft_types;
+-- +goose StatementEnd
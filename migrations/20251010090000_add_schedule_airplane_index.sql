@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Serves rotation checks, which walk an airplane's flights in departure order.
+CREATE INDEX IF NOT EXISTS flight_schedules_airplane_departure_idx ON flight_schedules (airplane_code, departure_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS flight_schedules_airplane_departure_idx;
+-- +goose StatementEnd
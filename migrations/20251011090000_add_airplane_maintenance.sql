@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Periods in which an airplane is out of service; starts_at inclusive, ends_at exclusive.
+CREATE TABLE IF NOT EXISTS airplane_maintenance (
+    id SERIAL PRIMARY KEY,
+    airplane_code VARCHAR(16) NOT NULL REFERENCES airplanes(code) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT airplane_maintenance_period CHECK (ends_at > starts_at)
+);
+CREATE INDEX IF NOT EXISTS airplane_maintenance_airplane_idx ON airplane_maintenance (airplane_code, ends_at);
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE airplane_maintenance TO flight_app;
+GRANT USAGE, SELECT ON SEQUENCE airplane_maintenance_id_seq TO flight_app;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS airplane_maintenance;
+-- +goose StatementEnd
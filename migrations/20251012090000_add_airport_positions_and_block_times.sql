@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Airport positions in decimal degrees; both NULL when unknown. Route distances are computed from them.
+ALTER TABLE airports ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION
+    CONSTRAINT airports_latitude_check CHECK (latitude BETWEEN -90 AND 90);
+ALTER TABLE airports ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION
+    CONSTRAINT airports_longitude_check CHECK (longitude BETWEEN -180 AND 180);
+ALTER TABLE airports ADD CONSTRAINT airports_position_check CHECK ((latitude IS NULL) = (longitude IS NULL));
+-- 0 means the route has no scheduled block time.
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS block_minutes INTEGER NOT NULL DEFAULT 0
+    CONSTRAINT routes_block_minutes_check CHECK (block_minutes BETWEEN 0 AND 1440);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE routes DROP COLUMN IF EXISTS block_minutes;
+ALTER TABLE airports DROP CONSTRAINT IF EXISTS airports_position_check;
+ALTER TABLE airports DROP COLUMN IF EXISTS longitude;
+ALTER TABLE airports DROP COLUMN IF EXISTS latitude;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Cancelled flights stay stored with their bookings; search leaves them out.
+ALTER TABLE flight_schedules ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'SCHEDULED'
+    CONSTRAINT flight_schedules_status_check CHECK (status IN ('SCHEDULED', 'CANCELLED'));
+ALTER TABLE flight_schedules ADD COLUMN IF NOT EXISTS cancel_reason VARCHAR(200) NOT NULL DEFAULT '';
+ALTER TABLE flight_schedules ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
+-- Set on the bookings of a cancelled flight; those not yet cancelled still need rebooking or a refund.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS affected_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS bookings_affected_idx ON bookings (schedule_id, seat_number) WHERE affected_at IS NOT NULL AND status <> 'CANCELLED';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS bookings_affected_idx;
+ALTER TABLE bookings DROP COLUMN IF EXISTS affected_at;
+ALTER TABLE flight_schedules DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE flight_schedules DROP COLUMN IF EXISTS cancel_reason;
+ALTER TABLE flight_schedules DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Delays are kept beside the planned times: a flight is expected delay_minutes later than planned.
+ALTER TABLE flight_schedules ADD COLUMN IF NOT EXISTS delay_minutes INTEGER NOT NULL DEFAULT 0
+    CONSTRAINT flight_schedules_delay_check CHECK (delay_minutes >= 0);
+ALTER TABLE flight_schedules ADD COLUMN IF NOT EXISTS delay_reason VARCHAR(200) NOT NULL DEFAULT '';
+-- Messages to passengers, queued for a sender to deliver and stamp sent_at.
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    message VARCHAR(500) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS notifications_pending_idx ON notifications (created_at) WHERE sent_at IS NULL;
+GRANT SELECT, INSERT, UPDATE, DELETE ON TABLE notifications TO flight_app;
+GRANT USAGE, SELECT ON SEQUENCE notifications_id_seq TO flight_app;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications;
+ALTER TABLE flight_schedules DROP COLUMN IF EXISTS delay_reason;
+ALTER TABLE flight_schedules DROP COLUMN IF EXISTS delay_minutes;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Archived master data is hidden from lists and new routes and schedules but kept for the bookings that reference it.
+ALTER TABLE airports ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+ALTER TABLE airplanes ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+ALTER TABLE routes ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE routes DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE airplanes DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE airports DROP COLUMN IF EXISTS archived_at;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Gates are assigned per flight at each end; empty until known.
+ALTER TABLE flight_schedules ADD COLUMN IF NOT EXISTS departure_gate VARCHAR(8) NOT NULL DEFAULT '';
+ALTER TABLE flight_schedules ADD COLUMN IF NOT EXISTS arrival_gate VARCHAR(8) NOT NULL DEFAULT '';
+-- Airport boards look flights up by instant.
+CREATE INDEX IF NOT EXISTS flight_schedules_arrival_at_idx ON flight_schedules (arrival_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS flight_schedules_arrival_at_idx;
+ALTER TABLE flight_schedules DROP COLUMN IF EXISTS arrival_gate;
+ALTER TABLE flight_schedules DROP COLUMN IF EXISTS departure_gate;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every trip so far is a round trip; connecting itineraries are booked as
+-- their own kind, the only one whose flights passengers connect between.
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS kind VARCHAR(16) NOT NULL DEFAULT 'ROUND_TRIP';
+ALTER TABLE trips ADD CONSTRAINT trips_kind_check CHECK (kind IN ('ROUND_TRIP', 'CONNECTING'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE trips DROP CONSTRAINT IF EXISTS trips_kind_check;
+ALTER TABLE trips DROP COLUMN IF EXISTS kind;
+-- +goose StatementEnd